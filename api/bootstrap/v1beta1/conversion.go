@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	apiconversion "k8s.io/apimachinery/pkg/conversion"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+)
+
+// ConvertTo converts this KairosConfig to the hub version (v1beta2).
+func (src *KairosConfig) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*bootstrapv1beta2.KairosConfig)
+	if err := Convert_v1beta1_KairosConfig_To_v1beta2_KairosConfig(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Restore the fields v1beta1 cannot represent
+	restored := &bootstrapv1beta2.KairosConfig{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+	RestoreKairosConfigSpec(&restored.Spec, &dst.Spec)
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1beta2) to this version.
+func (dst *KairosConfig) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*bootstrapv1beta2.KairosConfig)
+	if err := Convert_v1beta2_KairosConfig_To_v1beta1_KairosConfig(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Keep the v1beta2 object in an annotation so a round trip through
+	// v1beta1 does not lose data
+	return utilconversion.MarshalData(src, dst)
+}
+
+// ConvertTo converts this KairosConfigList to the hub version (v1beta2).
+func (src *KairosConfigList) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*bootstrapv1beta2.KairosConfigList)
+	return Convert_v1beta1_KairosConfigList_To_v1beta2_KairosConfigList(src, dst, nil)
+}
+
+// ConvertFrom converts from the hub version (v1beta2) to this version.
+func (dst *KairosConfigList) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*bootstrapv1beta2.KairosConfigList)
+	return Convert_v1beta2_KairosConfigList_To_v1beta1_KairosConfigList(src, dst, nil)
+}
+
+// ConvertTo converts this KairosConfigTemplate to the hub version (v1beta2).
+func (src *KairosConfigTemplate) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*bootstrapv1beta2.KairosConfigTemplate)
+	if err := Convert_v1beta1_KairosConfigTemplate_To_v1beta2_KairosConfigTemplate(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Restore the fields v1beta1 cannot represent
+	restored := &bootstrapv1beta2.KairosConfigTemplate{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+	RestoreKairosConfigSpec(&restored.Spec.Template.Spec, &dst.Spec.Template.Spec)
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1beta2) to this version.
+func (dst *KairosConfigTemplate) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*bootstrapv1beta2.KairosConfigTemplate)
+	if err := Convert_v1beta2_KairosConfigTemplate_To_v1beta1_KairosConfigTemplate(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Keep the v1beta2 object in an annotation so a round trip through
+	// v1beta1 does not lose data
+	return utilconversion.MarshalData(src, dst)
+}
+
+// ConvertTo converts this KairosConfigTemplateList to the hub version (v1beta2).
+func (src *KairosConfigTemplateList) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*bootstrapv1beta2.KairosConfigTemplateList)
+	return Convert_v1beta1_KairosConfigTemplateList_To_v1beta2_KairosConfigTemplateList(src, dst, nil)
+}
+
+// ConvertFrom converts from the hub version (v1beta2) to this version.
+func (dst *KairosConfigTemplateList) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*bootstrapv1beta2.KairosConfigTemplateList)
+	return Convert_v1beta2_KairosConfigTemplateList_To_v1beta1_KairosConfigTemplateList(src, dst, nil)
+}
+
+// Convert_v1beta1_KairosConfigSpec_To_v1beta2_KairosConfigSpec is declared
+// here rather than generated, so that conversion-gen finds it when it
+// generates the conversions of the controlplane types that embed the spec.
+func Convert_v1beta1_KairosConfigSpec_To_v1beta2_KairosConfigSpec(in *KairosConfigSpec, out *bootstrapv1beta2.KairosConfigSpec, s apiconversion.Scope) error {
+	return autoConvert_v1beta1_KairosConfigSpec_To_v1beta2_KairosConfigSpec(in, out, s)
+}
+
+// Convert_v1beta2_KairosConfigSpec_To_v1beta1_KairosConfigSpec drops the
+// fields v1beta1 cannot represent; they are restored from the conversion data
+// annotation.
+func Convert_v1beta2_KairosConfigSpec_To_v1beta1_KairosConfigSpec(in *bootstrapv1beta2.KairosConfigSpec, out *KairosConfigSpec, s apiconversion.Scope) error {
+	return autoConvert_v1beta2_KairosConfigSpec_To_v1beta1_KairosConfigSpec(in, out, s)
+}
+
+// RestoreKairosConfigSpec copies the fields v1beta1 cannot represent from
+// restored, the v1beta2 spec kept in the conversion data annotation, to dst.
+func RestoreKairosConfigSpec(restored, dst *bootstrapv1beta2.KairosConfigSpec) {
+	dst.CertSANs = restored.CertSANs
+	dst.OIDC = restored.OIDC
+}
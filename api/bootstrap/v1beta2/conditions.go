@@ -23,6 +23,29 @@ const (
 
 	// DataSecretAvailableCondition reports whether the bootstrap data secret is available
 	DataSecretAvailableCondition = "DataSecretAvailable"
+
+	// ValidSpecCondition reports whether the spec passed validation. It is only
+	// set when the controller validates at reconcile time (webhooks disabled)
+	ValidSpecCondition = "ValidSpec"
+
+	// NodeJoinedCondition reports whether the machine's node has joined the workload cluster
+	NodeJoinedCondition = "NodeJoined"
+
+	// NodeReadyCondition reports whether the machine's node is Ready in the workload cluster
+	NodeReadyCondition = "NodeReady"
+
+	// BootstrapExecutedCondition reports whether the machine's node reported
+	// that it completed bootstrap. It is only set with
+	// spec.completionCallback.nodeAnnotation
+	BootstrapExecutedCondition = "BootstrapExecuted"
+
+	// PausedCondition reports whether reconciliation is paused by the
+	// cluster.x-k8s.io/paused annotation or the owning Cluster's spec.paused
+	PausedCondition = "Paused"
+
+	// OSUpgradeCompletedCondition reports whether the machine's node completed the
+	// system-upgrade-controller Plan of spec.osUpgrade. It is only set when spec.osUpgrade is set
+	OSUpgradeCompletedCondition = "OSUpgradeCompleted"
 )
 
 // Condition reasons
@@ -44,4 +67,72 @@ const (
 
 	// BootstrapFailedReason indicates that bootstrap failed
 	BootstrapFailedReason = "BootstrapFailed"
+
+	// InvalidSpecReason indicates that the spec failed validation
+	InvalidSpecReason = "InvalidSpec"
+
+	// WaitingForNodeReason indicates that the machine's node has not joined the workload cluster yet
+	WaitingForNodeReason = "WaitingForNode"
+
+	// NodeNotReadyReason indicates that the machine's node is not Ready
+	NodeNotReadyReason = "NodeNotReady"
+
+	// WaitingForBootstrapCompletionReason indicates that the machine's node has not reported bootstrap completion yet
+	WaitingForBootstrapCompletionReason = "WaitingForBootstrapCompletion"
+
+	// WorkloadClusterUnreachableReason indicates that the workload cluster API server could not be reached
+	WorkloadClusterUnreachableReason = "WorkloadClusterUnreachable"
+
+	// NotPausedReason indicates that reconciliation is not paused
+	NotPausedReason = "NotPaused"
+
+	// SystemUpgradeControllerNotInstalledReason indicates that the system-upgrade-controller is missing from the
+	// workload cluster and no manifest was configured to install it
+	SystemUpgradeControllerNotInstalledReason = "SystemUpgradeControllerNotInstalled"
+
+	// InstallingSystemUpgradeControllerReason indicates that the system-upgrade-controller is being installed in
+	// the workload cluster
+	InstallingSystemUpgradeControllerReason = "InstallingSystemUpgradeController"
+
+	// OSUpgradeInProgressReason indicates that the system-upgrade-controller is upgrading the machine's node
+	OSUpgradeInProgressReason = "OSUpgradeInProgress"
+)
+
+// Condition types and reasons reported in status.v1beta2.conditions
+const (
+	// ReadyV1Beta2Condition is true when the bootstrap data is ready
+	ReadyV1Beta2Condition = "Ready"
+
+	// DataSecretAvailableV1Beta2Condition is true when the bootstrap data secret is available
+	DataSecretAvailableV1Beta2Condition = "DataSecretAvailable"
+
+	// ReadyV1Beta2Reason is used when the bootstrap data is ready
+	ReadyV1Beta2Reason = "Ready"
+
+	// NotReadyV1Beta2Reason is used when the bootstrap data is not ready
+	NotReadyV1Beta2Reason = "NotReady"
+
+	// DataSecretAvailableV1Beta2Reason is used when the bootstrap data secret is available
+	DataSecretAvailableV1Beta2Reason = "Available"
+
+	// DataSecretNotAvailableV1Beta2Reason is used when the bootstrap data secret is not available
+	DataSecretNotAvailableV1Beta2Reason = "NotAvailable"
+
+	// PausedV1Beta2Condition is true while reconciliation is paused
+	PausedV1Beta2Condition = "Paused"
+
+	// PausedV1Beta2Reason is used when reconciliation is paused
+	PausedV1Beta2Reason = "Paused"
+
+	// NotPausedV1Beta2Reason is used when reconciliation is not paused
+	NotPausedV1Beta2Reason = "NotPaused"
+)
+
+// Event reasons emitted on KairosConfigs and the Machines they bootstrap
+const (
+	// BootstrapDataGeneratedEvent is emitted when new bootstrap data was written to the data secret
+	BootstrapDataGeneratedEvent = "BootstrapDataGenerated"
+
+	// TokenMissingEvent is emitted while the join token of a machine cannot be read or provisioned
+	TokenMissingEvent = "TokenMissing"
 )
@@ -1,6 +1,3 @@
-// Package v1beta2 contains API Schema definitions for the bootstrap v1beta2 API group
-// +kubebuilder:object:generate=true
-// +groupName=bootstrap.cluster.x-k8s.io
 package v1beta2
 
 import (
@@ -17,4 +14,13 @@ var (
 
 	// AddToScheme adds the types in this group-version to the given scheme.
 	AddToScheme = SchemeBuilder.AddToScheme
+
+	// SchemeGroupVersion is an alias of GroupVersion used by the generated
+	// clientset, listers and informers in pkg/client.
+	SchemeGroupVersion = GroupVersion
 )
+
+// Resource takes an unqualified resource and returns a group qualified GroupResource.
+func Resource(resource string) schema.GroupResource {
+	return GroupVersion.WithResource(resource).GroupResource()
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Tokens of HostnameTemplate, replaced by the controller when the bootstrap
+// data is generated
+const (
+	HostnameMachineNameToken = "{machine-name}"
+	HostnameClusterNameToken = "{cluster-name}"
+	HostnameNamespaceToken   = "{namespace}"
+)
+
+var (
+	// kairosTemplatePattern matches the Kairos template expressions Kairos
+	// renders on the node, e.g. {{ trunc 4 .MachineID }}
+	kairosTemplatePattern = regexp.MustCompile(`{{[^}]*}}`)
+	// hostnameTokenPattern matches the single-brace controller tokens
+	hostnameTokenPattern = regexp.MustCompile(`{[^{}]*}`)
+)
+
+// ExpandHostnameTemplate replaces the controller tokens of a HostnameTemplate.
+// Kairos template expressions are kept for Kairos to render on the node.
+func ExpandHostnameTemplate(template, machineName, clusterName, namespace string) string {
+	return strings.NewReplacer(
+		HostnameMachineNameToken, machineName,
+		HostnameClusterNameToken, clusterName,
+		HostnameNamespaceToken, namespace,
+	).Replace(template)
+}
@@ -19,6 +19,7 @@ package v1beta2
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
@@ -26,8 +27,26 @@ const (
 	// KairosConfigFinalizer allows the reconciler to clean up resources associated with KairosConfig before
 	// removing it from the API server.
 	KairosConfigFinalizer = "kairosconfig.bootstrap.cluster.x-k8s.io"
+
+	// BootstrapDataOutdatedAnnotation is set on Machines, and on the
+	// MachineDeployments owning them, that booted from bootstrap data which was
+	// re-rendered afterwards because of spec.regenerateOnChange. Its value is the
+	// KairosConfig generation of the new bootstrap data. Replacing the Machine
+	// picks up the new data.
+	BootstrapDataOutdatedAnnotation = "bootstrap.cluster.x-k8s.io/bootstrap-data-outdated"
+
+	// BootstrapExecutedAnnotation is set on the Node by nodes with
+	// spec.completionCallback.nodeAnnotation once they completed bootstrap.
+	// Its value is the completion time in RFC 3339 format.
+	BootstrapExecutedAnnotation = "bootstrap.cluster.x-k8s.io/bootstrap-executed"
 )
 
+// CredentialsSecretType is the type of the Secrets the controllers create to
+// hold k0s controller and worker join tokens. They are owned by the
+// KairosControlPlane or Cluster they belong to and replaced before the
+// tokens expire.
+const CredentialsSecretType corev1.SecretType = "bootstrap.cluster.x-k8s.io/credentials"
+
 // KairosConfigSpec defines the desired state of KairosConfig
 type KairosConfigSpec struct {
 	// Role indicates whether this is a control-plane or worker node
@@ -40,9 +59,22 @@ type KairosConfigSpec struct {
 	// +kubebuilder:default=k0s
 	Distribution string `json:"distribution,omitempty"`
 
+	// Format is the encoding of the bootstrap data Secret. cloud-config
+	// stores the Kairos cloud-config as is. iso-userdata also stores it under
+	// the userdata and metadata keys of a NoCloud datasource, for providers
+	// that build a config drive or NoCloud ISO from the Secret.
+	// ignition-wrapped stores an Ignition config that writes the cloud-config
+	// to /oem, for providers that only deliver Ignition. Defaults to
+	// cloud-config.
+	// +kubebuilder:validation:Enum=cloud-config;iso-userdata;ignition-wrapped
+	// +optional
+	Format Format `json:"format,omitempty"`
+
 	// KubernetesVersion specifies the Kubernetes version to install
-	// +kubebuilder:validation:Required
-	KubernetesVersion string `json:"kubernetesVersion"`
+	// It is set by the control plane for its own machines and can be left
+	// empty in configurations referenced or embedded by a KairosControlPlane.
+	// +optional
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
 
 	// ServerAddress is the address of the Kubernetes API server (for worker nodes)
 	// +optional
@@ -80,6 +112,13 @@ type KairosConfigSpec struct {
 	// +optional
 	Pause bool `json:"pause,omitempty"`
 
+	// RegenerateOnChange re-renders the bootstrap data Secret when the spec
+	// changes after the Secret was generated. Machines that already booted
+	// from the previous data get the BootstrapDataOutdatedAnnotation so a
+	// rolling replacement can pick up the new configuration.
+	// +optional
+	RegenerateOnChange bool `json:"regenerateOnChange,omitempty"`
+
 	// SingleNode indicates this is a single-node control plane cluster
 	// When true, k0s will be configured with --single flag
 	// +optional
@@ -90,14 +129,26 @@ type KairosConfigSpec struct {
 	// +optional
 	UserName string `json:"userName,omitempty"`
 
-	// UserPassword is the password for the default user
-	// Defaults to "kairos" if not specified.
+	// UserPassword is the plain text password for the default user.
+	// For production use, prefer UserPasswordSecretRef or PasswdHash instead.
+	// When no password is set, the default user gets the password "kairos".
 	// WARNING: This default is for development only and is NOT production-safe.
-	// For production use, always set a strong password.
-	// +kubebuilder:default=kairos
 	// +optional
 	UserPassword string `json:"userPassword,omitempty"`
 
+	// UserPasswordSecretRef is a reference to a Secret containing the password
+	// of the default user, in plain text or as a crypt(3) hash. The Secret is
+	// read when the bootstrap data is generated, so the password never appears
+	// in the KairosConfig. Mutually exclusive with UserPassword and PasswdHash.
+	// +optional
+	UserPasswordSecretRef *UserPasswordSecretReference `json:"userPasswordSecretRef,omitempty"`
+
+	// PasswdHash is the password of the default user as a crypt(3) hash,
+	// e.g. the output of "openssl passwd -6". Mutually exclusive with
+	// UserPassword and UserPasswordSecretRef.
+	// +optional
+	PasswdHash string `json:"passwdHash,omitempty"`
+
 	// UserGroups are the groups for the default user
 	// +kubebuilder:default={admin}
 	// +optional
@@ -112,6 +163,20 @@ type KairosConfigSpec struct {
 	// +optional
 	SSHPublicKey string `json:"sshPublicKey,omitempty"`
 
+	// SSHAuthorizedKeys are additional SSH public keys of the default user
+	// +optional
+	SSHAuthorizedKeys []string `json:"sshAuthorizedKeys,omitempty"`
+
+	// SSHKeySecretRefs reference Secrets holding SSH public keys of the
+	// default user, one key per line in each data value. The namespace
+	// defaults to the KairosConfig namespace.
+	// +optional
+	SSHKeySecretRefs []corev1.SecretReference `json:"sshKeySecretRefs,omitempty"`
+
+	// Users are additional user accounts created next to the default user
+	// +optional
+	Users []KairosUser `json:"users,omitempty"`
+
 	// WorkerToken is the join token for worker nodes (inline specification)
 	// For production use, prefer WorkerTokenSecretRef instead.
 	// If both WorkerToken and WorkerTokenSecretRef are set, WorkerTokenSecretRef takes precedence.
@@ -135,6 +200,21 @@ type KairosConfigSpec struct {
 	// +optional
 	K3sTokenSecretRef *WorkerTokenSecretReference `json:"k3sTokenSecretRef,omitempty"`
 
+	// ControllerTokenSecretRef is a reference to a Secret containing a k0s controller join token.
+	// When set on a control-plane KairosConfig, the node joins the existing k0s control plane
+	// instead of initializing a new cluster. The KairosControlPlane controller sets it for every
+	// control plane machine after the first.
+	// +optional
+	ControllerTokenSecretRef *WorkerTokenSecretReference `json:"controllerTokenSecretRef,omitempty"`
+
+	// ExternalControlPlaneEndpoint indicates that the Cluster's
+	// controlPlaneEndpoint is managed outside of Cluster API. KubeVirt control
+	// plane nodes then use it instead of waiting for the control plane load
+	// balancer Service. The KairosControlPlane controller sets it when
+	// spec.externalManagedEndpoint is set.
+	// +optional
+	ExternalControlPlaneEndpoint bool `json:"externalControlPlaneEndpoint,omitempty"`
+
 	// Manifests are Kubernetes manifests to be placed in the distribution manifests directory.
 	// These will be automatically applied by the distribution at cluster startup.
 	// k0s: /var/lib/k0s/manifests/{Name}/{File}
@@ -143,10 +223,20 @@ type KairosConfigSpec struct {
 	Manifests []Manifest `json:"manifests,omitempty"`
 
 	// Hostname is the node hostname to set inside the VM
-	// If set, it takes precedence over HostnamePrefix.
+	// If set, it takes precedence over HostnameTemplate and HostnamePrefix.
 	// +optional
 	Hostname string `json:"hostname,omitempty"`
 
+	// HostnameTemplate is a template for the node hostname, used when Hostname
+	// is not set. The controller replaces the tokens {machine-name},
+	// {cluster-name} and {namespace}; Kairos template expressions like
+	// {{ trunc 4 .MachineID }} are rendered by Kairos on the node.
+	// For example "{cluster-name}-{{ trunc 4 .MachineID }}".
+	// Without Hostname and HostnameTemplate the node is named after its Machine.
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	HostnameTemplate string `json:"hostnameTemplate,omitempty"`
+
 	// HostnamePrefix is the prefix for the hostname that will be set on the node
 	// The final hostname will be: {HostnamePrefix}{{ trunc 4 .MachineID }}
 	// For example, if HostnamePrefix is "metal-", the hostname will be "metal-{4-char-machine-id}"
@@ -160,13 +250,13 @@ type KairosConfigSpec struct {
 	// +optional
 	DNSServers []string `json:"dnsServers,omitempty"`
 
-	// PodCIDR configures the pod network CIDR for k0s
-	// Defaults to k0s defaults if not specified.
+	// PodCIDR configures the pod network CIDR for k0s and k3s control planes
+	// Defaults to the distribution defaults if not specified.
 	// +optional
 	PodCIDR string `json:"podCIDR,omitempty"`
 
-	// ServiceCIDR configures the service network CIDR for k0s
-	// Defaults to k0s defaults if not specified.
+	// ServiceCIDR configures the service network CIDR for k0s and k3s control planes
+	// Defaults to the distribution defaults if not specified.
 	// +optional
 	ServiceCIDR string `json:"serviceCIDR,omitempty"`
 
@@ -179,6 +269,149 @@ type KairosConfigSpec struct {
 	// This controls how Kairos OS is installed to disk
 	// +optional
 	Install *InstallConfig `json:"install,omitempty"`
+
+	// TrustedBoot marks the node image as a Kairos Trusted Boot (UKI) image.
+	// The kernel command line of UKI images is signed and measured, so grub
+	// options are not rendered, and the partition layout has no recovery or
+	// state partition.
+	// +optional
+	TrustedBoot bool `json:"trustedBoot,omitempty"`
+
+	// Encryption encrypts partitions at rest with Kairos kcrypt during
+	// installation. Requires spec.install.
+	// +optional
+	Encryption *EncryptionConfig `json:"encryption,omitempty"`
+
+	// P2P configures Kairos P2P networking (EdgeVPN). Nodes sharing a network
+	// token find each other and set up k3s over the VPN, without explicit
+	// server addresses or join tokens. Only supported with k3s.
+	// +optional
+	P2P *P2PConfig `json:"p2p,omitempty"`
+
+	// KubeletExtraArgs are extra kubelet flags, without leading dashes, e.g.
+	// {"cgroup-driver": "systemd"}. k0s passes them with --kubelet-extra-args,
+	// k3s with one --kubelet-arg per entry.
+	// +optional
+	KubeletExtraArgs map[string]string `json:"kubeletExtraArgs,omitempty"`
+
+	// ExtraInstallArgs are appended verbatim to the k0s/k3s install arguments
+	// +optional
+	ExtraInstallArgs []string `json:"extraInstallArgs,omitempty"`
+
+	// CertSANs are additional subject alternative names for the API server
+	// certificate of control plane nodes, e.g. external DNS names or virtual
+	// IPs. Rendered into spec.api.sans of the k0s config or --tls-san flags of
+	// k3s.
+	// +optional
+	CertSANs []string `json:"certSANs,omitempty"`
+
+	// OIDC configures the API server of control plane nodes to authenticate
+	// users with OpenID Connect ID tokens. Rendered into spec.api.extraArgs of
+	// the k0s config or --kube-apiserver-arg flags of k3s.
+	// +optional
+	OIDC *OIDCConfig `json:"oidc,omitempty"`
+
+	// K0sConfig customizes the k0s configuration written to /etc/k0s/k0s.yaml
+	// on control plane nodes. k0s only.
+	// +optional
+	K0sConfig *K0sConfig `json:"k0sConfig,omitempty"`
+
+	// Airgap configures nodes to bootstrap without internet access, from image
+	// bundles pre-seeded on the OS image and registry mirrors
+	// +optional
+	Airgap *AirgapConfig `json:"airgap,omitempty"`
+
+	// RegistryCredentials reference Secrets with docker registry credentials
+	// that k0s/k3s use to pull images
+	// +optional
+	RegistryCredentials []RegistrySecretRef `json:"registryCredentials,omitempty"`
+
+	// Proxy configures the egress proxy of k0s/k3s and containerd
+	// +optional
+	Proxy *ProxyConfig `json:"proxy,omitempty"`
+
+	// Network configures the node network with systemd-networkd, e.g. static
+	// addresses on networks without DHCP
+	// +optional
+	Network *NetworkConfig `json:"network,omitempty"`
+
+	// Stages are Kairos cloud-config stages, e.g. boot or after-install,
+	// mapped to their steps. The steps are appended to the steps the
+	// controller renders for the same stage.
+	// +optional
+	Stages map[string][]runtime.RawExtension `json:"stages,omitempty"`
+
+	// CompletionCallback makes the node report that it completed bootstrap,
+	// in addition to writing /run/cluster-api/bootstrap-success.complete
+	// +optional
+	CompletionCallback *CompletionCallback `json:"completionCallback,omitempty"`
+
+	// OSUpgrade upgrades the Kairos OS of the running worker node in place
+	// with a system-upgrade-controller Plan in the workload cluster. Control
+	// plane nodes are upgraded through the KairosControlPlane spec.osUpgrade.
+	// +optional
+	OSUpgrade *OSUpgrade `json:"osUpgrade,omitempty"`
+}
+
+// OSUpgrade configures an in-place Kairos OS upgrade run by the
+// system-upgrade-controller
+type OSUpgrade struct {
+	// Image is the Kairos OS image without tag, e.g. quay.io/kairos/ubuntu
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+
+	// Version is the tag of Image the nodes are upgraded to
+	// +kubebuilder:validation:MinLength=1
+	Version string `json:"version"`
+
+	// Concurrency is the number of nodes upgraded at the same time. Defaults
+	// to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Concurrency int64 `json:"concurrency,omitempty"`
+}
+
+// CompletionCallback configures how a node reports bootstrap completion
+type CompletionCallback struct {
+	// NodeAnnotation makes the node set the
+	// bootstrap.cluster.x-k8s.io/bootstrap-executed annotation on its Node with
+	// the kubelet credentials. The controller reports it in the
+	// BootstrapExecuted condition.
+	// +optional
+	NodeAnnotation bool `json:"nodeAnnotation,omitempty"`
+
+	// URL receives an HTTP POST with the machine metadata as JSON
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	URL string `json:"url,omitempty"`
+}
+
+// KairosUser specifies an additional user account on the node
+type KairosUser struct {
+	// Name is the user name
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Password is the user password, in plain text or as a crypt(3) hash
+	// +optional
+	Password string `json:"password,omitempty"`
+
+	// LockPassword disables password login for the user
+	// +optional
+	LockPassword bool `json:"lockPassword,omitempty"`
+
+	// Groups are the supplementary groups of the user
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+
+	// SSHAuthorizedKeys are the SSH public keys of the user. Entries of the
+	// form "github:<user>" fetch the keys of a GitHub user.
+	// +optional
+	SSHAuthorizedKeys []string `json:"sshAuthorizedKeys,omitempty"`
+
+	// Sudo is a sudoers rule for the user, e.g. "ALL=(ALL) NOPASSWD:ALL"
+	// +optional
+	Sudo string `json:"sudo,omitempty"`
 }
 
 // InstallConfig specifies the Kairos installation configuration
@@ -201,6 +434,448 @@ type InstallConfig struct {
 	// +kubebuilder:default=true
 	// +optional
 	Reboot *bool `json:"reboot,omitempty"`
+
+	// NoFormat installs without formatting the device, e.g. onto a disk
+	// partitioned in advance
+	// +optional
+	NoFormat bool `json:"noFormat,omitempty"`
+
+	// Partitions configures the size and filesystem of the Kairos partitions
+	// +optional
+	Partitions *InstallPartitions `json:"partitions,omitempty"`
+
+	// GrubOptions are set in the Kairos grub environment, e.g.
+	// {"extra_cmdline": "console=ttyS0"}
+	// +optional
+	GrubOptions map[string]string `json:"grubOptions,omitempty"`
+}
+
+// InstallPartitions specifies the Kairos partition layout
+type InstallPartitions struct {
+	// OEM is the partition holding the cloud-config
+	// +optional
+	OEM *InstallPartition `json:"oem,omitempty"`
+
+	// Recovery is the partition holding the recovery system
+	// +optional
+	Recovery *InstallPartition `json:"recovery,omitempty"`
+
+	// State is the partition holding the active and passive systems
+	// +optional
+	State *InstallPartition `json:"state,omitempty"`
+
+	// Persistent is the partition holding persistent data, e.g. k0s/k3s state
+	// +optional
+	Persistent *InstallPartition `json:"persistent,omitempty"`
+}
+
+// InstallPartition specifies the size and filesystem of a partition
+type InstallPartition struct {
+	// Size is the partition size in MiB. 0 uses the Kairos default; for the
+	// persistent partition it uses the rest of the device.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Size int32 `json:"size,omitempty"`
+
+	// Filesystem is the filesystem of the partition
+	// +kubebuilder:validation:Enum=ext2;ext3;ext4;xfs
+	// +optional
+	Filesystem string `json:"filesystem,omitempty"`
+}
+
+// EncryptionConfig specifies the Kairos kcrypt disk encryption
+type EncryptionConfig struct {
+	// Partitions are the filesystem labels of the partitions to encrypt.
+	// Defaults to COS_PERSISTENT.
+	// +optional
+	Partitions []string `json:"partitions,omitempty"`
+
+	// ChallengerServer is the URL of the kcrypt challenger (KMS) that
+	// releases the partition keys to the node's TPM. Without it the keys are
+	// sealed to the local TPM.
+	// +optional
+	ChallengerServer string `json:"challengerServer,omitempty"`
+
+	// MDNS resolves the challenger server host name over mDNS
+	// +optional
+	MDNS bool `json:"mdns,omitempty"`
+
+	// TPM configures the TPM used to store the partition keys
+	// +optional
+	TPM *EncryptionTPMConfig `json:"tpm,omitempty"`
+}
+
+// EncryptionTPMConfig specifies the TPM options of kcrypt
+type EncryptionTPMConfig struct {
+	// NVIndex is the TPM NV index holding the encrypted passphrase, e.g. "0x1500000"
+	// +optional
+	NVIndex string `json:"nvIndex,omitempty"`
+
+	// CIndex is the TPM NV index of the certificate used to encrypt the
+	// passphrase, e.g. "0x1c00000"
+	// +optional
+	CIndex string `json:"cIndex,omitempty"`
+
+	// Device is the TPM device, e.g. "/dev/tpmrm0"
+	// +optional
+	Device string `json:"device,omitempty"`
+}
+
+// K0sConfig specifies the k0s ClusterConfig of control plane nodes.
+// The structured fields, spec.podCIDR, spec.serviceCIDR and the control plane
+// endpoint are merged on top of Config.
+type K0sConfig struct {
+	// Config is a complete or partial k0s ClusterConfig, e.g. to configure
+	// extensions. apiVersion, kind and metadata.name are defaulted.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Config *runtime.RawExtension `json:"config,omitempty"`
+
+	// APISANs are additional subject alternative names for the API server certificate
+	// +optional
+	APISANs []string `json:"apiSANs,omitempty"`
+
+	// NetworkProvider selects the k0s network provider
+	// +kubebuilder:validation:Enum=kuberouter;calico;custom
+	// +optional
+	NetworkProvider string `json:"networkProvider,omitempty"`
+
+	// Storage configures the k0s storage backend
+	// +optional
+	Storage *K0sStorageConfig `json:"storage,omitempty"`
+
+	// Extensions are deployed by k0s on the controllers, as an alternative to
+	// spec.manifests
+	// +optional
+	Extensions *K0sExtensions `json:"extensions,omitempty"`
+
+	// DynamicConfig runs the controllers with --enable-dynamic-config: k0s
+	// stores the cluster-wide configuration, including extensions, in a
+	// ClusterConfig object in the workload cluster, where it can be changed
+	// after bootstrap. Only the first controller's config is applied.
+	// +optional
+	DynamicConfig bool `json:"dynamicConfig,omitempty"`
+}
+
+// K0sExtensions specifies the k0s extensions
+type K0sExtensions struct {
+	// Helm holds the Helm repositories and charts of the k0s Helm extension
+	// +optional
+	Helm *K0sHelmExtension `json:"helm,omitempty"`
+}
+
+// K0sHelmExtension specifies the Helm charts k0s installs
+type K0sHelmExtension struct {
+	// Repositories are the Helm repositories the charts are pulled from
+	// +optional
+	Repositories []K0sHelmRepository `json:"repositories,omitempty"`
+
+	// Charts are the Helm charts k0s installs and keeps up to date
+	// +optional
+	Charts []K0sHelmChart `json:"charts,omitempty"`
+}
+
+// K0sHelmRepository is a Helm chart repository
+type K0sHelmRepository struct {
+	// Name is the repository name charts refer to, e.g. "prometheus-community"
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// URL is the repository URL
+	// +kubebuilder:validation:Pattern=`^(https?|oci)://`
+	URL string `json:"url"`
+}
+
+// K0sHelmChart is a Helm chart installed by k0s
+type K0sHelmChart struct {
+	// Name is the Helm release name
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// ChartName is the chart as <repository>/<chart>, an OCI reference or a
+	// path to a chart archive on the node
+	// +kubebuilder:validation:MinLength=1
+	ChartName string `json:"chartname"`
+
+	// Version is the chart version. Defaults to the latest version.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Values is a YAML document with the chart values
+	// +optional
+	Values string `json:"values,omitempty"`
+
+	// Namespace is the namespace of the release
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// Order sorts the chart installation, lower first
+	// +optional
+	Order int32 `json:"order,omitempty"`
+}
+
+// K0sStorageConfig specifies the k0s storage backend
+type K0sStorageConfig struct {
+	// Type is the storage backend
+	// +kubebuilder:validation:Enum=etcd;kine
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// KineDataSource is the kine data source, e.g. a MySQL or PostgreSQL DSN.
+	// Only valid with type kine. k0s defaults to SQLite.
+	// +optional
+	KineDataSource string `json:"kineDataSource,omitempty"`
+}
+
+// AirgapConfig specifies the image sources of air-gapped nodes
+type AirgapConfig struct {
+	// ImageBundles are absolute paths of image tarballs on the node, e.g. the
+	// k0s/k3s airgap bundle baked into the OS image. They are imported when
+	// k0s/k3s starts.
+	// +optional
+	ImageBundles []string `json:"imageBundles,omitempty"`
+
+	// Mirrors configures containerd registry mirrors
+	// +optional
+	Mirrors []RegistryMirror `json:"mirrors,omitempty"`
+
+	// DisableDefaultRegistry stops nodes from falling back to the upstream
+	// registries of mirrored images
+	// +optional
+	DisableDefaultRegistry bool `json:"disableDefaultRegistry,omitempty"`
+}
+
+// RegistryMirror specifies the mirrors of a registry
+type RegistryMirror struct {
+	// Registry is the mirrored registry host, e.g. "docker.io" or "registry.k8s.io"
+	// +kubebuilder:validation:MinLength=1
+	Registry string `json:"registry"`
+
+	// Endpoints are the mirror URLs, tried in order
+	// +kubebuilder:validation:MinItems=1
+	Endpoints []string `json:"endpoints"`
+}
+
+// NetworkConfig specifies the node network. Bonds and VLANs are created first;
+// Interfaces then configure the addressing of physical links, bonds and VLANs.
+type NetworkConfig struct {
+	// Interfaces configures the addressing of network links
+	// +optional
+	Interfaces []NetworkInterface `json:"interfaces,omitempty"`
+
+	// Bonds creates bonded links
+	// +optional
+	Bonds []NetworkBond `json:"bonds,omitempty"`
+
+	// VLANs creates VLAN links
+	// +optional
+	VLANs []NetworkVLAN `json:"vlans,omitempty"`
+}
+
+// NetworkInterface specifies the addressing of a link
+type NetworkInterface struct {
+	// Name is the link name, e.g. "eth0", or the name of a bond or VLAN
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// MACAddress matches the link by MAC address instead of by name
+	// +optional
+	MACAddress string `json:"macAddress,omitempty"`
+
+	// DHCP enables DHCP on the link
+	// +optional
+	DHCP bool `json:"dhcp,omitempty"`
+
+	// Addresses are static addresses in CIDR notation, e.g. "10.0.0.10/24"
+	// +optional
+	Addresses []string `json:"addresses,omitempty"`
+
+	// Gateway is the default gateway reached through the link
+	// +optional
+	Gateway string `json:"gateway,omitempty"`
+
+	// DNSServers are DNS resolvers used for the link
+	// +optional
+	DNSServers []string `json:"dnsServers,omitempty"`
+
+	// MTU sets the link MTU
+	// +kubebuilder:validation:Minimum=68
+	// +optional
+	MTU int32 `json:"mtu,omitempty"`
+}
+
+// NetworkBond specifies a bonded link
+type NetworkBond struct {
+	// Name is the name of the bond, e.g. "bond0"
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Interfaces are the names of the bonded links
+	// +kubebuilder:validation:MinItems=1
+	Interfaces []string `json:"interfaces"`
+
+	// Mode is the bonding mode
+	// +kubebuilder:validation:Enum=balance-rr;active-backup;balance-xor;broadcast;"802.3ad";balance-tlb;balance-alb
+	// +kubebuilder:default=active-backup
+	// +optional
+	Mode string `json:"mode,omitempty"`
+}
+
+// NetworkVLAN specifies a VLAN link
+type NetworkVLAN struct {
+	// Name is the name of the VLAN link, e.g. "vlan10"
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// ID is the VLAN ID
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	ID int32 `json:"id"`
+
+	// Link is the parent link, e.g. "eth0" or "bond0"
+	// +kubebuilder:validation:MinLength=1
+	Link string `json:"link"`
+}
+
+// ProxyConfig specifies an egress proxy
+type ProxyConfig struct {
+	// HTTPProxy is the proxy URL for HTTP requests
+	// +optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the proxy URL for HTTPS requests
+	// +optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy is a comma-separated list of hosts, domains and CIDRs that are
+	// reached directly. k0s clusters should include the pod and service CIDRs.
+	// +optional
+	NoProxy string `json:"noProxy,omitempty"`
+}
+
+// OIDCConfig specifies the OpenID Connect provider the API server trusts
+type OIDCConfig struct {
+	// IssuerURL is the HTTPS URL of the OpenID provider, which must match the
+	// iss claim of the tokens
+	// +kubebuilder:validation:Pattern=`^https://`
+	IssuerURL string `json:"issuerURL"`
+
+	// ClientID is the client ID the tokens must be issued for
+	// +kubebuilder:validation:MinLength=1
+	ClientID string `json:"clientID"`
+
+	// UsernameClaim is the claim used as the user name. The API server
+	// defaults to "sub".
+	// +optional
+	UsernameClaim string `json:"usernameClaim,omitempty"`
+
+	// UsernamePrefix is prepended to user names, e.g. "oidc:". "-" disables
+	// prefixing.
+	// +optional
+	UsernamePrefix string `json:"usernamePrefix,omitempty"`
+
+	// GroupsClaim is the claim holding the user's groups
+	// +optional
+	GroupsClaim string `json:"groupsClaim,omitempty"`
+
+	// GroupsPrefix is prepended to group names, e.g. "oidc:"
+	// +optional
+	GroupsPrefix string `json:"groupsPrefix,omitempty"`
+
+	// RequiredClaims are claims the tokens must carry with the given values
+	// +optional
+	RequiredClaims map[string]string `json:"requiredClaims,omitempty"`
+
+	// SigningAlgorithms are the accepted token signing algorithms. The API
+	// server defaults to RS256.
+	// +optional
+	SigningAlgorithms []string `json:"signingAlgorithms,omitempty"`
+
+	// CAFile is the path on the node of the CA bundle that signed the
+	// certificate of the OpenID provider, e.g. written through spec.files.
+	// The system trust store is used if empty.
+	// +optional
+	CAFile string `json:"caFile,omitempty"`
+}
+
+// RegistrySecretRef references a Secret holding a docker config JSON, such as
+// a kubernetes.io/dockerconfigjson Secret
+type RegistrySecretRef struct {
+	// Name is the name of the Secret in the namespace of the KairosConfig
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key is the Secret key holding the docker config JSON
+	// +kubebuilder:default=.dockerconfigjson
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// P2PConfig specifies the Kairos P2P network configuration
+type P2PConfig struct {
+	// NetworkToken is the EdgeVPN network token shared by all nodes of the cluster
+	// Mutually exclusive with NetworkTokenSecretRef.
+	// +optional
+	NetworkToken string `json:"networkToken,omitempty"`
+
+	// NetworkTokenSecretRef is a reference to a Secret containing the network token
+	// +optional
+	NetworkTokenSecretRef *WorkerTokenSecretReference `json:"networkTokenSecretRef,omitempty"`
+
+	// NetworkID separates clusters that share a network token
+	// +optional
+	NetworkID string `json:"networkID,omitempty"`
+
+	// DNS enables the embedded DNS server of the VPN
+	// +optional
+	DNS bool `json:"dns,omitempty"`
+
+	// DisableDHT limits node discovery to the local network
+	// +optional
+	DisableDHT bool `json:"disableDHT,omitempty"`
+
+	// Auto lets the nodes assign control plane and worker roles among themselves
+	// If not enabled, each node takes the role of its KairosConfig.
+	// +optional
+	Auto *P2PAutoConfig `json:"auto,omitempty"`
+}
+
+// P2PAutoConfig specifies automatic role assignment for P2P clusters
+type P2PAutoConfig struct {
+	// Enable turns on automatic role assignment
+	// +optional
+	Enable bool `json:"enable,omitempty"`
+
+	// HA configures a highly available control plane
+	// +optional
+	HA *P2PHAConfig `json:"ha,omitempty"`
+}
+
+// P2PHAConfig specifies the control plane of automatically coordinated P2P clusters
+type P2PHAConfig struct {
+	// Enable turns on a highly available control plane
+	// +optional
+	Enable bool `json:"enable,omitempty"`
+
+	// MasterNodes is the number of control plane nodes in addition to the one
+	// that initializes the cluster
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MasterNodes int32 `json:"masterNodes,omitempty"`
+}
+
+// UserPasswordSecretReference references the Secret key holding the password
+// of the default user
+type UserPasswordSecretReference struct {
+	// Name is the name of the Secret in the namespace of the KairosConfig
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key is the key within the Secret that contains the password
+	// Defaults to "password" if not specified
+	// +kubebuilder:default=password
+	// +optional
+	Key string `json:"key,omitempty"`
 }
 
 // WorkerTokenSecretReference is a reference to a Secret containing a worker join token
@@ -235,8 +910,26 @@ type Manifest struct {
 	File string `json:"file"`
 
 	// Content is the manifest YAML content
-	// +kubebuilder:validation:Required
-	Content string `json:"content"`
+	// Mutually exclusive with ContentFrom.
+	// +optional
+	Content string `json:"content,omitempty"`
+
+	// ContentFrom takes the manifest content from a Secret or a ConfigMap
+	// instead of Content, e.g. for large manifests or manifests holding
+	// credentials
+	// +optional
+	ContentFrom *ManifestSource `json:"contentFrom,omitempty"`
+}
+
+// ManifestSource is the source of a Manifest's content. Exactly one field must be set.
+type ManifestSource struct {
+	// Secret is a key of a Secret in the namespace of the KairosConfig
+	// +optional
+	Secret *FileKeySelector `json:"secret,omitempty"`
+
+	// ConfigMap is a key of a ConfigMap in the namespace of the KairosConfig
+	// +optional
+	ConfigMap *FileKeySelector `json:"configMap,omitempty"`
 }
 
 // File represents a file to be written in the cloud-config
@@ -245,7 +938,20 @@ type File struct {
 	Path string `json:"path"`
 
 	// Content is the file content
-	Content string `json:"content"`
+	// Mutually exclusive with ContentFrom.
+	// +optional
+	Content string `json:"content,omitempty"`
+
+	// ContentFrom takes the file content from a Secret, a ConfigMap or a URL
+	// instead of Content.
+	// +optional
+	ContentFrom *FileSource `json:"contentFrom,omitempty"`
+
+	// Encoding is the encoding of the content, which is decoded on the node
+	// before the file is written. Not supported with contentFrom.url.
+	// +kubebuilder:validation:Enum=base64;gzip+base64
+	// +optional
+	Encoding FileEncoding `json:"encoding,omitempty"`
 
 	// Permissions are the file permissions (octal format, e.g., "0644")
 	// +optional
@@ -256,6 +962,54 @@ type File struct {
 	Owner string `json:"owner,omitempty"`
 }
 
+// FileEncoding is the encoding of a File's content
+type FileEncoding string
+
+const (
+	// Base64FileEncoding is base64-encoded content
+	Base64FileEncoding FileEncoding = "base64"
+	// GzipBase64FileEncoding is gzip-compressed, base64-encoded content
+	GzipBase64FileEncoding FileEncoding = "gzip+base64"
+)
+
+// Format is the encoding of the bootstrap data
+type Format string
+
+const (
+	// CloudConfigFormat is the Kairos cloud-config as is
+	CloudConfigFormat Format = "cloud-config"
+	// ISOUserdataFormat is the cloud-config with NoCloud userdata and metadata keys
+	ISOUserdataFormat Format = "iso-userdata"
+	// IgnitionWrappedFormat is an Ignition config writing the cloud-config to /oem
+	IgnitionWrappedFormat Format = "ignition-wrapped"
+)
+
+// FileSource is the source of a File's content. Exactly one field must be set.
+type FileSource struct {
+	// Secret is a key of a Secret in the namespace of the KairosConfig
+	// +optional
+	Secret *FileKeySelector `json:"secret,omitempty"`
+
+	// ConfigMap is a key of a ConfigMap in the namespace of the KairosConfig
+	// +optional
+	ConfigMap *FileKeySelector `json:"configMap,omitempty"`
+
+	// URL is an http(s) URL the node downloads the content from at boot
+	// +optional
+	URL string `json:"url,omitempty"`
+}
+
+// FileKeySelector selects a key of a Secret or ConfigMap
+type FileKeySelector struct {
+	// Name is the name of the Secret or ConfigMap
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Key is the key holding the file content
+	// +kubebuilder:validation:Required
+	Key string `json:"key"`
+}
+
 // KairosConfigStatus defines the observed state of KairosConfig
 // Contract: BootstrapConfig v1beta2 MUST expose a dataSecretName and ready status
 type KairosConfigStatus struct {
@@ -286,6 +1040,21 @@ type KairosConfigStatus struct {
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
+	// DataSecretGeneration is the generation of the spec the bootstrap data
+	// Secret was last rendered from
+	// +optional
+	DataSecretGeneration int64 `json:"dataSecretGeneration,omitempty"`
+
+	// WorkerTokenSecretRef references the worker join token Secret the
+	// controller provisioned from the workload cluster, for k0s workers whose
+	// spec sets no token
+	// +optional
+	WorkerTokenSecretRef *WorkerTokenSecretReference `json:"workerTokenSecretRef,omitempty"`
+
+	// V1Beta2 groups the fields of the Cluster API v1beta2 status contract.
+	// +optional
+	V1Beta2 *KairosConfigV1Beta2Status `json:"v1beta2,omitempty"`
+
 	// FailureReason indicates the reason for bootstrap failure
 	// This field is set only when bootstrap fails permanently.
 	// +optional
@@ -297,6 +1066,18 @@ type KairosConfigStatus struct {
 	FailureMessage string `json:"failureMessage,omitempty"`
 }
 
+// KairosConfigV1Beta2Status groups the fields of the Cluster API v1beta2 status contract.
+type KairosConfigV1Beta2Status struct {
+	// Conditions represents the observations of the KairosConfig in the
+	// metav1.Condition format. Known condition types are Ready and
+	// DataSecretAvailable.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=32
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
 // KairosConfigInitialization provides observations of the KairosConfig initialization process.
 // NOTE: Fields in this struct are part of the Cluster API contract, and they are used to orchestrate initial Machine provisioning.
 type KairosConfigInitialization struct {
@@ -306,10 +1087,14 @@ type KairosConfigInitialization struct {
 	DataSecretCreated bool `json:"dataSecretCreated,omitempty"`
 }
 
+// +genclient
 // +kubebuilder:object:root=true
-// +kubebuilder:resource:path=kairosconfigs,scope=Namespaced,categories=cluster-api
+// +kubebuilder:resource:path=kairosconfigs,scope=Namespaced,categories=cluster-api,shortName=kcfg
 // +kubebuilder:subresource:status
 // +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels['cluster\\.x-k8s\\.io/cluster-name']",description="Cluster"
+// +kubebuilder:printcolumn:name="Role",type="string",JSONPath=".spec.role",description="Node role"
+// +kubebuilder:printcolumn:name="Distribution",type="string",JSONPath=".spec.distribution",description="Kubernetes distribution"
 // +kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready",description="Bootstrap ready"
 // +kubebuilder:printcolumn:name="DataSecretName",type="string",JSONPath=".status.dataSecretName",description="Secret containing bootstrap data"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
@@ -342,6 +1127,22 @@ func (c *KairosConfig) SetConditions(conditions clusterv1.Conditions) {
 	c.Status.Conditions = conditions
 }
 
+// GetV1Beta2Conditions returns the set of v1beta2 conditions for this object.
+func (c *KairosConfig) GetV1Beta2Conditions() []metav1.Condition {
+	if c.Status.V1Beta2 == nil {
+		return nil
+	}
+	return c.Status.V1Beta2.Conditions
+}
+
+// SetV1Beta2Conditions sets the v1beta2 conditions on this object.
+func (c *KairosConfig) SetV1Beta2Conditions(conditions []metav1.Condition) {
+	if c.Status.V1Beta2 == nil {
+		c.Status.V1Beta2 = &KairosConfigV1Beta2Status{}
+	}
+	c.Status.V1Beta2.Conditions = conditions
+}
+
 func init() {
 	SchemeBuilder.Register(&KairosConfig{}, &KairosConfigList{})
 }
@@ -17,14 +17,23 @@ permissions and limitations under the License.
 package v1beta2
 
 import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/yaml"
 )
 
 // log is for logging in this package.
@@ -44,26 +53,32 @@ var _ webhook.Defaulter = &KairosConfig{}
 // Default implements webhook.Defaulter so a webhook will be registered for the type
 func (r *KairosConfig) Default() {
 	kairosconfigLog.Info("default", "name", r.Name)
+	r.setDefaults()
+}
+
+// setDefaults fills in unset fields with their default values
+func (r *KairosConfig) setDefaults() {
+	r.Spec.setDefaults()
+}
 
+// setDefaults fills in unset spec fields with their default values
+func (s *KairosConfigSpec) setDefaults() {
 	// Set defaults for user configuration
-	if r.Spec.UserName == "" {
-		r.Spec.UserName = "kairos"
-	}
-	if r.Spec.UserPassword == "" {
-		r.Spec.UserPassword = "kairos"
+	if s.UserName == "" {
+		s.UserName = "kairos"
 	}
-	if len(r.Spec.UserGroups) == 0 {
-		r.Spec.UserGroups = []string{"admin"}
+	if len(s.UserGroups) == 0 {
+		s.UserGroups = []string{"admin"}
 	}
 
 	// Set default distribution
-	if r.Spec.Distribution == "" {
-		r.Spec.Distribution = "k0s"
+	if s.Distribution == "" {
+		s.Distribution = "k0s"
 	}
 
 	// Set default role
-	if r.Spec.Role == "" {
-		r.Spec.Role = "worker"
+	if s.Role == "" {
+		s.Role = "worker"
 	}
 }
 
@@ -74,13 +89,13 @@ var _ webhook.Validator = &KairosConfig{}
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (r *KairosConfig) ValidateCreate() (admission.Warnings, error) {
 	kairosconfigLog.Info("validate create", "name", r.Name)
-	return nil, r.validate()
+	return r.Spec.warnings(field.NewPath("spec")), r.validate()
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (r *KairosConfig) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
 	kairosconfigLog.Info("validate update", "name", r.Name)
-	return nil, r.validate()
+	return r.Spec.warnings(field.NewPath("spec")), r.validate()
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -89,61 +104,969 @@ func (r *KairosConfig) ValidateDelete() (admission.Warnings, error) {
 	return nil, nil
 }
 
+// Validate runs the same checks as the validating webhook against a defaulted
+// copy of the object. Controllers call it when admission webhooks are disabled.
+func (r *KairosConfig) Validate() error {
+	defaulted := r.DeepCopy()
+	defaulted.setDefaults()
+	return defaulted.validate()
+}
+
+// ValidateSpec checks a defaulted copy of spec and reports errors under
+// fldPath. Resources embedding a KairosConfigSpec, like KairosControlPlanes,
+// use it to validate the KairosConfigs they create.
+func ValidateSpec(spec *KairosConfigSpec, fldPath *field.Path) field.ErrorList {
+	defaulted := spec.DeepCopy()
+	defaulted.setDefaults()
+	return defaulted.validate(fldPath)
+}
+
+// ValidateCertSANs checks that every API server certificate SAN is an IP
+// address or a DNS name, optionally with a leading wildcard.
+func ValidateCertSANs(sans []string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, san := range sans {
+		if net.ParseIP(san) != nil {
+			continue
+		}
+		if errs := validation.IsDNS1123Subdomain(strings.TrimPrefix(san, "*.")); len(errs) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), san,
+				"must be an IP address or a DNS name: "+strings.Join(errs, ", ")))
+		}
+	}
+	return allErrs
+}
+
+// SpecWarnings returns the admission warnings for spec, reported under
+// fldPath. Resources embedding a KairosConfigSpec return them from their own
+// validating webhooks.
+func SpecWarnings(spec *KairosConfigSpec, fldPath *field.Path) admission.Warnings {
+	return spec.warnings(fldPath)
+}
+
+// warnings flags settings that are valid but unsafe for production, like
+// passwords stored in plain text
+func (s *KairosConfigSpec) warnings(fldPath *field.Path) admission.Warnings {
+	var warnings admission.Warnings
+
+	switch {
+	case s.UserPassword != "":
+		warnings = append(warnings, fmt.Sprintf("%s stores the password in plain text; use %s or %s instead",
+			fldPath.Child("userPassword"), fldPath.Child("userPasswordSecretRef"), fldPath.Child("passwdHash")))
+	case s.UserPasswordSecretRef == nil && s.PasswdHash == "":
+		warnings = append(warnings, fmt.Sprintf("no password is set for the default user, it gets the development password \"kairos\"; set %s or %s",
+			fldPath.Child("userPasswordSecretRef"), fldPath.Child("passwdHash")))
+	}
+	for i, user := range s.Users {
+		if user.Password != "" && !isPasswordHash(user.Password) {
+			warnings = append(warnings, fmt.Sprintf("%s stores the password in plain text; use a crypt(3) hash instead",
+				fldPath.Child("users").Index(i).Child("password")))
+		}
+	}
+
+	return warnings
+}
+
 // validate performs validation on the KairosConfig spec
 func (r *KairosConfig) validate() error {
+	allErrs := r.Spec.validate(field.NewPath("spec"))
+
+	if len(allErrs) > 0 {
+		return errors.NewInvalid(
+			schema.GroupKind{Group: GroupVersion.Group, Kind: "KairosConfig"},
+			r.Name,
+			allErrs,
+		)
+	}
+
+	return nil
+}
+
+// validate checks a KairosConfig spec. fldPath is the path of the spec, so
+// KairosConfigTemplates report errors under spec.template.spec.
+func (s *KairosConfigSpec) validate(fldPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
 
 	// Validate role
-	if r.Spec.Role != "control-plane" && r.Spec.Role != "worker" {
+	if s.Role != "control-plane" && s.Role != "worker" {
 		allErrs = append(allErrs, field.Invalid(
-			field.NewPath("spec", "role"),
-			r.Spec.Role,
+			fldPath.Child("role"),
+			s.Role,
 			"spec.role must be one of [control-plane, worker]",
 		))
 	}
 
 	// Validate distribution
-	if r.Spec.Distribution != "" && r.Spec.Distribution != "k0s" && r.Spec.Distribution != "k3s" {
+	if s.Distribution != "" && s.Distribution != "k0s" && s.Distribution != "k3s" {
 		allErrs = append(allErrs, field.Invalid(
-			field.NewPath("spec", "distribution"),
-			r.Spec.Distribution,
+			fldPath.Child("distribution"),
+			s.Distribution,
 			"spec.distribution must be one of [k0s, k3s]",
 		))
 	}
 
-	// Validate worker token requirement
-	if r.Spec.Role == "worker" {
-		switch r.Spec.Distribution {
-		case "k3s":
-			hasK3sToken := r.Spec.K3sToken != ""
-			hasK3sTokenRef := r.Spec.K3sTokenSecretRef != nil && r.Spec.K3sTokenSecretRef.Name != ""
-			hasWorkerToken := r.Spec.WorkerToken != ""
-			hasWorkerTokenRef := r.Spec.WorkerTokenSecretRef != nil && r.Spec.WorkerTokenSecretRef.Name != ""
-			if !hasK3sToken && !hasK3sTokenRef && !hasWorkerToken && !hasWorkerTokenRef {
-				allErrs = append(allErrs, field.Required(
-					field.NewPath("spec", "k3sToken"),
-					"k3s worker requires spec.k3sToken, spec.k3sTokenSecretRef, spec.workerToken, or spec.workerTokenSecretRef to be set",
-				))
+	switch s.Format {
+	case "", CloudConfigFormat, ISOUserdataFormat, IgnitionWrappedFormat:
+	default:
+		allErrs = append(allErrs, field.NotSupported(
+			fldPath.Child("format"),
+			s.Format,
+			[]string{string(CloudConfigFormat), string(ISOUserdataFormat), string(IgnitionWrappedFormat)},
+		))
+	}
+
+	// Validate worker token requirement; k0s workers without a token get one
+	// provisioned from the workload cluster, P2P nodes coordinate over the VPN
+	if s.Role == "worker" && s.Distribution == "k3s" && s.P2P == nil {
+		hasK3sToken := s.K3sToken != ""
+		hasK3sTokenRef := s.K3sTokenSecretRef != nil && s.K3sTokenSecretRef.Name != ""
+		hasWorkerToken := s.WorkerToken != ""
+		hasWorkerTokenRef := s.WorkerTokenSecretRef != nil && s.WorkerTokenSecretRef.Name != ""
+		if !hasK3sToken && !hasK3sTokenRef && !hasWorkerToken && !hasWorkerTokenRef {
+			allErrs = append(allErrs, field.Required(
+				fldPath.Child("k3sToken"),
+				"k3s worker requires spec.k3sToken, spec.k3sTokenSecretRef, spec.workerToken, or spec.workerTokenSecretRef to be set",
+			))
+		}
+	}
+
+	// k3s join tokens are only read for k3s nodes
+	if s.Distribution == "k0s" {
+		if s.K3sToken != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("k3sToken"), "k3sToken is only supported with spec.distribution k3s"))
+		}
+		if s.K3sTokenSecretRef != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("k3sTokenSecretRef"), "k3sTokenSecretRef is only supported with spec.distribution k3s"))
+		}
+	}
+
+	// Single-node mode and controller join tokens only apply to control plane nodes
+	if s.SingleNode && s.Role != "control-plane" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("singleNode"), "singleNode is only supported for control-plane nodes"))
+	}
+	if s.ControllerTokenSecretRef != nil {
+		switch {
+		case s.Distribution != "k0s":
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("controllerTokenSecretRef"), "controllerTokenSecretRef is only supported with spec.distribution k0s"))
+		case s.Role != "control-plane":
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("controllerTokenSecretRef"), "controllerTokenSecretRef is only supported for control-plane nodes"))
+		case s.SingleNode:
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("controllerTokenSecretRef"), "a single-node control plane cannot join other controllers"))
+		}
+	}
+
+	// Workers serve no API server certificate
+	if len(s.CertSANs) > 0 && s.Role != "control-plane" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("certSANs"), "certSANs is only supported for control-plane nodes"))
+	} else {
+		allErrs = append(allErrs, ValidateCertSANs(s.CertSANs, fldPath.Child("certSANs"))...)
+	}
+
+	if s.OIDC != nil {
+		if s.Role != "control-plane" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("oidc"), "oidc is only supported for control-plane nodes"))
+		} else {
+			allErrs = append(allErrs, validateOIDC(fldPath.Child("oidc"), s.OIDC)...)
+		}
+	}
+
+	// Control plane nodes are upgraded by their KairosControlPlane so the
+	// upgrade is coordinated across all of them
+	if s.OSUpgrade != nil && s.Role != "worker" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("osUpgrade"), "osUpgrade is only supported for worker nodes; set spec.osUpgrade on the KairosControlPlane instead"))
+	}
+
+	if s.P2P != nil {
+		allErrs = append(allErrs, s.validateP2P(fldPath.Child("p2p"))...)
+	}
+
+	if s.K0sConfig != nil {
+		allErrs = append(allErrs, s.validateK0sConfig(fldPath.Child("k0sConfig"))...)
+	}
+
+	if s.Airgap != nil {
+		allErrs = append(allErrs, validateAirgap(fldPath.Child("airgap"), s.Airgap)...)
+	}
+
+	if s.Proxy != nil {
+		allErrs = append(allErrs, validateProxy(fldPath.Child("proxy"), s.Proxy)...)
+	}
+
+	for i, key := range s.SSHAuthorizedKeys {
+		if strings.TrimSpace(key) == "" || strings.ContainsAny(key, "\r\n") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("sshAuthorizedKeys").Index(i), key, "must be a single SSH public key"))
+		}
+	}
+	for i, ref := range s.SSHKeySecretRefs {
+		if ref.Name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("sshKeySecretRefs").Index(i).Child("name"), "name is required"))
+		}
+	}
+
+	allErrs = append(allErrs, s.validateUserPassword(fldPath)...)
+	allErrs = append(allErrs, s.validateHostnameTemplate(fldPath)...)
+	allErrs = append(allErrs, s.validateUsers(fldPath.Child("users"))...)
+
+	if s.Install != nil {
+		allErrs = append(allErrs, validateGrubOptions(fldPath.Child("install", "grubOptions"), s.Install.GrubOptions)...)
+	}
+
+	if s.TrustedBoot {
+		allErrs = append(allErrs, s.validateTrustedBoot(fldPath.Child("install"))...)
+	}
+
+	if s.Encryption != nil {
+		allErrs = append(allErrs, s.validateEncryption(fldPath)...)
+	}
+
+	if s.Network != nil {
+		allErrs = append(allErrs, validateNetwork(fldPath.Child("network"), s.Network)...)
+	}
+
+	for i, ref := range s.RegistryCredentials {
+		if ref.Name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("registryCredentials").Index(i).Child("name"), "name is required"))
+		}
+	}
+
+	allErrs = append(allErrs, validateKubeletExtraArgs(fldPath.Child("kubeletExtraArgs"), s.KubeletExtraArgs)...)
+
+	allErrs = append(allErrs, validateStages(fldPath.Child("stages"), s.Stages)...)
+
+	for i := range s.Files {
+		allErrs = append(allErrs, validateFile(fldPath.Child("files").Index(i), &s.Files[i])...)
+	}
+	for i := range s.Manifests {
+		allErrs = append(allErrs, validateManifest(fldPath.Child("manifests").Index(i), &s.Manifests[i])...)
+	}
+
+	return allErrs
+}
+
+// validateP2P checks spec.p2p
+func (s *KairosConfigSpec) validateP2P(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	p2p := s.P2P
+
+	if s.Distribution != "k3s" {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "p2p is only supported with spec.distribution k3s"))
+	}
+	hasToken := p2p.NetworkToken != ""
+	hasTokenRef := p2p.NetworkTokenSecretRef != nil && p2p.NetworkTokenSecretRef.Name != ""
+	if !hasToken && !hasTokenRef {
+		allErrs = append(allErrs, field.Required(fldPath.Child("networkToken"), "either networkToken or networkTokenSecretRef must be set"))
+	}
+	if hasToken && hasTokenRef {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("networkToken"), "networkToken and networkTokenSecretRef are mutually exclusive"))
+	}
+	if p2p.Auto != nil && p2p.Auto.HA != nil && p2p.Auto.HA.MasterNodes < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("auto", "ha", "masterNodes"), p2p.Auto.HA.MasterNodes, "must be non-negative"))
+	}
+
+	return allErrs
+}
+
+// validateK0sConfig checks spec.k0sConfig
+func (s *KairosConfigSpec) validateK0sConfig(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	k0sConfig := s.K0sConfig
+
+	if s.Distribution != "k0s" {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "k0sConfig is only supported with spec.distribution k0s"))
+	}
+	if k0sConfig.Config != nil && len(k0sConfig.Config.Raw) > 0 {
+		var config map[string]interface{}
+		if err := json.Unmarshal(k0sConfig.Config.Raw, &config); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("config"), string(k0sConfig.Config.Raw), "must be a k0s ClusterConfig object"))
+		} else if kind, ok := config["kind"]; ok && kind != "ClusterConfig" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("config", "kind"), kind, "must be ClusterConfig"))
+		}
+	}
+	if k0sConfig.Storage != nil && k0sConfig.Storage.KineDataSource != "" && k0sConfig.Storage.Type != "kine" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("storage", "kineDataSource"), "kineDataSource requires storage type kine"))
+	}
+	if k0sConfig.Extensions != nil && k0sConfig.Extensions.Helm != nil {
+		allErrs = append(allErrs, validateK0sHelmExtension(fldPath.Child("extensions", "helm"), k0sConfig.Extensions.Helm)...)
+	}
+
+	return allErrs
+}
+
+// validateK0sHelmExtension checks spec.k0sConfig.extensions.helm
+func validateK0sHelmExtension(fldPath *field.Path, helm *K0sHelmExtension) field.ErrorList {
+	var allErrs field.ErrorList
+
+	repositories := map[string]bool{}
+	for i, repo := range helm.Repositories {
+		if repositories[repo.Name] {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("repositories").Index(i).Child("name"), repo.Name))
+		}
+		repositories[repo.Name] = true
+	}
+
+	charts := map[string]bool{}
+	for i, chart := range helm.Charts {
+		chartPath := fldPath.Child("charts").Index(i)
+		if charts[chart.Name] {
+			allErrs = append(allErrs, field.Duplicate(chartPath.Child("name"), chart.Name))
+		}
+		charts[chart.Name] = true
+		for _, msg := range validation.IsDNS1123Label(chart.Namespace) {
+			allErrs = append(allErrs, field.Invalid(chartPath.Child("namespace"), chart.Namespace, msg))
+		}
+		if chart.Values != "" {
+			var values map[string]interface{}
+			if err := yaml.Unmarshal([]byte(chart.Values), &values); err != nil {
+				allErrs = append(allErrs, field.Invalid(chartPath.Child("values"), chart.Values, fmt.Sprintf("must be a YAML object: %v", err)))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validateAirgap checks spec.airgap
+func validateAirgap(fldPath *field.Path, airgap *AirgapConfig) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for i, bundle := range airgap.ImageBundles {
+		if !strings.HasPrefix(bundle, "/") || strings.ContainsAny(bundle, "\"\n") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("imageBundles").Index(i), bundle, "must be an absolute path"))
+		}
+	}
+
+	registries := map[string]bool{}
+	for i, mirror := range airgap.Mirrors {
+		mirrorPath := fldPath.Child("mirrors").Index(i)
+		switch {
+		case mirror.Registry == "":
+			allErrs = append(allErrs, field.Required(mirrorPath.Child("registry"), "registry is required"))
+		case mirror.Registry == "." || mirror.Registry == ".." || strings.ContainsAny(mirror.Registry, "/ \t\n\"'"):
+			allErrs = append(allErrs, field.Invalid(mirrorPath.Child("registry"), mirror.Registry, "must be a registry host, e.g. docker.io"))
+		case registries[mirror.Registry]:
+			allErrs = append(allErrs, field.Duplicate(mirrorPath.Child("registry"), mirror.Registry))
+		}
+		registries[mirror.Registry] = true
+
+		if len(mirror.Endpoints) == 0 {
+			allErrs = append(allErrs, field.Required(mirrorPath.Child("endpoints"), "at least one endpoint is required"))
+		}
+		for j, endpoint := range mirror.Endpoints {
+			if !isHTTPURL(endpoint) || strings.ContainsAny(endpoint, "\"\n") {
+				allErrs = append(allErrs, field.Invalid(mirrorPath.Child("endpoints").Index(j), endpoint, "must be an http or https URL"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validateProxy checks spec.proxy. The values are rendered into quoted
+// systemd Environment= assignments.
+func validateProxy(fldPath *field.Path, proxy *ProxyConfig) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for _, p := range []struct{ name, value string }{
+		{"httpProxy", proxy.HTTPProxy},
+		{"httpsProxy", proxy.HTTPSProxy},
+	} {
+		if p.value != "" && (!isHTTPURL(p.value) || strings.ContainsAny(p.value, "\"\\\n")) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(p.name), p.value, "must be an http or https URL"))
+		}
+	}
+	if strings.ContainsAny(proxy.NoProxy, " \t\"\\\n") {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("noProxy"), proxy.NoProxy, "must be a comma-separated list without whitespace or quotes"))
+	}
+
+	return allErrs
+}
+
+// validateOIDC checks spec.oidc. The values become API server flags on the
+// k0s/k3s service command line, and lists are comma-separated.
+func validateOIDC(fldPath *field.Path, oidc *OIDCConfig) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if u, err := url.Parse(oidc.IssuerURL); err != nil || u.Scheme != "https" || u.Host == "" || strings.ContainsAny(oidc.IssuerURL, " \t\n'\"\\") {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("issuerURL"), oidc.IssuerURL, "must be an https URL"))
+	}
+	if oidc.ClientID == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("clientID"), "clientID is required"))
+	}
+	for _, f := range []struct{ name, value string }{
+		{"clientID", oidc.ClientID},
+		{"usernameClaim", oidc.UsernameClaim},
+		{"usernamePrefix", oidc.UsernamePrefix},
+		{"groupsClaim", oidc.GroupsClaim},
+		{"groupsPrefix", oidc.GroupsPrefix},
+		{"caFile", oidc.CAFile},
+	} {
+		if strings.ContainsAny(f.value, " \t\n'\"\\") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(f.name), f.value, "must not contain whitespace, quotes or backslashes"))
+		}
+	}
+	if oidc.CAFile != "" && !strings.HasPrefix(oidc.CAFile, "/") {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("caFile"), oidc.CAFile, "must be an absolute path"))
+	}
+
+	claims := make([]string, 0, len(oidc.RequiredClaims))
+	for claim := range oidc.RequiredClaims {
+		claims = append(claims, claim)
+	}
+	sort.Strings(claims)
+	for _, claim := range claims {
+		if claim == "" || strings.ContainsAny(claim, ",= \t\n'\"\\") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("requiredClaims"), claim, "keys must be claim names without commas, equal signs, whitespace or quotes"))
+		} else if strings.ContainsAny(oidc.RequiredClaims[claim], ", \t\n'\"\\") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("requiredClaims").Key(claim), oidc.RequiredClaims[claim], "must not contain commas, whitespace or quotes"))
+		}
+	}
+	for i, alg := range oidc.SigningAlgorithms {
+		if alg == "" || strings.ContainsAny(alg, ", \t\n'\"\\") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("signingAlgorithms").Index(i), alg, "must be a JWS algorithm name, e.g. RS256"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateUsers checks spec.users. The default user and the capk user are
+// rendered by the controller and cannot be redefined.
+func (s *KairosConfigSpec) validateUsers(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	names := map[string]bool{}
+	for i, user := range s.Users {
+		userPath := fldPath.Index(i)
+		switch {
+		case !isUserName(user.Name):
+			allErrs = append(allErrs, field.Invalid(userPath.Child("name"), user.Name, "must be a lowercase user name"))
+		case user.Name == s.UserName || user.Name == "capk":
+			allErrs = append(allErrs, field.Forbidden(userPath.Child("name"), user.Name+" is created by the controller"))
+		case names[user.Name]:
+			allErrs = append(allErrs, field.Duplicate(userPath.Child("name"), user.Name))
+		}
+		names[user.Name] = true
+
+		for j, group := range user.Groups {
+			if !isUserName(group) {
+				allErrs = append(allErrs, field.Invalid(userPath.Child("groups").Index(j), group, "must be a lowercase group name"))
+			}
+		}
+		for j, key := range user.SSHAuthorizedKeys {
+			if strings.TrimSpace(key) == "" || strings.ContainsAny(key, "\r\n") {
+				allErrs = append(allErrs, field.Invalid(userPath.Child("sshAuthorizedKeys").Index(j), key, "must be a single SSH public key"))
 			}
+		}
+		if strings.ContainsAny(user.Password, "\r\n") {
+			allErrs = append(allErrs, field.Invalid(userPath.Child("password"), "", "must not contain line breaks"))
+		}
+		if strings.ContainsAny(user.Sudo, "\r\n") {
+			allErrs = append(allErrs, field.Invalid(userPath.Child("sudo"), user.Sudo, "must be a single sudoers rule"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateUserPassword checks that the password of the default user comes from
+// a single source
+func (s *KairosConfigSpec) validateUserPassword(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	var sources []string
+	if s.UserPassword != "" {
+		sources = append(sources, "userPassword")
+	}
+	if s.UserPasswordSecretRef != nil {
+		sources = append(sources, "userPasswordSecretRef")
+	}
+	if s.PasswdHash != "" {
+		sources = append(sources, "passwdHash")
+	}
+	if len(sources) > 1 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child(sources[1]), "only one of userPassword, userPasswordSecretRef and passwdHash may be set"))
+	}
+
+	if strings.ContainsAny(s.UserPassword, "\r\n") {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("userPassword"), "", "must not contain line breaks"))
+	}
+	if s.UserPasswordSecretRef != nil && s.UserPasswordSecretRef.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("userPasswordSecretRef", "name"), "name is required"))
+	}
+	if s.PasswdHash != "" && !isPasswordHash(s.PasswdHash) {
+		// Never echo the value back, it may be a mistyped plain text password
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("passwdHash"), "", "must be a crypt(3) hash like $6$<salt>$<hash>"))
+	}
+
+	return allErrs
+}
+
+// validateHostnameTemplate checks that hostnameTemplate only uses known
+// tokens and otherwise holds hostname characters
+func (s *KairosConfigSpec) validateHostnameTemplate(fldPath *field.Path) field.ErrorList {
+	if s.HostnameTemplate == "" {
+		return nil
+	}
+	var allErrs field.ErrorList
+	templatePath := fldPath.Child("hostnameTemplate")
+
+	if s.Hostname != "" {
+		allErrs = append(allErrs, field.Forbidden(templatePath, "hostnameTemplate cannot be set together with hostname"))
+	}
+
+	// The hostname is written unquoted to the cloud-config, where a leading
+	// {{ would start a YAML flow mapping
+	if strings.HasPrefix(s.HostnameTemplate, "{{") {
+		allErrs = append(allErrs, field.Invalid(templatePath, s.HostnameTemplate,
+			"must not start with a Kairos template expression, add a prefix like node-{{ trunc 4 .MachineID }}"))
+	}
+
+	// Kairos expressions are rendered on the node, the tokens by the controller
+	rest := kairosTemplatePattern.ReplaceAllString(s.HostnameTemplate, "x")
+	for _, token := range hostnameTokenPattern.FindAllString(rest, -1) {
+		switch token {
+		case HostnameMachineNameToken, HostnameClusterNameToken, HostnameNamespaceToken:
 		default:
-			hasToken := r.Spec.WorkerToken != ""
-			hasTokenRef := r.Spec.WorkerTokenSecretRef != nil && r.Spec.WorkerTokenSecretRef.Name != ""
-			if !hasToken && !hasTokenRef {
-				allErrs = append(allErrs, field.Required(
-					field.NewPath("spec", "workerToken"),
-					"worker KairosConfig requires either spec.workerToken or spec.workerTokenSecretRef to be set",
-				))
+			allErrs = append(allErrs, field.Invalid(templatePath, s.HostnameTemplate,
+				fmt.Sprintf("unknown token %s, supported tokens are %s, %s and %s", token, HostnameMachineNameToken, HostnameClusterNameToken, HostnameNamespaceToken)))
+		}
+	}
+	rest = hostnameTokenPattern.ReplaceAllString(rest, "x")
+	if errs := validation.IsDNS1123Subdomain(rest); len(errs) > 0 {
+		allErrs = append(allErrs, field.Invalid(templatePath, s.HostnameTemplate,
+			"must render a lowercase hostname: "+strings.Join(errs, ", ")))
+	}
+
+	return allErrs
+}
+
+// isPasswordHash reports whether s looks like a crypt(3) password hash in the
+// modular "$id$..." format
+func isPasswordHash(s string) bool {
+	return strings.HasPrefix(s, "$") && strings.Count(s, "$") >= 3 && !strings.ContainsAny(s, " \t\r\n:")
+}
+
+// isUserName reports whether s is a portable Linux user or group name
+func isUserName(s string) bool {
+	if s == "" || len(s) > 32 || s[0] == '-' || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for _, c := range s {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// validateTrustedBoot rejects spec.install options that UKI images cannot
+// honor: the signed kernel command line ignores the grub environment, and
+// the system lives on the EFI partition rather than recovery and state.
+func (s *KairosConfigSpec) validateTrustedBoot(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	install := s.Install
+	if install == nil {
+		return allErrs
+	}
+
+	if len(install.GrubOptions) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("grubOptions"), "grub options are not supported with spec.trustedBoot"))
+	}
+	if install.Partitions != nil {
+		if install.Partitions.Recovery != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("partitions", "recovery"), "Trusted Boot images have no recovery partition"))
+		}
+		if install.Partitions.State != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("partitions", "state"), "Trusted Boot images have no state partition"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateEncryption checks spec.encryption. kcrypt encrypts partitions
+// during installation; the partitions needed to boot must stay readable.
+// specPath is the path of the spec.
+func (s *KairosConfigSpec) validateEncryption(specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	encryption := s.Encryption
+	fldPath := specPath.Child("encryption")
+
+	if s.Install == nil {
+		allErrs = append(allErrs, field.Required(specPath.Child("install"), "spec.encryption requires spec.install"))
+	}
+
+	labels := map[string]bool{}
+	for i, label := range encryption.Partitions {
+		labelPath := fldPath.Child("partitions").Index(i)
+		switch {
+		case label == "" || len(label) > 16 || strings.ContainsAny(label, " \t\n\"'\\/"):
+			allErrs = append(allErrs, field.Invalid(labelPath, label, "must be a filesystem label"))
+		case label == "COS_STATE" || label == "COS_RECOVERY" || label == "COS_GRUB":
+			allErrs = append(allErrs, field.Forbidden(labelPath, label+" is needed to boot and cannot be encrypted"))
+		case labels[label]:
+			allErrs = append(allErrs, field.Duplicate(labelPath, label))
+		}
+		labels[label] = true
+	}
+
+	if encryption.ChallengerServer != "" && (!isHTTPURL(encryption.ChallengerServer) || strings.ContainsAny(encryption.ChallengerServer, "\"\\\n")) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("challengerServer"), encryption.ChallengerServer, "must be an http or https URL"))
+	}
+	if encryption.MDNS && encryption.ChallengerServer == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("challengerServer"), "mdns requires a challenger server"))
+	}
+
+	if tpm := encryption.TPM; tpm != nil {
+		tpmPath := fldPath.Child("tpm")
+		for _, index := range []struct{ name, value string }{
+			{"nvIndex", tpm.NVIndex},
+			{"cIndex", tpm.CIndex},
+		} {
+			if index.value != "" && !isHexIndex(index.value) {
+				allErrs = append(allErrs, field.Invalid(tpmPath.Child(index.name), index.value, "must be a hexadecimal TPM index, e.g. 0x1500000"))
 			}
 		}
+		if tpm.Device != "" && (!strings.HasPrefix(tpm.Device, "/dev/") || strings.ContainsAny(tpm.Device, " \t\n\"\\")) {
+			allErrs = append(allErrs, field.Invalid(tpmPath.Child("device"), tpm.Device, "must be a device path, e.g. /dev/tpmrm0"))
+		}
 	}
 
-	if len(allErrs) > 0 {
-		return errors.NewInvalid(
-			schema.GroupKind{Group: GroupVersion.Group, Kind: "KairosConfig"},
-			r.Name,
-			allErrs,
-		)
+	return allErrs
+}
+
+// isFileMode reports whether s is an octal file mode of three or four digits
+func isFileMode(s string) bool {
+	if len(s) < 3 || len(s) > 4 {
+		return false
 	}
+	for _, c := range s {
+		if c < '0' || c > '7' {
+			return false
+		}
+	}
+	return true
+}
 
-	return nil
+// isHexIndex reports whether s is a 0x-prefixed hexadecimal number
+func isHexIndex(s string) bool {
+	if !strings.HasPrefix(s, "0x") || len(s) == 2 {
+		return false
+	}
+	for _, c := range s[2:] {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// validateGrubOptions checks spec.install.grubOptions. The options are stored
+// in the grub environment block, which holds one name=value pair per line.
+func validateGrubOptions(fldPath *field.Path, options map[string]string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !isGrubVariable(key) {
+			allErrs = append(allErrs, field.Invalid(fldPath, key, "keys must be grub variable names of letters, digits and underscores"))
+		} else if strings.ContainsAny(options[key], "\r\n") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(key), options[key], "must not contain line breaks"))
+		}
+	}
+
+	return allErrs
+}
+
+// isGrubVariable reports whether s is a valid grub environment variable name
+func isGrubVariable(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for _, c := range s {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// validateNetwork checks spec.network
+func validateNetwork(fldPath *field.Path, network *NetworkConfig) field.ErrorList {
+	var allErrs field.ErrorList
+
+	links := map[string]bool{}
+	bondMembers := map[string]bool{}
+	for i, bond := range network.Bonds {
+		bondPath := fldPath.Child("bonds").Index(i)
+		allErrs = append(allErrs, validateLinkName(bondPath.Child("name"), bond.Name, links)...)
+		if len(bond.Interfaces) == 0 {
+			allErrs = append(allErrs, field.Required(bondPath.Child("interfaces"), "at least one interface is required"))
+		}
+		for j, member := range bond.Interfaces {
+			memberPath := bondPath.Child("interfaces").Index(j)
+			if !isLinkName(member) {
+				allErrs = append(allErrs, field.Invalid(memberPath, member, "must be a network interface name"))
+			} else if bondMembers[member] {
+				allErrs = append(allErrs, field.Duplicate(memberPath, member))
+			}
+			bondMembers[member] = true
+		}
+	}
+	for i, vlan := range network.VLANs {
+		vlanPath := fldPath.Child("vlans").Index(i)
+		allErrs = append(allErrs, validateLinkName(vlanPath.Child("name"), vlan.Name, links)...)
+		if vlan.ID < 1 || vlan.ID > 4094 {
+			allErrs = append(allErrs, field.Invalid(vlanPath.Child("id"), vlan.ID, "must be between 1 and 4094"))
+		}
+		if !isLinkName(vlan.Link) {
+			allErrs = append(allErrs, field.Invalid(vlanPath.Child("link"), vlan.Link, "must be a network interface name"))
+		} else if bondMembers[vlan.Link] {
+			allErrs = append(allErrs, field.Forbidden(vlanPath.Child("link"), "bond members cannot carry VLANs; use the bond"))
+		}
+	}
+
+	interfaces := map[string]bool{}
+	for i, iface := range network.Interfaces {
+		ifacePath := fldPath.Child("interfaces").Index(i)
+		if !isLinkName(iface.Name) {
+			allErrs = append(allErrs, field.Invalid(ifacePath.Child("name"), iface.Name, "must be a network interface name"))
+		} else if interfaces[iface.Name] {
+			allErrs = append(allErrs, field.Duplicate(ifacePath.Child("name"), iface.Name))
+		} else if bondMembers[iface.Name] {
+			allErrs = append(allErrs, field.Forbidden(ifacePath.Child("name"), "bond members are configured through their bond"))
+		}
+		interfaces[iface.Name] = true
+
+		if iface.MACAddress != "" {
+			if _, err := net.ParseMAC(iface.MACAddress); err != nil {
+				allErrs = append(allErrs, field.Invalid(ifacePath.Child("macAddress"), iface.MACAddress, "must be a MAC address"))
+			}
+		}
+		for j, address := range iface.Addresses {
+			if _, _, err := net.ParseCIDR(address); err != nil {
+				allErrs = append(allErrs, field.Invalid(ifacePath.Child("addresses").Index(j), address, "must be an address in CIDR notation"))
+			}
+		}
+		if iface.Gateway != "" && net.ParseIP(iface.Gateway) == nil {
+			allErrs = append(allErrs, field.Invalid(ifacePath.Child("gateway"), iface.Gateway, "must be an IP address"))
+		}
+		for j, dns := range iface.DNSServers {
+			if net.ParseIP(dns) == nil {
+				allErrs = append(allErrs, field.Invalid(ifacePath.Child("dnsServers").Index(j), dns, "must be an IP address"))
+			}
+		}
+		if iface.MTU != 0 && iface.MTU < 68 {
+			allErrs = append(allErrs, field.Invalid(ifacePath.Child("mtu"), iface.MTU, "must be at least 68"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateLinkName checks the name of a link created by spec.network and
+// records it in names
+func validateLinkName(fldPath *field.Path, name string, names map[string]bool) field.ErrorList {
+	var allErrs field.ErrorList
+	if !isLinkName(name) {
+		allErrs = append(allErrs, field.Invalid(fldPath, name, "must be a network interface name"))
+	} else if names[name] {
+		allErrs = append(allErrs, field.Duplicate(fldPath, name))
+	}
+	names[name] = true
+	return allErrs
+}
+
+// isLinkName reports whether s is a valid Linux network interface name
+func isLinkName(s string) bool {
+	return s != "" && s != "." && s != ".." && len(s) <= 15 && !strings.ContainsAny(s, "/: \t\n\"'")
+}
+
+// validateKubeletExtraArgs checks spec.kubeletExtraArgs. The args end up
+// single-quoted on the k0s/k3s service command line, so quotes, backslashes
+// and line breaks are rejected.
+func validateKubeletExtraArgs(fldPath *field.Path, args map[string]string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch {
+		case key == "" || strings.HasPrefix(key, "-") || strings.ContainsAny(key, "= \t\n'\"\\"):
+			allErrs = append(allErrs, field.Invalid(fldPath, key, "keys must be kubelet flag names without leading dashes"))
+		case key == "provider-id":
+			allErrs = append(allErrs, field.Forbidden(fldPath.Key(key), "provider-id is set by the controller"))
+		case strings.ContainsAny(args[key], "\n'\"\\"):
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(key), args[key], "must not contain quotes, backslashes or line breaks"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateStages checks spec.stages. Stage names are rendered as cloud-config
+// keys and every step must be an object.
+func validateStages(fldPath *field.Path, stages map[string][]runtime.RawExtension) field.ErrorList {
+	var allErrs field.ErrorList
+
+	names := make([]string, 0, len(stages))
+	for name := range stages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !isStageName(name) {
+			allErrs = append(allErrs, field.Invalid(fldPath, name, "must be a Kairos stage name, e.g. boot, after-install or boot.before"))
+			continue
+		}
+		for i, step := range stages[name] {
+			var fields map[string]interface{}
+			if err := json.Unmarshal(step.Raw, &fields); err != nil || fields == nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Key(name).Index(i), string(step.Raw), "must be an object"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// isStageName reports whether s is a stage name of lowercase words joined
+// by dashes and dots, e.g. "after-install" or "boot.before"
+func isStageName(s string) bool {
+	for _, part := range strings.Split(s, ".") {
+		if part == "" || part[0] == '-' || part[len(part)-1] == '-' {
+			return false
+		}
+		for _, c := range part {
+			if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validateFile checks a single spec.files entry
+func validateFile(fldPath *field.Path, file *File) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if file.Path == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("path"), "path is required"))
+	} else if !strings.HasPrefix(file.Path, "/") {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("path"), file.Path, "path must be absolute"))
+	}
+
+	if file.Permissions != "" && !isFileMode(file.Permissions) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("permissions"), file.Permissions, "must be an octal file mode, e.g. 0644"))
+	}
+
+	switch file.Encoding {
+	case "", Base64FileEncoding, GzipBase64FileEncoding:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("encoding"), file.Encoding,
+			[]string{string(Base64FileEncoding), string(GzipBase64FileEncoding)}))
+	}
+
+	source := file.ContentFrom
+	if source == nil {
+		return allErrs
+	}
+	sourcePath := fldPath.Child("contentFrom")
+	if file.Content != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("content"), "content and contentFrom are mutually exclusive"))
+	}
+	sources := 0
+	if source.Secret != nil {
+		sources++
+		if source.Secret.Name == "" || source.Secret.Key == "" {
+			allErrs = append(allErrs, field.Required(sourcePath.Child("secret"), "name and key are required"))
+		}
+	}
+	if source.ConfigMap != nil {
+		sources++
+		if source.ConfigMap.Name == "" || source.ConfigMap.Key == "" {
+			allErrs = append(allErrs, field.Required(sourcePath.Child("configMap"), "name and key are required"))
+		}
+	}
+	if source.URL != "" {
+		sources++
+		if !isHTTPURL(source.URL) {
+			allErrs = append(allErrs, field.Invalid(sourcePath.Child("url"), source.URL, "url must be an http or https URL"))
+		}
+		if file.Encoding != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("encoding"), "encoding is not supported with contentFrom.url"))
+		}
+	}
+	if sources != 1 {
+		allErrs = append(allErrs, field.Invalid(sourcePath, "", "exactly one of secret, configMap or url must be set"))
+	}
+
+	return allErrs
+}
+
+// ValidateManifests checks manifests added to the bootstrap configuration by
+// a control plane, and that no two of them are written to the same file.
+func ValidateManifests(manifests []Manifest, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	seen := map[string]bool{}
+	for i := range manifests {
+		allErrs = append(allErrs, validateManifest(fldPath.Index(i), &manifests[i])...)
+		path := manifests[i].Name + "/" + manifests[i].File
+		if seen[path] {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i), path))
+		}
+		seen[path] = true
+	}
+	return allErrs
+}
+
+// validateManifest checks a single spec.manifests entry
+func validateManifest(fldPath *field.Path, manifest *Manifest) field.ErrorList {
+	var allErrs field.ErrorList
+
+	source := manifest.ContentFrom
+	if source == nil {
+		if manifest.Content == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("content"), "content or contentFrom is required"))
+		}
+		return allErrs
+	}
+	sourcePath := fldPath.Child("contentFrom")
+	if manifest.Content != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("content"), "content and contentFrom are mutually exclusive"))
+	}
+	sources := 0
+	if source.Secret != nil {
+		sources++
+		if source.Secret.Name == "" || source.Secret.Key == "" {
+			allErrs = append(allErrs, field.Required(sourcePath.Child("secret"), "name and key are required"))
+		}
+	}
+	if source.ConfigMap != nil {
+		sources++
+		if source.ConfigMap.Name == "" || source.ConfigMap.Key == "" {
+			allErrs = append(allErrs, field.Required(sourcePath.Child("configMap"), "name and key are required"))
+		}
+	}
+	if sources != 1 {
+		allErrs = append(allErrs, field.Invalid(sourcePath, "", "exactly one of secret or configMap must be set"))
+	}
+
+	return allErrs
+}
+
+// isHTTPURL reports whether s is an absolute http or https URL
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
 }
@@ -0,0 +1,286 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKairosConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    KairosConfigSpec
+		wantErr string
+	}{
+		{
+			name: "k0s worker without token",
+			spec: KairosConfigSpec{Role: "worker", Distribution: "k0s"},
+		},
+		{
+			name:    "k3s worker without token",
+			spec:    KairosConfigSpec{Role: "worker", Distribution: "k3s"},
+			wantErr: "spec.k3sToken: Required value",
+		},
+		{
+			name:    "k3s token with k0s",
+			spec:    KairosConfigSpec{Role: "worker", Distribution: "k0s", K3sToken: "token"},
+			wantErr: "spec.k3sToken: Forbidden",
+		},
+		{
+			name:    "single-node worker",
+			spec:    KairosConfigSpec{Role: "worker", SingleNode: true},
+			wantErr: "spec.singleNode: Forbidden",
+		},
+		{
+			name: "worker osUpgrade",
+			spec: KairosConfigSpec{Role: "worker", OSUpgrade: &OSUpgrade{Image: "quay.io/kairos/ubuntu", Version: "v3.5.0"}},
+		},
+		{
+			name:    "control-plane osUpgrade",
+			spec:    KairosConfigSpec{Role: "control-plane", OSUpgrade: &OSUpgrade{Image: "quay.io/kairos/ubuntu", Version: "v3.5.0"}},
+			wantErr: "spec.osUpgrade: Forbidden",
+		},
+		{
+			name: "single-node controller joining",
+			spec: KairosConfigSpec{
+				Role:                     "control-plane",
+				SingleNode:               true,
+				ControllerTokenSecretRef: &WorkerTokenSecretReference{Name: "join"},
+			},
+			wantErr: "spec.controllerTokenSecretRef: Forbidden",
+		},
+		{
+			name:    "malformed file permissions",
+			spec:    KairosConfigSpec{Role: "worker", Files: []File{{Path: "/etc/motd", Content: "hi", Permissions: "rw-r--r--"}}},
+			wantErr: "spec.files[0].permissions: Invalid value",
+		},
+		{
+			name: "valid file permissions",
+			spec: KairosConfigSpec{Role: "worker", Files: []File{{Path: "/usr/local/bin/hook", Content: "#!/bin/sh", Permissions: "0755"}}},
+		},
+		{
+			name: "ignition-wrapped format",
+			spec: KairosConfigSpec{Role: "worker", Format: IgnitionWrappedFormat},
+		},
+		{
+			name:    "unknown format",
+			spec:    KairosConfigSpec{Role: "worker", Format: "ignition"},
+			wantErr: "spec.format: Unsupported value",
+		},
+		{
+			name: "password hash",
+			spec: KairosConfigSpec{Role: "worker", PasswdHash: "$6$salt$hash"},
+		},
+		{
+			name:    "plain text password hash",
+			spec:    KairosConfigSpec{Role: "worker", PasswdHash: "secret"},
+			wantErr: "spec.passwdHash: Invalid value",
+		},
+		{
+			name: "password from two sources",
+			spec: KairosConfigSpec{
+				Role:                  "worker",
+				UserPassword:          "secret",
+				UserPasswordSecretRef: &UserPasswordSecretReference{Name: "password"},
+			},
+			wantErr: "spec.userPasswordSecretRef: Forbidden",
+		},
+		{
+			name: "hostname template",
+			spec: KairosConfigSpec{Role: "worker", HostnameTemplate: "{cluster-name}-{machine-name}-{{ trunc 4 .MachineID }}"},
+		},
+		{
+			name:    "hostname template with unknown token",
+			spec:    KairosConfigSpec{Role: "worker", HostnameTemplate: "{site}-{machine-name}"},
+			wantErr: "unknown token {site}",
+		},
+		{
+			name:    "hostname template with invalid characters",
+			spec:    KairosConfigSpec{Role: "worker", HostnameTemplate: "Node_{machine-name}"},
+			wantErr: "spec.hostnameTemplate: Invalid value",
+		},
+		{
+			name:    "hostname template starting with a Kairos expression",
+			spec:    KairosConfigSpec{Role: "worker", HostnameTemplate: "{{ trunc 4 .MachineID }}"},
+			wantErr: "must not start with a Kairos template expression",
+		},
+		{
+			name: "manifest from a configmap",
+			spec: KairosConfigSpec{Role: "control-plane", Manifests: []Manifest{{
+				Name: "cni", File: "calico.yaml", ContentFrom: &ManifestSource{ConfigMap: &FileKeySelector{Name: "calico", Key: "calico.yaml"}},
+			}}},
+		},
+		{
+			name:    "manifest without content",
+			spec:    KairosConfigSpec{Role: "control-plane", Manifests: []Manifest{{Name: "cni", File: "calico.yaml"}}},
+			wantErr: "spec.manifests[0].content: Required value",
+		},
+		{
+			name: "manifest with content and contentFrom",
+			spec: KairosConfigSpec{Role: "control-plane", Manifests: []Manifest{{
+				Name: "cni", File: "calico.yaml", Content: "kind: List",
+				ContentFrom: &ManifestSource{Secret: &FileKeySelector{Name: "calico", Key: "calico.yaml"}},
+			}}},
+			wantErr: "spec.manifests[0].content: Forbidden",
+		},
+		{
+			name: "k0s helm extension",
+			spec: KairosConfigSpec{Role: "control-plane", Distribution: "k0s", K0sConfig: &K0sConfig{Extensions: &K0sExtensions{Helm: &K0sHelmExtension{
+				Repositories: []K0sHelmRepository{{Name: "metallb", URL: "https://metallb.github.io/metallb"}},
+				Charts:       []K0sHelmChart{{Name: "metallb", ChartName: "metallb/metallb", Namespace: "metallb-system", Values: "speaker:\n  enabled: true\n"}},
+			}}}},
+		},
+		{
+			name: "k0s helm chart with invalid values",
+			spec: KairosConfigSpec{Role: "control-plane", Distribution: "k0s", K0sConfig: &K0sConfig{Extensions: &K0sExtensions{Helm: &K0sHelmExtension{
+				Charts: []K0sHelmChart{{Name: "metallb", ChartName: "metallb/metallb", Namespace: "metallb-system", Values: "- speaker"}},
+			}}}},
+			wantErr: "spec.k0sConfig.extensions.helm.charts[0].values: Invalid value",
+		},
+		{
+			name: "duplicate k0s helm charts",
+			spec: KairosConfigSpec{Role: "control-plane", Distribution: "k0s", K0sConfig: &K0sConfig{Extensions: &K0sExtensions{Helm: &K0sHelmExtension{
+				Charts: []K0sHelmChart{
+					{Name: "metallb", ChartName: "metallb/metallb", Namespace: "metallb-system"},
+					{Name: "metallb", ChartName: "metallb/metallb", Namespace: "default"},
+				},
+			}}}},
+			wantErr: "spec.k0sConfig.extensions.helm.charts[1].name: Duplicate value",
+		},
+		{
+			name: "control-plane certSANs",
+			spec: KairosConfigSpec{Role: "control-plane", CertSANs: []string{"api.example.com", "*.apps.example.com", "192.0.2.10", "2001:db8::1"}},
+		},
+		{
+			name:    "worker certSANs",
+			spec:    KairosConfigSpec{Role: "worker", CertSANs: []string{"api.example.com"}},
+			wantErr: "spec.certSANs: Forbidden",
+		},
+		{
+			name:    "invalid certSAN",
+			spec:    KairosConfigSpec{Role: "control-plane", CertSANs: []string{"api.example.com", "https://api.example.com"}},
+			wantErr: "spec.certSANs[1]: Invalid value",
+		},
+		{
+			name: "control-plane oidc",
+			spec: KairosConfigSpec{Role: "control-plane", OIDC: &OIDCConfig{
+				IssuerURL:         "https://dex.example.com/issuer",
+				ClientID:          "kubernetes",
+				UsernamePrefix:    "oidc:",
+				RequiredClaims:    map[string]string{"hd": "example.com"},
+				SigningAlgorithms: []string{"RS256"},
+				CAFile:            "/etc/kubernetes/oidc-ca.crt",
+			}},
+		},
+		{
+			name:    "worker oidc",
+			spec:    KairosConfigSpec{Role: "worker", OIDC: &OIDCConfig{IssuerURL: "https://dex.example.com", ClientID: "kubernetes"}},
+			wantErr: "spec.oidc: Forbidden",
+		},
+		{
+			name:    "oidc http issuer",
+			spec:    KairosConfigSpec{Role: "control-plane", OIDC: &OIDCConfig{IssuerURL: "http://dex.example.com", ClientID: "kubernetes"}},
+			wantErr: "spec.oidc.issuerURL: Invalid value",
+		},
+		{
+			name:    "oidc required claim with comma",
+			spec:    KairosConfigSpec{Role: "control-plane", OIDC: &OIDCConfig{IssuerURL: "https://dex.example.com", ClientID: "kubernetes", RequiredClaims: map[string]string{"aud": "a,b"}}},
+			wantErr: "spec.oidc.requiredClaims[aud]: Invalid value",
+		},
+		{
+			name:    "hostname and hostname template",
+			spec:    KairosConfigSpec{Role: "worker", Hostname: "node", HostnameTemplate: "{machine-name}"},
+			wantErr: "spec.hostnameTemplate: Forbidden",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&KairosConfig{Spec: tt.spec}).Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Expected no error, got %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestKairosConfigTemplateValidate(t *testing.T) {
+	template := &KairosConfigTemplate{}
+	template.Spec.Template.Spec = KairosConfigSpec{Distribution: "k3s"}
+
+	// Templated workers need a token source just like KairosConfigs
+	err := template.validate()
+	if err == nil || !strings.Contains(err.Error(), "spec.template.spec.k3sToken: Required value") {
+		t.Errorf("Expected a k3sToken error under spec.template.spec, got %v", err)
+	}
+
+	template.Spec.Template.Spec.K3sTokenSecretRef = &WorkerTokenSecretReference{Name: "k3s-token"}
+	if err := template.validate(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if template.Spec.Template.Spec.Role != "" {
+		t.Error("Validation must not default the stored template")
+	}
+}
+
+func TestKairosConfigWarnings(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  KairosConfigSpec
+		wants []string
+	}{
+		{
+			name:  "plain text password",
+			spec:  KairosConfigSpec{UserPassword: "secret"},
+			wants: []string{"spec.userPassword stores the password in plain text"},
+		},
+		{
+			name:  "no password",
+			wants: []string{"development password"},
+		},
+		{
+			name: "password secret",
+			spec: KairosConfigSpec{UserPasswordSecretRef: &UserPasswordSecretReference{Name: "password"}},
+		},
+		{
+			name: "plain text user password",
+			spec: KairosConfigSpec{
+				PasswdHash: "$6$salt$hash",
+				Users:      []KairosUser{{Name: "ops", Password: "secret"}, {Name: "dev", Password: "$6$salt$hash"}},
+			},
+			wants: []string{"spec.users[0].password stores the password in plain text"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, _ := (&KairosConfig{Spec: tt.spec}).ValidateCreate()
+			if len(warnings) != len(tt.wants) {
+				t.Fatalf("Expected %d warnings, got %v", len(tt.wants), warnings)
+			}
+			for i, want := range tt.wants {
+				if !strings.Contains(warnings[i], want) {
+					t.Errorf("Expected warning containing %q, got %q", want, warnings[i])
+				}
+			}
+		})
+	}
+}
@@ -36,8 +36,13 @@ type KairosConfigTemplateResource struct {
 	Spec KairosConfigSpec `json:"spec"`
 }
 
+// +genclient
+// +genclient:noStatus
 // +kubebuilder:object:root=true
-// +kubebuilder:resource:path=kairosconfigtemplates,scope=Namespaced,categories=cluster-api
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=kairosconfigtemplates,scope=Namespaced,categories=cluster-api,shortName=kcfgt
+// +kubebuilder:printcolumn:name="Role",type="string",JSONPath=".spec.template.spec.role",description="Node role"
+// +kubebuilder:printcolumn:name="Distribution",type="string",JSONPath=".spec.template.spec.distribution",description="Kubernetes distribution"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // KairosConfigTemplate is the Schema for the kairosconfigtemplates API
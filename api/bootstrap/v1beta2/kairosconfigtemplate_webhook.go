@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var kairosconfigtemplateLog = logf.Log.WithName("kairosconfigtemplate-resource")
+
+// SetupWebhookWithManager sets up the webhook with the Manager.
+func (r *KairosConfigTemplate) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-bootstrap-cluster-x-k8s-io-v1beta2-kairosconfigtemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=bootstrap.cluster.x-k8s.io,resources=kairosconfigtemplates,verbs=create;update,versions=v1beta2,name=vkairosconfigtemplate.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &KairosConfigTemplate{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *KairosConfigTemplate) ValidateCreate() (admission.Warnings, error) {
+	kairosconfigtemplateLog.Info("validate create", "name", r.Name)
+	return SpecWarnings(&r.Spec.Template.Spec, field.NewPath("spec", "template", "spec")), r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *KairosConfigTemplate) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	kairosconfigtemplateLog.Info("validate update", "name", r.Name)
+	return SpecWarnings(&r.Spec.Template.Spec, field.NewPath("spec", "template", "spec")), r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *KairosConfigTemplate) ValidateDelete() (admission.Warnings, error) {
+	kairosconfigtemplateLog.Info("validate delete", "name", r.Name)
+	return nil, nil
+}
+
+// validate checks the templated KairosConfig spec with the defaults the
+// KairosConfig webhook applies to the KairosConfigs created from it.
+func (r *KairosConfigTemplate) validate() error {
+	allErrs := ValidateSpec(&r.Spec.Template.Spec, field.NewPath("spec", "template", "spec"))
+	if len(allErrs) > 0 {
+		return errors.NewInvalid(
+			schema.GroupKind{Group: GroupVersion.Group, Kind: "KairosConfigTemplate"},
+			r.Name,
+			allErrs,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	apiconversion "k8s.io/apimachinery/pkg/conversion"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	bootstrapv1beta1 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta1"
+	controlplanev1beta2 "github.com/kairos-io/kairos-capi/api/controlplane/v1beta2"
+)
+
+// ConvertTo converts this KairosControlPlane to the hub version (v1beta2).
+func (src *KairosControlPlane) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*controlplanev1beta2.KairosControlPlane)
+	if err := Convert_v1beta1_KairosControlPlane_To_v1beta2_KairosControlPlane(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Restore the fields v1beta1 cannot represent
+	restored := &controlplanev1beta2.KairosControlPlane{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+	restoreKairosControlPlaneSpec(&restored.Spec, &dst.Spec)
+	dst.Status.MachineNodes = restored.Status.MachineNodes
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1beta2) to this version.
+func (dst *KairosControlPlane) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*controlplanev1beta2.KairosControlPlane)
+	if err := Convert_v1beta2_KairosControlPlane_To_v1beta1_KairosControlPlane(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Keep the v1beta2 object in an annotation so a round trip through
+	// v1beta1 does not lose data
+	return utilconversion.MarshalData(src, dst)
+}
+
+// ConvertTo converts this KairosControlPlaneList to the hub version (v1beta2).
+func (src *KairosControlPlaneList) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*controlplanev1beta2.KairosControlPlaneList)
+	return Convert_v1beta1_KairosControlPlaneList_To_v1beta2_KairosControlPlaneList(src, dst, nil)
+}
+
+// ConvertFrom converts from the hub version (v1beta2) to this version.
+func (dst *KairosControlPlaneList) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*controlplanev1beta2.KairosControlPlaneList)
+	return Convert_v1beta2_KairosControlPlaneList_To_v1beta1_KairosControlPlaneList(src, dst, nil)
+}
+
+// ConvertTo converts this KairosControlPlaneTemplate to the hub version (v1beta2).
+func (src *KairosControlPlaneTemplate) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*controlplanev1beta2.KairosControlPlaneTemplate)
+	if err := Convert_v1beta1_KairosControlPlaneTemplate_To_v1beta2_KairosControlPlaneTemplate(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Restore the fields v1beta1 cannot represent
+	restored := &controlplanev1beta2.KairosControlPlaneTemplate{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+	restoreKairosControlPlaneTemplateResourceSpec(&restored.Spec.Template.Spec, &dst.Spec.Template.Spec)
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1beta2) to this version.
+func (dst *KairosControlPlaneTemplate) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*controlplanev1beta2.KairosControlPlaneTemplate)
+	if err := Convert_v1beta2_KairosControlPlaneTemplate_To_v1beta1_KairosControlPlaneTemplate(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Keep the v1beta2 object in an annotation so a round trip through
+	// v1beta1 does not lose data
+	return utilconversion.MarshalData(src, dst)
+}
+
+// ConvertTo converts this KairosControlPlaneTemplateList to the hub version (v1beta2).
+func (src *KairosControlPlaneTemplateList) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*controlplanev1beta2.KairosControlPlaneTemplateList)
+	return Convert_v1beta1_KairosControlPlaneTemplateList_To_v1beta2_KairosControlPlaneTemplateList(src, dst, nil)
+}
+
+// ConvertFrom converts from the hub version (v1beta2) to this version.
+func (dst *KairosControlPlaneTemplateList) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*controlplanev1beta2.KairosControlPlaneTemplateList)
+	return Convert_v1beta2_KairosControlPlaneTemplateList_To_v1beta1_KairosControlPlaneTemplateList(src, dst, nil)
+}
+
+// Convert_v1beta2_KairosControlPlaneSpec_To_v1beta1_KairosControlPlaneSpec
+// drops the fields v1beta1 cannot represent; they are restored from the
+// conversion data annotation.
+func Convert_v1beta2_KairosControlPlaneSpec_To_v1beta1_KairosControlPlaneSpec(in *controlplanev1beta2.KairosControlPlaneSpec, out *KairosControlPlaneSpec, s apiconversion.Scope) error {
+	return autoConvert_v1beta2_KairosControlPlaneSpec_To_v1beta1_KairosControlPlaneSpec(in, out, s)
+}
+
+// Convert_v1beta2_KairosControlPlaneStatus_To_v1beta1_KairosControlPlaneStatus
+// drops the fields v1beta1 cannot represent; they are restored from the
+// conversion data annotation.
+func Convert_v1beta2_KairosControlPlaneStatus_To_v1beta1_KairosControlPlaneStatus(in *controlplanev1beta2.KairosControlPlaneStatus, out *KairosControlPlaneStatus, s apiconversion.Scope) error {
+	return autoConvert_v1beta2_KairosControlPlaneStatus_To_v1beta1_KairosControlPlaneStatus(in, out, s)
+}
+
+// Convert_v1beta2_KairosControlPlaneTemplateResourceSpec_To_v1beta1_KairosControlPlaneTemplateResourceSpec
+// drops the fields v1beta1 cannot represent; they are restored from the
+// conversion data annotation.
+func Convert_v1beta2_KairosControlPlaneTemplateResourceSpec_To_v1beta1_KairosControlPlaneTemplateResourceSpec(in *controlplanev1beta2.KairosControlPlaneTemplateResourceSpec, out *KairosControlPlaneTemplateResourceSpec, s apiconversion.Scope) error {
+	return autoConvert_v1beta2_KairosControlPlaneTemplateResourceSpec_To_v1beta1_KairosControlPlaneTemplateResourceSpec(in, out, s)
+}
+
+// restoreKairosControlPlaneSpec copies the fields v1beta1 cannot represent
+// from restored, the v1beta2 spec kept in the conversion data annotation, to
+// dst.
+func restoreKairosControlPlaneSpec(restored, dst *controlplanev1beta2.KairosControlPlaneSpec) {
+	dst.CertSANs = restored.CertSANs
+	dst.CNI = restored.CNI
+	dst.Addons = restored.Addons
+	if restored.KairosConfigSpec != nil && dst.KairosConfigSpec != nil {
+		bootstrapv1beta1.RestoreKairosConfigSpec(restored.KairosConfigSpec, dst.KairosConfigSpec)
+	}
+}
+
+// restoreKairosControlPlaneTemplateResourceSpec is
+// restoreKairosControlPlaneSpec for templates.
+func restoreKairosControlPlaneTemplateResourceSpec(restored, dst *controlplanev1beta2.KairosControlPlaneTemplateResourceSpec) {
+	dst.CertSANs = restored.CertSANs
+	dst.CNI = restored.CNI
+	dst.Addons = restored.Addons
+	if restored.KairosConfigSpec != nil && dst.KairosConfigSpec != nil {
+		bootstrapv1beta1.RestoreKairosConfigSpec(restored.KairosConfigSpec, dst.KairosConfigSpec)
+	}
+}
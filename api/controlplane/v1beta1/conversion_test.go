@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
+
+	controlplanev1beta2 "github.com/kairos-io/kairos-capi/api/controlplane/v1beta2"
+	"github.com/kairos-io/kairos-capi/internal/test/apifuzz"
+)
+
+func TestFuzzyConversion(t *testing.T) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(AddToScheme(scheme)).To(Succeed())
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+
+	t.Run("for KairosControlPlane", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
+		Scheme:      scheme,
+		Hub:         &controlplanev1beta2.KairosControlPlane{},
+		Spoke:       &KairosControlPlane{},
+		FuzzerFuncs: []fuzzer.FuzzerFuncs{apifuzz.Funcs},
+	}))
+
+	t.Run("for KairosControlPlaneTemplate", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
+		Scheme:      scheme,
+		Hub:         &controlplanev1beta2.KairosControlPlaneTemplate{},
+		Spoke:       &KairosControlPlaneTemplate{},
+		FuzzerFuncs: []fuzzer.FuzzerFuncs{apifuzz.Funcs},
+	}))
+}
@@ -0,0 +1,24 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package v1beta1 contains API Schema definitions for the controlplane v1beta1 API group.
+// It is served for manifests and tooling written against v1beta1 and is
+// converted to and from the v1beta2 storage version.
+// +kubebuilder:object:generate=true
+// +k8s:conversion-gen=github.com/kairos-io/kairos-capi/api/controlplane/v1beta2
+// +groupGoName=ControlPlane
+// +groupName=controlplane.cluster.x-k8s.io
+package v1beta1
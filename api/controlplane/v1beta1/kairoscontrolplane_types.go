@@ -0,0 +1,501 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	bootstrapv1beta1 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta1"
+)
+
+// KairosControlPlaneSpec defines the desired state of KairosControlPlane
+type KairosControlPlaneSpec struct {
+	// Replicas is the number of control plane machines
+	// Contract: ControlPlane MUST expose replicas
+	// When replicas == 1, the control plane operates in single-node mode and k0s will be
+	// configured with --single flag. For HA setups, set replicas > 1 (full HA support is planned).
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Version is the Kubernetes version to use
+	// Contract: ControlPlane MUST expose version
+	// +kubebuilder:validation:Required
+	Version string `json:"version"`
+
+	// Distribution specifies the Kubernetes distribution to install
+	// +kubebuilder:validation:Enum=k0s;k3s
+	// +kubebuilder:default=k0s
+	// +optional
+	Distribution string `json:"distribution,omitempty"`
+
+	// MachineTemplate defines the template for creating control plane machines
+	// Contract: ControlPlane MUST expose machineTemplate
+	MachineTemplate KairosControlPlaneMachineTemplate `json:"machineTemplate"`
+
+	// KairosConfigTemplate is a reference to a KairosConfigTemplate resource
+	// Contract: ControlPlane MUST reference a BootstrapConfigTemplate
+	// Exactly one of kairosConfigTemplate and kairosConfigSpec must be set.
+	// +optional
+	KairosConfigTemplate KairosConfigTemplateReference `json:"kairosConfigTemplate,omitempty,omitzero"`
+
+	// KairosConfigSpec is the bootstrap configuration of the control plane
+	// machines, given inline instead of through kairosConfigTemplate so that
+	// ClusterClass patches can change it. Role, distribution,
+	// kubernetesVersion and singleNode are set by the control plane.
+	// +optional
+	KairosConfigSpec *bootstrapv1beta1.KairosConfigSpec `json:"kairosConfigSpec,omitempty"`
+
+	// ControlPlaneEndpoint is a virtual IP the control plane nodes announce for
+	// the Kubernetes API, so multi-node control planes get an HA endpoint
+	// without an external load balancer. k0s nodes use the keepalived based
+	// control plane load balancing of k0s, k3s nodes run kube-vip as a static
+	// pod. Cluster API uses host and port as the Cluster endpoint when the
+	// infrastructure provider does not set one.
+	// +optional
+	ControlPlaneEndpoint *ControlPlaneEndpoint `json:"controlPlaneEndpoint,omitempty"`
+
+	// ExternalManagedEndpoint declares that the Cluster's controlPlaneEndpoint
+	// is managed outside of Cluster API, e.g. by a load balancer in front of
+	// the control plane nodes. The controller then waits for the endpoint to
+	// be set instead of deriving it from the machines, does not create a
+	// control plane load balancer Service for KubeVirt, and adds the endpoint
+	// host to the API server certificate. Mutually exclusive with
+	// controlPlaneEndpoint.
+	// +optional
+	ExternalManagedEndpoint bool `json:"externalManagedEndpoint,omitempty"`
+
+	// RolloutStrategy defines the strategy for rolling out updates
+	// +optional
+	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// RemediationStrategy configures how unhealthy control plane machines are
+	// replaced. Machines are remediated when they carry the
+	// cluster.x-k8s.io/remediate-machine annotation or a MachineHealthCheck
+	// marks them for remediation.
+	// +optional
+	RemediationStrategy *RemediationStrategy `json:"remediationStrategy,omitempty"`
+
+	// FailureDomains lists the failure domains control plane machines are
+	// spread across. When empty, the failure domains in the Cluster status
+	// that are marked for control plane use are used. New machines go to the
+	// failure domain with the fewest control plane machines and scale down
+	// removes a machine from the one with the most.
+	// +listType=set
+	// +optional
+	FailureDomains []string `json:"failureDomains,omitempty"`
+
+	// MachineNamingStrategy configures the names of the control plane
+	// Machines and of the KairosConfigs and infrastructure machines created
+	// with them
+	// +optional
+	MachineNamingStrategy *MachineNamingStrategy `json:"machineNamingStrategy,omitempty"`
+
+	// OSImage is the Kairos OS image the control plane nodes should run,
+	// e.g. quay.io/kairos/ubuntu. When set, the controller upgrades the nodes
+	// in place through the kairos operator in the workload cluster.
+	// +optional
+	OSImage string `json:"osImage,omitempty"`
+
+	// OSVersion is the tag of OSImage to run. When empty, OSImage must be a
+	// complete image reference including tag or digest.
+	// +optional
+	OSVersion string `json:"osVersion,omitempty"`
+
+	// OSUpgrade upgrades the Kairos OS of the control plane nodes in place
+	// with a system-upgrade-controller Plan in the workload cluster. Mutually
+	// exclusive with osImage.
+	// +optional
+	OSUpgrade *bootstrapv1beta1.OSUpgrade `json:"osUpgrade,omitempty"`
+
+	// UpgradeStrategy selects how a spec.version change reaches the existing
+	// control plane machines. Replace (the default) rolls out new machines
+	// as configured by rolloutStrategy. InPlace upgrades k0s/k3s on the
+	// running nodes with the Kairos image of inPlaceUpgrade that carries the
+	// new version; changes to other fields still replace machines.
+	// +kubebuilder:validation:Enum=Replace;InPlace
+	// +optional
+	UpgradeStrategy string `json:"upgradeStrategy,omitempty"`
+
+	// InPlaceUpgrade configures the InPlace upgrade strategy
+	// +optional
+	InPlaceUpgrade *InPlaceUpgrade `json:"inPlaceUpgrade,omitempty"`
+}
+
+const (
+	// UpgradeStrategyReplace replaces machines on a version change
+	UpgradeStrategyReplace = "Replace"
+
+	// UpgradeStrategyInPlace upgrades the running nodes on a version change
+	UpgradeStrategyInPlace = "InPlace"
+)
+
+// KairosControlPlaneMachineTemplate defines the template for control plane machines
+type KairosControlPlaneMachineTemplate struct {
+	// InfrastructureRef is a reference to a resource that provides infrastructure
+	// Contract: ControlPlane MUST reference an infrastructure template
+	InfrastructureRef corev1.ObjectReference `json:"infrastructureRef"`
+
+	// NodeDrainTimeout is the total amount of time that the controller will spend
+	// on draining a controlplane node
+	// +optional
+	NodeDrainTimeout *metav1.Duration `json:"nodeDrainTimeout,omitempty"`
+
+	// Metadata is the metadata to apply to the machines
+	// +optional
+	Metadata clusterv1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+// KairosConfigTemplateReference is a reference to a KairosConfigTemplate
+type KairosConfigTemplateReference struct {
+	// APIVersion is the API version of the referenced resource
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kind is the kind of the referenced resource
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name is the name of the referenced resource
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+// ControlPlaneEndpoint is the virtual IP of a control plane
+type ControlPlaneEndpoint struct {
+	// Host is the virtual IP address
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host"`
+
+	// Port is the port the API server listens on. Defaults to 6443.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:default=6443
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Interface is the network interface the virtual IP is announced on.
+	// Defaults to the interface of the default route.
+	// +optional
+	Interface string `json:"interface,omitempty"`
+
+	// KubeVIPImage is the kube-vip image run on k3s control plane nodes.
+	// Defaults to ghcr.io/kube-vip/kube-vip:v0.8.9.
+	// +optional
+	KubeVIPImage string `json:"kubeVIPImage,omitempty"`
+}
+
+// RolloutStrategy defines the strategy for rolling out updates
+type RolloutStrategy struct {
+	// Type is the type of rollout strategy
+	// +kubebuilder:validation:Enum=RollingUpdate
+	// +kubebuilder:default=RollingUpdate
+	Type string `json:"type,omitempty"`
+
+	// RollingUpdate defines the rolling update configuration
+	// +optional
+	RollingUpdate *RollingUpdate `json:"rollingUpdate,omitempty"`
+}
+
+// RollingUpdate defines the rolling update configuration
+type RollingUpdate struct {
+	// MaxSurge is the maximum number of machines that can be created above the
+	// desired number of machines. Defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxSurge *int32 `json:"maxSurge,omitempty"`
+
+	// MaxUnavailable is the maximum number of desired machines that can be
+	// unavailable while outdated machines are replaced. Defaults to 0.
+	// Single-node control planes must use maxSurge 0 and maxUnavailable 1,
+	// since a surge machine would start a separate cluster.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxUnavailable *int32 `json:"maxUnavailable,omitempty"`
+}
+
+// MachineNamingStrategy defines how control plane Machines are named
+type MachineNamingStrategy struct {
+	// Template is a Go text/template rendering the Machine names. It can
+	// reference {{ .cluster.name }}, {{ .kairosControlPlane.name }},
+	// {{ .index }}, the position of the Machine in creation order, and
+	// {{ .random }}, a random 5 character suffix. It must reference .index or
+	// .random so the names are unique, and render a DNS-1123 label of at
+	// most 63 characters. Defaults to "{{ .kairosControlPlane.name }}-{{ .index }}".
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=256
+	// +optional
+	Template string `json:"template,omitempty"`
+}
+
+// InPlaceUpgrade configures in-place Kubernetes upgrades. The nodes are
+// upgraded with a system-upgrade-controller Plan to the tag of Image built
+// for spec.version, since Kairos images ship k0s and k3s.
+type InPlaceUpgrade struct {
+	// Image is the Kairos OS image without tag, e.g.
+	// quay.io/kairos/ubuntu
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+
+	// TagTemplate is a Go text/template rendering the tag of Image that
+	// carries a Kubernetes version. It can reference {{ .version }}, the
+	// spec.version. A "+" in the rendered tag is replaced with "-" when the
+	// image is pulled. Defaults to "{{ .version }}".
+	// +kubebuilder:validation:MaxLength=256
+	// +optional
+	TagTemplate string `json:"tagTemplate,omitempty"`
+
+	// Concurrency is the number of nodes upgraded at the same time. Defaults
+	// to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Concurrency int64 `json:"concurrency,omitempty"`
+}
+
+// RemediationStrategy defines how unhealthy control plane machines are remediated
+type RemediationStrategy struct {
+	// MaxRetry is how many times remediation is retried when a machine fails
+	// within MinHealthyPeriod of the previous remediation. Further failures in
+	// that period are not remediated. Unlimited when unset.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRetry *int32 `json:"maxRetry,omitempty"`
+
+	// RetryPeriod is the minimum time between two consecutive remediations.
+	// Defaults to 0.
+	// +optional
+	RetryPeriod metav1.Duration `json:"retryPeriod,omitempty"`
+
+	// MinHealthyPeriod is how long after a remediation a new failure is still
+	// counted as a retry. Defaults to 1h.
+	// +optional
+	MinHealthyPeriod *metav1.Duration `json:"minHealthyPeriod,omitempty"`
+}
+
+// LastRemediationStatus records the most recent remediation of a control plane machine
+type LastRemediationStatus struct {
+	// Machine is the name of the remediated machine
+	Machine string `json:"machine"`
+
+	// Timestamp is when the machine was deleted for remediation
+	Timestamp metav1.Time `json:"timestamp"`
+
+	// RetryCount is the number of consecutive remediations before this one
+	RetryCount int32 `json:"retryCount"`
+}
+
+// KairosControlPlaneStatus defines the observed state of KairosControlPlane
+// Contract: ControlPlane v1beta2 MUST expose initialized, readyReplicas, updatedReplicas, unavailableReplicas
+type KairosControlPlaneStatus struct {
+	// Initialized indicates whether the control plane has been initialized
+	// Contract: ControlPlane MUST expose initialized
+	// This field MUST be set to true when the first control plane machine is ready
+	// and the control plane is functional.
+	// +optional
+	Initialized bool `json:"initialized,omitempty"`
+
+	// Initialization provides observations of the control plane initialization process.
+	// This is part of the Cluster API v1beta2 contract.
+	// +optional
+	Initialization KairosControlPlaneInitializationStatus `json:"initialization,omitempty,omitzero"`
+
+	// ReadyReplicas is the number of control plane machines that are ready
+	// Contract: ControlPlane MUST expose readyReplicas
+	// A machine is considered ready when it has a NodeRef and the Node is ready.
+	// Note: omitempty is removed to ensure the field is always present (even when 0),
+	// as the Cluster controller checks this field and null vs 0 can cause issues.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// Replicas is the total number of control plane machines
+	// This includes machines in all states (pending, running, failed, etc.)
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// UpdatedReplicas is the number of control plane machines that have been updated
+	// Contract: ControlPlane MUST expose updatedReplicas
+	// A machine is considered updated when its spec matches the desired state.
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// UnavailableReplicas is the number of control plane machines that are unavailable
+	// Contract: ControlPlane MUST expose unavailableReplicas
+	// A machine is unavailable if it is not ready or if it is being deleted.
+	// +optional
+	UnavailableReplicas int32 `json:"unavailableReplicas,omitempty"`
+
+	// Version is the lowest Kubernetes version among the control plane machines
+	// Contract: ControlPlane SHOULD expose version
+	// It differs from spec.version while an upgrade is rolled out, and is unset
+	// until a control plane machine exists.
+	// +optional
+	Version *string `json:"version,omitempty"`
+
+	// Conditions defines current service state of the KairosControlPlane
+	// Contract: ControlPlane SHOULD expose Conditions
+	// Standard CAPI conditions: Ready, Available, Initialized
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the most recent generation observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// V1Beta2 groups the fields of the Cluster API v1beta2 status contract.
+	// +optional
+	V1Beta2 *KairosControlPlaneV1Beta2Status `json:"v1beta2,omitempty"`
+
+	// FailureReason indicates the reason for control plane failure
+	// This field is set only when the control plane fails permanently.
+	// +optional
+	FailureReason string `json:"failureReason,omitempty"`
+
+	// FailureMessage indicates the message for control plane failure
+	// This field is set only when the control plane fails permanently.
+	// +optional
+	FailureMessage string `json:"failureMessage,omitempty"`
+
+	// Selector is the label selector for control plane machines
+	// This is used to identify machines belonging to this control plane.
+	// It is exposed through the scale subresource.
+	// +optional
+	Selector string `json:"selector,omitempty"`
+
+	// OSImage is the OS image reference the control plane nodes were last
+	// upgraded to by the kairos operator.
+	// +optional
+	OSImage string `json:"osImage,omitempty"`
+
+	// OSUpgrade reports the progress of spec.osUpgrade
+	// +optional
+	OSUpgrade *OSUpgradeStatus `json:"osUpgrade,omitempty"`
+
+	// LastRemediation records the most recent remediation of a control plane machine
+	// +optional
+	LastRemediation *LastRemediationStatus `json:"lastRemediation,omitempty"`
+}
+
+// OSUpgradeStatus reports the progress of a system-upgrade-controller Plan
+// upgrading the control plane nodes
+type OSUpgradeStatus struct {
+	// Image is the image reference the Plan upgrades the nodes to
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Plan is the name of the Plan in the system-upgrade namespace of the
+	// workload cluster
+	// +optional
+	Plan string `json:"plan,omitempty"`
+
+	// Nodes is the number of control plane nodes selected by the Plan
+	// +optional
+	Nodes int32 `json:"nodes"`
+
+	// UpdatedNodes is the number of control plane nodes the Plan completed on
+	// +optional
+	UpdatedNodes int32 `json:"updatedNodes"`
+
+	// Applying lists the nodes that are being upgraded
+	// +optional
+	Applying []string `json:"applying,omitempty"`
+}
+
+// KairosControlPlaneV1Beta2Status groups the fields of the Cluster API v1beta2 status contract.
+type KairosControlPlaneV1Beta2Status struct {
+	// Conditions represents the observations of the KairosControlPlane in the
+	// metav1.Condition format. Known condition types are Available, Ready,
+	// ScalingUp, ScalingDown and MachinesReady.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=32
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// KairosControlPlaneInitializationStatus provides observations of the control plane initialization process.
+// +kubebuilder:validation:MinProperties=1
+type KairosControlPlaneInitializationStatus struct {
+	// ControlPlaneInitialized is true when the control plane is initialized and can accept requests.
+	// +optional
+	ControlPlaneInitialized *bool `json:"controlPlaneInitialized,omitempty"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=kairoscontrolplanes,scope=Namespaced,categories=cluster-api,shortName=kcp-kairos
+// +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels['cluster\\.x-k8s\\.io/cluster-name']",description="Cluster"
+// +kubebuilder:printcolumn:name="Initialized",type="boolean",JSONPath=".status.initialized",description="Control plane initialized"
+// +kubebuilder:printcolumn:name="Desired",type="integer",JSONPath=".spec.replicas",description="Desired replicas"
+// +kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".status.replicas",description="Total replicas"
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyReplicas",description="Ready replicas"
+// +kubebuilder:printcolumn:name="Updated",type="integer",JSONPath=".status.updatedReplicas",description="Updated replicas"
+// +kubebuilder:printcolumn:name="Unavailable",type="integer",JSONPath=".status.unavailableReplicas",description="Unavailable replicas"
+// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".spec.version",description="Kubernetes version"
+// +kubebuilder:printcolumn:name="Distribution",type="string",JSONPath=".spec.distribution",description="Kubernetes distribution",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// KairosControlPlane is the Schema for the kairoscontrolplanes API
+type KairosControlPlane struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KairosControlPlaneSpec   `json:"spec,omitempty"`
+	Status KairosControlPlaneStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KairosControlPlaneList contains a list of KairosControlPlane
+type KairosControlPlaneList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KairosControlPlane `json:"items"`
+}
+
+// GetConditions returns the set of conditions for this object.
+func (c *KairosControlPlane) GetConditions() clusterv1.Conditions {
+	return c.Status.Conditions
+}
+
+// SetConditions sets the conditions on this object.
+func (c *KairosControlPlane) SetConditions(conditions clusterv1.Conditions) {
+	c.Status.Conditions = conditions
+}
+
+// GetV1Beta2Conditions returns the set of v1beta2 conditions for this object.
+func (c *KairosControlPlane) GetV1Beta2Conditions() []metav1.Condition {
+	if c.Status.V1Beta2 == nil {
+		return nil
+	}
+	return c.Status.V1Beta2.Conditions
+}
+
+// SetV1Beta2Conditions sets the v1beta2 conditions on this object.
+func (c *KairosControlPlane) SetV1Beta2Conditions(conditions []metav1.Condition) {
+	if c.Status.V1Beta2 == nil {
+		c.Status.V1Beta2 = &KairosControlPlaneV1Beta2Status{}
+	}
+	c.Status.V1Beta2.Conditions = conditions
+}
+
+func init() {
+	SchemeBuilder.Register(&KairosControlPlane{}, &KairosControlPlaneList{})
+}
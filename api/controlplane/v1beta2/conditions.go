@@ -20,6 +20,34 @@ package v1beta2
 const (
 	// AvailableCondition indicates that the control plane is available
 	AvailableCondition = "Available"
+
+	// ValidSpecCondition reports whether the spec passed validation. It is only
+	// set when the controller validates at reconcile time (webhooks disabled)
+	ValidSpecCondition = "ValidSpec"
+
+	// NodesReadyCondition reports whether every control plane machine has a Ready node in the workload cluster
+	NodesReadyCondition = "NodesReady"
+
+	// MachinesSpecUpToDateCondition reports whether every control plane Machine matches the current spec
+	MachinesSpecUpToDateCondition = "MachinesSpecUpToDate"
+
+	// MachinesHealthyCondition reports whether any control plane Machine is waiting for remediation
+	MachinesHealthyCondition = "MachinesHealthy"
+
+	// OSImageUpToDateCondition reports whether the control plane nodes run spec.osImage. It is only set when spec.osImage is set
+	OSImageUpToDateCondition = "OSImageUpToDate"
+
+	// OSUpgradeCompletedCondition reports whether every control plane node completed the system-upgrade-controller
+	// Plan of spec.osUpgrade. It is only set when spec.osUpgrade is set
+	OSUpgradeCompletedCondition = "OSUpgradeCompleted"
+
+	// EtcdClusterHealthyCondition reports whether the etcd members of deleted k0s control plane machines were
+	// removed and machine deletions keep etcd quorum
+	EtcdClusterHealthyCondition = "EtcdClusterHealthy"
+
+	// PausedCondition reports whether reconciliation is paused by the
+	// cluster.x-k8s.io/paused annotation or the owning Cluster's spec.paused
+	PausedCondition = "Paused"
 )
 
 // Condition reasons
@@ -41,4 +69,174 @@ const (
 
 	// ScalingDownReason indicates that the control plane is scaling down
 	ScalingDownReason = "ScalingDown"
+
+	// InvalidSpecReason indicates that the spec failed validation
+	InvalidSpecReason = "InvalidSpec"
+
+	// WaitingForNodesReason indicates that some control plane nodes have not joined the workload cluster yet
+	WaitingForNodesReason = "WaitingForNodes"
+
+	// NodesNotReadyReason indicates that some control plane nodes are not Ready
+	NodesNotReadyReason = "NodesNotReady"
+
+	// WorkloadClusterUnreachableReason indicates that the workload cluster API server could not be reached
+	WorkloadClusterUnreachableReason = "WorkloadClusterUnreachable"
+
+	// RollingUpdateInProgressReason indicates that outdated control plane machines are being replaced
+	RollingUpdateInProgressReason = "RollingUpdateInProgress"
+
+	// RolloutBlockedReason indicates that outdated control plane machines cannot be replaced with the
+	// configured rollout strategy
+	RolloutBlockedReason = "RolloutBlocked"
+
+	// InPlaceUpgradeInProgressReason indicates that control plane nodes are being upgraded to spec.version
+	// in place
+	InPlaceUpgradeInProgressReason = "InPlaceUpgradeInProgress"
+
+	// RemediationInProgressReason indicates that an unhealthy control plane machine is being replaced
+	RemediationInProgressReason = "RemediationInProgress"
+
+	// RemediationBlockedReason indicates that an unhealthy control plane machine cannot be replaced
+	// without losing quorum or exceeding the remediation strategy limits
+	RemediationBlockedReason = "RemediationBlocked"
+
+	// KairosOperatorNotInstalledReason indicates that the kairos operator is missing from the workload cluster
+	// and no operator manifest was configured to install it
+	KairosOperatorNotInstalledReason = "KairosOperatorNotInstalled"
+
+	// InstallingKairosOperatorReason indicates that the kairos operator is being installed in the workload cluster
+	InstallingKairosOperatorReason = "InstallingKairosOperator"
+
+	// SystemUpgradeControllerNotInstalledReason indicates that the system-upgrade-controller is missing from the
+	// workload cluster and no manifest was configured to install it
+	SystemUpgradeControllerNotInstalledReason = "SystemUpgradeControllerNotInstalled"
+
+	// InstallingSystemUpgradeControllerReason indicates that the system-upgrade-controller is being installed in
+	// the workload cluster
+	InstallingSystemUpgradeControllerReason = "InstallingSystemUpgradeController"
+
+	// OSUpgradeInProgressReason indicates that the kairos operator or the system-upgrade-controller is upgrading
+	// the control plane nodes
+	OSUpgradeInProgressReason = "OSUpgradeInProgress"
+
+	// OSUpgradeFailedReason indicates that the kairos operator stopped the upgrade after a failure
+	OSUpgradeFailedReason = "OSUpgradeFailed"
+
+	// EtcdMemberRemovalFailedReason indicates that the etcd member of a deleted control plane machine could not be
+	// removed yet
+	EtcdMemberRemovalFailedReason = "EtcdMemberRemovalFailed"
+
+	// EtcdQuorumAtRiskReason indicates that a control plane machine is not deleted because the remaining healthy
+	// machines would not keep etcd quorum
+	EtcdQuorumAtRiskReason = "EtcdQuorumAtRisk"
+
+	// WaitingForControlPlaneEndpointReason indicates that spec.externalManagedEndpoint is set and the Cluster has no
+	// controlPlaneEndpoint yet
+	WaitingForControlPlaneEndpointReason = "WaitingForControlPlaneEndpoint"
+
+	// NotPausedReason indicates that reconciliation is not paused
+	NotPausedReason = "NotPaused"
+)
+
+// Condition types and reasons reported per machine in status.machineNodes
+const (
+	// NodeRegisteredCondition is true when the Machine's Node exists in the workload cluster
+	NodeRegisteredCondition = "NodeRegistered"
+
+	// NodeProviderIDSetCondition is true when the Node carries the Machine's providerID
+	NodeProviderIDSetCondition = "NodeProviderIDSet"
+
+	// NodeReadyCondition mirrors the Ready condition of the Node
+	NodeReadyCondition = "NodeReady"
+
+	// NodeRegisteredReason indicates that the Node registered
+	NodeRegisteredReason = "NodeRegistered"
+
+	// NodeNotRegisteredReason indicates that no Node matches the Machine's nodeRef or providerID yet
+	NodeNotRegisteredReason = "NodeNotRegistered"
+
+	// ProviderIDSetReason indicates that the Node carries the Machine's providerID
+	ProviderIDSetReason = "ProviderIDSet"
+
+	// ProviderIDMissingReason indicates that the Node has no providerID
+	ProviderIDMissingReason = "ProviderIDMissing"
+
+	// ProviderIDMismatchReason indicates that the providerID of the Node differs from the Machine's
+	ProviderIDMismatchReason = "ProviderIDMismatch"
+
+	// NodeReadyReason indicates that the Node is Ready
+	NodeReadyReason = "NodeReady"
+
+	// NodeNotReadyReason indicates that the Node is not Ready
+	NodeNotReadyReason = "NodeNotReady"
+)
+
+// Condition types and reasons reported in status.v1beta2.conditions
+const (
+	// AvailableV1Beta2Condition is true when the control plane can serve requests
+	AvailableV1Beta2Condition = "Available"
+
+	// ReadyV1Beta2Condition is true when the control plane is initialized and has ready machines
+	ReadyV1Beta2Condition = "Ready"
+
+	// ScalingUpV1Beta2Condition is true while there are fewer control plane machines than spec.replicas
+	ScalingUpV1Beta2Condition = "ScalingUp"
+
+	// ScalingDownV1Beta2Condition is true while there are more control plane machines than spec.replicas
+	ScalingDownV1Beta2Condition = "ScalingDown"
+
+	// MachinesReadyV1Beta2Condition is true when every control plane machine is ready
+	MachinesReadyV1Beta2Condition = "MachinesReady"
+
+	// AvailableV1Beta2Reason is used when the control plane is available
+	AvailableV1Beta2Reason = "Available"
+
+	// NotAvailableV1Beta2Reason is used when the control plane is not available
+	NotAvailableV1Beta2Reason = "NotAvailable"
+
+	// ReadyV1Beta2Reason is used when the control plane or all its machines are ready
+	ReadyV1Beta2Reason = "Ready"
+
+	// NotReadyV1Beta2Reason is used when the control plane or some of its machines are not ready
+	NotReadyV1Beta2Reason = "NotReady"
+
+	// NoReplicasV1Beta2Reason is used for MachinesReady when there are no control plane machines
+	NoReplicasV1Beta2Reason = "NoReplicas"
+
+	// ScalingUpV1Beta2Reason is used while control plane machines are being added
+	ScalingUpV1Beta2Reason = "ScalingUp"
+
+	// NotScalingUpV1Beta2Reason is used when no control plane machines need to be added
+	NotScalingUpV1Beta2Reason = "NotScalingUp"
+
+	// ScalingDownV1Beta2Reason is used while control plane machines are being removed
+	ScalingDownV1Beta2Reason = "ScalingDown"
+
+	// NotScalingDownV1Beta2Reason is used when no control plane machines need to be removed
+	NotScalingDownV1Beta2Reason = "NotScalingDown"
+
+	// PausedV1Beta2Condition is true while reconciliation is paused
+	PausedV1Beta2Condition = "Paused"
+
+	// PausedV1Beta2Reason is used when reconciliation is paused
+	PausedV1Beta2Reason = "Paused"
+
+	// NotPausedV1Beta2Reason is used when reconciliation is not paused
+	NotPausedV1Beta2Reason = "NotPaused"
+)
+
+// Event reasons emitted on KairosControlPlanes and their Machines
+const (
+	// ControlPlaneScalingUpEvent is emitted when a control plane machine is created to reach spec.replicas
+	ControlPlaneScalingUpEvent = "ControlPlaneScalingUp"
+
+	// ControlPlaneScalingDownEvent is emitted when a control plane machine is deleted to reach spec.replicas
+	ControlPlaneScalingDownEvent = "ControlPlaneScalingDown"
+
+	// UpgradeStartedEvent is emitted when a rolling update, an in-place upgrade or an OS upgrade of the control
+	// plane starts
+	UpgradeStartedEvent = "UpgradeStarted"
+
+	// RemediationTriggeredEvent is emitted when an unhealthy control plane machine is deleted to be replaced
+	RemediationTriggeredEvent = "RemediationTriggered"
 )
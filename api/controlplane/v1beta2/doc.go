@@ -0,0 +1,21 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package v1beta2 contains API Schema definitions for the controlplane v1beta2 API group
+// +kubebuilder:object:generate=true
+// +groupGoName=ControlPlane
+// +groupName=controlplane.cluster.x-k8s.io
+package v1beta2
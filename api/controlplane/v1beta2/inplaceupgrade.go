@@ -0,0 +1,47 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// DefaultInPlaceUpgradeTagTemplate uses the Kubernetes version as image tag
+const DefaultInPlaceUpgradeTagTemplate = "{{ .version }}"
+
+// Tag renders the tag of the in-place upgrade image for the Kubernetes
+// version from TagTemplate.
+func (u *InPlaceUpgrade) Tag(version string) (string, error) {
+	text := DefaultInPlaceUpgradeTagTemplate
+	if u.TagTemplate != "" {
+		text = u.TagTemplate
+	}
+	tmpl, err := template.New("tag").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse tag template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]interface{}{"version": version}); err != nil {
+		return "", fmt.Errorf("failed to render tag template: %w", err)
+	}
+	if buf.Len() == 0 {
+		return "", fmt.Errorf("tag template rendered an empty tag")
+	}
+	return buf.String(), nil
+}
@@ -20,12 +20,24 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
 )
 
 const (
 	// KairosControlPlaneFinalizer allows the reconciler to clean up resources associated with KairosControlPlane before
 	// removing it from the API server.
 	KairosControlPlaneFinalizer = "kairoscontrolplane.controlplane.cluster.x-k8s.io"
+
+	// MachineSpecHashAnnotation records on each control plane Machine a hash of
+	// the KairosControlPlane spec fields it was created from. Machines whose
+	// hash differs from the current spec are replaced by a rolling update.
+	MachineSpecHashAnnotation = "controlplane.cluster.x-k8s.io/kairos-spec-hash"
+
+	// MachineIndexAnnotation records on each control plane Machine its index in
+	// creation order, which machineNamingStrategy templates reference as
+	// {{ .index }}
+	MachineIndexAnnotation = "controlplane.cluster.x-k8s.io/machine-index"
 )
 
 // KairosControlPlaneSpec defines the desired state of KairosControlPlane
@@ -55,13 +67,126 @@ type KairosControlPlaneSpec struct {
 
 	// KairosConfigTemplate is a reference to a KairosConfigTemplate resource
 	// Contract: ControlPlane MUST reference a BootstrapConfigTemplate
-	KairosConfigTemplate KairosConfigTemplateReference `json:"kairosConfigTemplate"`
+	// Exactly one of kairosConfigTemplate and kairosConfigSpec must be set.
+	// +optional
+	KairosConfigTemplate KairosConfigTemplateReference `json:"kairosConfigTemplate,omitempty,omitzero"`
+
+	// KairosConfigSpec is the bootstrap configuration of the control plane
+	// machines, given inline instead of through kairosConfigTemplate so that
+	// ClusterClass patches can change it. Role, distribution,
+	// kubernetesVersion and singleNode are set by the control plane.
+	// +optional
+	KairosConfigSpec *bootstrapv1beta2.KairosConfigSpec `json:"kairosConfigSpec,omitempty"`
+
+	// ControlPlaneEndpoint is a virtual IP the control plane nodes announce for
+	// the Kubernetes API, so multi-node control planes get an HA endpoint
+	// without an external load balancer. k0s nodes use the keepalived based
+	// control plane load balancing of k0s, k3s nodes run kube-vip as a static
+	// pod. Cluster API uses host and port as the Cluster endpoint when the
+	// infrastructure provider does not set one.
+	// +optional
+	ControlPlaneEndpoint *ControlPlaneEndpoint `json:"controlPlaneEndpoint,omitempty"`
+
+	// ExternalManagedEndpoint declares that the Cluster's controlPlaneEndpoint
+	// is managed outside of Cluster API, e.g. by a load balancer in front of
+	// the control plane nodes. The controller then waits for the endpoint to
+	// be set instead of deriving it from the machines, does not create a
+	// control plane load balancer Service for KubeVirt, and adds the endpoint
+	// host to the API server certificate. Mutually exclusive with
+	// controlPlaneEndpoint.
+	// +optional
+	ExternalManagedEndpoint bool `json:"externalManagedEndpoint,omitempty"`
+
+	// CertSANs are additional subject alternative names for the API server
+	// certificate, e.g. external DNS names or virtual IPs. They are added to
+	// spec.certSANs of the KairosConfig of every control plane machine.
+	// Changing them replaces the control plane machines.
+	// +optional
+	CertSANs []string `json:"certSANs,omitempty"`
+
+	// CNI selects the network plugin of a k0s cluster: kuberouter, the k0s
+	// default, calico, cilium, which k0s installs through its Helm extension,
+	// or custom, which deploys none so that one can be brought in through
+	// manifests. It sets spec.k0sConfig.networkProvider of the KairosConfig
+	// of every control plane machine. Changing it replaces the control plane
+	// machines. k0s only.
+	// +kubebuilder:validation:Enum=kuberouter;calico;cilium;custom
+	// +optional
+	CNI string `json:"cni,omitempty"`
+
+	// Addons are manifests, such as a cloud controller manager or a CSI
+	// driver, added to spec.manifests of the KairosConfig of every control
+	// plane machine, so that k0s or k3s applies them when the cluster starts.
+	// They replace bootstrap manifests with the same name and file. Changing
+	// them replaces the control plane machines.
+	// +optional
+	Addons []bootstrapv1beta2.Manifest `json:"addons,omitempty"`
 
 	// RolloutStrategy defines the strategy for rolling out updates
 	// +optional
 	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// RemediationStrategy configures how unhealthy control plane machines are
+	// replaced. Machines are remediated when they carry the
+	// cluster.x-k8s.io/remediate-machine annotation or a MachineHealthCheck
+	// marks them for remediation.
+	// +optional
+	RemediationStrategy *RemediationStrategy `json:"remediationStrategy,omitempty"`
+
+	// FailureDomains lists the failure domains control plane machines are
+	// spread across. When empty, the failure domains in the Cluster status
+	// that are marked for control plane use are used. New machines go to the
+	// failure domain with the fewest control plane machines and scale down
+	// removes a machine from the one with the most.
+	// +listType=set
+	// +optional
+	FailureDomains []string `json:"failureDomains,omitempty"`
+
+	// MachineNamingStrategy configures the names of the control plane
+	// Machines and of the KairosConfigs and infrastructure machines created
+	// with them
+	// +optional
+	MachineNamingStrategy *MachineNamingStrategy `json:"machineNamingStrategy,omitempty"`
+
+	// OSImage is the Kairos OS image the control plane nodes should run,
+	// e.g. quay.io/kairos/ubuntu. When set, the controller upgrades the nodes
+	// in place through the kairos operator in the workload cluster.
+	// +optional
+	OSImage string `json:"osImage,omitempty"`
+
+	// OSVersion is the tag of OSImage to run. When empty, OSImage must be a
+	// complete image reference including tag or digest.
+	// +optional
+	OSVersion string `json:"osVersion,omitempty"`
+
+	// OSUpgrade upgrades the Kairos OS of the control plane nodes in place
+	// with a system-upgrade-controller Plan in the workload cluster. Mutually
+	// exclusive with osImage.
+	// +optional
+	OSUpgrade *bootstrapv1beta2.OSUpgrade `json:"osUpgrade,omitempty"`
+
+	// UpgradeStrategy selects how a spec.version change reaches the existing
+	// control plane machines. Replace (the default) rolls out new machines
+	// as configured by rolloutStrategy. InPlace upgrades k0s/k3s on the
+	// running nodes with the Kairos image of inPlaceUpgrade that carries the
+	// new version; changes to other fields still replace machines.
+	// +kubebuilder:validation:Enum=Replace;InPlace
+	// +optional
+	UpgradeStrategy string `json:"upgradeStrategy,omitempty"`
+
+	// InPlaceUpgrade configures the InPlace upgrade strategy
+	// +optional
+	InPlaceUpgrade *InPlaceUpgrade `json:"inPlaceUpgrade,omitempty"`
 }
 
+const (
+	// UpgradeStrategyReplace replaces machines on a version change
+	UpgradeStrategyReplace = "Replace"
+
+	// UpgradeStrategyInPlace upgrades the running nodes on a version change
+	UpgradeStrategyInPlace = "InPlace"
+)
+
 // KairosControlPlaneMachineTemplate defines the template for control plane machines
 type KairosControlPlaneMachineTemplate struct {
 	// InfrastructureRef is a reference to a resource that provides infrastructure
@@ -93,6 +218,30 @@ type KairosConfigTemplateReference struct {
 	Name string `json:"name"`
 }
 
+// ControlPlaneEndpoint is the virtual IP of a control plane
+type ControlPlaneEndpoint struct {
+	// Host is the virtual IP address
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host"`
+
+	// Port is the port the API server listens on. Defaults to 6443.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:default=6443
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Interface is the network interface the virtual IP is announced on.
+	// Defaults to the interface of the default route.
+	// +optional
+	Interface string `json:"interface,omitempty"`
+
+	// KubeVIPImage is the kube-vip image run on k3s control plane nodes.
+	// Defaults to ghcr.io/kube-vip/kube-vip:v0.8.9.
+	// +optional
+	KubeVIPImage string `json:"kubeVIPImage,omitempty"`
+}
+
 // RolloutStrategy defines the strategy for rolling out updates
 type RolloutStrategy struct {
 	// Type is the type of rollout strategy
@@ -108,9 +257,88 @@ type RolloutStrategy struct {
 // RollingUpdate defines the rolling update configuration
 type RollingUpdate struct {
 	// MaxSurge is the maximum number of machines that can be created above the
-	// desired number of machines
+	// desired number of machines. Defaults to 1.
+	// +kubebuilder:validation:Minimum=0
 	// +optional
 	MaxSurge *int32 `json:"maxSurge,omitempty"`
+
+	// MaxUnavailable is the maximum number of desired machines that can be
+	// unavailable while outdated machines are replaced. Defaults to 0.
+	// Single-node control planes must use maxSurge 0 and maxUnavailable 1,
+	// since a surge machine would start a separate cluster.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxUnavailable *int32 `json:"maxUnavailable,omitempty"`
+}
+
+// MachineNamingStrategy defines how control plane Machines are named
+type MachineNamingStrategy struct {
+	// Template is a Go text/template rendering the Machine names. It can
+	// reference {{ .cluster.name }}, {{ .kairosControlPlane.name }},
+	// {{ .index }}, the position of the Machine in creation order, and
+	// {{ .random }}, a random 5 character suffix. It must reference .index or
+	// .random so the names are unique, and render a DNS-1123 label of at
+	// most 63 characters. Defaults to "{{ .kairosControlPlane.name }}-{{ .index }}".
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=256
+	// +optional
+	Template string `json:"template,omitempty"`
+}
+
+// InPlaceUpgrade configures in-place Kubernetes upgrades. The nodes are
+// upgraded with a system-upgrade-controller Plan to the tag of Image built
+// for spec.version, since Kairos images ship k0s and k3s.
+type InPlaceUpgrade struct {
+	// Image is the Kairos OS image without tag, e.g.
+	// quay.io/kairos/ubuntu
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+
+	// TagTemplate is a Go text/template rendering the tag of Image that
+	// carries a Kubernetes version. It can reference {{ .version }}, the
+	// spec.version. A "+" in the rendered tag is replaced with "-" when the
+	// image is pulled. Defaults to "{{ .version }}".
+	// +kubebuilder:validation:MaxLength=256
+	// +optional
+	TagTemplate string `json:"tagTemplate,omitempty"`
+
+	// Concurrency is the number of nodes upgraded at the same time. Defaults
+	// to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Concurrency int64 `json:"concurrency,omitempty"`
+}
+
+// RemediationStrategy defines how unhealthy control plane machines are remediated
+type RemediationStrategy struct {
+	// MaxRetry is how many times remediation is retried when a machine fails
+	// within MinHealthyPeriod of the previous remediation. Further failures in
+	// that period are not remediated. Unlimited when unset.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRetry *int32 `json:"maxRetry,omitempty"`
+
+	// RetryPeriod is the minimum time between two consecutive remediations.
+	// Defaults to 0.
+	// +optional
+	RetryPeriod metav1.Duration `json:"retryPeriod,omitempty"`
+
+	// MinHealthyPeriod is how long after a remediation a new failure is still
+	// counted as a retry. Defaults to 1h.
+	// +optional
+	MinHealthyPeriod *metav1.Duration `json:"minHealthyPeriod,omitempty"`
+}
+
+// LastRemediationStatus records the most recent remediation of a control plane machine
+type LastRemediationStatus struct {
+	// Machine is the name of the remediated machine
+	Machine string `json:"machine"`
+
+	// Timestamp is when the machine was deleted for remediation
+	Timestamp metav1.Time `json:"timestamp"`
+
+	// RetryCount is the number of consecutive remediations before this one
+	RetryCount int32 `json:"retryCount"`
 }
 
 // KairosControlPlaneStatus defines the observed state of KairosControlPlane
@@ -153,6 +381,13 @@ type KairosControlPlaneStatus struct {
 	// +optional
 	UnavailableReplicas int32 `json:"unavailableReplicas,omitempty"`
 
+	// Version is the lowest Kubernetes version among the control plane machines
+	// Contract: ControlPlane SHOULD expose version
+	// It differs from spec.version while an upgrade is rolled out, and is unset
+	// until a control plane machine exists.
+	// +optional
+	Version *string `json:"version,omitempty"`
+
 	// Conditions defines current service state of the KairosControlPlane
 	// Contract: ControlPlane SHOULD expose Conditions
 	// Standard CAPI conditions: Ready, Available, Initialized
@@ -163,6 +398,10 @@ type KairosControlPlaneStatus struct {
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
+	// V1Beta2 groups the fields of the Cluster API v1beta2 status contract.
+	// +optional
+	V1Beta2 *KairosControlPlaneV1Beta2Status `json:"v1beta2,omitempty"`
+
 	// FailureReason indicates the reason for control plane failure
 	// This field is set only when the control plane fails permanently.
 	// +optional
@@ -175,8 +414,88 @@ type KairosControlPlaneStatus struct {
 
 	// Selector is the label selector for control plane machines
 	// This is used to identify machines belonging to this control plane.
+	// It is exposed through the scale subresource.
 	// +optional
 	Selector string `json:"selector,omitempty"`
+
+	// OSImage is the OS image reference the control plane nodes were last
+	// upgraded to by the kairos operator.
+	// +optional
+	OSImage string `json:"osImage,omitempty"`
+
+	// OSUpgrade reports the progress of spec.osUpgrade
+	// +optional
+	OSUpgrade *OSUpgradeStatus `json:"osUpgrade,omitempty"`
+
+	// LastRemediation records the most recent remediation of a control plane machine
+	// +optional
+	LastRemediation *LastRemediationStatus `json:"lastRemediation,omitempty"`
+
+	// MachineNodes reports, for every control plane Machine, whether its Node
+	// registered in the workload cluster, carries the Machine's providerID
+	// and is Ready, as observed in the workload cluster by the controller
+	// +optional
+	// +listType=map
+	// +listMapKey=machine
+	MachineNodes []MachineNodeStatus `json:"machineNodes,omitempty"`
+}
+
+// MachineNodeStatus is the state of the workload cluster Node of a control
+// plane Machine
+type MachineNodeStatus struct {
+	// Machine is the name of the control plane Machine
+	Machine string `json:"machine"`
+
+	// NodeName is the name of the Node backing the Machine, once it registered
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+
+	// ProviderID is the providerID of the Node
+	// +optional
+	ProviderID string `json:"providerID,omitempty"`
+
+	// Conditions are NodeRegistered, NodeProviderIDSet and NodeReady
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// OSUpgradeStatus reports the progress of a system-upgrade-controller Plan
+// upgrading the control plane nodes
+type OSUpgradeStatus struct {
+	// Image is the image reference the Plan upgrades the nodes to
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Plan is the name of the Plan in the system-upgrade namespace of the
+	// workload cluster
+	// +optional
+	Plan string `json:"plan,omitempty"`
+
+	// Nodes is the number of control plane nodes selected by the Plan
+	// +optional
+	Nodes int32 `json:"nodes"`
+
+	// UpdatedNodes is the number of control plane nodes the Plan completed on
+	// +optional
+	UpdatedNodes int32 `json:"updatedNodes"`
+
+	// Applying lists the nodes that are being upgraded
+	// +optional
+	Applying []string `json:"applying,omitempty"`
+}
+
+// KairosControlPlaneV1Beta2Status groups the fields of the Cluster API v1beta2 status contract.
+type KairosControlPlaneV1Beta2Status struct {
+	// Conditions represents the observations of the KairosControlPlane in the
+	// metav1.Condition format. Known condition types are Available, Ready,
+	// ScalingUp, ScalingDown and MachinesReady.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=32
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // KairosControlPlaneInitializationStatus provides observations of the control plane initialization process.
@@ -187,15 +506,21 @@ type KairosControlPlaneInitializationStatus struct {
 	ControlPlaneInitialized *bool `json:"controlPlaneInitialized,omitempty"`
 }
 
+// +genclient
 // +kubebuilder:object:root=true
-// +kubebuilder:resource:path=kairoscontrolplanes,scope=Namespaced,categories=cluster-api
+// +kubebuilder:resource:path=kairoscontrolplanes,scope=Namespaced,categories=cluster-api,shortName=kcp-kairos
 // +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
 // +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels['cluster\\.x-k8s\\.io/cluster-name']",description="Cluster"
 // +kubebuilder:printcolumn:name="Initialized",type="boolean",JSONPath=".status.initialized",description="Control plane initialized"
+// +kubebuilder:printcolumn:name="Desired",type="integer",JSONPath=".spec.replicas",description="Desired replicas"
 // +kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".status.replicas",description="Total replicas"
 // +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyReplicas",description="Ready replicas"
 // +kubebuilder:printcolumn:name="Updated",type="integer",JSONPath=".status.updatedReplicas",description="Updated replicas"
 // +kubebuilder:printcolumn:name="Unavailable",type="integer",JSONPath=".status.unavailableReplicas",description="Unavailable replicas"
+// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".spec.version",description="Kubernetes version"
+// +kubebuilder:printcolumn:name="Distribution",type="string",JSONPath=".spec.distribution",description="Kubernetes distribution",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // KairosControlPlane is the Schema for the kairoscontrolplanes API
@@ -216,6 +541,28 @@ type KairosControlPlaneList struct {
 	Items           []KairosControlPlane `json:"items"`
 }
 
+// RolloutLimits returns the effective maxSurge and maxUnavailable of the
+// rolling update strategy, applying the defaults of 1 and 0.
+func (c *KairosControlPlane) RolloutLimits() (maxSurge, maxUnavailable int32) {
+	return c.Spec.RolloutStrategy.limits()
+}
+
+// limits returns the effective maxSurge and maxUnavailable of a possibly nil
+// rollout strategy.
+func (s *RolloutStrategy) limits() (maxSurge, maxUnavailable int32) {
+	maxSurge, maxUnavailable = 1, 0
+	if s == nil || s.RollingUpdate == nil {
+		return maxSurge, maxUnavailable
+	}
+	if s.RollingUpdate.MaxSurge != nil {
+		maxSurge = *s.RollingUpdate.MaxSurge
+	}
+	if s.RollingUpdate.MaxUnavailable != nil {
+		maxUnavailable = *s.RollingUpdate.MaxUnavailable
+	}
+	return maxSurge, maxUnavailable
+}
+
 // GetConditions returns the set of conditions for this object.
 func (c *KairosControlPlane) GetConditions() clusterv1.Conditions {
 	return c.Status.Conditions
@@ -226,6 +573,22 @@ func (c *KairosControlPlane) SetConditions(conditions clusterv1.Conditions) {
 	c.Status.Conditions = conditions
 }
 
+// GetV1Beta2Conditions returns the set of v1beta2 conditions for this object.
+func (c *KairosControlPlane) GetV1Beta2Conditions() []metav1.Condition {
+	if c.Status.V1Beta2 == nil {
+		return nil
+	}
+	return c.Status.V1Beta2.Conditions
+}
+
+// SetV1Beta2Conditions sets the v1beta2 conditions on this object.
+func (c *KairosControlPlane) SetV1Beta2Conditions(conditions []metav1.Condition) {
+	if c.Status.V1Beta2 == nil {
+		c.Status.V1Beta2 = &KairosControlPlaneV1Beta2Status{}
+	}
+	c.Status.V1Beta2.Conditions = conditions
+}
+
 func init() {
 	SchemeBuilder.Register(&KairosControlPlane{}, &KairosControlPlaneList{})
 }
@@ -17,14 +17,21 @@ permissions and limitations under the License.
 package v1beta2
 
 import (
+	"fmt"
+	"net"
+	"strings"
+
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
 )
 
 // log is for logging in this package.
@@ -44,16 +51,30 @@ var _ webhook.Defaulter = &KairosControlPlane{}
 // Default implements webhook.Defaulter so a webhook will be registered for the type
 func (r *KairosControlPlane) Default() {
 	kairoscontrolplaneLog.Info("default", "name", r.Name)
+	r.setDefaults()
+}
+
+// setDefaults fills in unset fields with their default values
+func (r *KairosControlPlane) setDefaults() {
+	r.Spec.setDefaults()
+}
 
+// setDefaults fills in unset fields of a KairosControlPlane spec with their
+// default values
+func (s *KairosControlPlaneSpec) setDefaults() {
 	// Set default replicas to 1 if not specified
-	if r.Spec.Replicas == nil {
+	if s.Replicas == nil {
 		replicas := int32(1)
-		r.Spec.Replicas = &replicas
+		s.Replicas = &replicas
 	}
 
 	// Set default distribution
-	if r.Spec.Distribution == "" {
-		r.Spec.Distribution = "k0s"
+	if s.Distribution == "" {
+		s.Distribution = "k0s"
+	}
+
+	if s.ControlPlaneEndpoint != nil && s.ControlPlaneEndpoint.Port == 0 {
+		s.ControlPlaneEndpoint.Port = 6443
 	}
 }
 
@@ -64,13 +85,13 @@ var _ webhook.Validator = &KairosControlPlane{}
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (r *KairosControlPlane) ValidateCreate() (admission.Warnings, error) {
 	kairoscontrolplaneLog.Info("validate create", "name", r.Name)
-	return nil, r.validate()
+	return r.Spec.warnings(field.NewPath("spec")), r.validate()
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (r *KairosControlPlane) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
 	kairoscontrolplaneLog.Info("validate update", "name", r.Name)
-	return nil, r.validate()
+	return r.Spec.warnings(field.NewPath("spec")), r.validate()
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -79,35 +100,248 @@ func (r *KairosControlPlane) ValidateDelete() (admission.Warnings, error) {
 	return nil, nil
 }
 
+// warnings returns the admission warnings for the inline bootstrap
+// configuration
+func (s *KairosControlPlaneSpec) warnings(fldPath *field.Path) admission.Warnings {
+	if s.KairosConfigSpec == nil {
+		return nil
+	}
+	return bootstrapv1beta2.SpecWarnings(s.KairosConfigSpec, fldPath.Child("kairosConfigSpec"))
+}
+
+// Validate runs the same checks as the validating webhook against a defaulted
+// copy of the object. Controllers call it when admission webhooks are disabled.
+func (r *KairosControlPlane) Validate() error {
+	defaulted := r.DeepCopy()
+	defaulted.setDefaults()
+	return defaulted.validate()
+}
+
 // validate performs validation on the KairosControlPlane spec
 func (r *KairosControlPlane) validate() error {
+	allErrs := r.Spec.validate(field.NewPath("spec"))
+
+	if len(allErrs) > 0 {
+		return errors.NewInvalid(
+			schema.GroupKind{Group: GroupVersion.Group, Kind: "KairosControlPlane"},
+			r.Name,
+			allErrs,
+		)
+	}
+
+	return nil
+}
+
+// validate checks a KairosControlPlane spec. fldPath is the path of the spec, so
+// KairosControlPlaneTemplates report errors under spec.template.spec.
+func (s *KairosControlPlaneSpec) validate(fldPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
 
 	// Validate replicas
-	if r.Spec.Replicas != nil && *r.Spec.Replicas < 1 {
+	if s.Replicas != nil && *s.Replicas < 1 {
 		allErrs = append(allErrs, field.Invalid(
-			field.NewPath("spec", "replicas"),
-			*r.Spec.Replicas,
+			fldPath.Child("replicas"),
+			*s.Replicas,
 			"spec.replicas must be greater than or equal to 1",
 		))
 	}
 
 	// Validate distribution
-	if r.Spec.Distribution != "" && r.Spec.Distribution != "k0s" && r.Spec.Distribution != "k3s" {
+	if s.Distribution != "" && s.Distribution != "k0s" && s.Distribution != "k3s" {
 		allErrs = append(allErrs, field.Invalid(
-			field.NewPath("spec", "distribution"),
-			r.Spec.Distribution,
+			fldPath.Child("distribution"),
+			s.Distribution,
 			"spec.distribution must be one of [k0s, k3s]",
 		))
 	}
 
-	if len(allErrs) > 0 {
-		return errors.NewInvalid(
-			schema.GroupKind{Group: GroupVersion.Group, Kind: "KairosControlPlane"},
-			r.Name,
-			allErrs,
-		)
+	allErrs = append(allErrs, s.validateKairosConfig(fldPath)...)
+
+	// The virtual IP is announced as is, it cannot be a hostname
+	if ep := s.ControlPlaneEndpoint; ep != nil && net.ParseIP(ep.Host) == nil {
+		allErrs = append(allErrs, field.Invalid(
+			fldPath.Child("controlPlaneEndpoint", "host"),
+			ep.Host,
+			"controlPlaneEndpoint.host must be an IP address",
+		))
+	}
+	allErrs = append(allErrs, bootstrapv1beta2.ValidateCertSANs(s.CertSANs, fldPath.Child("certSANs"))...)
+	if s.CNI != "" {
+		allErrs = append(allErrs, s.validateCNI(fldPath)...)
+	}
+	allErrs = append(allErrs, bootstrapv1beta2.ValidateManifests(s.Addons, fldPath.Child("addons"))...)
+	if s.ControlPlaneEndpoint != nil && s.ExternalManagedEndpoint {
+		allErrs = append(allErrs, field.Forbidden(
+			fldPath.Child("externalManagedEndpoint"),
+			"externalManagedEndpoint cannot be set together with controlPlaneEndpoint",
+		))
+	}
+
+	// A rollout needs room to either add or remove a machine
+	if maxSurge, maxUnavailable := s.RolloutStrategy.limits(); maxSurge == 0 && maxUnavailable == 0 {
+		allErrs = append(allErrs, field.Invalid(
+			fldPath.Child("rolloutStrategy", "rollingUpdate"),
+			"maxSurge=0, maxUnavailable=0",
+			"maxSurge and maxUnavailable cannot both be 0",
+		))
 	}
 
+	if rs := s.RemediationStrategy; rs != nil {
+		if rs.RetryPeriod.Duration < 0 {
+			allErrs = append(allErrs, field.Invalid(
+				fldPath.Child("remediationStrategy", "retryPeriod"),
+				rs.RetryPeriod.Duration.String(),
+				"retryPeriod cannot be negative",
+			))
+		}
+		if rs.MinHealthyPeriod != nil && rs.MinHealthyPeriod.Duration < 0 {
+			allErrs = append(allErrs, field.Invalid(
+				fldPath.Child("remediationStrategy", "minHealthyPeriod"),
+				rs.MinHealthyPeriod.Duration.String(),
+				"minHealthyPeriod cannot be negative",
+			))
+		}
+	}
+
+	seen := map[string]bool{}
+	for i, name := range s.FailureDomains {
+		switch {
+		case name == "":
+			allErrs = append(allErrs, field.Required(fldPath.Child("failureDomains").Index(i), "failure domain names cannot be empty"))
+		case seen[name]:
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("failureDomains").Index(i), name))
+		}
+		seen[name] = true
+	}
+
+	if s.MachineNamingStrategy != nil {
+		allErrs = append(allErrs, validateMachineNamingStrategy(fldPath.Child("machineNamingStrategy", "template"), s.MachineNamingStrategy)...)
+	}
+
+	// osVersion is a tag of osImage and means nothing on its own
+	if s.OSVersion != "" && s.OSImage == "" {
+		allErrs = append(allErrs, field.Required(
+			fldPath.Child("osImage"),
+			"spec.osImage must be set when spec.osVersion is set",
+		))
+	}
+	// Both would upgrade the same nodes
+	if s.OSUpgrade != nil && s.OSImage != "" {
+		allErrs = append(allErrs, field.Forbidden(
+			fldPath.Child("osUpgrade"),
+			"spec.osUpgrade cannot be set together with spec.osImage",
+		))
+	}
+
+	allErrs = append(allErrs, s.validateUpgradeStrategy(fldPath)...)
+
+	return allErrs
+}
+
+// validateCNI checks that spec.cni is only set for k0s and does not conflict
+// with a network provider chosen in the inline bootstrap configuration.
+func (s *KairosControlPlaneSpec) validateCNI(fldPath *field.Path) field.ErrorList {
+	if s.Distribution == "k3s" {
+		return field.ErrorList{field.Forbidden(
+			fldPath.Child("cni"),
+			"spec.cni is only supported with spec.distribution k0s",
+		)}
+	}
+	if s.KairosConfigSpec != nil && s.KairosConfigSpec.K0sConfig != nil && s.KairosConfigSpec.K0sConfig.NetworkProvider != "" {
+		return field.ErrorList{field.Forbidden(
+			fldPath.Child("kairosConfigSpec", "k0sConfig", "networkProvider"),
+			"networkProvider cannot be set together with spec.cni",
+		)}
+	}
 	return nil
 }
+
+// validateUpgradeStrategy checks that the InPlace upgrade strategy has an
+// image to upgrade to and is the only thing upgrading the Kairos OS of the
+// control plane nodes.
+func (s *KairosControlPlaneSpec) validateUpgradeStrategy(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if s.UpgradeStrategy != UpgradeStrategyInPlace {
+		if s.InPlaceUpgrade != nil {
+			allErrs = append(allErrs, field.Forbidden(
+				fldPath.Child("inPlaceUpgrade"),
+				"spec.inPlaceUpgrade requires spec.upgradeStrategy InPlace",
+			))
+		}
+		return allErrs
+	}
+
+	if s.InPlaceUpgrade == nil {
+		return append(allErrs, field.Required(
+			fldPath.Child("inPlaceUpgrade"),
+			"spec.inPlaceUpgrade must be set when spec.upgradeStrategy is InPlace",
+		))
+	}
+	if _, err := s.InPlaceUpgrade.Tag("v1.30.0+k0s.0"); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("inPlaceUpgrade", "tagTemplate"), s.InPlaceUpgrade.TagTemplate, err.Error()))
+	}
+	// The in-place upgrade image is the OS image of the nodes
+	if s.OSImage != "" {
+		allErrs = append(allErrs, field.Forbidden(
+			fldPath.Child("osImage"),
+			"spec.osImage cannot be set when spec.upgradeStrategy is InPlace",
+		))
+	}
+	if s.OSUpgrade != nil {
+		allErrs = append(allErrs, field.Forbidden(
+			fldPath.Child("osUpgrade"),
+			"spec.osUpgrade cannot be set when spec.upgradeStrategy is InPlace",
+		))
+	}
+	return allErrs
+}
+
+// validateMachineNamingStrategy renders the naming template for two sample
+// Machines. The names must differ and be valid DNS-1123 labels; their length
+// is checked against the real cluster name when the Machines are created.
+func validateMachineNamingStrategy(fldPath *field.Path, strategy *MachineNamingStrategy) field.ErrorList {
+	first, err := renderMachineName(strategy, "cluster", "control-plane", 0, "abcde")
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, strategy.Template, err.Error())}
+	}
+	second, err := renderMachineName(strategy, "cluster", "control-plane", 1, "fghij")
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, strategy.Template, err.Error())}
+	}
+	if first == second {
+		return field.ErrorList{field.Invalid(fldPath, strategy.Template, "template must reference {{ .index }} or {{ .random }} so machine names are unique")}
+	}
+	if errs := validation.IsDNS1123Label(first); len(errs) > 0 {
+		return field.ErrorList{field.Invalid(fldPath, strategy.Template, fmt.Sprintf("template must render a valid DNS-1123 label, got %q: %s", first, strings.Join(errs, ", ")))}
+	}
+	return nil
+}
+
+// validateKairosConfig checks that the control plane machines get their
+// bootstrap configuration from exactly one of kairosConfigTemplate and
+// kairosConfigSpec, and validates an inline configuration the way the
+// KairosConfigs created from it will be.
+func (s *KairosControlPlaneSpec) validateKairosConfig(fldPath *field.Path) field.ErrorList {
+	hasTemplate := s.KairosConfigTemplate.Name != ""
+	switch {
+	case hasTemplate && s.KairosConfigSpec != nil:
+		return field.ErrorList{field.Forbidden(
+			fldPath.Child("kairosConfigSpec"),
+			"kairosConfigSpec cannot be set together with kairosConfigTemplate",
+		)}
+	case !hasTemplate && s.KairosConfigSpec == nil:
+		return field.ErrorList{field.Required(
+			fldPath.Child("kairosConfigTemplate"),
+			"one of kairosConfigTemplate or kairosConfigSpec must be set",
+		)}
+	case s.KairosConfigSpec == nil:
+		return nil
+	}
+
+	// The control plane sets these when it creates the KairosConfigs
+	config := s.KairosConfigSpec.DeepCopy()
+	config.Role = "control-plane"
+	config.Distribution = s.Distribution
+	config.SingleNode = false
+	return bootstrapv1beta2.ValidateSpec(config, fldPath.Child("kairosConfigSpec"))
+}
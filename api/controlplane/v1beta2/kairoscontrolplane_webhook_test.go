@@ -0,0 +1,461 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+)
+
+func TestKairosControlPlaneValidate(t *testing.T) {
+	configTemplate := KairosConfigTemplateReference{Name: "control-plane"}
+
+	tests := []struct {
+		name    string
+		spec    KairosControlPlaneSpec
+		wantErr string
+	}{
+		{
+			name: "kairosConfigTemplate",
+			spec: KairosControlPlaneSpec{Version: "v1.30.0+k0s.0", KairosConfigTemplate: configTemplate},
+		},
+		{
+			name: "inline kairosConfigSpec",
+			spec: KairosControlPlaneSpec{
+				Version:          "v1.30.0+k0s.0",
+				KairosConfigSpec: &bootstrapv1beta2.KairosConfigSpec{UserName: "kairos"},
+			},
+		},
+		{
+			name:    "no bootstrap configuration",
+			spec:    KairosControlPlaneSpec{Version: "v1.30.0+k0s.0"},
+			wantErr: "spec.kairosConfigTemplate: Required value",
+		},
+		{
+			name: "both bootstrap configurations",
+			spec: KairosControlPlaneSpec{
+				Version:              "v1.30.0+k0s.0",
+				KairosConfigTemplate: configTemplate,
+				KairosConfigSpec:     &bootstrapv1beta2.KairosConfigSpec{},
+			},
+			wantErr: "spec.kairosConfigSpec: Forbidden",
+		},
+		{
+			name: "invalid inline kairosConfigSpec",
+			spec: KairosControlPlaneSpec{
+				Version: "v1.30.0+k0s.0",
+				KairosConfigSpec: &bootstrapv1beta2.KairosConfigSpec{
+					Files: []bootstrapv1beta2.File{{Path: "/etc/motd", Content: "hi", Permissions: "rw-r--r--"}},
+				},
+			},
+			wantErr: "spec.kairosConfigSpec.files[0].permissions: Invalid value",
+		},
+		{
+			name: "controlPlaneEndpoint",
+			spec: KairosControlPlaneSpec{
+				Version:              "v1.30.0+k0s.0",
+				KairosConfigTemplate: configTemplate,
+				ControlPlaneEndpoint: &ControlPlaneEndpoint{Host: "192.168.1.100"},
+			},
+		},
+		{
+			name: "controlPlaneEndpoint hostname",
+			spec: KairosControlPlaneSpec{
+				Version:              "v1.30.0+k0s.0",
+				KairosConfigTemplate: configTemplate,
+				ControlPlaneEndpoint: &ControlPlaneEndpoint{Host: "api.example.com"},
+			},
+			wantErr: "spec.controlPlaneEndpoint.host: Invalid value",
+		},
+		{
+			name: "externalManagedEndpoint",
+			spec: KairosControlPlaneSpec{
+				Version:                 "v1.30.0+k0s.0",
+				KairosConfigTemplate:    configTemplate,
+				ExternalManagedEndpoint: true,
+			},
+		},
+		{
+			name: "externalManagedEndpoint with controlPlaneEndpoint",
+			spec: KairosControlPlaneSpec{
+				Version:                 "v1.30.0+k0s.0",
+				KairosConfigTemplate:    configTemplate,
+				ControlPlaneEndpoint:    &ControlPlaneEndpoint{Host: "192.168.1.100"},
+				ExternalManagedEndpoint: true,
+			},
+			wantErr: "spec.externalManagedEndpoint: Forbidden",
+		},
+		{
+			name: "certSANs",
+			spec: KairosControlPlaneSpec{
+				Version:              "v1.30.0+k0s.0",
+				KairosConfigTemplate: configTemplate,
+				CertSANs:             []string{"api.example.com", "192.0.2.10"},
+			},
+		},
+		{
+			name: "invalid certSANs",
+			spec: KairosControlPlaneSpec{
+				Version:              "v1.30.0+k0s.0",
+				KairosConfigTemplate: configTemplate,
+				CertSANs:             []string{"api.example.com:6443"},
+			},
+			wantErr: "spec.certSANs[0]: Invalid value",
+		},
+		{
+			name: "cni",
+			spec: KairosControlPlaneSpec{
+				Version:              "v1.30.0+k0s.0",
+				KairosConfigTemplate: configTemplate,
+				CNI:                  "cilium",
+			},
+		},
+		{
+			name: "cni with k3s",
+			spec: KairosControlPlaneSpec{
+				Version:              "v1.30.0+k3s1",
+				Distribution:         "k3s",
+				KairosConfigTemplate: configTemplate,
+				CNI:                  "calico",
+			},
+			wantErr: "spec.cni: Forbidden",
+		},
+		{
+			name: "cni with inline networkProvider",
+			spec: KairosControlPlaneSpec{
+				Version:          "v1.30.0+k0s.0",
+				Distribution:     "k0s",
+				KairosConfigSpec: &bootstrapv1beta2.KairosConfigSpec{K0sConfig: &bootstrapv1beta2.K0sConfig{NetworkProvider: "calico"}},
+				CNI:              "cilium",
+			},
+			wantErr: "spec.kairosConfigSpec.k0sConfig.networkProvider: Forbidden",
+		},
+		{
+			name: "addons",
+			spec: KairosControlPlaneSpec{
+				Version:              "v1.30.0+k0s.0",
+				KairosConfigTemplate: configTemplate,
+				Addons: []bootstrapv1beta2.Manifest{
+					{Name: "kubevirt-ccm", File: "ccm.yaml", Content: "kind: Deployment"},
+					{Name: "kubevirt-csi", File: "csi.yaml", Content: "kind: DaemonSet"},
+				},
+			},
+		},
+		{
+			name: "duplicate addons",
+			spec: KairosControlPlaneSpec{
+				Version:              "v1.30.0+k0s.0",
+				KairosConfigTemplate: configTemplate,
+				Addons: []bootstrapv1beta2.Manifest{
+					{Name: "ccm", File: "ccm.yaml", Content: "kind: Deployment"},
+					{Name: "ccm", File: "ccm.yaml", Content: "kind: DaemonSet"},
+				},
+			},
+			wantErr: "spec.addons[1]: Duplicate value",
+		},
+		{
+			name: "addon without content",
+			spec: KairosControlPlaneSpec{
+				Version:              "v1.30.0+k0s.0",
+				KairosConfigTemplate: configTemplate,
+				Addons:               []bootstrapv1beta2.Manifest{{Name: "ccm", File: "ccm.yaml"}},
+			},
+			wantErr: "spec.addons[0].content: Required value",
+		},
+		{
+			name: "failureDomains",
+			spec: KairosControlPlaneSpec{
+				Version:              "v1.30.0+k0s.0",
+				KairosConfigTemplate: configTemplate,
+				FailureDomains:       []string{"zone-a", "zone-b"},
+			},
+		},
+		{
+			name: "duplicate failureDomains",
+			spec: KairosControlPlaneSpec{
+				Version:              "v1.30.0+k0s.0",
+				KairosConfigTemplate: configTemplate,
+				FailureDomains:       []string{"zone-a", "zone-a"},
+			},
+			wantErr: "spec.failureDomains[1]: Duplicate value",
+		},
+		{
+			name: "inline kairosConfigSpec checked against the control plane distribution",
+			spec: KairosControlPlaneSpec{
+				Version:          "v1.30.0+k0s.0",
+				KairosConfigSpec: &bootstrapv1beta2.KairosConfigSpec{Distribution: "k3s", K3sToken: "token"},
+			},
+			wantErr: "spec.kairosConfigSpec.k3sToken: Forbidden",
+		},
+		{
+			name: "machine naming template",
+			spec: KairosControlPlaneSpec{
+				Version:               "v1.30.0+k0s.0",
+				KairosConfigTemplate:  configTemplate,
+				MachineNamingStrategy: &MachineNamingStrategy{Template: "{{ .cluster.name }}-cp-{{ .random }}"},
+			},
+		},
+		{
+			name: "machine naming template without unique part",
+			spec: KairosControlPlaneSpec{
+				Version:               "v1.30.0+k0s.0",
+				KairosConfigTemplate:  configTemplate,
+				MachineNamingStrategy: &MachineNamingStrategy{Template: "{{ .cluster.name }}-cp"},
+			},
+			wantErr: "spec.machineNamingStrategy.template: Invalid value",
+		},
+		{
+			name: "machine naming template with unknown field",
+			spec: KairosControlPlaneSpec{
+				Version:               "v1.30.0+k0s.0",
+				KairosConfigTemplate:  configTemplate,
+				MachineNamingStrategy: &MachineNamingStrategy{Template: "{{ .machine.name }}-{{ .index }}"},
+			},
+			wantErr: "spec.machineNamingStrategy.template: Invalid value",
+		},
+		{
+			name: "machine naming template rendering an invalid name",
+			spec: KairosControlPlaneSpec{
+				Version:               "v1.30.0+k0s.0",
+				KairosConfigTemplate:  configTemplate,
+				MachineNamingStrategy: &MachineNamingStrategy{Template: "CP_{{ .index }}"},
+			},
+			wantErr: "must render a valid DNS-1123 label",
+		},
+		{
+			name: "in-place upgrade strategy",
+			spec: KairosControlPlaneSpec{
+				Version:              "v1.30.0+k0s.0",
+				KairosConfigTemplate: configTemplate,
+				UpgradeStrategy:      UpgradeStrategyInPlace,
+				InPlaceUpgrade:       &InPlaceUpgrade{Image: "quay.io/kairos/ubuntu", TagTemplate: "v3.5.0-k0s{{ .version }}"},
+			},
+		},
+		{
+			name: "in-place upgrade strategy without inPlaceUpgrade",
+			spec: KairosControlPlaneSpec{
+				Version:              "v1.30.0+k0s.0",
+				KairosConfigTemplate: configTemplate,
+				UpgradeStrategy:      UpgradeStrategyInPlace,
+			},
+			wantErr: "spec.inPlaceUpgrade: Required value",
+		},
+		{
+			name: "inPlaceUpgrade without in-place upgrade strategy",
+			spec: KairosControlPlaneSpec{
+				Version:              "v1.30.0+k0s.0",
+				KairosConfigTemplate: configTemplate,
+				InPlaceUpgrade:       &InPlaceUpgrade{Image: "quay.io/kairos/ubuntu"},
+			},
+			wantErr: "spec.inPlaceUpgrade: Forbidden",
+		},
+		{
+			name: "in-place upgrade tag template with unknown key",
+			spec: KairosControlPlaneSpec{
+				Version:              "v1.30.0+k0s.0",
+				KairosConfigTemplate: configTemplate,
+				UpgradeStrategy:      UpgradeStrategyInPlace,
+				InPlaceUpgrade:       &InPlaceUpgrade{Image: "quay.io/kairos/ubuntu", TagTemplate: "{{ .kairosVersion }}"},
+			},
+			wantErr: "spec.inPlaceUpgrade.tagTemplate: Invalid value",
+		},
+		{
+			name: "in-place upgrade strategy with osImage",
+			spec: KairosControlPlaneSpec{
+				Version:              "v1.30.0+k0s.0",
+				KairosConfigTemplate: configTemplate,
+				UpgradeStrategy:      UpgradeStrategyInPlace,
+				InPlaceUpgrade:       &InPlaceUpgrade{Image: "quay.io/kairos/ubuntu"},
+				OSImage:              "quay.io/kairos/ubuntu:v3.5.0",
+			},
+			wantErr: "spec.osImage: Forbidden",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&KairosControlPlane{Spec: tt.spec}).Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Expected no error, got %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestKairosControlPlaneMachineName(t *testing.T) {
+	kcp := &KairosControlPlane{}
+	kcp.Name = "control-plane"
+
+	name, err := kcp.MachineName("cluster", 2, "abcde")
+	if err != nil || name != "control-plane-2" {
+		t.Errorf("Expected the default name control-plane-2, got %q (%v)", name, err)
+	}
+
+	kcp.Spec.MachineNamingStrategy = &MachineNamingStrategy{Template: "{{ .cluster.name }}-cp{{ .index }}-{{ .random }}"}
+	name, err = kcp.MachineName("cluster", 2, "abcde")
+	if err != nil || name != "cluster-cp2-abcde" {
+		t.Errorf("Expected cluster-cp2-abcde, got %q (%v)", name, err)
+	}
+
+	// The cluster name is only known when the machine is created
+	_, err = kcp.MachineName(strings.Repeat("c", 60), 2, "abcde")
+	if err == nil || !strings.Contains(err.Error(), "is invalid") {
+		t.Errorf("Expected an invalid name error, got %v", err)
+	}
+}
+
+func TestKairosControlPlaneTemplateValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    KairosControlPlaneTemplateResourceSpec
+		wantErr string
+	}{
+		{
+			name: "inline kairosConfigSpec",
+			spec: KairosControlPlaneTemplateResourceSpec{
+				KairosConfigSpec: &bootstrapv1beta2.KairosConfigSpec{UserName: "kairos"},
+			},
+		},
+		{
+			name:    "no bootstrap configuration",
+			spec:    KairosControlPlaneTemplateResourceSpec{},
+			wantErr: "spec.template.spec.kairosConfigTemplate: Required value",
+		},
+		{
+			name: "osVersion without osImage",
+			spec: KairosControlPlaneTemplateResourceSpec{
+				KairosConfigTemplate: KairosConfigTemplateReference{Name: "control-plane"},
+				OSVersion:            "v3.2.1",
+			},
+			wantErr: "spec.template.spec.osImage: Required value",
+		},
+		{
+			name: "osUpgrade together with osImage",
+			spec: KairosControlPlaneTemplateResourceSpec{
+				KairosConfigTemplate: KairosConfigTemplateReference{Name: "control-plane"},
+				OSImage:              "quay.io/kairos/ubuntu:v3.5.0",
+				OSUpgrade:            &bootstrapv1beta2.OSUpgrade{Image: "quay.io/kairos/ubuntu", Version: "v3.5.0"},
+			},
+			wantErr: "spec.template.spec.osUpgrade: Forbidden",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := &KairosControlPlaneTemplate{
+				Spec: KairosControlPlaneTemplateSpec{
+					Template: KairosControlPlaneTemplateResource{Spec: tt.spec},
+				},
+			}
+			_, err := template.ValidateCreate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Expected no error, got %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestKairosControlPlaneTemplateValidateUpdate(t *testing.T) {
+	oldSpec := KairosControlPlaneTemplateResourceSpec{
+		KairosConfigSpec: &bootstrapv1beta2.KairosConfigSpec{
+			UserName: "kairos",
+			Install:  &bootstrapv1beta2.InstallConfig{Device: "/dev/sda"},
+		},
+		RolloutStrategy: &RolloutStrategy{Type: "RollingUpdate"},
+	}
+	tests := []struct {
+		name    string
+		mutate  func(spec *KairosControlPlaneTemplateResourceSpec)
+		wantErr []string
+	}{
+		{
+			name:   "unchanged",
+			mutate: func(*KairosControlPlaneTemplateResourceSpec) {},
+		},
+		{
+			name: "mutable fields",
+			mutate: func(spec *KairosControlPlaneTemplateResourceSpec) {
+				spec.MachineTemplate = &KairosControlPlaneTemplateMachineTemplate{
+					NodeDrainTimeout: &metav1.Duration{Duration: time.Minute},
+				}
+				spec.RolloutStrategy = nil
+				spec.RemediationStrategy = &RemediationStrategy{MaxRetry: ptr.To[int32](3)}
+			},
+		},
+		{
+			name: "nested field",
+			mutate: func(spec *KairosControlPlaneTemplateResourceSpec) {
+				spec.KairosConfigSpec.Install.Device = "/dev/vda"
+			},
+			wantErr: []string{"spec.template.spec.kairosConfigSpec.install.device: Forbidden"},
+		},
+		{
+			name: "several fields",
+			mutate: func(spec *KairosControlPlaneTemplateResourceSpec) {
+				spec.Distribution = "k3s"
+				spec.FailureDomains = []string{"fd-1"}
+			},
+			wantErr: []string{
+				"spec.template.spec.distribution: Forbidden",
+				"spec.template.spec.failureDomains: Forbidden",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := &KairosControlPlaneTemplate{
+				Spec: KairosControlPlaneTemplateSpec{
+					Template: KairosControlPlaneTemplateResource{Spec: *oldSpec.DeepCopy()},
+				},
+			}
+			template := old.DeepCopy()
+			tt.mutate(&template.Spec.Template.Spec)
+
+			_, err := template.ValidateUpdate(old)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected errors %q, got none", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected error containing %q, got %v", want, err)
+				}
+			}
+			if strings.Contains(err.Error(), "kairosConfigSpec: Forbidden") {
+				t.Errorf("Expected errors on the changed leaf fields only, got %v", err)
+			}
+		})
+	}
+}
@@ -18,6 +18,9 @@ package v1beta2
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
 )
 
 // KairosControlPlaneTemplateSpec defines the desired state of KairosControlPlaneTemplate
@@ -33,11 +36,152 @@ type KairosControlPlaneTemplateResource struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	// Spec is the specification of the KairosControlPlane
-	Spec KairosControlPlaneSpec `json:"spec"`
+	Spec KairosControlPlaneTemplateResourceSpec `json:"spec"`
+}
+
+// KairosControlPlaneTemplateResourceSpec is the KairosControlPlane spec stamped
+// out by a ClusterClass. Replicas, version and the machine infrastructure
+// reference are left out because the Cluster topology sets them.
+type KairosControlPlaneTemplateResourceSpec struct {
+	// Distribution specifies the Kubernetes distribution to install
+	// +kubebuilder:validation:Enum=k0s;k3s
+	// +kubebuilder:default=k0s
+	// +optional
+	Distribution string `json:"distribution,omitempty"`
+
+	// MachineTemplate defines the template for creating control plane machines
+	// +optional
+	MachineTemplate *KairosControlPlaneTemplateMachineTemplate `json:"machineTemplate,omitempty"`
+
+	// KairosConfigTemplate is a reference to a KairosConfigTemplate resource
+	// Exactly one of kairosConfigTemplate and kairosConfigSpec must be set.
+	// +optional
+	KairosConfigTemplate KairosConfigTemplateReference `json:"kairosConfigTemplate,omitempty,omitzero"`
+
+	// KairosConfigSpec is the bootstrap configuration of the control plane
+	// machines, given inline instead of through kairosConfigTemplate so that
+	// ClusterClass patches can change it. Role, distribution,
+	// kubernetesVersion and singleNode are set by the control plane.
+	// +optional
+	KairosConfigSpec *bootstrapv1beta2.KairosConfigSpec `json:"kairosConfigSpec,omitempty"`
+
+	// ControlPlaneEndpoint is a virtual IP the control plane nodes announce for
+	// the Kubernetes API, so multi-node control planes get an HA endpoint
+	// without an external load balancer. k0s nodes use the keepalived based
+	// control plane load balancing of k0s, k3s nodes run kube-vip as a static
+	// pod. Cluster API uses host and port as the Cluster endpoint when the
+	// infrastructure provider does not set one.
+	// +optional
+	ControlPlaneEndpoint *ControlPlaneEndpoint `json:"controlPlaneEndpoint,omitempty"`
+
+	// ExternalManagedEndpoint declares that the Cluster's controlPlaneEndpoint
+	// is managed outside of Cluster API, e.g. by a load balancer in front of
+	// the control plane nodes. The controller then waits for the endpoint to
+	// be set instead of deriving it from the machines, does not create a
+	// control plane load balancer Service for KubeVirt, and adds the endpoint
+	// host to the API server certificate. Mutually exclusive with
+	// controlPlaneEndpoint.
+	// +optional
+	ExternalManagedEndpoint bool `json:"externalManagedEndpoint,omitempty"`
+
+	// CertSANs are additional subject alternative names for the API server
+	// certificate, e.g. external DNS names or virtual IPs. They are added to
+	// spec.certSANs of the KairosConfig of every control plane machine.
+	// Changing them replaces the control plane machines.
+	// +optional
+	CertSANs []string `json:"certSANs,omitempty"`
+
+	// CNI selects the network plugin of a k0s cluster: kuberouter, the k0s
+	// default, calico, cilium, which k0s installs through its Helm extension,
+	// or custom, which deploys none so that one can be brought in through
+	// manifests. It sets spec.k0sConfig.networkProvider of the KairosConfig
+	// of every control plane machine. Changing it replaces the control plane
+	// machines. k0s only.
+	// +kubebuilder:validation:Enum=kuberouter;calico;cilium;custom
+	// +optional
+	CNI string `json:"cni,omitempty"`
+
+	// Addons are manifests, such as a cloud controller manager or a CSI
+	// driver, added to spec.manifests of the KairosConfig of every control
+	// plane machine, so that k0s or k3s applies them when the cluster starts.
+	// They replace bootstrap manifests with the same name and file. Changing
+	// them replaces the control plane machines.
+	// +optional
+	Addons []bootstrapv1beta2.Manifest `json:"addons,omitempty"`
+
+	// RolloutStrategy defines the strategy for rolling out updates
+	// +optional
+	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// RemediationStrategy configures how unhealthy control plane machines are
+	// replaced. Machines are remediated when they carry the
+	// cluster.x-k8s.io/remediate-machine annotation or a MachineHealthCheck
+	// marks them for remediation.
+	// +optional
+	RemediationStrategy *RemediationStrategy `json:"remediationStrategy,omitempty"`
+
+	// FailureDomains lists the failure domains control plane machines are
+	// spread across. When empty, the failure domains in the Cluster status
+	// that are marked for control plane use are used. New machines go to the
+	// failure domain with the fewest control plane machines and scale down
+	// removes a machine from the one with the most.
+	// +listType=set
+	// +optional
+	FailureDomains []string `json:"failureDomains,omitempty"`
+
+	// MachineNamingStrategy configures the names of the control plane
+	// Machines and of the KairosConfigs and infrastructure machines created
+	// with them
+	// +optional
+	MachineNamingStrategy *MachineNamingStrategy `json:"machineNamingStrategy,omitempty"`
+
+	// OSImage is the Kairos OS image the control plane nodes should run,
+	// e.g. quay.io/kairos/ubuntu. When set, the controller upgrades the nodes
+	// in place through the kairos operator in the workload cluster.
+	// +optional
+	OSImage string `json:"osImage,omitempty"`
+
+	// OSVersion is the tag of OSImage to run. When empty, OSImage must be a
+	// complete image reference including tag or digest.
+	// +optional
+	OSVersion string `json:"osVersion,omitempty"`
+
+	// OSUpgrade upgrades the Kairos OS of the control plane nodes in place
+	// with a system-upgrade-controller Plan in the workload cluster. Mutually
+	// exclusive with osImage.
+	// +optional
+	OSUpgrade *bootstrapv1beta2.OSUpgrade `json:"osUpgrade,omitempty"`
+
+	// UpgradeStrategy selects how a spec.version change reaches the existing
+	// control plane machines: Replace (the default) or InPlace
+	// +kubebuilder:validation:Enum=Replace;InPlace
+	// +optional
+	UpgradeStrategy string `json:"upgradeStrategy,omitempty"`
+
+	// InPlaceUpgrade configures the InPlace upgrade strategy
+	// +optional
+	InPlaceUpgrade *InPlaceUpgrade `json:"inPlaceUpgrade,omitempty"`
+}
+
+// KairosControlPlaneTemplateMachineTemplate defines the template for control
+// plane machines created from a KairosControlPlaneTemplate
+type KairosControlPlaneTemplateMachineTemplate struct {
+	// NodeDrainTimeout is the total amount of time that the controller will spend
+	// on draining a controlplane node
+	// +optional
+	NodeDrainTimeout *metav1.Duration `json:"nodeDrainTimeout,omitempty"`
+
+	// Metadata is the metadata to apply to the machines
+	// +optional
+	Metadata clusterv1.ObjectMeta `json:"metadata,omitempty"`
 }
 
+// +genclient
+// +genclient:noStatus
 // +kubebuilder:object:root=true
-// +kubebuilder:resource:path=kairoscontrolplanetemplates,scope=Namespaced,categories=cluster-api
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=kairoscontrolplanetemplates,scope=Namespaced,categories=cluster-api,shortName=kcpt-kairos
+// +kubebuilder:printcolumn:name="Distribution",type="string",JSONPath=".spec.template.spec.distribution",description="Kubernetes distribution"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // KairosControlPlaneTemplate is the Schema for the kairoscontrolplanetemplates API
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var kairoscontrolplanetemplateLog = logf.Log.WithName("kairoscontrolplanetemplate-resource")
+
+// SetupWebhookWithManager sets up the webhook with the Manager.
+func (r *KairosControlPlaneTemplate) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-controlplane-cluster-x-k8s-io-v1beta2-kairoscontrolplanetemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=controlplane.cluster.x-k8s.io,resources=kairoscontrolplanetemplates,verbs=create;update,versions=v1beta2,name=vkairoscontrolplanetemplate.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &KairosControlPlaneTemplate{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *KairosControlPlaneTemplate) ValidateCreate() (admission.Warnings, error) {
+	kairoscontrolplanetemplateLog.Info("validate create", "name", r.Name)
+	return r.Spec.Template.Spec.controlPlaneSpec().warnings(field.NewPath("spec", "template", "spec")), r.invalid(r.validate())
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *KairosControlPlaneTemplate) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	kairoscontrolplanetemplateLog.Info("validate update", "name", r.Name)
+	oldTemplate, ok := old.(*KairosControlPlaneTemplate)
+	if !ok {
+		return nil, errors.NewBadRequest(fmt.Sprintf("expected a KairosControlPlaneTemplate but got a %T", old))
+	}
+	fldPath := field.NewPath("spec", "template", "spec")
+	allErrs := r.Spec.Template.Spec.validateImmutable(&oldTemplate.Spec.Template.Spec, fldPath)
+	allErrs = append(allErrs, r.validate()...)
+	return r.Spec.Template.Spec.controlPlaneSpec().warnings(fldPath), r.invalid(allErrs)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *KairosControlPlaneTemplate) ValidateDelete() (admission.Warnings, error) {
+	kairoscontrolplanetemplateLog.Info("validate delete", "name", r.Name)
+	return nil, nil
+}
+
+// validate checks the templated spec with the defaults the KairosControlPlane
+// webhook applies to the KairosControlPlanes created from it.
+func (r *KairosControlPlaneTemplate) validate() field.ErrorList {
+	spec := r.Spec.Template.Spec.controlPlaneSpec()
+	spec.setDefaults()
+	return spec.validate(field.NewPath("spec", "template", "spec"))
+}
+
+// invalid wraps allErrs in an Invalid error, or returns nil when empty.
+func (r *KairosControlPlaneTemplate) invalid(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return errors.NewInvalid(
+		schema.GroupKind{Group: GroupVersion.Group, Kind: "KairosControlPlaneTemplate"},
+		r.Name,
+		allErrs,
+	)
+}
+
+// validateImmutable rejects changes to the templated spec. Following the
+// Cluster API template contract, a changed spec needs a new template that the
+// ClusterClass is pointed to, so the topology controller rolls it out. Only
+// machineTemplate, rolloutStrategy and remediationStrategy can be changed, as
+// they do not affect the machines created from the template.
+func (s *KairosControlPlaneTemplateResourceSpec) validateImmutable(old *KairosControlPlaneTemplateResourceSpec, fldPath *field.Path) field.ErrorList {
+	newSpec, oldSpec := s.DeepCopy(), old.DeepCopy()
+	for _, spec := range []*KairosControlPlaneTemplateResourceSpec{newSpec, oldSpec} {
+		spec.MachineTemplate = nil
+		spec.RolloutStrategy = nil
+		spec.RemediationStrategy = nil
+	}
+	return immutableFieldErrors(reflect.ValueOf(*newSpec), reflect.ValueOf(*oldSpec), fldPath)
+}
+
+// immutableFieldErrors returns a Forbidden error for every changed field of
+// the structs newValue and oldValue, descending into nested structs so the
+// errors point at the changed leaf fields.
+func immutableFieldErrors(newValue, oldValue reflect.Value, fldPath *field.Path) field.ErrorList {
+	if newValue.Kind() == reflect.Ptr && !newValue.IsNil() && !oldValue.IsNil() {
+		return immutableFieldErrors(newValue.Elem(), oldValue.Elem(), fldPath)
+	}
+	// Apimachinery types such as Quantity and Duration are compared as a whole
+	if newValue.Kind() != reflect.Struct || strings.HasPrefix(newValue.Type().PkgPath(), "k8s.io/apimachinery/") {
+		if apiequality.Semantic.DeepEqual(newValue.Interface(), oldValue.Interface()) {
+			return nil
+		}
+		return field.ErrorList{field.Forbidden(fldPath, "field is immutable; create a new KairosControlPlaneTemplate and point the ClusterClass to it instead")}
+	}
+
+	var allErrs field.ErrorList
+	for i := 0; i < newValue.NumField(); i++ {
+		structField := newValue.Type().Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(structField.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		childPath := fldPath
+		switch {
+		case strings.Contains(opts, "inline"):
+		case name == "":
+			childPath = fldPath.Child(structField.Name)
+		default:
+			childPath = fldPath.Child(name)
+		}
+		allErrs = append(allErrs, immutableFieldErrors(newValue.Field(i), oldValue.Field(i), childPath)...)
+	}
+	return allErrs
+}
+
+// controlPlaneSpec returns the KairosControlPlane spec stamped out from the
+// template, without the fields the Cluster topology fills in.
+func (s *KairosControlPlaneTemplateResourceSpec) controlPlaneSpec() *KairosControlPlaneSpec {
+	s = s.DeepCopy()
+	spec := &KairosControlPlaneSpec{
+		Distribution:            s.Distribution,
+		KairosConfigTemplate:    s.KairosConfigTemplate,
+		KairosConfigSpec:        s.KairosConfigSpec,
+		ControlPlaneEndpoint:    s.ControlPlaneEndpoint,
+		ExternalManagedEndpoint: s.ExternalManagedEndpoint,
+		CertSANs:                s.CertSANs,
+		CNI:                     s.CNI,
+		Addons:                  s.Addons,
+		RolloutStrategy:         s.RolloutStrategy,
+		RemediationStrategy:     s.RemediationStrategy,
+		FailureDomains:          s.FailureDomains,
+		MachineNamingStrategy:   s.MachineNamingStrategy,
+		OSImage:                 s.OSImage,
+		OSVersion:               s.OSVersion,
+		OSUpgrade:               s.OSUpgrade,
+		UpgradeStrategy:         s.UpgradeStrategy,
+		InPlaceUpgrade:          s.InPlaceUpgrade,
+	}
+	if s.MachineTemplate != nil {
+		spec.MachineTemplate.NodeDrainTimeout = s.MachineTemplate.NodeDrainTimeout
+		spec.MachineTemplate.Metadata = s.MachineTemplate.Metadata
+	}
+	return spec
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// DefaultMachineNameTemplate names control plane Machines after the
+// KairosControlPlane and their index
+const DefaultMachineNameTemplate = "{{ .kairosControlPlane.name }}-{{ .index }}"
+
+// MachineName renders the name of the control plane Machine with the given
+// index from spec.machineNamingStrategy. random is substituted for
+// {{ .random }}. The result must be a valid DNS-1123 label.
+func (r *KairosControlPlane) MachineName(clusterName string, index int32, random string) (string, error) {
+	name, err := renderMachineName(r.Spec.MachineNamingStrategy, clusterName, r.Name, index, random)
+	if err != nil {
+		return "", err
+	}
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return "", fmt.Errorf("machine name %q is invalid: %v", name, errs)
+	}
+	return name, nil
+}
+
+// renderMachineName executes the naming template with the given values
+func renderMachineName(strategy *MachineNamingStrategy, clusterName, kcpName string, index int32, random string) (string, error) {
+	text := DefaultMachineNameTemplate
+	if strategy != nil && strategy.Template != "" {
+		text = strategy.Template
+	}
+	tmpl, err := template.New("machineName").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse machine name template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]interface{}{
+		"cluster":            map[string]interface{}{"name": clusterName},
+		"kairosControlPlane": map[string]interface{}{"name": kcpName},
+		"index":              index,
+		"random":             random,
+	}); err != nil {
+		return "", fmt.Errorf("failed to render machine name template: %w", err)
+	}
+	return buf.String(), nil
+}
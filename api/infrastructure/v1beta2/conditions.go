@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package v1beta2
+
+// Condition types for KairosCluster, KairosMachine and KairosHost
+const (
+	// PausedCondition reports whether reconciliation is paused by the
+	// cluster.x-k8s.io/paused annotation or a paused Cluster
+	PausedCondition = "Paused"
+
+	// BootstrapDataDeliveredCondition reports whether the bootstrap data of
+	// the Machine bound to a KairosHost is published in its status
+	BootstrapDataDeliveredCondition = "BootstrapDataDelivered"
+
+	// BootstrapDataAppliedCondition is set by the host agent once it applied
+	// the bootstrap data. It is removed when the agent reset the host.
+	BootstrapDataAppliedCondition = "BootstrapDataApplied"
+)
+
+// Condition reasons
+const (
+	// WaitingForClusterInfrastructureReason indicates that the KairosMachine waits for the KairosCluster
+	// to be ready
+	WaitingForClusterInfrastructureReason = "WaitingForClusterInfrastructure"
+
+	// WaitingForBootstrapDataReason indicates that the KairosMachine waits for the bootstrap data of its
+	// Machine
+	WaitingForBootstrapDataReason = "WaitingForBootstrapData"
+
+	// WaitingForHostReason indicates that no KairosHost matching the
+	// hostSelector of the KairosMachine is available
+	WaitingForHostReason = "WaitingForHost"
+
+	// HostNotFoundReason indicates that the KairosHost bound to the
+	// KairosMachine was deleted
+	HostNotFoundReason = "HostNotFound"
+
+	// WaitingForProviderIDReason indicates that the KairosHost waits for its
+	// Machine to carry the provider ID before the bootstrap data is delivered,
+	// so the host applies bootstrap data that sets it on the Node
+	WaitingForProviderIDReason = "WaitingForProviderID"
+
+	// NotPausedReason indicates that reconciliation is not paused
+	NotPausedReason = "NotPaused"
+)
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// KairosClusterSpec defines the desired state of KairosCluster
+type KairosClusterSpec struct {
+	// ControlPlaneEndpoint is the endpoint the workload cluster API server is
+	// reached at, usually a virtual IP or the address of a control plane
+	// host. It is passed to the Cluster as is. When empty, the endpoint comes
+	// from the control plane, e.g. the KairosControlPlane
+	// spec.controlPlaneEndpoint, or is set on the Cluster directly.
+	// +optional
+	ControlPlaneEndpoint clusterv1.APIEndpoint `json:"controlPlaneEndpoint,omitempty"`
+}
+
+// KairosClusterStatus defines the observed state of KairosCluster
+type KairosClusterStatus struct {
+	// Ready is true once the cluster infrastructure is ready. Kairos hosts
+	// bring their own infrastructure, so it is set as soon as the
+	// KairosCluster belongs to a Cluster.
+	// Contract: InfrastructureCluster MUST expose ready
+	// +optional
+	Ready bool `json:"ready"`
+
+	// Initialization provides observations of the KairosCluster
+	// initialization process. This is part of the Cluster API v1beta2
+	// contract.
+	// +optional
+	Initialization KairosClusterInitializationStatus `json:"initialization,omitempty,omitzero"`
+
+	// Conditions defines current service state of the KairosCluster
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the latest generation observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// KairosClusterInitializationStatus provides observations of the KairosCluster initialization process.
+// +kubebuilder:validation:MinProperties=1
+type KairosClusterInitializationStatus struct {
+	// Provisioned is true when the cluster infrastructure is ready.
+	// +optional
+	Provisioned *bool `json:"provisioned,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=kairosclusters,scope=Namespaced,categories=cluster-api,shortName=kcl
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels['cluster\\.x-k8s\\.io/cluster-name']",description="Cluster"
+// +kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready",description="Cluster infrastructure ready"
+// +kubebuilder:printcolumn:name="Endpoint",type="string",JSONPath=".spec.controlPlaneEndpoint.host",description="Control plane endpoint"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// KairosCluster is the Schema for the kairosclusters API
+type KairosCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KairosClusterSpec   `json:"spec,omitempty"`
+	Status KairosClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KairosClusterList contains a list of KairosCluster
+type KairosClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KairosCluster `json:"items"`
+}
+
+// GetConditions returns the set of conditions for this object.
+func (c *KairosCluster) GetConditions() clusterv1.Conditions {
+	return c.Status.Conditions
+}
+
+// SetConditions sets the conditions on this object.
+func (c *KairosCluster) SetConditions(conditions clusterv1.Conditions) {
+	c.Status.Conditions = conditions
+}
+
+func init() {
+	SchemeBuilder.Register(&KairosCluster{}, &KairosClusterList{})
+}
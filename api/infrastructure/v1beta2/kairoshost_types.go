@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// KairosHostSpec defines the desired state of KairosHost
+type KairosHostSpec struct {
+	// Address is the address the host is reachable at, as reported by the
+	// host agent
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// ConsumerRef references the KairosMachine the host is bound to. It is
+	// set by the controller when it binds the host and cleared when the
+	// KairosMachine is deleted.
+	// +optional
+	ConsumerRef *corev1.ObjectReference `json:"consumerRef,omitempty"`
+}
+
+// KairosHostStatus defines the observed state of KairosHost
+type KairosHostStatus struct {
+	// BootstrapDataSecretName is the Secret holding the bootstrap data of the
+	// Machine the host is bound to, under the "value" key. The host agent
+	// applies it.
+	// +optional
+	BootstrapDataSecretName string `json:"bootstrapDataSecretName,omitempty"`
+
+	// Conditions defines current service state of the KairosHost
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the latest generation observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=kairoshosts,scope=Namespaced,categories=cluster-api,shortName=kh
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels['cluster\\.x-k8s\\.io/cluster-name']",description="Cluster"
+// +kubebuilder:printcolumn:name="Machine",type="string",JSONPath=".spec.consumerRef.name",description="KairosMachine the host is bound to"
+// +kubebuilder:printcolumn:name="Address",type="string",JSONPath=".spec.address",description="Host address"
+// +kubebuilder:printcolumn:name="Applied",type="string",JSONPath=".status.conditions[?(@.type=='BootstrapDataApplied')].status",description="Bootstrap data applied"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// KairosHost is the Schema for the kairoshosts API. A KairosHost is created
+// by the agent of a host that already runs Kairos and is bound to a
+// KairosMachine that selects it.
+type KairosHost struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KairosHostSpec   `json:"spec,omitempty"`
+	Status KairosHostStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KairosHostList contains a list of KairosHost
+type KairosHostList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KairosHost `json:"items"`
+}
+
+// GetConditions returns the set of conditions for this object.
+func (h *KairosHost) GetConditions() clusterv1.Conditions {
+	return h.Status.Conditions
+}
+
+// SetConditions sets the conditions on this object.
+func (h *KairosHost) SetConditions(conditions clusterv1.Conditions) {
+	h.Status.Conditions = conditions
+}
+
+func init() {
+	SchemeBuilder.Register(&KairosHost{}, &KairosHostList{})
+}
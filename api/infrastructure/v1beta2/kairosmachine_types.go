@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// ProviderIDPrefix prefixes the provider IDs of Kairos hosts
+const ProviderIDPrefix = "kairos://"
+
+// KairosMachineSpec defines the desired state of KairosMachine
+type KairosMachineSpec struct {
+	// ProviderID identifies the host in the workload cluster and is copied to
+	// the Machine and the Node. When empty, the controller sets it to
+	// kairos://<namespace>/<name>.
+	// +optional
+	ProviderID *string `json:"providerID,omitempty"`
+
+	// HostSelector binds the machine to an available KairosHost matching it.
+	// The host agent then applies the bootstrap data of the Machine. An empty
+	// selector matches every host in the namespace. When unset, the bootstrap
+	// data is applied to the host by other means.
+	// +optional
+	HostSelector *metav1.LabelSelector `json:"hostSelector,omitempty"`
+}
+
+// KairosMachineStatus defines the observed state of KairosMachine
+type KairosMachineStatus struct {
+	// Ready is true once the bootstrap data of the Machine is available, the
+	// provider ID is set and, with a hostSelector, a host is bound.
+	// Contract: InfrastructureMachine MUST expose ready
+	// +optional
+	Ready bool `json:"ready"`
+
+	// Initialization provides observations of the KairosMachine
+	// initialization process. This is part of the Cluster API v1beta2
+	// contract.
+	// +optional
+	Initialization KairosMachineInitializationStatus `json:"initialization,omitempty,omitzero"`
+
+	// HostRef references the KairosHost bound to the machine
+	// +optional
+	HostRef *corev1.ObjectReference `json:"hostRef,omitempty"`
+
+	// Conditions defines current service state of the KairosMachine
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the latest generation observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// KairosMachineInitializationStatus provides observations of the KairosMachine initialization process.
+// +kubebuilder:validation:MinProperties=1
+type KairosMachineInitializationStatus struct {
+	// Provisioned is true when the machine infrastructure is ready.
+	// +optional
+	Provisioned *bool `json:"provisioned,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=kairosmachines,scope=Namespaced,categories=cluster-api,shortName=kma
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels['cluster\\.x-k8s\\.io/cluster-name']",description="Cluster"
+// +kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready",description="Machine infrastructure ready"
+// +kubebuilder:printcolumn:name="ProviderID",type="string",JSONPath=".spec.providerID",description="Provider ID"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// KairosMachine is the Schema for the kairosmachines API
+type KairosMachine struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KairosMachineSpec   `json:"spec,omitempty"`
+	Status KairosMachineStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KairosMachineList contains a list of KairosMachine
+type KairosMachineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KairosMachine `json:"items"`
+}
+
+// GetConditions returns the set of conditions for this object.
+func (m *KairosMachine) GetConditions() clusterv1.Conditions {
+	return m.Status.Conditions
+}
+
+// SetConditions sets the conditions on this object.
+func (m *KairosMachine) SetConditions(conditions clusterv1.Conditions) {
+	m.Status.Conditions = conditions
+}
+
+func init() {
+	SchemeBuilder.Register(&KairosMachine{}, &KairosMachineList{})
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KairosMachineTemplateSpec defines the desired state of KairosMachineTemplate
+type KairosMachineTemplateSpec struct {
+	// Template is the KairosMachine template to be used for each Machine
+	Template KairosMachineTemplateResource `json:"template"`
+}
+
+// KairosMachineTemplateResource defines the template for KairosMachine
+type KairosMachineTemplateResource struct {
+	// Standard object's metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec is the specification of the KairosMachine
+	Spec KairosMachineSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=kairosmachinetemplates,scope=Namespaced,categories=cluster-api,shortName=kmat
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// KairosMachineTemplate is the Schema for the kairosmachinetemplates API
+type KairosMachineTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KairosMachineTemplateSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KairosMachineTemplateList contains a list of KairosMachineTemplate
+type KairosMachineTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KairosMachineTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KairosMachineTemplate{}, &KairosMachineTemplateList{})
+}
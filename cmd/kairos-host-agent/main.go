@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Command kairos-host-agent runs on hosts that already run Kairos. It
+// registers the host as a KairosHost in the management cluster and applies
+// the bootstrap data of the Machine the host gets bound to.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	infrastructurev1beta2 "github.com/kairos-io/kairos-capi/api/infrastructure/v1beta2"
+	"github.com/kairos-io/kairos-capi/internal/hostagent"
+	"github.com/kairos-io/kairos-capi/internal/version"
+)
+
+func main() {
+	var name string
+	var namespace string
+	var hostLabels string
+	var address string
+	var configPath string
+	var applyCommand string
+	var resetCommand string
+	var interval time.Duration
+	var showVersion bool
+	hostname, _ := os.Hostname()
+	flag.StringVar(&name, "name", hostname, "Name of the KairosHost of this host. Defaults to the hostname.")
+	flag.StringVar(&namespace, "namespace", "default", "Namespace of the KairosHost of this host.")
+	flag.StringVar(&hostLabels, "labels", "", "Comma separated key=value labels set on the KairosHost, matched by KairosMachine hostSelectors.")
+	flag.StringVar(&address, "address", "", "Address of the host reported on the KairosHost.")
+	flag.StringVar(&configPath, "config-path", hostagent.DefaultConfigPath, "File the bootstrap data is written to.")
+	flag.StringVar(&applyCommand, "apply-command", strings.Join(hostagent.DefaultApplyCommand, " "),
+		"Command run after the bootstrap data was written. Empty to run none.")
+	flag.StringVar(&resetCommand, "reset-command", strings.Join(hostagent.DefaultResetCommand, " "),
+		"Command run once the host is released by its KairosMachine. Empty to run none.")
+	flag.DurationVar(&interval, "interval", 30*time.Second, "How often the KairosHost is synced.")
+	flag.BoolVar(&showVersion, "version", false, "Print version information and exit.")
+	opts := zap.Options{}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	if showVersion {
+		fmt.Println(version.Get())
+		os.Exit(0)
+	}
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	log := ctrl.Log.WithName("kairos-host-agent")
+
+	if name == "" {
+		log.Error(nil, "--name is required when the hostname is unknown")
+		os.Exit(1)
+	}
+	parsedLabels, err := labels.ConvertSelectorToLabelsMap(hostLabels)
+	if err != nil {
+		log.Error(err, "invalid --labels")
+		os.Exit(1)
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(infrastructurev1beta2.AddToScheme(scheme))
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		log.Error(err, "unable to create client")
+		os.Exit(1)
+	}
+
+	agent := &hostagent.Agent{
+		Client:       c,
+		Log:          log,
+		Name:         name,
+		Namespace:    namespace,
+		Labels:       parsedLabels,
+		Address:      address,
+		ConfigPath:   configPath,
+		ApplyCommand: strings.Fields(applyCommand),
+		ResetCommand: strings.Fields(resetCommand),
+	}
+	log.Info("Starting host agent", "version", version.Get().Version, "name", name, "namespace", namespace)
+	agent.Run(ctrl.SetupSignalHandler(), interval)
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+//go:embed templates/clusterclass.yaml.tmpl
+var clusterClassTemplate string
+
+// infrastructureProvider describes the infrastructure templates a ClusterClass
+// needs from one Cluster API infrastructure provider.
+type infrastructureProvider struct {
+	APIVersion          string
+	ClusterTemplateKind string
+	MachineTemplateKind string
+}
+
+var infrastructureProviders = map[string]infrastructureProvider{
+	"docker": {
+		APIVersion:          "infrastructure.cluster.x-k8s.io/v1beta1",
+		ClusterTemplateKind: "DockerClusterTemplate",
+		MachineTemplateKind: "DockerMachineTemplate",
+	},
+	"kubevirt": {
+		APIVersion:          "infrastructure.cluster.x-k8s.io/v1alpha1",
+		ClusterTemplateKind: "KubevirtClusterTemplate",
+		MachineTemplateKind: "KubevirtMachineTemplate",
+	},
+	"vsphere": {
+		APIVersion:          "infrastructure.cluster.x-k8s.io/v1beta1",
+		ClusterTemplateKind: "VSphereClusterTemplate",
+		MachineTemplateKind: "VSphereMachineTemplate",
+	},
+}
+
+var defaultKubernetesVersions = map[string]string{
+	"k0s": "v1.30.0+k0s.0",
+	"k3s": "v1.30.0+k3s.0",
+}
+
+// clusterClassOptions are the inputs of `kairosctl generate clusterclass`.
+type clusterClassOptions struct {
+	Name              string
+	Namespace         string
+	Infrastructure    string
+	Distribution      string
+	KubernetesVersion string
+	WorkerClass       string
+	UserName          string
+	Output            string
+}
+
+// clusterClassData is what the manifest template is rendered with.
+type clusterClassData struct {
+	clusterClassOptions
+	Provider infrastructureProvider
+	// MachineRoles get one infrastructure machine template each.
+	MachineRoles []string
+}
+
+func newGenerateClusterClassCmd() *cobra.Command {
+	opts := clusterClassOptions{}
+	cmd := &cobra.Command{
+		Use:   "clusterclass",
+		Short: "Generate a ClusterClass with Kairos control plane and bootstrap templates",
+		Long: "Generate a ClusterClass together with the KairosControlPlaneTemplate, KairosConfigTemplates " +
+			"and infrastructure template skeletons it references. Review the infrastructure templates " +
+			"before applying: they only contain placeholders for provider specific settings.",
+		Example: "  kairosctl generate clusterclass --name kairos-docker --infrastructure docker\n" +
+			"  kairosctl generate clusterclass --name kairos-kv --infrastructure kubevirt --distribution k3s -o clusterclass.yaml",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manifest, err := renderClusterClass(opts)
+			if err != nil {
+				return err
+			}
+			if opts.Output == "" || opts.Output == "-" {
+				_, err = io.Copy(cmd.OutOrStdout(), bytes.NewReader(manifest))
+				return err
+			}
+			if err := os.WriteFile(opts.Output, manifest, 0o644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", opts.Output, err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Name, "name", "kairos", "Name of the ClusterClass; also used as prefix for the templates")
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "default", "Namespace of the generated resources")
+	cmd.Flags().StringVar(&opts.Infrastructure, "infrastructure", "docker",
+		fmt.Sprintf("Infrastructure provider (%s)", strings.Join(providerNames(), ", ")))
+	cmd.Flags().StringVar(&opts.Distribution, "distribution", "k0s", "Kubernetes distribution (k0s, k3s)")
+	cmd.Flags().StringVar(&opts.KubernetesVersion, "kubernetes-version", "",
+		"Kubernetes version of the example Cluster topology (defaults to a version matching --distribution)")
+	cmd.Flags().StringVar(&opts.WorkerClass, "worker-class", "default-worker", "Name of the MachineDeployment class for workers")
+	cmd.Flags().StringVar(&opts.UserName, "user-name", "kairos", "Kairos user created on every node")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "", "Write the manifest to this file instead of stdout")
+	return cmd
+}
+
+// renderClusterClass validates opts, fills in defaults and renders the manifest.
+func renderClusterClass(opts clusterClassOptions) ([]byte, error) {
+	provider, ok := infrastructureProviders[opts.Infrastructure]
+	if !ok {
+		return nil, fmt.Errorf("unsupported infrastructure %q (supported: %s)", opts.Infrastructure, strings.Join(providerNames(), ", "))
+	}
+	defaultVersion, ok := defaultKubernetesVersions[opts.Distribution]
+	if !ok {
+		return nil, fmt.Errorf("unsupported distribution %q (supported: k0s, k3s)", opts.Distribution)
+	}
+	if opts.KubernetesVersion == "" {
+		opts.KubernetesVersion = defaultVersion
+	}
+	if !strings.Contains(opts.KubernetesVersion, "+"+opts.Distribution) {
+		return nil, fmt.Errorf("kubernetes version %q does not match distribution %s (expected a +%s suffix)",
+			opts.KubernetesVersion, opts.Distribution, opts.Distribution)
+	}
+	for flag, value := range map[string]string{"name": opts.Name, "namespace": opts.Namespace, "worker-class": opts.WorkerClass} {
+		if errs := validation.IsDNS1123Subdomain(value); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid --%s %q: %s", flag, value, strings.Join(errs, "; "))
+		}
+	}
+
+	tmpl, err := template.New("clusterclass").Parse(clusterClassTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse clusterclass template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, clusterClassData{
+		clusterClassOptions: opts,
+		Provider:            provider,
+		MachineRoles:        []string{"control-plane", "worker"},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to render clusterclass template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func providerNames() []string {
+	names := make([]string, 0, len(infrastructureProviders))
+	for name := range infrastructureProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+	controlplanev1beta2 "github.com/kairos-io/kairos-capi/api/controlplane/v1beta2"
+)
+
+func decodeManifest(t *testing.T, manifest []byte) []*unstructured.Unstructured {
+	t.Helper()
+	var objs []*unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifest), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objs
+			}
+			t.Fatalf("failed to decode manifest: %v", err)
+		}
+		objs = append(objs, obj)
+	}
+}
+
+func TestRenderClusterClass(t *testing.T) {
+	for _, infrastructure := range providerNames() {
+		for _, distribution := range []string{"k0s", "k3s"} {
+			t.Run(infrastructure+"-"+distribution, func(t *testing.T) {
+				g := NewWithT(t)
+
+				manifest, err := renderClusterClass(clusterClassOptions{
+					Name:           "kairos",
+					Namespace:      "clusters",
+					Infrastructure: infrastructure,
+					Distribution:   distribution,
+					WorkerClass:    "default-worker",
+					UserName:       "kairos",
+				})
+				g.Expect(err).NotTo(HaveOccurred())
+
+				provider := infrastructureProviders[infrastructure]
+				objs := decodeManifest(t, manifest)
+				var kinds []string
+				for _, obj := range objs {
+					kinds = append(kinds, obj.GetKind())
+					g.Expect(obj.GetNamespace()).To(Equal("clusters"))
+				}
+				g.Expect(kinds).To(Equal([]string{
+					"ClusterClass",
+					"KairosControlPlaneTemplate",
+					"KairosConfigTemplate",
+					provider.ClusterTemplateKind,
+					provider.MachineTemplateKind,
+					provider.MachineTemplateKind,
+				}))
+
+				kcpTemplate := &controlplanev1beta2.KairosControlPlaneTemplate{}
+				g.Expect(fromUnstructured(objs[1], kcpTemplate)).To(Succeed())
+				g.Expect(kcpTemplate.Spec.Template.Spec.Distribution).To(Equal(distribution))
+				g.Expect(kcpTemplate.Spec.Template.Spec.KairosConfigSpec).NotTo(BeNil())
+				g.Expect(kcpTemplate.Spec.Template.Spec.KairosConfigSpec.UserName).To(Equal("kairos"))
+				_, err = kcpTemplate.ValidateCreate()
+				g.Expect(err).NotTo(HaveOccurred())
+
+				worker := &bootstrapv1beta2.KairosConfigTemplate{}
+				g.Expect(fromUnstructured(objs[2], worker)).To(Succeed())
+				g.Expect(worker.Spec.Template.Spec.Role).To(Equal("worker"))
+				if distribution == "k3s" {
+					g.Expect(worker.Spec.Template.Spec.K3sTokenSecretRef).NotTo(BeNil())
+				} else {
+					g.Expect(worker.Spec.Template.Spec.WorkerTokenSecretRef).NotTo(BeNil())
+				}
+
+				machineDeployments, _, _ := unstructured.NestedSlice(objs[0].Object, "spec", "workers", "machineDeployments")
+				g.Expect(machineDeployments).To(HaveLen(1))
+				g.Expect(machineDeployments[0]).To(HaveKeyWithValue("class", "default-worker"))
+			})
+		}
+	}
+}
+
+func TestRenderClusterClass_InvalidOptions(t *testing.T) {
+	valid := clusterClassOptions{
+		Name:           "kairos",
+		Namespace:      "default",
+		Infrastructure: "docker",
+		Distribution:   "k0s",
+		WorkerClass:    "default-worker",
+	}
+
+	tests := map[string]func(o *clusterClassOptions){
+		"unknown infrastructure": func(o *clusterClassOptions) { o.Infrastructure = "aws" },
+		"unknown distribution":   func(o *clusterClassOptions) { o.Distribution = "rke2" },
+		"version mismatch":       func(o *clusterClassOptions) { o.KubernetesVersion = "v1.30.0+k3s.0" },
+		"invalid name":           func(o *clusterClassOptions) { o.Name = "Kairos_Class" },
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			opts := valid
+			mutate(&opts)
+			_, err := renderClusterClass(opts)
+			g.Expect(err).To(HaveOccurred())
+		})
+	}
+}
+
+func fromUnstructured(obj *unstructured.Unstructured, into interface{}) error {
+	data, err := sigsyaml.Marshal(obj.Object)
+	if err != nil {
+		return err
+	}
+	return sigsyaml.UnmarshalStrict(data, into)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Command kairosctl is a helper CLI for users of the Kairos Cluster API provider.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kairos-io/kairos-capi/internal/version"
+)
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:           "kairosctl",
+		Short:         "Kairos Cluster API provider CLI",
+		Long:          "A CLI for generating and working with Kairos Cluster API provider resources",
+		Version:       version.Get().String(),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	rootCmd.AddCommand(newGenerateCmd())
+
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func newGenerateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate Kairos Cluster API manifests",
+	}
+	cmd.AddCommand(newGenerateClusterClassCmd())
+	return cmd
+}
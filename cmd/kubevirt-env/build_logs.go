@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// buildLogTailLines is how much of each container's log is printed when a
+// build fails.
+const buildLogTailLines = 200
+
+// buildLogGracePeriod is how long the log streams may keep printing once the
+// build is over.
+const buildLogGracePeriod = 10 * time.Second
+
+// buildLogStreamer streams the logs of the pods of the Jobs osbuilder runs
+// for an OSArtifact, each line prefixed with its pod and container.
+type buildLogStreamer struct {
+	clientset kubernetes.Interface
+	namespace string
+	artifact  string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	// mu keeps the lines of concurrent streams whole
+	mu       sync.Mutex
+	streamed map[string]bool
+	wg       sync.WaitGroup
+}
+
+func newBuildLogStreamer(ctx context.Context, clientset kubernetes.Interface, namespace, artifact string) *buildLogStreamer {
+	ctx, cancel := context.WithCancel(ctx)
+	return &buildLogStreamer{
+		clientset: clientset,
+		namespace: namespace,
+		artifact:  artifact,
+		ctx:       ctx,
+		cancel:    cancel,
+		streamed:  map[string]bool{},
+	}
+}
+
+// poll starts streaming the containers that started since the last poll.
+func (s *buildLogStreamer) poll(ctx context.Context) {
+	pods, err := s.builderPods(ctx)
+	if err != nil {
+		return
+	}
+	for _, pod := range pods {
+		for _, status := range containerStatuses(pod) {
+			if status.State.Running == nil && status.State.Terminated == nil {
+				continue
+			}
+			key := pod.Name + "/" + status.Name
+			if s.streamed[key] {
+				continue
+			}
+			s.streamed[key] = true
+			s.wg.Add(1)
+			go func(pod, container string) {
+				defer s.wg.Done()
+				s.stream(pod, container)
+			}(pod.Name, status.Name)
+		}
+	}
+}
+
+// stop gives the streams up to grace to print the rest of the logs of
+// finished containers, then stops them.
+func (s *buildLogStreamer) stop(grace time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(grace):
+	}
+	s.cancel()
+	<-done
+}
+
+func (s *buildLogStreamer) stream(pod, container string) {
+	logs, err := s.clientset.CoreV1().Pods(s.namespace).GetLogs(pod, &corev1.PodLogOptions{
+		Container: container,
+		Follow:    true,
+	}).Stream(s.ctx)
+	if err != nil {
+		return
+	}
+	defer logs.Close()
+	s.printLines(logs, fmt.Sprintf("[%s/%s] ", pod, container))
+}
+
+// dumpTail prints the last lines of every container of the builder pods.
+func (s *buildLogStreamer) dumpTail(ctx context.Context) {
+	pods, err := s.builderPods(ctx)
+	if err != nil {
+		fmt.Printf("Warning: failed to find the osbuilder pods: %v\n", err)
+		return
+	}
+	if len(pods) == 0 {
+		fmt.Println("Warning: no osbuilder pods found for the build")
+		return
+	}
+
+	tail := int64(buildLogTailLines)
+	for _, pod := range pods {
+		for _, status := range containerStatuses(pod) {
+			if status.State.Waiting != nil && status.LastTerminationState.Terminated == nil {
+				continue
+			}
+			data, err := s.clientset.CoreV1().Pods(s.namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+				Container: status.Name,
+				TailLines: &tail,
+			}).DoRaw(ctx)
+			if err != nil {
+				fmt.Printf("Warning: failed to get logs of %s/%s: %v\n", pod.Name, status.Name, err)
+				continue
+			}
+			fmt.Printf("--- last %d lines of %s/%s ---\n", buildLogTailLines, pod.Name, status.Name)
+			s.printLines(bytes.NewReader(data), "")
+		}
+	}
+}
+
+func (s *buildLogStreamer) printLines(r io.Reader, prefix string) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		s.mu.Lock()
+		fmt.Println(prefix + scanner.Text())
+		s.mu.Unlock()
+	}
+}
+
+// builderPods returns the pods of the Jobs owned by the OSArtifact.
+func (s *buildLogStreamer) builderPods(ctx context.Context) ([]corev1.Pod, error) {
+	jobs, err := s.clientset.BatchV1().Jobs(s.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var pods []corev1.Pod
+	for _, job := range jobs.Items {
+		if !ownedByOSArtifact(job, s.artifact) {
+			continue
+		}
+		list, err := s.clientset.CoreV1().Pods(s.namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: "job-name=" + job.Name,
+		})
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, list.Items...)
+	}
+	return pods, nil
+}
+
+func ownedByOSArtifact(job batchv1.Job, artifact string) bool {
+	for _, ref := range job.OwnerReferences {
+		if ref.Kind == "OSArtifact" && ref.Name == artifact {
+			return true
+		}
+	}
+	return false
+}
+
+// containerStatuses returns the statuses of the init containers, which do
+// the build, followed by those of the containers, in the order they run.
+func containerStatuses(pod corev1.Pod) []corev1.ContainerStatus {
+	return append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+}
@@ -14,7 +14,6 @@ import (
 )
 
 const (
-	calicoVersion     = "v3.29.1"
 	calicoManifestURL = "https://raw.githubusercontent.com/projectcalico/calico/%s/manifests/calico.yaml"
 )
 
@@ -65,7 +64,7 @@ func isCalicoInstalled() bool {
 		return false
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(interruptCtx, 5*time.Second)
 	defer cancel()
 
 	// Check if calico-node daemonset exists and is ready
@@ -115,6 +114,7 @@ func installCalico() error {
 		return fmt.Errorf("failed to create dynamic client: %w", err)
 	}
 
+	calicoVersion := getCalicoVersion()
 	fmt.Printf("Installing Calico CNI %s...\n", calicoVersion)
 	calicoURL := fmt.Sprintf(calicoManifestURL, calicoVersion)
 
@@ -124,7 +124,7 @@ func installCalico() error {
 	}
 
 	fmt.Println("Waiting for Calico to be ready...")
-	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	ctx, cancel := waitContext("calico")
 	defer cancel()
 
 	// Wait for calico-kube-controllers deployment using client-go
@@ -166,7 +166,7 @@ func uninstallCalico() error {
 	}
 
 	fmt.Println("Uninstalling Calico CNI...")
-	calicoURL := fmt.Sprintf(calicoManifestURL, calicoVersion)
+	calicoURL := fmt.Sprintf(calicoManifestURL, getCalicoVersion())
 
 	// Delete Calico manifest
 	if err := deleteResourcesFromManifestURL(dynamicClient, config, calicoURL); err != nil {
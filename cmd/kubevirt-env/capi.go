@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"time"
 
@@ -15,10 +14,6 @@ import (
 	"github.com/spf13/cobra"
 )
 
-const (
-	capiVersion = "v1.8.0"
-)
-
 func newInstallCapiCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "capi",
@@ -67,10 +62,7 @@ func newReinstallCapiCmd() *cobra.Command {
 }
 
 func validateClusterctlInstalled() error {
-	if _, err := exec.LookPath("clusterctl"); err != nil {
-		return fmt.Errorf("required command 'clusterctl' not found in PATH. Please install it first")
-	}
-	return nil
+	return ensureTool("clusterctl")
 }
 
 func isCapiInstalled() bool {
@@ -79,7 +71,7 @@ func isCapiInstalled() bool {
 		return false
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(interruptCtx, 5*time.Second)
 	defer cancel()
 
 	// Check if CAPI core controller exists and is available
@@ -105,6 +97,7 @@ func installCapi() error {
 		return nil
 	}
 
+	capiVersion := getCapiVersion()
 	fmt.Printf("Installing Cluster API %s...\n", capiVersion)
 
 	// Get bin directory
@@ -119,7 +112,11 @@ func installCapi() error {
 	}
 
 	// Run clusterctl init without infrastructure provider (just CAPI core)
-	clusterctlCmd := exec.Command("clusterctl", "init")
+	args := []string{"init"}
+	if capiVersion != latestVersion {
+		args = append(args, "--core", "cluster-api:"+capiVersion)
+	}
+	clusterctlCmd := command(toolPath("clusterctl"), args...)
 	clusterctlCmd.Env = append(os.Environ(), "PATH="+path)
 	clusterctlCmd.Stdout = os.Stdout
 	clusterctlCmd.Stderr = os.Stderr
@@ -129,7 +126,7 @@ func installCapi() error {
 	}
 
 	fmt.Println("Waiting for CAPI components to be ready...")
-	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	ctx, cancel := waitContext("capi")
 	defer cancel()
 
 	clientset, err := getKubeClient()
@@ -168,7 +165,7 @@ func uninstallCapi() error {
 	}
 
 	// Run clusterctl delete --all
-	clusterctlCmd := exec.Command("clusterctl", "delete", "--all")
+	clusterctlCmd := command(toolPath("clusterctl"), "delete", "--all")
 	clusterctlCmd.Env = append(os.Environ(), "PATH="+path)
 	clusterctlCmd.Stdout = os.Stdout
 	clusterctlCmd.Stderr = os.Stderr
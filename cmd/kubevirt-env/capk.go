@@ -4,9 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -16,10 +14,6 @@ import (
 	"github.com/spf13/cobra"
 )
 
-const (
-	defaultCAPKVersionEnv = "CAPK_VERSION"
-)
-
 func newInstallCapkCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "capk",
@@ -80,7 +74,7 @@ func isCapkInstalled() bool {
 		return false
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(interruptCtx, 5*time.Second)
 	defer cancel()
 
 	// Check if CAPK infrastructure controller exists and is available
@@ -106,12 +100,8 @@ func installCapk() error {
 		return nil
 	}
 
-	capkVersion := strings.TrimSpace(os.Getenv(defaultCAPKVersionEnv))
-	if capkVersion != "" {
-		fmt.Printf("Installing CAPK %s...\n", capkVersion)
-	} else {
-		fmt.Println("Installing CAPK (latest)...")
-	}
+	capkVersion := getCapkVersion()
+	fmt.Printf("Installing CAPK %s...\n", capkVersion)
 
 	// Get bin directory
 	binDir := filepath.Join(".", "bin")
@@ -125,15 +115,11 @@ func installCapk() error {
 	}
 
 	// Run clusterctl init with KubeVirt infrastructure provider
-	var infraArg string
-	if capkVersion != "" {
-		fmt.Printf("Using CAPK version: %s\n", capkVersion)
+	infraArg := "kubevirt"
+	if capkVersion != latestVersion {
 		infraArg = fmt.Sprintf("kubevirt:%s", capkVersion)
-	} else {
-		fmt.Println("Using CAPK version: latest")
-		infraArg = "kubevirt"
 	}
-	clusterctlCmd := exec.Command("clusterctl", "init", "--infrastructure", infraArg)
+	clusterctlCmd := command(toolPath("clusterctl"), "init", "--infrastructure", infraArg)
 	clusterctlCmd.Env = append(os.Environ(), "PATH="+path)
 	clusterctlCmd.Stdout = os.Stdout
 	clusterctlCmd.Stderr = os.Stderr
@@ -143,7 +129,7 @@ func installCapk() error {
 	}
 
 	fmt.Println("Waiting for CAPK infrastructure controller...")
-	waitCtx, waitCancel := context.WithTimeout(context.Background(), 300*time.Second)
+	waitCtx, waitCancel := waitContext("capk")
 	defer waitCancel()
 
 	clientset, err := getKubeClient()
@@ -17,10 +17,17 @@ import (
 )
 
 const (
-	cdiOperatorURL = "https://github.com/kubevirt/containerized-data-importer/releases/latest/download/cdi-operator.yaml"
-	cdiCRURL       = "https://github.com/kubevirt/containerized-data-importer/releases/latest/download/cdi-cr.yaml"
+	cdiRepo = "kubevirt/containerized-data-importer"
 )
 
+func cdiOperatorURL() string {
+	return githubReleaseAssetURL(cdiRepo, getCdiVersion(), "cdi-operator.yaml")
+}
+
+func cdiCRURL() string {
+	return githubReleaseAssetURL(cdiRepo, getCdiVersion(), "cdi-cr.yaml")
+}
+
 func newInstallCdiCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "cdi",
@@ -84,7 +91,7 @@ func isCdiInstalled() bool {
 		return false
 	}
 
-	ctx := context.Background()
+	ctx := interruptCtx
 	deployment, err := clientset.AppsV1().Deployments("cdi").Get(ctx, "cdi-operator", metav1.GetOptions{})
 	if err != nil {
 		return false
@@ -107,7 +114,7 @@ func installCdi() error {
 	}
 
 	// Check if CDI namespace exists and is terminating
-	checkCtx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	checkCtx, cancel := waitContext("namespace-delete")
 	defer cancel()
 
 	ns, err := clientset.CoreV1().Namespaces().Get(checkCtx, "cdi", metav1.GetOptions{})
@@ -122,7 +129,7 @@ func installCdi() error {
 		}
 	}
 
-	fmt.Println("Installing CDI (Containerized Data Importer)...")
+	fmt.Printf("Installing CDI (Containerized Data Importer) %s...\n", getCdiVersion())
 
 	config, err := getKubeConfig()
 	if err != nil {
@@ -135,18 +142,18 @@ func installCdi() error {
 	}
 
 	// Apply operator manifest
-	if err := applyManifestFromURL(dynamicClient, config, cdiOperatorURL); err != nil {
+	if err := applyManifestFromURL(dynamicClient, config, cdiOperatorURL()); err != nil {
 		return fmt.Errorf("failed to apply CDI operator manifest: %w", err)
 	}
 
 	// Apply CR manifest
-	if err := applyManifestFromURL(dynamicClient, config, cdiCRURL); err != nil {
+	if err := applyManifestFromURL(dynamicClient, config, cdiCRURL()); err != nil {
 		return fmt.Errorf("failed to apply CDI CR manifest: %w", err)
 	}
 
 	// Wait for CDI operator deployment
 	fmt.Println("Waiting for CDI to be ready...")
-	waitCtx, waitCancel := context.WithTimeout(context.Background(), 300*time.Second)
+	waitCtx, waitCancel := waitContext("cdi")
 	defer waitCancel()
 
 	if err := waitForDeployment(waitCtx, clientset, "cdi", "cdi-operator"); err != nil {
@@ -173,7 +180,7 @@ func waitForCdiCRReady(ctx context.Context, dynamicClient dynamic.Interface) err
 	fmt.Println("Checking CDI status...")
 
 	// First try to wait for Available condition with a short timeout (like Makefile does with 10s)
-	conditionCtx, conditionCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	conditionCtx, conditionCancel := context.WithTimeout(interruptCtx, 10*time.Second)
 	defer conditionCancel()
 
 	conditionMet := false
@@ -261,11 +268,11 @@ func uninstallCdi() error {
 	}
 
 	// Delete CR first, then operator
-	if err := deleteResourcesFromManifestURL(dynamicClient, config, cdiCRURL); err != nil {
+	if err := deleteResourcesFromManifestURL(dynamicClient, config, cdiCRURL()); err != nil {
 		return fmt.Errorf("failed to delete CDI CR: %w", err)
 	}
 
-	if err := deleteResourcesFromManifestURL(dynamicClient, config, cdiOperatorURL); err != nil {
+	if err := deleteResourcesFromManifestURL(dynamicClient, config, cdiOperatorURL()); err != nil {
 		return fmt.Errorf("failed to delete CDI operator: %w", err)
 	}
 
@@ -275,7 +282,7 @@ func uninstallCdi() error {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	ctx, cancel := waitContext("cdi")
 	defer cancel()
 
 	if err := waitForNamespaceDeleted(ctx, clientset, "cdi"); err != nil {
@@ -13,10 +13,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
-const (
-	certManagerVersion = "v1.16.2"
-	certManagerURL     = "https://github.com/cert-manager/cert-manager/releases/download/%s/cert-manager.yaml"
-)
+func certManagerURL(version string) string {
+	return githubReleaseAssetURL("cert-manager/cert-manager", version, "cert-manager.yaml")
+}
 
 func newInstallCertManagerCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -50,7 +49,7 @@ func isCertManagerInstalled() bool {
 		return false
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(interruptCtx, 5*time.Second)
 	defer cancel()
 
 	// Check if cert-manager deployment exists and is available
@@ -117,8 +116,9 @@ func installCertManager() error {
 		return fmt.Errorf("failed to create dynamic client: %w", err)
 	}
 
+	certManagerVersion := getCertManagerVersion()
 	fmt.Printf("Installing cert-manager %s...\n", certManagerVersion)
-	certManagerManifestURL := fmt.Sprintf(certManagerURL, certManagerVersion)
+	certManagerManifestURL := certManagerURL(certManagerVersion)
 
 	// Download and apply manifest using client-go
 	if err := applyManifestFromURL(dynamicClient, config, certManagerManifestURL); err != nil {
@@ -127,7 +127,7 @@ func installCertManager() error {
 	}
 
 	fmt.Println("Waiting for cert-manager to be ready...")
-	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	ctx, cancel := waitContext("cert-manager")
 	defer cancel()
 
 	// Wait for cert-manager deployment
@@ -170,7 +170,7 @@ func uninstallCertManager() error {
 	}
 
 	fmt.Println("Uninstalling cert-manager...")
-	certManagerManifestURL := fmt.Sprintf(certManagerURL, certManagerVersion)
+	certManagerManifestURL := certManagerURL(getCertManagerVersion())
 
 	// Delete cert-manager manifest
 	if err := deleteResourcesFromManifestURL(dynamicClient, config, certManagerManifestURL); err != nil {
@@ -184,7 +184,7 @@ func uninstallCertManager() error {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	ctx, cancel := waitContext("namespace-delete")
 	defer cancel()
 
 	if err := waitForNamespaceDeleted(ctx, clientset, "cert-manager"); err != nil {
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/dynamic"
+)
+
+// defaultKubernetesVersions are the versions the generated clusters run when
+// none is given.
+var defaultKubernetesVersions = map[string]string{
+	"k0s": "v1.34.1+k0s.1",
+	"k3s": "v1.34.1+k3s1",
+}
+
+// clusterManifestOptions describe a workload cluster manifest.
+type clusterManifestOptions struct {
+	Name              string
+	Namespace         string
+	Distribution      string
+	KubernetesVersion string
+	Replicas          int
+	SingleNode        bool
+	Workers           int
+	StorageClass      string
+	// DataVolume is the PVC holding the uploaded Kairos image, cloned into
+	// the root disk of each VM
+	DataVolume string
+	DiskSize   string
+	CPU        int
+	Memory     string
+}
+
+func defaultClusterManifestOptions() clusterManifestOptions {
+	return clusterManifestOptions{
+		Name:         clusterName,
+		Namespace:    clusterNamespace,
+		Distribution: "k0s",
+		Replicas:     1,
+		DataVolume:   kairosImageName,
+		DiskSize:     "30Gi",
+		CPU:          2,
+		Memory:       "4Gi",
+	}
+}
+
+func (o clusterManifestOptions) validate() error {
+	if o.Name == "" {
+		return fmt.Errorf("the cluster name is required")
+	}
+	if _, ok := defaultKubernetesVersions[o.Distribution]; !ok {
+		return fmt.Errorf("invalid distribution %q: must be k0s or k3s", o.Distribution)
+	}
+	if o.Replicas < 1 {
+		return fmt.Errorf("invalid replicas %d: must be at least 1", o.Replicas)
+	}
+	if o.SingleNode && o.Replicas != 1 {
+		return fmt.Errorf("a single-node cluster has 1 control-plane replica, got %d", o.Replicas)
+	}
+	if o.Workers < 0 {
+		return fmt.Errorf("invalid workers %d: must not be negative", o.Workers)
+	}
+	if o.CPU < 1 {
+		return fmt.Errorf("invalid CPU cores %d: must be at least 1", o.CPU)
+	}
+	for flag, quantity := range map[string]string{"memory": o.Memory, "disk size": o.DiskSize} {
+		if _, err := resource.ParseQuantity(quantity); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", flag, quantity, err)
+		}
+	}
+	return nil
+}
+
+// kubernetesVersion returns the version to run, the default one of the
+// distribution when none is set.
+func (o clusterManifestOptions) kubernetesVersion() string {
+	if o.KubernetesVersion != "" {
+		return o.KubernetesVersion
+	}
+	return defaultKubernetesVersions[o.Distribution]
+}
+
+// clusterManifestTemplate renders the control plane of a cluster ("cluster")
+// and its workers ("workers"), which share the VM template ("vm").
+var clusterManifestTemplate = template.Must(template.New("manifest").Parse(`
+{{- define "vm" }}
+      virtualMachineTemplate:
+        spec:
+          dataVolumeTemplates:
+          - apiVersion: cdi.kubevirt.io/v1beta1
+            kind: DataVolume
+            metadata:
+              name: kairos-rootdisk
+            spec:
+              pvc:
+                accessModes:
+                - ReadWriteOnce
+                resources:
+                  requests:
+                    storage: {{ .DiskSize }}
+{{- if .StorageClass }}
+                storageClassName: {{ .StorageClass }}
+{{- end }}
+              source:
+                pvc:
+                  name: {{ .DataVolume }}
+                  namespace: {{ .Namespace }}
+          running: true
+          template:
+            spec:
+              domain:
+                cpu:
+                  cores: {{ .CPU }}
+                memory:
+                  guest: {{ .Memory }}
+                devices:
+                  disks:
+                  - name: rootdisk
+                    bootOrder: 1
+                    disk:
+                      bus: virtio
+                  interfaces:
+                  - name: default
+                    masquerade: {}
+                features:
+                  acpi:
+                    enabled: true
+                # Use UEFI boot (image has GPT partition table with EFI System partition)
+                # Disable secure boot to avoid boot issues
+                firmware:
+                  bootloader:
+                    efi:
+                      secureBoot: false
+              networks:
+              - name: default
+                pod: {}
+              volumes:
+              - name: rootdisk
+                dataVolume:
+                  name: kairos-rootdisk
+{{- end }}
+
+{{- define "cluster" -}}
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: Cluster
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+spec:
+  infrastructureRef:
+    apiGroup: infrastructure.cluster.x-k8s.io
+    kind: KubevirtCluster
+    name: {{ .Name }}
+  controlPlaneRef:
+    apiGroup: controlplane.cluster.x-k8s.io
+    kind: KairosControlPlane
+    name: {{ .Name }}-control-plane
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1alpha1
+kind: KubevirtCluster
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+spec: {}
+---
+apiVersion: controlplane.cluster.x-k8s.io/v1beta2
+kind: KairosControlPlane
+metadata:
+  name: {{ .Name }}-control-plane
+  namespace: {{ .Namespace }}
+spec:
+  replicas: {{ .Replicas }}
+  version: "{{ .Version }}"
+  distribution: {{ .Distribution }}
+  machineTemplate:
+    infrastructureRef:
+      apiVersion: infrastructure.cluster.x-k8s.io/v1alpha1
+      kind: KubevirtMachineTemplate
+      name: {{ .Name }}-control-plane-template
+      namespace: {{ .Namespace }}
+  kairosConfigTemplate:
+    name: {{ .Name }}-config-template-control-plane
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1alpha1
+kind: KubevirtMachineTemplate
+metadata:
+  name: {{ .Name }}-control-plane-template
+  namespace: {{ .Namespace }}
+spec:
+  template:
+    spec:{{ template "vm" . }}
+---
+apiVersion: bootstrap.cluster.x-k8s.io/v1beta2
+kind: KairosConfigTemplate
+metadata:
+  name: {{ .Name }}-config-template-control-plane
+  namespace: {{ .Namespace }}
+spec:
+  template:
+    spec:
+      role: control-plane
+      distribution: {{ .Distribution }}
+      kubernetesVersion: "{{ .Version }}"
+{{- if .SingleNode }}
+      singleNode: true
+{{- end }}
+      userName: kairos
+      userPassword: kairos
+      userGroups:
+        - admin
+      # Optional: Add GitHub user for SSH access
+      # githubUser: "your-github-username"
+      # Optional: Add SSH public key instead
+      # sshPublicKey: "ssh-rsa AAAAB3NzaC1yc2E..."
+{{- end }}
+
+{{- define "workers" -}}
+# The worker join token is provisioned by the bootstrap provider once the
+# control plane is up.
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: MachineDeployment
+metadata:
+  name: {{ .Name }}-workers
+  namespace: {{ .Namespace }}
+spec:
+  clusterName: {{ .Name }}
+  replicas: {{ .Workers }}
+  selector:
+    matchLabels:
+      cluster.x-k8s.io/cluster-name: {{ .Name }}
+      cluster.x-k8s.io/deployment-name: {{ .Name }}-workers
+  template:
+    metadata:
+      labels:
+        cluster.x-k8s.io/cluster-name: {{ .Name }}
+        cluster.x-k8s.io/deployment-name: {{ .Name }}-workers
+    spec:
+      clusterName: {{ .Name }}
+      version: "{{ .Version }}"
+      bootstrap:
+        configRef:
+          apiGroup: bootstrap.cluster.x-k8s.io
+          kind: KairosConfigTemplate
+          name: {{ .Name }}-config-template-worker
+      infrastructureRef:
+        apiGroup: infrastructure.cluster.x-k8s.io
+        kind: KubevirtMachineTemplate
+        name: {{ .Name }}-worker-template
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1alpha1
+kind: KubevirtMachineTemplate
+metadata:
+  name: {{ .Name }}-worker-template
+  namespace: {{ .Namespace }}
+spec:
+  template:
+    spec:{{ template "vm" . }}
+---
+apiVersion: bootstrap.cluster.x-k8s.io/v1beta2
+kind: KairosConfigTemplate
+metadata:
+  name: {{ .Name }}-config-template-worker
+  namespace: {{ .Namespace }}
+spec:
+  template:
+    spec:
+      role: worker
+      distribution: {{ .Distribution }}
+      kubernetesVersion: "{{ .Version }}"
+      userName: kairos
+      userPassword: kairos
+      userGroups:
+        - admin
+{{- end }}
+`))
+
+// renderClusterManifest renders the control plane of the cluster when
+// controlPlane is set, and its workers when it has any.
+func renderClusterManifest(opts clusterManifestOptions, controlPlane bool) ([]byte, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	data := struct {
+		clusterManifestOptions
+		Version string
+	}{opts, opts.kubernetesVersion()}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Kairos %s cluster %s on KubeVirt: %d control-plane replica(s), %d worker(s)\n", opts.Distribution, opts.Name, opts.Replicas, opts.Workers)
+	fmt.Fprintf(&buf, "# Generated by kubevirt-env generate-cluster; upload the Kairos image to the %s PVC first (kubevirt-env upload-kairos-image)\n", opts.DataVolume)
+	var parts []string
+	if controlPlane {
+		parts = append(parts, "cluster")
+	}
+	if opts.Workers > 0 {
+		parts = append(parts, "workers")
+	}
+	for i, part := range parts {
+		if i > 0 {
+			buf.WriteString("\n---\n")
+		}
+		if err := clusterManifestTemplate.ExecuteTemplate(&buf, part, data); err != nil {
+			return nil, fmt.Errorf("failed to render cluster manifest: %w", err)
+		}
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+func newGenerateClusterCmd() *cobra.Command {
+	opts := defaultClusterManifestOptions()
+	var output string
+	var apply bool
+
+	cmd := &cobra.Command{
+		Use:   "generate-cluster",
+		Short: "Generate a workload cluster manifest",
+		Long: `Generate the manifest of a Kairos workload cluster on KubeVirt: the Cluster, its KairosControlPlane and, with --workers, a MachineDeployment.
+
+The manifest is written to stdout or --output, or applied to the management cluster with --apply.`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manifest, err := renderClusterManifest(opts, true)
+			if err != nil {
+				return err
+			}
+
+			if output != "" {
+				if err := os.WriteFile(output, manifest, 0644); err != nil {
+					return fmt.Errorf("failed to write cluster manifest: %w", err)
+				}
+				fmt.Printf("Cluster manifest written to %s ✓\n", output)
+			} else if !apply {
+				_, err := os.Stdout.Write(manifest)
+				return err
+			}
+
+			if apply {
+				config, err := getKubeConfig()
+				if err != nil {
+					return err
+				}
+				dynamicClient, err := dynamic.NewForConfig(config)
+				if err != nil {
+					return fmt.Errorf("failed to create dynamic client: %w", err)
+				}
+				if err := applyManifestContent(dynamicClient, config, manifest); err != nil {
+					return fmt.Errorf("failed to apply cluster manifest: %w", err)
+				}
+				fmt.Printf("Cluster %s applied ✓\n", opts.Name)
+			}
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&opts.Name, "name", opts.Name, "Name of the cluster")
+	fs.StringVar(&opts.Namespace, "namespace", opts.Namespace, "Namespace of the cluster")
+	fs.StringVar(&opts.Distribution, "distribution", opts.Distribution, "Kubernetes distribution (k0s, k3s)")
+	fs.StringVar(&opts.KubernetesVersion, "kubernetes-version", "", fmt.Sprintf("Kubernetes version (default %s for k0s, %s for k3s)", defaultKubernetesVersions["k0s"], defaultKubernetesVersions["k3s"]))
+	fs.IntVar(&opts.Replicas, "replicas", opts.Replicas, "Number of control-plane machines")
+	fs.BoolVar(&opts.SingleNode, "single-node", false, "Mark the control plane as a single-node cluster (requires --replicas 1)")
+	fs.IntVar(&opts.Workers, "workers", 0, "Number of worker machines")
+	fs.StringVar(&opts.StorageClass, "storage-class", "", "StorageClass of the VM disks (default the cluster default)")
+	fs.StringVar(&opts.DataVolume, "data-volume", opts.DataVolume, "PVC holding the Kairos image the VM disks are cloned from")
+	fs.StringVar(&opts.DiskSize, "disk-size", opts.DiskSize, "Size of the VM disks")
+	fs.IntVar(&opts.CPU, "cpu", opts.CPU, "CPU cores of each VM")
+	fs.StringVar(&opts.Memory, "memory", opts.Memory, "Memory of each VM")
+	fs.StringVarP(&output, "output", "o", "", "Write the manifest to a file instead of stdout")
+	fs.BoolVar(&apply, "apply", false, "Apply the manifest to the management cluster")
+	return cmd
+}
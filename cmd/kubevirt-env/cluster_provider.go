@@ -0,0 +1,324 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const defaultClusterProvider = "kind"
+
+// clusterProvider creates the local cluster the environment runs in.
+type clusterProvider interface {
+	// tool is the command that manages the clusters
+	tool() string
+	// validate checks that the provider supports topology
+	validate(topology kindTopology) error
+	exists(clusterName string) bool
+	// create creates the cluster and writes its kubeconfig to kubeconfigPath.
+	// The nodes use the Docker config at dockerConfigPath to pull images when
+	// the provider supports it.
+	create(clusterName string, topology kindTopology, dockerConfigPath, kubeconfigPath string) error
+	delete(clusterName string) error
+	// loadImage loads a local Docker image into the nodes
+	loadImage(clusterName, image string) error
+	kubectlContext(clusterName string) string
+}
+
+var clusterProviders = map[string]clusterProvider{
+	"kind":     kindProvider{},
+	"k3d":      k3dProvider{},
+	"minikube": minikubeProvider{},
+}
+
+func clusterProviderNames() []string {
+	return []string{"kind", "k3d", "minikube"}
+}
+
+func addClusterProviderFlag(fs *pflag.FlagSet) {
+	fs.String("provider", defaultClusterProvider, "Local cluster provider: "+strings.Join(clusterProviderNames(), ", ")+" (can also be set via KUBEVIRT_ENV_PROVIDER env var)")
+	viper.BindPFlag("cluster-provider", fs.Lookup("provider"))
+	viper.BindEnv("cluster-provider", "KUBEVIRT_ENV_PROVIDER")
+}
+
+func validateClusterProvider() error {
+	if _, ok := clusterProviders[getClusterProviderName()]; !ok {
+		return fmt.Errorf("invalid --provider %q: must be one of %s", getClusterProviderName(), strings.Join(clusterProviderNames(), ", "))
+	}
+	return nil
+}
+
+func getClusterProviderName() string {
+	return viper.GetString("cluster-provider")
+}
+
+func getClusterProvider() clusterProvider {
+	if provider, ok := clusterProviders[getClusterProviderName()]; ok {
+		return provider
+	}
+	return clusterProviders[defaultClusterProvider]
+}
+
+// runTool runs a command of a provider, printing its output.
+func runTool(name string, args ...string) error {
+	cmd := command(toolPath(name), args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// kindProvider creates the cluster with kind, which runs the nodes as Docker
+// containers.
+type kindProvider struct{}
+
+func (kindProvider) tool() string {
+	return "kind"
+}
+
+func (kindProvider) validate(topology kindTopology) error {
+	return nil
+}
+
+func (kindProvider) exists(clusterName string) bool {
+	output, err := command(toolPath("kind"), "get", "clusters").Output()
+	if err != nil {
+		return false
+	}
+
+	clusters := strings.Split(strings.TrimSpace(string(output)), "\n")
+	for _, line := range clusters {
+		if strings.TrimSpace(line) == clusterName {
+			return true
+		}
+	}
+	return false
+}
+
+func (kindProvider) create(clusterName string, topology kindTopology, dockerConfigPath, kubeconfigPath string) error {
+	kindConfigPath := filepath.Join(getWorkDir(), "kind-config.yaml")
+	kindConfig, err := topology.kindConfig(clusterName, dockerConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to generate kind config: %w", err)
+	}
+
+	if err := os.WriteFile(kindConfigPath, kindConfig, 0644); err != nil {
+		return fmt.Errorf("failed to create kind config: %w", err)
+	}
+
+	fmt.Printf("Kind config created with Docker config mount: %s\n", dockerConfigPath)
+	fmt.Printf("Nodes: %d control-plane, %d worker\n", topology.controlPlanes, topology.workers)
+
+	fmt.Printf("Creating kind cluster '%s'...\n", clusterName)
+	if err := runTool("kind", "create", "cluster", "--name", clusterName, "--config", kindConfigPath); err != nil {
+		return fmt.Errorf("failed to create kind cluster: %w", err)
+	}
+
+	fmt.Printf("Saving kubeconfig to %s...\n", kubeconfigPath)
+	output, err := command(toolPath("kind"), "get", "kubeconfig", "--name", clusterName).Output()
+	if err != nil {
+		return fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	if err := os.WriteFile(kubeconfigPath, output, 0600); err != nil {
+		return fmt.Errorf("failed to save kubeconfig: %w", err)
+	}
+	return nil
+}
+
+func (kindProvider) delete(clusterName string) error {
+	return runTool("kind", "delete", "cluster", "--name", clusterName)
+}
+
+func (kindProvider) loadImage(clusterName, image string) error {
+	return runTool("kind", "load", "docker-image", image, "--name", clusterName)
+}
+
+func (kindProvider) kubectlContext(clusterName string) string {
+	return "kind-" + clusterName
+}
+
+// k3dProvider creates the cluster with k3d, which runs k3s nodes as Docker
+// containers. Flannel, the network policy controller, the local-path
+// provisioner and Traefik of k3s are disabled, as Calico and local-path are
+// installed instead. The k3s service load balancer is kept, and serves the
+// control-plane LoadBalancer Services.
+type k3dProvider struct{}
+
+func (k3dProvider) tool() string {
+	return "k3d"
+}
+
+func (k3dProvider) validate(topology kindTopology) error {
+	return nil
+}
+
+func (k3dProvider) exists(clusterName string) bool {
+	return command(toolPath("k3d"), "cluster", "get", clusterName).Run() == nil
+}
+
+func (k3dProvider) create(clusterName string, topology kindTopology, dockerConfigPath, kubeconfigPath string) error {
+	extraMounts, err := topology.parseExtraMounts()
+	if err != nil {
+		return err
+	}
+
+	args := []string{"cluster", "create", clusterName,
+		"--servers", strconv.Itoa(topology.controlPlanes),
+		"--agents", strconv.Itoa(topology.workers),
+		"--kubeconfig-update-default=false",
+		"--wait",
+		"--volume", dockerConfigPath + ":/var/lib/kubelet/config.json@all",
+	}
+	k3sArgs := []string{"--flannel-backend=none", "--disable-network-policy", "--disable=local-storage", "--disable=traefik"}
+	if topology.podSubnet != "" {
+		k3sArgs = append(k3sArgs, "--cluster-cidr="+topology.podSubnet)
+	}
+	if topology.serviceSubnet != "" {
+		k3sArgs = append(k3sArgs, "--service-cidr="+topology.serviceSubnet)
+	}
+	for _, arg := range k3sArgs {
+		args = append(args, "--k3s-arg", arg+"@server:*")
+	}
+	for _, m := range extraMounts {
+		volume := m.mount.HostPath + ":" + m.mount.ContainerPath
+		if m.mount.ReadOnly {
+			volume += ":ro"
+		}
+		args = append(args, "--volume", volume+"@"+k3dNodeFilter(m.role, m.index))
+	}
+
+	fmt.Printf("Nodes: %d server, %d agent\n", topology.controlPlanes, topology.workers)
+	fmt.Printf("Creating k3d cluster '%s'...\n", clusterName)
+	if err := runTool("k3d", args...); err != nil {
+		return fmt.Errorf("failed to create k3d cluster: %w", err)
+	}
+
+	fmt.Printf("Saving kubeconfig to %s...\n", kubeconfigPath)
+	output, err := command(toolPath("k3d"), "kubeconfig", "get", clusterName).Output()
+	if err != nil {
+		return fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	if err := os.WriteFile(kubeconfigPath, output, 0600); err != nil {
+		return fmt.Errorf("failed to save kubeconfig: %w", err)
+	}
+	return nil
+}
+
+// k3dNodeFilter returns the k3d node filter of a node selector of
+// --extra-mount. k3d calls control-plane nodes servers and workers agents,
+// and numbers them from 0.
+func k3dNodeFilter(role string, index int) string {
+	switch {
+	case role == "":
+		return "all"
+	case index == 0:
+		return k3dRole(role) + ":*"
+	default:
+		return fmt.Sprintf("%s:%d", k3dRole(role), index-1)
+	}
+}
+
+func k3dRole(role string) string {
+	if role == "control-plane" {
+		return "server"
+	}
+	return "agent"
+}
+
+func (k3dProvider) delete(clusterName string) error {
+	return runTool("k3d", "cluster", "delete", clusterName)
+}
+
+func (k3dProvider) loadImage(clusterName, image string) error {
+	return runTool("k3d", "image", "import", image, "--cluster", clusterName)
+}
+
+func (k3dProvider) kubectlContext(clusterName string) string {
+	return "k3d-" + clusterName
+}
+
+// minikubeProvider creates the cluster with the Docker driver of minikube, in
+// a profile named after the cluster. The default-storageclass addon is
+// disabled, so local-path becomes the default StorageClass.
+type minikubeProvider struct{}
+
+func (minikubeProvider) tool() string {
+	return "minikube"
+}
+
+func (minikubeProvider) validate(topology kindTopology) error {
+	if topology.controlPlanes != 1 {
+		return fmt.Errorf("--control-planes must be 1 with minikube")
+	}
+	if len(topology.extraMounts) > 0 {
+		return fmt.Errorf("--extra-mount is not supported with minikube")
+	}
+	return nil
+}
+
+func (minikubeProvider) exists(clusterName string) bool {
+	// Fails when the profile does not exist or is stopped
+	output, _ := command(toolPath("minikube"), "status", "--profile", clusterName, "--format", "{{.Host}}").Output()
+	return strings.TrimSpace(string(output)) == "Running"
+}
+
+func (minikubeProvider) create(clusterName string, topology kindTopology, dockerConfigPath, kubeconfigPath string) error {
+	kubeconfigPath, err := filepath.Abs(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	args := []string{"start",
+		"--profile", clusterName,
+		"--driver", "docker",
+		"--nodes", strconv.Itoa(1 + topology.workers),
+		// Calico is installed instead
+		"--cni", "false",
+		// The system pods are not ready until Calico is installed
+		"--wait", "apiserver",
+	}
+	if topology.podSubnet != "" {
+		args = append(args, "--extra-config", "kubeadm.pod-network-cidr="+topology.podSubnet)
+	}
+	if topology.serviceSubnet != "" {
+		args = append(args, "--service-cluster-ip-range", topology.serviceSubnet)
+	}
+
+	fmt.Printf("Nodes: 1 control-plane, %d worker\n", topology.workers)
+	fmt.Printf("Creating minikube cluster '%s'...\n", clusterName)
+	// minikube writes the kubeconfig to $KUBECONFIG
+	if err := runMinikube(kubeconfigPath, args...); err != nil {
+		return fmt.Errorf("failed to create minikube cluster: %w", err)
+	}
+	if err := os.Chmod(kubeconfigPath, 0600); err != nil {
+		return fmt.Errorf("failed to save kubeconfig: %w", err)
+	}
+
+	if err := runMinikube(kubeconfigPath, "addons", "disable", "default-storageclass", "--profile", clusterName); err != nil {
+		return fmt.Errorf("failed to disable the default-storageclass addon: %w", err)
+	}
+	return nil
+}
+
+func runMinikube(kubeconfigPath string, args ...string) error {
+	cmd := command(toolPath("minikube"), args...)
+	cmd.Env = append(os.Environ(), "KUBECONFIG="+kubeconfigPath)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (minikubeProvider) delete(clusterName string) error {
+	return runTool("minikube", "delete", "--profile", clusterName)
+}
+
+func (minikubeProvider) loadImage(clusterName, image string) error {
+	return runTool("minikube", "image", "load", image, "--profile", clusterName)
+}
+
+func (minikubeProvider) kubectlContext(clusterName string) string {
+	return clusterName
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// defaultConfigFile is read from the working directory when --config is not
+// set.
+const defaultConfigFile = "kubevirt-env.yaml"
+
+// Defaults of the settings that have no flag.
+const (
+	defaultKairosArch        = "amd64"
+	defaultKairosImageFormat = "raw"
+	defaultKairosDiskSize    = "32000"
+	defaultKairosUploadSize  = "25Gi"
+)
+
+// defaultKairosBaseImage is the base image for an architecture.
+const defaultKairosBaseImage = "quay.io/kairos/fedora:40-core-%s-generic-v3.6.1-beta2"
+
+// defaultKairosCloudConfig adds console parameters to the kernel cmdline of
+// the Kairos image.
+const defaultKairosCloudConfig = `#cloud-config
+
+# Add console parameters to kernel cmdline for serial console access
+# console=ttyS0 enables serial console, console=tty0 enables VGA console
+install:
+  grub_options:
+    extra_cmdline: "console=ttyS0 console=tty0"
+`
+
+func addConfigFlag(fs *pflag.FlagSet) {
+	fs.String("config", "", fmt.Sprintf("Config file (default %s in the working directory if present, can also be set via KUBEVIRT_ENV_CONFIG env var)", defaultConfigFile))
+	viper.BindPFlag("config", fs.Lookup("config"))
+	viper.BindEnv("config", "KUBEVIRT_ENV_CONFIG")
+}
+
+func setConfigDefaults() {
+	viper.SetDefault("images.provider", kairosCapiImg)
+	viper.SetDefault("kairos-image.arch", defaultKairosArch)
+	viper.SetDefault("kairos-image.image-format", defaultKairosImageFormat)
+	viper.SetDefault("kairos-image.disk-size", defaultKairosDiskSize)
+	viper.SetDefault("kairos-image.upload-size", defaultKairosUploadSize)
+	viper.SetDefault("kairos-image.cloud-config", defaultKairosCloudConfig)
+}
+
+// readConfigFile reads the config file set with --config, or the default one
+// when it exists. Flags and environment variables take precedence over it.
+func readConfigFile() error {
+	path := viper.GetString("config")
+	if path == "" {
+		if _, err := os.Stat(defaultConfigFile); err != nil {
+			return nil
+		}
+		path = defaultConfigFile
+	}
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func getProviderImage() string {
+	return viper.GetString("images.provider")
+}
+
+// getKairosBaseImage returns the configured base image, or the default one
+// for the configured architecture.
+func getKairosBaseImage() string {
+	if image := viper.GetString("kairos-image.base-image"); image != "" {
+		return image
+	}
+	return fmt.Sprintf(defaultKairosBaseImage, getKairosArch())
+}
+
+func getKairosArch() string {
+	return viper.GetString("kairos-image.arch")
+}
+
+func getKairosImageFormat() string {
+	return viper.GetString("kairos-image.image-format")
+}
+
+func getKairosDiskSize() string {
+	return viper.GetString("kairos-image.disk-size")
+}
+
+func getKairosUploadSize() string {
+	return viper.GetString("kairos-image.upload-size")
+}
+
+// getKairosCloudConfig returns the cloud-config of the Kairos image, read
+// from kairos-image.cloud-config-file when it is set.
+func getKairosCloudConfig() (string, error) {
+	if path := viper.GetString("kairos-image.cloud-config-file"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read cloud-config file: %w", err)
+		}
+		return string(data), nil
+	}
+	return viper.GetString("kairos-image.cloud-config"), nil
+}
+
+// validateKairosImageConfig checks the settings of the Kairos image build.
+func validateKairosImageConfig() error {
+	switch arch := getKairosArch(); arch {
+	case "amd64", "arm64":
+	default:
+		return fmt.Errorf("invalid Kairos image arch %q: must be amd64 or arm64", arch)
+	}
+	switch format := getKairosImageFormat(); format {
+	case "raw", "qcow2":
+	default:
+		return fmt.Errorf("invalid Kairos image format %q: must be raw or qcow2", format)
+	}
+	if size := getKairosDiskSize(); size == "" || strings.Trim(size, "0123456789") != "" {
+		return fmt.Errorf("invalid Kairos image disk size %q: must be a size in MB such as 32000", size)
+	}
+	if path := viper.GetString("kairos-image.cloud-config-file"); path != "" {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("invalid cloud-config file: %w", err)
+		}
+	}
+	return nil
+}
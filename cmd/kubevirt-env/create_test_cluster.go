@@ -3,57 +3,49 @@ package main
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"os/user"
 	"path/filepath"
-	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 func newCreateTestClusterCmd() *cobra.Command {
+	var topology kindTopology
+
 	cmd := &cobra.Command{
 		Use:   "create-test-cluster",
-		Short: "Create a kind cluster for testing",
-		Long:  "Create a kind cluster with CNI disabled (for Calico installation)",
+		Short: "Create a local cluster for testing",
+		Long:  "Create a kind, k3d or minikube cluster (see --provider) with CNI disabled (for Calico installation), optionally with several control-plane and worker nodes",
 		Args:  cobra.NoArgs,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			return validateKindInstalled()
+			topology.applyConfig(cmd.Flags())
+			if err := topology.validate(); err != nil {
+				return err
+			}
+			if err := getClusterProvider().validate(topology); err != nil {
+				return err
+			}
+			return validateProviderInstalled()
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			clusterName := getClusterName()
-			return createTestCluster(clusterName)
+			return createTestCluster(clusterName, topology)
 		},
 	}
 
+	topology.addFlags(cmd.Flags())
 	return cmd
 }
 
-func validateKindInstalled() error {
-	if _, err := exec.LookPath("kind"); err != nil {
-		return fmt.Errorf("required command 'kind' not found in PATH. Please install it first")
-	}
-	return nil
+// validateProviderInstalled checks the tool of the cluster provider, and
+// installs kind when it is missing.
+func validateProviderInstalled() error {
+	return ensureTool(getClusterProvider().tool())
 }
 
 func isClusterReady(clusterName string) bool {
 	// Check if cluster exists
-	kindCmd := exec.Command("kind", "get", "clusters")
-	output, err := kindCmd.Output()
-	if err != nil {
-		return false
-	}
-
-	clusters := strings.Split(strings.TrimSpace(string(output)), "\n")
-	clusterExists := false
-	for _, line := range clusters {
-		if strings.TrimSpace(line) == clusterName {
-			clusterExists = true
-			break
-		}
-	}
-
-	if !clusterExists {
+	if !getClusterProvider().exists(clusterName) {
 		return false
 	}
 
@@ -66,7 +58,7 @@ func isClusterReady(clusterName string) bool {
 	return true
 }
 
-func createTestCluster(clusterName string) error {
+func createTestCluster(clusterName string, topology kindTopology) error {
 	// Check if cluster already exists and is ready
 	if isClusterReady(clusterName) {
 		fmt.Printf("Cluster '%s' already exists and is ready ✓\n", clusterName)
@@ -98,57 +90,21 @@ func createTestCluster(clusterName string) error {
 		}
 	}
 
-	// Create kind config file
-	kindConfigPath := filepath.Join(workDir, "kind-config.yaml")
-	kindConfig := fmt.Sprintf(`kind: Cluster
-apiVersion: kind.x-k8s.io/v1alpha4
-name: %s
-networking:
-  disableDefaultCNI: true
-nodes:
-- role: control-plane
-  extraMounts:
-  - containerPath: /var/lib/kubelet/config.json
-    hostPath: %s
-`, clusterName, dockerConfigPath)
-
-	if err := os.WriteFile(kindConfigPath, []byte(kindConfig), 0644); err != nil {
-		return fmt.Errorf("failed to create kind config: %w", err)
-	}
-
-	fmt.Printf("Kind config created with Docker config mount: %s\n", dockerConfigPath)
-
-	// Create cluster
-	fmt.Printf("Creating kind cluster '%s'...\n", clusterName)
-	kindCmd := exec.Command("kind", "create", "cluster", "--name", clusterName, "--config", kindConfigPath)
-	kindCmd.Stdout = os.Stdout
-	kindCmd.Stderr = os.Stderr
-	if err := kindCmd.Run(); err != nil {
-		return fmt.Errorf("failed to create kind cluster: %w", err)
-	}
-
-	// Save kubeconfig to work directory
-	kubeconfigPath := getKubeconfigPath()
-	fmt.Printf("Saving kubeconfig to %s...\n", kubeconfigPath)
-	kindCmd = exec.Command("kind", "get", "kubeconfig", "--name", clusterName)
-	output, err := kindCmd.Output()
-	if err != nil {
-		return fmt.Errorf("failed to get kubeconfig: %w", err)
-	}
-	if err := os.WriteFile(kubeconfigPath, output, 0600); err != nil {
-		return fmt.Errorf("failed to save kubeconfig: %w", err)
+	provider := getClusterProvider()
+	if err := provider.create(clusterName, topology, dockerConfigPath, getKubeconfigPath()); err != nil {
+		return err
 	}
 
 	// Show cluster info
-	fmt.Printf("Showing cluster info for context kind-%s...\n", clusterName)
-	kubectlCmd := exec.Command("kubectl", "cluster-info", "--context", getKubectlContext(), "--kubeconfig", kubeconfigPath)
+	fmt.Printf("Showing cluster info for context %s...\n", getKubectlContext())
+	kubectlCmd := command("kubectl", "cluster-info", "--context", getKubectlContext(), "--kubeconfig", getKubeconfigPath())
 	kubectlCmd.Stdout = os.Stdout
 	kubectlCmd.Stderr = os.Stderr
 	if err := kubectlCmd.Run(); err != nil {
 		return fmt.Errorf("failed to show cluster info: %w", err)
 	}
 
-	fmt.Println("Kind cluster created ✓")
+	fmt.Printf("%s cluster created ✓\n", getClusterProviderName())
 	fmt.Println("Note: Default CNI is disabled. Install Calico with: kubevirt-env install-calico")
 
 	return nil
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// depsBinDir is where `kubevirt-env deps install` puts the tools, the same
+// ./bin the Makefile installs its tools into.
+const depsBinDir = "bin"
+
+// dependency is an external tool that kubevirt-env runs, installed at a
+// pinned version by `kubevirt-env deps install`.
+type dependency struct {
+	name    string
+	version string
+	// asset returns the release asset for a platform
+	asset func(version, goos, goarch string) string
+	// url returns the download URL of asset
+	url func(version, asset string) string
+	// githubRepo is set for tools whose checksum is the digest of the
+	// GitHub release asset
+	githubRepo string
+	// checksumURL is set for tools that publish a .sha256sum file per asset
+	checksumURL func(version, asset string) string
+	// archivePath is the path of the binary in a .tar.gz asset, if any
+	archivePath func(goos, goarch string) string
+}
+
+var dependencies = []dependency{
+	{
+		name:    "kind",
+		version: "v0.24.0",
+		asset: func(version, goos, goarch string) string {
+			return fmt.Sprintf("kind-%s-%s", goos, goarch)
+		},
+		url: func(version, asset string) string {
+			return githubReleaseAssetURL("kubernetes-sigs/kind", version, asset)
+		},
+		checksumURL: func(version, asset string) string {
+			return githubReleaseAssetURL("kubernetes-sigs/kind", version, asset+".sha256sum")
+		},
+	},
+	{
+		name:    "clusterctl",
+		version: "v1.11.0",
+		asset: func(version, goos, goarch string) string {
+			return fmt.Sprintf("clusterctl-%s-%s", goos, goarch)
+		},
+		url: func(version, asset string) string {
+			return githubReleaseAssetURL("kubernetes-sigs/cluster-api", version, asset)
+		},
+		githubRepo: "kubernetes-sigs/cluster-api",
+	},
+	{
+		name:    "virtctl",
+		version: "v1.6.0",
+		asset: func(version, goos, goarch string) string {
+			return fmt.Sprintf("virtctl-%s-%s-%s", version, goos, goarch)
+		},
+		url: func(version, asset string) string {
+			return githubReleaseAssetURL(kubevirtRepo, version, asset)
+		},
+		githubRepo: kubevirtRepo,
+	},
+}
+
+// depsOptions control how dependencies are installed.
+type depsOptions struct {
+	force        bool
+	skipChecksum bool
+}
+
+func newDepsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deps",
+		Short: "Manage the external tools",
+		Long:  "Install and list the external tools kubevirt-env runs (" + strings.Join(dependencyNames(), ", ") + ")",
+	}
+
+	cmd.AddCommand(newDepsInstallCmd())
+	cmd.AddCommand(newDepsListCmd())
+	return cmd
+}
+
+func newDepsInstallCmd() *cobra.Command {
+	var opts depsOptions
+
+	cmd := &cobra.Command{
+		Use:   "install [TOOL...]",
+		Short: "Install the external tools into ./bin",
+		Long: `Download pinned versions of the external tools into ./bin, verifying their checksums. All tools are installed when none is given.
+
+kubevirt-env runs the tools in ./bin before the ones in PATH, and installs a missing tool on first use.
+
+Tools: ` + strings.Join(dependencyNames(), ", "),
+		ValidArgs: dependencyNames(),
+		Args:      cobra.OnlyValidArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			deps := dependencies
+			if len(args) > 0 {
+				deps = nil
+				for _, name := range args {
+					dep, _ := findDependency(name)
+					deps = append(deps, dep)
+				}
+			}
+			for _, dep := range deps {
+				if err := installDependency(dep, opts); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.force, "force", false, "Download the tools even if ./bin already has them")
+	cmd.Flags().BoolVar(&opts.skipChecksum, "skip-checksum", false, "Do not verify the checksums of the downloads")
+	return cmd
+}
+
+func newDepsListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the external tools",
+		Long:  "List the external tools, their pinned version and the binary kubevirt-env runs",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "TOOL\tPINNED\tPATH")
+			for _, dep := range dependencies {
+				path, err := lookupTool(dep.name)
+				if err != nil {
+					path = "not installed"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", dep.name, dep.version, path)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func dependencyNames() []string {
+	names := make([]string, len(dependencies))
+	for i, dep := range dependencies {
+		names[i] = dep.name
+	}
+	return names
+}
+
+func findDependency(name string) (dependency, bool) {
+	for _, dep := range dependencies {
+		if dep.name == name {
+			return dep, true
+		}
+	}
+	return dependency{}, false
+}
+
+// lookupTool returns the binary of a tool, from ./bin or PATH.
+func lookupTool(name string) (string, error) {
+	local := filepath.Join(depsBinDir, name)
+	if info, err := os.Stat(local); err == nil && !info.IsDir() {
+		return "./" + local, nil
+	}
+	return exec.LookPath(name)
+}
+
+// toolPath returns the binary to run for a tool. It falls back to the bare
+// name, so a missing tool fails with the usual exec error.
+func toolPath(name string) string {
+	if path, err := lookupTool(name); err == nil {
+		return path
+	}
+	return name
+}
+
+// ensureTool installs a tool into ./bin when it is neither there nor in PATH.
+func ensureTool(name string) error {
+	if _, err := lookupTool(name); err == nil {
+		return nil
+	}
+	dep, ok := findDependency(name)
+	if !ok {
+		return fmt.Errorf("required command '%s' not found in PATH. Please install it first", name)
+	}
+	fmt.Printf("Required command '%s' not found, installing %s into ./%s...\n", name, dep.version, depsBinDir)
+	return installDependency(dep, depsOptions{})
+}
+
+func installDependency(dep dependency, opts depsOptions) error {
+	target := filepath.Join(depsBinDir, dep.name)
+	if _, err := os.Stat(target); err == nil && !opts.force {
+		fmt.Printf("%s is already installed in ./%s ✓\n", dep.name, depsBinDir)
+		return nil
+	}
+
+	goos, goarch := runtime.GOOS, runtime.GOARCH
+	if (goos != "linux" && goos != "darwin") || (goarch != "amd64" && goarch != "arm64") {
+		return fmt.Errorf("no %s download for %s/%s, please install it manually", dep.name, goos, goarch)
+	}
+	asset := dep.asset(dep.version, goos, goarch)
+	url := dep.url(dep.version, asset)
+
+	fmt.Printf("Downloading %s %s (%s/%s)...\n", dep.name, dep.version, goos, goarch)
+	data, err := httpGet(url)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", dep.name, err)
+	}
+
+	if opts.skipChecksum {
+		fmt.Printf("Warning: skipping checksum verification of %s\n", dep.name)
+	} else {
+		expected, err := dependencyChecksum(dep, asset)
+		if err != nil {
+			return fmt.Errorf("failed to get the checksum of %s (`kubevirt-env deps install %s --skip-checksum` installs it without verification): %w", dep.name, dep.name, err)
+		}
+		sum := sha256.Sum256(data)
+		if actual := hex.EncodeToString(sum[:]); actual != expected {
+			return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", asset, expected, actual)
+		}
+	}
+
+	if dep.archivePath != nil {
+		data, err = extractFromTarGz(data, dep.archivePath(goos, goarch))
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", dep.name, err)
+		}
+	}
+
+	if err := os.MkdirAll(depsBinDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", depsBinDir, err)
+	}
+	// Write next to the target and rename, so an interrupted download
+	// never leaves a truncated binary behind
+	tmp := target + ".download"
+	if err := os.WriteFile(tmp, data, 0755); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dep.name, err)
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to install %s: %w", dep.name, err)
+	}
+
+	fmt.Printf("%s %s installed in ./%s ✓\n", dep.name, dep.version, depsBinDir)
+	return nil
+}
+
+// dependencyChecksum returns the expected SHA-256 of asset, as published
+// with the release.
+func dependencyChecksum(dep dependency, asset string) (string, error) {
+	switch {
+	case dep.checksumURL != nil:
+		data, err := httpGet(dep.checksumURL(dep.version, asset))
+		if err != nil {
+			return "", err
+		}
+		// "<sha256>  <file>"
+		fields := strings.Fields(string(data))
+		if len(fields) == 0 || len(fields[0]) != sha256.Size*2 {
+			return "", fmt.Errorf("invalid checksum file for %s", asset)
+		}
+		return strings.ToLower(fields[0]), nil
+	case dep.githubRepo != "":
+		return githubAssetDigest(dep.githubRepo, dep.version, asset)
+	default:
+		return "", fmt.Errorf("no checksum published for %s", asset)
+	}
+}
+
+// githubAssetDigest returns the SHA-256 digest GitHub reports for a release
+// asset.
+func githubAssetDigest(repo, version, asset string) (string, error) {
+	data, err := httpGet(fmt.Sprintf("https://api.github.com/repos/%s/releases/tags/%s", repo, version))
+	if err != nil {
+		return "", err
+	}
+	var release struct {
+		Assets []struct {
+			Name   string `json:"name"`
+			Digest string `json:"digest"`
+		} `json:"assets"`
+	}
+	if err := json.Unmarshal(data, &release); err != nil {
+		return "", fmt.Errorf("failed to parse release %s of %s: %w", version, repo, err)
+	}
+	for _, a := range release.Assets {
+		if a.Name != asset {
+			continue
+		}
+		digest, ok := strings.CutPrefix(a.Digest, "sha256:")
+		if !ok {
+			return "", fmt.Errorf("release %s of %s has no digest for %s", version, repo, asset)
+		}
+		return digest, nil
+	}
+	return "", fmt.Errorf("release %s of %s has no asset %s", version, repo, asset)
+}
+
+func httpGet(url string) ([]byte, error) {
+	client := &http.Client{Timeout: 10 * time.Minute}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func extractFromTarGz(data []byte, path string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s not found in archive", path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Name == path && header.Typeflag == tar.TypeReg {
+			return io.ReadAll(tr)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// minFreeDisk covers the kind node images, the Kairos image build and
+	// the DataVolume of the uploaded image
+	minFreeDisk   = 50 << 30
+	minMemory     = 8 << 30
+	minInotify    = 524288
+	minInotifyIns = 512
+)
+
+// checkStatus is the outcome of a doctor check.
+type checkStatus int
+
+const (
+	checkOK checkStatus = iota
+	checkWarning
+	checkFailed
+)
+
+// checkResult is the outcome of a doctor check, with how to fix it.
+type checkResult struct {
+	status checkStatus
+	detail string
+	fix    string
+}
+
+// doctorCheck is a host prerequisite checked by `kubevirt-env doctor`.
+type doctorCheck struct {
+	name string
+	run  func() checkResult
+}
+
+func newDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the host prerequisites",
+		Long:  "Check that the host can run the environment (docker, KVM, disk, memory, inotify limits, tools, ports) and print how to fix what is missing",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoctor()
+		},
+	}
+}
+
+func doctorChecks() []doctorCheck {
+	return []doctorCheck{
+		{name: "docker", run: checkDocker},
+		{name: "kvm", run: checkKVM},
+		{name: "disk", run: checkDisk},
+		{name: "memory", run: checkMemory},
+		{name: "inotify", run: checkInotify},
+		{name: "tools", run: checkTools},
+		{name: "upload port", run: checkUploadPort},
+	}
+}
+
+func runDoctor() error {
+	failed, warnings := 0, 0
+	for _, check := range doctorChecks() {
+		result := check.run()
+		fix := ""
+		if result.status != checkOK && result.fix != "" {
+			fix = fmt.Sprintf("  Fix: %s\n", result.fix)
+		}
+		switch result.status {
+		case checkOK:
+			fmt.Printf("✓ %s: %s\n", check.name, result.detail)
+			emitEvent(event{Type: eventStep, Step: check.name, Status: eventSuccess})
+		case checkWarning:
+			warnings++
+			fmt.Printf("! %s: %s\n%s", check.name, result.detail, fix)
+			emitEvent(event{Type: eventStep, Step: check.name, Status: eventWarning, Error: result.detail})
+		case checkFailed:
+			failed++
+			reportf("✗ %s: %s\n%s", check.name, result.detail, fix)
+			emitEvent(event{Type: eventStep, Step: check.name, Status: eventFailure, Error: result.detail})
+		}
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed, %d warning(s)", failed, warnings)
+	}
+	if warnings > 0 {
+		fmt.Printf("All required checks passed, %d warning(s) ✓\n", warnings)
+		return nil
+	}
+	fmt.Println("All checks passed ✓")
+	return nil
+}
+
+func checkDocker() checkResult {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return checkResult{checkFailed, "docker not found in PATH", "install Docker: https://docs.docker.com/engine/install/"}
+	}
+	out, err := command("docker", "info", "--format", "{{.ServerVersion}}").CombinedOutput()
+	if err != nil {
+		return checkResult{checkFailed, "the Docker daemon is not reachable: " + strings.TrimSpace(string(out)),
+			"start Docker (e.g. `sudo systemctl start docker`) and make sure your user can use it (`sudo usermod -aG docker $USER`, then log in again)"}
+	}
+	return checkResult{checkOK, "Docker " + strings.TrimSpace(string(out)) + " is running", ""}
+}
+
+func checkKVM() checkResult {
+	emulation := "KubeVirt emulation is enabled, VMs will be slow"
+	if !shouldUseEmulation() {
+		emulation = "KUBEVIRT_USE_EMULATION=false requires /dev/kvm"
+	}
+	status := checkWarning
+	if !shouldUseEmulation() {
+		status = checkFailed
+	}
+
+	if runtime.GOOS != "linux" {
+		return checkResult{status, "/dev/kvm is only available on Linux; " + emulation, ""}
+	}
+	file, err := os.OpenFile("/dev/kvm", os.O_RDWR, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return checkResult{status, "/dev/kvm not found; " + emulation,
+				"enable virtualization in the BIOS, or nested virtualization when the host is a VM, and load the module (`sudo modprobe kvm_intel` or `kvm_amd`)"}
+		}
+		return checkResult{status, fmt.Sprintf("cannot open /dev/kvm: %v; %s", err, emulation),
+			"add your user to the kvm group: `sudo usermod -aG kvm $USER`, then log in again"}
+	}
+	file.Close()
+
+	detail := "/dev/kvm is available"
+	if nested, ok := kvmNested(); ok {
+		if !nested {
+			return checkResult{checkWarning, detail + ", but nested virtualization is disabled",
+				"enable it, e.g. `echo 'options kvm_intel nested=1' | sudo tee /etc/modprobe.d/kvm.conf` and reload the module"}
+		}
+		detail += " with nested virtualization"
+	}
+	if shouldUseEmulation() {
+		return checkResult{checkWarning, detail + ", but KubeVirt emulation is enabled",
+			"set KUBEVIRT_USE_EMULATION=false before `kubevirt-env setup` for hardware acceleration"}
+	}
+	return checkResult{checkOK, detail, ""}
+}
+
+// kvmNested reports whether the KVM module allows nested virtualization.
+func kvmNested() (bool, bool) {
+	for _, module := range []string{"kvm_intel", "kvm_amd"} {
+		data, err := os.ReadFile("/sys/module/" + module + "/parameters/nested")
+		if err != nil {
+			continue
+		}
+		value := strings.TrimSpace(string(data))
+		return value == "Y" || value == "1", true
+	}
+	return false, false
+}
+
+func checkDisk() checkResult {
+	path := "."
+	// kind stores the node images and volumes in the Docker root directory
+	if out, err := command("docker", "info", "--format", "{{.DockerRootDir}}").Output(); err == nil {
+		if dir := strings.TrimSpace(string(out)); dir != "" {
+			if _, err := os.Stat(dir); err == nil {
+				path = dir
+			}
+		}
+	}
+
+	free, err := freeDiskSpace(path)
+	if err != nil {
+		return checkResult{checkWarning, fmt.Sprintf("cannot check the free space of %s: %v", path, err), ""}
+	}
+	detail := fmt.Sprintf("%s free in %s", formatBytes(int64(free)), path)
+	if path != "." {
+		if cwdFree, err := freeDiskSpace("."); err == nil && cwdFree < free {
+			free = cwdFree
+			detail += fmt.Sprintf(", %s in the working directory", formatBytes(int64(cwdFree)))
+		}
+	}
+	if free < minFreeDisk {
+		return checkResult{checkFailed, detail + fmt.Sprintf(", at least %s is needed", formatBytes(minFreeDisk)),
+			"free up space, e.g. `docker system prune` and removing old work directories (.work-kubevirt-*)"}
+	}
+	return checkResult{checkOK, detail, ""}
+}
+
+func freeDiskSpace(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
+
+func checkMemory() checkResult {
+	if runtime.GOOS != "linux" {
+		return checkResult{checkWarning, "cannot check the memory on " + runtime.GOOS,
+			fmt.Sprintf("make sure Docker can use at least %s", formatBytes(minMemory))}
+	}
+	total, err := memTotal()
+	if err != nil {
+		return checkResult{checkWarning, fmt.Sprintf("cannot read the memory size: %v", err), ""}
+	}
+	detail := formatBytes(int64(total)) + " of memory"
+	if total < minMemory {
+		return checkResult{checkFailed, detail + fmt.Sprintf(", at least %s is needed for the control-plane VM", formatBytes(minMemory)),
+			"use a host with more memory"}
+	}
+	return checkResult{checkOK, detail, ""}
+}
+
+func memTotal() (uint64, error) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// "MemTotal:       16318412 kB"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0, err
+			}
+			return kb << 10, nil
+		}
+	}
+	return 0, fmt.Errorf("MemTotal not found in /proc/meminfo")
+}
+
+func checkInotify() checkResult {
+	if runtime.GOOS != "linux" {
+		return checkResult{checkOK, "not applicable on " + runtime.GOOS, ""}
+	}
+	watches, err := readSysctl("fs/inotify/max_user_watches")
+	if err != nil {
+		return checkResult{checkWarning, fmt.Sprintf("cannot read the inotify limits: %v", err), ""}
+	}
+	instances, err := readSysctl("fs/inotify/max_user_instances")
+	if err != nil {
+		return checkResult{checkWarning, fmt.Sprintf("cannot read the inotify limits: %v", err), ""}
+	}
+	detail := fmt.Sprintf("max_user_watches=%d, max_user_instances=%d", watches, instances)
+	if watches < minInotify || instances < minInotifyIns {
+		return checkResult{checkWarning, detail + ", too low for a multi-node kind cluster (pods fail with \"too many open files\")",
+			fmt.Sprintf("`sudo sysctl fs.inotify.max_user_watches=%d fs.inotify.max_user_instances=%d`, and add them to /etc/sysctl.conf to persist", minInotify, minInotifyIns)}
+	}
+	return checkResult{checkOK, detail, ""}
+}
+
+func readSysctl(name string) (int, error) {
+	data, err := os.ReadFile("/proc/sys/" + name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+func checkTools() checkResult {
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return checkResult{checkFailed, "kubectl not found in PATH", "install kubectl: https://kubernetes.io/docs/tasks/tools/"}
+	}
+
+	// kind is a dependency, the tools of the other providers are not
+	provider := getClusterProvider().tool()
+	if _, ok := findDependency(provider); !ok {
+		if _, err := lookupTool(provider); err != nil {
+			return checkResult{checkFailed, provider + " not found in PATH, required by --provider " + getClusterProviderName(),
+				"install " + provider + ", or use --provider kind"}
+		}
+	}
+
+	var missing []string
+	for _, dep := range dependencies {
+		if _, err := lookupTool(dep.name); err != nil {
+			missing = append(missing, dep.name)
+		}
+	}
+	if len(missing) > 0 {
+		return checkResult{checkWarning, "kubectl found, missing " + strings.Join(missing, ", ") + " (installed into ./bin on first use)",
+			"`kubevirt-env deps install` installs them now"}
+	}
+	return checkResult{checkOK, "kubectl and " + strings.Join(dependencyNames(), ", ") + " found", ""}
+}
+
+func checkUploadPort() checkResult {
+	port := getCDIUploadPort()
+	listener, err := net.Listen("tcp", net.JoinHostPort("localhost", strconv.Itoa(port)))
+	if err != nil {
+		return checkResult{checkFailed, fmt.Sprintf("port %d, used to upload the Kairos image, is in use", port),
+			"stop what listens on it, or set CDI_UPLOAD_PORT to a free port"}
+	}
+	listener.Close()
+	return checkResult{checkOK, fmt.Sprintf("port %d is free", port), ""}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	kairosHelmRepo = "https://kairos-io.github.io/helm-charts"
+	helmNamespace  = "default"
+)
+
+// helmChart is a chart of the Kairos repository installed as a release of the
+// same name.
+type helmChart struct {
+	name string
+	// wait for the resources of the release to be ready, up to timeout
+	wait    bool
+	timeout time.Duration
+}
+
+// helmSettings returns the Helm settings for the environment's cluster. The
+// charts are downloaded to the Helm repository cache.
+func helmSettings() *cli.EnvSettings {
+	settings := cli.New()
+	settings.KubeConfig = getKubeconfigPath()
+	settings.KubeContext = getKubectlContext()
+	settings.SetNamespace(helmNamespace)
+	return settings
+}
+
+func helmConfiguration(settings *cli.EnvSettings) (*action.Configuration, error) {
+	cfg := new(action.Configuration)
+	if err := cfg.Init(settings.RESTClientGetter(), helmNamespace, os.Getenv("HELM_DRIVER"), helmLog); err != nil {
+		return nil, fmt.Errorf("failed to initialize helm: %w", err)
+	}
+	return cfg, nil
+}
+
+// helmLog prints the Helm debug messages in verbose mode.
+func helmLog(format string, v ...interface{}) {
+	if isVerbose() {
+		fmt.Fprintf(os.Stderr, "helm: "+format+"\n", v...)
+	}
+}
+
+// upgradeOrInstallChart installs chart, or upgrades its release when it is
+// already installed, like helm upgrade --install. The values are the ones set
+// for the release in the config file.
+func upgradeOrInstallChart(chart helmChart) error {
+	settings := helmSettings()
+	cfg, err := helmConfiguration(settings)
+	if err != nil {
+		return err
+	}
+	values, err := helmValues(chart.name)
+	if err != nil {
+		return err
+	}
+
+	upgrade := action.NewUpgrade(cfg)
+	upgrade.Install = true
+	upgrade.Namespace = helmNamespace
+	upgrade.RepoURL = kairosHelmRepo
+	upgrade.Wait = chart.wait
+	upgrade.Timeout = chart.timeout
+
+	chartPath, err := upgrade.LocateChart(chart.name, settings)
+	if err != nil {
+		return fmt.Errorf("failed to download chart %s: %w", chart.name, err)
+	}
+	loaded, err := loader.Load(chartPath)
+	if err != nil {
+		return fmt.Errorf("failed to load chart %s: %w", chart.name, err)
+	}
+
+	// action.Upgrade doesn't install by itself, the install is done when the
+	// release has no history, as helm upgrade --install does
+	history := action.NewHistory(cfg)
+	history.Max = 1
+	if _, err := history.Run(chart.name); errors.Is(err, driver.ErrReleaseNotFound) {
+		install := action.NewInstall(cfg)
+		install.ReleaseName = chart.name
+		install.Namespace = helmNamespace
+		install.CreateNamespace = true
+		install.Wait = upgrade.Wait
+		install.Timeout = upgrade.Timeout
+		if _, err := install.RunWithContext(interruptCtx, loaded, values); err != nil {
+			return fmt.Errorf("failed to install release %s: %w", chart.name, err)
+		}
+		fmt.Printf("Release %q installed\n", chart.name)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get history of release %s: %w", chart.name, err)
+	}
+
+	if _, err := upgrade.RunWithContext(interruptCtx, chart.name, loaded, values); err != nil {
+		return fmt.Errorf("failed to upgrade release %s: %w", chart.name, err)
+	}
+	fmt.Printf("Release %q upgraded\n", chart.name)
+	return nil
+}
+
+// uninstallRelease uninstalls a release of the Helm namespace.
+func uninstallRelease(release string) error {
+	cfg, err := helmConfiguration(helmSettings())
+	if err != nil {
+		return err
+	}
+	if _, err := action.NewUninstall(cfg).Run(release); err != nil {
+		return fmt.Errorf("failed to uninstall release %s: %w", release, err)
+	}
+	return nil
+}
+
+// helmValues returns the values of release set under helm.<release>.values in
+// the config file.
+func helmValues(release string) (map[string]interface{}, error) {
+	configFile := viper.ConfigFileUsed()
+	if configFile == "" {
+		return nil, nil
+	}
+	// viper lowercases keys, so the values are read from the file itself
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var config struct {
+		Helm map[string]struct {
+			Values map[string]interface{} `json:"values"`
+		} `json:"helm"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", configFile, err)
+	}
+	return config.Helm[release].Values, nil
+}
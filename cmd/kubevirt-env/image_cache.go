@@ -0,0 +1,128 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+// getImageCacheDir returns the directory where built Kairos images are kept
+// for reuse across clusters.
+func getImageCacheDir() (string, error) {
+	if dir := viper.GetString("kairos-image.cache-dir"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the user cache directory (set kairos-image.cache-dir): %w", err)
+	}
+	return filepath.Join(dir, "kubevirt-env", "images"), nil
+}
+
+func reuseKairosImage() bool {
+	return viper.GetBool("kairos-image.reuse")
+}
+
+// kairosImageSpecHash identifies the image the OSArtifact builds: the
+// OSArtifact and the cloud-config baked into the image.
+func kairosImageSpecHash() (string, error) {
+	cloudConfig, err := getKairosCloudConfig()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	hash.Write([]byte(osArtifactManifest()))
+	hash.Write([]byte("\n---\n"))
+	hash.Write([]byte(cloudConfig))
+	return hex.EncodeToString(hash.Sum(nil))[:16], nil
+}
+
+// cachedKairosImageFile returns where the image of the current OSArtifact
+// spec is cached.
+func cachedKairosImageFile() (string, error) {
+	dir, err := getImageCacheDir()
+	if err != nil {
+		return "", err
+	}
+	specHash, err := kairosImageSpecHash()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, specHash, fmt.Sprintf("%s.%s", kairosImageName, getKairosImageFormat())), nil
+}
+
+// findCachedKairosImage returns the cached image of the current OSArtifact
+// spec, if there is one.
+func findCachedKairosImage() (string, bool) {
+	path, err := cachedKairosImageFile()
+	if err != nil {
+		return "", false
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return "", false
+	}
+	return path, true
+}
+
+// cacheKairosImage adds a built image and its checksum to the cache. The
+// files are hard linked when possible, so the cache costs no extra space.
+func cacheKairosImage(imageFile string) error {
+	path, err := cachedKairosImageFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create image cache directory: %w", err)
+	}
+	for _, f := range [][2]string{
+		{imageFile, path},
+		{imageChecksumFile(imageFile), imageChecksumFile(path)},
+	} {
+		if err := linkOrCopyFile(f[0], f[1]); err != nil {
+			return fmt.Errorf("failed to cache image: %w", err)
+		}
+	}
+	fmt.Printf("Cached image: %s\n", path)
+	return nil
+}
+
+func linkOrCopyFile(src, dst string) error {
+	// Write next to dst and rename, so an interrupted copy never leaves a
+	// truncated image in the cache
+	tmp := dst + ".tmp"
+	os.Remove(tmp)
+	if err := os.Link(src, tmp); err != nil {
+		if err := copyFile(src, tmp); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// imageChecksumFile is the sha256sum-style file recording the checksum of
+// imageFile.
+func imageChecksumFile(imageFile string) string {
+	return imageFile + ".sha256"
+}
+
+func writeImageChecksum(imageFile, checksum string) error {
+	line := fmt.Sprintf("%s  %s\n", checksum, filepath.Base(imageFile))
+	// Replaced rather than overwritten, as it may be hard linked into the
+	// image cache
+	os.Remove(imageChecksumFile(imageFile))
+	if err := os.WriteFile(imageChecksumFile(imageFile), []byte(line), 0644); err != nil {
+		return fmt.Errorf("failed to write image checksum: %w", err)
+	}
+	return nil
+}
+
+// imageChecksum returns the SHA-256 of imageFile. The checksum recorded when
+// the image was downloaded is used unless the image changed since; otherwise
+// it is computed and recorded.
+func imageChecksum(imageFile string) (string, error) {
+	info, err := os.Stat(imageFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image file: %w", err)
+	}
+	if sumInfo, err := os.Stat(imageChecksumFile(imageFile)); err == nil && !sumInfo.ModTime().Before(info.ModTime()) {
+		data, err := os.ReadFile(imageChecksumFile(imageFile))
+		if err == nil {
+			if fields := strings.Fields(string(data)); len(fields) > 0 && len(fields[0]) == sha256.Size*2 {
+				return fields[0], nil
+			}
+		}
+	}
+
+	fmt.Printf("Computing SHA256 of %s...\n", filepath.Base(imageFile))
+	file, err := os.Open(imageFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to open image file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	progress := newProgressReader(file, info.Size())
+	_, err = io.Copy(hash, progress)
+	progress.finish()
+	if err != nil {
+		return "", fmt.Errorf("failed to read image file: %w", err)
+	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	if err := writeImageChecksum(imageFile, checksum); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	return checksum, nil
+}
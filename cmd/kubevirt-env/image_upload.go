@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// imageChecksumAnnotation records the SHA-256 of the image uploaded to a
+	// DataVolume, so the same image is not uploaded again and an interrupted
+	// upload of it reuses the DataVolume.
+	imageChecksumAnnotation = "kubevirt-env.kairos.io/image-sha256"
+	// bindImmediateAnnotation binds the PVC of the DataVolume without waiting
+	// for a consumer, like `virtctl image-upload --force-bind`.
+	bindImmediateAnnotation = "cdi.kubevirt.io/storage.bind.immediate.requested"
+
+	uploadAttempts = 3
+)
+
+var (
+	dataVolumeGVR = schema.GroupVersionResource{
+		Group:    "cdi.kubevirt.io",
+		Version:  "v1beta1",
+		Resource: "datavolumes",
+	}
+	uploadTokenRequestGVR = schema.GroupVersionResource{
+		Group:    "upload.cdi.kubevirt.io",
+		Version:  "v1beta1",
+		Resource: "uploadtokenrequests",
+	}
+)
+
+// imageUpload uploads an image file to a DataVolume through the CDI upload
+// proxy, as `virtctl image-upload` does: it creates the DataVolume with an
+// upload source, requests an upload token once the upload server is ready,
+// and PUTs the file to the proxy.
+type imageUpload struct {
+	dynamicClient dynamic.Interface
+	namespace     string
+	name          string
+	size          string
+	imageFile     string
+	proxyURL      string
+}
+
+// run uploads the image. A DataVolume left by an interrupted upload of the
+// same image, by checksum, is reused; one that already holds it is kept as
+// is. The CDI upload server accepts a whole image per request, so a failed
+// transfer is retried from the start.
+func (u *imageUpload) run() error {
+	info, err := os.Stat(u.imageFile)
+	if err != nil {
+		return fmt.Errorf("failed to read image file: %w", err)
+	}
+	checksum, err := imageChecksum(u.imageFile)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Image SHA256: %s\n", checksum)
+
+	ctx := interruptCtx
+	phase, reuse, err := u.existingDataVolume(ctx, checksum)
+	if err != nil {
+		return err
+	}
+	switch {
+	case reuse && phase == "Succeeded":
+		fmt.Printf("DataVolume %s already holds this image, skipping upload ✓\n", u.name)
+		return nil
+	case reuse:
+		fmt.Printf("Resuming upload to existing DataVolume %s (phase %s)...\n", u.name, phase)
+	default:
+		fmt.Printf("Creating DataVolume %s (%s)...\n", u.name, u.size)
+		if err := u.createDataVolume(ctx, checksum); err != nil {
+			return err
+		}
+	}
+
+	var uploadErr error
+	for attempt := 1; attempt <= uploadAttempts; attempt++ {
+		if attempt > 1 {
+			fmt.Printf("Warning: upload attempt %d/%d failed: %v\n", attempt-1, uploadAttempts, uploadErr)
+			fmt.Println("Retrying upload from the start...")
+		}
+		if err := u.waitForPhase("UploadReady"); err != nil {
+			return err
+		}
+		token, err := u.requestToken(ctx)
+		if err != nil {
+			return err
+		}
+		if uploadErr = u.put(token, info.Size(), checksum); uploadErr == nil {
+			break
+		}
+	}
+	if uploadErr != nil {
+		return fmt.Errorf("upload failed after %d attempts: %w", uploadAttempts, uploadErr)
+	}
+
+	fmt.Println("Waiting for CDI to process the image...")
+	return u.waitForPhase("Succeeded")
+}
+
+// existingDataVolume returns the phase of the DataVolume and whether it can
+// be reused for the image with checksum. Any other DataVolume of that name is
+// deleted.
+func (u *imageUpload) existingDataVolume(ctx context.Context, checksum string) (string, bool, error) {
+	dvClient := u.dynamicClient.Resource(dataVolumeGVR).Namespace(u.namespace)
+	dv, err := dvClient.Get(ctx, u.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get DataVolume %s: %w", u.name, err)
+	}
+
+	phase, _, _ := unstructured.NestedString(dv.Object, "status", "phase")
+	if dv.GetAnnotations()[imageChecksumAnnotation] == checksum && dv.GetDeletionTimestamp() == nil && phase != "Failed" {
+		return phase, true, nil
+	}
+
+	fmt.Printf("DataVolume %s already exists. Deleting for fresh upload...\n", u.name)
+	if err := dvClient.Delete(ctx, u.name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return "", false, fmt.Errorf("failed to delete DataVolume %s: %w", u.name, err)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, getTimeout("image-upload"))
+	defer cancel()
+	err = wait.PollUntilContextCancel(waitCtx, 2*time.Second, true, func(ctx context.Context) (bool, error) {
+		_, err := dvClient.Get(ctx, u.name, metav1.GetOptions{})
+		return apierrors.IsNotFound(err), nil
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("timed out waiting for DataVolume %s to be deleted: %w", u.name, err)
+	}
+	return "", false, nil
+}
+
+func (u *imageUpload) createDataVolume(ctx context.Context, checksum string) error {
+	dv := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cdi.kubevirt.io/v1beta1",
+		"kind":       "DataVolume",
+		"metadata": map[string]interface{}{
+			"name":      u.name,
+			"namespace": u.namespace,
+			"annotations": map[string]interface{}{
+				imageChecksumAnnotation: checksum,
+				bindImmediateAnnotation: "true",
+			},
+		},
+		"spec": map[string]interface{}{
+			"source": map[string]interface{}{
+				"upload": map[string]interface{}{},
+			},
+			"storage": map[string]interface{}{
+				"accessModes": []interface{}{"ReadWriteOnce"},
+				"resources": map[string]interface{}{
+					"requests": map[string]interface{}{
+						"storage": u.size,
+					},
+				},
+			},
+		},
+	}}
+	if _, err := u.dynamicClient.Resource(dataVolumeGVR).Namespace(u.namespace).Create(ctx, dv, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create DataVolume %s: %w", u.name, err)
+	}
+	return nil
+}
+
+// waitForPhase waits for the DataVolume to reach phase, and fails early if
+// CDI reports it failed.
+func (u *imageUpload) waitForPhase(phase string) error {
+	ctx, cancel := waitContext("image-upload")
+	defer cancel()
+
+	var last string
+	err := wait.PollUntilContextCancel(ctx, 2*time.Second, true, func(ctx context.Context) (bool, error) {
+		dv, err := u.dynamicClient.Resource(dataVolumeGVR).Namespace(u.namespace).Get(ctx, u.name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		last, _, _ = unstructured.NestedString(dv.Object, "status", "phase")
+		if last == "Failed" {
+			return false, fmt.Errorf("DataVolume %s failed", u.name)
+		}
+		return last == phase, nil
+	})
+	if err != nil {
+		return fmt.Errorf("DataVolume %s did not reach phase %s (phase %q): %w", u.name, phase, last, err)
+	}
+	return nil
+}
+
+func (u *imageUpload) requestToken(ctx context.Context) (string, error) {
+	request := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "upload.cdi.kubevirt.io/v1beta1",
+		"kind":       "UploadTokenRequest",
+		"metadata": map[string]interface{}{
+			"name":      u.name,
+			"namespace": u.namespace,
+		},
+		"spec": map[string]interface{}{
+			"pvcName": u.name,
+		},
+	}}
+	response, err := u.dynamicClient.Resource(uploadTokenRequestGVR).Namespace(u.namespace).Create(ctx, request, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to request upload token: %w", err)
+	}
+	token, _, _ := unstructured.NestedString(response.Object, "status", "token")
+	if token == "" {
+		return "", fmt.Errorf("upload token request returned no token")
+	}
+	return token, nil
+}
+
+// put streams the image to the upload proxy. The async endpoint returns once
+// the data is received, and CDI processes it in the background; proxies
+// without it get the synchronous one. The data sent is checked against
+// checksum.
+func (u *imageUpload) put(token string, size int64, checksum string) error {
+	client := &http.Client{
+		Transport: &http.Transport{
+			// the upload proxy serves a self-signed certificate
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+		},
+	}
+
+	for _, path := range []string{"/v1beta1/upload-async", "/v1beta1/upload"} {
+		file, err := os.Open(u.imageFile)
+		if err != nil {
+			return fmt.Errorf("failed to open image file: %w", err)
+		}
+		hash := sha256.New()
+		progress := newProgressReader(io.TeeReader(file, hash), size)
+		req, err := http.NewRequest(http.MethodPut, u.proxyURL+path, progress)
+		if err != nil {
+			file.Close()
+			return err
+		}
+		req.ContentLength = size
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/octet-stream")
+
+		resp, err := client.Do(req)
+		progress.finish()
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to upload image: %w", err)
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		switch {
+		case resp.StatusCode == http.StatusNotFound && path == "/v1beta1/upload-async":
+			continue
+		case resp.StatusCode != http.StatusOK:
+			return fmt.Errorf("upload proxy returned %s: %s", resp.Status, string(body))
+		}
+		if sent := hex.EncodeToString(hash.Sum(nil)); sent != checksum {
+			return fmt.Errorf("image file changed during upload: sent SHA256 %s, expected %s", sent, checksum)
+		}
+		return nil
+	}
+	return errors.New("upload proxy has no upload endpoint")
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
@@ -19,12 +21,12 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
-	"os/exec"
 	"os/signal"
 	"strconv"
 	"syscall"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 const (
@@ -38,6 +40,9 @@ func newBuildKairosImageCmd() *cobra.Command {
 		Short: "Build Kairos cloud image",
 		Long:  "Build Kairos cloud image using OSArtifact CR (requires osbuilder to be installed)",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if _, ok := findCachedKairosImage(); ok && reuseKairosImage() {
+				return nil
+			}
 			// Validate osbuilder is installed
 			if !isOsbuilderInstalled() {
 				return fmt.Errorf("osbuilder is not installed. Please install it first with: kubevirt-env install osbuilder")
@@ -49,6 +54,16 @@ func newBuildKairosImageCmd() *cobra.Command {
 		},
 	}
 
+	fs := cmd.Flags()
+	fs.String("base-image", "", fmt.Sprintf("Kairos base image (default %s for the --arch)", fmt.Sprintf(defaultKairosBaseImage, "<arch>")))
+	fs.String("arch", defaultKairosArch, "Architecture of the default base image (amd64, arm64)")
+	fs.String("disk-size", defaultKairosDiskSize, "Disk size of the image in MB")
+	fs.String("cloud-config-file", "", "File with the cloud-config baked into the image (default adds serial console parameters)")
+	fs.String("image-format", defaultKairosImageFormat, "Format of the image (raw, qcow2)")
+	for _, name := range []string{"base-image", "arch", "disk-size", "cloud-config-file", "image-format"} {
+		viper.BindPFlag("kairos-image."+name, fs.Lookup(name))
+	}
+
 	return cmd
 }
 
@@ -60,7 +75,7 @@ func newUploadKairosImageCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "upload-kairos-image",
 		Short: "Upload Kairos image to KubeVirt",
-		Long:  "Upload Kairos image to KubeVirt as a DataVolume using virtctl",
+		Long:  "Upload Kairos image to KubeVirt as a DataVolume through the CDI upload proxy. Rerunning it after an interrupted upload of the same image reuses the DataVolume",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return uploadKairosImage()
 		},
@@ -70,7 +85,7 @@ func newUploadKairosImageCmd() *cobra.Command {
 }
 
 func uploadKairosImage() error {
-	fmt.Println("=== Uploading Kairos image using virtctl ===")
+	fmt.Println("=== Uploading Kairos image to CDI ===")
 
 	// Find image file
 	imageFile, err := findKairosImageFile()
@@ -79,26 +94,18 @@ func uploadKairosImage() error {
 	}
 	fmt.Printf("Using image file: %s\n", imageFile)
 
-	// Check virtctl
-	virtctlPath, err := findVirtctl()
-	if err != nil {
-		return err
-	}
-	fmt.Printf("Using virtctl: %s\n", virtctlPath)
-
 	// Check CDI is installed
 	clientset, err := getKubeClient()
 	if err != nil {
 		return err
 	}
 
-	ctx := context.Background()
+	ctx := interruptCtx
 	_, err = clientset.CoreV1().Services("cdi").Get(ctx, "cdi-uploadproxy", metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("CDI upload proxy service not found. Make sure CDI is installed: %w", err)
 	}
 
-	// Delete existing DataVolume if present
 	config, err := getKubeConfig()
 	if err != nil {
 		return err
@@ -109,84 +116,37 @@ func uploadKairosImage() error {
 		return fmt.Errorf("failed to create dynamic client: %w", err)
 	}
 
-	dvGVR := schema.GroupVersionResource{
-		Group:    "cdi.kubevirt.io",
-		Version:  "v1beta1",
-		Resource: "datavolumes",
-	}
-
-	_, err = dynamicClient.Resource(dvGVR).Namespace("default").Get(ctx, kairosImageName, metav1.GetOptions{})
-	if err == nil {
-		fmt.Printf("DataVolume %s already exists. Deleting for fresh upload...\n", kairosImageName)
-		err = dynamicClient.Resource(dvGVR).Namespace("default").Delete(ctx, kairosImageName, metav1.DeleteOptions{})
-		if err != nil {
-			fmt.Printf("Warning: Failed to delete existing DataVolume: %v\n", err)
-		}
-		time.Sleep(2 * time.Second)
-	}
-
 	// Set up port-forward
-	port := defaultPort
-	if envPort := os.Getenv("CDI_UPLOAD_PORT"); envPort != "" {
-		if p, err := strconv.Atoi(envPort); err == nil {
-			port = p
-		}
-	}
-
+	port := getCDIUploadPort()
 	fmt.Printf("Setting up port-forward on port %d...\n", port)
-	kubeconfigPath := getKubeconfigPath()
-	kubectlContext := getKubectlContext()
-
-	portForwardCmd := exec.Command("kubectl", "port-forward", "-n", "cdi", "service/cdi-uploadproxy",
-		fmt.Sprintf("%d:443", port), "--kubeconfig", kubeconfigPath, "--context", kubectlContext)
-	portForwardCmd.Stdout = os.Stdout
-	portForwardCmd.Stderr = os.Stderr
-
-	if err := portForwardCmd.Start(); err != nil {
+	portForward, err := startServicePortForward("cdi", "cdi-uploadproxy", 443, port)
+	if err != nil {
 		return fmt.Errorf("failed to start port-forward: %w", err)
 	}
+	defer portForward.stop()
+	fmt.Printf("Forwarding localhost:%d to cdi/cdi-uploadproxy:443 ✓\n", port)
 
-	// Set up signal handling to cleanup port-forward
+	// Close the local port on interrupt
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
 	go func() {
 		<-sigChan
-		portForwardCmd.Process.Kill()
+		portForward.stop()
 		os.Exit(1)
 	}()
 
-	defer func() {
-		if portForwardCmd.Process != nil {
-			portForwardCmd.Process.Kill()
-			portForwardCmd.Wait()
-		}
-	}()
-
-	// Wait a bit for port-forward to be ready
-	time.Sleep(2 * time.Second)
-
-	// Run virtctl upload
-	uploadProxyURL := fmt.Sprintf("https://localhost:%d", port)
-	fmt.Printf("Upload proxy URL: %s\n", uploadProxyURL)
-	fmt.Println("Starting upload with virtctl...")
-
-	virtctlCmd := exec.Command(virtctlPath, "image-upload",
-		"dv", kairosImageName,
-		"--size=25Gi",
-		"--access-mode=ReadWriteOnce",
-		"--image-path", imageFile,
-		"--uploadproxy-url", uploadProxyURL,
-		"--insecure",
-		"--force-bind",
-		"--wait-secs=300",
-		"--kubeconfig", kubeconfigPath,
-		"--context", kubectlContext,
-	)
-	virtctlCmd.Stdout = os.Stdout
-	virtctlCmd.Stderr = os.Stderr
-
-	if err := virtctlCmd.Run(); err != nil {
-		return fmt.Errorf("virtctl image-upload failed: %w", err)
+	upload := &imageUpload{
+		dynamicClient: dynamicClient,
+		namespace:     "default",
+		name:          kairosImageName,
+		size:          getKairosUploadSize(),
+		imageFile:     imageFile,
+		proxyURL:      fmt.Sprintf("https://localhost:%d", port),
+	}
+	fmt.Printf("Upload proxy URL: %s\n", upload.proxyURL)
+	if err := upload.run(); err != nil {
+		return err
 	}
 
 	fmt.Println("\n✓ Image upload completed successfully!")
@@ -194,6 +154,17 @@ func uploadKairosImage() error {
 	return nil
 }
 
+// getCDIUploadPort returns the local port the CDI upload proxy is forwarded
+// to.
+func getCDIUploadPort() int {
+	if envPort := os.Getenv("CDI_UPLOAD_PORT"); envPort != "" {
+		if p, err := strconv.Atoi(envPort); err == nil {
+			return p
+		}
+	}
+	return defaultPort
+}
+
 func findKairosImageFile() (string, error) {
 	// Check KAIROS_IMAGE_FILE env var
 	if envFile := os.Getenv("KAIROS_IMAGE_FILE"); envFile != "" {
@@ -202,8 +173,15 @@ func findKairosImageFile() (string, error) {
 		}
 	}
 
+	// A cached image is used as is in --reuse-image mode
+	if reuseKairosImage() {
+		if path, ok := findCachedKairosImage(); ok {
+			return path, nil
+		}
+	}
+
 	// Check default location
-	defaultFile := filepath.Join(getKairosImageBuildDir(), fmt.Sprintf("%s.raw", kairosImageName))
+	defaultFile := filepath.Join(getKairosImageBuildDir(), fmt.Sprintf("%s.%s", kairosImageName, getKairosImageFormat()))
 	if _, err := os.Stat(defaultFile); err == nil {
 		return defaultFile, nil
 	}
@@ -224,25 +202,22 @@ func findKairosImageFile() (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("image file not found. Expected: %s or in %s", defaultFile, buildDir)
-}
-
-func findVirtctl() (string, error) {
-	// Check PATH
-	if path, err := exec.LookPath("virtctl"); err == nil {
+	if path, ok := findCachedKairosImage(); ok {
 		return path, nil
 	}
 
-	// Check bin directory
-	binPath := filepath.Join(".", "bin", "virtctl")
-	if _, err := os.Stat(binPath); err == nil {
-		return binPath, nil
-	}
-
-	return "", fmt.Errorf("virtctl not found in PATH or ./bin/virtctl. Please install virtctl first")
+	return "", fmt.Errorf("image file not found. Expected: %s or in %s", defaultFile, buildDir)
 }
 
 func buildKairosImage() error {
+	if reuseKairosImage() {
+		if path, ok := findCachedKairosImage(); ok {
+			fmt.Printf("Reusing cached Kairos image %s ✓\n", path)
+			return nil
+		}
+		fmt.Println("No cached Kairos image matches the OSArtifact spec, building it")
+	}
+
 	fmt.Println("Building Kairos cloud image using OSArtifact CR...")
 	fmt.Println("Note: osbuilder controller will create a Job to build the image.")
 	fmt.Println("The built image will be served via nginx service.")
@@ -284,7 +259,7 @@ func buildKairosImage() error {
 	}
 
 	// Wait for OSArtifact to be ready
-	if err := waitForOSArtifactReady(dynamicClient); err != nil {
+	if err := waitForOSArtifactReady(clientset, dynamicClient); err != nil {
 		return fmt.Errorf("failed to wait for OSArtifact: %w", err)
 	}
 
@@ -293,21 +268,22 @@ func buildKairosImage() error {
 		return fmt.Errorf("failed to download image: %w", err)
 	}
 
+	imageFile := filepath.Join(buildDir, fmt.Sprintf("%s.%s", kairosImageName, getKairosImageFormat()))
+	if err := cacheKairosImage(imageFile); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
 	fmt.Println("Kairos image build complete ✓")
 	return nil
 }
 
 func createCloudConfigSecret(clientset kubernetes.Interface) error {
-	fmt.Println("Creating cloud-config Secret with console parameters...")
-
-	cloudConfig := `#cloud-config
+	fmt.Println("Creating cloud-config Secret...")
 
-# Add console parameters to kernel cmdline for serial console access
-# console=ttyS0 enables serial console, console=tty0 enables VGA console
-install:
-  grub_options:
-    extra_cmdline: "console=ttyS0 console=tty0"
-`
+	cloudConfig, err := getKairosCloudConfig()
+	if err != nil {
+		return err
+	}
 
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -319,8 +295,8 @@ install:
 		},
 	}
 
-	ctx := context.Background()
-	_, err := clientset.CoreV1().Secrets("default").Create(ctx, secret, metav1.CreateOptions{})
+	ctx := interruptCtx
+	_, err = clientset.CoreV1().Secrets("default").Create(ctx, secret, metav1.CreateOptions{})
 	if err != nil {
 		// Try update if it already exists
 		_, err = clientset.CoreV1().Secrets("default").Update(ctx, secret, metav1.UpdateOptions{})
@@ -335,15 +311,25 @@ install:
 func createOSArtifactCR(dynamicClient dynamic.Interface, config *rest.Config) error {
 	fmt.Println("Creating OSArtifact CustomResource...")
 
-	osartifactYAML := fmt.Sprintf(`apiVersion: build.kairos.io/v1alpha2
+	// Apply YAML content directly using dynamic client
+	if err := applyManifestContent(dynamicClient, config, []byte(osArtifactManifest())); err != nil {
+		return fmt.Errorf("failed to apply OSArtifact: %w", err)
+	}
+
+	return nil
+}
+
+// osArtifactManifest returns the OSArtifact that builds the Kairos image.
+func osArtifactManifest() string {
+	return fmt.Sprintf(`apiVersion: build.kairos.io/v1alpha2
 kind: OSArtifact
 metadata:
   name: %s
   namespace: default
 spec:
-  imageName: "quay.io/kairos/fedora:40-core-amd64-generic-v3.6.1-beta2"
+  imageName: %q
   cloudImage: true
-  diskSize: "32000"
+  diskSize: %q
   cloudConfigRef:
     name: %s-cloud-config
     key: cloud_config.yaml
@@ -353,7 +339,34 @@ spec:
         restartPolicy: Never
         containers:
         - name: upload
-          image: quay.io/curl/curl
+%s          volumeMounts:
+          - name: artifacts
+            mountPath: /artifacts
+`, kairosImageName, getKairosBaseImage(), getKairosDiskSize(), kairosImageName, osArtifactExporter(getKairosImageFormat()))
+}
+
+// osArtifactExporter returns the exporter container, without its volume
+// mounts, that uploads the built image to nginx. osbuilder builds a raw
+// image; for qcow2 the exporter converts it first.
+func osArtifactExporter(format string) string {
+	if format == "qcow2" {
+		return `          image: alpine:3.20
+          command:
+          - /bin/sh
+          args:
+          - -c
+          - |
+              set -e
+              apk add --no-cache curl qemu-img
+              for f in /artifacts/*.raw
+              do
+              out=/tmp/$(basename "${f%.raw}").qcow2
+              qemu-img convert -O qcow2 "$f" "$out"
+              curl -T "$out" http://osartifactbuilder-operator-osbuilder-nginx/upload/$(basename "$out")
+              done
+`
+	}
+	return `          image: quay.io/curl/curl
           command:
           - /bin/sh
           args:
@@ -363,22 +376,12 @@ spec:
               do
               curl -T /artifacts/$f http://osartifactbuilder-operator-osbuilder-nginx/upload/$f
               done
-          volumeMounts:
-          - name: artifacts
-            mountPath: /artifacts
-`, kairosImageName, kairosImageName)
-
-	// Apply YAML content directly using dynamic client
-	if err := applyManifestContent(dynamicClient, config, []byte(osartifactYAML)); err != nil {
-		return fmt.Errorf("failed to apply OSArtifact: %w", err)
-	}
-
-	return nil
+`
 }
 
-func waitForOSArtifactReady(dynamicClient dynamic.Interface) error {
-	fmt.Println("Waiting for OSArtifact to be ready...")
-	ctx, cancel := context.WithTimeout(context.Background(), 1800*time.Second)
+func waitForOSArtifactReady(clientset kubernetes.Interface, dynamicClient dynamic.Interface) error {
+	fmt.Println("Waiting for OSArtifact to be ready, streaming the osbuilder logs...")
+	ctx, cancel := waitContext("image-build")
 	defer cancel()
 
 	osartifactGVR := schema.GroupVersionResource{
@@ -387,42 +390,46 @@ func waitForOSArtifactReady(dynamicClient dynamic.Interface) error {
 		Resource: "osartifacts",
 	}
 
-	return wait.PollUntilContextCancel(ctx, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+	logs := newBuildLogStreamer(ctx, clientset, "default", kairosImageName)
+
+	var phase string
+	err := wait.PollUntilContextCancel(ctx, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+		logs.poll(ctx)
+
 		osartifact, err := dynamicClient.Resource(osartifactGVR).Namespace("default").Get(ctx, kairosImageName, metav1.GetOptions{})
 		if err != nil {
-			fmt.Print(".")
 			return false, nil
 		}
 
-		phase, found, err := unstructured.NestedString(osartifact.Object, "status", "phase")
-		if !found || err != nil {
-			fmt.Print(".")
-			return false, nil
-		}
-
-		if phase == "Ready" {
-			fmt.Printf("\n✓ OSArtifact is ready (phase: %s)\n", phase)
+		phase, _, _ = unstructured.NestedString(osartifact.Object, "status", "phase")
+		switch phase {
+		case "Ready":
 			return true, nil
-		}
-
-		if phase == "Error" {
-			fmt.Println("\n✗ OSArtifact build failed. Check logs:")
-			// Print the full object for debugging
-			if objBytes, err := osartifact.MarshalJSON(); err == nil {
-				fmt.Println(string(objBytes))
-			}
+		case "Error":
 			return false, fmt.Errorf("OSArtifact build failed with phase: %s", phase)
 		}
-
-		fmt.Print(".")
 		return false, nil
 	})
+
+	// Let the streams print what is left before the summary
+	logs.stop(buildLogGracePeriod)
+
+	if err != nil {
+		fmt.Println("✗ OSArtifact build failed, osbuilder logs:")
+		logs.dumpTail(context.Background())
+		if phase != "Error" {
+			return fmt.Errorf("OSArtifact not ready (phase: %q): %w", phase, err)
+		}
+		return err
+	}
+	fmt.Printf("✓ OSArtifact is ready (phase: %s)\n", phase)
+	return nil
 }
 
 func downloadImageFromNginx(clientset kubernetes.Interface, buildDir string) error {
 	fmt.Println("Downloading built image from nginx...")
 
-	ctx := context.Background()
+	ctx := interruptCtx
 
 	// Find nginx service
 	services, err := clientset.CoreV1().Services("default").List(ctx, metav1.ListOptions{})
@@ -473,7 +480,7 @@ func downloadImageFromNginx(clientset kubernetes.Interface, buildDir string) err
 	}
 
 	// Download image
-	imageFilename := fmt.Sprintf("%s.raw", kairosImageName)
+	imageFilename := fmt.Sprintf("%s.%s", kairosImageName, getKairosImageFormat())
 	nginxURL := fmt.Sprintf("http://%s:%d/%s", nodeIP, nodePort, imageFilename)
 	outputFile := filepath.Join(buildDir, imageFilename)
 
@@ -489,17 +496,34 @@ func downloadImageFromNginx(clientset kubernetes.Interface, buildDir string) err
 		return fmt.Errorf("failed to download image: HTTP %d", resp.StatusCode)
 	}
 
+	// The old image may be hard linked into the image cache, so it is
+	// replaced rather than overwritten
+	if err := os.Remove(outputFile); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove old image file: %w", err)
+	}
 	outFile, err := os.Create(outputFile)
 	if err != nil {
 		return fmt.Errorf("failed to create output file: %w", err)
 	}
 	defer outFile.Close()
 
-	if _, err := io.Copy(outFile, resp.Body); err != nil {
+	hash := sha256.New()
+	progress := newProgressReader(resp.Body, resp.ContentLength)
+	_, err = io.Copy(io.MultiWriter(outFile, hash), progress)
+	progress.finish()
+	if err != nil {
+		return fmt.Errorf("failed to write image file: %w", err)
+	}
+	if err := outFile.Close(); err != nil {
 		return fmt.Errorf("failed to write image file: %w", err)
 	}
 
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	if err := writeImageChecksum(outputFile, checksum); err != nil {
+		return err
+	}
 	fmt.Printf("Downloaded to: %s\n", outputFile)
+	fmt.Printf("SHA256: %s (%s)\n", checksum, imageChecksumFile(outputFile))
 
 	// Check for built image
 	fmt.Println("Checking for built image...")
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -14,11 +13,13 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
 
 	"github.com/spf13/cobra"
 )
 
 const (
+	// kairosCapiImg is the image set by config/manager
 	kairosCapiImg = "ghcr.io/kairos-io/kairos-capi:latest"
 )
 
@@ -26,13 +27,13 @@ func newInstallKairosProviderCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "kairos-provider",
 		Short: "Install Kairos CAPI Provider",
-		Long:  "Install Kairos CAPI Provider on the kind cluster (requires cert-manager)",
+		Long:  "Install Kairos CAPI Provider on the local cluster (requires cert-manager)",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			// Validate cert-manager is installed
 			if !isCertManagerInstalled() {
 				return fmt.Errorf("cert-manager is not installed. Please install it first with: kubevirt-env install cert-manager")
 			}
-			return nil
+			return validateProviderInstalled()
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return installKairosProvider()
@@ -76,7 +77,7 @@ func isKairosProviderInstalled() bool {
 		return false
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(interruptCtx, 5*time.Second)
 	defer cancel()
 
 	// Check if kairos-capi-controller-manager deployment exists and is available
@@ -120,7 +121,7 @@ func buildAndLoadKairosProviderImage() error {
 	fmt.Println("Building Kairos CAPI Provider image...")
 
 	// Build Docker image using Makefile
-	makeCmd := exec.Command("make", "-f", "Makefile", "docker-build", fmt.Sprintf("IMG=%s", kairosCapiImg))
+	makeCmd := command("make", "-f", "Makefile", "docker-build", fmt.Sprintf("IMG=%s", getProviderImage()))
 	makeCmd.Dir = "."
 	makeCmd.Stdout = os.Stdout
 	makeCmd.Stderr = os.Stderr
@@ -128,14 +129,10 @@ func buildAndLoadKairosProviderImage() error {
 		return fmt.Errorf("failed to build Docker image: %w", err)
 	}
 
-	// Load image into Kind cluster
-	clusterName := getClusterName()
-	fmt.Println("Loading image into Kind cluster...")
-	kindCmd := exec.Command("kind", "load", "docker-image", kairosCapiImg, "--name", clusterName)
-	kindCmd.Stdout = os.Stdout
-	kindCmd.Stderr = os.Stderr
-	if err := kindCmd.Run(); err != nil {
-		return fmt.Errorf("failed to load image into Kind: %w", err)
+	// Load image into the cluster
+	fmt.Printf("Loading image into %s cluster...\n", getClusterProviderName())
+	if err := getClusterProvider().loadImage(getClusterName(), getProviderImage()); err != nil {
+		return fmt.Errorf("failed to load image into %s: %w", getClusterProviderName(), err)
 	}
 
 	return nil
@@ -143,46 +140,52 @@ func buildAndLoadKairosProviderImage() error {
 
 func applyKairosProviderConfigs() error {
 	fmt.Println("Installing Kairos CAPI Provider...")
-	kubeconfigPath := getKubeconfigPath()
-	kubectlContext := getKubectlContext()
+	config, err := getKubeConfig()
+	if err != nil {
+		return err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create dynamic client: %w", err)
+	}
 
 	// Apply namespace
-	if err := applyKustomize(kubeconfigPath, kubectlContext, "config/namespace"); err != nil {
+	if err := applyKustomize(dynamicClient, config, "config/namespace"); err != nil {
 		return fmt.Errorf("failed to apply namespace: %w", err)
 	}
 
 	// Apply CRDs
-	if err := applyKustomize(kubeconfigPath, kubectlContext, "config/crd"); err != nil {
+	if err := applyKustomize(dynamicClient, config, "config/crd"); err != nil {
 		return fmt.Errorf("failed to apply CRDs: %w", err)
 	}
 
 	// Apply RBAC
-	if err := applyKustomize(kubeconfigPath, kubectlContext, "config/rbac"); err != nil {
+	if err := applyKustomize(dynamicClient, config, "config/rbac"); err != nil {
 		return fmt.Errorf("failed to apply RBAC: %w", err)
 	}
 
 	// Apply cert-manager config
-	if err := applyKustomize(kubeconfigPath, kubectlContext, "config/certmanager"); err != nil {
+	if err := applyKustomize(dynamicClient, config, "config/certmanager"); err != nil {
 		return fmt.Errorf("failed to apply cert-manager config: %w", err)
 	}
 
 	// Wait for webhook certificate
-	if err := waitForWebhookCertificate(kubeconfigPath, kubectlContext); err != nil {
+	if err := waitForWebhookCertificate(); err != nil {
 		fmt.Printf("Warning: Webhook certificate may not be ready: %v\n", err)
 	}
 
 	// Apply webhook
-	if err := applyKustomize(kubeconfigPath, kubectlContext, "config/webhook"); err != nil {
+	if err := applyKustomize(dynamicClient, config, "config/webhook"); err != nil {
 		return fmt.Errorf("failed to apply webhook: %w", err)
 	}
 
 	// Wait for CA bundle injection
-	if err := waitForCABundleInjection(kubeconfigPath, kubectlContext); err != nil {
+	if err := waitForCABundleInjection(); err != nil {
 		fmt.Printf("Warning: CA bundle may not be injected: %v\n", err)
 	}
 
 	// Apply manager
-	if err := applyKustomize(kubeconfigPath, kubectlContext, "config/manager"); err != nil {
+	if err := applyKustomize(dynamicClient, config, "config/manager"); err != nil {
 		return fmt.Errorf("failed to apply manager: %w", err)
 	}
 
@@ -193,7 +196,7 @@ func applyKairosProviderConfigs() error {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	ctx, cancel := waitContext("kairos-provider")
 	defer cancel()
 
 	if err := waitForDeployment(ctx, clientset, "kairos-capi-system", "kairos-capi-controller-manager"); err != nil {
@@ -203,16 +206,45 @@ func applyKairosProviderConfigs() error {
 	return nil
 }
 
-func applyKustomize(kubeconfigPath, kubectlContext, path string) error {
-	kubectlCmd := exec.Command("kubectl", "apply", "-k", path, "--kubeconfig", kubeconfigPath, "--context", kubectlContext)
-	kubectlCmd.Stdout = os.Stdout
-	kubectlCmd.Stderr = os.Stderr
-	return kubectlCmd.Run()
+// applyKustomize renders the kustomization at path and applies it. The
+// manager Deployment gets the configured provider image.
+func applyKustomize(dynamicClient dynamic.Interface, config *rest.Config, path string) error {
+	objs, err := renderKustomization(path)
+	if err != nil {
+		return err
+	}
+	if path == "config/manager" {
+		if err := setManagerImage(objs, getProviderImage()); err != nil {
+			return err
+		}
+	}
+	return applyObjects(dynamicClient, config, objs)
 }
 
-func waitForWebhookCertificate(kubeconfigPath, kubectlContext string) error {
+// setManagerImage sets the image of the manager container of the controller
+// Deployment in objs.
+func setManagerImage(objs []*unstructured.Unstructured, image string) error {
+	for _, obj := range objs {
+		if obj.GetKind() != "Deployment" || obj.GetName() != "kairos-capi-controller-manager" {
+			continue
+		}
+		containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+		if err != nil {
+			return fmt.Errorf("failed to read manager containers: %w", err)
+		}
+		for _, c := range containers {
+			if container, ok := c.(map[string]interface{}); ok && container["name"] == "manager" {
+				container["image"] = image
+			}
+		}
+		return unstructured.SetNestedSlice(obj.Object, containers, "spec", "template", "spec", "containers")
+	}
+	return fmt.Errorf("manager Deployment not found in config/manager")
+}
+
+func waitForWebhookCertificate() error {
 	fmt.Println("Waiting for webhook certificate to be created...")
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	ctx, cancel := waitContext("webhook")
 	defer cancel()
 
 	config, err := getKubeConfig()
@@ -270,9 +302,9 @@ func waitForWebhookCertificate(kubeconfigPath, kubectlContext string) error {
 	})
 }
 
-func waitForCABundleInjection(kubeconfigPath, kubectlContext string) error {
+func waitForCABundleInjection() error {
 	fmt.Println("Waiting for cert-manager CA injector to inject CA bundle into webhook...")
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := waitContext("webhook")
 	defer cancel()
 
 	config, err := getKubeConfig()
@@ -323,16 +355,24 @@ func uninstallKairosProvider() error {
 	}
 
 	fmt.Println("Uninstalling Kairos CAPI Provider...")
-	kubeconfigPath := getKubeconfigPath()
-	kubectlContext := getKubectlContext()
+	config, err := getKubeConfig()
+	if err != nil {
+		return err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create dynamic client: %w", err)
+	}
 
 	// Delete in reverse order
-	configs := []string{"config/manager", "config/webhook", "config/certmanager", "config/rbac", "config/crd", "config/namespace"}
-	for _, config := range configs {
-		kubectlCmd := exec.Command("kubectl", "delete", "-k", config, "--kubeconfig", kubeconfigPath, "--context", kubectlContext, "--ignore-not-found=true")
-		kubectlCmd.Stdout = os.Stdout
-		kubectlCmd.Stderr = os.Stderr
-		kubectlCmd.Run() // Ignore errors
+	paths := []string{"config/manager", "config/webhook", "config/certmanager", "config/rbac", "config/crd", "config/namespace"}
+	for _, path := range paths {
+		objs, err := renderKustomization(path)
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+			continue
+		}
+		deleteObjects(dynamicClient, config, objs)
 	}
 
 	fmt.Println("Kairos CAPI Provider uninstalled ✓")
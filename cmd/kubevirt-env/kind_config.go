@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"sigs.k8s.io/yaml"
+)
+
+// kindTopology describes the nodes and networks of the cluster. The kind
+// section of the config file sets it for every provider.
+type kindTopology struct {
+	controlPlanes int
+	workers       int
+	podSubnet     string
+	serviceSubnet string
+	// extraMounts are "[NODE=]HOST:CONTAINER[:ro]" specs
+	extraMounts []string
+}
+
+func (t *kindTopology) addFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&t.controlPlanes, "control-planes", 1, "Number of control-plane nodes of the cluster")
+	fs.IntVar(&t.workers, "workers", 0, "Number of worker nodes of the cluster")
+	fs.StringVar(&t.podSubnet, "pod-subnet", "", "Pod subnet of the cluster (CIDR, provider default when empty)")
+	fs.StringVar(&t.serviceSubnet, "service-subnet", "", "Service subnet of the cluster (CIDR, provider default when empty)")
+	fs.StringArrayVar(&t.extraMounts, "extra-mount", nil,
+		"Extra mount of a host path into the cluster nodes, as [NODE=]HOST:CONTAINER[:ro]. "+
+			"NODE is control-plane, worker, or a single node such as worker-2; all nodes when omitted. Can be repeated")
+}
+
+// applyConfig sets the topology settings whose flag is not set from the kind
+// section of the config file.
+func (t *kindTopology) applyConfig(fs *pflag.FlagSet) {
+	if !fs.Changed("control-planes") && viper.IsSet("kind.control-planes") {
+		t.controlPlanes = viper.GetInt("kind.control-planes")
+	}
+	if !fs.Changed("workers") && viper.IsSet("kind.workers") {
+		t.workers = viper.GetInt("kind.workers")
+	}
+	if !fs.Changed("pod-subnet") && viper.IsSet("kind.pod-subnet") {
+		t.podSubnet = viper.GetString("kind.pod-subnet")
+	}
+	if !fs.Changed("service-subnet") && viper.IsSet("kind.service-subnet") {
+		t.serviceSubnet = viper.GetString("kind.service-subnet")
+	}
+	if !fs.Changed("extra-mount") && viper.IsSet("kind.extra-mounts") {
+		t.extraMounts = viper.GetStringSlice("kind.extra-mounts")
+	}
+}
+
+// kind cluster config, v1alpha4
+type kindClusterConfig struct {
+	Kind       string         `json:"kind"`
+	APIVersion string         `json:"apiVersion"`
+	Name       string         `json:"name"`
+	Networking kindNetworking `json:"networking"`
+	Nodes      []kindNode     `json:"nodes"`
+}
+
+type kindNetworking struct {
+	DisableDefaultCNI bool   `json:"disableDefaultCNI"`
+	PodSubnet         string `json:"podSubnet,omitempty"`
+	ServiceSubnet     string `json:"serviceSubnet,omitempty"`
+}
+
+type kindNode struct {
+	Role        string      `json:"role"`
+	ExtraMounts []kindMount `json:"extraMounts,omitempty"`
+}
+
+type kindMount struct {
+	HostPath      string `json:"hostPath"`
+	ContainerPath string `json:"containerPath"`
+	ReadOnly      bool   `json:"readOnly,omitempty"`
+}
+
+// kindExtraMount is a parsed --extra-mount.
+type kindExtraMount struct {
+	// role is empty for all nodes; index is 0 for all nodes of role
+	role  string
+	index int
+	mount kindMount
+}
+
+func (t kindTopology) validate() error {
+	if t.controlPlanes < 1 {
+		return fmt.Errorf("--control-planes must be at least 1")
+	}
+	if t.workers < 0 {
+		return fmt.Errorf("--workers must not be negative")
+	}
+	for flag, subnet := range map[string]string{"--pod-subnet": t.podSubnet, "--service-subnet": t.serviceSubnet} {
+		if subnet == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(subnet); err != nil {
+			return fmt.Errorf("invalid %s %q: must be a CIDR", flag, subnet)
+		}
+	}
+	mounts, err := t.parseExtraMounts()
+	if err != nil {
+		return err
+	}
+	for _, m := range mounts {
+		if (m.role == "control-plane" && m.index > t.controlPlanes) || (m.role == "worker" && m.index > t.workers) {
+			return fmt.Errorf("--extra-mount for %s-%d, but the cluster has no such node", m.role, m.index)
+		}
+	}
+	return nil
+}
+
+func (t kindTopology) parseExtraMounts() ([]kindExtraMount, error) {
+	var mounts []kindExtraMount
+	for _, spec := range t.extraMounts {
+		var m kindExtraMount
+		paths := spec
+		if node, rest, ok := strings.Cut(spec, "="); ok {
+			paths = rest
+			role, index, err := parseKindNode(node)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --extra-mount %q: %w", spec, err)
+			}
+			m.role, m.index = role, index
+		}
+
+		parts := strings.Split(paths, ":")
+		switch {
+		case len(parts) == 3 && parts[2] == "ro":
+			m.mount.ReadOnly = true
+		case len(parts) == 2:
+		default:
+			return nil, fmt.Errorf("invalid --extra-mount %q: must be [NODE=]HOST:CONTAINER[:ro]", spec)
+		}
+		if parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid --extra-mount %q: host and container paths are required", spec)
+		}
+		hostPath, err := filepath.Abs(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid host path in --extra-mount %q: %w", spec, err)
+		}
+		m.mount.HostPath = hostPath
+		m.mount.ContainerPath = parts[1]
+		mounts = append(mounts, m)
+	}
+	return mounts, nil
+}
+
+// parseKindNode parses a node selector: a role, or a role and 1-based index
+// such as worker-2.
+func parseKindNode(node string) (string, int, error) {
+	for _, role := range []string{"control-plane", "worker"} {
+		if node == role {
+			return role, 0, nil
+		}
+		if index, ok := strings.CutPrefix(node, role+"-"); ok {
+			if n, err := strconv.Atoi(index); err == nil && n >= 1 {
+				return role, n, nil
+			}
+		}
+	}
+	return "", 0, fmt.Errorf("invalid node %q: must be control-plane, worker, control-plane-N or worker-N", node)
+}
+
+// kindConfig renders the kind config of clusterName. Every node mounts the
+// Docker config at dockerConfigPath, so image pulls are authenticated.
+func (t kindTopology) kindConfig(clusterName, dockerConfigPath string) ([]byte, error) {
+	extraMounts, err := t.parseExtraMounts()
+	if err != nil {
+		return nil, err
+	}
+
+	config := kindClusterConfig{
+		Kind:       "Cluster",
+		APIVersion: "kind.x-k8s.io/v1alpha4",
+		Name:       clusterName,
+		Networking: kindNetworking{
+			// Calico is installed instead
+			DisableDefaultCNI: true,
+			PodSubnet:         t.podSubnet,
+			ServiceSubnet:     t.serviceSubnet,
+		},
+	}
+	addNodes := func(role string, count int) {
+		for i := 1; i <= count; i++ {
+			node := kindNode{
+				Role: role,
+				ExtraMounts: []kindMount{{
+					HostPath:      dockerConfigPath,
+					ContainerPath: "/var/lib/kubelet/config.json",
+				}},
+			}
+			for _, m := range extraMounts {
+				if (m.role == "" || m.role == role) && (m.index == 0 || m.index == i) {
+					node.ExtraMounts = append(node.ExtraMounts, m.mount)
+				}
+			}
+			config.Nodes = append(config.Nodes, node)
+		}
+	}
+	addNodes("control-plane", t.controlPlanes)
+	addNodes("worker", t.workers)
+
+	return yaml.Marshal(config)
+}
@@ -7,17 +7,22 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	yamlserializer "k8s.io/apimachinery/pkg/runtime/serializer/yaml"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -73,6 +78,42 @@ func getKubeConfig() (*rest.Config, error) {
 	return config, nil
 }
 
+// restMappers holds one discovery-backed REST mapper per API server so that
+// applying many manifests only fetches discovery once. Mappers are reset when
+// a CRD is applied or a kind is not found.
+var (
+	restMappersMu sync.Mutex
+	restMappers   = map[string]*restmapper.DeferredDiscoveryRESTMapper{}
+)
+
+func getRESTMapper(config *rest.Config) (*restmapper.DeferredDiscoveryRESTMapper, error) {
+	restMappersMu.Lock()
+	defer restMappersMu.Unlock()
+
+	if mapper, ok := restMappers[config.Host]; ok {
+		return mapper, nil
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+	restMappers[config.Host] = mapper
+	return mapper, nil
+}
+
+// restMapping resolves gvk, refreshing discovery once if the kind is unknown
+// (e.g. its CRD was installed by another command or is still being established).
+func restMapping(mapper *restmapper.DeferredDiscoveryRESTMapper, gvk *schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		mapper.Reset()
+		mapping, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	return mapping, err
+}
+
 func applyManifestFromURL(dynamicClient dynamic.Interface, config *rest.Config, url string) error {
 	// Download manifest
 	resp, err := http.Get(url)
@@ -96,19 +137,12 @@ func applyManifestFromURL(dynamicClient dynamic.Interface, config *rest.Config,
 }
 
 func applyManifestContent(dynamicClient dynamic.Interface, config *rest.Config, yamlContent []byte) error {
-	// Create discovery client for REST mapper
-	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	// Get the shared REST mapper
+	mapper, err := getRESTMapper(config)
 	if err != nil {
-		return fmt.Errorf("failed to create discovery client: %w", err)
+		return err
 	}
 
-	// Get REST mapper
-	gr, err := restmapper.GetAPIGroupResources(discoveryClient)
-	if err != nil {
-		return fmt.Errorf("failed to get API group resources: %w", err)
-	}
-	mapper := restmapper.NewDiscoveryRESTMapper(gr)
-
 	// Parse YAML and apply each resource
 	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(string(yamlContent)), 4096)
 	dec := yamlserializer.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
@@ -134,7 +168,7 @@ func applyManifestContent(dynamicClient dynamic.Interface, config *rest.Config,
 		}
 
 		// Get REST mapping
-		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		mapping, err := restMapping(mapper, gvk)
 		if err != nil {
 			fmt.Printf("Warning: failed to get REST mapping for %s: %v\n", gvk, err)
 			continue
@@ -150,12 +184,12 @@ func applyManifestContent(dynamicClient dynamic.Interface, config *rest.Config,
 
 		// Convert to apply configuration
 		obj.SetManagedFields(nil)
-		_, err = dr.Apply(context.Background(), obj.GetName(), obj, metav1.ApplyOptions{
+		_, err = dr.Apply(interruptCtx, obj.GetName(), obj, metav1.ApplyOptions{
 			FieldManager: "kubevirt-env",
 		})
 		if err != nil {
 			// Try create if apply fails (for resources that don't support apply)
-			_, createErr := dr.Create(context.Background(), obj, metav1.CreateOptions{})
+			_, createErr := dr.Create(interruptCtx, obj, metav1.CreateOptions{})
 			if createErr != nil {
 				// Ignore already exists errors
 				if !strings.Contains(createErr.Error(), "already exists") {
@@ -163,6 +197,11 @@ func applyManifestContent(dynamicClient dynamic.Interface, config *rest.Config,
 				}
 			}
 		}
+
+		// A new CRD adds resources the cached discovery doesn't know about yet
+		if gvk.Group == apiextensionsv1.GroupName && gvk.Kind == "CustomResourceDefinition" {
+			mapper.Reset()
+		}
 	}
 
 	return nil
@@ -186,19 +225,12 @@ func deleteResourcesFromManifestFile(dynamicClient dynamic.Interface, config *re
 		return fmt.Errorf("failed to read manifest file: %w", err)
 	}
 
-	// Create discovery client for REST mapper
-	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	// Get the shared REST mapper
+	mapper, err := getRESTMapper(config)
 	if err != nil {
-		return fmt.Errorf("failed to create discovery client: %w", err)
+		return err
 	}
 
-	// Get REST mapper
-	gr, err := restmapper.GetAPIGroupResources(discoveryClient)
-	if err != nil {
-		return fmt.Errorf("failed to get API group resources: %w", err)
-	}
-	mapper := restmapper.NewDiscoveryRESTMapper(gr)
-
 	// Parse YAML and delete each resource
 	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(string(yamlContent)), 4096)
 	dec := yamlserializer.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
@@ -223,7 +255,7 @@ func deleteResourcesFromManifestFile(dynamicClient dynamic.Interface, config *re
 		}
 
 		// Get REST mapping
-		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		mapping, err := restMapping(mapper, gvk)
 		if err != nil {
 			continue
 		}
@@ -236,7 +268,7 @@ func deleteResourcesFromManifestFile(dynamicClient dynamic.Interface, config *re
 			dr = dynamicClient.Resource(mapping.Resource)
 		}
 
-		err = dr.Delete(context.Background(), obj.GetName(), metav1.DeleteOptions{})
+		err = dr.Delete(interruptCtx, obj.GetName(), metav1.DeleteOptions{})
 		if err != nil {
 			// Ignore not found errors
 			if !strings.Contains(err.Error(), "not found") {
@@ -260,18 +292,11 @@ func deleteResourcesFromManifestURL(dynamicClient dynamic.Interface, config *res
 		return fmt.Errorf("failed to download manifest: HTTP %d", resp.StatusCode)
 	}
 
-	// Create discovery client for REST mapper
-	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
-	if err != nil {
-		return fmt.Errorf("failed to create discovery client: %w", err)
-	}
-
-	// Get REST mapper
-	gr, err := restmapper.GetAPIGroupResources(discoveryClient)
+	// Get the shared REST mapper
+	mapper, err := getRESTMapper(config)
 	if err != nil {
-		return fmt.Errorf("failed to get API group resources: %w", err)
+		return err
 	}
-	mapper := restmapper.NewDiscoveryRESTMapper(gr)
 
 	// Parse YAML and delete each resource
 	decoder := yaml.NewYAMLOrJSONDecoder(resp.Body, 4096)
@@ -297,7 +322,7 @@ func deleteResourcesFromManifestURL(dynamicClient dynamic.Interface, config *res
 		}
 
 		// Get REST mapping
-		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		mapping, err := restMapping(mapper, gvk)
 		if err != nil {
 			continue
 		}
@@ -310,7 +335,7 @@ func deleteResourcesFromManifestURL(dynamicClient dynamic.Interface, config *res
 			dr = dynamicClient.Resource(mapping.Resource)
 		}
 
-		err = dr.Delete(context.Background(), obj.GetName(), metav1.DeleteOptions{})
+		err = dr.Delete(interruptCtx, obj.GetName(), metav1.DeleteOptions{})
 		if err != nil {
 			// Ignore not found errors
 			if !strings.Contains(err.Error(), "not found") {
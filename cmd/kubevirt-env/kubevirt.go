@@ -17,9 +17,7 @@ import (
 )
 
 const (
-	kubevirtVersion     = "v1.3.0"
-	kubevirtOperatorURL = "https://github.com/kubevirt/kubevirt/releases/download/%s/kubevirt-operator.yaml"
-	kubevirtCRURL       = "https://github.com/kubevirt/kubevirt/releases/download/%s/kubevirt-cr.yaml"
+	kubevirtRepo = "kubevirt/kubevirt"
 )
 
 func newInstallKubevirtCmd() *cobra.Command {
@@ -74,7 +72,7 @@ func isKubeVirtInstalled() bool {
 		return false
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(interruptCtx, 5*time.Second)
 	defer cancel()
 
 	kubevirt, err := getKubeVirtCR(ctx, dynamicClient)
@@ -127,23 +125,24 @@ func installKubevirt() error {
 		return fmt.Errorf("failed to create dynamic client: %w", err)
 	}
 
+	kubevirtVersion := getKubevirtVersion()
 	fmt.Printf("Installing KubeVirt %s...\n", kubevirtVersion)
 
 	// Apply KubeVirt operator
-	operatorURL := fmt.Sprintf(kubevirtOperatorURL, kubevirtVersion)
+	operatorURL := githubReleaseAssetURL(kubevirtRepo, kubevirtVersion, "kubevirt-operator.yaml")
 	if err := applyManifestFromURL(dynamicClient, config, operatorURL); err != nil {
 		return fmt.Errorf("failed to apply KubeVirt operator: %w", err)
 	}
 
 	// Apply KubeVirt CR
-	crURL := fmt.Sprintf(kubevirtCRURL, kubevirtVersion)
+	crURL := githubReleaseAssetURL(kubevirtRepo, kubevirtVersion, "kubevirt-cr.yaml")
 	if err := applyManifestFromURL(dynamicClient, config, crURL); err != nil {
 		return fmt.Errorf("failed to apply KubeVirt CR: %w", err)
 	}
 
 	// For local environments without /dev/kvm, enable emulation
 	if shouldUseEmulation() {
-		patchCtx, patchCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		patchCtx, patchCancel := context.WithTimeout(interruptCtx, 30*time.Second)
 		defer patchCancel()
 		if err := ensureKubeVirtEmulation(patchCtx, dynamicClient); err != nil {
 			fmt.Printf("Warning: failed to enable KubeVirt emulation: %v\n", err)
@@ -153,7 +152,7 @@ func installKubevirt() error {
 	}
 
 	fmt.Println("Waiting for KubeVirt to be ready...")
-	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	ctx, cancel := waitContext("kubevirt")
 	defer cancel()
 
 	// Wait for virt-operator deployment
@@ -198,13 +197,14 @@ func uninstallKubevirt() error {
 	fmt.Println("Uninstalling KubeVirt...")
 
 	// Delete KubeVirt CR first
-	crURL := fmt.Sprintf(kubevirtCRURL, kubevirtVersion)
+	kubevirtVersion := getKubevirtVersion()
+	crURL := githubReleaseAssetURL(kubevirtRepo, kubevirtVersion, "kubevirt-cr.yaml")
 	if err := deleteResourcesFromManifestURL(dynamicClient, config, crURL); err != nil {
 		fmt.Printf("Warning: failed to delete KubeVirt CR: %v\n", err)
 	}
 
 	// Delete KubeVirt operator
-	operatorURL := fmt.Sprintf(kubevirtOperatorURL, kubevirtVersion)
+	operatorURL := githubReleaseAssetURL(kubevirtRepo, kubevirtVersion, "kubevirt-operator.yaml")
 	if err := deleteResourcesFromManifestURL(dynamicClient, config, operatorURL); err != nil {
 		return fmt.Errorf("failed to delete KubeVirt operator: %w", err)
 	}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// renderKustomization builds the kustomization in dir, like `kubectl
+// kustomize`.
+func renderKustomization(dir string) ([]*unstructured.Unstructured, error) {
+	resMap, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(filesys.MakeFsOnDisk(), dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kustomization %s: %w", dir, err)
+	}
+
+	var objs []*unstructured.Unstructured
+	for _, res := range resMap.Resources() {
+		// Decoding the JSON gives the int64 numbers unstructured expects
+		data, err := res.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from kustomization %s: %w", res.CurId(), dir, err)
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(data); err != nil {
+			return nil, fmt.Errorf("failed to decode %s from kustomization %s: %w", res.CurId(), dir, err)
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// applyObjects server-side applies objs in order. Unlike
+// applyManifestContent it fails when a resource cannot be applied, and
+// reports every resource that failed.
+func applyObjects(dynamicClient dynamic.Interface, config *rest.Config, objs []*unstructured.Unstructured) error {
+	mapper, err := getRESTMapper(config)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		mapping, err := restMapping(mapper, &gvk)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", gvk.Kind, obj.GetName(), err))
+			continue
+		}
+
+		var dr dynamic.ResourceInterface
+		if mapping.Scope.Name() == "namespace" && obj.GetNamespace() != "" {
+			dr = dynamicClient.Resource(mapping.Resource).Namespace(obj.GetNamespace())
+		} else {
+			dr = dynamicClient.Resource(mapping.Resource)
+		}
+
+		// Force takes over fields set by an earlier `kubectl apply -k`
+		_, err = dr.Apply(interruptCtx, obj.GetName(), obj, metav1.ApplyOptions{
+			FieldManager: "kubevirt-env",
+			Force:        true,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", gvk.Kind, obj.GetName(), err))
+			continue
+		}
+		fmt.Printf("%s/%s applied\n", gvk.Kind, obj.GetName())
+
+		// A new CRD adds resources the cached discovery doesn't know about yet
+		if gvk.Group == apiextensionsv1.GroupName && gvk.Kind == "CustomResourceDefinition" {
+			mapper.Reset()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deleteObjects deletes objs, ignoring those that do not exist.
+func deleteObjects(dynamicClient dynamic.Interface, config *rest.Config, objs []*unstructured.Unstructured) {
+	mapper, err := getRESTMapper(config)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		return
+	}
+
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		mapping, err := restMapping(mapper, &gvk)
+		if err != nil {
+			// The CRD is already gone
+			continue
+		}
+
+		var dr dynamic.ResourceInterface
+		if mapping.Scope.Name() == "namespace" && obj.GetNamespace() != "" {
+			dr = dynamicClient.Resource(mapping.Resource).Namespace(obj.GetNamespace())
+		} else {
+			dr = dynamicClient.Resource(mapping.Resource)
+		}
+
+		err = dr.Delete(interruptCtx, obj.GetName(), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			fmt.Printf("Warning: failed to delete %s/%s: %v\n", gvk.Kind, obj.GetName(), err)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/spf13/cobra"
+)
+
+// logComponent is a component whose controller logs `kubevirt-env logs`
+// shows.
+type logComponent struct {
+	name        string
+	namespace   string
+	deployments []string
+}
+
+var logComponents = []logComponent{
+	{name: "kairos-provider", namespace: "kairos-capi-system", deployments: []string{"kairos-capi-controller-manager"}},
+	{name: "capi", namespace: "capi-system", deployments: []string{"capi-controller-manager"}},
+	{name: "capk", namespace: "capk-system", deployments: []string{"capk-controller-manager"}},
+	{name: "osbuilder", namespace: "default", deployments: []string{"osbuilder"}},
+	{name: "cdi", namespace: "cdi", deployments: []string{"cdi-operator", "cdi-deployment", "cdi-apiserver", "cdi-uploadproxy"}},
+}
+
+// logsOptions select the logs to show.
+type logsOptions struct {
+	components []string
+	follow     bool
+	since      time.Duration
+	tail       int64
+	grep       string
+}
+
+// logSource is a container whose logs are shown.
+type logSource struct {
+	component string
+	namespace string
+	pod       string
+	container string
+}
+
+func (s logSource) prefix() string {
+	return fmt.Sprintf("[%s %s/%s] ", s.component, s.pod, s.container)
+}
+
+// logLine is a line of a container log with the timestamp the kubelet added.
+type logLine struct {
+	time time.Time
+	text string
+}
+
+func newLogsCmd() *cobra.Command {
+	opts := logsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show the logs of the provider and CAPI controllers",
+		Long: `Show the logs of the controller pods of the environment, merged in time order and prefixed with their component, pod and container.
+
+Components: ` + strings.Join(logComponentNames(), ", "),
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			for _, name := range opts.components {
+				if _, ok := findLogComponent(name); !ok {
+					return fmt.Errorf("unknown component %q: must be one of %s", name, strings.Join(logComponentNames(), ", "))
+				}
+			}
+			if _, err := regexp.Compile(opts.grep); err != nil {
+				return fmt.Errorf("invalid --grep: %w", err)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return showLogs(opts)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&opts.components, "component", "c", nil, "Components to show the logs of, repeated or comma separated (default all): "+strings.Join(logComponentNames(), ", "))
+	cmd.Flags().BoolVarP(&opts.follow, "follow", "f", false, "Stream the logs as they are written")
+	cmd.Flags().DurationVar(&opts.since, "since", 0, "Only show lines newer than a duration such as 10m (default all)")
+	cmd.Flags().Int64Var(&opts.tail, "tail", -1, "Lines of each container to show, -1 for all (10 with --follow unless --since is set)")
+	cmd.Flags().StringVar(&opts.grep, "grep", "", "Only show lines matching a regular expression")
+	return cmd
+}
+
+func logComponentNames() []string {
+	names := make([]string, len(logComponents))
+	for i, c := range logComponents {
+		names[i] = c.name
+	}
+	return names
+}
+
+func findLogComponent(name string) (logComponent, bool) {
+	for _, c := range logComponents {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return logComponent{}, false
+}
+
+func showLogs(opts logsOptions) error {
+	clientset, err := getKubeClient()
+	if err != nil {
+		return err
+	}
+
+	components := logComponents
+	if len(opts.components) > 0 {
+		components = nil
+		for _, name := range opts.components {
+			c, _ := findLogComponent(name)
+			components = append(components, c)
+		}
+	}
+
+	ctx := interruptCtx
+
+	var sources []logSource
+	for _, c := range components {
+		found, err := componentLogSources(ctx, clientset, c)
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+			continue
+		}
+		sources = append(sources, found...)
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("no controller pods found, is the environment set up?")
+	}
+
+	filter := regexp.MustCompile(opts.grep)
+	if opts.follow {
+		return followLogs(ctx, clientset, sources, opts, filter)
+	}
+	return mergeLogs(ctx, clientset, sources, opts, filter)
+}
+
+// componentLogSources returns the containers of the pods of the deployments
+// of a component.
+func componentLogSources(ctx context.Context, clientset kubernetes.Interface, c logComponent) ([]logSource, error) {
+	var sources []logSource
+	for _, name := range c.deployments {
+		deployment, err := clientset.AppsV1().Deployments(c.namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("%s is not installed (deployment %s/%s not found)", c.name, c.namespace, name)
+			}
+			return nil, fmt.Errorf("failed to get deployment %s/%s: %w", c.namespace, name, err)
+		}
+		selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector of deployment %s/%s: %w", c.namespace, name, err)
+		}
+		pods, err := clientset.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods of %s/%s: %w", c.namespace, name, err)
+		}
+		for _, pod := range pods.Items {
+			for _, container := range pod.Spec.Containers {
+				sources = append(sources, logSource{component: c.name, namespace: c.namespace, pod: pod.Name, container: container.Name})
+			}
+		}
+	}
+	return sources, nil
+}
+
+func podLogOptions(source logSource, opts logsOptions) *corev1.PodLogOptions {
+	logOpts := &corev1.PodLogOptions{
+		Container:  source.container,
+		Follow:     opts.follow,
+		Timestamps: true,
+	}
+	if opts.since > 0 {
+		seconds := int64(opts.since.Seconds())
+		logOpts.SinceSeconds = &seconds
+	}
+	tail := opts.tail
+	if tail < 0 && opts.follow && opts.since == 0 {
+		tail = 10
+	}
+	if tail >= 0 {
+		logOpts.TailLines = &tail
+	}
+	return logOpts
+}
+
+// parseLogLine splits the timestamp the kubelet adds off a line.
+func parseLogLine(line string) logLine {
+	stamp, text, ok := strings.Cut(line, " ")
+	if ok {
+		if t, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+			return logLine{time: t, text: text}
+		}
+	}
+	return logLine{text: line}
+}
+
+// mergeLogs prints the logs of all sources merged in time order.
+func mergeLogs(ctx context.Context, clientset kubernetes.Interface, sources []logSource, opts logsOptions, filter *regexp.Regexp) error {
+	type sourceLine struct {
+		logLine
+		source logSource
+	}
+
+	var lines []sourceLine
+	for _, source := range sources {
+		data, err := clientset.CoreV1().Pods(source.namespace).GetLogs(source.pod, podLogOptions(source, opts)).DoRaw(ctx)
+		if err != nil {
+			fmt.Printf("Warning: failed to get logs of %s/%s: %v\n", source.pod, source.container, err)
+			continue
+		}
+		scanner := bufio.NewScanner(strings.NewReader(string(data)))
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := parseLogLine(scanner.Text())
+			if filter.MatchString(line.text) {
+				lines = append(lines, sourceLine{logLine: line, source: source})
+			}
+		}
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].time.Before(lines[j].time)
+	})
+	for _, line := range lines {
+		fmt.Println(line.source.prefix() + line.text)
+	}
+	return nil
+}
+
+// followLogs streams the logs of all sources until interrupted.
+func followLogs(ctx context.Context, clientset kubernetes.Interface, sources []logSource, opts logsOptions, filter *regexp.Regexp) error {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, source := range sources {
+		wg.Add(1)
+		go func(source logSource) {
+			defer wg.Done()
+			stream, err := clientset.CoreV1().Pods(source.namespace).GetLogs(source.pod, podLogOptions(source, opts)).Stream(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					fmt.Printf("Warning: failed to stream logs of %s/%s: %v\n", source.pod, source.container, err)
+				}
+				return
+			}
+			defer stream.Close()
+
+			scanner := bufio.NewScanner(stream)
+			scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+			for scanner.Scan() {
+				line := parseLogLine(scanner.Text())
+				if !filter.MatchString(line.text) {
+					continue
+				}
+				mu.Lock()
+				fmt.Println(source.prefix() + line.text)
+				mu.Unlock()
+			}
+		}(source)
+	}
+	wg.Wait()
+	return nil
+}
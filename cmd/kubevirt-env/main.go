@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"github.com/kairos-io/kairos-capi/internal/version"
 )
 
 const (
@@ -15,17 +18,41 @@ const (
 
 func main() {
 	rootCmd := &cobra.Command{
-		Use:   "kubevirt-env",
-		Short: "KubeVirt Local Testing Environment CLI",
-		Long:  "A CLI tool for managing local KubeVirt testing environments",
+		Use:     "kubevirt-env",
+		Short:   "KubeVirt Local Testing Environment CLI",
+		Long:    "A CLI tool for managing local KubeVirt testing environments",
+		Version: version.Get().String(),
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			return initializeConfig()
+			if err := initializeConfig(); err != nil {
+				return err
+			}
+			if err := setupOutput(); err != nil {
+				return err
+			}
+			if err := validateTimeouts(); err != nil {
+				return err
+			}
+			if err := validateClusterProvider(); err != nil {
+				return err
+			}
+			if err := validateComponentVersions(); err != nil {
+				return err
+			}
+			return validateKairosImageConfig()
 		},
 	}
 
+	addConfigFlag(rootCmd.PersistentFlags())
 	rootCmd.PersistentFlags().String("cluster-name", defaultClusterName, "Cluster name (can also be set via CLUSTER_NAME env var)")
 	viper.BindPFlag("cluster-name", rootCmd.PersistentFlags().Lookup("cluster-name"))
 	viper.BindEnv("cluster-name", "CLUSTER_NAME")
+	addClusterProviderFlag(rootCmd.PersistentFlags())
+	rootCmd.PersistentFlags().Bool("reuse-image", false, "Skip the Kairos image build when the image cache has an image built from the same OSArtifact spec, and upload it from the cache (can also be set via KAIROS_REUSE_IMAGE env var)")
+	viper.BindPFlag("kairos-image.reuse", rootCmd.PersistentFlags().Lookup("reuse-image"))
+	viper.BindEnv("kairos-image.reuse", "KAIROS_REUSE_IMAGE")
+	addComponentVersionFlags(rootCmd.PersistentFlags())
+	addOutputFlags(rootCmd.PersistentFlags())
+	addTimeoutFlags(rootCmd.PersistentFlags())
 
 	rootCmd.AddCommand(newCreateTestClusterCmd())
 	rootCmd.AddCommand(newSetupCmd())
@@ -36,10 +63,27 @@ func main() {
 	rootCmd.AddCommand(newBuildKairosImageCmd())
 	rootCmd.AddCommand(newUploadKairosImageCmd())
 	rootCmd.AddCommand(newTestControlPlaneCmd())
+	rootCmd.AddCommand(newTestWorkersCmd())
+	rootCmd.AddCommand(newGetKubeconfigCmd())
+	rootCmd.AddCommand(newLogsCmd())
+	rootCmd.AddCommand(newDoctorCmd())
+	rootCmd.AddCommand(newGenerateClusterCmd())
 	rootCmd.AddCommand(newTestClusterStatusCmd())
 	rootCmd.AddCommand(newDeleteTestClusterCmd())
+	rootCmd.AddCommand(newStatusCmd())
+	rootCmd.AddCommand(newDepsCmd())
+	rootCmd.AddCommand(newVersionCmd())
 
-	if err := rootCmd.Execute(); err != nil {
+	setupInterrupt()
+	start := time.Now()
+	cmd, err := rootCmd.ExecuteC()
+	result := event{Type: eventResult, Command: cmd.CommandPath(), Status: eventSuccess, Duration: seconds(time.Since(start))}
+	if err != nil {
+		result.Status = eventFailure
+		result.Error = err.Error()
+	}
+	emitEvent(result)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -48,7 +92,8 @@ func main() {
 func initializeConfig() error {
 	viper.SetEnvPrefix("")
 	viper.AutomaticEnv()
-	return nil
+	setConfigDefaults()
+	return readConfigFile()
 }
 
 func getClusterName() string {
@@ -65,6 +110,5 @@ func getKubeconfigPath() string {
 }
 
 func getKubectlContext() string {
-	clusterName := getClusterName()
-	return fmt.Sprintf("kind-%s", clusterName)
+	return getClusterProvider().kubectlContext(getClusterName())
 }
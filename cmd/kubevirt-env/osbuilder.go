@@ -3,8 +3,6 @@ package main
 import (
 	"context"
 	"fmt"
-	"os"
-	"os/exec"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -18,18 +16,11 @@ import (
 	"github.com/spf13/cobra"
 )
 
-const (
-	kairosHelmRepo = "https://kairos-io.github.io/helm-charts"
-)
-
 func newInstallOsbuilderCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "osbuilder",
 		Short: "Install osbuilder",
 		Long:  "Install osbuilder using Helm charts (includes CRDs)",
-		PreRunE: func(cmd *cobra.Command, args []string) error {
-			return validateHelmInstalled()
-		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return installOsbuilder()
 		},
@@ -43,9 +34,6 @@ func newUninstallOsbuilderCmd() *cobra.Command {
 		Use:   "osbuilder",
 		Short: "Uninstall osbuilder",
 		Long:  "Uninstall osbuilder from the cluster",
-		PreRunE: func(cmd *cobra.Command, args []string) error {
-			return validateHelmInstalled()
-		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return uninstallOsbuilder()
 		},
@@ -69,20 +57,13 @@ func newReinstallOsbuilderCmd() *cobra.Command {
 	return cmd
 }
 
-func validateHelmInstalled() error {
-	if _, err := exec.LookPath("helm"); err != nil {
-		return fmt.Errorf("required command 'helm' not found in PATH. Please install it first")
-	}
-	return nil
-}
-
 func isOsbuilderInstalled() bool {
 	clientset, err := getKubeClient()
 	if err != nil {
 		return false
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(interruptCtx, 5*time.Second)
 	defer cancel()
 
 	// Check if osbuilder deployment exists and is available
@@ -135,31 +116,8 @@ func installOsbuilder() error {
 func installOsbuilderCRDs() error {
 	fmt.Println("Installing osbuilder CRDs from Helm chart...")
 
-	// Add kairos helm repo
-	repoAddCmd := exec.Command("helm", "repo", "add", "kairos", kairosHelmRepo)
-	repoAddCmd.Stderr = os.Stderr
-	if err := repoAddCmd.Run(); err != nil {
-		// Try with --force-update if it already exists
-		repoAddCmd = exec.Command("helm", "repo", "add", "kairos", kairosHelmRepo, "--force-update")
-		repoAddCmd.Stderr = os.Stderr
-		if err := repoAddCmd.Run(); err != nil {
-			return fmt.Errorf("failed to add kairos helm repo: %w", err)
-		}
-	}
-
-	// Update helm repo
-	repoUpdateCmd := exec.Command("helm", "repo", "update", "kairos")
-	repoUpdateCmd.Stdout = os.Stdout
-	repoUpdateCmd.Stderr = os.Stderr
-	if err := repoUpdateCmd.Run(); err != nil {
-		return fmt.Errorf("failed to update helm repo: %w", err)
-	}
-
 	// Install kairos-crds chart
-	installCmd := exec.Command("helm", "upgrade", "--install", "kairos-crds", "kairos/kairos-crds", "--namespace", "default", "--create-namespace", "--wait", "--timeout=60s")
-	installCmd.Stdout = os.Stdout
-	installCmd.Stderr = os.Stderr
-	if err := installCmd.Run(); err != nil {
+	if err := upgradeOrInstallChart(helmChart{name: "kairos-crds", wait: true, timeout: getTimeout("crd")}); err != nil {
 		return fmt.Errorf("failed to install/upgrade osbuilder CRDs: %w", err)
 	}
 
@@ -170,7 +128,7 @@ func installOsbuilderCRDs() error {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := waitContext("crd")
 	defer cancel()
 
 	if err := waitForCRDEstablished(ctx, clientset, "osartifacts.build.kairos.io"); err != nil {
@@ -184,24 +142,8 @@ func installOsbuilderCRDs() error {
 func installOsbuilderDeployment() error {
 	fmt.Println("Installing osbuilder using Helm charts...")
 
-	// Add kairos helm repo (in case it wasn't added earlier)
-	repoAddCmd := exec.Command("helm", "repo", "add", "kairos", kairosHelmRepo)
-	repoAddCmd.Stderr = os.Stderr
-	repoAddCmd.Run() // Ignore error if repo already exists
-
-	// Update helm repo
-	repoUpdateCmd := exec.Command("helm", "repo", "update", "kairos")
-	repoUpdateCmd.Stdout = os.Stdout
-	repoUpdateCmd.Stderr = os.Stderr
-	if err := repoUpdateCmd.Run(); err != nil {
-		return fmt.Errorf("failed to update helm repo: %w", err)
-	}
-
 	// Install osbuilder chart
-	installCmd := exec.Command("helm", "upgrade", "--install", "osbuilder", "kairos/osbuilder", "-n", "default", "--create-namespace")
-	installCmd.Stdout = os.Stdout
-	installCmd.Stderr = os.Stderr
-	if err := installCmd.Run(); err != nil {
+	if err := upgradeOrInstallChart(helmChart{name: "osbuilder"}); err != nil {
 		return fmt.Errorf("failed to install/upgrade osbuilder: %w", err)
 	}
 
@@ -212,7 +154,7 @@ func installOsbuilderDeployment() error {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	ctx, cancel := waitContext("osbuilder")
 	defer cancel()
 
 	if err := waitForDeployment(ctx, clientset, "default", "osbuilder"); err != nil {
@@ -233,18 +175,12 @@ func uninstallOsbuilder() error {
 	fmt.Println("Uninstalling osbuilder...")
 
 	// Uninstall osbuilder chart
-	uninstallCmd := exec.Command("helm", "uninstall", "osbuilder", "-n", "default")
-	uninstallCmd.Stdout = os.Stdout
-	uninstallCmd.Stderr = os.Stderr
-	if err := uninstallCmd.Run(); err != nil {
+	if err := uninstallRelease("osbuilder"); err != nil {
 		fmt.Printf("Warning: failed to uninstall osbuilder chart: %v\n", err)
 	}
 
 	// Uninstall kairos-crds chart
-	uninstallCRDsCmd := exec.Command("helm", "uninstall", "kairos-crds", "-n", "default")
-	uninstallCRDsCmd.Stdout = os.Stdout
-	uninstallCRDsCmd.Stderr = os.Stderr
-	if err := uninstallCRDsCmd.Run(); err != nil {
+	if err := uninstallRelease("kairos-crds"); err != nil {
 		fmt.Printf("Warning: failed to uninstall kairos-crds chart: %v\n", err)
 	}
 
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// stdout is the standard output of the process. In json and quiet mode
+// os.Stdout is redirected, so the progress messages the commands print don't
+// mix with the events and results written here.
+var stdout io.Writer = os.Stdout
+
+var eventMu sync.Mutex
+
+// event is a JSON line written in json output mode.
+type event struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Command string    `json:"command,omitempty"`
+	Step    string    `json:"step,omitempty"`
+	Status  string    `json:"status,omitempty"`
+	// Duration is in seconds
+	Duration float64        `json:"duration,omitempty"`
+	Error    string         `json:"error,omitempty"`
+	Steps    []summaryEntry `json:"steps,omitempty"`
+}
+
+// summaryEntry is a step in the summary event of setup.
+type summaryEntry struct {
+	Step     string  `json:"step"`
+	Status   string  `json:"status"`
+	Duration float64 `json:"duration,omitempty"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// Event types and step statuses
+const (
+	eventStep    = "step"
+	eventSummary = "summary"
+	eventResult  = "result"
+
+	eventStart   = "start"
+	eventSuccess = "success"
+	eventFailure = "failure"
+	eventWarning = "warning"
+	eventSkipped = "skipped"
+)
+
+// addOutputFlags binds the flags to keys that the automatic environment
+// variables can't set, so OUTPUT, QUIET or VERBOSE set by a CI system don't
+// change the output.
+func addOutputFlags(fs *pflag.FlagSet) {
+	fs.String("output", outputText, "Output format: text, or json for one JSON event per line on stdout with the progress messages on stderr (can also be set via KUBEVIRT_ENV_OUTPUT env var, which also applies to the commands with their own --output flag)")
+	fs.BoolP("quiet", "q", false, "Only print failures and results")
+	fs.BoolP("verbose", "v", false, "Also print the external commands that are run")
+	viper.BindPFlag("output-format", fs.Lookup("output"))
+	viper.BindEnv("output-format", "KUBEVIRT_ENV_OUTPUT")
+	viper.BindPFlag("output-quiet", fs.Lookup("quiet"))
+	viper.BindPFlag("output-verbose", fs.Lookup("verbose"))
+}
+
+// setupOutput validates the output flags and redirects the progress messages
+// for json and quiet mode.
+func setupOutput() error {
+	switch viper.GetString("output-format") {
+	case outputText, outputJSON:
+	default:
+		return fmt.Errorf("invalid --output %q: must be text or json", viper.GetString("output-format"))
+	}
+	if isQuiet() && isVerbose() {
+		return fmt.Errorf("--quiet and --verbose cannot be used together")
+	}
+
+	switch {
+	case isJSONOutput():
+		if !isQuiet() {
+			os.Stdout = os.Stderr
+			break
+		}
+		fallthrough
+	case isQuiet():
+		devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", os.DevNull, err)
+		}
+		os.Stdout = devNull
+	}
+	return nil
+}
+
+func isJSONOutput() bool {
+	return viper.GetString("output-format") == outputJSON
+}
+
+func isQuiet() bool {
+	return viper.GetBool("output-quiet")
+}
+
+func isVerbose() bool {
+	return viper.GetBool("output-verbose")
+}
+
+// emitEvent writes e in json output mode.
+func emitEvent(e event) {
+	if !isJSONOutput() {
+		return
+	}
+	e.Time = time.Now().UTC()
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	eventMu.Lock()
+	defer eventMu.Unlock()
+	fmt.Fprintln(stdout, string(data))
+}
+
+// reportf prints a failure or result, which quiet mode keeps. json mode
+// reports them as events instead.
+func reportf(format string, args ...interface{}) {
+	if isJSONOutput() {
+		return
+	}
+	eventMu.Lock()
+	defer eventMu.Unlock()
+	fmt.Fprintf(stdout, format, args...)
+}
+
+// command returns the command to run an external tool, printed in verbose
+// mode.
+func command(name string, args ...string) *exec.Cmd {
+	if isVerbose() {
+		fmt.Fprintf(os.Stderr, "+ %s %s\n", name, strings.Join(args, " "))
+	}
+	return exec.Command(name, args...)
+}
+
+func seconds(d time.Duration) float64 {
+	return d.Round(time.Millisecond).Seconds()
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+const (
+	portForwardReadyTimeout = 30 * time.Second
+	portForwardRetryDelay   = 2 * time.Second
+)
+
+// servicePortForward forwards a local port to a port of a service, like
+// `kubectl port-forward service/...`. The traffic goes to a ready pod of the
+// service; when the connection to it drops, the forward is re-established,
+// with another pod if needed, until stop is called.
+type servicePortForward struct {
+	config      *rest.Config
+	clientset   kubernetes.Interface
+	namespace   string
+	service     string
+	servicePort int32
+	localPort   int
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// startServicePortForward starts forwarding localPort to servicePort of
+// namespace/service and returns once the local port accepts connections.
+func startServicePortForward(namespace, service string, servicePort int32, localPort int) (*servicePortForward, error) {
+	config, err := getKubeConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	pf := &servicePortForward{
+		config:      config,
+		clientset:   clientset,
+		namespace:   namespace,
+		service:     service,
+		servicePort: servicePort,
+		localPort:   localPort,
+		stopCh:      make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+
+	ready := make(chan struct{})
+	errCh := make(chan error, 1)
+	go pf.run(ready, errCh)
+
+	select {
+	case <-ready:
+		return pf, nil
+	case err := <-errCh:
+		pf.stop()
+		return nil, err
+	case <-time.After(portForwardReadyTimeout):
+		pf.stop()
+		return nil, fmt.Errorf("timed out waiting for port-forward to %s/%s to be ready", namespace, service)
+	}
+}
+
+// stop closes the local port and waits for the forward to end. It is safe to
+// call more than once.
+func (pf *servicePortForward) stop() {
+	pf.stopOnce.Do(func() { close(pf.stopCh) })
+	<-pf.done
+}
+
+// run forwards until stop is called. The first attempt reports readiness on
+// ready, or its error on errCh; later attempts retry until stopped.
+func (pf *servicePortForward) run(ready chan struct{}, errCh chan<- error) {
+	defer close(pf.done)
+
+	first := true
+	for {
+		attemptReady := make(chan struct{})
+		if first {
+			attemptReady = ready
+		}
+		err := pf.forward(attemptReady)
+
+		select {
+		case <-pf.stopCh:
+			return
+		default:
+		}
+		if first {
+			select {
+			case <-ready:
+			default:
+				// never became ready
+				if err == nil {
+					err = fmt.Errorf("port-forward to %s/%s ended", pf.namespace, pf.service)
+				}
+				errCh <- err
+				return
+			}
+			first = false
+		}
+
+		fmt.Printf("Warning: port-forward to %s/%s dropped (%v), reconnecting...\n", pf.namespace, pf.service, err)
+		select {
+		case <-pf.stopCh:
+			return
+		case <-time.After(portForwardRetryDelay):
+		}
+	}
+}
+
+// forward runs a single port-forward to a ready pod of the service, and
+// returns when the connection to the pod is lost or stop is called.
+func (pf *servicePortForward) forward(ready chan struct{}) error {
+	ctx, cancel := context.WithTimeout(interruptCtx, 10*time.Second)
+	pod, targetPort, err := pf.resolvePod(ctx)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(pf.config)
+	if err != nil {
+		return fmt.Errorf("failed to create SPDY transport: %w", err)
+	}
+	url := pf.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pf.namespace).
+		Name(pod).
+		SubResource("portforward").
+		URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+	ports := []string{fmt.Sprintf("%d:%d", pf.localPort, targetPort)}
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"localhost"}, ports, pf.stopCh, ready, io.Discard, os.Stderr)
+	if err != nil {
+		return fmt.Errorf("failed to create port-forward: %w", err)
+	}
+	if err := forwarder.ForwardPorts(); err != nil {
+		if errors.Is(err, portforward.ErrLostConnectionToPod) {
+			return fmt.Errorf("lost connection to pod %s", pod)
+		}
+		return fmt.Errorf("failed to forward port %d to pod %s: %w", pf.localPort, pod, err)
+	}
+	return nil
+}
+
+// resolvePod returns a ready pod backing the service and the container port
+// that the service port targets.
+func (pf *servicePortForward) resolvePod(ctx context.Context) (string, int32, error) {
+	svc, err := pf.clientset.CoreV1().Services(pf.namespace).Get(ctx, pf.service, metav1.GetOptions{})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get service %s/%s: %w", pf.namespace, pf.service, err)
+	}
+	var svcPort *corev1.ServicePort
+	for i := range svc.Spec.Ports {
+		if svc.Spec.Ports[i].Port == pf.servicePort {
+			svcPort = &svc.Spec.Ports[i]
+			break
+		}
+	}
+	if svcPort == nil {
+		return "", 0, fmt.Errorf("service %s/%s has no port %d", pf.namespace, pf.service, pf.servicePort)
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return "", 0, fmt.Errorf("service %s/%s has no selector", pf.namespace, pf.service)
+	}
+
+	pods, err := pf.clientset.CoreV1().Pods(pf.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to list pods of service %s/%s: %w", pf.namespace, pf.service, err)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil || !isPodReady(pod) {
+			continue
+		}
+		port, err := podTargetPort(pod, *svcPort)
+		if err != nil {
+			return "", 0, err
+		}
+		return pod.Name, port, nil
+	}
+	return "", 0, fmt.Errorf("no ready pod found for service %s/%s", pf.namespace, pf.service)
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// podTargetPort resolves the targetPort of svcPort, which may be a container
+// port name, to a number.
+func podTargetPort(pod *corev1.Pod, svcPort corev1.ServicePort) (int32, error) {
+	if svcPort.TargetPort.IntValue() != 0 {
+		return int32(svcPort.TargetPort.IntValue()), nil
+	}
+	if svcPort.TargetPort.StrVal == "" {
+		return svcPort.Port, nil
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Name == svcPort.TargetPort.StrVal {
+				return port.ContainerPort, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("pod %s has no container port named %q", pod.Name, svcPort.TargetPort.StrVal)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// progressReader reports the progress of a transfer on a single line while
+// it is read.
+type progressReader struct {
+	reader io.Reader
+	// total is the expected size, or 0 when unknown
+	total   int64
+	read    atomic.Int64
+	start   time.Time
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func newProgressReader(reader io.Reader, total int64) *progressReader {
+	p := &progressReader{
+		reader:  reader,
+		total:   total,
+		start:   time.Now(),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go func() {
+		defer close(p.stopped)
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.done:
+				p.print()
+				fmt.Println()
+				return
+			case <-ticker.C:
+				p.print()
+			}
+		}
+	}()
+	return p
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	p.read.Add(int64(n))
+	return n, err
+}
+
+// finish prints the final progress. It must be called once.
+func (p *progressReader) finish() {
+	close(p.done)
+	<-p.stopped
+}
+
+func (p *progressReader) print() {
+	read := p.read.Load()
+	var rate float64
+	if elapsed := time.Since(p.start).Seconds(); elapsed > 0 {
+		rate = float64(read) / elapsed
+	}
+	if p.total <= 0 {
+		fmt.Printf("\r%s (%s/s)   ", formatBytes(read), formatBytes(int64(rate)))
+		return
+	}
+
+	percent := float64(read) * 100 / float64(p.total)
+	const width = 30
+	filled := min(int(percent/100*width), width)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", width-filled)
+	fmt.Printf("\r[%s] %5.1f%% %s / %s (%s/s)   ", bar, percent, formatBytes(read), formatBytes(p.total), formatBytes(int64(rate)))
+}
+
+// formatBytes formats n in binary units, e.g. 1.5 GiB.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -3,29 +3,52 @@ package main
 import (
 	"fmt"
 	"os"
-	"os/exec"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 func newSetupCmd() *cobra.Command {
+	var opts setupOptions
+
 	cmd := &cobra.Command{
 		Use:   "setup",
 		Short: "Complete setup: create cluster and install all components",
-		Long:  "Create a kind cluster and install all required components (local-path, Calico, CDI, KubeVirt, CAPI, CAPK, osbuilder, cert-manager, Kairos provider) and build/upload the Kairos image",
+		Long: `Create a kind, k3d or minikube cluster (see --provider) and install all required components (local-path, Calico, CDI, KubeVirt, CAPI, CAPK, osbuilder, cert-manager, Kairos provider) and build/upload the Kairos image.
+
+Steps that do not depend on each other run concurrently, up to --jobs at a time. Steps can be selected by name or number with --only, --skip and --from. Completed steps are recorded in the work directory, so a failed setup can be continued with --resume.
+
+Steps: ` + strings.Join(setupStepNames(), ", "),
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.topology.applyConfig(cmd.Flags())
+			if err := opts.topology.validate(); err != nil {
+				return err
+			}
+			return getClusterProvider().validate(opts.topology)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runSetup()
+			return runSetup(opts)
 		},
 	}
 
+	cmd.Flags().StringSliceVar(&opts.only, "only", nil, "Only run these steps (names or numbers)")
+	cmd.Flags().StringSliceVar(&opts.skip, "skip", nil, "Skip these steps (names or numbers)")
+	cmd.Flags().StringVar(&opts.from, "from", "", "Start at this step (name or number)")
+	cmd.Flags().BoolVar(&opts.resume, "resume", false, "Skip the steps completed by a previous setup")
+	cmd.Flags().IntVarP(&opts.jobs, "jobs", "j", 4, "Number of independent steps run concurrently (1 runs the steps one by one)")
+	opts.topology.addFlags(cmd.Flags())
+	cmd.MarkFlagsMutuallyExclusive("only", "from")
+	cmd.MarkFlagsMutuallyExclusive("only", "resume")
+
 	return cmd
 }
 
 func newCleanupCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "cleanup",
-		Short: "Clean up everything including the kind cluster",
-		Long:  "Delete the kind cluster and clean up work directories",
+		Short: "Clean up everything including the local cluster",
+		Long:  "Delete the kind, k3d or minikube cluster and clean up work directories",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runCleanup()
 		},
@@ -34,96 +57,38 @@ func newCleanupCmd() *cobra.Command {
 	return cmd
 }
 
-func runSetup() error {
+func runSetup(opts setupOptions) error {
 	clusterName := getClusterName()
 
-	fmt.Println("=== Starting complete setup ===")
-	fmt.Printf("Cluster name: %s\n", clusterName)
-	fmt.Println()
-
-	// 1. Create test cluster
-	fmt.Println("[1/12] Creating kind cluster...")
-	if err := createTestCluster(clusterName); err != nil {
-		return fmt.Errorf("failed to create test cluster: %w", err)
-	}
-	fmt.Println()
-
-	// 2. Install Calico
-	fmt.Println("[2/12] Installing local-path provisioner...")
-	if err := installLocalPath(); err != nil {
-		return fmt.Errorf("failed to install local-path provisioner: %w", err)
+	steps, err := selectSetupSteps(setupSteps(clusterName, opts.topology), opts)
+	if err != nil {
+		return err
 	}
-	fmt.Println()
-
-	// 3. Install Calico
-	fmt.Println("[3/12] Installing Calico CNI...")
-	if err := installCalico(); err != nil {
-		return fmt.Errorf("failed to install Calico: %w", err)
-	}
-	fmt.Println()
 
-	// 4. Install CDI (required for KubeVirt)
-	fmt.Println("[4/12] Installing CDI...")
-	if err := installCdi(); err != nil {
-		return fmt.Errorf("failed to install CDI: %w", err)
+	state, err := loadSetupState()
+	if err != nil {
+		return err
 	}
-	fmt.Println()
-
-	// 5. Install KubeVirt
-	fmt.Println("[5/12] Installing KubeVirt...")
-	if err := installKubevirt(); err != nil {
-		return fmt.Errorf("failed to install KubeVirt: %w", err)
-	}
-	fmt.Println()
 
-	// 6. Install CAPI
-	fmt.Println("[6/12] Installing Cluster API (CAPI)...")
-	if err := installCapi(); err != nil {
-		return fmt.Errorf("failed to install CAPI: %w", err)
-	}
-	fmt.Println()
-
-	// 7. Install CAPK
-	fmt.Println("[7/12] Installing CAPK...")
-	if err := installCapk(); err != nil {
-		return fmt.Errorf("failed to install CAPK: %w", err)
-	}
-	fmt.Println()
-
-	// 8. Install osbuilder (includes CRDs)
-	fmt.Println("[8/12] Installing osbuilder...")
-	if err := installOsbuilder(); err != nil {
-		return fmt.Errorf("failed to install osbuilder: %w", err)
-	}
-	fmt.Println()
-
-	// 9. Build Kairos image
-	fmt.Println("[9/12] Building Kairos image...")
-	if err := buildKairosImage(); err != nil {
-		return fmt.Errorf("failed to build Kairos image: %w", err)
-	}
-	fmt.Println()
-
-	// 10. Upload Kairos image
-	fmt.Println("[10/12] Uploading Kairos image...")
-	if err := uploadKairosImage(); err != nil {
-		return fmt.Errorf("failed to upload Kairos image: %w", err)
-	}
+	fmt.Println("=== Starting complete setup ===")
+	fmt.Printf("Cluster name: %s\n", clusterName)
 	fmt.Println()
 
-	// 11. Install cert-manager (required for Kairos provider)
-	fmt.Println("[11/12] Installing cert-manager...")
-	if err := installCertManager(); err != nil {
-		return fmt.Errorf("failed to install cert-manager: %w", err)
+	// Install missing tools before steps run concurrently
+	for _, step := range steps {
+		for _, tool := range step.tools {
+			if err := ensureTool(tool); err != nil {
+				return err
+			}
+		}
 	}
-	fmt.Println()
 
-	// 12. Install Kairos provider
-	fmt.Println("[12/12] Installing Kairos CAPI Provider...")
-	if err := installKairosProvider(); err != nil {
-		return fmt.Errorf("failed to install Kairos provider: %w", err)
+	results, err := runSetupSteps(steps, state, opts.resume, opts.jobs)
+	printSetupSummary(results)
+	if err != nil {
+		reportf("Continue with: kubevirt-env setup --resume\n")
+		return err
 	}
-	fmt.Println()
 
 	fmt.Println("=== Setup complete ===")
 	fmt.Println("You can now create a test cluster with: kubevirt-env test-control-plane")
@@ -137,15 +102,13 @@ func runCleanup() error {
 	fmt.Printf("Cluster name: %s\n", clusterName)
 	fmt.Println()
 
-	// Delete kind cluster
-	fmt.Println("Deleting kind cluster...")
-	kindCmd := exec.Command("kind", "delete", "cluster", "--name", clusterName)
-	kindCmd.Stdout = os.Stdout
-	kindCmd.Stderr = os.Stderr
-	if err := kindCmd.Run(); err != nil {
-		fmt.Printf("Warning: Failed to delete kind cluster: %v\n", err)
+	// Delete the cluster
+	provider := getClusterProviderName()
+	fmt.Printf("Deleting %s cluster...\n", provider)
+	if err := getClusterProvider().delete(clusterName); err != nil {
+		fmt.Printf("Warning: Failed to delete %s cluster: %v\n", provider, err)
 	} else {
-		fmt.Println("Kind cluster deleted ✓")
+		fmt.Printf("%s cluster deleted ✓\n", provider)
 	}
 	fmt.Println()
 
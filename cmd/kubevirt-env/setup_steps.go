@@ -0,0 +1,414 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// setupStep is one step of `kubevirt-env setup`.
+type setupStep struct {
+	name        string
+	description string
+	// failure prefixes the error returned when run fails
+	failure string
+	// deps are the steps that must complete before this one starts
+	deps []string
+	// tools are the external tools the step runs
+	tools []string
+	run   func() error
+}
+
+// setupOptions select the steps run by setup.
+type setupOptions struct {
+	only   []string
+	skip   []string
+	from   string
+	resume bool
+	// jobs is the number of steps run concurrently
+	jobs int
+	// topology is used when the cluster is created
+	topology kindTopology
+}
+
+// setupSteps returns the setup steps in the order they run sequentially.
+// Every step comes after its dependencies.
+func setupSteps(clusterName string, topology kindTopology) []setupStep {
+	return []setupStep{
+		{
+			name:        "kind-cluster",
+			description: "Creating " + getClusterProviderName() + " cluster",
+			failure:     "failed to create test cluster",
+			tools:       []string{getClusterProvider().tool()},
+			run:         func() error { return createTestCluster(clusterName, topology) },
+		},
+		{
+			name:        "local-path",
+			description: "Installing local-path provisioner",
+			failure:     "failed to install local-path provisioner",
+			deps:        []string{"kind-cluster"},
+			run:         installLocalPath,
+		},
+		{
+			name:        "calico",
+			description: "Installing Calico CNI",
+			failure:     "failed to install Calico",
+			deps:        []string{"kind-cluster"},
+			run:         installCalico,
+		},
+		{
+			// CDI imports into volumes of the default StorageClass
+			name:        "cdi",
+			description: "Installing CDI",
+			failure:     "failed to install CDI",
+			deps:        []string{"local-path", "calico"},
+			run:         installCdi,
+		},
+		{
+			// CDI is required for KubeVirt
+			name:        "kubevirt",
+			description: "Installing KubeVirt",
+			failure:     "failed to install KubeVirt",
+			deps:        []string{"cdi"},
+			run:         installKubevirt,
+		},
+		{
+			name:        "capi",
+			description: "Installing Cluster API (CAPI)",
+			failure:     "failed to install CAPI",
+			deps:        []string{"calico"},
+			tools:       []string{"clusterctl"},
+			run:         installCapi,
+		},
+		{
+			// clusterctl must not run twice at once, and CAPK watches KubeVirt resources
+			name:        "capk",
+			description: "Installing CAPK",
+			failure:     "failed to install CAPK",
+			deps:        []string{"capi", "kubevirt"},
+			tools:       []string{"clusterctl"},
+			run:         installCapk,
+		},
+		{
+			// osbuilder includes the CRDs
+			name:        "osbuilder",
+			description: "Installing osbuilder",
+			failure:     "failed to install osbuilder",
+			deps:        []string{"calico"},
+			run:         installOsbuilder,
+		},
+		{
+			name:        "build-kairos-image",
+			description: "Building Kairos image",
+			failure:     "failed to build Kairos image",
+			deps:        []string{"osbuilder"},
+			run:         buildKairosImage,
+		},
+		{
+			name:        "upload-kairos-image",
+			description: "Uploading Kairos image",
+			failure:     "failed to upload Kairos image",
+			deps:        []string{"build-kairos-image", "cdi"},
+			run:         uploadKairosImage,
+		},
+		{
+			// clusterctl init installs cert-manager when it is missing
+			name:        "cert-manager",
+			description: "Installing cert-manager",
+			failure:     "failed to install cert-manager",
+			deps:        []string{"capi"},
+			run:         installCertManager,
+		},
+		{
+			// cert-manager is required for the Kairos provider
+			name:        "kairos-provider",
+			description: "Installing Kairos CAPI Provider",
+			failure:     "failed to install Kairos provider",
+			deps:        []string{"cert-manager", "capi"},
+			tools:       []string{getClusterProvider().tool()},
+			run:         installKairosProvider,
+		},
+	}
+}
+
+func setupStepNames() []string {
+	return stepNames(setupSteps("", kindTopology{}))
+}
+
+func stepNames(steps []setupStep) []string {
+	names := make([]string, len(steps))
+	for i, step := range steps {
+		names[i] = step.name
+	}
+	return names
+}
+
+// selectSetupSteps returns the steps selected by opts, in order.
+func selectSetupSteps(steps []setupStep, opts setupOptions) ([]setupStep, error) {
+	selected := make([]bool, len(steps))
+	if len(opts.only) == 0 {
+		for i := range selected {
+			selected[i] = true
+		}
+	}
+	for _, ref := range opts.only {
+		i, err := setupStepIndex(steps, ref)
+		if err != nil {
+			return nil, err
+		}
+		selected[i] = true
+	}
+	if opts.from != "" {
+		from, err := setupStepIndex(steps, opts.from)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < from; i++ {
+			selected[i] = false
+		}
+	}
+	for _, ref := range opts.skip {
+		i, err := setupStepIndex(steps, ref)
+		if err != nil {
+			return nil, err
+		}
+		selected[i] = false
+	}
+
+	var out []setupStep
+	for i, step := range steps {
+		if selected[i] {
+			out = append(out, step)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no setup steps selected")
+	}
+	return out, nil
+}
+
+// setupStepIndex resolves a step name or 1-based step number.
+func setupStepIndex(steps []setupStep, ref string) (int, error) {
+	ref = strings.TrimSpace(ref)
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(steps) {
+			return 0, fmt.Errorf("setup step %d out of range (1-%d)", n, len(steps))
+		}
+		return n - 1, nil
+	}
+	for i, step := range steps {
+		if step.name == ref {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown setup step %q (valid steps: %s)", ref, strings.Join(stepNames(steps), ", "))
+}
+
+// setupState records the setup steps completed in the work directory.
+type setupState struct {
+	Completed map[string]time.Time `json:"completed"`
+}
+
+func getSetupStatePath() string {
+	return filepath.Join(getWorkDir(), "setup-state.json")
+}
+
+func loadSetupState() (*setupState, error) {
+	state := &setupState{Completed: map[string]time.Time{}}
+	data, err := os.ReadFile(getSetupStatePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("failed to read setup state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse setup state %s: %w", getSetupStatePath(), err)
+	}
+	if state.Completed == nil {
+		state.Completed = map[string]time.Time{}
+	}
+	return state, nil
+}
+
+func (s *setupState) isCompleted(step string) bool {
+	_, ok := s.Completed[step]
+	return ok
+}
+
+func (s *setupState) complete(step string) {
+	s.Completed[step] = time.Now().UTC()
+}
+
+func (s *setupState) reset(step string) {
+	delete(s.Completed, step)
+}
+
+func (s *setupState) save() error {
+	if err := os.MkdirAll(getWorkDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create work directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(getSetupStatePath(), data, 0644)
+}
+
+// setupResult is the outcome of a setup step.
+type setupResult struct {
+	step     setupStep
+	status   string
+	duration time.Duration
+	err      error
+}
+
+const (
+	setupStepDone    = "done"
+	setupStepFailed  = "failed"
+	setupStepSkipped = "skipped"
+	setupStepNotRun  = "not run"
+)
+
+// runSetupSteps runs steps, up to jobs at a time, starting every step once the
+// selected steps it depends on completed. After a failure no further steps
+// start; the running ones are waited for. Completed steps are recorded in
+// state.
+func runSetupSteps(steps []setupStep, state *setupState, resume bool, jobs int) ([]setupResult, error) {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	selected := map[string]bool{}
+	for _, step := range steps {
+		selected[step.name] = true
+	}
+
+	results := make([]setupResult, len(steps))
+	finished := map[string]bool{}
+	started := make([]bool, len(steps))
+	total := len(steps)
+	for i, step := range steps {
+		results[i] = setupResult{step: step, status: setupStepNotRun}
+		if resume && state.isCompleted(step.name) {
+			results[i].status = setupStepSkipped
+			finished[step.name] = true
+			started[i] = true
+			total--
+			fmt.Printf("Step %s already completed, skipping ✓\n", step.name)
+			emitEvent(event{Type: eventStep, Step: step.name, Status: eventSkipped})
+		}
+	}
+
+	ready := func(step setupStep) bool {
+		for _, dep := range step.deps {
+			if selected[dep] && !finished[dep] {
+				return false
+			}
+		}
+		return true
+	}
+
+	type completion struct {
+		index    int
+		duration time.Duration
+		err      error
+	}
+	done := make(chan completion)
+	running, count := 0, 0
+	failed := false
+	for {
+		for i, step := range steps {
+			if failed || running >= jobs {
+				break
+			}
+			if started[i] || !ready(step) {
+				continue
+			}
+			started[i] = true
+			running++
+			count++
+			fmt.Printf("[%d/%d] %s...\n", count, total, step.description)
+			if isVerbose() && len(step.deps) > 0 {
+				fmt.Printf("Step %s started after %s\n", step.name, strings.Join(step.deps, ", "))
+			}
+			emitEvent(event{Type: eventStep, Step: step.name, Status: eventStart})
+			go func(i int, step setupStep) {
+				start := time.Now()
+				err := step.run()
+				done <- completion{index: i, duration: time.Since(start), err: err}
+			}(i, step)
+		}
+		if running == 0 {
+			break
+		}
+
+		c := <-done
+		running--
+		result := &results[c.index]
+		result.duration = c.duration
+		if c.err != nil {
+			result.status = setupStepFailed
+			result.err = fmt.Errorf("%s: %w", result.step.failure, c.err)
+			state.reset(result.step.name)
+			failed = true
+			reportf("✗ Step %s failed after %s: %v\n", result.step.name, c.duration.Round(time.Second), c.err)
+			emitEvent(event{Type: eventStep, Step: result.step.name, Status: eventFailure, Duration: seconds(c.duration), Error: result.err.Error()})
+		} else {
+			result.status = setupStepDone
+			state.complete(result.step.name)
+			finished[result.step.name] = true
+			fmt.Printf("✓ Step %s completed in %s\n", result.step.name, c.duration.Round(time.Second))
+			emitEvent(event{Type: eventStep, Step: result.step.name, Status: eventSuccess, Duration: seconds(c.duration)})
+		}
+		if err := state.save(); err != nil {
+			fmt.Printf("Warning: failed to save setup state: %v\n", err)
+		}
+		fmt.Println()
+	}
+
+	var errs []error
+	for _, result := range results {
+		if result.err != nil {
+			errs = append(errs, result.err)
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// printSetupSummary prints the outcome of every step, also in quiet mode. In
+// json mode it is a summary event.
+func printSetupSummary(results []setupResult) {
+	if isJSONOutput() {
+		summary := event{Type: eventSummary}
+		for _, result := range results {
+			entry := summaryEntry{Step: result.step.name, Status: result.status, Duration: seconds(result.duration)}
+			if result.err != nil {
+				entry.Error = result.err.Error()
+			}
+			summary.Steps = append(summary.Steps, entry)
+		}
+		emitEvent(summary)
+		return
+	}
+
+	reportf("=== Setup summary ===\n")
+	var buf strings.Builder
+	w := tabwriter.NewWriter(&buf, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "STEP\tSTATUS\tDURATION")
+	for _, result := range results {
+		duration := "-"
+		if result.status == setupStepDone || result.status == setupStepFailed {
+			duration = result.duration.Round(time.Second).String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.step.name, result.status, duration)
+	}
+	w.Flush()
+	reportf("%s\n", buf.String())
+}
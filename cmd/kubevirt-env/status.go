@@ -0,0 +1,369 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/spf13/cobra"
+)
+
+// componentStatus is the state of one component of the environment.
+type componentStatus struct {
+	Name      string `json:"name"`
+	Installed bool   `json:"installed"`
+	Ready     bool   `json:"ready"`
+	Version   string `json:"version,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// environmentStatus is the state of the whole environment, as printed by
+// `kubevirt-env status`.
+type environmentStatus struct {
+	Cluster    string            `json:"cluster"`
+	Components []componentStatus `json:"components"`
+}
+
+func newStatusCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the status of the environment",
+		Long:  "Show whether each component of the environment (kind, k3d or minikube cluster, Calico, CDI, KubeVirt, CAPI, CAPK, osbuilder, cert-manager, Kairos provider and the Kairos image DataVolume) is installed and ready, and its version",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status := getEnvironmentStatus()
+			switch output {
+			case "":
+				return printEnvironmentStatus(status)
+			case "json":
+				data, err := json.MarshalIndent(status, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to encode status: %w", err)
+				}
+				fmt.Println(string(data))
+			case "yaml":
+				data, err := yaml.Marshal(status)
+				if err != nil {
+					return fmt.Errorf("failed to encode status: %w", err)
+				}
+				fmt.Print(string(data))
+			default:
+				return fmt.Errorf("unsupported output format %q (supported: json, yaml)", output)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format (json, yaml)")
+	return cmd
+}
+
+func printEnvironmentStatus(status environmentStatus) error {
+	fmt.Printf("Cluster name: %s\n\n", status.Cluster)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "COMPONENT\tINSTALLED\tREADY\tVERSION\tMESSAGE")
+	for _, component := range status.Components {
+		version := component.Version
+		if version == "" {
+			version = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", component.Name, yesNo(component.Installed), yesNo(component.Ready), version, component.Message)
+	}
+	return w.Flush()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// getEnvironmentStatus checks every component. Components of a cluster that
+// does not exist or cannot be reached are reported as not installed.
+func getEnvironmentStatus() environmentStatus {
+	status := environmentStatus{Cluster: getClusterName()}
+
+	cluster := clusterStatus(status.Cluster)
+	status.Components = append(status.Components, cluster)
+
+	checks := []struct {
+		name  string
+		check func(ctx context.Context, clients *statusClients) componentStatus
+	}{
+		{"calico", calicoStatus},
+		{"cdi", cdiStatus},
+		{"kubevirt", kubevirtStatus},
+		{"capi", deploymentComponentStatus("capi-system", "capi-controller-manager")},
+		{"capk", deploymentComponentStatus("capk-system", "capk-controller-manager")},
+		{"osbuilder", osbuilderStatus},
+		{"cert-manager", deploymentComponentStatus("cert-manager", "cert-manager", "cert-manager-webhook", "cert-manager-cainjector")},
+		{"kairos-provider", deploymentComponentStatus("kairos-capi-system", "kairos-capi-controller-manager")},
+		{"kairos-image", kairosImageStatus},
+	}
+
+	var clients *statusClients
+	if cluster.Ready {
+		clients = newStatusClients()
+	}
+	for _, c := range checks {
+		var component componentStatus
+		if clients == nil {
+			component.Message = "cluster not reachable"
+		} else {
+			ctx, cancel := context.WithTimeout(interruptCtx, 5*time.Second)
+			component = c.check(ctx, clients)
+			cancel()
+		}
+		component.Name = c.name
+		status.Components = append(status.Components, component)
+	}
+	return status
+}
+
+type statusClients struct {
+	clientset kubernetes.Interface
+	dynamic   dynamic.Interface
+	crds      apiextensionsv1.ApiextensionsV1Interface
+}
+
+func newStatusClients() *statusClients {
+	config, err := getKubeConfig()
+	if err != nil {
+		return nil
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil
+	}
+	crds, err := apiextensionsv1.NewForConfig(config)
+	if err != nil {
+		return nil
+	}
+	return &statusClients{clientset: clientset, dynamic: dynamicClient, crds: crds}
+}
+
+func clusterStatus(clusterName string) componentStatus {
+	provider := getClusterProvider()
+	status := componentStatus{Name: getClusterProviderName() + "-cluster"}
+	if _, err := lookupTool(provider.tool()); err != nil {
+		status.Message = provider.tool() + " not found in ./bin or PATH"
+		return status
+	}
+	if !provider.exists(clusterName) {
+		status.Message = "cluster does not exist"
+		return status
+	}
+	status.Installed = true
+
+	clientset, err := getKubeClient()
+	if err != nil {
+		status.Message = err.Error()
+		return status
+	}
+	version, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		status.Message = fmt.Sprintf("API server not reachable: %v", err)
+		return status
+	}
+	status.Ready = true
+	status.Version = version.GitVersion
+	return status
+}
+
+// deploymentComponentStatus returns a check for a component made of
+// deployments in namespace. It is installed when the first deployment exists,
+// ready when all are available, and versioned by the image tag of the first.
+func deploymentComponentStatus(namespace string, names ...string) func(ctx context.Context, clients *statusClients) componentStatus {
+	return func(ctx context.Context, clients *statusClients) componentStatus {
+		var status componentStatus
+		status.Ready = true
+		for i, name := range names {
+			deployment, err := clients.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				if i == 0 {
+					status.Ready = false
+					return status
+				}
+				status.Ready = false
+				status.Message = fmt.Sprintf("deployment %s/%s not found", namespace, name)
+				continue
+			}
+			if i == 0 {
+				status.Installed = true
+				status.Version = deploymentVersion(deployment)
+			}
+			if !isDeploymentAvailable(deployment) {
+				status.Ready = false
+				status.Message = fmt.Sprintf("deployment %s/%s not available", namespace, name)
+			}
+		}
+		return status
+	}
+}
+
+func calicoStatus(ctx context.Context, clients *statusClients) componentStatus {
+	var status componentStatus
+	ds, err := clients.clientset.AppsV1().DaemonSets("kube-system").Get(ctx, "calico-node", metav1.GetOptions{})
+	if err != nil {
+		return status
+	}
+	status.Installed = true
+	status.Version = imageTag(ds.Spec.Template.Spec.Containers)
+
+	controllers := deploymentComponentStatus("kube-system", "calico-kube-controllers")(ctx, clients)
+	dsReady := ds.Status.NumberReady == ds.Status.DesiredNumberScheduled && ds.Status.DesiredNumberScheduled > 0
+	status.Ready = dsReady && controllers.Ready
+	switch {
+	case !dsReady:
+		status.Message = fmt.Sprintf("calico-node %d/%d pods ready", ds.Status.NumberReady, ds.Status.DesiredNumberScheduled)
+	case !controllers.Ready:
+		status.Message = "calico-kube-controllers not available"
+	}
+	return status
+}
+
+func cdiStatus(ctx context.Context, clients *statusClients) componentStatus {
+	status := deploymentComponentStatus("cdi", "cdi-operator")(ctx, clients)
+	if !status.Installed {
+		return status
+	}
+	cdi, err := clients.dynamic.Resource(schema.GroupVersionResource{
+		Group:    "cdi.kubevirt.io",
+		Version:  "v1beta1",
+		Resource: "cdis",
+	}).Get(ctx, "cdi", metav1.GetOptions{})
+	if err != nil {
+		status.Ready = false
+		status.Message = "CDI resource not found"
+		return status
+	}
+	return operatorResourceStatus(status, cdi, "observedVersion")
+}
+
+func kubevirtStatus(ctx context.Context, clients *statusClients) componentStatus {
+	status := deploymentComponentStatus("kubevirt", "virt-operator")(ctx, clients)
+	if !status.Installed {
+		return status
+	}
+	kubevirt, err := getKubeVirtCR(ctx, clients.dynamic)
+	if err != nil {
+		status.Ready = false
+		status.Message = "KubeVirt resource not found"
+		return status
+	}
+	return operatorResourceStatus(status, kubevirt, "observedKubeVirtVersion")
+}
+
+// operatorResourceStatus completes status from the custom resource of an
+// operator that reports Available and a Deployed phase, as CDI and KubeVirt do.
+func operatorResourceStatus(status componentStatus, obj *unstructured.Unstructured, versionField string) componentStatus {
+	if version, _, _ := unstructured.NestedString(obj.Object, "status", versionField); version != "" {
+		status.Version = version
+	}
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	if phase == "Deployed" || hasTrueCondition(obj, "Available") {
+		return status
+	}
+	status.Ready = false
+	if phase != "" {
+		status.Message = "phase " + phase
+	}
+	return status
+}
+
+func hasTrueCondition(obj *unstructured.Unstructured, conditionType string) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, cond := range conditions {
+		if condMap, ok := cond.(map[string]interface{}); ok {
+			if condMap["type"] == conditionType && condMap["status"] == "True" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func osbuilderStatus(ctx context.Context, clients *statusClients) componentStatus {
+	status := deploymentComponentStatus("default", "osbuilder")(ctx, clients)
+	if !status.Installed {
+		return status
+	}
+	if _, err := clients.crds.CustomResourceDefinitions().Get(ctx, "osartifacts.build.kairos.io", metav1.GetOptions{}); err != nil {
+		status.Ready = false
+		status.Message = "OSArtifact CRD not found"
+	}
+	return status
+}
+
+func kairosImageStatus(ctx context.Context, clients *statusClients) componentStatus {
+	var status componentStatus
+	dv, err := clients.dynamic.Resource(schema.GroupVersionResource{
+		Group:    "cdi.kubevirt.io",
+		Version:  "v1beta1",
+		Resource: "datavolumes",
+	}).Namespace("default").Get(ctx, kairosImageName, metav1.GetOptions{})
+	if err != nil {
+		return status
+	}
+	status.Installed = true
+	if sum := dv.GetAnnotations()[imageChecksumAnnotation]; len(sum) >= 12 {
+		status.Version = "sha256:" + sum[:12]
+	}
+	phase, _, _ := unstructured.NestedString(dv.Object, "status", "phase")
+	status.Ready = phase == "Succeeded"
+	if !status.Ready {
+		status.Message = "phase " + phase
+		if progress, _, _ := unstructured.NestedString(dv.Object, "status", "progress"); progress != "" && progress != "N/A" {
+			status.Message += ", progress " + progress
+		}
+	}
+	return status
+}
+
+func isDeploymentAvailable(deployment *appsv1.Deployment) bool {
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentAvailable && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func deploymentVersion(deployment *appsv1.Deployment) string {
+	return imageTag(deployment.Spec.Template.Spec.Containers)
+}
+
+// imageTag returns the tag of the first container image, e.g. v1.8.0 for
+// registry.k8s.io/cluster-api/cluster-api-controller:v1.8.0.
+func imageTag(containers []corev1.Container) string {
+	if len(containers) == 0 {
+		return ""
+	}
+	image, _, _ := strings.Cut(containers[0].Image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return ""
+}
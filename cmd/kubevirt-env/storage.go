@@ -77,7 +77,7 @@ func isLocalPathInstalled() bool {
 		return false
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(interruptCtx, 5*time.Second)
 	defer cancel()
 
 	_, err = clientset.StorageV1().StorageClasses().Get(ctx, localPathClassName, metav1.GetOptions{})
@@ -107,7 +107,7 @@ func installLocalPath() error {
 	}
 
 	// Wait for the local-path provisioner deployment
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	ctx, cancel := waitContext("local-path")
 	defer cancel()
 	if err := waitForDeployment(ctx, clientset, localPathNamespace, "local-path-provisioner"); err != nil {
 		fmt.Printf("Warning: local-path provisioner may not be fully ready: %v\n", err)
@@ -146,7 +146,7 @@ func uninstallLocalPath() error {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	ctx, cancel := waitContext("namespace-delete")
 	defer cancel()
 
 	if err := waitForNamespaceDeleted(ctx, clientset, localPathNamespace); err != nil {
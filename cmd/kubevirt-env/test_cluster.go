@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"time"
 
@@ -111,156 +110,18 @@ func createSampleCluster() error {
 		return fmt.Errorf("failed to create manifest directory: %w", err)
 	}
 
-	// Generate the YAML content
-	yamlContent := `# ============================================================================
-# CAPK Sample: Single-Node k0s Cluster on Kairos OS with KubeVirt
-# ============================================================================
-#
-# SETUP INSTRUCTIONS:
-# 
-# 1. Make sure you have completed the setup:
-#    - kubevirt-env setup (or individual install commands)
-#    - kubevirt-env upload-kairos-image (to upload the Kairos image)
-#
-# 2. Update the KubeVirtMachineTemplate below:
-#    - Set the storageClassName if needed
-#    - Adjust CPU, memory, and disk sizes
-#    - Set the dataVolumeTemplate name to match your uploaded image PVC
-#
-# 3. Apply the manifest:
-#    - kubectl apply -f config/samples/capk/kairos_cluster_k0s_single_node.yaml
-#
-# ============================================================================
-
-apiVersion: cluster.x-k8s.io/v1beta2
-kind: Cluster
-metadata:
-  name: kairos-cluster
-  namespace: default
-spec:
-  infrastructureRef:
-    apiGroup: infrastructure.cluster.x-k8s.io
-    kind: KubevirtCluster
-    name: kairos-cluster
-  controlPlaneRef:
-    apiGroup: controlplane.cluster.x-k8s.io
-    kind: KairosControlPlane
-    name: kairos-control-plane
----
-apiVersion: infrastructure.cluster.x-k8s.io/v1alpha4
-kind: KubevirtCluster
-metadata:
-  name: kairos-cluster
-  namespace: default
-spec: {}
----
-apiVersion: controlplane.cluster.x-k8s.io/v1beta2
-kind: KairosControlPlane
-metadata:
-  name: kairos-control-plane
-  namespace: default
-spec:
-  replicas: 1
-  version: "v1.34.1+k0s.1"
-  machineTemplate:
-    infrastructureRef:
-      apiVersion: infrastructure.cluster.x-k8s.io/v1alpha1
-      kind: KubevirtMachineTemplate
-      name: kairos-control-plane-template
-      namespace: default
-  kairosConfigTemplate:
-    name: kairos-config-template-control-plane
----
-apiVersion: infrastructure.cluster.x-k8s.io/v1alpha4
-kind: KubevirtMachineTemplate
-metadata:
-  name: kairos-control-plane-template
-  namespace: default
-spec:
-  template:
-    spec:
-      virtualMachineTemplate:
-        spec:
-          dataVolumeTemplates:
-          - apiVersion: cdi.kubevirt.io/v1beta1
-            kind: DataVolume
-            metadata:
-              name: kairos-rootdisk
-            spec:
-              pvc:
-                accessModes:
-                - ReadWriteOnce
-                resources:
-                  requests:
-                    storage: 30Gi
-                # TODO: Set storageClassName if needed
-                # storageClassName: standard
-              source:
-                pvc:
-                  name: kairos-kubevirt
-                  namespace: default
-          running: true
-          template:
-            spec:
-              domain:
-                cpu:
-                  cores: 2
-                memory:
-                  guest: 4Gi
-                devices:
-                  disks:
-                  - name: rootdisk
-                    bootOrder: 1
-                    disk:
-                      bus: virtio
-                  interfaces:
-                  - name: default
-                    masquerade: {}
-                features:
-                  acpi:
-                    enabled: true
-                # Use UEFI boot (image has GPT partition table with EFI System partition)
-                # Disable secure boot to avoid boot issues
-                firmware:
-                  bootloader:
-                    efi:
-                      secureBoot: false
-              networks:
-              - name: default
-                pod: {}
-              volumes:
-              - name: rootdisk
-                dataVolume:
-                  name: kairos-rootdisk
----
-apiVersion: bootstrap.cluster.x-k8s.io/v1beta2
-kind: KairosConfigTemplate
-metadata:
-  name: kairos-config-template-control-plane
-  namespace: default
-spec:
-  template:
-    spec:
-      role: control-plane
-      distribution: k0s
-      kubernetesVersion: "v1.34.1+k0s.1"
-      userName: kairos
-      userPassword: kairos
-      userGroups:
-        - admin
-      # Optional: Add GitHub user for SSH access
-      # githubUser: "your-github-username"
-      # Optional: Add SSH public key instead
-      # sshPublicKey: "ssh-rsa AAAAB3NzaC1yc2E..."
-`
+	manifest, err := renderClusterManifest(defaultClusterManifestOptions(), true)
+	if err != nil {
+		return err
+	}
 
 	// Write the YAML file
-	if err := os.WriteFile(sampleClusterFile, []byte(yamlContent), 0644); err != nil {
+	if err := os.WriteFile(sampleClusterFile, manifest, 0644); err != nil {
 		return fmt.Errorf("failed to write sample cluster manifest: %w", err)
 	}
 
 	fmt.Printf("Sample cluster manifest created at %s\n", sampleClusterFile)
-	fmt.Println("Remember to update the dataVolumeTemplate source.pvc.name if your PVC name differs from 'kairos-kubevirt'")
+	fmt.Println("Use kubevirt-env generate-cluster to change the distribution, sizes, storage class or image PVC")
 	return nil
 }
 
@@ -289,7 +150,7 @@ func verifyCAPKCRDs() error {
 		"kubevirtmachines.infrastructure.cluster.x-k8s.io",
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := waitContext("crd")
 	defer cancel()
 
 	for _, crdName := range crds {
@@ -347,8 +208,8 @@ func deleteExistingMachineTemplate() error {
 		Resource: "kubevirtmachinetemplates",
 	}
 
-	ctx := context.Background()
-	err = dynamicClient.Resource(templateGVR).Namespace(clusterNamespace).Delete(ctx, "kairos-control-plane-template", metav1.DeleteOptions{})
+	ctx := interruptCtx
+	err = dynamicClient.Resource(templateGVR).Namespace(clusterNamespace).Delete(ctx, clusterName+"-control-plane-template", metav1.DeleteOptions{})
 	if err != nil {
 		// Ignore not found errors
 		return nil
@@ -374,7 +235,7 @@ func waitForClusterProvisioned() error {
 		Resource: "clusters",
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	ctx, cancel := waitContext("control-plane")
 	defer cancel()
 
 	return wait.PollUntilContextCancel(ctx, 5*time.Second, true, func(ctx context.Context) (bool, error) {
@@ -405,21 +266,21 @@ func showTestClusterStatus() error {
 	kubectlContext := getKubectlContext()
 
 	fmt.Println("\n=== Cluster Status ===")
-	cmd := exec.Command("kubectl", "get", "cluster", clusterName, "-n", clusterNamespace,
+	cmd := command("kubectl", "get", "cluster", clusterName, "-n", clusterNamespace,
 		"--kubeconfig", kubeconfigPath, "--context", kubectlContext)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Run()
 
 	fmt.Println("\n=== Control Plane Status ===")
-	cmd = exec.Command("kubectl", "get", "kairoscontrolplane", "kairos-control-plane", "-n", clusterNamespace,
+	cmd = command("kubectl", "get", "kairoscontrolplane", clusterName+"-control-plane", "-n", clusterNamespace,
 		"--kubeconfig", kubeconfigPath, "--context", kubectlContext)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Run()
 
 	fmt.Println("\n=== Machine Status ===")
-	cmd = exec.Command("kubectl", "get", "machines", "-n", clusterNamespace,
+	cmd = command("kubectl", "get", "machines", "-n", clusterNamespace,
 		"-l", fmt.Sprintf("cluster.x-k8s.io/cluster-name=%s", clusterName),
 		"--kubeconfig", kubeconfigPath, "--context", kubectlContext)
 	cmd.Stdout = os.Stdout
@@ -427,7 +288,7 @@ func showTestClusterStatus() error {
 	cmd.Run()
 
 	fmt.Println("\n=== KubeVirt VM Status ===")
-	cmd = exec.Command("kubectl", "get", "vms", "-n", clusterNamespace,
+	cmd = command("kubectl", "get", "vms", "-n", clusterNamespace,
 		"-l", fmt.Sprintf("cluster.x-k8s.io/cluster-name=%s", clusterName),
 		"--kubeconfig", kubeconfigPath, "--context", kubectlContext)
 	cmd.Stdout = os.Stdout
@@ -435,7 +296,7 @@ func showTestClusterStatus() error {
 	cmd.Run()
 
 	fmt.Println("\n=== Pods Status ===")
-	cmd = exec.Command("kubectl", "get", "pods", "-n", clusterNamespace,
+	cmd = command("kubectl", "get", "pods", "-n", clusterNamespace,
 		"-l", fmt.Sprintf("cluster.x-k8s.io/cluster-name=%s", clusterName),
 		"--kubeconfig", kubeconfigPath, "--context", kubectlContext)
 	cmd.Stdout = os.Stdout
@@ -458,6 +319,14 @@ func deleteTestCluster() error {
 		return fmt.Errorf("failed to create dynamic client: %w", err)
 	}
 
+	// Workers added by test-workers go first, so their templates don't
+	// outlive the cluster
+	if _, err := os.Stat(workersSampleFile); err == nil {
+		if err := deleteResourcesFromManifestFile(dynamicClient, config, workersSampleFile); err != nil {
+			return fmt.Errorf("failed to delete workers manifest: %w", err)
+		}
+	}
+
 	if err := deleteResourcesFromManifestFile(dynamicClient, config, sampleClusterFile); err != nil {
 		return fmt.Errorf("failed to delete cluster manifest: %w", err)
 	}
@@ -479,7 +348,7 @@ func deleteTestCluster() error {
 		Resource: "clusters",
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	ctx, cancel := waitContext("cluster-delete")
 	defer cancel()
 
 	return wait.PollUntilContextCancel(ctx, 2*time.Second, true, func(checkCtx context.Context) (bool, error) {
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	workersSampleFile    = "config/samples/capk/kairos_cluster_k0s_workers.yaml"
+	workerDeploymentName = clusterName + "-workers"
+)
+
+var (
+	machineGVR = schema.GroupVersionResource{
+		Group:    "cluster.x-k8s.io",
+		Version:  "v1beta2",
+		Resource: "machines",
+	}
+	vmiGVR = schema.GroupVersionResource{
+		Group:    "kubevirt.io",
+		Version:  "v1",
+		Resource: "virtualmachineinstances",
+	}
+)
+
+func newTestWorkersCmd() *cobra.Command {
+	var workers int
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "test-workers",
+		Short: "Add workers to the test cluster and verify they join",
+		Long: `Add a MachineDeployment with worker machines to the test cluster and verify the whole cluster end to end:
+the worker VMIs boot, the workload kubeconfig is retrieved, and the worker nodes become Ready.
+
+The control-plane test cluster is created first when it does not exist (see test-control-plane).`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if workers < 1 {
+				return fmt.Errorf("--workers must be at least 1")
+			}
+			if timeout == 0 {
+				timeout = getTimeout("workers")
+			}
+			return testWorkers(workers, timeout)
+		},
+	}
+
+	cmd.Flags().IntVar(&workers, "workers", 2, "Number of worker machines")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "How long to wait for the workers to boot and become Ready nodes (default timeouts.workers, 20m)")
+	return cmd
+}
+
+func testWorkers(workers int, timeout time.Duration) error {
+	config, err := getKubeConfig()
+	if err != nil {
+		return err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	clusterGVR := schema.GroupVersionResource{
+		Group:    "cluster.x-k8s.io",
+		Version:  "v1beta2",
+		Resource: "clusters",
+	}
+	_, err = dynamicClient.Resource(clusterGVR).Namespace(clusterNamespace).Get(interruptCtx, clusterName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		fmt.Println("Test cluster not found, creating the control plane first...")
+		if err := testControlPlane(); err != nil {
+			return err
+		}
+	case err != nil:
+		return fmt.Errorf("failed to get cluster %s: %w", clusterName, err)
+	default:
+		fmt.Printf("Using the existing test cluster %s ✓\n", clusterName)
+	}
+
+	if err := createWorkersSample(workers); err != nil {
+		return fmt.Errorf("failed to create workers manifest: %w", err)
+	}
+
+	fmt.Printf("Creating %d worker(s)...\n", workers)
+	if err := applyManifestFromFile(dynamicClient, config, workersSampleFile); err != nil {
+		return fmt.Errorf("failed to apply workers manifest: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(interruptCtx, timeout)
+	defer cancel()
+
+	fmt.Println("Waiting for the worker VMIs to boot...")
+	if err := waitForWorkerVMIs(ctx, dynamicClient, workers); err != nil {
+		return fmt.Errorf("worker VMIs did not boot: %w", err)
+	}
+
+	fmt.Println("Retrieving the workload cluster kubeconfig...")
+	workloadKubeconfig, err := getWorkloadKubeconfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Waiting for the worker nodes to be Ready...")
+	if err := waitForWorkerNodesReady(ctx, dynamicClient, workloadKubeconfig, workers); err != nil {
+		return fmt.Errorf("worker nodes are not Ready: %w", err)
+	}
+
+	fmt.Println("\n=== Workload Cluster Nodes ===")
+	cmd := command("kubectl", "get", "nodes", "-o", "wide", "--kubeconfig", workloadKubeconfig)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Run()
+
+	fmt.Printf("\n✓ Cluster %s has %d Ready worker(s)\n", clusterName, workers)
+	fmt.Printf("Workload kubeconfig: %s\n", workloadKubeconfig)
+	return nil
+}
+
+func createWorkersSample(workers int) error {
+	fmt.Println("Creating workers manifest...")
+	if err := os.MkdirAll(filepath.Dir(workersSampleFile), 0755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+
+	opts := defaultClusterManifestOptions()
+	opts.Workers = workers
+	manifest, err := renderClusterManifest(opts, false)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(workersSampleFile, manifest, 0644); err != nil {
+		return fmt.Errorf("failed to write workers manifest: %w", err)
+	}
+
+	fmt.Printf("Workers manifest created at %s\n", workersSampleFile)
+	return nil
+}
+
+// workerMachines returns the Machines of the worker MachineDeployment.
+func workerMachines(ctx context.Context, dynamicClient dynamic.Interface) ([]unstructured.Unstructured, error) {
+	list, err := dynamicClient.Resource(machineGVR).Namespace(clusterNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("cluster.x-k8s.io/cluster-name=%s,cluster.x-k8s.io/deployment-name=%s", clusterName, workerDeploymentName),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// waitForWorkerVMIs waits until every worker Machine has a running VMI. CAPK
+// names the VM after the KubevirtMachine.
+func waitForWorkerVMIs(ctx context.Context, dynamicClient dynamic.Interface, workers int) error {
+	lastRunning := -1
+	return wait.PollUntilContextCancel(ctx, 10*time.Second, true, func(ctx context.Context) (bool, error) {
+		machines, err := workerMachines(ctx, dynamicClient)
+		if err != nil {
+			return false, nil
+		}
+
+		running := 0
+		for _, machine := range machines {
+			name, _, _ := unstructured.NestedString(machine.Object, "spec", "infrastructureRef", "name")
+			if name == "" {
+				continue
+			}
+			vmi, err := dynamicClient.Resource(vmiGVR).Namespace(clusterNamespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				continue
+			}
+			if phase, _, _ := unstructured.NestedString(vmi.Object, "status", "phase"); phase == "Running" {
+				running++
+			}
+		}
+
+		if running != lastRunning {
+			fmt.Printf("Worker VMIs running: %d/%d\n", running, workers)
+			lastRunning = running
+		}
+		return running >= workers, nil
+	})
+}
+
+// getWorkloadKubeconfig writes the kubeconfig of the test cluster to the work
+// directory once the control plane has created it, and returns its path.
+func getWorkloadKubeconfig(ctx context.Context) (string, error) {
+	clientset, err := getKubeClient()
+	if err != nil {
+		return "", err
+	}
+
+	opts := workloadKubeconfigOptions{cluster: clusterName, namespace: clusterNamespace, endpoint: "auto"}
+	var path string
+	var lastErr error
+	err = wait.PollUntilContextCancel(ctx, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+		path, lastErr = writeWorkloadKubeconfig(ctx, clientset, opts)
+		return lastErr == nil, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("workload kubeconfig not available: %w (last error: %v)", err, lastErr)
+	}
+	return path, nil
+}
+
+// waitForWorkerNodesReady waits until the nodes of the worker Machines are
+// Ready in the workload cluster.
+func waitForWorkerNodesReady(ctx context.Context, dynamicClient dynamic.Interface, kubeconfigPath string, workers int) error {
+	data, err := os.ReadFile(kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read workload kubeconfig: %w", err)
+	}
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse workload kubeconfig: %w", err)
+	}
+	restConfig.Timeout = 10 * time.Second
+	workloadClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create workload cluster client: %w", err)
+	}
+
+	lastReady := -1
+	return wait.PollUntilContextCancel(ctx, 10*time.Second, true, func(ctx context.Context) (bool, error) {
+		machines, err := workerMachines(ctx, dynamicClient)
+		if err != nil {
+			return false, nil
+		}
+
+		ready := 0
+		for _, machine := range machines {
+			nodeName, _, _ := unstructured.NestedString(machine.Object, "status", "nodeRef", "name")
+			if nodeName == "" {
+				continue
+			}
+			node, err := workloadClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+			if err != nil {
+				continue
+			}
+			for _, condition := range node.Status.Conditions {
+				if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+					ready++
+					break
+				}
+			}
+		}
+
+		if ready != lastReady {
+			fmt.Printf("Worker nodes Ready: %d/%d\n", ready, workers)
+			lastReady = ready
+		}
+		return ready >= workers, nil
+	})
+}
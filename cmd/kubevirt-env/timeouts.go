@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// interruptCtx is cancelled on SIGINT or SIGTERM, which stops the waits and
+// the requests to the cluster. A second signal kills the process.
+var interruptCtx = context.Background()
+
+// waitTimeout is the timeout of a wait, set with timeouts.<key> in the config
+// file.
+type waitTimeout struct {
+	key string
+	// fallback is the key used when key is not set, instead of def
+	fallback string
+	def      time.Duration
+}
+
+// waitTimeouts are the configurable timeouts. The components default to the
+// install timeout.
+var waitTimeouts = []waitTimeout{
+	{key: "install", def: 300 * time.Second},
+	{key: "local-path", fallback: "install"},
+	{key: "calico", fallback: "install"},
+	{key: "cdi", fallback: "install"},
+	{key: "kubevirt", fallback: "install"},
+	{key: "capi", fallback: "install"},
+	{key: "capk", fallback: "install"},
+	{key: "cert-manager", fallback: "install"},
+	{key: "osbuilder", fallback: "install"},
+	{key: "kairos-provider", fallback: "install"},
+	{key: "namespace-delete", def: 120 * time.Second},
+	{key: "crd", def: 60 * time.Second},
+	{key: "webhook", def: 120 * time.Second},
+	{key: "image-build", def: 1800 * time.Second},
+	{key: "image-upload", def: 300 * time.Second},
+	{key: "control-plane", def: 600 * time.Second},
+	{key: "workers", def: 1200 * time.Second},
+	{key: "cluster-delete", def: 120 * time.Second},
+}
+
+// setupInterrupt makes interruptCtx cancelled on SIGINT or SIGTERM.
+func setupInterrupt() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		fmt.Fprintln(os.Stderr, "Interrupted, stopping...")
+		stop()
+	}()
+	interruptCtx = ctx
+}
+
+func addTimeoutFlags(fs *pflag.FlagSet) {
+	fs.Float64("timeout-multiplier", 1, "Multiplies every wait timeout, e.g. 2 on slow CI runners (can also be set via KUBEVIRT_ENV_TIMEOUT_MULTIPLIER env var)")
+	viper.BindPFlag("timeouts.multiplier", fs.Lookup("timeout-multiplier"))
+	viper.BindEnv("timeouts.multiplier", "KUBEVIRT_ENV_TIMEOUT_MULTIPLIER")
+}
+
+func findWaitTimeout(key string) (waitTimeout, bool) {
+	for _, t := range waitTimeouts {
+		if t.key == key {
+			return t, true
+		}
+	}
+	return waitTimeout{}, false
+}
+
+// validateTimeouts checks the timeouts set in the config file and the
+// multiplier.
+func validateTimeouts() error {
+	if m := viper.GetFloat64("timeouts.multiplier"); m <= 0 {
+		return fmt.Errorf("invalid timeout multiplier %v: must be positive", m)
+	}
+	for _, t := range waitTimeouts {
+		key := "timeouts." + t.key
+		if !viper.IsSet(key) {
+			continue
+		}
+		if d := viper.GetDuration(key); d <= 0 {
+			return fmt.Errorf("invalid %s: must be a positive duration such as 5m", key)
+		}
+	}
+	return nil
+}
+
+// getTimeout returns the timeout of a wait scaled by the multiplier.
+func getTimeout(key string) time.Duration {
+	return time.Duration(float64(baseTimeout(key)) * viper.GetFloat64("timeouts.multiplier"))
+}
+
+func baseTimeout(key string) time.Duration {
+	t, ok := findWaitTimeout(key)
+	if !ok {
+		panic(fmt.Sprintf("unknown timeout %q", key))
+	}
+	switch {
+	case viper.IsSet("timeouts." + key):
+		return viper.GetDuration("timeouts." + key)
+	case t.fallback != "":
+		return baseTimeout(t.fallback)
+	}
+	return t.def
+}
+
+// waitContext returns the context of a wait bounded by the timeout of key,
+// also cancelled on interrupt.
+func waitContext(key string) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(interruptCtx, getTimeout(key))
+}
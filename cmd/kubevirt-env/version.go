@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kairos-io/kairos-capi/internal/version"
+)
+
+func newVersionCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print version information",
+		Long:  "Print the kubevirt-env version, git commit, Go version and CAPI contract version",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info := version.Get()
+			switch output {
+			case "":
+				fmt.Println(info)
+			case "json":
+				data, err := json.MarshalIndent(info, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to encode version: %w", err)
+				}
+				fmt.Println(string(data))
+			default:
+				return fmt.Errorf("unsupported output format %q (supported: json)", output)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format (json)")
+	return cmd
+}
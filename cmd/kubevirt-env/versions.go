@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// latestVersion selects the latest release of components that support it.
+const latestVersion = "latest"
+
+var versionPattern = regexp.MustCompile(`^v\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?$`)
+
+// componentVersion is the version of an installed component, set with a
+// flag, an environment variable or the versions section of the config file.
+type componentVersion struct {
+	// key is the config key
+	key          string
+	flag         string
+	env          string
+	defaultValue string
+	// allowLatest accepts "latest" besides a vX.Y.Z version
+	allowLatest bool
+	component   string
+}
+
+var componentVersions = []componentVersion{
+	{key: "versions.calico", flag: "calico-version", env: "CALICO_VERSION", defaultValue: "v3.29.1", component: "Calico"},
+	{key: "versions.cdi", flag: "cdi-version", env: "CDI_VERSION", defaultValue: latestVersion, allowLatest: true, component: "CDI"},
+	{key: "versions.kubevirt", flag: "kubevirt-version", env: "KUBEVIRT_VERSION", defaultValue: "v1.3.0", allowLatest: true, component: "KubeVirt"},
+	{key: "versions.capi", flag: "capi-version", env: "CAPI_VERSION", defaultValue: latestVersion, allowLatest: true, component: "Cluster API"},
+	{key: "versions.capk", flag: "capk-version", env: "CAPK_VERSION", defaultValue: latestVersion, allowLatest: true, component: "CAPK"},
+	{key: "versions.cert-manager", flag: "cert-manager-version", env: "CERT_MANAGER_VERSION", defaultValue: "v1.16.2", allowLatest: true, component: "cert-manager"},
+}
+
+// addComponentVersionFlags registers a flag per component version and binds
+// it, and its environment variable, to viper.
+func addComponentVersionFlags(fs *pflag.FlagSet) {
+	for _, v := range componentVersions {
+		usage := fmt.Sprintf("%s version (can also be set via %s env var)", v.component, v.env)
+		if v.allowLatest {
+			usage = fmt.Sprintf("%s version or %q (can also be set via %s env var)", v.component, latestVersion, v.env)
+		}
+		fs.String(v.flag, v.defaultValue, usage)
+		viper.BindPFlag(v.key, fs.Lookup(v.flag))
+		viper.BindEnv(v.key, v.env)
+	}
+}
+
+// validateComponentVersions checks the format of every component version.
+func validateComponentVersions() error {
+	for _, v := range componentVersions {
+		version := getComponentVersion(v.key)
+		if version == latestVersion && v.allowLatest {
+			continue
+		}
+		if !versionPattern.MatchString(version) {
+			if v.allowLatest {
+				return fmt.Errorf("invalid --%s %q: must be %q or a version such as v1.2.3", v.flag, version, latestVersion)
+			}
+			return fmt.Errorf("invalid --%s %q: must be a version such as v1.2.3", v.flag, version)
+		}
+	}
+	return nil
+}
+
+func getComponentVersion(key string) string {
+	return strings.TrimSpace(viper.GetString(key))
+}
+
+func getCalicoVersion() string {
+	return getComponentVersion("versions.calico")
+}
+
+func getCdiVersion() string {
+	return getComponentVersion("versions.cdi")
+}
+
+func getKubevirtVersion() string {
+	return getComponentVersion("versions.kubevirt")
+}
+
+func getCapiVersion() string {
+	return getComponentVersion("versions.capi")
+}
+
+func getCapkVersion() string {
+	return getComponentVersion("versions.capk")
+}
+
+func getCertManagerVersion() string {
+	return getComponentVersion("versions.cert-manager")
+}
+
+// githubReleaseAssetURL returns the download URL of asset in the release of
+// repo at version, or in its latest release.
+func githubReleaseAssetURL(repo, version, asset string) string {
+	if version == latestVersion {
+		return fmt.Sprintf("https://github.com/%s/releases/latest/download/%s", repo, asset)
+	}
+	return fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", repo, version, asset)
+}
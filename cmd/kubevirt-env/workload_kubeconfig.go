@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/spf13/cobra"
+)
+
+// workloadKubeconfigOptions select the workload cluster kubeconfig to write.
+type workloadKubeconfigOptions struct {
+	cluster   string
+	namespace string
+	// endpoint is how the API server is reached from this machine: auto,
+	// lb, nodeport or none to keep the server of the secret
+	endpoint string
+	output   string
+}
+
+func newGetKubeconfigCmd() *cobra.Command {
+	opts := workloadKubeconfigOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "get-kubeconfig",
+		Short: "Write the kubeconfig of a workload cluster",
+		Long: `Write the kubeconfig of a workload cluster from its CAPI <cluster>-kubeconfig secret to the work directory.
+
+The server is rewritten to the <cluster>-control-plane-lb Service, so kubectl works from this machine:
+  auto      the LoadBalancer address if it has one, otherwise the NodePort on a cluster node
+  lb        the LoadBalancer address
+  nodeport  the NodePort on a cluster node
+  none      keep the server of the secret`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			switch opts.endpoint {
+			case "auto", "lb", "nodeport", "none":
+				return nil
+			default:
+				return fmt.Errorf("invalid --endpoint %q: must be auto, lb, nodeport or none", opts.endpoint)
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientset, err := getKubeClient()
+			if err != nil {
+				return err
+			}
+			path, err := writeWorkloadKubeconfig(interruptCtx, clientset, opts)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Use it with: kubectl --kubeconfig %s get nodes\n", path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.cluster, "cluster", clusterName, "Name of the workload cluster")
+	cmd.Flags().StringVar(&opts.namespace, "namespace", clusterNamespace, "Namespace of the workload cluster")
+	cmd.Flags().StringVar(&opts.endpoint, "endpoint", "auto", "API server endpoint to write: auto, lb, nodeport or none")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Path of the kubeconfig (default <work dir>/<cluster>.kubeconfig)")
+	return cmd
+}
+
+// writeWorkloadKubeconfig writes the kubeconfig of a workload cluster with its
+// server rewritten as opts.endpoint selects, and returns its path.
+func writeWorkloadKubeconfig(ctx context.Context, clientset kubernetes.Interface, opts workloadKubeconfigOptions) (string, error) {
+	secretName := opts.cluster + "-kubeconfig"
+	secret, err := clientset.CoreV1().Secrets(opts.namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", fmt.Errorf("kubeconfig secret %s/%s not found, is the control plane of %s initialized?", opts.namespace, secretName, opts.cluster)
+		}
+		return "", fmt.Errorf("failed to get kubeconfig secret: %w", err)
+	}
+	data := secret.Data["value"]
+	if len(data) == 0 {
+		return "", fmt.Errorf("kubeconfig secret %s/%s has no value", opts.namespace, secretName)
+	}
+
+	kubeconfig, err := clientcmd.Load(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse kubeconfig secret %s/%s: %w", opts.namespace, secretName, err)
+	}
+
+	if opts.endpoint != "none" {
+		server, err := workloadAPIServer(ctx, clientset, opts)
+		if err != nil {
+			return "", err
+		}
+		for name, cluster := range kubeconfig.Clusters {
+			original, err := url.Parse(cluster.Server)
+			if err != nil {
+				return "", fmt.Errorf("invalid server %q of cluster %s in kubeconfig: %w", cluster.Server, name, err)
+			}
+			// The certificate of the API server is only valid for the
+			// original address
+			if cluster.TLSServerName == "" && original.Hostname() != "" {
+				cluster.TLSServerName = original.Hostname()
+			}
+			cluster.Server = server
+		}
+		fmt.Printf("API server: %s\n", server)
+	}
+
+	path := opts.output
+	if path == "" {
+		path = filepath.Join(getWorkDir(), opts.cluster+".kubeconfig")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create kubeconfig directory: %w", err)
+	}
+	if err := clientcmd.WriteToFile(*kubeconfig, path); err != nil {
+		return "", fmt.Errorf("failed to write workload kubeconfig: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		return "", fmt.Errorf("failed to write workload kubeconfig: %w", err)
+	}
+
+	fmt.Printf("✓ Workload kubeconfig saved to %s\n", path)
+	return path, nil
+}
+
+// workloadAPIServer returns the URL of the API server of a workload cluster
+// through its control-plane LoadBalancer Service.
+func workloadAPIServer(ctx context.Context, clientset kubernetes.Interface, opts workloadKubeconfigOptions) (string, error) {
+	serviceName := opts.cluster + "-control-plane-lb"
+	svc, err := clientset.CoreV1().Services(opts.namespace).Get(ctx, serviceName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get control-plane Service %s/%s: %w", opts.namespace, serviceName, err)
+	}
+	if len(svc.Spec.Ports) == 0 {
+		return "", fmt.Errorf("control-plane Service %s/%s has no ports", opts.namespace, serviceName)
+	}
+	port := svc.Spec.Ports[0]
+	for _, p := range svc.Spec.Ports {
+		if p.Port == 6443 {
+			port = p
+			break
+		}
+	}
+
+	if opts.endpoint == "auto" || opts.endpoint == "lb" {
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			host := ingress.IP
+			if host == "" {
+				host = ingress.Hostname
+			}
+			if host != "" {
+				return "https://" + net.JoinHostPort(host, strconv.Itoa(int(port.Port))), nil
+			}
+		}
+		if opts.endpoint == "lb" {
+			return "", fmt.Errorf("control-plane Service %s/%s has no LoadBalancer address yet", opts.namespace, serviceName)
+		}
+	}
+
+	if port.NodePort == 0 {
+		return "", fmt.Errorf("control-plane Service %s/%s has no NodePort", opts.namespace, serviceName)
+	}
+	nodeIP, err := clusterNodeIP(ctx, clientset)
+	if err != nil {
+		return "", err
+	}
+	return "https://" + net.JoinHostPort(nodeIP, strconv.Itoa(int(port.NodePort))), nil
+}
+
+// clusterNodeIP returns the address of a node of the local cluster, which is
+// reachable from this machine.
+func clusterNodeIP(ctx context.Context, clientset kubernetes.Interface) (string, error) {
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list nodes: %w", err)
+	}
+	for _, node := range nodes.Items {
+		for _, addr := range node.Status.Addresses {
+			if addr.Type == corev1.NodeInternalIP {
+				return addr.Address, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("could not determine node IP")
+}
@@ -4,10 +4,13 @@ Add contract version labels to Cluster API CRDs.
 
 This script adds the required CAPI contract version labels to CRD metadata:
 - cluster.x-k8s.io/provider: kairos
+- cluster.x-k8s.io/v1beta1: v1beta2
 - cluster.x-k8s.io/v1beta2: v1beta2
+- clusterctl.cluster.x-k8s.io: ""
 
 These labels are required by Cluster API for provider discovery and contract version
-compatibility checking. See: https://cluster-api.sigs.k8s.io/developer/providers/contracts/overview.html#api-version-labels
+compatibility checking. The clusterctl label lets `clusterctl move` discover the
+types even when the provider was not installed through clusterctl. See: https://cluster-api.sigs.k8s.io/developer/providers/contracts/overview.html#api-version-labels
 
 The script is idempotent - running it multiple times produces the same result.
 
@@ -92,7 +95,9 @@ def add_labels_to_crd(filepath):
     # Add/update contract version labels (idempotent operation)
     required_labels = {
         'cluster.x-k8s.io/provider': 'kairos',
-        'cluster.x-k8s.io/v1beta2': 'v1beta2'
+        'cluster.x-k8s.io/v1beta1': 'v1beta2',
+        'cluster.x-k8s.io/v1beta2': 'v1beta2',
+        'clusterctl.cluster.x-k8s.io': ''
     }
     metadata['labels'].update(required_labels)
     
@@ -48,6 +48,16 @@ kubectl patch validatingwebhookconfiguration "${VALIDATING_WEBHOOK}" \
   exit 1
 }
 
+echo "Patching conversion webhooks of the CRDs..."
+for crd in kairosconfigs.bootstrap.cluster.x-k8s.io kairosconfigtemplates.bootstrap.cluster.x-k8s.io kairoscontrolplanes.controlplane.cluster.x-k8s.io kairoscontrolplanetemplates.controlplane.cluster.x-k8s.io; do
+  kubectl patch crd "${crd}" \
+    --type='json' \
+    -p="[{\"op\": \"add\", \"path\": \"/spec/conversion/webhook/clientConfig/caBundle\", \"value\": \"${CA_BUNDLE}\"}]" || {
+    echo "ERROR: Failed to patch CRD ${crd}"
+    exit 1
+  }
+done
+
 echo "Successfully injected CA bundle into webhook configurations"
 echo "Restarting controller to pick up changes..."
 kubectl rollout restart deployment/kairos-capi-controller-manager -n "${NAMESPACE}" || true
@@ -0,0 +1,47 @@
+#!/usr/bin/env bash
+# Generates the typed clientset, listers and informers in pkg/client from the
+# v1beta2 API types. Run through `make generate-client`.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+ROOT="$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)"
+cd "${ROOT}"
+
+MODULE="github.com/kairos-io/kairos-capi"
+OUTPUT_PKG="${MODULE}/pkg/client"
+OUTPUT_DIR="pkg/client"
+BOILERPLATE="hack/boilerplate.go.txt"
+INPUTS=(
+  "${MODULE}/api/bootstrap/v1beta2"
+  "${MODULE}/api/controlplane/v1beta2"
+)
+
+CLIENT_GEN="${CLIENT_GEN:-client-gen}"
+LISTER_GEN="${LISTER_GEN:-lister-gen}"
+INFORMER_GEN="${INFORMER_GEN:-informer-gen}"
+
+rm -rf "${OUTPUT_DIR}/clientset" "${OUTPUT_DIR}/listers" "${OUTPUT_DIR}/informers"
+
+"${CLIENT_GEN}" \
+  --clientset-name versioned \
+  --input-base "" \
+  --input "$(IFS=,; echo "${INPUTS[*]}")" \
+  --output-pkg "${OUTPUT_PKG}/clientset" \
+  --output-dir "${OUTPUT_DIR}/clientset" \
+  --go-header-file "${BOILERPLATE}"
+
+"${LISTER_GEN}" \
+  --output-pkg "${OUTPUT_PKG}/listers" \
+  --output-dir "${OUTPUT_DIR}/listers" \
+  --go-header-file "${BOILERPLATE}" \
+  "${INPUTS[@]}"
+
+"${INFORMER_GEN}" \
+  --versioned-clientset-package "${OUTPUT_PKG}/clientset/versioned" \
+  --listers-package "${OUTPUT_PKG}/listers" \
+  --output-pkg "${OUTPUT_PKG}/informers" \
+  --output-dir "${OUTPUT_DIR}/informers" \
+  --go-header-file "${BOILERPLATE}" \
+  "${INPUTS[@]}"
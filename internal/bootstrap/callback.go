@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+)
+
+// kubeletKubeconfigs are the kubelet kubeconfigs of a distribution. Nodes
+// use them to annotate their own Node.
+var kubeletKubeconfigs = map[string]string{
+	"k0s": "/var/lib/k0s/kubelet.conf",
+	"k3s": "/var/lib/rancher/k3s/agent/kubelet.kubeconfig",
+}
+
+// CompletionCallbackScript returns the shell commands with which a node of
+// distribution reports that it completed bootstrap, for the end of the
+// post-bootstrap script. It returns "" without a callback. Failed reports are
+// retried for five minutes and never fail the script.
+func CompletionCallbackScript(distribution string, callback *bootstrapv1beta2.CompletionCallback, metadata *MachineMetadata) (string, error) {
+	if callback == nil || (!callback.NodeAnnotation && callback.URL == "") {
+		return "", nil
+	}
+	if distribution != "k3s" {
+		distribution = "k0s"
+	}
+
+	var b strings.Builder
+	b.WriteString("# Report bootstrap completion to Cluster API\n")
+	if callback.NodeAnnotation {
+		kubeconfig := kubeletKubeconfigs[distribution]
+		fmt.Fprintf(&b, `annotate_node() {
+  if [ ! -f %[1]s ]; then
+    echo "No kubelet on this node; not annotating the Node"
+    return 0
+  fi
+  local node
+  node="$(hostname | tr '[:upper:]' '[:lower:]')"
+  for i in $(seq 1 30); do
+    if %[2]s kubectl --kubeconfig %[1]s annotate --overwrite node "${node}" "%[3]s=$(date -u +%%Y-%%m-%%dT%%H:%%M:%%SZ)"; then
+      return 0
+    fi
+    sleep 10
+  done
+  echo "WARN: failed to annotate node ${node} with bootstrap completion"
+}
+annotate_node || true
+`, kubeconfig, distribution, bootstrapv1beta2.BootstrapExecutedAnnotation)
+	}
+	if callback.URL != "" {
+		if metadata == nil {
+			metadata = &MachineMetadata{}
+		}
+		body, err := json.Marshal(metadata)
+		if err != nil {
+			return "", fmt.Errorf("failed to render completion callback body: %w", err)
+		}
+		fmt.Fprintf(&b, `post_completion() {
+  for i in $(seq 1 30); do
+    if curl -fsS -X POST -H "Content-Type: application/json" --data %[1]s %[2]s; then
+      return 0
+    fi
+    sleep 10
+  done
+  echo "WARN: failed to post bootstrap completion"
+}
+post_completion || true
+`, shellQuote(string(body)), shellQuote(callback.URL))
+	}
+	return b.String(), nil
+}
+
+// shellQuote quotes s as a single shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+)
+
+func TestCompletionCallbackScript(t *testing.T) {
+	g := NewWithT(t)
+
+	script, err := CompletionCallbackScript("k0s", nil, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(script).To(BeEmpty())
+
+	script, err = CompletionCallbackScript("k3s", &bootstrapv1beta2.CompletionCallback{NodeAnnotation: true}, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(script).To(ContainSubstring("k3s kubectl --kubeconfig /var/lib/rancher/k3s/agent/kubelet.kubeconfig annotate --overwrite node"))
+	g.Expect(script).To(ContainSubstring(`"bootstrap.cluster.x-k8s.io/bootstrap-executed=$(date -u +%Y-%m-%dT%H:%M:%SZ)"`))
+	g.Expect(script).NotTo(ContainSubstring("curl"))
+
+	metadata := &MachineMetadata{ClusterName: "prod", MachineName: "prod-md-0", Namespace: "default", Role: "worker", FailureDomain: "rack'1"}
+	script, err = CompletionCallbackScript("k0s", &bootstrapv1beta2.CompletionCallback{URL: "https://hooks.example.com/done"}, metadata)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(script).NotTo(ContainSubstring("kubectl"))
+	g.Expect(script).To(ContainSubstring(`--data '{"clusterName":"prod","machineName":"prod-md-0","namespace":"default","role":"worker","failureDomain":"rack'\''1"}' 'https://hooks.example.com/done'`))
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+// certificatePaths is where a distribution expects a certificate and its key
+type certificatePaths struct {
+	cert string
+	key  string
+}
+
+// k0sCertificatePaths are the k0s PKI files of the Cluster API certificates.
+// k0s only generates the files that are missing on first start.
+var k0sCertificatePaths = map[secret.Purpose][]certificatePaths{
+	secret.ClusterCA:      {{cert: "/var/lib/k0s/pki/ca.crt", key: "/var/lib/k0s/pki/ca.key"}},
+	secret.EtcdCA:         {{cert: "/var/lib/k0s/pki/etcd/ca.crt", key: "/var/lib/k0s/pki/etcd/ca.key"}},
+	secret.FrontProxyCA:   {{cert: "/var/lib/k0s/pki/front-proxy-ca.crt", key: "/var/lib/k0s/pki/front-proxy-ca.key"}},
+	secret.ServiceAccount: {{cert: "/var/lib/k0s/pki/sa.pub", key: "/var/lib/k0s/pki/sa.key"}},
+}
+
+// k3sCertificatePaths are the k3s server TLS files of the Cluster API
+// certificates. k3s signs server and client certificates with separate CAs
+// and etcd peer and server certificates likewise; both use the same CA here.
+var k3sCertificatePaths = map[secret.Purpose][]certificatePaths{
+	secret.ClusterCA: {
+		{cert: "/var/lib/rancher/k3s/server/tls/server-ca.crt", key: "/var/lib/rancher/k3s/server/tls/server-ca.key"},
+		{cert: "/var/lib/rancher/k3s/server/tls/client-ca.crt", key: "/var/lib/rancher/k3s/server/tls/client-ca.key"},
+	},
+	secret.EtcdCA: {
+		{cert: "/var/lib/rancher/k3s/server/tls/etcd/server-ca.crt", key: "/var/lib/rancher/k3s/server/tls/etcd/server-ca.key"},
+		{cert: "/var/lib/rancher/k3s/server/tls/etcd/peer-ca.crt", key: "/var/lib/rancher/k3s/server/tls/etcd/peer-ca.key"},
+	},
+	secret.FrontProxyCA: {
+		{cert: "/var/lib/rancher/k3s/server/tls/request-header-ca.crt", key: "/var/lib/rancher/k3s/server/tls/request-header-ca.key"},
+	},
+	// k3s derives the public key from service.key
+	secret.ServiceAccount: {{key: "/var/lib/rancher/k3s/server/tls/service.key"}},
+}
+
+// ClusterCertificates returns the certificates Cluster API expects in the
+// <cluster>-ca, <cluster>-etcd, <cluster>-proxy and <cluster>-sa Secrets.
+func ClusterCertificates() secret.Certificates {
+	return secret.NewCertificatesForInitialControlPlane(nil)
+}
+
+// CertificateFiles returns the files that install certificates on a control
+// plane node of distribution before k0s or k3s first starts. Certificates
+// without key pair are skipped.
+func CertificateFiles(distribution string, certificates secret.Certificates) []File {
+	paths := k0sCertificatePaths
+	if distribution == "k3s" {
+		paths = k3sCertificatePaths
+	}
+
+	var files []File
+	for _, certificate := range certificates {
+		if certificate.KeyPair == nil {
+			continue
+		}
+		for _, p := range paths[certificate.Purpose] {
+			if p.cert != "" && len(certificate.KeyPair.Cert) > 0 {
+				files = append(files, File{Path: p.cert, Content: string(certificate.KeyPair.Cert), Permissions: "0644", Owner: "root:root"})
+			}
+			if p.key != "" && len(certificate.KeyPair.Key) > 0 {
+				files = append(files, File{Path: p.key, Content: string(certificate.KeyPair.Key), Permissions: "0600", Owner: "root:root"})
+			}
+		}
+	}
+	return files
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+func TestCertificateFiles(t *testing.T) {
+	g := NewWithT(t)
+
+	certificates := ClusterCertificates()
+	g.Expect(certificates).To(HaveLen(4))
+	// Certificates that were neither looked up nor generated are skipped
+	g.Expect(CertificateFiles("k0s", certificates)).To(BeEmpty())
+	g.Expect(certificates.Generate()).To(Succeed())
+
+	paths := func(files []File) map[string]string {
+		byPath := map[string]string{}
+		for _, f := range files {
+			byPath[f.Path] = f.Permissions
+		}
+		return byPath
+	}
+
+	k0s := paths(CertificateFiles("k0s", certificates))
+	g.Expect(k0s).To(HaveLen(8))
+	g.Expect(k0s).To(HaveKeyWithValue("/var/lib/k0s/pki/ca.crt", "0644"))
+	g.Expect(k0s).To(HaveKeyWithValue("/var/lib/k0s/pki/ca.key", "0600"))
+	g.Expect(k0s).To(HaveKey("/var/lib/k0s/pki/etcd/ca.crt"))
+	g.Expect(k0s).To(HaveKey("/var/lib/k0s/pki/front-proxy-ca.key"))
+	g.Expect(k0s).To(HaveKey("/var/lib/k0s/pki/sa.pub"))
+
+	k3s := paths(CertificateFiles("k3s", certificates))
+	g.Expect(k3s).To(HaveLen(11))
+	g.Expect(k3s).To(HaveKey("/var/lib/rancher/k3s/server/tls/server-ca.crt"))
+	g.Expect(k3s).To(HaveKey("/var/lib/rancher/k3s/server/tls/client-ca.key"))
+	g.Expect(k3s).To(HaveKey("/var/lib/rancher/k3s/server/tls/etcd/peer-ca.crt"))
+	g.Expect(k3s).To(HaveKey("/var/lib/rancher/k3s/server/tls/request-header-ca.crt"))
+	g.Expect(k3s).To(HaveKeyWithValue("/var/lib/rancher/k3s/server/tls/service.key", "0600"))
+	g.Expect(k3s).NotTo(HaveKey("/var/lib/rancher/k3s/server/tls/service.pub"))
+
+	ca := certificates.GetByPurpose(secret.ClusterCA)
+	for _, f := range CertificateFiles("k3s", certificates) {
+		if f.Path == "/var/lib/rancher/k3s/server/tls/client-ca.crt" {
+			g.Expect(f.Content).To(Equal(string(ca.KeyPair.Cert)))
+		}
+	}
+}
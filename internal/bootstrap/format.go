@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+)
+
+const (
+	// IgnitionCloudConfigPath is where the Ignition wrapper writes the
+	// cloud-config. Kairos reads the cloud-config files in /oem at boot.
+	IgnitionCloudConfigPath = "/oem/90_kairos_capi.yaml"
+
+	// ignitionVersion is the Ignition config spec version of the wrapper
+	ignitionVersion = "3.3.0"
+
+	// ignitionDataURLPrefix prefixes the base64 contents of the wrapped file
+	ignitionDataURLPrefix = "data:;base64,"
+)
+
+// Keys of the bootstrap data Secret
+const (
+	// DataValueKey holds the bootstrap data Cluster API passes to the infrastructure provider
+	DataValueKey = "value"
+	// DataFormatKey holds the Cluster API bootstrap data format, cloud-config or ignition
+	DataFormatKey = "format"
+	// DataUserdataKey holds the NoCloud user-data of iso-userdata bootstrap data
+	DataUserdataKey = "userdata"
+	// DataMetadataKey holds the NoCloud meta-data of iso-userdata bootstrap data
+	DataMetadataKey = "metadata"
+)
+
+type ignitionConfig struct {
+	Ignition struct {
+		Version string `json:"version"`
+	} `json:"ignition"`
+	Storage struct {
+		Files []ignitionFile `json:"files"`
+	} `json:"storage"`
+}
+
+type ignitionFile struct {
+	Path      string `json:"path"`
+	Mode      int    `json:"mode"`
+	Overwrite bool   `json:"overwrite"`
+	Contents  struct {
+		Source string `json:"source"`
+	} `json:"contents"`
+}
+
+// BootstrapData encodes cloudConfig as the data of a bootstrap data Secret in
+// format. instanceID identifies the node in NoCloud meta-data.
+func BootstrapData(format bootstrapv1beta2.Format, cloudConfig, instanceID string) (map[string][]byte, error) {
+	switch format {
+	case "", bootstrapv1beta2.CloudConfigFormat:
+		return map[string][]byte{
+			DataValueKey:  []byte(cloudConfig),
+			DataFormatKey: []byte("cloud-config"),
+		}, nil
+	case bootstrapv1beta2.ISOUserdataFormat:
+		return map[string][]byte{
+			DataValueKey:    []byte(cloudConfig),
+			DataFormatKey:   []byte("cloud-config"),
+			DataUserdataKey: []byte(cloudConfig),
+			DataMetadataKey: []byte(fmt.Sprintf("instance-id: %s\n", instanceID)),
+		}, nil
+	case bootstrapv1beta2.IgnitionWrappedFormat:
+		config := ignitionConfig{}
+		config.Ignition.Version = ignitionVersion
+		file := ignitionFile{Path: IgnitionCloudConfigPath, Mode: 0o600, Overwrite: true}
+		file.Contents.Source = ignitionDataURLPrefix + base64.StdEncoding.EncodeToString([]byte(cloudConfig))
+		config.Storage.Files = []ignitionFile{file}
+		value, err := json.Marshal(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal ignition config: %w", err)
+		}
+		return map[string][]byte{
+			DataValueKey:  value,
+			DataFormatKey: []byte("ignition"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported bootstrap data format %q", format)
+	}
+}
+
+// CloudConfigFromBootstrapData returns the cloud-config encoded in the data of
+// a bootstrap data Secret by BootstrapData.
+func CloudConfigFromBootstrapData(data map[string][]byte) (string, error) {
+	value, ok := data[DataValueKey]
+	if !ok {
+		return "", fmt.Errorf("bootstrap data has no %s key", DataValueKey)
+	}
+	if string(data[DataFormatKey]) != "ignition" {
+		return string(value), nil
+	}
+
+	var config ignitionConfig
+	if err := json.Unmarshal(value, &config); err != nil {
+		return "", fmt.Errorf("failed to parse ignition config: %w", err)
+	}
+	for _, file := range config.Storage.Files {
+		if file.Path != IgnitionCloudConfigPath {
+			continue
+		}
+		encoded, ok := strings.CutPrefix(file.Contents.Source, ignitionDataURLPrefix)
+		if !ok {
+			return "", fmt.Errorf("ignition file %s has no base64 data URL", file.Path)
+		}
+		cloudConfig, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode ignition file %s: %w", file.Path, err)
+		}
+		return string(cloudConfig), nil
+	}
+	return "", fmt.Errorf("ignition config does not write %s", IgnitionCloudConfigPath)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+)
+
+func TestBootstrapData(t *testing.T) {
+	g := NewWithT(t)
+
+	const cloudConfig = "#cloud-config\nhostname: node-0\n"
+
+	data, err := BootstrapData("", cloudConfig, "machine-0")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(data).To(Equal(map[string][]byte{
+		DataValueKey:  []byte(cloudConfig),
+		DataFormatKey: []byte("cloud-config"),
+	}))
+
+	data, err = BootstrapData(bootstrapv1beta2.ISOUserdataFormat, cloudConfig, "machine-0")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(data).To(HaveKeyWithValue(DataValueKey, []byte(cloudConfig)))
+	g.Expect(data).To(HaveKeyWithValue(DataUserdataKey, []byte(cloudConfig)))
+	g.Expect(data).To(HaveKeyWithValue(DataMetadataKey, []byte("instance-id: machine-0\n")))
+	g.Expect(data).To(HaveKeyWithValue(DataFormatKey, []byte("cloud-config")))
+
+	data, err = BootstrapData(bootstrapv1beta2.IgnitionWrappedFormat, cloudConfig, "machine-0")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(data).To(HaveKeyWithValue(DataFormatKey, []byte("ignition")))
+	var ignition map[string]interface{}
+	g.Expect(json.Unmarshal(data[DataValueKey], &ignition)).To(Succeed())
+	g.Expect(ignition).To(HaveKeyWithValue("ignition", HaveKeyWithValue("version", "3.3.0")))
+	g.Expect(string(data[DataValueKey])).To(ContainSubstring(`"path":"/oem/90_kairos_capi.yaml"`))
+
+	_, err = BootstrapData("yaml", cloudConfig, "machine-0")
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestCloudConfigFromBootstrapData(t *testing.T) {
+	g := NewWithT(t)
+
+	const cloudConfig = "#cloud-config\nhostname: node-0\n"
+	for _, format := range []bootstrapv1beta2.Format{bootstrapv1beta2.CloudConfigFormat, bootstrapv1beta2.ISOUserdataFormat, bootstrapv1beta2.IgnitionWrappedFormat} {
+		data, err := BootstrapData(format, cloudConfig, "machine-0")
+		g.Expect(err).NotTo(HaveOccurred())
+		decoded, err := CloudConfigFromBootstrapData(data)
+		g.Expect(err).NotTo(HaveOccurred(), string(format))
+		g.Expect(decoded).To(Equal(cloudConfig), string(format))
+	}
+
+	// Secrets written before the format key existed
+	decoded, err := CloudConfigFromBootstrapData(map[string][]byte{DataValueKey: []byte(cloudConfig)})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(decoded).To(Equal(cloudConfig))
+
+	_, err = CloudConfigFromBootstrapData(map[string][]byte{})
+	g.Expect(err).To(HaveOccurred())
+	_, err = CloudConfigFromBootstrapData(map[string][]byte{DataValueKey: []byte("{}"), DataFormatKey: []byte("ignition")})
+	g.Expect(err).To(HaveOccurred())
+}
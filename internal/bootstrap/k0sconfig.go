@@ -0,0 +1,238 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+)
+
+const (
+	k0sConfigAPIVersion = "k0s.k0sproject.io/v1beta1"
+	k0sConfigKind       = "ClusterConfig"
+)
+
+// RenderK0sClusterConfig renders the k0s ClusterConfig written to /etc/k0s/k0s.yaml.
+// cfg.Config is the base; the structured fields of cfg, podCIDR, serviceCIDR,
+// extraSANs and apiServerArgs, set as spec.api.extraArgs, are set on top of it.
+func RenderK0sClusterConfig(cfg *bootstrapv1beta2.K0sConfig, podCIDR, serviceCIDR string, extraSANs []string, apiServerArgs map[string]string) (string, error) {
+	clusterConfig := map[string]interface{}{}
+	if cfg.Config != nil && len(cfg.Config.Raw) > 0 {
+		if err := yaml.Unmarshal(cfg.Config.Raw, &clusterConfig); err != nil {
+			return "", fmt.Errorf("failed to parse k0s config: %w", err)
+		}
+		if clusterConfig == nil {
+			clusterConfig = map[string]interface{}{}
+		}
+	}
+
+	if kind, ok := clusterConfig["kind"]; ok && kind != k0sConfigKind {
+		return "", fmt.Errorf("k0s config must be a %s, got kind %v", k0sConfigKind, kind)
+	}
+	clusterConfig["kind"] = k0sConfigKind
+	if _, ok := clusterConfig["apiVersion"]; !ok {
+		clusterConfig["apiVersion"] = k0sConfigAPIVersion
+	}
+	metadata, err := childMap(clusterConfig, "metadata")
+	if err != nil {
+		return "", err
+	}
+	if _, ok := metadata["name"]; !ok {
+		metadata["name"] = "k0s"
+	}
+
+	spec, err := childMap(clusterConfig, "spec")
+	if err != nil {
+		return "", err
+	}
+
+	sans := append(append([]string{}, cfg.APISANs...), extraSANs...)
+	if len(sans) > 0 {
+		api, err := childMap(spec, "api")
+		if err != nil {
+			return "", err
+		}
+		existing, _ := api["sans"].([]interface{})
+		seen := map[string]bool{}
+		for _, san := range existing {
+			if s, ok := san.(string); ok {
+				seen[s] = true
+			}
+		}
+		for _, san := range sans {
+			if !seen[san] {
+				existing = append(existing, san)
+				seen[san] = true
+			}
+		}
+		api["sans"] = existing
+	}
+
+	if len(apiServerArgs) > 0 {
+		api, err := childMap(spec, "api")
+		if err != nil {
+			return "", err
+		}
+		extraArgs, err := childMap(api, "extraArgs")
+		if err != nil {
+			return "", err
+		}
+		for flag, value := range apiServerArgs {
+			extraArgs[flag] = value
+		}
+	}
+
+	if podCIDR != "" || serviceCIDR != "" || cfg.NetworkProvider != "" {
+		network, err := childMap(spec, "network")
+		if err != nil {
+			return "", err
+		}
+		if podCIDR != "" {
+			network["podCIDR"] = podCIDR
+		}
+		if serviceCIDR != "" {
+			network["serviceCIDR"] = serviceCIDR
+		}
+		if cfg.NetworkProvider != "" {
+			network["provider"] = cfg.NetworkProvider
+		}
+	}
+
+	if cfg.Storage != nil && (cfg.Storage.Type != "" || cfg.Storage.KineDataSource != "") {
+		storage, err := childMap(spec, "storage")
+		if err != nil {
+			return "", err
+		}
+		if cfg.Storage.Type != "" {
+			storage["type"] = cfg.Storage.Type
+		}
+		if cfg.Storage.KineDataSource != "" {
+			kine, err := childMap(storage, "kine")
+			if err != nil {
+				return "", err
+			}
+			kine["dataSource"] = cfg.Storage.KineDataSource
+		}
+	}
+
+	if cfg.Extensions != nil && cfg.Extensions.Helm != nil {
+		if err := mergeHelmExtension(spec, cfg.Extensions.Helm); err != nil {
+			return "", err
+		}
+	}
+
+	out, err := yaml.Marshal(clusterConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal k0s config: %w", err)
+	}
+	return string(out), nil
+}
+
+// childMap returns parent[key] as a map, creating it if it is missing
+func childMap(parent map[string]interface{}, key string) (map[string]interface{}, error) {
+	switch child := parent[key].(type) {
+	case map[string]interface{}:
+		return child, nil
+	case nil:
+		m := map[string]interface{}{}
+		parent[key] = m
+		return m, nil
+	default:
+		return nil, fmt.Errorf("k0s config field %q must be an object", key)
+	}
+}
+
+// mergeHelmExtension adds the repositories and charts of helm to
+// spec.extensions.helm. Entries replace those of the same name in the base
+// config.
+func mergeHelmExtension(spec map[string]interface{}, helm *bootstrapv1beta2.K0sHelmExtension) error {
+	if len(helm.Repositories) == 0 && len(helm.Charts) == 0 {
+		return nil
+	}
+	extensions, err := childMap(spec, "extensions")
+	if err != nil {
+		return err
+	}
+	helmConfig, err := childMap(extensions, "helm")
+	if err != nil {
+		return err
+	}
+
+	repositories := make([]map[string]interface{}, 0, len(helm.Repositories))
+	for _, repo := range helm.Repositories {
+		repositories = append(repositories, map[string]interface{}{"name": repo.Name, "url": repo.URL})
+	}
+	if err := mergeByName(helmConfig, "repositories", repositories); err != nil {
+		return err
+	}
+
+	charts := make([]map[string]interface{}, 0, len(helm.Charts))
+	for _, chart := range helm.Charts {
+		c := map[string]interface{}{
+			"name":      chart.Name,
+			"chartname": chart.ChartName,
+			"namespace": chart.Namespace,
+		}
+		if chart.Version != "" {
+			c["version"] = chart.Version
+		}
+		if chart.Values != "" {
+			c["values"] = chart.Values
+		}
+		if chart.Order != 0 {
+			c["order"] = chart.Order
+		}
+		charts = append(charts, c)
+	}
+	return mergeByName(helmConfig, "charts", charts)
+}
+
+// mergeByName merges items into the list parent[key], replacing the entries
+// with the same name
+func mergeByName(parent map[string]interface{}, key string, items []map[string]interface{}) error {
+	if len(items) == 0 {
+		return nil
+	}
+	var existing []interface{}
+	switch list := parent[key].(type) {
+	case []interface{}:
+		existing = list
+	case nil:
+	default:
+		return fmt.Errorf("k0s config field %q must be a list", key)
+	}
+
+	index := map[interface{}]int{}
+	for i, entry := range existing {
+		if m, ok := entry.(map[string]interface{}); ok {
+			index[m["name"]] = i
+		}
+	}
+	for _, item := range items {
+		if i, ok := index[item["name"]]; ok {
+			existing[i] = item
+			continue
+		}
+		index[item["name"]] = len(existing)
+		existing = append(existing, item)
+	}
+	parent[key] = existing
+	return nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+)
+
+func TestRenderK0sClusterConfig(t *testing.T) {
+	cfg := &bootstrapv1beta2.K0sConfig{
+		Config: &runtime.RawExtension{Raw: []byte(`{
+			"spec": {
+				"api": {"sans": ["api.example.com"], "extraArgs": {"audit-log-maxage": "30"}},
+				"network": {"podCIDR": "10.1.0.0/16", "kubeProxy": {"mode": "ipvs"}},
+				"extensions": {"helm": {"charts": [{"name": "metrics-server"}]}}
+			}
+		}`)},
+		APISANs:         []string{"10.0.0.10", "api.example.com"},
+		NetworkProvider: "calico",
+		Storage:         &bootstrapv1beta2.K0sStorageConfig{Type: "kine", KineDataSource: "mysql://k0s@tcp(db:3306)/k0s"},
+	}
+
+	result, err := RenderK0sClusterConfig(cfg, "10.244.0.0/16", "", []string{"10.0.0.1"}, map[string]string{"oidc-client-id": "kubernetes"})
+	if err != nil {
+		t.Fatalf("Failed to render k0s config: %v", err)
+	}
+
+	var parsed struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			API struct {
+				SANs      []string          `json:"sans"`
+				ExtraArgs map[string]string `json:"extraArgs"`
+			} `json:"api"`
+			Network struct {
+				PodCIDR   string                 `json:"podCIDR"`
+				Provider  string                 `json:"provider"`
+				KubeProxy map[string]interface{} `json:"kubeProxy"`
+			} `json:"network"`
+			Storage struct {
+				Type string `json:"type"`
+				Kine struct {
+					DataSource string `json:"dataSource"`
+				} `json:"kine"`
+			} `json:"storage"`
+			Extensions map[string]interface{} `json:"extensions"`
+		} `json:"spec"`
+	}
+	if err := yaml.Unmarshal([]byte(result), &parsed); err != nil {
+		t.Fatalf("Rendered k0s config is not valid YAML: %v", err)
+	}
+
+	if parsed.APIVersion != "k0s.k0sproject.io/v1beta1" || parsed.Kind != "ClusterConfig" || parsed.Metadata.Name != "k0s" {
+		t.Errorf("Missing ClusterConfig defaults: %s", result)
+	}
+	if got := strings.Join(parsed.Spec.API.SANs, ","); got != "api.example.com,10.0.0.10,10.0.0.1" {
+		t.Errorf("Unexpected sans: %s", got)
+	}
+	if parsed.Spec.API.ExtraArgs["audit-log-maxage"] != "30" || parsed.Spec.API.ExtraArgs["oidc-client-id"] != "kubernetes" {
+		t.Errorf("Unexpected api extraArgs: %v", parsed.Spec.API.ExtraArgs)
+	}
+	if parsed.Spec.Network.PodCIDR != "10.244.0.0/16" || parsed.Spec.Network.Provider != "calico" {
+		t.Errorf("Unexpected network: %+v", parsed.Spec.Network)
+	}
+	if parsed.Spec.Network.KubeProxy["mode"] != "ipvs" || parsed.Spec.Extensions["helm"] == nil {
+		t.Errorf("Base config settings were not preserved: %s", result)
+	}
+	if parsed.Spec.Storage.Type != "kine" || parsed.Spec.Storage.Kine.DataSource != "mysql://k0s@tcp(db:3306)/k0s" {
+		t.Errorf("Unexpected storage: %+v", parsed.Spec.Storage)
+	}
+}
+
+func TestRenderK0sClusterConfig_HelmExtension(t *testing.T) {
+	cfg := &bootstrapv1beta2.K0sConfig{
+		Config: &runtime.RawExtension{Raw: []byte(`{
+			"spec": {"extensions": {"helm": {"charts": [
+				{"name": "metrics-server", "chartname": "stable/metrics-server", "namespace": "kube-system"},
+				{"name": "metallb", "chartname": "old/metallb", "namespace": "default"}
+			]}}}
+		}`)},
+		Extensions: &bootstrapv1beta2.K0sExtensions{Helm: &bootstrapv1beta2.K0sHelmExtension{
+			Repositories: []bootstrapv1beta2.K0sHelmRepository{{Name: "metallb", URL: "https://metallb.github.io/metallb"}},
+			Charts: []bootstrapv1beta2.K0sHelmChart{{
+				Name:      "metallb",
+				ChartName: "metallb/metallb",
+				Version:   "0.14.5",
+				Values:    "speaker:\n  enabled: true\n",
+				Namespace: "metallb-system",
+				Order:     1,
+			}},
+		}},
+	}
+
+	result, err := RenderK0sClusterConfig(cfg, "", "", nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to render k0s config: %v", err)
+	}
+
+	var parsed struct {
+		Spec struct {
+			Extensions struct {
+				Helm struct {
+					Repositories []map[string]interface{} `json:"repositories"`
+					Charts       []map[string]interface{} `json:"charts"`
+				} `json:"helm"`
+			} `json:"extensions"`
+		} `json:"spec"`
+	}
+	if err := yaml.Unmarshal([]byte(result), &parsed); err != nil {
+		t.Fatalf("Rendered k0s config is not valid YAML: %v", err)
+	}
+
+	helm := parsed.Spec.Extensions.Helm
+	if len(helm.Repositories) != 1 || helm.Repositories[0]["url"] != "https://metallb.github.io/metallb" {
+		t.Errorf("Unexpected repositories: %+v", helm.Repositories)
+	}
+	// The chart of the same name in the base config is replaced
+	if len(helm.Charts) != 2 || helm.Charts[0]["name"] != "metrics-server" {
+		t.Fatalf("Unexpected charts: %+v", helm.Charts)
+	}
+	metallb := helm.Charts[1]
+	if metallb["chartname"] != "metallb/metallb" || metallb["namespace"] != "metallb-system" ||
+		metallb["version"] != "0.14.5" || metallb["values"] != "speaker:\n  enabled: true\n" || metallb["order"] != float64(1) {
+		t.Errorf("Unexpected metallb chart: %+v", metallb)
+	}
+}
+
+func TestRenderK0sClusterConfig_Invalid(t *testing.T) {
+	for name, raw := range map[string]string{
+		"wrong kind":     `{"kind": "Cluster"}`,
+		"spec not a map": `{"spec": "nope"}`,
+		"not an object":  `["a"]`,
+	} {
+		cfg := &bootstrapv1beta2.K0sConfig{Config: &runtime.RawExtension{Raw: []byte(raw)}}
+		if _, err := RenderK0sClusterConfig(cfg, "", "", nil, nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
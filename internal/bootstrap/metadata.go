@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+// MachineMetadataPath is where nodes find the Cluster API metadata of their Machine
+const MachineMetadataPath = "/run/cluster-api/metadata.yaml"
+
+const (
+	// MachineRoleLabel is the node label holding the role of the Machine
+	MachineRoleLabel = "cluster.x-k8s.io/role"
+	// MachineFailureDomainLabel is the node label holding the failure domain of the Machine
+	MachineFailureDomainLabel = "cluster.x-k8s.io/failure-domain"
+)
+
+// MachineMetadata identifies the Machine a node was bootstrapped for
+type MachineMetadata struct {
+	ClusterName   string `json:"clusterName"`
+	MachineName   string `json:"machineName"`
+	Namespace     string `json:"namespace"`
+	Role          string `json:"role,omitempty"`
+	FailureDomain string `json:"failureDomain,omitempty"`
+}
+
+// YAML renders the metadata written to MachineMetadataPath
+func (m MachineMetadata) YAML() (string, error) {
+	out, err := yaml.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("failed to render machine metadata: %w", err)
+	}
+	return string(out), nil
+}
+
+// NodeLabels returns the metadata as "key=value" node labels sorted by key.
+// Values that are not valid label values, such as long failure domain names,
+// are left out.
+func (m MachineMetadata) NodeLabels() []string {
+	labels := map[string]string{
+		clusterv1.ClusterNameLabel:           m.ClusterName,
+		clusterv1.MachineAnnotation:          m.MachineName,
+		clusterv1.ClusterNamespaceAnnotation: m.Namespace,
+		MachineRoleLabel:                     m.Role,
+		MachineFailureDomainLabel:            m.FailureDomain,
+	}
+	var pairs []string
+	for key, value := range labels {
+		if value == "" || len(validation.IsValidLabelValue(value)) > 0 {
+			continue
+		}
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// MergeNodeLabels adds labels to the node-labels flag of the kubelet
+// arguments. Labels already set through args take precedence. args is not
+// modified.
+func MergeNodeLabels(args map[string]string, labels []string) map[string]string {
+	if len(labels) == 0 {
+		return args
+	}
+	merged := make(map[string]string, len(args)+1)
+	for key, value := range args {
+		merged[key] = value
+	}
+
+	var nodeLabels []string
+	existing := map[string]bool{}
+	if value := merged["node-labels"]; value != "" {
+		nodeLabels = strings.Split(value, ",")
+		for _, label := range nodeLabels {
+			key, _, _ := strings.Cut(label, "=")
+			existing[key] = true
+		}
+	}
+	for _, label := range labels {
+		key, _, _ := strings.Cut(label, "=")
+		if !existing[key] {
+			nodeLabels = append(nodeLabels, label)
+		}
+	}
+	merged["node-labels"] = strings.Join(nodeLabels, ",")
+	return merged
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMachineMetadata(t *testing.T) {
+	g := NewWithT(t)
+
+	metadata := MachineMetadata{
+		ClusterName:   "prod",
+		MachineName:   "prod-cp-x7k2p",
+		Namespace:     "default",
+		Role:          "control-plane",
+		FailureDomain: "a failure domain that is not a label value",
+	}
+	out, err := metadata.YAML()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(out).To(ContainSubstring("machineName: prod-cp-x7k2p\n"))
+	g.Expect(out).To(ContainSubstring("failureDomain: a failure domain that is not a label value\n"))
+
+	// The failure domain is not a valid label value and is left out
+	g.Expect(metadata.NodeLabels()).To(Equal([]string{
+		"cluster.x-k8s.io/cluster-name=prod",
+		"cluster.x-k8s.io/cluster-namespace=default",
+		"cluster.x-k8s.io/machine=prod-cp-x7k2p",
+		"cluster.x-k8s.io/role=control-plane",
+	}))
+}
+
+func TestMergeNodeLabels(t *testing.T) {
+	g := NewWithT(t)
+
+	labels := []string{"cluster.x-k8s.io/cluster-name=prod", "cluster.x-k8s.io/role=worker"}
+	g.Expect(MergeNodeLabels(nil, labels)).To(Equal(map[string]string{
+		"node-labels": "cluster.x-k8s.io/cluster-name=prod,cluster.x-k8s.io/role=worker",
+	}))
+
+	args := map[string]string{"node-labels": "cluster.x-k8s.io/role=gateway", "max-pods": "50"}
+	g.Expect(MergeNodeLabels(args, labels)).To(Equal(map[string]string{
+		"node-labels": "cluster.x-k8s.io/role=gateway,cluster.x-k8s.io/cluster-name=prod",
+		"max-pods":    "50",
+	}))
+	g.Expect(args["node-labels"]).To(Equal("cluster.x-k8s.io/role=gateway"))
+	g.Expect(MergeNodeLabels(args, nil)).To(Equal(args))
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"fmt"
+	"strings"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+)
+
+const networkdDir = "/etc/systemd/network"
+
+// NetworkdFiles renders spec.network as systemd-networkd units. Bonds and VLANs
+// get a .netdev unit; bond members and VLAN parents are attached in the .network
+// unit of their link. Unit names are prefixed so that bond members (20-) match
+// before configured interfaces (30-).
+func NetworkdFiles(network *bootstrapv1beta2.NetworkConfig) []File {
+	if network == nil {
+		return nil
+	}
+
+	var files []File
+	bondOf := map[string]string{}
+	vlansOf := map[string][]string{}
+	var links []string // links with a .network unit, in order
+
+	for _, bond := range network.Bonds {
+		mode := bond.Mode
+		if mode == "" {
+			mode = "active-backup"
+		}
+		files = append(files, networkdFile(fmt.Sprintf("10-%s.netdev", bond.Name),
+			"[NetDev]", "Name="+bond.Name, "Kind=bond", "", "[Bond]", "Mode="+mode))
+		for _, member := range bond.Interfaces {
+			bondOf[member] = bond.Name
+			links = append(links, member)
+		}
+	}
+	for _, vlan := range network.VLANs {
+		files = append(files, networkdFile(fmt.Sprintf("10-%s.netdev", vlan.Name),
+			"[NetDev]", "Name="+vlan.Name, "Kind=vlan", "", "[VLAN]", fmt.Sprintf("Id=%d", vlan.ID)))
+		if len(vlansOf[vlan.Link]) == 0 {
+			links = append(links, vlan.Link)
+		}
+		vlansOf[vlan.Link] = append(vlansOf[vlan.Link], vlan.Name)
+	}
+
+	interfaces := map[string]*bootstrapv1beta2.NetworkInterface{}
+	for i := range network.Interfaces {
+		iface := &network.Interfaces[i]
+		if _, ok := interfaces[iface.Name]; !ok && bondOf[iface.Name] == "" && len(vlansOf[iface.Name]) == 0 {
+			links = append(links, iface.Name)
+		}
+		interfaces[iface.Name] = iface
+	}
+
+	seen := map[string]bool{}
+	for _, link := range links {
+		if seen[link] {
+			continue
+		}
+		seen[link] = true
+
+		prefix := "30"
+		if bondOf[link] != "" {
+			prefix = "20"
+		}
+		lines := []string{"[Match]"}
+		iface := interfaces[link]
+		if iface != nil && iface.MACAddress != "" {
+			lines = append(lines, "MACAddress="+iface.MACAddress)
+		} else {
+			lines = append(lines, "Name="+link)
+		}
+
+		lines = append(lines, "", "[Network]")
+		if bond := bondOf[link]; bond != "" {
+			lines = append(lines, "Bond="+bond)
+		}
+		for _, vlan := range vlansOf[link] {
+			lines = append(lines, "VLAN="+vlan)
+		}
+		if iface != nil {
+			if iface.DHCP {
+				lines = append(lines, "DHCP=yes")
+			}
+			for _, address := range iface.Addresses {
+				lines = append(lines, "Address="+address)
+			}
+			if iface.Gateway != "" {
+				lines = append(lines, "Gateway="+iface.Gateway)
+			}
+			for _, dns := range iface.DNSServers {
+				lines = append(lines, "DNS="+dns)
+			}
+			if iface.MTU > 0 {
+				lines = append(lines, "", "[Link]", fmt.Sprintf("MTUBytes=%d", iface.MTU))
+			}
+		} else if bondOf[link] == "" {
+			// VLAN parent without addressing of its own
+			lines = append(lines, "LinkLocalAddressing=no")
+		}
+		files = append(files, networkdFile(fmt.Sprintf("%s-%s.network", prefix, link), lines...))
+	}
+
+	return files
+}
+
+func networkdFile(name string, lines ...string) File {
+	return File{
+		Path:        networkdDir + "/" + name,
+		Content:     strings.Join(lines, "\n") + "\n",
+		Permissions: "0644",
+	}
+}
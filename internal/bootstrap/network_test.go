@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"testing"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+)
+
+func TestNetworkdFiles(t *testing.T) {
+	if files := NetworkdFiles(nil); files != nil {
+		t.Errorf("Expected no files without spec.network, got %v", files)
+	}
+
+	files := NetworkdFiles(&bootstrapv1beta2.NetworkConfig{
+		Bonds: []bootstrapv1beta2.NetworkBond{
+			{Name: "bond0", Interfaces: []string{"eth0", "eth1"}, Mode: "802.3ad"},
+		},
+		VLANs: []bootstrapv1beta2.NetworkVLAN{
+			{Name: "vlan10", ID: 10, Link: "bond0"},
+			{Name: "vlan20", ID: 20, Link: "eth2"},
+		},
+		Interfaces: []bootstrapv1beta2.NetworkInterface{
+			{Name: "bond0", DHCP: true},
+			{Name: "vlan10", Addresses: []string{"10.0.10.5/24"}, Gateway: "10.0.10.1", DNSServers: []string{"10.0.10.2"}, MTU: 9000},
+			{Name: "mgmt", MACAddress: "52:54:00:12:34:56", Addresses: []string{"192.168.1.5/24"}},
+		},
+	})
+
+	want := map[string]string{
+		"/etc/systemd/network/10-bond0.netdev":  "[NetDev]\nName=bond0\nKind=bond\n\n[Bond]\nMode=802.3ad\n",
+		"/etc/systemd/network/10-vlan10.netdev": "[NetDev]\nName=vlan10\nKind=vlan\n\n[VLAN]\nId=10\n",
+		"/etc/systemd/network/10-vlan20.netdev": "[NetDev]\nName=vlan20\nKind=vlan\n\n[VLAN]\nId=20\n",
+		"/etc/systemd/network/20-eth0.network":  "[Match]\nName=eth0\n\n[Network]\nBond=bond0\n",
+		"/etc/systemd/network/20-eth1.network":  "[Match]\nName=eth1\n\n[Network]\nBond=bond0\n",
+		"/etc/systemd/network/30-bond0.network": "[Match]\nName=bond0\n\n[Network]\nVLAN=vlan10\nDHCP=yes\n",
+		"/etc/systemd/network/30-eth2.network":  "[Match]\nName=eth2\n\n[Network]\nVLAN=vlan20\nLinkLocalAddressing=no\n",
+		"/etc/systemd/network/30-vlan10.network": "[Match]\nName=vlan10\n\n[Network]\nAddress=10.0.10.5/24\nGateway=10.0.10.1\nDNS=10.0.10.2\n\n" +
+			"[Link]\nMTUBytes=9000\n",
+		"/etc/systemd/network/30-mgmt.network": "[Match]\nMACAddress=52:54:00:12:34:56\n\n[Network]\nAddress=192.168.1.5/24\n",
+	}
+	if len(files) != len(want) {
+		t.Errorf("Expected %d files, got %d: %v", len(want), len(files), files)
+	}
+	for _, f := range files {
+		content, ok := want[f.Path]
+		if !ok {
+			t.Errorf("Unexpected file %s", f.Path)
+			continue
+		}
+		if f.Content != content {
+			t.Errorf("Unexpected content of %s:\n%s\nwant:\n%s", f.Path, f.Content, content)
+		}
+		if f.Permissions != "0644" {
+			t.Errorf("Unexpected permissions of %s: %s", f.Path, f.Permissions)
+		}
+	}
+}
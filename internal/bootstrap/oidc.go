@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"sort"
+	"strings"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+)
+
+// OIDCAPIServerArgs returns the kube-apiserver flags, without leading dashes,
+// that configure OpenID Connect authentication from oidc. It returns nil if
+// oidc is nil.
+func OIDCAPIServerArgs(oidc *bootstrapv1beta2.OIDCConfig) map[string]string {
+	if oidc == nil {
+		return nil
+	}
+	args := map[string]string{
+		"oidc-issuer-url": oidc.IssuerURL,
+		"oidc-client-id":  oidc.ClientID,
+	}
+	for flag, value := range map[string]string{
+		"oidc-username-claim":  oidc.UsernameClaim,
+		"oidc-username-prefix": oidc.UsernamePrefix,
+		"oidc-groups-claim":    oidc.GroupsClaim,
+		"oidc-groups-prefix":   oidc.GroupsPrefix,
+		"oidc-ca-file":         oidc.CAFile,
+	} {
+		if value != "" {
+			args[flag] = value
+		}
+	}
+	if len(oidc.SigningAlgorithms) > 0 {
+		args["oidc-signing-algs"] = strings.Join(oidc.SigningAlgorithms, ",")
+	}
+	if len(oidc.RequiredClaims) > 0 {
+		claims := make([]string, 0, len(oidc.RequiredClaims))
+		for claim, value := range oidc.RequiredClaims {
+			claims = append(claims, claim+"="+value)
+		}
+		sort.Strings(claims)
+		args["oidc-required-claim"] = strings.Join(claims, ",")
+	}
+	return args
+}
+
+// K3sAPIServerArgs renders API server flags as k3s --kube-apiserver-arg flags,
+// sorted by flag name.
+func K3sAPIServerArgs(args map[string]string) []string {
+	flags := make([]string, 0, len(args))
+	for flag := range args {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+
+	out := make([]string, 0, len(flags))
+	for _, flag := range flags {
+		out = append(out, "--kube-apiserver-arg="+flag+"="+args[flag])
+	}
+	return out
+}
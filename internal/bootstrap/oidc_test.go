@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"reflect"
+	"testing"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+)
+
+func TestOIDCAPIServerArgs(t *testing.T) {
+	if args := OIDCAPIServerArgs(nil); args != nil {
+		t.Errorf("Expected no args without oidc, got %v", args)
+	}
+
+	args := OIDCAPIServerArgs(&bootstrapv1beta2.OIDCConfig{
+		IssuerURL:         "https://dex.example.com",
+		ClientID:          "kubernetes",
+		UsernameClaim:     "email",
+		GroupsClaim:       "groups",
+		GroupsPrefix:      "oidc:",
+		RequiredClaims:    map[string]string{"hd": "example.com", "aud": "kubernetes"},
+		SigningAlgorithms: []string{"RS256", "ES256"},
+		CAFile:            "/etc/kubernetes/oidc-ca.crt",
+	})
+	expected := map[string]string{
+		"oidc-issuer-url":     "https://dex.example.com",
+		"oidc-client-id":      "kubernetes",
+		"oidc-username-claim": "email",
+		"oidc-groups-claim":   "groups",
+		"oidc-groups-prefix":  "oidc:",
+		"oidc-required-claim": "aud=kubernetes,hd=example.com",
+		"oidc-signing-algs":   "RS256,ES256",
+		"oidc-ca-file":        "/etc/kubernetes/oidc-ca.crt",
+	}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("Unexpected args:\n got %v\nwant %v", args, expected)
+	}
+
+	flags := K3sAPIServerArgs(map[string]string{"oidc-issuer-url": "https://dex.example.com", "oidc-client-id": "kubernetes"})
+	expectedFlags := []string{
+		"--kube-apiserver-arg=oidc-client-id=kubernetes",
+		"--kube-apiserver-arg=oidc-issuer-url=https://dex.example.com",
+	}
+	if !reflect.DeepEqual(flags, expectedFlags) {
+		t.Errorf("Unexpected k3s flags: %v", flags)
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// RenderStages renders the steps of spec.stages as YAML sequences keyed by
+// stage name, so the templates can append them to the stages they render.
+// Every step must be an object.
+func RenderStages(stages map[string][]runtime.RawExtension) (map[string]string, error) {
+	if len(stages) == 0 {
+		return nil, nil
+	}
+
+	rendered := make(map[string]string, len(stages))
+	for name, steps := range stages {
+		if len(steps) == 0 {
+			continue
+		}
+		parsed := make([]map[string]interface{}, 0, len(steps))
+		for i, step := range steps {
+			var fields map[string]interface{}
+			if err := yaml.Unmarshal(step.Raw, &fields); err != nil || fields == nil {
+				return nil, fmt.Errorf("step %d of stage %s must be an object", i, name)
+			}
+			parsed = append(parsed, fields)
+		}
+		out, err := yaml.Marshal(parsed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render stage %s: %w", name, err)
+		}
+		rendered[name] = string(out)
+	}
+	if len(rendered) == 0 {
+		return nil, nil
+	}
+	return rendered, nil
+}
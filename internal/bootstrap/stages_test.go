@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestRenderStages(t *testing.T) {
+	if stages, err := RenderStages(nil); err != nil || stages != nil {
+		t.Errorf("Expected no stages without spec.stages, got %v, %v", stages, err)
+	}
+
+	stages, err := RenderStages(map[string][]runtime.RawExtension{
+		"boot": {
+			{Raw: []byte(`{"name": "Set sysctl", "sysctl": {"vm.max_map_count": "262144"}}`)},
+			{Raw: []byte(`{"commands": ["echo {{ .MachineID }}"]}`)},
+		},
+		"after-install": {},
+	})
+	if err != nil {
+		t.Fatalf("Failed to render stages: %v", err)
+	}
+	want := `- name: Set sysctl
+  sysctl:
+    vm.max_map_count: "262144"
+- commands:
+  - echo {{ .MachineID }}
+`
+	if len(stages) != 1 || stages["boot"] != want {
+		t.Errorf("Unexpected stages: %v", stages)
+	}
+
+	if _, err := RenderStages(map[string][]runtime.RawExtension{"boot": {{Raw: []byte(`["echo"]`)}}}); err == nil {
+		t.Error("Expected an error for a step that is not an object")
+	}
+}
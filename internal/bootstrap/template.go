@@ -24,6 +24,7 @@ import (
 	"text/template"
 
 	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+	"github.com/kairos-io/kairos-capi/internal/dockerconfig"
 )
 
 //go:embed templates/*.tmpl
@@ -39,8 +40,13 @@ type TemplateData struct {
 	UserGroups                     []string
 	GitHubUser                     string
 	SSHPublicKey                   string
+	SSHAuthorizedKeys              []string // keys from spec.sshAuthorizedKeys and spec.sshKeySecretRefs
+	Users                          []User   // additional users from spec.users
 	WorkerToken                    string
+	ControllerToken                string // k0s controller join token for control plane nodes after the first
 	Manifests                      []bootstrapv1beta2.Manifest
+	Files                          []File     // spec.files written through write_files
+	Downloads                      []Download // spec.files the node downloads at boot
 	HostnamePrefix                 string
 	DNSServers                     []string
 	PodCIDR                        string
@@ -53,6 +59,18 @@ type TemplateData struct {
 	ProviderID                     string // ProviderID for the Node (e.g., "vsphere://<vm-uuid>")
 	K3sServerURL                   string
 	K3sToken                       string
+	P2P                            *P2PConfig // k3s only
+	KubeletExtraArgs               []string   // "key=value" kubelet flags, sorted by key
+	ExtraInstallArgs               []string   // extra k0s/k3s install flags
+	K0sConfig                      string     // rendered /etc/k0s/k0s.yaml, k0s control plane only
+	Airgap                         *bootstrapv1beta2.AirgapConfig
+	RegistryAuths                  []dockerconfig.Auth
+	Proxy                          *ProxyConfig
+	NetworkFiles                   []File // systemd-networkd units from spec.network
+	Kcrypt                         *KcryptConfig
+	Stages                         map[string]string // steps from spec.stages as YAML sequences, by stage
+	MachineMetadata                string            // content of MachineMetadataPath, written at boot
+	CompletionCallback             string            // shell commands reporting bootstrap completion
 	ControlPlaneLBServiceName      string
 	ControlPlaneLBServiceNamespace string
 	ControlPlaneLBEndpoint         string
@@ -63,11 +81,102 @@ type TemplateData struct {
 	ManagementAPIServer                 string
 }
 
+// File is a file written on the node. Content is decoded according to
+// Encoding ("base64" or "gzip+base64") when set.
+type File struct {
+	Path        string
+	Content     string
+	Encoding    string
+	Permissions string
+	Owner       string
+}
+
+// Download is a file the node downloads from URL at boot
+type Download struct {
+	Path        string
+	URL         string
+	Permissions string
+	Owner       string
+}
+
+// KcryptConfig holds the kcrypt challenger configuration of encrypted nodes
+type KcryptConfig struct {
+	ChallengerServer string
+	MDNS             bool
+	NVIndex          string
+	CIndex           string
+	TPMDevice        string
+}
+
+// ProxyConfig holds the egress proxy of the k0s/k3s services
+type ProxyConfig struct {
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+}
+
+// Environment returns the proxy variables as systemd Environment= assignments.
+// With containerd set, the CONTAINERD_ variants k3s passes to its embedded
+// containerd are included as well.
+func (p *ProxyConfig) Environment(containerd bool) []string {
+	var env []string
+	add := func(name, value string) {
+		if value == "" {
+			return
+		}
+		// systemd expands % specifiers in Environment=
+		value = strings.ReplaceAll(value, "%", "%%")
+		env = append(env, name+"="+value)
+		if containerd {
+			env = append(env, "CONTAINERD_"+name+"="+value)
+		}
+	}
+	add("HTTP_PROXY", p.HTTPProxy)
+	add("HTTPS_PROXY", p.HTTPSProxy)
+	add("NO_PROXY", p.NoProxy)
+	return env
+}
+
+// P2PConfig holds the Kairos P2P network configuration for the template.
+// Without Auto, nodes take the role of their KairosConfig.
+type P2PConfig struct {
+	NetworkToken string
+	NetworkID    string
+	DNS          bool
+	DisableDHT   bool
+	Auto         bool
+	HA           bool
+	MasterNodes  int32
+}
+
+// User is an additional user account. Sudo is a sudoers rule written to
+// /etc/sudoers.d.
+type User struct {
+	Name              string
+	Password          string
+	LockPassword      bool
+	Groups            []string
+	SSHAuthorizedKeys []string
+	Sudo              string
+}
+
 // InstallConfig holds installation configuration for the template
 type InstallConfig struct {
-	Auto   bool
-	Device string
-	Reboot bool
+	Auto                bool
+	Device              string
+	Reboot              bool
+	NoFormat            bool
+	Partitions          []InstallPartition
+	GrubOptions         map[string]string
+	EncryptedPartitions []string // labels of the partitions kcrypt encrypts
+}
+
+// InstallPartition is a Kairos partition override. Name is the Kairos
+// partition name (oem, recovery, state or persistent).
+type InstallPartition struct {
+	Name       string
+	Size       int32
+	Filesystem string
 }
 
 // RenderK0sCloudConfig renders the k0s Kairos cloud-config template
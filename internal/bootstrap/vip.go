@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+)
+
+const (
+	// DefaultKubeVIPImage is the kube-vip image used when none is configured
+	DefaultKubeVIPImage = "ghcr.io/kube-vip/kube-vip:v0.8.9"
+
+	// KubeVIPManifestPath is where k3s picks up the kube-vip static pod
+	KubeVIPManifestPath = "/var/lib/rancher/k3s/agent/pod-manifests/kube-vip.yaml"
+
+	// k3sKubeconfigPath is the admin kubeconfig kube-vip uses for leader election
+	k3sKubeconfigPath = "/etc/rancher/k3s/k3s.yaml"
+)
+
+// VirtualIP is a control plane endpoint announced by the control plane nodes
+type VirtualIP struct {
+	Host      string
+	Port      int32
+	Interface string // defaults to the interface of the default route
+
+	// KubeVIPImage is the kube-vip image of k3s nodes
+	KubeVIPImage string
+
+	// AuthPass and VirtualRouterID identify the VRRP instance of a k0s
+	// control plane. Every node of a cluster must use the same values, and
+	// clusters sharing a network must use different router IDs.
+	AuthPass        string
+	VirtualRouterID int32
+}
+
+// K0sVirtualIPConfig returns a copy of cfg with the k0s control plane load
+// balancing (keepalived) configured to announce vip. The VIP is added to the
+// API server SANs and its port becomes the API server port.
+func K0sVirtualIPConfig(cfg *bootstrapv1beta2.K0sConfig, vip VirtualIP) (*bootstrapv1beta2.K0sConfig, error) {
+	out := cfg.DeepCopy()
+	if out == nil {
+		out = &bootstrapv1beta2.K0sConfig{}
+	}
+
+	clusterConfig := map[string]interface{}{}
+	if out.Config != nil && len(out.Config.Raw) > 0 {
+		if err := yaml.Unmarshal(out.Config.Raw, &clusterConfig); err != nil {
+			return nil, fmt.Errorf("failed to parse k0s config: %w", err)
+		}
+		if clusterConfig == nil {
+			clusterConfig = map[string]interface{}{}
+		}
+	}
+	spec, err := childMap(clusterConfig, "spec")
+	if err != nil {
+		return nil, err
+	}
+	network, err := childMap(spec, "network")
+	if err != nil {
+		return nil, err
+	}
+
+	instance := map[string]interface{}{
+		"virtualIPs":      []interface{}{vipCIDR(vip.Host)},
+		"authPass":        vip.AuthPass,
+		"virtualRouterID": vip.VirtualRouterID,
+	}
+	if vip.Interface != "" {
+		instance["interface"] = vip.Interface
+	}
+	network["controlPlaneLoadBalancing"] = map[string]interface{}{
+		"enabled": true,
+		"type":    "Keepalived",
+		"keepalived": map[string]interface{}{
+			"vrrpInstances": []interface{}{instance},
+		},
+	}
+
+	if vip.Port != 0 && vip.Port != 6443 {
+		api, err := childMap(spec, "api")
+		if err != nil {
+			return nil, err
+		}
+		api["port"] = vip.Port
+	}
+
+	raw, err := json.Marshal(clusterConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal k0s config: %w", err)
+	}
+	out.Config = &runtime.RawExtension{Raw: raw}
+	if !slices.Contains(out.APISANs, vip.Host) {
+		out.APISANs = append(out.APISANs, vip.Host)
+	}
+	return out, nil
+}
+
+// vipCIDR returns host as a single address CIDR, as keepalived expects
+func vipCIDR(host string) string {
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		return host + "/128"
+	}
+	return host + "/32"
+}
+
+// KubeVIPManifest renders the static pod that announces vip from k3s control
+// plane nodes through ARP. The nodes elect the holder of the address with a
+// lease in kube-system.
+func KubeVIPManifest(vip VirtualIP) (string, error) {
+	image := vip.KubeVIPImage
+	if image == "" {
+		image = DefaultKubeVIPImage
+	}
+	port := vip.Port
+	if port == 0 {
+		port = 6443
+	}
+
+	env := []corev1.EnvVar{
+		{Name: "address", Value: vip.Host},
+		{Name: "port", Value: strconv.Itoa(int(port))},
+		{Name: "vip_arp", Value: "true"},
+		{Name: "cp_enable", Value: "true"},
+		{Name: "cp_namespace", Value: "kube-system"},
+		{Name: "vip_leaderelection", Value: "true"},
+		{Name: "vip_leasename", Value: "plndr-cp-lock"},
+		{Name: "vip_leaseduration", Value: "5"},
+		{Name: "vip_renewdeadline", Value: "3"},
+		{Name: "vip_retryperiod", Value: "1"},
+	}
+	if vip.Interface != "" {
+		env = append(env, corev1.EnvVar{Name: "vip_interface", Value: vip.Interface})
+	}
+
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kube-vip",
+			Namespace: "kube-system",
+		},
+		Spec: corev1.PodSpec{
+			HostNetwork: true,
+			// k3s.yaml points at 127.0.0.1, which is valid for the kubernetes SAN
+			HostAliases: []corev1.HostAlias{{IP: "127.0.0.1", Hostnames: []string{"kubernetes"}}},
+			Containers: []corev1.Container{{
+				Name:  "kube-vip",
+				Image: image,
+				Args:  []string{"manager"},
+				Env:   env,
+				SecurityContext: &corev1.SecurityContext{
+					Capabilities: &corev1.Capabilities{
+						Add: []corev1.Capability{"NET_ADMIN", "NET_RAW"},
+					},
+				},
+				VolumeMounts: []corev1.VolumeMount{{Name: "kubeconfig", MountPath: "/etc/kubernetes/admin.conf"}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "kubeconfig",
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{Path: k3sKubeconfigPath},
+				},
+			}},
+		},
+	}
+
+	out, err := yaml.Marshal(pod)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal kube-vip manifest: %w", err)
+	}
+	return string(out), nil
+}
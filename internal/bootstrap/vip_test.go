@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+)
+
+func TestK0sVirtualIPConfig(t *testing.T) {
+	cfg := &bootstrapv1beta2.K0sConfig{
+		Config:  &runtime.RawExtension{Raw: []byte(`{"spec": {"network": {"kubeProxy": {"mode": "ipvs"}}}}`)},
+		APISANs: []string{"api.example.com"},
+	}
+	vip := VirtualIP{Host: "192.168.1.100", Port: 7443, Interface: "eth1", AuthPass: "0a1b2c3d", VirtualRouterID: 42}
+
+	result, err := K0sVirtualIPConfig(cfg, vip)
+	if err != nil {
+		t.Fatalf("Failed to configure virtual IP: %v", err)
+	}
+	if strings.Contains(string(cfg.Config.Raw), "Keepalived") {
+		t.Errorf("Expected the input config to be left unchanged, got %s", cfg.Config.Raw)
+	}
+	if len(result.APISANs) != 2 || result.APISANs[1] != "192.168.1.100" {
+		t.Errorf("Expected the virtual IP to be added to the API SANs, got %v", result.APISANs)
+	}
+
+	rendered, err := RenderK0sClusterConfig(result, "", "", nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to render k0s config: %v", err)
+	}
+	var parsed struct {
+		Spec struct {
+			API struct {
+				Port int `json:"port"`
+			} `json:"api"`
+			Network struct {
+				KubeProxy struct {
+					Mode string `json:"mode"`
+				} `json:"kubeProxy"`
+				ControlPlaneLoadBalancing struct {
+					Enabled    bool   `json:"enabled"`
+					Type       string `json:"type"`
+					Keepalived struct {
+						VRRPInstances []struct {
+							VirtualIPs      []string `json:"virtualIPs"`
+							AuthPass        string   `json:"authPass"`
+							Interface       string   `json:"interface"`
+							VirtualRouterID int      `json:"virtualRouterID"`
+						} `json:"vrrpInstances"`
+					} `json:"keepalived"`
+				} `json:"controlPlaneLoadBalancing"`
+			} `json:"network"`
+		} `json:"spec"`
+	}
+	if err := yaml.Unmarshal([]byte(rendered), &parsed); err != nil {
+		t.Fatalf("Failed to parse rendered config: %v", err)
+	}
+
+	network := parsed.Spec.Network
+	if network.KubeProxy.Mode != "ipvs" {
+		t.Errorf("Expected the base config to be kept, got kubeProxy mode %q", network.KubeProxy.Mode)
+	}
+	if parsed.Spec.API.Port != 7443 {
+		t.Errorf("Expected API port 7443, got %d", parsed.Spec.API.Port)
+	}
+	cplb := network.ControlPlaneLoadBalancing
+	if !cplb.Enabled || cplb.Type != "Keepalived" || len(cplb.Keepalived.VRRPInstances) != 1 {
+		t.Fatalf("Expected keepalived control plane load balancing, got %+v", cplb)
+	}
+	instance := cplb.Keepalived.VRRPInstances[0]
+	if len(instance.VirtualIPs) != 1 || instance.VirtualIPs[0] != "192.168.1.100/32" {
+		t.Errorf("Expected virtual IP 192.168.1.100/32, got %v", instance.VirtualIPs)
+	}
+	if instance.AuthPass != "0a1b2c3d" || instance.Interface != "eth1" || instance.VirtualRouterID != 42 {
+		t.Errorf("Unexpected VRRP instance %+v", instance)
+	}
+}
+
+func TestKubeVIPManifest(t *testing.T) {
+	manifest, err := KubeVIPManifest(VirtualIP{Host: "fd00::100", Interface: "enp1s0"})
+	if err != nil {
+		t.Fatalf("Failed to render kube-vip manifest: %v", err)
+	}
+
+	pod := &corev1.Pod{}
+	if err := yaml.Unmarshal([]byte(manifest), pod); err != nil {
+		t.Fatalf("Failed to parse kube-vip manifest: %v", err)
+	}
+	if pod.Kind != "Pod" || pod.Namespace != "kube-system" || !pod.Spec.HostNetwork {
+		t.Errorf("Expected a host network pod in kube-system, got %s %s/%s", pod.Kind, pod.Namespace, pod.Name)
+	}
+	container := pod.Spec.Containers[0]
+	if container.Image != DefaultKubeVIPImage {
+		t.Errorf("Expected default image %s, got %s", DefaultKubeVIPImage, container.Image)
+	}
+	env := map[string]string{}
+	for _, e := range container.Env {
+		env[e.Name] = e.Value
+	}
+	for name, want := range map[string]string{"address": "fd00::100", "port": "6443", "vip_interface": "enp1s0", "cp_enable": "true"} {
+		if env[name] != want {
+			t.Errorf("Expected env %s=%q, got %q", name, want, env[name])
+		}
+	}
+	if pod.Spec.Volumes[0].HostPath.Path != "/etc/rancher/k3s/k3s.yaml" {
+		t.Errorf("Expected the k3s kubeconfig to be mounted, got %s", pod.Spec.Volumes[0].HostPath.Path)
+	}
+}
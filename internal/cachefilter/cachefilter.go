@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package cachefilter restricts the manager's Secret and ConfigMap informers to
+// objects owned by Cluster API clusters. Management clusters can hold tens of
+// thousands of unrelated Secrets; caching all of them is wasteful.
+package cachefilter
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// Selector matches objects carrying the Cluster API cluster-name label, such as
+// the bootstrap data secrets and the kubeconfig secrets. Secrets created
+// without it are read with GetSecret.
+func Selector() labels.Selector {
+	req, err := labels.NewRequirement(clusterv1.ClusterNameLabel, selection.Exists, nil)
+	if err != nil {
+		// The requirement is static; this can only fail on a programming error.
+		panic(err)
+	}
+	return labels.NewSelector().Add(*req)
+}
+
+// ByObject returns the per-object cache configuration for the manager.
+func ByObject() map[client.Object]cache.ByObject {
+	return map[client.Object]cache.ByObject{
+		&corev1.Secret{}:    {Label: Selector()},
+		&corev1.ConfigMap{}: {Label: Selector()},
+	}
+}
+
+// GetSecret reads a Secret through the cached client and falls back to a live
+// read when the Secret is not in the filtered cache, e.g. user-provided token
+// secrets referenced from a KairosConfig. apiReader may be nil, in which case
+// only the cached client is used.
+func GetSecret(ctx context.Context, c client.Reader, apiReader client.Reader, key types.NamespacedName, secret *corev1.Secret) error {
+	err := c.Get(ctx, key, secret)
+	if err == nil || !apierrors.IsNotFound(err) || apiReader == nil {
+		return err
+	}
+	return apiReader.Get(ctx, key, secret)
+}
+
+// GetConfigMap is GetSecret for ConfigMaps.
+func GetConfigMap(ctx context.Context, c client.Reader, apiReader client.Reader, key types.NamespacedName, configMap *corev1.ConfigMap) error {
+	err := c.Get(ctx, key, configMap)
+	if err == nil || !apierrors.IsNotFound(err) || apiReader == nil {
+		return err
+	}
+	return apiReader.Get(ctx, key, configMap)
+}
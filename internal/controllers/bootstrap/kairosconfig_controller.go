@@ -20,9 +20,13 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/sha1"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -37,30 +41,101 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/remote"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
 	"github.com/kairos-io/kairos-capi/internal/bootstrap"
+	"github.com/kairos-io/kairos-capi/internal/cachefilter"
+	"github.com/kairos-io/kairos-capi/internal/dockerconfig"
+	"github.com/kairos-io/kairos-capi/internal/k0stoken"
+	"github.com/kairos-io/kairos-capi/internal/kubeconfigsecret"
+	"github.com/kairos-io/kairos-capi/internal/metrics"
+	"github.com/kairos-io/kairos-capi/internal/osupgrade"
+	"github.com/kairos-io/kairos-capi/internal/redact"
+	"github.com/kairos-io/kairos-capi/internal/shutdown"
+	"github.com/kairos-io/kairos-capi/internal/v1beta2conditions"
+	"github.com/kairos-io/kairos-capi/internal/workload"
 )
 
 const controlPlaneLBServiceSuffix = "control-plane-lb"
 
+// controllerName labels the metrics of this controller, matching the name
+// controller-runtime reports its own metrics under.
+const controllerName = "kairosconfig"
+
 var errLBEndpointNotReady = errors.New("control plane load balancer endpoint not ready")
 var errK3sTokenNotReady = errors.New("k3s token secret not ready")
+var errControllerTokenNotReady = errors.New("k0s controller token secret not ready")
+var errWorkerTokenNotReady = errors.New("workload cluster not ready to provision a worker token")
+
+// tokenFetchError marks a join token that could not be read or provisioned,
+// so it is counted by the token fetch failure metric.
+type tokenFetchError struct {
+	err error
+}
+
+func (e *tokenFetchError) Error() string { return e.err.Error() }
+
+func (e *tokenFetchError) Unwrap() error { return e.err }
+
+func tokenFetchFailed(err error) error {
+	return &tokenFetchError{err: err}
+}
+
+const (
+	// workerJoinTokenTTL is the expiry of provisioned k0s worker join tokens
+	workerJoinTokenTTL = 24 * time.Hour
+	// workerJoinTokenRenewBefore is how long before expiry a token is replaced
+	workerJoinTokenRenewBefore = 12 * time.Hour
+	// workerJoinTokenExpiryAnnotation records the expiry of the token stored in the secret
+	workerJoinTokenExpiryAnnotation = "bootstrap.cluster.x-k8s.io/token-expiry"
+)
 
 // KairosConfigReconciler reconciles a KairosConfig object
 type KairosConfigReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
 	RESTConfig *rest.Config
+	// APIReader reads objects that are not in the manager's label-filtered
+	// cache, such as user-provided token secrets.
+	APIReader client.Reader
+	// ValidateOnReconcile runs the webhook validation inside the reconcile loop.
+	// It is set when the manager runs without admission webhooks.
+	ValidateOnReconcile bool
+	// Tracker provides cached workload cluster clients used to watch Nodes and
+	// report the NodeJoined/NodeReady conditions. Node conditions are skipped
+	// when nil.
+	Tracker *remote.ClusterCacheTracker
+	// ConnectionBackoff spaces out new connection attempts to workload
+	// clusters whose API server could not be reached through the Tracker.
+	// When nil, every reconcile tries to connect.
+	ConnectionBackoff *workload.ConnectionBackoff
+	// SystemUpgradeControllerManifest is applied to workload clusters with
+	// workers that set spec.osUpgrade but that do not run the
+	// system-upgrade-controller yet. When empty, it has to be installed by
+	// other means.
+	SystemUpgradeControllerManifest []byte
+
+	controller controller.Controller
+	// recorder emits events on KairosConfigs and their Machines. It is set by
+	// SetupWithManager; no events are emitted when nil.
+	recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=bootstrap.cluster.x-k8s.io,resources=kairosconfigs,verbs=get;list;watch;create;update;patch;delete
@@ -68,6 +143,7 @@ type KairosConfigReconciler struct {
 //+kubebuilder:rbac:groups=bootstrap.cluster.x-k8s.io,resources=kairosconfigs/finalizers,verbs=update
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;clusters,verbs=get;list;watch
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines/status;clusters/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machinedeployments,verbs=get;patch
 //+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=vspheremachines,verbs=get;list;watch
 //+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=vspheremachines/status,verbs=get
 //+kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachineinstances,verbs=get
@@ -79,6 +155,7 @@ type KairosConfigReconciler struct {
 //+kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=create;get;list;update;patch;watch
 //+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch
 //+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations;validatingwebhookconfigurations,verbs=get;list;patch;update
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;patch;update
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=create;get;list;watch
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;patch;update
 
@@ -142,9 +219,34 @@ func (r *KairosConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, err
 	}
 
+	// clusterctl move pauses the Cluster while it copies objects; only report
+	// the pause until it is lifted
+	if annotations.IsPaused(cluster, kairosConfig) {
+		log.Info("Reconciliation is paused for this object")
+		conditions.MarkTrue(kairosConfig, bootstrapv1beta2.PausedCondition)
+		return ctrl.Result{}, r.patchKairosConfig(ctx, helper, kairosConfig)
+	}
+	conditions.MarkFalse(kairosConfig, bootstrapv1beta2.PausedCondition, bootstrapv1beta2.NotPausedReason, clusterv1.ConditionSeverityInfo, "")
+
 	// Always update observedGeneration
 	kairosConfig.Status.ObservedGeneration = kairosConfig.Generation
 
+	// Without admission webhooks invalid specs reach the controller; stop here
+	// and report them instead of generating bootstrap data from them
+	if r.ValidateOnReconcile {
+		if err := kairosConfig.Validate(); err != nil {
+			log.Info("KairosConfig spec is invalid", "error", err.Error())
+			conditions.MarkFalse(kairosConfig, bootstrapv1beta2.ValidSpecCondition, bootstrapv1beta2.InvalidSpecReason, clusterv1.ConditionSeverityError, "%s", err.Error())
+			conditions.MarkFalse(kairosConfig, clusterv1.ReadyCondition, bootstrapv1beta2.InvalidSpecReason, clusterv1.ConditionSeverityError, "%s", err.Error())
+			kairosConfig.Status.FailureReason = bootstrapv1beta2.InvalidSpecReason
+			kairosConfig.Status.FailureMessage = err.Error()
+			kairosConfig.Status.Ready = false
+			metrics.RecordReconcileError(controllerName, bootstrapv1beta2.InvalidSpecReason)
+			return ctrl.Result{}, r.patchKairosConfig(ctx, helper, kairosConfig)
+		}
+		conditions.MarkTrue(kairosConfig, bootstrapv1beta2.ValidSpecCondition)
+	}
+
 	// Reconcile bootstrap data
 	result, err := r.reconcileBootstrapData(ctx, log, kairosConfig, machine, cluster)
 	if err != nil {
@@ -157,13 +259,14 @@ func (r *KairosConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		kairosConfig.Status.FailureReason = bootstrapv1beta2.BootstrapDataSecretGenerationFailedReason
 		kairosConfig.Status.FailureMessage = err.Error()
 		kairosConfig.Status.Ready = false
+		metrics.RecordReconcileError(controllerName, bootstrapv1beta2.BootstrapDataSecretGenerationFailedReason)
 
-		return ctrl.Result{}, helper.Patch(ctx, kairosConfig)
+		return ctrl.Result{}, r.patchKairosConfig(ctx, helper, kairosConfig)
 	}
 
 	// If reconcileBootstrapData requested a requeue (e.g., waiting for providerID), return it
 	if result.Requeue || result.RequeueAfter > 0 {
-		return result, helper.Patch(ctx, kairosConfig)
+		return result, r.patchKairosConfig(ctx, helper, kairosConfig)
 	}
 
 	// Mark conditions as true on success
@@ -175,8 +278,299 @@ func (r *KairosConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	kairosConfig.Status.FailureReason = ""
 	kairosConfig.Status.FailureMessage = ""
 
+	// Reflect the workload cluster node state once bootstrap data is in place
+	result = r.reconcileNodeConditions(ctx, log, kairosConfig, machine, cluster)
+
+	// Roll spec.osUpgrade out to the worker node through a system-upgrade-controller Plan
+	result = util.LowestNonZeroResult(result, r.reconcileOSUpgrade(ctx, log, kairosConfig, machine, cluster))
+
 	// Update status
-	return ctrl.Result{}, helper.Patch(ctx, kairosConfig)
+	return result, r.patchKairosConfig(ctx, helper, kairosConfig)
+}
+
+// eventf emits an event on kairosConfig and, when set, on the Machine it
+// bootstraps.
+func (r *KairosConfigReconciler) eventf(kairosConfig *bootstrapv1beta2.KairosConfig, machine *clusterv1.Machine, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.recorder == nil {
+		return
+	}
+	message := redact.String(fmt.Sprintf(messageFmt, args...))
+	r.recorder.Event(kairosConfig, eventType, reason, message)
+	if machine != nil {
+		r.recorder.Event(machine, eventType, reason, message)
+	}
+}
+
+// reconcileNodeConditions sets NodeJoined, NodeReady and BootstrapExecuted
+// from the Node backing machine in the workload cluster and makes sure Node
+// changes trigger a reconcile. Workload cluster errors are reported in the conditions and
+// retried later instead of failing the reconcile.
+func (r *KairosConfigReconciler) reconcileNodeConditions(ctx context.Context, log logr.Logger, kairosConfig *bootstrapv1beta2.KairosConfig, machine *clusterv1.Machine, cluster *clusterv1.Cluster) ctrl.Result {
+	if r.Tracker == nil {
+		return ctrl.Result{}
+	}
+	if !reportsBootstrapCompletion(kairosConfig) {
+		conditions.Delete(kairosConfig, bootstrapv1beta2.BootstrapExecutedCondition)
+	}
+
+	if !conditions.IsTrue(cluster, clusterv1.ControlPlaneInitializedCondition) {
+		conditions.MarkFalse(kairosConfig, bootstrapv1beta2.NodeJoinedCondition, bootstrapv1beta2.WaitingForControlPlaneInitializationReason, clusterv1.ConditionSeverityInfo, "Waiting for the control plane to be initialized")
+		conditions.MarkFalse(kairosConfig, bootstrapv1beta2.NodeReadyCondition, bootstrapv1beta2.WaitingForControlPlaneInitializationReason, clusterv1.ConditionSeverityInfo, "Waiting for the control plane to be initialized")
+		if reportsBootstrapCompletion(kairosConfig) {
+			conditions.MarkFalse(kairosConfig, bootstrapv1beta2.BootstrapExecutedCondition, bootstrapv1beta2.WaitingForControlPlaneInitializationReason, clusterv1.ConditionSeverityInfo, "Waiting for the control plane to be initialized")
+		}
+		return ctrl.Result{}
+	}
+
+	clusterKey := util.ObjectKey(cluster)
+	if err := r.ConnectionBackoff.Watch(ctx, r.Tracker, remote.WatchInput{
+		Name:         "kairosconfig-watchNodes",
+		Cluster:      clusterKey,
+		Watcher:      r.controller,
+		Kind:         &corev1.Node{},
+		EventHandler: handler.EnqueueRequestsFromMapFunc(r.nodeToKairosConfig(clusterKey)),
+		Predicates: []predicate.Predicate{predicate.Or(
+			workload.NodeReadinessChanged(),
+			workload.NodeAnnotationChanged(bootstrapv1beta2.BootstrapExecutedAnnotation),
+		)},
+	}); err != nil {
+		return r.markWorkloadClusterUnreachable(log, kairosConfig, err)
+	}
+
+	workloadClient, err := r.ConnectionBackoff.GetClient(ctx, r.Tracker, clusterKey)
+	if err != nil {
+		return r.markWorkloadClusterUnreachable(log, kairosConfig, err)
+	}
+
+	node, err := workload.NodeForMachine(ctx, workloadClient, machine)
+	if err != nil {
+		return r.markWorkloadClusterUnreachable(log, kairosConfig, err)
+	}
+	if node == nil {
+		conditions.MarkFalse(kairosConfig, bootstrapv1beta2.NodeJoinedCondition, bootstrapv1beta2.WaitingForNodeReason, clusterv1.ConditionSeverityInfo, "Waiting for the node to join the cluster")
+		conditions.MarkFalse(kairosConfig, bootstrapv1beta2.NodeReadyCondition, bootstrapv1beta2.WaitingForNodeReason, clusterv1.ConditionSeverityInfo, "Waiting for the node to join the cluster")
+		if reportsBootstrapCompletion(kairosConfig) {
+			conditions.MarkFalse(kairosConfig, bootstrapv1beta2.BootstrapExecutedCondition, bootstrapv1beta2.WaitingForNodeReason, clusterv1.ConditionSeverityInfo, "Waiting for the node to join the cluster")
+		}
+		return ctrl.Result{}
+	}
+
+	conditions.MarkTrue(kairosConfig, bootstrapv1beta2.NodeJoinedCondition)
+	if workload.IsNodeReady(node) {
+		conditions.MarkTrue(kairosConfig, bootstrapv1beta2.NodeReadyCondition)
+	} else {
+		conditions.MarkFalse(kairosConfig, bootstrapv1beta2.NodeReadyCondition, bootstrapv1beta2.NodeNotReadyReason, clusterv1.ConditionSeverityWarning, "Node %s is not Ready", node.Name)
+	}
+	if reportsBootstrapCompletion(kairosConfig) {
+		if node.Annotations[bootstrapv1beta2.BootstrapExecutedAnnotation] != "" {
+			conditions.MarkTrue(kairosConfig, bootstrapv1beta2.BootstrapExecutedCondition)
+		} else {
+			conditions.MarkFalse(kairosConfig, bootstrapv1beta2.BootstrapExecutedCondition, bootstrapv1beta2.WaitingForBootstrapCompletionReason, clusterv1.ConditionSeverityInfo, "Waiting for node %s to report bootstrap completion", node.Name)
+		}
+	}
+	return ctrl.Result{}
+}
+
+// reportsBootstrapCompletion reports whether the node of kairosConfig
+// annotates its Node on bootstrap completion. k0s controllers without a
+// worker have no Node to annotate.
+func reportsBootstrapCompletion(kairosConfig *bootstrapv1beta2.KairosConfig) bool {
+	spec := kairosConfig.Spec
+	if spec.CompletionCallback == nil || !spec.CompletionCallback.NodeAnnotation {
+		return false
+	}
+	return spec.Distribution == "k3s" || spec.Role != "control-plane" || spec.SingleNode
+}
+
+// markWorkloadClusterUnreachable records a workload cluster access error on the
+// node conditions and schedules a retry.
+func (r *KairosConfigReconciler) markWorkloadClusterUnreachable(log logr.Logger, kairosConfig *bootstrapv1beta2.KairosConfig, err error) ctrl.Result {
+	if errors.Is(err, remote.ErrClusterLocked) {
+		// Another worker is connecting to this cluster; try again shortly
+		return ctrl.Result{RequeueAfter: 5 * time.Second}
+	}
+	log.V(4).Info("Workload cluster not reachable, will retry node conditions", "error", err.Error())
+	conditions.MarkUnknown(kairosConfig, bootstrapv1beta2.NodeJoinedCondition, bootstrapv1beta2.WorkloadClusterUnreachableReason, "%s", err.Error())
+	conditions.MarkUnknown(kairosConfig, bootstrapv1beta2.NodeReadyCondition, bootstrapv1beta2.WorkloadClusterUnreachableReason, "%s", err.Error())
+	if reportsBootstrapCompletion(kairosConfig) {
+		conditions.MarkUnknown(kairosConfig, bootstrapv1beta2.BootstrapExecutedCondition, bootstrapv1beta2.WorkloadClusterUnreachableReason, "%s", err.Error())
+	}
+	return ctrl.Result{RequeueAfter: 30 * time.Second}
+}
+
+// reconcileOSUpgrade upgrades the worker node of machine to spec.osUpgrade in
+// place. Workers upgrading to the same image share one
+// system-upgrade-controller Plan per cluster, which selects their Nodes by
+// the PlanNodeLabel set here. Progress is reported on the OSUpgradeCompleted
+// condition; errors are retried later instead of failing the reconcile.
+func (r *KairosConfigReconciler) reconcileOSUpgrade(ctx context.Context, log logr.Logger, kairosConfig *bootstrapv1beta2.KairosConfig, machine *clusterv1.Machine, cluster *clusterv1.Cluster) ctrl.Result {
+	upgrade := kairosConfig.Spec.OSUpgrade
+	if upgrade == nil || kairosConfig.Spec.Role != "worker" || r.Tracker == nil {
+		conditions.Delete(kairosConfig, bootstrapv1beta2.OSUpgradeCompletedCondition)
+		return ctrl.Result{}
+	}
+	if !conditions.IsTrue(cluster, clusterv1.ControlPlaneInitializedCondition) {
+		conditions.MarkFalse(kairosConfig, bootstrapv1beta2.OSUpgradeCompletedCondition, bootstrapv1beta2.WaitingForControlPlaneInitializationReason, clusterv1.ConditionSeverityInfo, "Waiting for the control plane to be initialized")
+		return ctrl.Result{}
+	}
+
+	workloadClient, err := r.ConnectionBackoff.GetClient(ctx, r.Tracker, util.ObjectKey(cluster))
+	if err != nil {
+		return r.markOSUpgradeError(log, kairosConfig, err)
+	}
+	node, err := workload.NodeForMachine(ctx, workloadClient, machine)
+	if err != nil {
+		return r.markOSUpgradeError(log, kairosConfig, err)
+	}
+	if node == nil {
+		conditions.MarkFalse(kairosConfig, bootstrapv1beta2.OSUpgradeCompletedCondition, bootstrapv1beta2.WaitingForNodeReason, clusterv1.ConditionSeverityInfo, "Waiting for the node to join the cluster")
+		return ctrl.Result{}
+	}
+
+	installed, err := osupgrade.SystemUpgradeControllerInstalled(workloadClient.RESTMapper())
+	if err != nil {
+		return r.markOSUpgradeError(log, kairosConfig, err)
+	}
+	if !installed {
+		if len(r.SystemUpgradeControllerManifest) == 0 {
+			conditions.MarkFalse(kairosConfig, bootstrapv1beta2.OSUpgradeCompletedCondition, bootstrapv1beta2.SystemUpgradeControllerNotInstalledReason, clusterv1.ConditionSeverityWarning,
+				"system-upgrade-controller is not installed in the workload cluster; install it or start the manager with --system-upgrade-controller-manifest")
+			return ctrl.Result{RequeueAfter: time.Minute}
+		}
+		log.Info("Installing system-upgrade-controller in workload cluster", "cluster", cluster.Name)
+		if err := osupgrade.ApplyManifest(ctx, workloadClient, r.SystemUpgradeControllerManifest); err != nil {
+			return r.markOSUpgradeError(log, kairosConfig, fmt.Errorf("failed to install system-upgrade-controller: %w", err))
+		}
+		conditions.MarkFalse(kairosConfig, bootstrapv1beta2.OSUpgradeCompletedCondition, bootstrapv1beta2.InstallingSystemUpgradeControllerReason, clusterv1.ConditionSeverityInfo, "Installing system-upgrade-controller")
+		return ctrl.Result{RequeueAfter: 15 * time.Second}
+	}
+
+	image := osupgrade.ImageRef(upgrade.Image, upgrade.Version)
+	desired := osupgrade.NewWorkerPlan(cluster.Name, upgrade.Image, upgrade.Version, upgrade.Concurrency)
+	plan := &unstructured.Unstructured{}
+	plan.SetGroupVersionKind(osupgrade.PlanGVK)
+	if err := workloadClient.Get(ctx, client.ObjectKeyFromObject(desired), plan); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return r.markOSUpgradeError(log, kairosConfig, err)
+		}
+		log.Info("Creating OS upgrade plan for workers", "image", image, "plan", desired.GetName())
+		if err := workloadClient.Create(ctx, desired); err != nil && !apierrors.IsAlreadyExists(err) {
+			return r.markOSUpgradeError(log, kairosConfig, fmt.Errorf("failed to create Plan: %w", err))
+		}
+		plan = desired
+	}
+
+	if node.Labels[osupgrade.PlanNodeLabel] != plan.GetName() {
+		log.Info("Starting OS upgrade of worker node", "node", node.Name, "image", image, "plan", plan.GetName())
+		original := node.DeepCopy()
+		if node.Labels == nil {
+			node.Labels = map[string]string{}
+		}
+		node.Labels[osupgrade.PlanNodeLabel] = plan.GetName()
+		if err := workloadClient.Patch(ctx, node, client.MergeFrom(original)); err != nil {
+			return r.markOSUpgradeError(log, kairosConfig, fmt.Errorf("failed to label node %s: %w", node.Name, err))
+		}
+	}
+
+	if !osupgrade.PlanApplied(plan, node) {
+		conditions.MarkFalse(kairosConfig, bootstrapv1beta2.OSUpgradeCompletedCondition, bootstrapv1beta2.OSUpgradeInProgressReason, clusterv1.ConditionSeverityInfo, "Upgrading node %s to %s", node.Name, image)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}
+	}
+	if !conditions.IsTrue(kairosConfig, bootstrapv1beta2.OSUpgradeCompletedCondition) {
+		log.Info("OS upgrade of worker node completed", "node", node.Name, "image", image)
+		r.deleteUnusedWorkerPlans(ctx, log, workloadClient, cluster, plan.GetName())
+	}
+	conditions.MarkTrue(kairosConfig, bootstrapv1beta2.OSUpgradeCompletedCondition)
+	return ctrl.Result{}
+}
+
+// deleteUnusedWorkerPlans removes the worker Plans of cluster, other than
+// current, that no Node is labeled for anymore.
+func (r *KairosConfigReconciler) deleteUnusedWorkerPlans(ctx context.Context, log logr.Logger, workloadClient client.Client, cluster *clusterv1.Cluster, current string) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(osupgrade.PlanGVK.GroupVersion().WithKind(osupgrade.PlanGVK.Kind + "List"))
+	if err := workloadClient.List(ctx, list, client.InNamespace(osupgrade.PlanNamespace), client.MatchingLabels{clusterv1.ClusterNameLabel: cluster.Name}); err != nil {
+		log.V(4).Info("Failed to list worker Plans for cleanup", "error", err.Error())
+		return
+	}
+	for i := range list.Items {
+		name := list.Items[i].GetName()
+		if name == current {
+			continue
+		}
+		nodes := &corev1.NodeList{}
+		if err := workloadClient.List(ctx, nodes, client.MatchingLabels{osupgrade.PlanNodeLabel: name}, client.Limit(1)); err != nil {
+			log.V(4).Info("Failed to list nodes of worker Plan", "plan", name, "error", err.Error())
+			continue
+		}
+		if len(nodes.Items) > 0 {
+			continue
+		}
+		if err := workloadClient.Delete(ctx, &list.Items[i]); client.IgnoreNotFound(err) != nil {
+			log.V(4).Info("Failed to delete unused worker Plan", "plan", name, "error", err.Error())
+		}
+	}
+}
+
+// markOSUpgradeError records a workload cluster error on the
+// OSUpgradeCompleted condition and schedules a retry.
+func (r *KairosConfigReconciler) markOSUpgradeError(log logr.Logger, kairosConfig *bootstrapv1beta2.KairosConfig, err error) ctrl.Result {
+	if errors.Is(err, remote.ErrClusterLocked) {
+		return ctrl.Result{RequeueAfter: 5 * time.Second}
+	}
+	log.V(4).Info("OS upgrade step failed, will retry", "error", err.Error())
+	conditions.MarkUnknown(kairosConfig, bootstrapv1beta2.OSUpgradeCompletedCondition, bootstrapv1beta2.WorkloadClusterUnreachableReason, "%s", err.Error())
+	return ctrl.Result{RequeueAfter: 30 * time.Second}
+}
+
+// nodeToKairosConfig maps workload cluster Nodes of cluster to the KairosConfig
+// of the Machine they back.
+func (r *KairosConfigReconciler) nodeToKairosConfig(cluster client.ObjectKey) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		node, ok := o.(*corev1.Node)
+		if !ok {
+			return nil
+		}
+		machines, err := workload.MachinesForNode(ctx, r.Client, cluster, node)
+		if err != nil {
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, machine := range machines {
+			configRef := machine.Spec.Bootstrap.ConfigRef
+			if configRef == nil || configRef.Kind != "KairosConfig" {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: machine.Namespace, Name: configRef.Name},
+			})
+		}
+		return requests
+	}
+}
+
+// patchKairosConfig persists kairosConfig even when the manager is shutting down,
+// so a bootstrap secret that was just written is always recorded in status.
+// The v1beta2 conditions are refreshed from the legacy ones first, and join
+// tokens are removed from the condition and failure messages.
+func (r *KairosConfigReconciler) patchKairosConfig(ctx context.Context, helper *patch.Helper, kairosConfig *bootstrapv1beta2.KairosConfig) error {
+	v1beta2conditions.Mirror(kairosConfig, clusterv1.ReadyCondition,
+		bootstrapv1beta2.ReadyV1Beta2Reason, bootstrapv1beta2.NotReadyV1Beta2Reason)
+	v1beta2conditions.Mirror(kairosConfig, bootstrapv1beta2.DataSecretAvailableCondition,
+		bootstrapv1beta2.DataSecretAvailableV1Beta2Reason, bootstrapv1beta2.DataSecretNotAvailableV1Beta2Reason)
+	if conditions.Has(kairosConfig, bootstrapv1beta2.PausedCondition) {
+		v1beta2conditions.Mirror(kairosConfig, bootstrapv1beta2.PausedCondition,
+			bootstrapv1beta2.PausedV1Beta2Reason, bootstrapv1beta2.NotPausedV1Beta2Reason)
+	}
+	redact.Conditions(kairosConfig.Status.Conditions)
+	if kairosConfig.Status.V1Beta2 != nil {
+		redact.V1Beta2Conditions(kairosConfig.Status.V1Beta2.Conditions)
+	}
+	kairosConfig.Status.FailureMessage = redact.String(kairosConfig.Status.FailureMessage)
+	patchCtx, cancel := shutdown.DetachedContext(ctx)
+	defer cancel()
+	return helper.Patch(patchCtx, kairosConfig)
 }
 
 func (r *KairosConfigReconciler) reconcileBootstrapData(ctx context.Context, log logr.Logger, kairosConfig *bootstrapv1beta2.KairosConfig, machine *clusterv1.Machine, cluster *clusterv1.Cluster) (ctrl.Result, error) {
@@ -304,6 +698,7 @@ func (r *KairosConfigReconciler) reconcileBootstrapData(ctx context.Context, log
 	}
 
 	// If dataSecretName is already set, verify the secret exists and check if regeneration is needed
+	specChanged := false
 	if kairosConfig.Status.DataSecretName != nil {
 		secret := &corev1.Secret{}
 		secretKey := types.NamespacedName{
@@ -319,20 +714,28 @@ func (r *KairosConfigReconciler) reconcileBootstrapData(ctx context.Context, log
 				return ctrl.Result{}, fmt.Errorf("failed to get bootstrap secret: %w", err)
 			}
 		} else {
-			// Secret exists, check if we need to regenerate it due to providerID availability
+			// Secret exists, check if we need to regenerate it due to a spec change
+			// or providerID availability
 			needsRegeneration := false
 			currentProviderID := r.getProviderID(ctx, log, machine)
 
+			if kairosConfig.Spec.RegenerateOnChange && kairosConfig.Status.DataSecretGeneration != 0 &&
+				kairosConfig.Status.DataSecretGeneration != kairosConfig.Generation {
+				log.Info("KairosConfig spec changed since the bootstrap secret was generated, regenerating",
+					"secret", *kairosConfig.Status.DataSecretName,
+					"dataSecretGeneration", kairosConfig.Status.DataSecretGeneration,
+					"generation", kairosConfig.Generation)
+				specChanged = true
+				needsRegeneration = true
+			}
+
 			if currentProviderID != "" {
 				// Machine has providerID, check if the secret contains it
-				secretData, ok := secret.Data["value"]
-				if !ok {
-					log.Info("Bootstrap secret missing data, regenerating", "secret", *kairosConfig.Status.DataSecretName)
+				cloudConfigStr, err := bootstrap.CloudConfigFromBootstrapData(secret.Data)
+				if err != nil {
+					log.Info("Bootstrap secret missing data, regenerating", "secret", *kairosConfig.Status.DataSecretName, "error", err.Error())
 					needsRegeneration = true
 				} else {
-					// Kubernetes Secrets are stored base64-encoded in etcd, but client-go
-					// already decodes them into Secret.Data. Treat it as plain text.
-					cloudConfigStr := string(secretData)
 					// Check if providerID is present in the script
 					hasProviderIDInSecret := strings.Contains(cloudConfigStr, currentProviderID)
 
@@ -376,6 +779,11 @@ func (r *KairosConfigReconciler) reconcileBootstrapData(ctx context.Context, log
 				// Secret exists and is up-to-date, verify it's ready
 				log.V(4).Info("Bootstrap data already generated and up-to-date", "secret", *kairosConfig.Status.DataSecretName)
 				kairosConfig.Status.Ready = true
+				// Secrets generated before the generation was recorded are
+				// assumed to match the current spec
+				if kairosConfig.Status.DataSecretGeneration == 0 {
+					kairosConfig.Status.DataSecretGeneration = kairosConfig.Generation
+				}
 				// Ensure initialization.dataSecretCreated is set
 				if kairosConfig.Status.Initialization == nil {
 					kairosConfig.Status.Initialization = &bootstrapv1beta2.KairosConfigInitialization{}
@@ -401,6 +809,11 @@ func (r *KairosConfigReconciler) reconcileBootstrapData(ctx context.Context, log
 	}
 
 	// Generate Kairos cloud-config
+	distribution := kairosConfig.Spec.Distribution
+	if distribution == "" {
+		distribution = "k0s"
+	}
+	generationStart := time.Now()
 	cloudConfig, err := r.generateCloudConfig(ctx, log, kairosConfig, machine, cluster)
 	if err != nil {
 		if errors.Is(err, errLBEndpointNotReady) {
@@ -409,12 +822,29 @@ func (r *KairosConfigReconciler) reconcileBootstrapData(ctx context.Context, log
 		}
 		if errors.Is(err, errK3sTokenNotReady) {
 			log.Info("Waiting for k3s token secret before generating cloud-config")
+			r.eventf(kairosConfig, machine, corev1.EventTypeNormal, bootstrapv1beta2.TokenMissingEvent, "Waiting for the k3s join token secret")
 			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
 		}
+		if errors.Is(err, errControllerTokenNotReady) {
+			log.Info("Waiting for k0s controller token secret before generating cloud-config")
+			r.eventf(kairosConfig, machine, corev1.EventTypeNormal, bootstrapv1beta2.TokenMissingEvent, "Waiting for the k0s controller join token secret")
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+		if errors.Is(err, errWorkerTokenNotReady) {
+			log.Info("Waiting for the workload cluster kubeconfig to provision a worker token")
+			r.eventf(kairosConfig, machine, corev1.EventTypeNormal, bootstrapv1beta2.TokenMissingEvent, "Waiting for the workload cluster kubeconfig to provision a worker join token")
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+		var tokenErr *tokenFetchError
+		if errors.As(err, &tokenErr) {
+			metrics.TokenFetchFailures.WithLabelValues(distribution).Inc()
+			r.eventf(kairosConfig, machine, corev1.EventTypeWarning, bootstrapv1beta2.TokenMissingEvent, "Failed to get join token: %v", err)
+		}
 		return ctrl.Result{}, fmt.Errorf("failed to generate cloud-config: %w", err)
 	}
+	metrics.BootstrapDataGenerationDuration.WithLabelValues(distribution).Observe(time.Since(generationStart).Seconds())
 
-	// Store the cloud-config as plain text in the secret
+	// Store the cloud-config in spec.format; the cloud-config format keeps it as plain text
 	// Kubernetes will automatically base64 encode it when storing in etcd
 	// CAPV will read it, base64 decode it (removing Kubernetes encoding), and get plain text
 	// CAPV will then pass it to VMware guestinfo properties as userdata (and possibly vendordata)
@@ -450,8 +880,8 @@ func (r *KairosConfigReconciler) reconcileBootstrapData(ctx context.Context, log
 			},
 			OwnerReferences: []metav1.OwnerReference{
 				{
-					APIVersion: kairosConfig.APIVersion,
-					Kind:       kairosConfig.Kind,
+					APIVersion: bootstrapv1beta2.GroupVersion.String(),
+					Kind:       "KairosConfig",
 					Name:       kairosConfig.Name,
 					UID:        kairosConfig.UID,
 					Controller: func() *bool { b := true; return &b }(),
@@ -459,33 +889,58 @@ func (r *KairosConfigReconciler) reconcileBootstrapData(ctx context.Context, log
 			},
 		},
 		Type: clusterv1.ClusterSecretType,
-		Data: map[string][]byte{
-			"value": []byte(cloudConfig),
-		},
+	}
+	instanceID := kairosConfig.Name
+	if machine != nil {
+		instanceID = machine.Name
+	}
+	secret.Data, err = bootstrap.BootstrapData(kairosConfig.Spec.Format, cloudConfig, instanceID)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 
-	// Create or update the secret in-place to preserve the name referenced by Machine
+	// Create or update the secret in-place to preserve the name referenced by Machine.
+	// The write must not be interrupted by a manager shutdown, otherwise the secret
+	// would exist without being recorded in status.
+	writeCtx, cancel := shutdown.DetachedContext(ctx)
+	defer cancel()
 	existingSecret := &corev1.Secret{}
-	if err := r.Get(ctx, secretKey, existingSecret); err != nil {
+	created, dataChanged := false, false
+	if err := r.Get(writeCtx, secretKey, existingSecret); err != nil {
 		if apierrors.IsNotFound(err) {
-			if err := r.Create(ctx, secret); err != nil {
+			if err := r.Create(writeCtx, secret); err != nil {
 				return ctrl.Result{}, err
 			}
+			created = true
 		} else {
 			return ctrl.Result{}, err
 		}
 	} else {
+		dataChanged = !reflect.DeepEqual(existingSecret.Data, secret.Data)
 		existingSecret.Type = secret.Type
 		existingSecret.Labels = secret.Labels
 		existingSecret.OwnerReferences = secret.OwnerReferences
 		existingSecret.Data = secret.Data
-		if err := r.Update(ctx, existingSecret); err != nil {
+		if err := r.Update(writeCtx, existingSecret); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
+	if created || dataChanged {
+		r.eventf(kairosConfig, machine, corev1.EventTypeNormal, bootstrapv1beta2.BootstrapDataGeneratedEvent, "Generated bootstrap data secret %s", secretName)
+	}
+
 	// Update status with dataSecretName
 	kairosConfig.Status.DataSecretName = &secretName
+	kairosConfig.Status.DataSecretGeneration = kairosConfig.Generation
+
+	// A Machine that already booted from the previous data keeps running it;
+	// flag it so a rolling replacement picks up the new configuration
+	if specChanged && dataChanged && machine != nil && machine.Status.InfrastructureReady {
+		if err := r.markBootstrapDataOutdated(writeCtx, log, machine, kairosConfig.Generation); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
 
 	// Mark secret as Ready - providerID will be included if available, otherwise it will be regenerated later
 	// We allow the secret to be Ready even without providerID initially, so VM can be created
@@ -548,6 +1003,43 @@ func (r *KairosConfigReconciler) reconcileBootstrapData(ctx context.Context, log
 	return ctrl.Result{}, nil
 }
 
+// markBootstrapDataOutdated sets the BootstrapDataOutdatedAnnotation on
+// machine and on the MachineDeployment owning it
+func (r *KairosConfigReconciler) markBootstrapDataOutdated(ctx context.Context, log logr.Logger, machine *clusterv1.Machine, generation int64) error {
+	value := strconv.FormatInt(generation, 10)
+
+	patchBase := client.MergeFrom(machine.DeepCopy())
+	if machine.Annotations == nil {
+		machine.Annotations = map[string]string{}
+	}
+	machine.Annotations[bootstrapv1beta2.BootstrapDataOutdatedAnnotation] = value
+	if err := r.Patch(ctx, machine, patchBase); err != nil {
+		return fmt.Errorf("failed to annotate machine %s: %w", machine.Name, err)
+	}
+	log.Info("Machine runs outdated bootstrap data", "machine", machine.Name, "generation", value)
+
+	deploymentName, ok := machine.Labels[clusterv1.MachineDeploymentNameLabel]
+	if !ok {
+		return nil
+	}
+	deployment := &clusterv1.MachineDeployment{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: machine.Namespace, Name: deploymentName}, deployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get MachineDeployment %s: %w", deploymentName, err)
+	}
+	patchBase = client.MergeFrom(deployment.DeepCopy())
+	if deployment.Annotations == nil {
+		deployment.Annotations = map[string]string{}
+	}
+	deployment.Annotations[bootstrapv1beta2.BootstrapDataOutdatedAnnotation] = value
+	if err := r.Patch(ctx, deployment, patchBase); err != nil {
+		return fmt.Errorf("failed to annotate MachineDeployment %s: %w", deploymentName, err)
+	}
+	return nil
+}
+
 func isKubevirtMachine(machine *clusterv1.Machine) bool {
 	if machine == nil {
 		return false
@@ -573,7 +1065,9 @@ func (r *KairosConfigReconciler) sanitizeCapkUserdataSecret(ctx context.Context,
 		Namespace: kairosConfig.Namespace,
 	}
 
-	if err := r.Get(ctx, secretKey, secret); err != nil {
+	// CAPK creates the userdata secret without the cluster-name label, so it
+	// is not in the filtered cache
+	if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, secret); err != nil {
 		if apierrors.IsNotFound(err) {
 			return false, false, nil
 		}
@@ -730,28 +1224,46 @@ func (r *KairosConfigReconciler) generateK0sCloudConfig(ctx context.Context, log
 	}
 
 	// Get worker token if needed (for worker nodes)
-	// Precedence: WorkerTokenSecretRef > WorkerToken > TokenSecretRef > Token
-	// TODO: Add validating webhook to enforce worker token requirement at API level
+	// Precedence: WorkerTokenSecretRef > WorkerToken > TokenSecretRef > Token.
+	// When none of them is set, a join token is provisioned from the workload
+	// cluster and recorded in status, so a token is never required at the API
+	// level. The spec is left as the user wrote it.
 	var workerToken string
 	if role == "worker" {
+		workerTokenSecretRef := kairosConfig.Spec.WorkerTokenSecretRef
+		kairosConfig.Status.WorkerTokenSecretRef = nil
+		// Without a configured token, provision one through the workload cluster
+		if kairosConfig.Spec.WorkerTokenSecretRef == nil && kairosConfig.Spec.WorkerToken == "" &&
+			kairosConfig.Spec.TokenSecretRef == nil && kairosConfig.Spec.Token == "" {
+			ref, err := r.ensureWorkerTokenSecret(ctx, log, cluster)
+			if err != nil {
+				if errors.Is(err, errWorkerTokenNotReady) {
+					return "", err
+				}
+				return "", tokenFetchFailed(err)
+			}
+			kairosConfig.Status.WorkerTokenSecretRef = ref
+			workerTokenSecretRef = ref
+		}
+
 		// Try WorkerTokenSecretRef first (most secure)
-		if kairosConfig.Spec.WorkerTokenSecretRef != nil {
+		if workerTokenSecretRef != nil {
 			secretKey := types.NamespacedName{
 				Namespace: kairosConfig.Namespace,
-				Name:      kairosConfig.Spec.WorkerTokenSecretRef.Name,
+				Name:      workerTokenSecretRef.Name,
 			}
 			// Use specified namespace or fall back to KairosConfig namespace
-			if kairosConfig.Spec.WorkerTokenSecretRef.Namespace != "" {
-				secretKey.Namespace = kairosConfig.Spec.WorkerTokenSecretRef.Namespace
+			if workerTokenSecretRef.Namespace != "" {
+				secretKey.Namespace = workerTokenSecretRef.Namespace
 			}
 
 			secret := &corev1.Secret{}
-			if err := r.Get(ctx, secretKey, secret); err != nil {
-				return "", fmt.Errorf("failed to get worker token secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err)
+			if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, secret); err != nil {
+				return "", tokenFetchFailed(fmt.Errorf("failed to get worker token secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err))
 			}
 
 			// Use specified key or default to "token"
-			key := kairosConfig.Spec.WorkerTokenSecretRef.Key
+			key := workerTokenSecretRef.Key
 			if key == "" {
 				key = "token"
 			}
@@ -759,7 +1271,7 @@ func (r *KairosConfigReconciler) generateK0sCloudConfig(ctx context.Context, log
 			if tokenData, ok := secret.Data[key]; ok {
 				workerToken = string(tokenData)
 			} else {
-				return "", fmt.Errorf("worker token secret %s/%s does not contain key '%s'", secretKey.Namespace, secretKey.Name, key)
+				return "", tokenFetchFailed(fmt.Errorf("worker token secret %s/%s does not contain key '%s'", secretKey.Namespace, secretKey.Name, key))
 			}
 		} else if kairosConfig.Spec.WorkerToken != "" {
 			// Fall back to inline WorkerToken
@@ -771,8 +1283,8 @@ func (r *KairosConfigReconciler) generateK0sCloudConfig(ctx context.Context, log
 				Namespace: cluster.Namespace,
 				Name:      kairosConfig.Spec.TokenSecretRef.Name,
 			}
-			if err := r.Get(ctx, secretKey, secret); err != nil {
-				return "", fmt.Errorf("failed to get token secret: %w", err)
+			if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, secret); err != nil {
+				return "", tokenFetchFailed(fmt.Errorf("failed to get token secret: %w", err))
 			}
 			// Try common token keys
 			if tokenData, ok := secret.Data["token"]; ok {
@@ -780,7 +1292,7 @@ func (r *KairosConfigReconciler) generateK0sCloudConfig(ctx context.Context, log
 			} else if tokenData, ok := secret.Data["value"]; ok {
 				workerToken = string(tokenData)
 			} else {
-				return "", fmt.Errorf("token secret does not contain 'token' or 'value' key")
+				return "", tokenFetchFailed(fmt.Errorf("token secret does not contain 'token' or 'value' key"))
 			}
 		} else if kairosConfig.Spec.Token != "" {
 			// Fall back to legacy Token
@@ -793,14 +1305,45 @@ func (r *KairosConfigReconciler) generateK0sCloudConfig(ctx context.Context, log
 		}
 	}
 
+	// Control plane nodes after the first join the existing control plane with a
+	// controller token, which only exists once the first node is up
+	var controllerToken string
+	if role == "control-plane" && kairosConfig.Spec.ControllerTokenSecretRef != nil {
+		secretKey := types.NamespacedName{
+			Namespace: kairosConfig.Namespace,
+			Name:      kairosConfig.Spec.ControllerTokenSecretRef.Name,
+		}
+		if kairosConfig.Spec.ControllerTokenSecretRef.Namespace != "" {
+			secretKey.Namespace = kairosConfig.Spec.ControllerTokenSecretRef.Namespace
+		}
+
+		secret := &corev1.Secret{}
+		if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				return "", errControllerTokenNotReady
+			}
+			return "", tokenFetchFailed(fmt.Errorf("failed to get controller token secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err))
+		}
+
+		key := kairosConfig.Spec.ControllerTokenSecretRef.Key
+		if key == "" {
+			key = "token"
+		}
+		tokenData, ok := secret.Data[key]
+		if !ok || len(tokenData) == 0 {
+			return "", errControllerTokenNotReady
+		}
+		controllerToken = string(tokenData)
+	}
+
 	// Set defaults for user configuration
 	userName := kairosConfig.Spec.UserName
 	if userName == "" {
 		userName = "kairos"
 	}
-	userPassword := kairosConfig.Spec.UserPassword
-	if userPassword == "" {
-		userPassword = "kairos"
+	userPassword, err := r.resolveUserPassword(ctx, kairosConfig)
+	if err != nil {
+		return "", err
 	}
 	userGroups := kairosConfig.Spec.UserGroups
 	if len(userGroups) == 0 {
@@ -813,30 +1356,25 @@ func (r *KairosConfigReconciler) generateK0sCloudConfig(ctx context.Context, log
 		hostnamePrefix = "metal-"
 	}
 
-	// Prefer explicit hostname, otherwise use Machine name
-	hostname := kairosConfig.Spec.Hostname
-	if hostname == "" && machine != nil {
-		hostname = machine.Name
-	}
+	hostname := nodeHostname(kairosConfig, machine, cluster)
 
-	// Set install configuration (with defaults)
-	var installConfig *bootstrap.InstallConfig
-	if kairosConfig.Spec.Install != nil {
-		installConfig = &bootstrap.InstallConfig{
-			Auto:   true,   // Default to true
-			Device: "auto", // Default to "auto"
-			Reboot: true,   // Default to true
-		}
-		if kairosConfig.Spec.Install.Auto != nil {
-			installConfig.Auto = *kairosConfig.Spec.Install.Auto
-		}
-		if kairosConfig.Spec.Install.Device != "" {
-			installConfig.Device = kairosConfig.Spec.Install.Device
-		}
-		if kairosConfig.Spec.Install.Reboot != nil {
-			installConfig.Reboot = *kairosConfig.Spec.Install.Reboot
+	kubeletArgs := kairosConfig.Spec.KubeletExtraArgs
+	var metadataYAML string
+	metadata := machineMetadata(kairosConfig, role, machine, cluster)
+	if metadata != nil {
+		metadataYAML, err = metadata.YAML()
+		if err != nil {
+			return "", err
 		}
+		kubeletArgs = bootstrap.MergeNodeLabels(kubeletArgs, metadata.NodeLabels())
 	}
+	completionCallback, err := bootstrap.CompletionCallbackScript("k0s", kairosConfig.Spec.CompletionCallback, metadata)
+	if err != nil {
+		return "", err
+	}
+
+	// Set install configuration (with defaults)
+	installConfig := installConfig(kairosConfig.Spec)
 
 	if installConfig != nil {
 		log.Info("Using install configuration", "auto", installConfig.Auto, "device", installConfig.Device, "reboot", installConfig.Reboot)
@@ -848,6 +1386,34 @@ func (r *KairosConfigReconciler) generateK0sCloudConfig(ctx context.Context, log
 	// This is needed to set the Node's providerID so the Machine controller can match Nodes to Machines
 	providerID := r.getProviderID(ctx, log, machine)
 
+	files, downloads, err := r.resolveFiles(ctx, kairosConfig)
+	if err != nil {
+		return "", err
+	}
+	manifests, err := r.resolveManifests(ctx, kairosConfig)
+	if err != nil {
+		return "", err
+	}
+	if role == "control-plane" {
+		certificateFiles, err := r.controlPlaneCertificateFiles(ctx, kairosConfig, cluster)
+		if err != nil {
+			return "", err
+		}
+		files = append(certificateFiles, files...)
+	}
+	registryAuths, err := r.resolveRegistryAuths(ctx, kairosConfig)
+	if err != nil {
+		return "", err
+	}
+	sshAuthorizedKeys, err := r.resolveSSHAuthorizedKeys(ctx, kairosConfig)
+	if err != nil {
+		return "", err
+	}
+	stages, err := bootstrap.RenderStages(kairosConfig.Spec.Stages)
+	if err != nil {
+		return "", fmt.Errorf("failed to render stages: %w", err)
+	}
+
 	var kubeconfigPush *kubeconfigPushConfig
 	if isKubevirtMachine(machine) && role == "control-plane" {
 		var err error
@@ -867,8 +1433,13 @@ func (r *KairosConfigReconciler) generateK0sCloudConfig(ctx context.Context, log
 		UserGroups:                          userGroups,
 		GitHubUser:                          kairosConfig.Spec.GitHubUser,
 		SSHPublicKey:                        kairosConfig.Spec.SSHPublicKey,
+		SSHAuthorizedKeys:                   sshAuthorizedKeys,
+		Users:                               kairosUsers(kairosConfig.Spec.Users),
 		WorkerToken:                         workerToken,
-		Manifests:                           kairosConfig.Spec.Manifests,
+		ControllerToken:                     controllerToken,
+		Manifests:                           manifests,
+		Files:                               files,
+		Downloads:                           downloads,
 		HostnamePrefix:                      hostnamePrefix,
 		DNSServers:                          kairosConfig.Spec.DNSServers,
 		PodCIDR:                             kairosConfig.Spec.PodCIDR,
@@ -879,6 +1450,16 @@ func (r *KairosConfigReconciler) generateK0sCloudConfig(ctx context.Context, log
 		IsKubeVirt:                          isKubevirtMachine(machine),
 		Install:                             installConfig,
 		ProviderID:                          providerID,
+		KubeletExtraArgs:                    kubeletExtraArgs(kubeletArgs),
+		ExtraInstallArgs:                    k0sExtraInstallArgs(kairosConfig.Spec, role),
+		Airgap:                              kairosConfig.Spec.Airgap,
+		RegistryAuths:                       registryAuths,
+		Proxy:                               proxyConfig(kairosConfig.Spec.Proxy),
+		NetworkFiles:                        bootstrap.NetworkdFiles(kairosConfig.Spec.Network),
+		Kcrypt:                              kcryptConfig(kairosConfig.Spec.Encryption),
+		Stages:                              stages,
+		MachineMetadata:                     metadataYAML,
+		CompletionCallback:                  completionCallback,
 		ControlPlaneLBServiceName:           "",
 		ControlPlaneLBServiceNamespace:      "",
 		ControlPlaneLBEndpoint:              "",
@@ -902,60 +1483,712 @@ func (r *KairosConfigReconciler) generateK0sCloudConfig(ctx context.Context, log
 		templateData.ControlPlaneLBServiceNamespace = cluster.Namespace
 	}
 	if cluster != nil && isKubevirtMachine(machine) && role == "control-plane" {
-		lbEndpoint, err := r.getControlPlaneLBEndpoint(ctx, cluster.Namespace, templateData.ControlPlaneLBServiceName)
+		lbEndpoint, err := r.kubevirtControlPlaneEndpoint(ctx, kairosConfig, cluster, templateData.ControlPlaneLBServiceName)
 		if err != nil {
 			return "", err
 		}
-		if lbEndpoint == "" {
-			return "", errLBEndpointNotReady
-		}
 		templateData.ControlPlaneLBEndpoint = lbEndpoint
 	}
+	if role == "control-plane" && (kairosConfig.Spec.K0sConfig != nil || len(kairosConfig.Spec.CertSANs) > 0 || kairosConfig.Spec.OIDC != nil) {
+		extraSANs := append([]string{}, kairosConfig.Spec.CertSANs...)
+		if templateData.ControlPlaneLBEndpoint != "" {
+			extraSANs = append(extraSANs, templateData.ControlPlaneLBEndpoint)
+		}
+		cfg := kairosConfig.Spec.K0sConfig
+		if cfg == nil {
+			cfg = &bootstrapv1beta2.K0sConfig{}
+		}
+		k0sConfig, err := bootstrap.RenderK0sClusterConfig(cfg, kairosConfig.Spec.PodCIDR, kairosConfig.Spec.ServiceCIDR, extraSANs, bootstrap.OIDCAPIServerArgs(kairosConfig.Spec.OIDC))
+		if err != nil {
+			return "", fmt.Errorf("failed to render k0s config: %w", err)
+		}
+		templateData.K0sConfig = k0sConfig
+	}
 
 	// Render template
 	return bootstrap.RenderK0sCloudConfig(templateData)
 }
 
-func (r *KairosConfigReconciler) generateK3sCloudConfig(ctx context.Context, log logr.Logger, kairosConfig *bootstrapv1beta2.KairosConfig, machine *clusterv1.Machine, cluster *clusterv1.Cluster, role, serverAddress string) (string, error) {
-	// Determine single-node mode
-	singleNode := kairosConfig.Spec.SingleNode
-	if !singleNode && role == "control-plane" && machine != nil {
-		ownerRef := metav1.GetControllerOf(machine)
-		if ownerRef != nil && ownerRef.Kind == "KairosControlPlane" {
-			log.V(4).Info("Control plane node, single-node mode determined from spec", "singleNode", singleNode)
+// workerJoinTokenSecretName returns the name of the managed Secret holding the
+// k0s worker join token of a cluster.
+func workerJoinTokenSecretName(clusterName string) string {
+	return fmt.Sprintf("%s-k0s-worker-token", clusterName)
+}
+
+// resolveP2PConfig builds the template P2P configuration from spec.p2p, reading
+// the network token from its Secret if referenced. It returns nil without spec.p2p.
+func (r *KairosConfigReconciler) resolveP2PConfig(ctx context.Context, kairosConfig *bootstrapv1beta2.KairosConfig) (*bootstrap.P2PConfig, error) {
+	spec := kairosConfig.Spec.P2P
+	if spec == nil {
+		return nil, nil
+	}
+
+	p2p := &bootstrap.P2PConfig{
+		NetworkToken: spec.NetworkToken,
+		NetworkID:    spec.NetworkID,
+		DNS:          spec.DNS,
+		DisableDHT:   spec.DisableDHT,
+	}
+	if spec.Auto != nil && spec.Auto.Enable {
+		p2p.Auto = true
+		if spec.Auto.HA != nil && spec.Auto.HA.Enable {
+			p2p.HA = true
+			p2p.MasterNodes = spec.Auto.HA.MasterNodes
 		}
 	}
 
-	// Resolve k3s token if needed (for worker nodes)
-	// Precedence: K3sTokenSecretRef > K3sToken > WorkerTokenSecretRef > WorkerToken > TokenSecretRef > Token
-	var k3sToken string
-	if role == "worker" {
-		if kairosConfig.Spec.K3sTokenSecretRef != nil {
-			secretKey := types.NamespacedName{
-				Namespace: kairosConfig.Namespace,
-				Name:      kairosConfig.Spec.K3sTokenSecretRef.Name,
-			}
-			if kairosConfig.Spec.K3sTokenSecretRef.Namespace != "" {
-				secretKey.Namespace = kairosConfig.Spec.K3sTokenSecretRef.Namespace
-			}
+	if ref := spec.NetworkTokenSecretRef; ref != nil {
+		secretKey := types.NamespacedName{Namespace: kairosConfig.Namespace, Name: ref.Name}
+		if ref.Namespace != "" {
+			secretKey.Namespace = ref.Namespace
+		}
+		secret := &corev1.Secret{}
+		if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, secret); err != nil {
+			return nil, fmt.Errorf("failed to get p2p network token secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err)
+		}
+		key := ref.Key
+		if key == "" {
+			key = "token"
+		}
+		tokenData, ok := secret.Data[key]
+		if !ok {
+			return nil, fmt.Errorf("p2p network token secret %s/%s does not contain key '%s'", secretKey.Namespace, secretKey.Name, key)
+		}
+		p2p.NetworkToken = string(tokenData)
+	}
+	if p2p.NetworkToken == "" {
+		return nil, fmt.Errorf("p2p requires networkToken or networkTokenSecretRef")
+	}
 
-			secret := &corev1.Secret{}
-			if err := r.Get(ctx, secretKey, secret); err != nil {
-				if apierrors.IsNotFound(err) {
-					return "", errK3sTokenNotReady
-				}
-				return "", fmt.Errorf("failed to get k3s token secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err)
-			}
+	return p2p, nil
+}
 
-			key := kairosConfig.Spec.K3sTokenSecretRef.Key
-			if key == "" {
-				key = "token"
-			}
+// kubeletExtraArgs returns spec.kubeletExtraArgs as "key=value" pairs sorted
+// by key, so the rendered bootstrap data is stable across reconciles.
+func kubeletExtraArgs(args map[string]string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+args[key])
+	}
+	return pairs
+}
 
-			if tokenData, ok := secret.Data[key]; ok {
-				k3sToken = string(tokenData)
+// proxyConfig converts spec.proxy for the template. It returns nil without a proxy.
+func proxyConfig(proxy *bootstrapv1beta2.ProxyConfig) *bootstrap.ProxyConfig {
+	if proxy == nil || (proxy.HTTPProxy == "" && proxy.HTTPSProxy == "" && proxy.NoProxy == "") {
+		return nil
+	}
+	return &bootstrap.ProxyConfig{
+		HTTPProxy:  proxy.HTTPProxy,
+		HTTPSProxy: proxy.HTTPSProxy,
+		NoProxy:    proxy.NoProxy,
+	}
+}
+
+// kairosUsers converts spec.users for the template
+func kairosUsers(users []bootstrapv1beta2.KairosUser) []bootstrap.User {
+	if len(users) == 0 {
+		return nil
+	}
+	converted := make([]bootstrap.User, 0, len(users))
+	for _, user := range users {
+		converted = append(converted, bootstrap.User{
+			Name:              user.Name,
+			Password:          user.Password,
+			LockPassword:      user.LockPassword,
+			Groups:            user.Groups,
+			SSHAuthorizedKeys: user.SSHAuthorizedKeys,
+			Sudo:              user.Sudo,
+		})
+	}
+	return converted
+}
+
+// installConfig converts spec.install for the template, applying the API
+// defaults. It returns nil without an install block. Trusted Boot images get
+// neither grub options nor the recovery and state partitions.
+func installConfig(spec bootstrapv1beta2.KairosConfigSpec) *bootstrap.InstallConfig {
+	install := spec.Install
+	if install == nil {
+		return nil
+	}
+	config := &bootstrap.InstallConfig{
+		Auto:     true,
+		Device:   "auto",
+		Reboot:   true,
+		NoFormat: install.NoFormat,
+	}
+	if !spec.TrustedBoot {
+		config.GrubOptions = install.GrubOptions
+	}
+	if spec.Encryption != nil {
+		config.EncryptedPartitions = spec.Encryption.Partitions
+		if len(config.EncryptedPartitions) == 0 {
+			config.EncryptedPartitions = []string{"COS_PERSISTENT"}
+		}
+	}
+	if install.Auto != nil {
+		config.Auto = *install.Auto
+	}
+	if install.Device != "" {
+		config.Device = install.Device
+	}
+	if install.Reboot != nil {
+		config.Reboot = *install.Reboot
+	}
+	if p := install.Partitions; p != nil {
+		for _, partition := range []struct {
+			name string
+			spec *bootstrapv1beta2.InstallPartition
+		}{
+			{"oem", p.OEM},
+			{"recovery", p.Recovery},
+			{"state", p.State},
+			{"persistent", p.Persistent},
+		} {
+			if partition.spec == nil || (partition.spec.Size == 0 && partition.spec.Filesystem == "") {
+				continue
+			}
+			if spec.TrustedBoot && (partition.name == "recovery" || partition.name == "state") {
+				continue
+			}
+			config.Partitions = append(config.Partitions, bootstrap.InstallPartition{
+				Name:       partition.name,
+				Size:       partition.spec.Size,
+				Filesystem: partition.spec.Filesystem,
+			})
+		}
+	}
+	return config
+}
+
+// kcryptConfig converts spec.encryption to the kcrypt challenger
+// configuration. It returns nil when the keys are sealed to the local TPM
+// with the kcrypt defaults.
+func kcryptConfig(encryption *bootstrapv1beta2.EncryptionConfig) *bootstrap.KcryptConfig {
+	if encryption == nil {
+		return nil
+	}
+	config := &bootstrap.KcryptConfig{
+		ChallengerServer: encryption.ChallengerServer,
+		MDNS:             encryption.MDNS,
+	}
+	if encryption.TPM != nil {
+		config.NVIndex = encryption.TPM.NVIndex
+		config.CIndex = encryption.TPM.CIndex
+		config.TPMDevice = encryption.TPM.Device
+	}
+	if *config == (bootstrap.KcryptConfig{}) {
+		return nil
+	}
+	return config
+}
+
+// k0sExtraInstallArgs returns spec.extraInstallArgs plus the k0s flags derived
+// from other spec fields for a node of role.
+func k0sExtraInstallArgs(spec bootstrapv1beta2.KairosConfigSpec, role string) []string {
+	args := append([]string{}, spec.ExtraInstallArgs...)
+	if role == "control-plane" && spec.K0sConfig != nil && spec.K0sConfig.DynamicConfig {
+		args = append(args, "--enable-dynamic-config")
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return args
+}
+
+// k3sExtraInstallArgs returns spec.extraInstallArgs plus the k3s flags derived
+// from other spec fields for a node of role.
+func k3sExtraInstallArgs(spec bootstrapv1beta2.KairosConfigSpec, role string) []string {
+	args := append([]string{}, spec.ExtraInstallArgs...)
+	if role == "control-plane" {
+		for _, san := range spec.CertSANs {
+			args = append(args, "--tls-san="+san)
+		}
+		args = append(args, bootstrap.K3sAPIServerArgs(bootstrap.OIDCAPIServerArgs(spec.OIDC))...)
+	}
+	if spec.Airgap != nil && spec.Airgap.DisableDefaultRegistry {
+		args = append(args, "--disable-default-registry-endpoint")
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return args
+}
+
+// resolveFiles splits spec.files into files written through write_files and
+// files the node downloads at boot. Content referenced from a Secret or
+// ConfigMap is base64-encoded unless the file sets an encoding, so binary
+// content survives the cloud-config.
+func (r *KairosConfigReconciler) resolveFiles(ctx context.Context, kairosConfig *bootstrapv1beta2.KairosConfig) ([]bootstrap.File, []bootstrap.Download, error) {
+	var files []bootstrap.File
+	var downloads []bootstrap.Download
+	for _, f := range kairosConfig.Spec.Files {
+		source := f.ContentFrom
+		if source != nil && source.URL != "" {
+			downloads = append(downloads, bootstrap.Download{
+				Path:        f.Path,
+				URL:         source.URL,
+				Permissions: f.Permissions,
+				Owner:       f.Owner,
+			})
+			continue
+		}
+
+		file := bootstrap.File{
+			Path:        f.Path,
+			Content:     f.Content,
+			Encoding:    string(f.Encoding),
+			Permissions: f.Permissions,
+			Owner:       f.Owner,
+		}
+		if source != nil {
+			data, err := r.getFileSourceData(ctx, kairosConfig.Namespace, source)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get content of file %s: %w", f.Path, err)
+			}
+			if f.Encoding == "" {
+				file.Content = base64.StdEncoding.EncodeToString(data)
+				file.Encoding = string(bootstrapv1beta2.Base64FileEncoding)
+			} else {
+				file.Content = string(data)
+			}
+		}
+		files = append(files, file)
+	}
+	return files, downloads, nil
+}
+
+// controlPlaneCertificateFiles returns the files installing the cluster
+// certificates on a control plane node. The certificate Secrets are generated
+// here, owned by the Cluster, when no KairosControlPlane created them yet.
+func (r *KairosConfigReconciler) controlPlaneCertificateFiles(ctx context.Context, kairosConfig *bootstrapv1beta2.KairosConfig, cluster *clusterv1.Cluster) ([]bootstrap.File, error) {
+	if cluster == nil {
+		return nil, nil
+	}
+	certificates := bootstrap.ClusterCertificates()
+	owner := metav1.OwnerReference{
+		APIVersion: clusterv1.GroupVersion.String(),
+		Kind:       "Cluster",
+		Name:       cluster.Name,
+		UID:        cluster.UID,
+	}
+	if err := certificates.LookupOrGenerate(ctx, r.Client, util.ObjectKey(cluster), owner); err != nil {
+		return nil, fmt.Errorf("failed to look up or generate cluster certificates: %w", err)
+	}
+	return bootstrap.CertificateFiles(kairosConfig.Spec.Distribution, certificates), nil
+}
+
+// resolveManifests returns spec.manifests with the content referenced from
+// Secrets and ConfigMaps filled in. The manifests end up in the bootstrap data
+// Secret, so together they must fit the Secret size limit.
+func (r *KairosConfigReconciler) resolveManifests(ctx context.Context, kairosConfig *bootstrapv1beta2.KairosConfig) ([]bootstrapv1beta2.Manifest, error) {
+	if len(kairosConfig.Spec.Manifests) == 0 {
+		return nil, nil
+	}
+	manifests := make([]bootstrapv1beta2.Manifest, 0, len(kairosConfig.Spec.Manifests))
+	size := 0
+	for _, m := range kairosConfig.Spec.Manifests {
+		manifest := bootstrapv1beta2.Manifest{Name: m.Name, File: m.File, Content: m.Content}
+		if source := m.ContentFrom; source != nil {
+			data, err := r.getFileSourceData(ctx, kairosConfig.Namespace, &bootstrapv1beta2.FileSource{Secret: source.Secret, ConfigMap: source.ConfigMap})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get content of manifest %s/%s: %w", m.Name, m.File, err)
+			}
+			manifest.Content = string(data)
+		}
+		size += len(manifest.Content)
+		if size > corev1.MaxSecretSize {
+			return nil, fmt.Errorf("manifests exceed the %d bytes a bootstrap data Secret can hold at %s/%s", corev1.MaxSecretSize, m.Name, m.File)
+		}
+		manifests = append(manifests, manifest)
+	}
+	return manifests, nil
+}
+
+// getFileSourceData reads the Secret or ConfigMap key a file's contentFrom points at
+func (r *KairosConfigReconciler) getFileSourceData(ctx context.Context, namespace string, source *bootstrapv1beta2.FileSource) ([]byte, error) {
+	switch {
+	case source.Secret != nil:
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Namespace: namespace, Name: source.Secret.Name}
+		if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, key, secret); err != nil {
+			return nil, fmt.Errorf("failed to get secret %s/%s: %w", key.Namespace, key.Name, err)
+		}
+		data, ok := secret.Data[source.Secret.Key]
+		if !ok {
+			return nil, fmt.Errorf("secret %s/%s does not contain key '%s'", key.Namespace, key.Name, source.Secret.Key)
+		}
+		return data, nil
+	case source.ConfigMap != nil:
+		configMap := &corev1.ConfigMap{}
+		key := types.NamespacedName{Namespace: namespace, Name: source.ConfigMap.Name}
+		if err := cachefilter.GetConfigMap(ctx, r.Client, r.APIReader, key, configMap); err != nil {
+			return nil, fmt.Errorf("failed to get configmap %s/%s: %w", key.Namespace, key.Name, err)
+		}
+		if data, ok := configMap.Data[source.ConfigMap.Key]; ok {
+			return []byte(data), nil
+		}
+		if data, ok := configMap.BinaryData[source.ConfigMap.Key]; ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf("configmap %s/%s does not contain key '%s'", key.Namespace, key.Name, source.ConfigMap.Key)
+	default:
+		return nil, fmt.Errorf("contentFrom must set secret, configMap or url")
+	}
+}
+
+// resolveSSHAuthorizedKeys merges spec.sshAuthorizedKeys with the keys held in
+// the Secrets of spec.sshKeySecretRefs. Secret values are read in key order,
+// one SSH key per line; blank lines and comments are skipped, as are keys seen
+// before.
+func (r *KairosConfigReconciler) resolveSSHAuthorizedKeys(ctx context.Context, kairosConfig *bootstrapv1beta2.KairosConfig) ([]string, error) {
+	var keys []string
+	seen := map[string]bool{}
+	add := func(key string) {
+		key = strings.TrimSpace(key)
+		if key == "" || strings.HasPrefix(key, "#") || seen[key] {
+			return
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+
+	for _, key := range kairosConfig.Spec.SSHAuthorizedKeys {
+		add(key)
+	}
+	for _, ref := range kairosConfig.Spec.SSHKeySecretRefs {
+		secretKey := types.NamespacedName{Namespace: kairosConfig.Namespace, Name: ref.Name}
+		if ref.Namespace != "" {
+			secretKey.Namespace = ref.Namespace
+		}
+		secret := &corev1.Secret{}
+		if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, secret); err != nil {
+			return nil, fmt.Errorf("failed to get SSH key secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err)
+		}
+		dataKeys := make([]string, 0, len(secret.Data))
+		for dataKey := range secret.Data {
+			dataKeys = append(dataKeys, dataKey)
+		}
+		sort.Strings(dataKeys)
+		for _, dataKey := range dataKeys {
+			for _, line := range strings.Split(string(secret.Data[dataKey]), "\n") {
+				add(line)
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// nodeHostname returns the hostname of the node: spec.hostname, else the
+// expanded spec.hostnameTemplate, else the Machine name. An empty result
+// leaves the hostname to the template's hostnamePrefix.
+func nodeHostname(kairosConfig *bootstrapv1beta2.KairosConfig, machine *clusterv1.Machine, cluster *clusterv1.Cluster) string {
+	if kairosConfig.Spec.Hostname != "" {
+		return kairosConfig.Spec.Hostname
+	}
+	if kairosConfig.Spec.HostnameTemplate != "" {
+		machineName := kairosConfig.Name
+		if machine != nil {
+			machineName = machine.Name
+		}
+		return bootstrapv1beta2.ExpandHostnameTemplate(kairosConfig.Spec.HostnameTemplate, machineName, cluster.Name, kairosConfig.Namespace)
+	}
+	if machine != nil {
+		return machine.Name
+	}
+	return ""
+}
+
+// machineMetadata describes the Machine a node is bootstrapped for. Nodes of a
+// P2P network with automatic role assignment get no role. It returns nil
+// without a Machine.
+func machineMetadata(kairosConfig *bootstrapv1beta2.KairosConfig, role string, machine *clusterv1.Machine, cluster *clusterv1.Cluster) *bootstrap.MachineMetadata {
+	if machine == nil {
+		return nil
+	}
+	metadata := &bootstrap.MachineMetadata{
+		ClusterName: cluster.Name,
+		MachineName: machine.Name,
+		Namespace:   machine.Namespace,
+		Role:        role,
+	}
+	if p2p := kairosConfig.Spec.P2P; p2p != nil && p2p.Auto != nil && p2p.Auto.Enable {
+		metadata.Role = ""
+	}
+	if machine.Spec.FailureDomain != nil {
+		metadata.FailureDomain = *machine.Spec.FailureDomain
+	}
+	return metadata
+}
+
+// resolveUserPassword returns the password of the default user, read from
+// spec.userPasswordSecretRef, spec.passwdHash or spec.userPassword. Without any
+// of them the user gets the development password "kairos".
+func (r *KairosConfigReconciler) resolveUserPassword(ctx context.Context, kairosConfig *bootstrapv1beta2.KairosConfig) (string, error) {
+	spec := kairosConfig.Spec
+	switch {
+	case spec.UserPasswordSecretRef != nil:
+		secret := &corev1.Secret{}
+		secretKey := types.NamespacedName{Namespace: kairosConfig.Namespace, Name: spec.UserPasswordSecretRef.Name}
+		if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, secret); err != nil {
+			return "", fmt.Errorf("failed to get user password secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err)
+		}
+		key := spec.UserPasswordSecretRef.Key
+		if key == "" {
+			key = "password"
+		}
+		password := strings.TrimRight(string(secret.Data[key]), "\r\n")
+		if password == "" {
+			return "", fmt.Errorf("user password secret %s/%s does not contain key '%s'", secretKey.Namespace, secretKey.Name, key)
+		}
+		if strings.ContainsAny(password, "\r\n") {
+			return "", fmt.Errorf("user password in secret %s/%s key '%s' must not contain line breaks", secretKey.Namespace, secretKey.Name, key)
+		}
+		return password, nil
+	case spec.PasswdHash != "":
+		return spec.PasswdHash, nil
+	case spec.UserPassword != "":
+		return spec.UserPassword, nil
+	default:
+		return "kairos", nil
+	}
+}
+
+// resolveRegistryAuths reads the registry credentials of spec.registryCredentials.
+// When several Secrets hold credentials for the same registry, the later one wins.
+func (r *KairosConfigReconciler) resolveRegistryAuths(ctx context.Context, kairosConfig *bootstrapv1beta2.KairosConfig) ([]dockerconfig.Auth, error) {
+	if len(kairosConfig.Spec.RegistryCredentials) == 0 {
+		return nil, nil
+	}
+
+	byRegistry := map[string]dockerconfig.Auth{}
+	for _, ref := range kairosConfig.Spec.RegistryCredentials {
+		dataKey := ref.Key
+		if dataKey == "" {
+			dataKey = corev1.DockerConfigJsonKey
+		}
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Namespace: kairosConfig.Namespace, Name: ref.Name}
+		if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, key, secret); err != nil {
+			return nil, fmt.Errorf("failed to get registry credentials secret %s/%s: %w", key.Namespace, key.Name, err)
+		}
+		data, ok := secret.Data[dataKey]
+		if !ok {
+			return nil, fmt.Errorf("registry credentials secret %s/%s does not contain key '%s'", key.Namespace, key.Name, dataKey)
+		}
+		auths, err := dockerconfig.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read registry credentials secret %s/%s: %w", key.Namespace, key.Name, err)
+		}
+		for _, auth := range auths {
+			byRegistry[auth.Registry] = auth
+		}
+	}
+
+	auths := make([]dockerconfig.Auth, 0, len(byRegistry))
+	for _, auth := range byRegistry {
+		auths = append(auths, auth)
+	}
+	sort.Slice(auths, func(i, j int) bool { return auths[i].Registry < auths[j].Registry })
+	return auths, nil
+}
+
+// newCredentialsSecret returns an empty join token Secret named by key.
+func newCredentialsSecret(key types.NamespacedName) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+		},
+		Type: bootstrapv1beta2.CredentialsSecretType,
+	}
+}
+
+// ensureWorkerTokenSecret returns a reference to the managed Secret holding a
+// k0s worker join token for cluster. The token is created as a bootstrap token
+// in the workload cluster once its kubeconfig secret exists, and replaced
+// before it expires.
+func (r *KairosConfigReconciler) ensureWorkerTokenSecret(ctx context.Context, log logr.Logger, cluster *clusterv1.Cluster) (*bootstrapv1beta2.WorkerTokenSecretReference, error) {
+	ref := &bootstrapv1beta2.WorkerTokenSecretReference{
+		Name: workerJoinTokenSecretName(cluster.Name),
+		Key:  "token",
+	}
+
+	secretKey := types.NamespacedName{Namespace: cluster.Namespace, Name: ref.Name}
+	secret := &corev1.Secret{}
+	if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get worker token secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err)
+		}
+		secret = newCredentialsSecret(secretKey)
+	} else if expiry, err := time.Parse(time.RFC3339, secret.Annotations[workerJoinTokenExpiryAnnotation]); err == nil {
+		if time.Until(expiry) > workerJoinTokenRenewBefore {
+			return ref, nil
+		}
+	}
+
+	kubeconfigSecret := &corev1.Secret{}
+	kubeconfigKey := types.NamespacedName{Namespace: cluster.Namespace, Name: kubeconfigsecret.Name(cluster.Name)}
+	if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, kubeconfigKey, kubeconfigSecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errWorkerTokenNotReady
+		}
+		return nil, fmt.Errorf("failed to get kubeconfig secret %s/%s: %w", kubeconfigKey.Namespace, kubeconfigKey.Name, err)
+	}
+	kubeconfig := kubeconfigSecret.Data[kubeconfigsecret.DataKey]
+	if len(kubeconfig) == 0 {
+		return nil, errWorkerTokenNotReady
+	}
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig secret %s/%s: %w", kubeconfigKey.Namespace, kubeconfigKey.Name, err)
+	}
+	kubeContext, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return nil, fmt.Errorf("kubeconfig secret %s/%s has no current context", kubeconfigKey.Namespace, kubeconfigKey.Name)
+	}
+	kubeCluster, ok := config.Clusters[kubeContext.Cluster]
+	if !ok {
+		return nil, fmt.Errorf("kubeconfig secret %s/%s has no cluster %q", kubeconfigKey.Namespace, kubeconfigKey.Name, kubeContext.Cluster)
+	}
+
+	// Workers join through the cluster endpoint, which may differ from the
+	// address the management cluster uses
+	server := kubeCluster.Server
+	if cluster.Spec.ControlPlaneEndpoint.IsValid() {
+		server = fmt.Sprintf("https://%s:%d", cluster.Spec.ControlPlaneEndpoint.Host, cluster.Spec.ControlPlaneEndpoint.Port)
+	}
+
+	workloadClient, err := r.getWorkloadClient(ctx, cluster, kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	token, err := k0stoken.NewBootstrapToken()
+	if err != nil {
+		return nil, err
+	}
+	expiry := time.Now().Add(workerJoinTokenTTL)
+	if err := workloadClient.Create(ctx, k0stoken.WorkerSecret(token, expiry)); err != nil {
+		return nil, fmt.Errorf("failed to create bootstrap token in workload cluster: %w", err)
+	}
+	joinToken, err := k0stoken.JoinToken(server, kubeCluster.CertificateAuthorityData, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if secret.ResourceVersion != "" && secret.Type != bootstrapv1beta2.CredentialsSecretType {
+		// Secrets written by earlier releases have another type, which cannot
+		// be changed; replace them
+		if err := r.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to replace worker token secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err)
+		}
+		secret = newCredentialsSecret(secretKey)
+	}
+	if secret.Labels == nil {
+		secret.Labels = map[string]string{}
+	}
+	secret.Labels[clusterv1.ClusterNameLabel] = cluster.Name
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[workerJoinTokenExpiryAnnotation] = expiry.UTC().Format(time.RFC3339)
+	secret.Data = map[string][]byte{ref.Key: []byte(joinToken)}
+	// The Cluster controls the secret, so it is garbage collected with it
+	if err := controllerutil.SetControllerReference(cluster, secret, r.Scheme); err != nil {
+		return nil, fmt.Errorf("failed to set owner on worker token secret: %w", err)
+	}
+	if secret.ResourceVersion == "" {
+		err = r.Create(ctx, secret)
+	} else {
+		err = r.Update(ctx, secret)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to store worker token secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err)
+	}
+
+	log.Info("Provisioned k0s worker join token", "secret", secretKey.Name, "expiry", expiry)
+	return ref, nil
+}
+
+// getWorkloadClient returns a client for the workload cluster, preferring the
+// shared tracker client over one built from kubeconfig.
+func (r *KairosConfigReconciler) getWorkloadClient(ctx context.Context, cluster *clusterv1.Cluster, kubeconfig []byte) (client.Client, error) {
+	if r.Tracker != nil {
+		workloadClient, err := r.ConnectionBackoff.GetClient(ctx, r.Tracker, util.ObjectKey(cluster))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get workload client: %w", err)
+		}
+		return workloadClient, nil
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build workload rest config: %w", err)
+	}
+
+	workloadClient, err := client.New(restConfig, client.Options{Scheme: r.Scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workload client: %w", err)
+	}
+	return workloadClient, nil
+}
+
+func (r *KairosConfigReconciler) generateK3sCloudConfig(ctx context.Context, log logr.Logger, kairosConfig *bootstrapv1beta2.KairosConfig, machine *clusterv1.Machine, cluster *clusterv1.Cluster, role, serverAddress string) (string, error) {
+	// Determine single-node mode
+	singleNode := kairosConfig.Spec.SingleNode
+	if !singleNode && role == "control-plane" && machine != nil {
+		ownerRef := metav1.GetControllerOf(machine)
+		if ownerRef != nil && ownerRef.Kind == "KairosControlPlane" {
+			log.V(4).Info("Control plane node, single-node mode determined from spec", "singleNode", singleNode)
+		}
+	}
+
+	// P2P nodes coordinate k3s over the VPN instead of joining a server address
+	p2p, err := r.resolveP2PConfig(ctx, kairosConfig)
+	if err != nil {
+		return "", err
+	}
+
+	// Resolve k3s token if needed (for worker nodes)
+	// Precedence: K3sTokenSecretRef > K3sToken > WorkerTokenSecretRef > WorkerToken > TokenSecretRef > Token
+	var k3sToken string
+	if role == "worker" && p2p == nil {
+		if kairosConfig.Spec.K3sTokenSecretRef != nil {
+			secretKey := types.NamespacedName{
+				Namespace: kairosConfig.Namespace,
+				Name:      kairosConfig.Spec.K3sTokenSecretRef.Name,
+			}
+			if kairosConfig.Spec.K3sTokenSecretRef.Namespace != "" {
+				secretKey.Namespace = kairosConfig.Spec.K3sTokenSecretRef.Namespace
+			}
+
+			secret := &corev1.Secret{}
+			if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, secret); err != nil {
+				if apierrors.IsNotFound(err) {
+					return "", errK3sTokenNotReady
+				}
+				return "", tokenFetchFailed(fmt.Errorf("failed to get k3s token secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err))
+			}
+
+			key := kairosConfig.Spec.K3sTokenSecretRef.Key
+			if key == "" {
+				key = "token"
+			}
+
+			if tokenData, ok := secret.Data[key]; ok {
+				k3sToken = string(tokenData)
 			} else {
-				return "", fmt.Errorf("k3s token secret %s/%s does not contain key '%s'", secretKey.Namespace, secretKey.Name, key)
+				return "", tokenFetchFailed(fmt.Errorf("k3s token secret %s/%s does not contain key '%s'", secretKey.Namespace, secretKey.Name, key))
 			}
 		} else if kairosConfig.Spec.K3sToken != "" {
 			k3sToken = kairosConfig.Spec.K3sToken
@@ -969,11 +2202,11 @@ func (r *KairosConfigReconciler) generateK3sCloudConfig(ctx context.Context, log
 			}
 
 			secret := &corev1.Secret{}
-			if err := r.Get(ctx, secretKey, secret); err != nil {
+			if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, secret); err != nil {
 				if apierrors.IsNotFound(err) {
 					return "", errK3sTokenNotReady
 				}
-				return "", fmt.Errorf("failed to get worker token secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err)
+				return "", tokenFetchFailed(fmt.Errorf("failed to get worker token secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err))
 			}
 
 			key := kairosConfig.Spec.WorkerTokenSecretRef.Key
@@ -984,7 +2217,7 @@ func (r *KairosConfigReconciler) generateK3sCloudConfig(ctx context.Context, log
 			if tokenData, ok := secret.Data[key]; ok {
 				k3sToken = string(tokenData)
 			} else {
-				return "", fmt.Errorf("worker token secret %s/%s does not contain key '%s'", secretKey.Namespace, secretKey.Name, key)
+				return "", tokenFetchFailed(fmt.Errorf("worker token secret %s/%s does not contain key '%s'", secretKey.Namespace, secretKey.Name, key))
 			}
 		} else if kairosConfig.Spec.WorkerToken != "" {
 			k3sToken = kairosConfig.Spec.WorkerToken
@@ -994,18 +2227,18 @@ func (r *KairosConfigReconciler) generateK3sCloudConfig(ctx context.Context, log
 				Name:      kairosConfig.Spec.TokenSecretRef.Name,
 			}
 			secret := &corev1.Secret{}
-			if err := r.Get(ctx, secretKey, secret); err != nil {
+			if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, secret); err != nil {
 				if apierrors.IsNotFound(err) {
 					return "", errK3sTokenNotReady
 				}
-				return "", fmt.Errorf("failed to get token secret: %w", err)
+				return "", tokenFetchFailed(fmt.Errorf("failed to get token secret: %w", err))
 			}
 			if tokenData, ok := secret.Data["token"]; ok {
 				k3sToken = string(tokenData)
 			} else if tokenData, ok := secret.Data["value"]; ok {
 				k3sToken = string(tokenData)
 			} else {
-				return "", fmt.Errorf("token secret does not contain 'token' or 'value' key")
+				return "", tokenFetchFailed(fmt.Errorf("token secret does not contain 'token' or 'value' key"))
 			}
 		} else if kairosConfig.Spec.Token != "" {
 			k3sToken = kairosConfig.Spec.Token
@@ -1024,9 +2257,9 @@ func (r *KairosConfigReconciler) generateK3sCloudConfig(ctx context.Context, log
 	if userName == "" {
 		userName = "kairos"
 	}
-	userPassword := kairosConfig.Spec.UserPassword
-	if userPassword == "" {
-		userPassword = "kairos"
+	userPassword, err := r.resolveUserPassword(ctx, kairosConfig)
+	if err != nil {
+		return "", err
 	}
 	userGroups := kairosConfig.Spec.UserGroups
 	if len(userGroups) == 0 {
@@ -1039,31 +2272,26 @@ func (r *KairosConfigReconciler) generateK3sCloudConfig(ctx context.Context, log
 		hostnamePrefix = "metal-"
 	}
 
-	// Prefer explicit hostname, otherwise use Machine name
-	hostname := kairosConfig.Spec.Hostname
-	if hostname == "" && machine != nil {
-		hostname = machine.Name
-	}
+	hostname := nodeHostname(kairosConfig, machine, cluster)
 
-	// Set install configuration (with defaults)
-	var installConfig *bootstrap.InstallConfig
-	if kairosConfig.Spec.Install != nil {
-		installConfig = &bootstrap.InstallConfig{
-			Auto:   true,
-			Device: "auto",
-			Reboot: true,
-		}
-		if kairosConfig.Spec.Install.Auto != nil {
-			installConfig.Auto = *kairosConfig.Spec.Install.Auto
-		}
-		if kairosConfig.Spec.Install.Device != "" {
-			installConfig.Device = kairosConfig.Spec.Install.Device
-		}
-		if kairosConfig.Spec.Install.Reboot != nil {
-			installConfig.Reboot = *kairosConfig.Spec.Install.Reboot
+	kubeletArgs := kairosConfig.Spec.KubeletExtraArgs
+	var metadataYAML string
+	metadata := machineMetadata(kairosConfig, role, machine, cluster)
+	if metadata != nil {
+		metadataYAML, err = metadata.YAML()
+		if err != nil {
+			return "", err
 		}
+		kubeletArgs = bootstrap.MergeNodeLabels(kubeletArgs, metadata.NodeLabels())
+	}
+	completionCallback, err := bootstrap.CompletionCallbackScript("k3s", kairosConfig.Spec.CompletionCallback, metadata)
+	if err != nil {
+		return "", err
 	}
 
+	// Set install configuration (with defaults)
+	installConfig := installConfig(kairosConfig.Spec)
+
 	if installConfig != nil {
 		log.Info("Using install configuration", "auto", installConfig.Auto, "device", installConfig.Device, "reboot", installConfig.Reboot)
 	} else {
@@ -1073,6 +2301,34 @@ func (r *KairosConfigReconciler) generateK3sCloudConfig(ctx context.Context, log
 	// Get providerID from Machine's infrastructure reference
 	providerID := r.getProviderID(ctx, log, machine)
 
+	files, downloads, err := r.resolveFiles(ctx, kairosConfig)
+	if err != nil {
+		return "", err
+	}
+	manifests, err := r.resolveManifests(ctx, kairosConfig)
+	if err != nil {
+		return "", err
+	}
+	if role == "control-plane" {
+		certificateFiles, err := r.controlPlaneCertificateFiles(ctx, kairosConfig, cluster)
+		if err != nil {
+			return "", err
+		}
+		files = append(certificateFiles, files...)
+	}
+	registryAuths, err := r.resolveRegistryAuths(ctx, kairosConfig)
+	if err != nil {
+		return "", err
+	}
+	sshAuthorizedKeys, err := r.resolveSSHAuthorizedKeys(ctx, kairosConfig)
+	if err != nil {
+		return "", err
+	}
+	stages, err := bootstrap.RenderStages(kairosConfig.Spec.Stages)
+	if err != nil {
+		return "", fmt.Errorf("failed to render stages: %w", err)
+	}
+
 	// CAPK: ensure kubeconfig push config and LB endpoint for KubeVirt control-plane (same as k0s)
 	var kubeconfigPush *kubeconfigPushConfig
 	if isKubevirtMachine(machine) && role == "control-plane" {
@@ -1093,9 +2349,15 @@ func (r *KairosConfigReconciler) generateK3sCloudConfig(ctx context.Context, log
 		UserGroups:                          userGroups,
 		GitHubUser:                          kairosConfig.Spec.GitHubUser,
 		SSHPublicKey:                        kairosConfig.Spec.SSHPublicKey,
-		Manifests:                           kairosConfig.Spec.Manifests,
+		SSHAuthorizedKeys:                   sshAuthorizedKeys,
+		Users:                               kairosUsers(kairosConfig.Spec.Users),
+		Manifests:                           manifests,
+		Files:                               files,
+		Downloads:                           downloads,
 		HostnamePrefix:                      hostnamePrefix,
 		DNSServers:                          kairosConfig.Spec.DNSServers,
+		PodCIDR:                             kairosConfig.Spec.PodCIDR,
+		ServiceCIDR:                         kairosConfig.Spec.ServiceCIDR,
 		PrimaryIP:                           kairosConfig.Spec.PrimaryIP,
 		MachineName:                         "",
 		ClusterNS:                           "",
@@ -1104,6 +2366,17 @@ func (r *KairosConfigReconciler) generateK3sCloudConfig(ctx context.Context, log
 		ProviderID:                          providerID,
 		K3sServerURL:                        serverAddress,
 		K3sToken:                            k3sToken,
+		P2P:                                 p2p,
+		KubeletExtraArgs:                    kubeletExtraArgs(kubeletArgs),
+		ExtraInstallArgs:                    k3sExtraInstallArgs(kairosConfig.Spec, role),
+		Airgap:                              kairosConfig.Spec.Airgap,
+		RegistryAuths:                       registryAuths,
+		Proxy:                               proxyConfig(kairosConfig.Spec.Proxy),
+		NetworkFiles:                        bootstrap.NetworkdFiles(kairosConfig.Spec.Network),
+		Kcrypt:                              kcryptConfig(kairosConfig.Spec.Encryption),
+		Stages:                              stages,
+		MachineMetadata:                     metadataYAML,
+		CompletionCallback:                  completionCallback,
 		ControlPlaneLBServiceName:           "",
 		ControlPlaneLBServiceNamespace:      "",
 		ControlPlaneLBEndpoint:              "",
@@ -1127,12 +2400,9 @@ func (r *KairosConfigReconciler) generateK3sCloudConfig(ctx context.Context, log
 		templateData.ControlPlaneLBServiceNamespace = cluster.Namespace
 	}
 	if cluster != nil && isKubevirtMachine(machine) && role == "control-plane" {
-		lbEndpoint, err := r.getControlPlaneLBEndpoint(ctx, cluster.Namespace, templateData.ControlPlaneLBServiceName)
+		lbEndpoint, err := r.kubevirtControlPlaneEndpoint(ctx, kairosConfig, cluster, templateData.ControlPlaneLBServiceName)
 		if err != nil {
-			return "", fmt.Errorf("failed to get control plane LB endpoint: %w", err)
-		}
-		if lbEndpoint == "" {
-			return "", errLBEndpointNotReady
+			return "", err
 		}
 		templateData.ControlPlaneLBEndpoint = lbEndpoint
 	}
@@ -1140,6 +2410,27 @@ func (r *KairosConfigReconciler) generateK3sCloudConfig(ctx context.Context, log
 	return bootstrap.RenderK3sCloudConfig(templateData)
 }
 
+// kubevirtControlPlaneEndpoint returns the address KubeVirt control plane
+// nodes serve the Kubernetes API on: the externally managed Cluster endpoint
+// when the KairosConfig uses one, the control plane load balancer Service
+// otherwise.
+func (r *KairosConfigReconciler) kubevirtControlPlaneEndpoint(ctx context.Context, kairosConfig *bootstrapv1beta2.KairosConfig, cluster *clusterv1.Cluster, serviceName string) (string, error) {
+	if kairosConfig.Spec.ExternalControlPlaneEndpoint {
+		if cluster.Spec.ControlPlaneEndpoint.Host == "" {
+			return "", errLBEndpointNotReady
+		}
+		return cluster.Spec.ControlPlaneEndpoint.Host, nil
+	}
+	lbEndpoint, err := r.getControlPlaneLBEndpoint(ctx, cluster.Namespace, serviceName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get control plane LB endpoint: %w", err)
+	}
+	if lbEndpoint == "" {
+		return "", errLBEndpointNotReady
+	}
+	return lbEndpoint, nil
+}
+
 func (r *KairosConfigReconciler) getControlPlaneLBEndpoint(ctx context.Context, namespace, name string) (string, error) {
 	if namespace == "" || name == "" {
 		return "", nil
@@ -1164,12 +2455,191 @@ func (r *KairosConfigReconciler) getControlPlaneLBEndpoint(ctx context.Context,
 	return "", nil
 }
 
+// reconcileDelete removes the secrets generated for kairosConfig before
+// releasing its finalizer: the bootstrap data secret and, once no other
+// KairosConfig of the cluster uses it, the managed k0s worker token, which is
+// also invalidated in the workload cluster when that is reachable.
 func (r *KairosConfigReconciler) reconcileDelete(ctx context.Context, log logr.Logger, kairosConfig *bootstrapv1beta2.KairosConfig) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(kairosConfig, bootstrapv1beta2.KairosConfigFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	// clusterctl move deletes the objects it copied while the Cluster is
+	// paused; the bootstrap data and the worker token are still used by the
+	// moved copies, so clean up only once the pause is lifted
+	paused, err := r.isDeletionPaused(ctx, kairosConfig)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if paused {
+		log.Info("Reconciliation is paused for this object, skipping cleanup")
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.deleteBootstrapDataSecret(ctx, log, kairosConfig); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.releaseWorkerTokenSecret(ctx, log, kairosConfig); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Remove finalizer
 	controllerutil.RemoveFinalizer(kairosConfig, bootstrapv1beta2.KairosConfigFinalizer)
 	return ctrl.Result{}, r.Update(ctx, kairosConfig)
 }
 
+// isDeletionPaused reports whether kairosConfig or its Cluster, found through
+// the cluster-name label, is paused. A Cluster that is gone does not pause the
+// deletion.
+func (r *KairosConfigReconciler) isDeletionPaused(ctx context.Context, kairosConfig *bootstrapv1beta2.KairosConfig) (bool, error) {
+	if annotations.HasPaused(kairosConfig) {
+		return true, nil
+	}
+	clusterName := kairosConfig.Labels[clusterv1.ClusterNameLabel]
+	if clusterName == "" {
+		return false, nil
+	}
+	cluster, err := util.GetClusterByName(ctx, r.Client, kairosConfig.Namespace, clusterName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return annotations.IsPaused(cluster, kairosConfig), nil
+}
+
+// deleteBootstrapDataSecret deletes the bootstrap data secret recorded in
+// status, unless it is not controlled by kairosConfig.
+func (r *KairosConfigReconciler) deleteBootstrapDataSecret(ctx context.Context, log logr.Logger, kairosConfig *bootstrapv1beta2.KairosConfig) error {
+	if kairosConfig.Status.DataSecretName == nil || *kairosConfig.Status.DataSecretName == "" {
+		return nil
+	}
+	secretKey := types.NamespacedName{Namespace: kairosConfig.Namespace, Name: *kairosConfig.Status.DataSecretName}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, secretKey, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get bootstrap data secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err)
+	}
+	if !metav1.IsControlledBy(secret, kairosConfig) {
+		return nil
+	}
+	if err := r.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete bootstrap data secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err)
+	}
+	log.Info("Deleted bootstrap data secret", "secret", secretKey.Name)
+	return nil
+}
+
+// releaseWorkerTokenSecret deletes the managed k0s worker token secret used by
+// kairosConfig when no other KairosConfig of the cluster uses it, after
+// deleting its bootstrap token from the workload cluster. A workload cluster
+// that cannot be reached keeps the token until it expires.
+func (r *KairosConfigReconciler) releaseWorkerTokenSecret(ctx context.Context, log logr.Logger, kairosConfig *bootstrapv1beta2.KairosConfig) error {
+	clusterName := kairosConfig.Labels[clusterv1.ClusterNameLabel]
+	ref := managedWorkerTokenSecretRef(kairosConfig)
+	if clusterName == "" || ref == nil || ref.Name != workerJoinTokenSecretName(clusterName) ||
+		(ref.Namespace != "" && ref.Namespace != kairosConfig.Namespace) {
+		return nil
+	}
+
+	// Workers that are still joining share the token
+	kairosConfigs := &bootstrapv1beta2.KairosConfigList{}
+	if err := r.List(ctx, kairosConfigs, client.InNamespace(kairosConfig.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: clusterName}); err != nil {
+		return fmt.Errorf("failed to list KairosConfigs of cluster %s: %w", clusterName, err)
+	}
+	for i := range kairosConfigs.Items {
+		other := &kairosConfigs.Items[i]
+		if other.UID == kairosConfig.UID || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if otherRef := managedWorkerTokenSecretRef(other); otherRef != nil && otherRef.Name == ref.Name {
+			return nil
+		}
+	}
+
+	secretKey := types.NamespacedName{Namespace: kairosConfig.Namespace, Name: ref.Name}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, secretKey, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get worker token secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err)
+	}
+	if _, ok := secret.Annotations[workerJoinTokenExpiryAnnotation]; !ok {
+		// Not written by this controller
+		return nil
+	}
+
+	key := ref.Key
+	if key == "" {
+		key = "token"
+	}
+	r.invalidateWorkerToken(ctx, log, kairosConfig.Namespace, clusterName, string(secret.Data[key]))
+
+	if err := r.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete worker token secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err)
+	}
+	log.Info("Deleted k0s worker join token secret", "secret", secretKey.Name)
+	return nil
+}
+
+// managedWorkerTokenSecretRef returns the worker token Secret provisioned for
+// kairosConfig, recorded in status. KairosConfigs provisioned before it was
+// recorded there reference the Secret from their spec instead.
+func managedWorkerTokenSecretRef(kairosConfig *bootstrapv1beta2.KairosConfig) *bootstrapv1beta2.WorkerTokenSecretReference {
+	if kairosConfig.Status.WorkerTokenSecretRef != nil {
+		return kairosConfig.Status.WorkerTokenSecretRef
+	}
+	return kairosConfig.Spec.WorkerTokenSecretRef
+}
+
+// invalidateWorkerToken deletes the bootstrap token carried by joinToken from
+// the workload cluster. It only logs failures, so that an unreachable or
+// deleted workload cluster does not block the deletion of the KairosConfig.
+func (r *KairosConfigReconciler) invalidateWorkerToken(ctx context.Context, log logr.Logger, namespace, clusterName, joinToken string) {
+	token, err := k0stoken.ParseJoinToken(joinToken)
+	if err != nil {
+		log.Info("Not invalidating k0s worker join token", "reason", err.Error())
+		return
+	}
+
+	cluster := &clusterv1.Cluster{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: clusterName}, cluster); err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Error(err, "Failed to get cluster to invalidate k0s worker join token")
+		}
+		return
+	}
+	if !cluster.DeletionTimestamp.IsZero() {
+		// The workload cluster goes away with the token
+		return
+	}
+
+	var kubeconfig []byte
+	if r.Tracker == nil {
+		kubeconfigSecret := &corev1.Secret{}
+		kubeconfigKey := types.NamespacedName{Namespace: namespace, Name: kubeconfigsecret.Name(clusterName)}
+		if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, kubeconfigKey, kubeconfigSecret); err != nil {
+			log.Info("Not invalidating k0s worker join token, workload cluster kubeconfig is unavailable", "reason", err.Error())
+			return
+		}
+		kubeconfig = kubeconfigSecret.Data[kubeconfigsecret.DataKey]
+	}
+	workloadClient, err := r.getWorkloadClient(ctx, cluster, kubeconfig)
+	if err != nil {
+		log.Info("Not invalidating k0s worker join token, workload cluster is unreachable", "reason", err.Error())
+		return
+	}
+	if err := workloadClient.Delete(ctx, k0stoken.WorkerSecret(token, time.Time{})); err != nil && !apierrors.IsNotFound(err) {
+		log.Error(err, "Failed to delete k0s worker bootstrap token from workload cluster")
+		return
+	}
+	log.Info("Invalidated k0s worker join token in workload cluster")
+}
+
 func splitLines(s string) []string {
 	return strings.Split(s, "\n")
 }
@@ -1202,7 +2672,7 @@ func (r *KairosConfigReconciler) ensureKubeconfigPushConfig(ctx context.Context,
 		return nil, nil
 	}
 
-	secretName := fmt.Sprintf("%s-kubeconfig", cluster.Name)
+	secretName := kubeconfigsecret.Name(cluster.Name)
 	saName := kubeconfigWriterName(cluster.Name)
 
 	serviceAccount := &corev1.ServiceAccount{
@@ -1327,9 +2797,11 @@ func kubeconfigWriterName(clusterName string) string {
 	return fmt.Sprintf("%s-%s-%s", base, trimmed, suffix)
 }
 
-// SetupWithManager sets up the controller with the Manager.
-func (r *KairosConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+// SetupWithManager sets up the controller with the Manager. options set the
+// number of concurrent reconciles and the work queue rate limiter.
+func (r *KairosConfigReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
 	log := ctrl.Log.WithName("KairosConfig")
+	r.recorder = mgr.GetEventRecorderFor("kairosconfig-controller")
 
 	// Create unstructured VSphereMachine object for watching
 	vsphereMachineGVK := schema.GroupVersionKind{
@@ -1359,6 +2831,7 @@ func (r *KairosConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&bootstrapv1beta2.KairosConfig{}).
+		WithOptions(options).
 		Watches(
 			&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(r.secretToKairosConfig),
@@ -1374,6 +2847,11 @@ func (r *KairosConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(
 			kubevirtMachineAlpha1,
 			handler.EnqueueRequestsFromMapFunc(r.kubevirtMachineToKairosConfig),
+		).
+		Watches(
+			&clusterv1.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(r.clusterToKairosConfigs),
+			ctrlbuilder.WithPredicates(predicates.ClusterUnpaused(log)),
 		)
 
 	if r.gvkExists(mgr, kubevirtMachineGVKAlpha4) {
@@ -1385,7 +2863,12 @@ func (r *KairosConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		log.V(2).Info("Skipping watch: KubevirtMachine v1alpha4 CRD not installed")
 	}
 
-	return builder.Complete(r)
+	c, err := builder.Build(r)
+	if err != nil {
+		return err
+	}
+	r.controller = c
+	return nil
 }
 
 func (r *KairosConfigReconciler) gvkExists(mgr ctrl.Manager, gvk schema.GroupVersionKind) bool {
@@ -1471,6 +2954,29 @@ func (r *KairosConfigReconciler) machineToKairosConfig(ctx context.Context, o cl
 	}
 }
 
+// clusterToKairosConfigs maps a Cluster to the KairosConfigs of its Machines
+// so they are reconciled again once the Cluster is unpaused
+func (r *KairosConfigReconciler) clusterToKairosConfigs(ctx context.Context, o client.Object) []reconcile.Request {
+	cluster, ok := o.(*clusterv1.Cluster)
+	if !ok {
+		return nil
+	}
+
+	machineList := &clusterv1.MachineList{}
+	if err := r.List(ctx, machineList,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: cluster.Name},
+	); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range machineList.Items {
+		requests = append(requests, r.machineToKairosConfig(ctx, &machineList.Items[i])...)
+	}
+	return requests
+}
+
 // vsphereMachineToKairosConfig maps a VSphereMachine to its KairosConfig
 // This allows us to watch for VSphereMachine changes (especially when providerID is set)
 // and trigger KairosConfig reconciliation to regenerate bootstrap secret with providerID
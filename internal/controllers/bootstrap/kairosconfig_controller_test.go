@@ -18,19 +18,42 @@ package bootstrap
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/secret"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
 
 	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+	"github.com/kairos-io/kairos-capi/internal/bootstrap"
+	"github.com/kairos-io/kairos-capi/internal/dockerconfig"
+	"github.com/kairos-io/kairos-capi/internal/k0stoken"
+	"github.com/kairos-io/kairos-capi/internal/metrics"
+	"github.com/kairos-io/kairos-capi/internal/osupgrade"
 )
 
 func TestGenerateK0sCloudConfig_ControlPlaneSingleNode(t *testing.T) {
@@ -185,6 +208,169 @@ func TestGenerateK0sCloudConfig_ControlPlaneWithCIDRs(t *testing.T) {
 	g.Expect(cloudConfig).To(ContainSubstring("serviceCIDR: 10.96.0.0/12"))
 }
 
+func TestGenerateK0sCloudConfig_K0sConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	reconciler := &KairosConfigReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme: scheme,
+	}
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role:              "control-plane",
+			Distribution:      "k0s",
+			KubernetesVersion: "v1.30.0+k0s.0",
+			PodCIDR:           "10.244.0.0/16",
+			K0sConfig: &bootstrapv1beta2.K0sConfig{
+				Config:          &runtime.RawExtension{Raw: []byte(`{"spec":{"telemetry":{"enabled":false}}}`)},
+				APISANs:         []string{"api.example.com"},
+				NetworkProvider: "calico",
+			},
+		},
+	}
+	machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: "default"}}
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+
+	cloudConfig, err := reconciler.generateK0sCloudConfig(context.Background(), log.Log, kairosConfig, machine, cluster, "control-plane", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cloudConfig).To(ContainSubstring("--config /etc/k0s/k0s.yaml"))
+
+	var parsed struct {
+		WriteFiles []struct {
+			Path    string `json:"path"`
+			Content string `json:"content"`
+		} `json:"write_files"`
+	}
+	g.Expect(yaml.Unmarshal([]byte(cloudConfig), &parsed)).To(Succeed())
+	var k0sYAML string
+	for _, file := range parsed.WriteFiles {
+		if file.Path == "/etc/k0s/k0s.yaml" {
+			k0sYAML = file.Content
+		}
+	}
+	g.Expect(k0sYAML).To(ContainSubstring("kind: ClusterConfig"))
+	g.Expect(k0sYAML).To(ContainSubstring("- api.example.com"))
+	g.Expect(k0sYAML).To(ContainSubstring("podCIDR: 10.244.0.0/16"))
+	g.Expect(k0sYAML).To(ContainSubstring("provider: calico"))
+	g.Expect(k0sYAML).To(ContainSubstring("enabled: false"))
+}
+
+func TestGenerateK0sCloudConfig_CertSANs(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	reconciler := &KairosConfigReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme: scheme,
+	}
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role:              "control-plane",
+			Distribution:      "k0s",
+			KubernetesVersion: "v1.30.0+k0s.0",
+			CertSANs:          []string{"api.example.com", "192.0.2.10"},
+		},
+	}
+	machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: "default"}}
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+
+	cloudConfig, err := reconciler.generateK0sCloudConfig(context.Background(), log.Log, kairosConfig, machine, cluster, "control-plane", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cloudConfig).To(ContainSubstring("--config /etc/k0s/k0s.yaml"))
+
+	var parsed struct {
+		WriteFiles []struct {
+			Path    string `json:"path"`
+			Content string `json:"content"`
+		} `json:"write_files"`
+	}
+	g.Expect(yaml.Unmarshal([]byte(cloudConfig), &parsed)).To(Succeed())
+	var k0sConfig struct {
+		Spec struct {
+			API struct {
+				SANs []string `json:"sans"`
+			} `json:"api"`
+		} `json:"spec"`
+	}
+	for _, file := range parsed.WriteFiles {
+		if file.Path == "/etc/k0s/k0s.yaml" {
+			g.Expect(yaml.Unmarshal([]byte(file.Content), &k0sConfig)).To(Succeed())
+		}
+	}
+	g.Expect(k0sConfig.Spec.API.SANs).To(Equal([]string{"api.example.com", "192.0.2.10"}))
+}
+
+func TestGenerateK0sCloudConfig_OIDC(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	reconciler := &KairosConfigReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme: scheme,
+	}
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role:              "control-plane",
+			Distribution:      "k0s",
+			KubernetesVersion: "v1.30.0+k0s.0",
+			OIDC: &bootstrapv1beta2.OIDCConfig{
+				IssuerURL:   "https://dex.example.com",
+				ClientID:    "kubernetes",
+				GroupsClaim: "groups",
+			},
+		},
+	}
+	machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: "default"}}
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+
+	cloudConfig, err := reconciler.generateK0sCloudConfig(context.Background(), log.Log, kairosConfig, machine, cluster, "control-plane", "")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var parsed struct {
+		WriteFiles []struct {
+			Path    string `json:"path"`
+			Content string `json:"content"`
+		} `json:"write_files"`
+	}
+	g.Expect(yaml.Unmarshal([]byte(cloudConfig), &parsed)).To(Succeed())
+	var k0sConfig struct {
+		Spec struct {
+			API struct {
+				ExtraArgs map[string]string `json:"extraArgs"`
+			} `json:"api"`
+		} `json:"spec"`
+	}
+	for _, file := range parsed.WriteFiles {
+		if file.Path == "/etc/k0s/k0s.yaml" {
+			g.Expect(yaml.Unmarshal([]byte(file.Content), &k0sConfig)).To(Succeed())
+		}
+	}
+	g.Expect(k0sConfig.Spec.API.ExtraArgs).To(Equal(map[string]string{
+		"oidc-issuer-url":   "https://dex.example.com",
+		"oidc-client-id":    "kubernetes",
+		"oidc-groups-claim": "groups",
+	}))
+}
+
 func TestGenerateK0sCloudConfig_ControlPlaneKubeVirtBootstrapTrap(t *testing.T) {
 	g := NewWithT(t)
 
@@ -264,6 +450,51 @@ func TestGenerateK0sCloudConfig_ControlPlaneKubeVirtBootstrapTrap(t *testing.T)
 	g.Expect(cloudConfig).To(ContainSubstring("CAPK: always mark bootstrap success on script exit"))
 }
 
+func TestGenerateK0sCloudConfig_KubevirtExternalEndpoint(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	// No control plane load balancer Service exists
+	reconciler := &KairosConfigReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme: scheme,
+	}
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role:                         "control-plane",
+			Distribution:                 "k0s",
+			KubernetesVersion:            "v1.30.0+k0s.0",
+			SingleNode:                   true,
+			UserName:                     "kairos",
+			UserPassword:                 "kairos",
+			UserGroups:                   []string{"admin"},
+			ExternalControlPlaneEndpoint: true,
+		},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: "default"},
+		Spec: clusterv1.MachineSpec{
+			InfrastructureRef: corev1.ObjectReference{Kind: "KubevirtMachine", Name: "test-kubevirt-machine", Namespace: "default"},
+		},
+	}
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+
+	// The endpoint is not set on the Cluster yet
+	_, err := reconciler.generateK0sCloudConfig(context.Background(), log.Log, kairosConfig, machine, cluster, "control-plane", "")
+	g.Expect(err).To(MatchError(errLBEndpointNotReady))
+
+	cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "api.example.com", Port: 443}
+	cloudConfig, err := reconciler.generateK0sCloudConfig(context.Background(), log.Log, kairosConfig, machine, cluster, "control-plane", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cloudConfig).To(ContainSubstring(`KAIROS_LB_ENDPOINT="api.example.com"`))
+}
+
 func TestGenerateK0sCloudConfig_ControlPlaneMultiNode(t *testing.T) {
 	g := NewWithT(t)
 
@@ -326,6 +557,76 @@ func TestGenerateK0sCloudConfig_ControlPlaneMultiNode(t *testing.T) {
 	g.Expect(cloudConfig).NotTo(ContainSubstring("k0s-worker:"))
 }
 
+func TestGenerateCloudConfig_ControlPlaneCertificates(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+			UID:       "cluster-uid",
+		},
+	}
+
+	// A user-provided cluster CA is kept, the other certificates are generated
+	userCertificates := bootstrap.ClusterCertificates()
+	clusterCA := userCertificates.GetByPurpose(secret.ClusterCA)
+	g.Expect(clusterCA.Generate()).To(Succeed())
+	clusterCA.Generated = false
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterCA.AsSecret(client.ObjectKeyFromObject(cluster), metav1.OwnerReference{})).Build()
+	reconciler := &KairosConfigReconciler{Client: c, Scheme: scheme}
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role:              "control-plane",
+			Distribution:      "k0s",
+			KubernetesVersion: "v1.30.0+k0s.0",
+		},
+	}
+	machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: "default"}}
+
+	cloudConfig, err := reconciler.generateK0sCloudConfig(context.Background(), log.Log, kairosConfig, machine, cluster, "control-plane", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cloudConfig).To(ContainSubstring("path: /var/lib/k0s/pki/ca.crt"))
+	g.Expect(cloudConfig).To(ContainSubstring("path: /var/lib/k0s/pki/etcd/ca.key"))
+	g.Expect(cloudConfig).To(ContainSubstring("path: /var/lib/k0s/pki/sa.pub"))
+	firstLine := strings.SplitN(string(clusterCA.KeyPair.Cert), "\n", 2)[0]
+	g.Expect(cloudConfig).To(ContainSubstring(firstLine))
+
+	for _, purpose := range []secret.Purpose{secret.EtcdCA, secret.FrontProxyCA, secret.ServiceAccount} {
+		generated := &corev1.Secret{}
+		g.Expect(c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: secret.Name("test-cluster", purpose)}, generated)).To(Succeed())
+		g.Expect(generated.OwnerReferences).To(HaveLen(1))
+		g.Expect(generated.OwnerReferences[0].Kind).To(Equal("Cluster"))
+	}
+	ca := &corev1.Secret{}
+	g.Expect(c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "test-cluster-ca"}, ca)).To(Succeed())
+	g.Expect(ca.Data[secret.TLSCrtDataName]).To(Equal(clusterCA.KeyPair.Cert))
+
+	// k3s servers get the same certificates at the k3s paths
+	kairosConfig.Spec.Distribution = "k3s"
+	kairosConfig.Spec.KubernetesVersion = "v1.30.0+k3s1"
+	cloudConfig, err = reconciler.generateK3sCloudConfig(context.Background(), log.Log, kairosConfig, machine, cluster, "control-plane", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cloudConfig).To(ContainSubstring("path: /var/lib/rancher/k3s/server/tls/server-ca.crt"))
+	g.Expect(cloudConfig).To(ContainSubstring("path: /var/lib/rancher/k3s/server/tls/service.key"))
+	g.Expect(cloudConfig).To(ContainSubstring(firstLine))
+
+	// Workers do not get the certificates
+	kairosConfig.Spec.Role = "worker"
+	kairosConfig.Spec.WorkerToken = "token"
+	cloudConfig, err = reconciler.generateK3sCloudConfig(context.Background(), log.Log, kairosConfig, machine, cluster, "worker", "https://10.0.0.1:6443")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cloudConfig).NotTo(ContainSubstring("server-ca.crt"))
+}
+
 func TestGenerateK0sCloudConfig_WorkerWithToken(t *testing.T) {
 	g := NewWithT(t)
 
@@ -505,6 +806,56 @@ users:
 	g.Expect(string(updatedSecret.Data["userdata"])).To(ContainSubstring("- \"ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC7\""))
 }
 
+func TestSanitizeCapkUserdataSecret_NotInFilteredCache(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	// CAPK creates the userdata secret without the cluster-name label
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine-secret-userdata",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			"userdata": []byte("#cloud-config\nusers:\n- name: capk\n  groups: users, admin\n"),
+		},
+	}
+
+	// The cached client misses unlabelled secrets but writes to the API server
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if _, ok := obj.(*corev1.Secret); ok {
+				return apierrors.NewNotFound(corev1.Resource("secrets"), key.Name)
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+	}).Build()
+	reconciler := &KairosConfigReconciler{
+		Client:    c,
+		APIReader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret.DeepCopy()).Build(),
+		Scheme:    scheme,
+	}
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-config",
+			Namespace: "default",
+		},
+		Status: bootstrapv1beta2.KairosConfigStatus{
+			DataSecretName: pointer.String("machine-secret"),
+		},
+	}
+
+	updated, found, err := reconciler.sanitizeCapkUserdataSecret(context.Background(), log.Log, kairosConfig, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(found).To(BeTrue())
+	g.Expect(updated).To(BeTrue())
+}
+
 func TestGenerateK0sCloudConfig_WorkerWithTokenSecretRef(t *testing.T) {
 	g := NewWithT(t)
 
@@ -577,7 +928,7 @@ func TestGenerateK0sCloudConfig_WorkerWithTokenSecretRef(t *testing.T) {
 	g.Expect(cloudConfig).To(ContainSubstring("secret-token-67890"))
 }
 
-func TestGenerateK0sCloudConfig_WorkerTokenPrecedence(t *testing.T) {
+func TestGenerateK0sCloudConfig_WorkerTokenSecretOutsideCache(t *testing.T) {
 	g := NewWithT(t)
 
 	scheme := runtime.NewScheme()
@@ -585,20 +936,22 @@ func TestGenerateK0sCloudConfig_WorkerTokenPrecedence(t *testing.T) {
 	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
 	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
 
+	// The token secret carries no cluster-name label, so the label-filtered
+	// cache does not see it; only the API reader does.
 	tokenSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "worker-token",
 			Namespace: "default",
 		},
 		Data: map[string][]byte{
-			"token": []byte("secret-token-takes-precedence"),
+			"token": []byte("uncached-token-13579"),
 		},
 	}
 
-	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tokenSecret).Build()
 	reconciler := &KairosConfigReconciler{
-		Client: client,
-		Scheme: scheme,
+		Client:    fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme:    scheme,
+		APIReader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(tokenSecret).Build(),
 	}
 
 	kairosConfig := &bootstrapv1beta2.KairosConfig{
@@ -610,7 +963,6 @@ func TestGenerateK0sCloudConfig_WorkerTokenPrecedence(t *testing.T) {
 			Role:              "worker",
 			Distribution:      "k0s",
 			KubernetesVersion: "v1.30.0+k0s.0",
-			WorkerToken:       "inline-token-should-be-ignored",
 			WorkerTokenSecretRef: &bootstrapv1beta2.WorkerTokenSecretReference{
 				Name: "worker-token",
 				Key:  "token",
@@ -646,12 +998,10 @@ func TestGenerateK0sCloudConfig_WorkerTokenPrecedence(t *testing.T) {
 	)
 
 	g.Expect(err).NotTo(HaveOccurred())
-	// WorkerTokenSecretRef should take precedence over WorkerToken
-	g.Expect(cloudConfig).To(ContainSubstring("secret-token-takes-precedence"))
-	g.Expect(cloudConfig).NotTo(ContainSubstring("inline-token-should-be-ignored"))
+	g.Expect(cloudConfig).To(ContainSubstring("uncached-token-13579"))
 }
 
-func TestGenerateK0sCloudConfig_WorkerMissingToken(t *testing.T) {
+func TestGenerateK0sCloudConfig_WorkerTokenPrecedence(t *testing.T) {
 	g := NewWithT(t)
 
 	scheme := runtime.NewScheme()
@@ -659,7 +1009,17 @@ func TestGenerateK0sCloudConfig_WorkerMissingToken(t *testing.T) {
 	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
 	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
 
-	client := fake.NewClientBuilder().WithScheme(scheme).Build()
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker-token",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			"token": []byte("secret-token-takes-precedence"),
+		},
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tokenSecret).Build()
 	reconciler := &KairosConfigReconciler{
 		Client: client,
 		Scheme: scheme,
@@ -674,7 +1034,11 @@ func TestGenerateK0sCloudConfig_WorkerMissingToken(t *testing.T) {
 			Role:              "worker",
 			Distribution:      "k0s",
 			KubernetesVersion: "v1.30.0+k0s.0",
-			// No token provided
+			WorkerToken:       "inline-token-should-be-ignored",
+			WorkerTokenSecretRef: &bootstrapv1beta2.WorkerTokenSecretReference{
+				Name: "worker-token",
+				Key:  "token",
+			},
 			UserName:     "kairos",
 			UserPassword: "kairos",
 			UserGroups:   []string{"admin"},
@@ -695,7 +1059,7 @@ func TestGenerateK0sCloudConfig_WorkerMissingToken(t *testing.T) {
 		},
 	}
 
-	_, err := reconciler.generateK0sCloudConfig(
+	cloudConfig, err := reconciler.generateK0sCloudConfig(
 		context.Background(),
 		log.Log,
 		kairosConfig,
@@ -705,11 +1069,13 @@ func TestGenerateK0sCloudConfig_WorkerMissingToken(t *testing.T) {
 		"https://control-plane:6443",
 	)
 
-	g.Expect(err).To(HaveOccurred())
-	g.Expect(err.Error()).To(ContainSubstring("worker token is required"))
+	g.Expect(err).NotTo(HaveOccurred())
+	// WorkerTokenSecretRef should take precedence over WorkerToken
+	g.Expect(cloudConfig).To(ContainSubstring("secret-token-takes-precedence"))
+	g.Expect(cloudConfig).NotTo(ContainSubstring("inline-token-should-be-ignored"))
 }
 
-func TestGenerateK0sCloudConfig_HostnameTemplating(t *testing.T) {
+func TestGenerateK0sCloudConfig_WorkerMissingToken(t *testing.T) {
 	g := NewWithT(t)
 
 	scheme := runtime.NewScheme()
@@ -729,13 +1095,13 @@ func TestGenerateK0sCloudConfig_HostnameTemplating(t *testing.T) {
 			Namespace: "default",
 		},
 		Spec: bootstrapv1beta2.KairosConfigSpec{
-			Role:              "control-plane",
+			Role:              "worker",
 			Distribution:      "k0s",
 			KubernetesVersion: "v1.30.0+k0s.0",
-			SingleNode:        true,
-			UserName:          "kairos",
-			UserPassword:      "kairos",
-			UserGroups:        []string{"admin"},
+			// No token provided
+			UserName:     "kairos",
+			UserPassword: "kairos",
+			UserGroups:   []string{"admin"},
 		},
 	}
 
@@ -753,24 +1119,21 @@ func TestGenerateK0sCloudConfig_HostnameTemplating(t *testing.T) {
 		},
 	}
 
-	cloudConfig, err := reconciler.generateK0sCloudConfig(
+	_, err := reconciler.generateK0sCloudConfig(
 		context.Background(),
 		log.Log,
 		kairosConfig,
 		machine,
 		cluster,
-		"control-plane",
-		"",
+		"worker",
+		"https://control-plane:6443",
 	)
 
-	g.Expect(err).NotTo(HaveOccurred())
-	// Verify hostname defaults to Machine name when no explicit hostname is set
-	g.Expect(cloudConfig).To(ContainSubstring("hostname: test-machine"))
-	// Should NOT contain Go template syntax
-	g.Expect(cloudConfig).NotTo(ContainSubstring("{{.MachineID}}"))
+	// Without a token the controller provisions one, which needs the workload cluster kubeconfig
+	g.Expect(err).To(Equal(errWorkerTokenNotReady))
 }
 
-func TestGenerateK3sCloudConfig_WorkerTokenSecretRef(t *testing.T) {
+func TestGenerateK0sCloudConfig_WorkerTokenProvisioned(t *testing.T) {
 	g := NewWithT(t)
 
 	scheme := runtime.NewScheme()
@@ -778,32 +1141,420 @@ func TestGenerateK3sCloudConfig_WorkerTokenSecretRef(t *testing.T) {
 	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
 	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
 
-	secret := &corev1.Secret{
+	cluster := &clusterv1.Cluster{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "k3s-token-secret",
+			Name:      "test-cluster",
 			Namespace: "default",
 		},
-		Data: map[string][]byte{
-			"token": []byte("k3s-secret-token"),
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "10.0.0.10", Port: 6443},
+		},
+	}
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters["test-cluster"] = &clientcmdapi.Cluster{Server: "https://192.168.1.10:6443", CertificateAuthorityData: []byte("test-ca")}
+	kubeconfig.AuthInfos["admin"] = &clientcmdapi.AuthInfo{Token: "admin-token"}
+	kubeconfig.Contexts["admin@test-cluster"] = &clientcmdapi.Context{Cluster: "test-cluster", AuthInfo: "admin"}
+	kubeconfig.CurrentContext = "admin@test-cluster"
+	kubeconfigData, err := clientcmd.Write(*kubeconfig)
+	g.Expect(err).NotTo(HaveOccurred())
+	kubeconfigSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster-kubeconfig",
+			Namespace: "default",
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
 		},
+		Data: map[string][]byte{"value": kubeconfigData},
 	}
 
-	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	mgmtClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, kubeconfigSecret).Build()
+	workloadClient := fake.NewClientBuilder().WithScheme(scheme).Build()
 	reconciler := &KairosConfigReconciler{
-		Client: client,
-		Scheme: scheme,
+		Client:  mgmtClient,
+		Scheme:  scheme,
+		Tracker: remote.NewTestClusterCacheTracker(log.Log, mgmtClient, workloadClient, scheme, types.NamespacedName{Namespace: "default", Name: "test-cluster"}),
 	}
 
-	kairosConfig := &bootstrapv1beta2.KairosConfig{
+	newWorkerConfig := func() *bootstrapv1beta2.KairosConfig {
+		return &bootstrapv1beta2.KairosConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-config",
+				Namespace: "default",
+			},
+			Spec: bootstrapv1beta2.KairosConfigSpec{
+				Role:              "worker",
+				Distribution:      "k0s",
+				KubernetesVersion: "v1.30.0+k0s.0",
+			},
+		}
+	}
+	machine := &clusterv1.Machine{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "test-config",
+			Name:      "test-machine",
 			Namespace: "default",
 		},
-		Spec: bootstrapv1beta2.KairosConfigSpec{
-			Role:              "worker",
-			Distribution:      "k3s",
-			KubernetesVersion: "v1.30.0+k3s.0",
-			K3sTokenSecretRef: &bootstrapv1beta2.WorkerTokenSecretReference{
+	}
+
+	kairosConfig := newWorkerConfig()
+	cloudConfig, err := reconciler.generateK0sCloudConfig(context.Background(), log.Log, kairosConfig, machine, cluster, "worker", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	// The managed secret is recorded in status, leaving the spec unchanged
+	g.Expect(kairosConfig.Spec.WorkerTokenSecretRef).To(BeNil())
+	g.Expect(kairosConfig.Status.WorkerTokenSecretRef).NotTo(BeNil())
+	g.Expect(kairosConfig.Status.WorkerTokenSecretRef.Name).To(Equal("test-cluster-k0s-worker-token"))
+
+	// The bootstrap token lives in the workload cluster
+	bootstrapTokens := &corev1.SecretList{}
+	g.Expect(workloadClient.List(context.Background(), bootstrapTokens)).To(Succeed())
+	g.Expect(bootstrapTokens.Items).To(HaveLen(1))
+	g.Expect(bootstrapTokens.Items[0].Namespace).To(Equal("kube-system"))
+
+	// and the join token in the managed secret, rendered into the cloud-config
+	tokenSecret := &corev1.Secret{}
+	g.Expect(mgmtClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "test-cluster-k0s-worker-token"}, tokenSecret)).To(Succeed())
+	g.Expect(tokenSecret.Labels).To(HaveKeyWithValue(clusterv1.ClusterNameLabel, "test-cluster"))
+	g.Expect(tokenSecret.Annotations).To(HaveKey(workerJoinTokenExpiryAnnotation))
+	g.Expect(tokenSecret.Type).To(Equal(bootstrapv1beta2.CredentialsSecretType))
+	g.Expect(metav1.IsControlledBy(tokenSecret, cluster)).To(BeTrue())
+	g.Expect(cloudConfig).To(ContainSubstring(string(tokenSecret.Data["token"])))
+
+	// Further workers reuse the token while it is fresh
+	_, err = reconciler.generateK0sCloudConfig(context.Background(), log.Log, newWorkerConfig(), machine, cluster, "worker", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(workloadClient.List(context.Background(), bootstrapTokens)).To(Succeed())
+	g.Expect(bootstrapTokens.Items).To(HaveLen(1))
+
+	// An expiring secret of an earlier release is replaced by a credentials secret
+	g.Expect(mgmtClient.Delete(context.Background(), tokenSecret)).To(Succeed())
+	g.Expect(mgmtClient.Create(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-cluster-k0s-worker-token",
+			Namespace:   "default",
+			Annotations: map[string]string{workerJoinTokenExpiryAnnotation: time.Now().UTC().Format(time.RFC3339)},
+		},
+		Type: clusterv1.ClusterSecretType,
+		Data: map[string][]byte{"token": []byte("old-token")},
+	})).To(Succeed())
+	_, err = reconciler.generateK0sCloudConfig(context.Background(), log.Log, newWorkerConfig(), machine, cluster, "worker", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(mgmtClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "test-cluster-k0s-worker-token"}, tokenSecret)).To(Succeed())
+	g.Expect(tokenSecret.Type).To(Equal(bootstrapv1beta2.CredentialsSecretType))
+	g.Expect(string(tokenSecret.Data["token"])).NotTo(Equal("old-token"))
+}
+
+func TestReconcileDelete_CleansUpGeneratedSecrets(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default", UID: "cluster-uid"},
+	}
+	token := k0stoken.BootstrapToken{ID: "abcdef", Secret: "0123456789abcdef"}
+	joinToken, err := k0stoken.JoinToken("https://10.0.0.10:6443", []byte("test-ca"), token)
+	g.Expect(err).NotTo(HaveOccurred())
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-cluster-k0s-worker-token",
+			Namespace:   "default",
+			Labels:      map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+			Annotations: map[string]string{workerJoinTokenExpiryAnnotation: time.Now().Add(time.Hour).UTC().Format(time.RFC3339)},
+		},
+		Type: bootstrapv1beta2.CredentialsSecretType,
+		Data: map[string][]byte{"token": []byte(joinToken)},
+	}
+	g.Expect(controllerutil.SetControllerReference(cluster, tokenSecret, scheme)).To(Succeed())
+
+	newWorkerConfig := func(name string) *bootstrapv1beta2.KairosConfig {
+		return &bootstrapv1beta2.KairosConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       name,
+				Namespace:  "default",
+				UID:        types.UID(name + "-uid"),
+				Labels:     map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+				Finalizers: []string{bootstrapv1beta2.KairosConfigFinalizer},
+			},
+			Spec: bootstrapv1beta2.KairosConfigSpec{
+				Role:         "worker",
+				Distribution: "k0s",
+			},
+			Status: bootstrapv1beta2.KairosConfigStatus{
+				WorkerTokenSecretRef: &bootstrapv1beta2.WorkerTokenSecretReference{
+					Name: "test-cluster-k0s-worker-token",
+					Key:  "token",
+				},
+			},
+		}
+	}
+	deleting := newWorkerConfig("worker-a")
+	deleting.Status.DataSecretName = pointer.String("worker-a-data")
+	dataSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-a-data", Namespace: "default"},
+		Type:       clusterv1.ClusterSecretType,
+	}
+	g.Expect(controllerutil.SetControllerReference(deleting, dataSecret, scheme)).To(Succeed())
+	// Workers provisioned by earlier releases reference the token from the spec
+	remaining := newWorkerConfig("worker-b")
+	remaining.Spec.WorkerTokenSecretRef, remaining.Status.WorkerTokenSecretRef = remaining.Status.WorkerTokenSecretRef, nil
+
+	mgmtClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(cluster, tokenSecret, deleting, dataSecret, remaining).
+		WithStatusSubresource(&bootstrapv1beta2.KairosConfig{}).
+		Build()
+	workloadClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k0stoken.WorkerSecret(token, time.Now().Add(time.Hour))).Build()
+	reconciler := &KairosConfigReconciler{
+		Client:  mgmtClient,
+		Scheme:  scheme,
+		Tracker: remote.NewTestClusterCacheTracker(log.Log, mgmtClient, workloadClient, scheme, types.NamespacedName{Namespace: "default", Name: "test-cluster"}),
+	}
+	ctx := context.Background()
+
+	// The data secret goes, the token stays while another worker uses it
+	g.Expect(mgmtClient.Delete(ctx, deleting)).To(Succeed())
+	g.Expect(mgmtClient.Get(ctx, client.ObjectKeyFromObject(deleting), deleting)).To(Succeed())
+	_, err = reconciler.reconcileDelete(ctx, log.Log, deleting)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(apierrors.IsNotFound(mgmtClient.Get(ctx, client.ObjectKeyFromObject(deleting), &bootstrapv1beta2.KairosConfig{}))).To(BeTrue())
+	g.Expect(apierrors.IsNotFound(mgmtClient.Get(ctx, client.ObjectKeyFromObject(dataSecret), &corev1.Secret{}))).To(BeTrue())
+	g.Expect(mgmtClient.Get(ctx, client.ObjectKeyFromObject(tokenSecret), &corev1.Secret{})).To(Succeed())
+	bootstrapTokens := &corev1.SecretList{}
+	g.Expect(workloadClient.List(ctx, bootstrapTokens)).To(Succeed())
+	g.Expect(bootstrapTokens.Items).To(HaveLen(1))
+
+	// The last worker deletes the token and invalidates it in the workload cluster
+	g.Expect(mgmtClient.Delete(ctx, remaining)).To(Succeed())
+	g.Expect(mgmtClient.Get(ctx, client.ObjectKeyFromObject(remaining), remaining)).To(Succeed())
+	_, err = reconciler.reconcileDelete(ctx, log.Log, remaining)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(apierrors.IsNotFound(mgmtClient.Get(ctx, client.ObjectKeyFromObject(remaining), &bootstrapv1beta2.KairosConfig{}))).To(BeTrue())
+	g.Expect(apierrors.IsNotFound(mgmtClient.Get(ctx, client.ObjectKeyFromObject(tokenSecret), &corev1.Secret{}))).To(BeTrue())
+	g.Expect(workloadClient.List(ctx, bootstrapTokens)).To(Succeed())
+	g.Expect(bootstrapTokens.Items).To(BeEmpty())
+}
+
+func TestReconcileDelete_SkipsCleanupWhileClusterPaused(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	// clusterctl move pauses the Cluster before deleting the source objects
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default", UID: "cluster-uid"},
+		Spec:       clusterv1.ClusterSpec{Paused: true},
+	}
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster-k0s-worker-token",
+			Namespace: "default",
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+		},
+		Type: bootstrapv1beta2.CredentialsSecretType,
+		Data: map[string][]byte{"token": []byte("join-token")},
+	}
+	g.Expect(controllerutil.SetControllerReference(cluster, tokenSecret, scheme)).To(Succeed())
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "worker-a",
+			Namespace:  "default",
+			UID:        "worker-a-uid",
+			Labels:     map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+			Finalizers: []string{bootstrapv1beta2.KairosConfigFinalizer},
+		},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role:         "worker",
+			Distribution: "k0s",
+		},
+		Status: bootstrapv1beta2.KairosConfigStatus{
+			DataSecretName:       pointer.String("worker-a-data"),
+			WorkerTokenSecretRef: &bootstrapv1beta2.WorkerTokenSecretReference{Name: "test-cluster-k0s-worker-token", Key: "token"},
+		},
+	}
+	dataSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-a-data", Namespace: "default"},
+		Type:       clusterv1.ClusterSecretType,
+	}
+	g.Expect(controllerutil.SetControllerReference(kairosConfig, dataSecret, scheme)).To(Succeed())
+
+	mgmtClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(cluster, tokenSecret, kairosConfig, dataSecret).
+		WithStatusSubresource(&bootstrapv1beta2.KairosConfig{}).
+		Build()
+	reconciler := &KairosConfigReconciler{
+		Client: mgmtClient,
+		Scheme: scheme,
+	}
+	ctx := context.Background()
+
+	g.Expect(mgmtClient.Delete(ctx, kairosConfig)).To(Succeed())
+	g.Expect(mgmtClient.Get(ctx, client.ObjectKeyFromObject(kairosConfig), kairosConfig)).To(Succeed())
+	_, err := reconciler.reconcileDelete(ctx, log.Log, kairosConfig)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// The finalizer holds the object until the pause is lifted
+	g.Expect(mgmtClient.Get(ctx, client.ObjectKeyFromObject(kairosConfig), &bootstrapv1beta2.KairosConfig{})).To(Succeed())
+	g.Expect(mgmtClient.Get(ctx, client.ObjectKeyFromObject(dataSecret), &corev1.Secret{})).To(Succeed())
+	g.Expect(mgmtClient.Get(ctx, client.ObjectKeyFromObject(tokenSecret), &corev1.Secret{})).To(Succeed())
+}
+
+func TestGenerateK0sCloudConfig_HostnameTemplating(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	client := fake.NewClientBuilder().WithScheme(scheme).Build()
+	reconciler := &KairosConfigReconciler{
+		Client: client,
+		Scheme: scheme,
+	}
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-config",
+			Namespace: "default",
+		},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role:              "control-plane",
+			Distribution:      "k0s",
+			KubernetesVersion: "v1.30.0+k0s.0",
+			SingleNode:        true,
+			UserName:          "kairos",
+			UserPassword:      "kairos",
+			UserGroups:        []string{"admin"},
+		},
+	}
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-machine",
+			Namespace: "default",
+		},
+	}
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+
+	cloudConfig, err := reconciler.generateK0sCloudConfig(
+		context.Background(),
+		log.Log,
+		kairosConfig,
+		machine,
+		cluster,
+		"control-plane",
+		"",
+	)
+
+	g.Expect(err).NotTo(HaveOccurred())
+	// Verify hostname defaults to Machine name when no explicit hostname is set
+	g.Expect(cloudConfig).To(ContainSubstring("hostname: test-machine"))
+	// Should NOT contain Go template syntax
+	g.Expect(cloudConfig).NotTo(ContainSubstring("{{.MachineID}}"))
+
+	// A hostname template expands the controller tokens and keeps Kairos expressions
+	kairosConfig.Spec.HostnameTemplate = "{cluster-name}-{machine-name}-{{ trunc 4 .MachineID }}"
+	cloudConfig, err = reconciler.generateK0sCloudConfig(
+		context.Background(),
+		log.Log,
+		kairosConfig,
+		machine,
+		cluster,
+		"control-plane",
+		"",
+	)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cloudConfig).To(ContainSubstring("hostname: test-cluster-test-machine-{{ trunc 4 .MachineID }}"))
+}
+
+func TestGenerateK0sCloudConfig_MachineMetadata(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	reconciler := &KairosConfigReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme: scheme,
+	}
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role:              "control-plane",
+			Distribution:      "k0s",
+			KubernetesVersion: "v1.30.0+k0s.0",
+			SingleNode:        true,
+			KubeletExtraArgs:  map[string]string{"node-labels": "tier=edge,cluster.x-k8s.io/role=gateway"},
+		},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: "default"},
+		Spec:       clusterv1.MachineSpec{ClusterName: "test-cluster", FailureDomain: pointer.String("zone-a")},
+	}
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+
+	cloudConfig, err := reconciler.generateK0sCloudConfig(context.Background(), log.Log, kairosConfig, machine, cluster, "control-plane", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cloudConfig).To(ContainSubstring("- path: /run/cluster-api/metadata.yaml"))
+	g.Expect(cloudConfig).To(ContainSubstring(`
+            clusterName: test-cluster
+            failureDomain: zone-a
+            machineName: test-machine
+            namespace: default
+            role: control-plane
+`))
+	// Labels set through spec.kubeletExtraArgs take precedence
+	g.Expect(cloudConfig).To(ContainSubstring("--node-labels=tier=edge,cluster.x-k8s.io/role=gateway," +
+		"cluster.x-k8s.io/cluster-name=test-cluster,cluster.x-k8s.io/cluster-namespace=default," +
+		"cluster.x-k8s.io/failure-domain=zone-a,cluster.x-k8s.io/machine=test-machine"))
+}
+
+func TestGenerateK3sCloudConfig_WorkerTokenSecretRef(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "k3s-token-secret",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			"token": []byte("k3s-secret-token"),
+		},
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	reconciler := &KairosConfigReconciler{
+		Client: client,
+		Scheme: scheme,
+	}
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-config",
+			Namespace: "default",
+		},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role:              "worker",
+			Distribution:      "k3s",
+			KubernetesVersion: "v1.30.0+k3s.0",
+			K3sTokenSecretRef: &bootstrapv1beta2.WorkerTokenSecretReference{
 				Name: "k3s-token-secret",
 				Key:  "token",
 			},
@@ -902,6 +1653,67 @@ func TestGenerateK3sCloudConfig_WorkerTokenSecretMissing(t *testing.T) {
 	g.Expect(err).To(Equal(errK3sTokenNotReady))
 }
 
+func TestGenerateK0sCloudConfig_ControlPlaneJoinToken(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	client := fake.NewClientBuilder().WithScheme(scheme).Build()
+	reconciler := &KairosConfigReconciler{
+		Client: client,
+		Scheme: scheme,
+	}
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-config",
+			Namespace: "default",
+		},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role:              "control-plane",
+			Distribution:      "k0s",
+			KubernetesVersion: "v1.30.0+k0s.0",
+			ControllerTokenSecretRef: &bootstrapv1beta2.WorkerTokenSecretReference{
+				Name: "test-cluster-k0s-controller-token",
+				Key:  "token",
+			},
+		},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-machine",
+			Namespace: "default",
+		},
+	}
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+
+	// The token only exists once the first control plane node is up
+	_, err := reconciler.generateK0sCloudConfig(context.Background(), log.Log, kairosConfig, machine, cluster, "control-plane", "")
+	g.Expect(err).To(Equal(errControllerTokenNotReady))
+
+	g.Expect(client.Create(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster-k0s-controller-token",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{"token": []byte("test-controller-token")},
+	})).To(Succeed())
+
+	cloudConfig, err := reconciler.generateK0sCloudConfig(context.Background(), log.Log, kairosConfig, machine, cluster, "control-plane", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cloudConfig).To(ContainSubstring("--token-file /etc/k0s/controller-token"))
+	g.Expect(cloudConfig).To(ContainSubstring("test-controller-token"))
+	g.Expect(cloudConfig).NotTo(ContainSubstring("--single"))
+}
+
 func TestGenerateK3sCloudConfig_ControlPlaneKubeVirtCapk(t *testing.T) {
 	g := NewWithT(t)
 
@@ -983,3 +1795,1005 @@ func TestGenerateK3sCloudConfig_ControlPlaneKubeVirtCapk(t *testing.T) {
 	g.Expect(cloudConfig).To(ContainSubstring("--tls-san=192.0.2.10"))
 	g.Expect(cloudConfig).To(ContainSubstring("k3s:"))
 }
+
+func TestReconcile_ValidateOnReconcileRejectsInvalidSpec(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-machine",
+			Namespace: "default",
+			Labels: map[string]string{
+				clusterv1.ClusterNameLabel: "test-cluster",
+			},
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: "test-cluster",
+		},
+	}
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-config",
+			Namespace:  "default",
+			Finalizers: []string{bootstrapv1beta2.KairosConfigFinalizer},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Machine",
+					Name:       "test-machine",
+				},
+			},
+		},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role:              "worker",
+			Distribution:      "k3s",
+			KubernetesVersion: "v1.30.0+k3s1",
+			// No token provided, which the webhook would reject
+		},
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(cluster, machine, kairosConfig).
+		WithStatusSubresource(kairosConfig).
+		Build()
+	reconciler := &KairosConfigReconciler{
+		Client:              client,
+		Scheme:              scheme,
+		ValidateOnReconcile: true,
+	}
+
+	_, err := reconciler.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-config", Namespace: "default"},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	updated := &bootstrapv1beta2.KairosConfig{}
+	g.Expect(client.Get(context.Background(), types.NamespacedName{Name: "test-config", Namespace: "default"}, updated)).To(Succeed())
+	g.Expect(conditions.IsFalse(updated, bootstrapv1beta2.ValidSpecCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(updated, bootstrapv1beta2.ValidSpecCondition)).To(Equal(bootstrapv1beta2.InvalidSpecReason))
+	g.Expect(updated.Status.FailureReason).To(Equal(bootstrapv1beta2.InvalidSpecReason))
+	g.Expect(updated.Status.DataSecretName).To(BeNil())
+
+	// The v1beta2 conditions mirror the legacy ones
+	ready := meta.FindStatusCondition(updated.GetV1Beta2Conditions(), bootstrapv1beta2.ReadyV1Beta2Condition)
+	g.Expect(ready).NotTo(BeNil())
+	g.Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(ready.Reason).To(Equal(bootstrapv1beta2.InvalidSpecReason))
+	dataSecret := meta.FindStatusCondition(updated.GetV1Beta2Conditions(), bootstrapv1beta2.DataSecretAvailableV1Beta2Condition)
+	g.Expect(dataSecret).NotTo(BeNil())
+	g.Expect(dataSecret.Status).To(Equal(metav1.ConditionUnknown))
+	g.Expect(dataSecret.Reason).To(Equal(bootstrapv1beta2.DataSecretNotAvailableV1Beta2Reason))
+
+	secrets := &corev1.SecretList{}
+	g.Expect(client.List(context.Background(), secrets)).To(Succeed())
+	g.Expect(secrets.Items).To(BeEmpty())
+}
+
+func TestReconcile_Paused(t *testing.T) {
+	tests := []struct {
+		name          string
+		clusterPaused bool
+		annotations   map[string]string
+		wantPaused    bool
+	}{
+		{
+			name:          "cluster paused",
+			clusterPaused: true,
+			wantPaused:    true,
+		},
+		{
+			name:        "paused annotation",
+			annotations: map[string]string{clusterv1.PausedAnnotation: ""},
+			wantPaused:  true,
+		},
+		{
+			name:       "not paused",
+			wantPaused: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+			g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+			g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+				Spec:       clusterv1.ClusterSpec{Paused: tt.clusterPaused},
+			}
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-machine",
+					Namespace: "default",
+					Labels:    map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+				},
+				Spec: clusterv1.MachineSpec{ClusterName: "test-cluster"},
+			}
+			// The spec is invalid so an unpaused reconcile is visible in ValidSpec
+			kairosConfig := &bootstrapv1beta2.KairosConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-config",
+					Namespace:   "default",
+					Annotations: tt.annotations,
+					Finalizers:  []string{bootstrapv1beta2.KairosConfigFinalizer},
+					OwnerReferences: []metav1.OwnerReference{
+						{APIVersion: clusterv1.GroupVersion.String(), Kind: "Machine", Name: "test-machine"},
+					},
+				},
+				Spec: bootstrapv1beta2.KairosConfigSpec{
+					Role:              "worker",
+					Distribution:      "k3s",
+					KubernetesVersion: "v1.30.0+k3s1",
+				},
+			}
+
+			client := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(cluster, machine, kairosConfig).
+				WithStatusSubresource(kairosConfig).
+				Build()
+			reconciler := &KairosConfigReconciler{
+				Client:              client,
+				Scheme:              scheme,
+				ValidateOnReconcile: true,
+			}
+
+			_, err := reconciler.Reconcile(context.Background(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: "test-config", Namespace: "default"},
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			updated := &bootstrapv1beta2.KairosConfig{}
+			g.Expect(client.Get(context.Background(), types.NamespacedName{Name: "test-config", Namespace: "default"}, updated)).To(Succeed())
+			paused := meta.FindStatusCondition(updated.GetV1Beta2Conditions(), bootstrapv1beta2.PausedV1Beta2Condition)
+			g.Expect(paused).NotTo(BeNil())
+			if tt.wantPaused {
+				g.Expect(conditions.IsTrue(updated, bootstrapv1beta2.PausedCondition)).To(BeTrue())
+				g.Expect(paused.Reason).To(Equal(bootstrapv1beta2.PausedV1Beta2Reason))
+				g.Expect(conditions.Has(updated, bootstrapv1beta2.ValidSpecCondition)).To(BeFalse())
+				return
+			}
+			g.Expect(conditions.IsFalse(updated, bootstrapv1beta2.PausedCondition)).To(BeTrue())
+			g.Expect(paused.Reason).To(Equal(bootstrapv1beta2.NotPausedV1Beta2Reason))
+			g.Expect(conditions.IsFalse(updated, bootstrapv1beta2.ValidSpecCondition)).To(BeTrue())
+		})
+	}
+}
+
+func TestReconcile_Move(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default", UID: "cluster-uid"},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "10.0.0.1", Port: 6443},
+		},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-machine",
+			Namespace: "default",
+			UID:       "machine-uid",
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+		},
+		Spec: clusterv1.MachineSpec{ClusterName: "test-cluster"},
+	}
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-config",
+			Namespace:  "default",
+			UID:        "config-uid",
+			Generation: 1,
+			Finalizers: []string{bootstrapv1beta2.KairosConfigFinalizer},
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: clusterv1.GroupVersion.String(), Kind: "Machine", Name: "test-machine", UID: "machine-uid"},
+			},
+		},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role:              "worker",
+			Distribution:      "k0s",
+			KubernetesVersion: "v1.30.0+k0s.0",
+			WorkerToken:       "test-token-12345",
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-config", Namespace: "default"}}
+
+	source := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(cluster, machine, kairosConfig).
+		WithStatusSubresource(kairosConfig).
+		Build()
+	_, err := (&KairosConfigReconciler{Client: source, Scheme: scheme}).Reconcile(ctx, req)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	generated := &bootstrapv1beta2.KairosConfig{}
+	g.Expect(source.Get(ctx, req.NamespacedName, generated)).To(Succeed())
+	g.Expect(generated.Status.DataSecretName).NotTo(BeNil())
+	secretName := *generated.Status.DataSecretName
+
+	// The Machine controller copies the secret name to the Machine, and
+	// clusterctl pauses the Cluster before moving it
+	g.Expect(source.Get(ctx, client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+	machine.Spec.Bootstrap.DataSecretName = &secretName
+	g.Expect(source.Update(ctx, machine)).To(Succeed())
+	g.Expect(source.Get(ctx, client.ObjectKeyFromObject(cluster), cluster)).To(Succeed())
+	cluster.Spec.Paused = true
+	g.Expect(source.Update(ctx, cluster)).To(Succeed())
+
+	// clusterctl move discovers the secret through its cluster-name label and
+	// its controller reference
+	secret := &corev1.Secret{}
+	g.Expect(source.Get(ctx, types.NamespacedName{Name: secretName, Namespace: "default"}, secret)).To(Succeed())
+	g.Expect(secret.Labels).To(HaveKeyWithValue(clusterv1.ClusterNameLabel, "test-cluster"))
+	owner := metav1.GetControllerOf(secret)
+	g.Expect(owner).NotTo(BeNil())
+	g.Expect(owner.APIVersion).To(Equal(bootstrapv1beta2.GroupVersion.String()))
+	g.Expect(owner.Kind).To(Equal("KairosConfig"))
+	g.Expect(owner.UID).To(Equal(kairosConfig.UID))
+
+	target := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&bootstrapv1beta2.KairosConfig{}).
+		Build()
+	moveObjects(g, source, target,
+		&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}},
+		&clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: "default"}},
+		&bootstrapv1beta2.KairosConfig{ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: "default"}},
+	)
+	reconciler := &KairosConfigReconciler{Client: target, Scheme: scheme}
+
+	// Nothing is touched on the target while the Cluster is paused
+	_, err = reconciler.Reconcile(ctx, req)
+	g.Expect(err).NotTo(HaveOccurred())
+	moved := &bootstrapv1beta2.KairosConfig{}
+	g.Expect(target.Get(ctx, req.NamespacedName, moved)).To(Succeed())
+	g.Expect(conditions.IsTrue(moved, bootstrapv1beta2.PausedCondition)).To(BeTrue())
+	g.Expect(moved.Status.DataSecretName).To(BeNil())
+
+	// Once unpaused, the moved secret is reused rather than replaced
+	movedCluster := &clusterv1.Cluster{}
+	g.Expect(target.Get(ctx, client.ObjectKeyFromObject(cluster), movedCluster)).To(Succeed())
+	movedCluster.Spec.Paused = false
+	g.Expect(target.Update(ctx, movedCluster)).To(Succeed())
+	_, err = reconciler.Reconcile(ctx, req)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(target.Get(ctx, req.NamespacedName, moved)).To(Succeed())
+	g.Expect(conditions.IsFalse(moved, bootstrapv1beta2.PausedCondition)).To(BeTrue())
+	g.Expect(moved.Status.DataSecretName).To(HaveValue(Equal(secretName)))
+	secrets := &corev1.SecretList{}
+	g.Expect(target.List(ctx, secrets)).To(Succeed())
+	g.Expect(secrets.Items).To(HaveLen(1))
+	g.Expect(secrets.Items[0].Data).To(Equal(secret.Data))
+	owner = metav1.GetControllerOf(&secrets.Items[0])
+	g.Expect(owner).NotTo(BeNil())
+	g.Expect(owner.UID).To(Equal(moved.UID))
+}
+
+// moveObjects copies objs from source to target like clusterctl move: the
+// copies get new UIDs, owner references are rewritten to them and status is
+// not carried over.
+func moveObjects(g *WithT, source, target client.Client, objs ...client.Object) {
+	ctx := context.Background()
+	uids := map[types.UID]types.UID{}
+	for _, obj := range objs {
+		g.Expect(source.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+		uids[obj.GetUID()] = "moved-" + obj.GetUID()
+	}
+	for _, obj := range objs {
+		obj.SetUID(uids[obj.GetUID()])
+		obj.SetResourceVersion("")
+		refs := obj.GetOwnerReferences()
+		for i := range refs {
+			if uid, ok := uids[refs[i].UID]; ok {
+				refs[i].UID = uid
+			}
+		}
+		obj.SetOwnerReferences(refs)
+		if config, ok := obj.(*bootstrapv1beta2.KairosConfig); ok {
+			config.Status = bootstrapv1beta2.KairosConfigStatus{}
+		}
+		g.Expect(target.Create(ctx, obj)).To(Succeed())
+	}
+}
+
+func TestReconcileBootstrapData_RegenerateOnChange(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "10.0.0.1", Port: 6443},
+		},
+	}
+	deployment := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "workers", Namespace: "default"},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-machine",
+			Namespace: "default",
+			Labels: map[string]string{
+				clusterv1.ClusterNameLabel:           "test-cluster",
+				clusterv1.MachineDeploymentNameLabel: "workers",
+			},
+		},
+		Spec:   clusterv1.MachineSpec{ClusterName: "test-cluster"},
+		Status: clusterv1.MachineStatus{InfrastructureReady: true},
+	}
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default", Generation: 1},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role:              "worker",
+			Distribution:      "k0s",
+			KubernetesVersion: "v1.30.0+k0s.0",
+			WorkerToken:       "test-token-12345",
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, deployment, machine).Build()
+	reconciler := &KairosConfigReconciler{Client: c, Scheme: scheme}
+	ctx := context.Background()
+
+	_, err := reconciler.reconcileBootstrapData(ctx, log.Log, kairosConfig, machine, cluster)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(kairosConfig.Status.DataSecretName).NotTo(BeNil())
+	g.Expect(kairosConfig.Status.DataSecretGeneration).To(Equal(int64(1)))
+	secretKey := types.NamespacedName{Name: *kairosConfig.Status.DataSecretName, Namespace: "default"}
+
+	cloudConfig := func() string {
+		secret := &corev1.Secret{}
+		g.Expect(c.Get(ctx, secretKey, secret)).To(Succeed())
+		return string(secret.Data["value"])
+	}
+
+	// Without regenerateOnChange spec changes leave the secret alone
+	kairosConfig.Spec.SSHAuthorizedKeys = []string{"ssh-ed25519 AAAA first-change"}
+	kairosConfig.Generation = 2
+	_, err = reconciler.reconcileBootstrapData(ctx, log.Log, kairosConfig, machine, cluster)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cloudConfig()).NotTo(ContainSubstring("first-change"))
+	g.Expect(kairosConfig.Status.DataSecretGeneration).To(Equal(int64(1)))
+
+	kairosConfig.Spec.RegenerateOnChange = true
+	kairosConfig.Spec.SSHAuthorizedKeys = []string{"ssh-ed25519 BBBB second-change"}
+	kairosConfig.Generation = 3
+	_, err = reconciler.reconcileBootstrapData(ctx, log.Log, kairosConfig, machine, cluster)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cloudConfig()).To(ContainSubstring("second-change"))
+	g.Expect(*kairosConfig.Status.DataSecretName).To(Equal(secretKey.Name))
+	g.Expect(kairosConfig.Status.DataSecretGeneration).To(Equal(int64(3)))
+
+	// The machine booted from the old data, so it and its MachineDeployment are flagged
+	updatedMachine := &clusterv1.Machine{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(machine), updatedMachine)).To(Succeed())
+	g.Expect(updatedMachine.Annotations).To(HaveKeyWithValue(bootstrapv1beta2.BootstrapDataOutdatedAnnotation, "3"))
+	updatedDeployment := &clusterv1.MachineDeployment{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(deployment), updatedDeployment)).To(Succeed())
+	g.Expect(updatedDeployment.Annotations).To(HaveKeyWithValue(bootstrapv1beta2.BootstrapDataOutdatedAnnotation, "3"))
+}
+
+func TestReconcileBootstrapData_MetricsAndEvents(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "10.0.0.1", Port: 6443},
+		},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-machine",
+			Namespace: "default",
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+		},
+		Spec: clusterv1.MachineSpec{ClusterName: "test-cluster"},
+	}
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-token", Namespace: "default"},
+		Data:       map[string][]byte{"other": []byte("test-token-12345")},
+	}
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default", Generation: 1},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role:                 "worker",
+			Distribution:         "k3s",
+			KubernetesVersion:    "v1.30.0+k3s1",
+			WorkerTokenSecretRef: &bootstrapv1beta2.WorkerTokenSecretReference{Name: "worker-token"},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, machine, tokenSecret).Build()
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &KairosConfigReconciler{Client: c, Scheme: scheme, recorder: recorder}
+	ctx := context.Background()
+
+	// The secret lacks the token key, which counts as a token fetch failure
+	failures := testutil.ToFloat64(metrics.TokenFetchFailures.WithLabelValues("k3s"))
+	_, err := reconciler.reconcileBootstrapData(ctx, log.Log, kairosConfig, machine, cluster)
+	g.Expect(err).To(MatchError(ContainSubstring("does not contain key 'token'")))
+	g.Expect(testutil.ToFloat64(metrics.TokenFetchFailures.WithLabelValues("k3s"))).To(Equal(failures + 1))
+	// Events go to the KairosConfig and its Machine
+	for i := 0; i < 2; i++ {
+		g.Expect(recorder.Events).To(Receive(HavePrefix("Warning " + bootstrapv1beta2.TokenMissingEvent)))
+	}
+
+	// Successful generation is timed
+	kairosConfig.Spec.WorkerTokenSecretRef.Key = "other"
+	_, err = reconciler.reconcileBootstrapData(ctx, log.Log, kairosConfig, machine, cluster)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(testutil.ToFloat64(metrics.TokenFetchFailures.WithLabelValues("k3s"))).To(Equal(failures + 1))
+	g.Expect(testutil.CollectAndCount(metrics.BootstrapDataGenerationDuration)).To(BeNumerically(">=", 1))
+	for i := 0; i < 2; i++ {
+		g.Expect(recorder.Events).To(Receive(HavePrefix("Normal " + bootstrapv1beta2.BootstrapDataGeneratedEvent)))
+	}
+
+	// Writing the same data again is not reported
+	_, err = reconciler.reconcileBootstrapData(ctx, log.Log, kairosConfig, machine, cluster)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(recorder.Events).NotTo(Receive())
+}
+
+func TestEventfRedactsTokens(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &KairosConfigReconciler{recorder: recorder}
+	kairosConfig := &bootstrapv1beta2.KairosConfig{ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"}}
+
+	reconciler.eventf(kairosConfig, nil, corev1.EventTypeWarning, bootstrapv1beta2.TokenMissingEvent,
+		"Failed to get join token: %v", errors.New("token abcdef.0123456789abcdef rejected"))
+	g.Expect(recorder.Events).To(Receive(Equal("Warning TokenMissing Failed to get join token: token <redacted> rejected")))
+}
+
+func TestReconcileOSUpgrade(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+		Status: clusterv1.ClusterStatus{
+			Conditions: clusterv1.Conditions{{Type: clusterv1.ControlPlaneInitializedCondition, Status: corev1.ConditionTrue}},
+		},
+	}
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-config",
+			Namespace: "default",
+		},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role: "worker",
+			OSUpgrade: &bootstrapv1beta2.OSUpgrade{
+				Image:   "quay.io/kairos/ubuntu",
+				Version: "v3.5.0",
+			},
+		},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-machine",
+			Namespace: "default",
+		},
+		Status: clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: "worker-0"}},
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0"}}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(osupgrade.PlanGVK, meta.RESTScopeNamespace)
+	mgmtClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+	workloadClient := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(node).Build()
+	reconciler := &KairosConfigReconciler{
+		Client:  mgmtClient,
+		Scheme:  scheme,
+		Tracker: remote.NewTestClusterCacheTracker(log.Log, mgmtClient, workloadClient, scheme, types.NamespacedName{Namespace: "default", Name: "test-cluster"}),
+	}
+
+	result := reconciler.reconcileOSUpgrade(context.Background(), log.Log, kairosConfig, machine, cluster)
+	g.Expect(result.RequeueAfter).NotTo(BeZero())
+	g.Expect(conditions.GetReason(kairosConfig, bootstrapv1beta2.OSUpgradeCompletedCondition)).To(Equal(bootstrapv1beta2.OSUpgradeInProgressReason))
+
+	// The node opts into the shared worker Plan of the cluster
+	planName := osupgrade.NewWorkerPlan("test-cluster", "quay.io/kairos/ubuntu", "v3.5.0", 1).GetName()
+	g.Expect(workloadClient.Get(context.Background(), client.ObjectKeyFromObject(node), node)).To(Succeed())
+	g.Expect(node.Labels).To(HaveKeyWithValue(osupgrade.PlanNodeLabel, planName))
+	plan := &unstructured.Unstructured{}
+	plan.SetGroupVersionKind(osupgrade.PlanGVK)
+	g.Expect(workloadClient.Get(context.Background(), client.ObjectKey{Namespace: osupgrade.PlanNamespace, Name: planName}, plan)).To(Succeed())
+
+	g.Expect(unstructured.SetNestedField(plan.Object, "abc", "status", "latestHash")).To(Succeed())
+	g.Expect(workloadClient.Update(context.Background(), plan)).To(Succeed())
+	node.Labels["plan.upgrade.cattle.io/"+planName] = "abc"
+	g.Expect(workloadClient.Update(context.Background(), node)).To(Succeed())
+
+	result = reconciler.reconcileOSUpgrade(context.Background(), log.Log, kairosConfig, machine, cluster)
+	g.Expect(result.IsZero()).To(BeTrue())
+	g.Expect(conditions.IsTrue(kairosConfig, bootstrapv1beta2.OSUpgradeCompletedCondition)).To(BeTrue())
+}
+
+func TestResolveFiles(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "files", Namespace: "default"},
+		Data:       map[string][]byte{"cert.pem": []byte("secret-content")},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "files", Namespace: "default"},
+		Data:       map[string]string{"tool": "H4sIAAAAAAAA"},
+	}
+	reconciler := &KairosConfigReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret, configMap).Build(),
+		Scheme: scheme,
+	}
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Files: []bootstrapv1beta2.File{
+				{Path: "/etc/motd", Content: "hello"},
+				{
+					Path:        "/etc/ssl/cert.pem",
+					ContentFrom: &bootstrapv1beta2.FileSource{Secret: &bootstrapv1beta2.FileKeySelector{Name: "files", Key: "cert.pem"}},
+					Permissions: "0600",
+				},
+				{
+					Path:        "/usr/local/bin/tool",
+					ContentFrom: &bootstrapv1beta2.FileSource{ConfigMap: &bootstrapv1beta2.FileKeySelector{Name: "files", Key: "tool"}},
+					Encoding:    bootstrapv1beta2.GzipBase64FileEncoding,
+				},
+				{
+					Path:        "/usr/local/bin/big",
+					ContentFrom: &bootstrapv1beta2.FileSource{URL: "https://example.com/big"},
+					Owner:       "root:root",
+				},
+			},
+		},
+	}
+
+	files, downloads, err := reconciler.resolveFiles(context.Background(), kairosConfig)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(files).To(Equal([]bootstrap.File{
+		{Path: "/etc/motd", Content: "hello"},
+		// Referenced content is base64-encoded unless an encoding is set
+		{Path: "/etc/ssl/cert.pem", Content: "c2VjcmV0LWNvbnRlbnQ=", Encoding: "base64", Permissions: "0600"},
+		{Path: "/usr/local/bin/tool", Content: "H4sIAAAAAAAA", Encoding: "gzip+base64"},
+	}))
+	g.Expect(downloads).To(Equal([]bootstrap.Download{
+		{Path: "/usr/local/bin/big", URL: "https://example.com/big", Owner: "root:root"},
+	}))
+
+	kairosConfig.Spec.Files[1].ContentFrom.Secret.Key = "missing"
+	_, _, err = reconciler.resolveFiles(context.Background(), kairosConfig)
+	g.Expect(err).To(MatchError(ContainSubstring("does not contain key 'missing'")))
+}
+
+func TestResolveManifests(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cloud-credentials", Namespace: "default"},
+		Data:       map[string][]byte{"secret.yaml": []byte("kind: Secret\n")},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cni", Namespace: "default"},
+		Data: map[string]string{
+			"calico.yaml": "kind: List\n",
+			"huge.yaml":   strings.Repeat("x", corev1.MaxSecretSize),
+		},
+	}
+	reconciler := &KairosConfigReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret, configMap).Build(),
+		Scheme: scheme,
+	}
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Manifests: []bootstrapv1beta2.Manifest{
+				{Name: "ns", File: "ns.yaml", Content: "kind: Namespace\n"},
+				{Name: "cni", File: "calico.yaml", ContentFrom: &bootstrapv1beta2.ManifestSource{
+					ConfigMap: &bootstrapv1beta2.FileKeySelector{Name: "cni", Key: "calico.yaml"},
+				}},
+				{Name: "cloud", File: "secret.yaml", ContentFrom: &bootstrapv1beta2.ManifestSource{
+					Secret: &bootstrapv1beta2.FileKeySelector{Name: "cloud-credentials", Key: "secret.yaml"},
+				}},
+			},
+		},
+	}
+
+	manifests, err := reconciler.resolveManifests(context.Background(), kairosConfig)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(manifests).To(Equal([]bootstrapv1beta2.Manifest{
+		{Name: "ns", File: "ns.yaml", Content: "kind: Namespace\n"},
+		{Name: "cni", File: "calico.yaml", Content: "kind: List\n"},
+		{Name: "cloud", File: "secret.yaml", Content: "kind: Secret\n"},
+	}))
+	// The spec keeps the references
+	g.Expect(kairosConfig.Spec.Manifests[1].Content).To(BeEmpty())
+
+	kairosConfig.Spec.Manifests[1].ContentFrom.ConfigMap.Key = "huge.yaml"
+	_, err = reconciler.resolveManifests(context.Background(), kairosConfig)
+	g.Expect(err).To(MatchError(ContainSubstring("manifests exceed the 1048576 bytes a bootstrap data Secret can hold at cni/calico.yaml")))
+
+	kairosConfig.Spec.Manifests[1].ContentFrom.ConfigMap.Key = "missing"
+	_, err = reconciler.resolveManifests(context.Background(), kairosConfig)
+	g.Expect(err).To(MatchError(ContainSubstring("failed to get content of manifest cni/calico.yaml")))
+}
+
+func TestGenerateK3sCloudConfig_P2PWorker(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "p2p-token", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("test-network-token")},
+	}
+	reconciler := &KairosConfigReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(tokenSecret).Build(),
+		Scheme: scheme,
+	}
+
+	// P2P workers need neither a k3s token nor a server address
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role:              "worker",
+			Distribution:      "k3s",
+			KubernetesVersion: "v1.30.0+k3s1",
+			P2P: &bootstrapv1beta2.P2PConfig{
+				NetworkTokenSecretRef: &bootstrapv1beta2.WorkerTokenSecretReference{Name: "p2p-token"},
+				Auto:                  &bootstrapv1beta2.P2PAutoConfig{Enable: true},
+			},
+		},
+	}
+	machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: "default"}}
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+
+	cloudConfig, err := reconciler.generateK3sCloudConfig(context.Background(), log.Log, kairosConfig, machine, cluster, "worker", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cloudConfig).To(ContainSubstring(`network_token: "test-network-token"`))
+	// The node takes its role from the P2P network, so it gets no role label
+	g.Expect(cloudConfig).To(ContainSubstring("--kubelet-arg='node-labels=cluster.x-k8s.io/cluster-name=test-cluster,"))
+	g.Expect(cloudConfig).NotTo(ContainSubstring("cluster.x-k8s.io/role="))
+
+	kairosConfig.Spec.P2P.NetworkTokenSecretRef.Name = "missing"
+	_, err = reconciler.generateK3sCloudConfig(context.Background(), log.Log, kairosConfig, machine, cluster, "worker", "")
+	g.Expect(err).To(MatchError(ContainSubstring("failed to get p2p network token secret")))
+}
+
+func TestKubeletExtraArgs(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(kubeletExtraArgs(nil)).To(BeNil())
+	g.Expect(kubeletExtraArgs(map[string]string{
+		"feature-gates": "A=true,B=false",
+		"eviction-hard": "memory.available<100Mi",
+		"cgroup-driver": "systemd",
+	})).To(Equal([]string{
+		"cgroup-driver=systemd",
+		"eviction-hard=memory.available<100Mi",
+		"feature-gates=A=true,B=false",
+	}))
+}
+
+func TestK0sExtraInstallArgs(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(k0sExtraInstallArgs(bootstrapv1beta2.KairosConfigSpec{}, "control-plane")).To(BeNil())
+
+	spec := bootstrapv1beta2.KairosConfigSpec{
+		ExtraInstallArgs: []string{"--debug"},
+		K0sConfig:        &bootstrapv1beta2.K0sConfig{DynamicConfig: true},
+	}
+	g.Expect(k0sExtraInstallArgs(spec, "control-plane")).To(Equal([]string{"--debug", "--enable-dynamic-config"}))
+	// Workers have no cluster configuration
+	g.Expect(k0sExtraInstallArgs(spec, "worker")).To(Equal([]string{"--debug"}))
+}
+
+func TestK3sExtraInstallArgs(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(k3sExtraInstallArgs(bootstrapv1beta2.KairosConfigSpec{}, "control-plane")).To(BeNil())
+
+	spec := bootstrapv1beta2.KairosConfigSpec{
+		ExtraInstallArgs: []string{"--disable=traefik"},
+		Airgap:           &bootstrapv1beta2.AirgapConfig{DisableDefaultRegistry: true},
+		CertSANs:         []string{"api.example.com", "192.0.2.10"},
+		OIDC:             &bootstrapv1beta2.OIDCConfig{IssuerURL: "https://dex.example.com", ClientID: "kubernetes"},
+	}
+	g.Expect(k3sExtraInstallArgs(spec, "control-plane")).To(Equal([]string{
+		"--disable=traefik",
+		"--tls-san=api.example.com",
+		"--tls-san=192.0.2.10",
+		"--kube-apiserver-arg=oidc-client-id=kubernetes",
+		"--kube-apiserver-arg=oidc-issuer-url=https://dex.example.com",
+		"--disable-default-registry-endpoint",
+	}))
+	g.Expect(spec.ExtraInstallArgs).To(Equal([]string{"--disable=traefik"}))
+	// Agents serve no API server certificate
+	g.Expect(k3sExtraInstallArgs(spec, "worker")).To(Equal([]string{"--disable=traefik", "--disable-default-registry-endpoint"}))
+}
+
+func TestResolveRegistryAuths(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	pullSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: "default"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {
+			"https://index.docker.io/v1/": {"username": "user", "password": "pass"},
+			"registry.example.com": {"auth": "b2xkOm9sZA=="}
+		}}`)},
+	}
+	overrideSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "override", Namespace: "default"},
+		Data:       map[string][]byte{"config.json": []byte(`{"auths": {"registry.example.com": {"auth": "bmV3Om5ldw=="}}}`)},
+	}
+	reconciler := &KairosConfigReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(pullSecret, overrideSecret).Build(),
+		Scheme: scheme,
+	}
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			RegistryCredentials: []bootstrapv1beta2.RegistrySecretRef{
+				{Name: "pull-secret"},
+				{Name: "override", Key: "config.json"},
+			},
+		},
+	}
+
+	auths, err := reconciler.resolveRegistryAuths(context.Background(), kairosConfig)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(auths).To(Equal([]dockerconfig.Auth{
+		{Registry: "registry-1.docker.io", Auth: "dXNlcjpwYXNz"},
+		{Registry: "registry.example.com", Auth: "bmV3Om5ldw=="},
+	}))
+
+	kairosConfig.Spec.RegistryCredentials = []bootstrapv1beta2.RegistrySecretRef{{Name: "override"}}
+	_, err = reconciler.resolveRegistryAuths(context.Background(), kairosConfig)
+	g.Expect(err).To(MatchError(ContainSubstring("does not contain key '.dockerconfigjson'")))
+}
+
+func TestProxyConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(proxyConfig(nil)).To(BeNil())
+	g.Expect(proxyConfig(&bootstrapv1beta2.ProxyConfig{})).To(BeNil())
+	g.Expect(proxyConfig(&bootstrapv1beta2.ProxyConfig{HTTPSProxy: "http://proxy:3128", NoProxy: ".svc"})).To(Equal(&bootstrap.ProxyConfig{
+		HTTPSProxy: "http://proxy:3128",
+		NoProxy:    ".svc",
+	}))
+}
+
+func TestInstallConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(installConfig(bootstrapv1beta2.KairosConfigSpec{})).To(BeNil())
+	g.Expect(installConfig(bootstrapv1beta2.KairosConfigSpec{Install: &bootstrapv1beta2.InstallConfig{}})).To(Equal(&bootstrap.InstallConfig{
+		Auto:   true,
+		Device: "auto",
+		Reboot: true,
+	}))
+
+	reboot := false
+	spec := bootstrapv1beta2.KairosConfigSpec{
+		Install: &bootstrapv1beta2.InstallConfig{
+			Device:   "/dev/vda",
+			Reboot:   &reboot,
+			NoFormat: true,
+			Partitions: &bootstrapv1beta2.InstallPartitions{
+				OEM:        &bootstrapv1beta2.InstallPartition{},
+				State:      &bootstrapv1beta2.InstallPartition{Size: 8192},
+				Persistent: &bootstrapv1beta2.InstallPartition{Filesystem: "xfs"},
+			},
+			GrubOptions: map[string]string{"extra_cmdline": "console=ttyS0"},
+		},
+	}
+	g.Expect(installConfig(spec)).To(Equal(&bootstrap.InstallConfig{
+		Auto:     true,
+		Device:   "/dev/vda",
+		NoFormat: true,
+		Partitions: []bootstrap.InstallPartition{
+			{Name: "state", Size: 8192},
+			{Name: "persistent", Filesystem: "xfs"},
+		},
+		GrubOptions: map[string]string{"extra_cmdline": "console=ttyS0"},
+	}))
+
+	// Trusted Boot images have no grub environment and no state partition
+	spec.TrustedBoot = true
+	g.Expect(installConfig(spec)).To(Equal(&bootstrap.InstallConfig{
+		Auto:     true,
+		Device:   "/dev/vda",
+		NoFormat: true,
+		Partitions: []bootstrap.InstallPartition{
+			{Name: "persistent", Filesystem: "xfs"},
+		},
+	}))
+}
+
+func TestKcryptConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(kcryptConfig(nil)).To(BeNil())
+	// Keys sealed to the local TPM need no challenger configuration
+	g.Expect(kcryptConfig(&bootstrapv1beta2.EncryptionConfig{Partitions: []string{"COS_OEM"}})).To(BeNil())
+	g.Expect(kcryptConfig(&bootstrapv1beta2.EncryptionConfig{
+		ChallengerServer: "https://kms.example.com",
+		TPM:              &bootstrapv1beta2.EncryptionTPMConfig{CIndex: "0x1c00000", Device: "/dev/tpmrm0"},
+	})).To(Equal(&bootstrap.KcryptConfig{
+		ChallengerServer: "https://kms.example.com",
+		CIndex:           "0x1c00000",
+		TPMDevice:        "/dev/tpmrm0",
+	}))
+
+	// The encrypted partitions default to the persistent partition
+	spec := bootstrapv1beta2.KairosConfigSpec{
+		Install:    &bootstrapv1beta2.InstallConfig{},
+		Encryption: &bootstrapv1beta2.EncryptionConfig{},
+	}
+	g.Expect(installConfig(spec).EncryptedPartitions).To(Equal([]string{"COS_PERSISTENT"}))
+	spec.Encryption.Partitions = []string{"COS_PERSISTENT", "COS_OEM"}
+	g.Expect(installConfig(spec).EncryptedPartitions).To(Equal([]string{"COS_PERSISTENT", "COS_OEM"}))
+}
+
+func TestResolveSSHAuthorizedKeys(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	teamKeys := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-keys", Namespace: "default"},
+		Data: map[string][]byte{
+			"bob":   []byte("ssh-ed25519 BBBB bob@example\n"),
+			"alice": []byte("# alice\nssh-ed25519 AAAA alice@example\n\nssh-ed25519 INLINE inline@example\n"),
+		},
+	}
+	sharedKeys := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared-keys", Namespace: "infra"},
+		Data:       map[string][]byte{"authorized_keys": []byte("ssh-rsa CCCC ops@example")},
+	}
+	reconciler := &KairosConfigReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(teamKeys, sharedKeys).Build(),
+		Scheme: scheme,
+	}
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			SSHAuthorizedKeys: []string{"ssh-ed25519 INLINE inline@example"},
+			SSHKeySecretRefs: []corev1.SecretReference{
+				{Name: "team-keys"},
+				{Name: "shared-keys", Namespace: "infra"},
+			},
+		},
+	}
+
+	keys, err := reconciler.resolveSSHAuthorizedKeys(context.Background(), kairosConfig)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(keys).To(Equal([]string{
+		"ssh-ed25519 INLINE inline@example",
+		"ssh-ed25519 AAAA alice@example",
+		"ssh-ed25519 BBBB bob@example",
+		"ssh-rsa CCCC ops@example",
+	}))
+
+	kairosConfig.Spec.SSHKeySecretRefs = []corev1.SecretReference{{Name: "missing"}}
+	_, err = reconciler.resolveSSHAuthorizedKeys(context.Background(), kairosConfig)
+	g.Expect(err).To(MatchError(ContainSubstring("failed to get SSH key secret default/missing")))
+}
+
+func TestResolveUserPassword(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	passwordSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-password", Namespace: "default"},
+		Data: map[string][]byte{
+			"password": []byte("s3cr3t: #1\n"),
+			"hash":     []byte("$6$salt$hash"),
+		},
+	}
+	reconciler := &KairosConfigReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(passwordSecret).Build(),
+		Scheme: scheme,
+	}
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"},
+	}
+
+	password, err := reconciler.resolveUserPassword(context.Background(), kairosConfig)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(password).To(Equal("kairos"))
+
+	kairosConfig.Spec.PasswdHash = "$6$other$hash"
+	password, err = reconciler.resolveUserPassword(context.Background(), kairosConfig)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(password).To(Equal("$6$other$hash"))
+
+	// The Secret wins over the inline settings and a trailing newline is dropped
+	kairosConfig.Spec.UserPasswordSecretRef = &bootstrapv1beta2.UserPasswordSecretReference{Name: "node-password"}
+	password, err = reconciler.resolveUserPassword(context.Background(), kairosConfig)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(password).To(Equal("s3cr3t: #1"))
+
+	kairosConfig.Spec.UserPasswordSecretRef.Key = "hash"
+	password, err = reconciler.resolveUserPassword(context.Background(), kairosConfig)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(password).To(Equal("$6$salt$hash"))
+
+	kairosConfig.Spec.UserPasswordSecretRef.Key = "missing"
+	_, err = reconciler.resolveUserPassword(context.Background(), kairosConfig)
+	g.Expect(err).To(MatchError(ContainSubstring("does not contain key 'missing'")))
+
+	kairosConfig.Spec.UserPasswordSecretRef = &bootstrapv1beta2.UserPasswordSecretReference{Name: "missing"}
+	_, err = reconciler.resolveUserPassword(context.Background(), kairosConfig)
+	g.Expect(err).To(MatchError(ContainSubstring("failed to get user password secret default/missing")))
+}
@@ -19,18 +19,25 @@ package controlplane
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/url"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/blang/semver/v4"
 	"github.com/go-logr/logr"
 	"golang.org/x/crypto/ssh"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
@@ -38,13 +45,20 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/remote"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/version"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -52,17 +66,95 @@ import (
 
 	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
 	controlplanev1beta2 "github.com/kairos-io/kairos-capi/api/controlplane/v1beta2"
+	"github.com/kairos-io/kairos-capi/internal/bootstrap"
+	"github.com/kairos-io/kairos-capi/internal/cachefilter"
+	"github.com/kairos-io/kairos-capi/internal/drain"
+	"github.com/kairos-io/kairos-capi/internal/etcd"
 	"github.com/kairos-io/kairos-capi/internal/infrastructure"
+	"github.com/kairos-io/kairos-capi/internal/kubeconfigsecret"
+	"github.com/kairos-io/kairos-capi/internal/metrics"
+	"github.com/kairos-io/kairos-capi/internal/osupgrade"
+	"github.com/kairos-io/kairos-capi/internal/redact"
+	"github.com/kairos-io/kairos-capi/internal/shutdown"
+	"github.com/kairos-io/kairos-capi/internal/v1beta2conditions"
+	"github.com/kairos-io/kairos-capi/internal/workload"
 )
 
 // KairosControlPlaneReconciler reconciles a KairosControlPlane object
 type KairosControlPlaneReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// APIReader reads kubeconfig secrets that are not (yet) labeled and are
+	// therefore missing from the manager's label-filtered cache.
+	APIReader client.Reader
+	// ValidateOnReconcile runs the webhook validation inside the reconcile loop.
+	// It is set when the manager runs without admission webhooks.
+	ValidateOnReconcile bool
+	// Tracker hands out shared, cached clients for workload clusters so that
+	// reconciles reuse one connection per cluster. When nil, a client is built
+	// from the kubeconfig secret on every call. It is also used to watch
+	// workload cluster Nodes for the NodesReady condition.
+	Tracker *remote.ClusterCacheTracker
+	// ConnectionBackoff spaces out new connection attempts to workload
+	// clusters whose API server could not be reached through the Tracker.
+	// When nil, every reconcile tries to connect.
+	ConnectionBackoff *workload.ConnectionBackoff
+	// KairosOperatorManifest is applied to workload clusters that need an OS
+	// upgrade but do not run the kairos operator yet. When empty, the operator
+	// has to be installed by other means.
+	KairosOperatorManifest []byte
+	// SystemUpgradeControllerManifest is applied to workload clusters whose
+	// control plane sets spec.osUpgrade but that do not run the
+	// system-upgrade-controller yet. When empty, it has to be installed by
+	// other means.
+	SystemUpgradeControllerManifest []byte
+
+	controller controller.Controller
+	// recorder emits events on KairosControlPlanes and their Machines. It is
+	// set by SetupWithManager; no events are emitted when nil.
+	recorder record.EventRecorder
+	// drainClient is the workload cluster client nodes are drained with. When
+	// nil, an uncached client is built from the Tracker's REST config.
+	drainClient client.Client
+
+	// etcdExecutor runs k0s etcd commands on control plane nodes. When nil,
+	// they are run over SSH.
+	etcdExecutor etcd.Executor
 }
 
 const controlPlaneLBServiceSuffix = "control-plane-lb"
 
+// The Helm repository and chart installing Cilium for spec.cni cilium
+var (
+	ciliumRepository = bootstrapv1beta2.K0sHelmRepository{Name: "cilium", URL: "https://helm.cilium.io"}
+	ciliumChart      = bootstrapv1beta2.K0sHelmChart{Name: "cilium", ChartName: "cilium/cilium", Namespace: "kube-system"}
+)
+
+// controllerName labels the metrics of this controller, matching the name
+// controller-runtime reports its own metrics under.
+const controllerName = "kairoscontrolplane"
+
+// defaultMinHealthyPeriod is used when remediationStrategy.minHealthyPeriod is unset
+const defaultMinHealthyPeriod = time.Hour
+
+const (
+	// controllerJoinTokenTTL is the expiry of generated k0s controller join tokens
+	controllerJoinTokenTTL = 24 * time.Hour
+	// controllerJoinTokenRenewBefore is how long before expiry a token is replaced
+	controllerJoinTokenRenewBefore = 12 * time.Hour
+	// controllerJoinTokenExpiryAnnotation records the expiry of the token stored in the secret
+	controllerJoinTokenExpiryAnnotation = "controlplane.cluster.x-k8s.io/token-expiry"
+)
+
+const (
+	// kubeconfigRenewBefore is how long before its client certificate expires
+	// the kubeconfig secret is refreshed from a control plane node
+	kubeconfigRenewBefore = 30 * 24 * time.Hour
+	// kubeconfigRenewRetry is how often a refresh is retried while the nodes
+	// still serve a client certificate inside the renewal window
+	kubeconfigRenewRetry = time.Hour
+)
+
 //+kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=kairoscontrolplanes,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=kairoscontrolplanes/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=kairoscontrolplanes/finalizers,verbs=update
@@ -71,6 +163,7 @@ const controlPlaneLBServiceSuffix = "control-plane-lb"
 //+kubebuilder:rbac:groups=bootstrap.cluster.x-k8s.io,resources=kairosconfigs;kairosconfigtemplates,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=*,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=services;endpoints,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop
@@ -139,9 +232,76 @@ func (r *KairosControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, nil
 	}
 
+	// clusterctl move pauses the Cluster while it copies objects; only report
+	// the pause until it is lifted
+	if annotations.IsPaused(cluster, kcp) {
+		log.Info("Reconciliation is paused for this object")
+		conditions.MarkTrue(kcp, controlplanev1beta2.PausedCondition)
+		if err := r.updateKCPStatus(ctx, kcp); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update KCP status: %w", err)
+		}
+		return ctrl.Result{}, nil
+	}
+	conditions.MarkFalse(kcp, controlplanev1beta2.PausedCondition, controlplanev1beta2.NotPausedReason, clusterv1.ConditionSeverityInfo, "")
+
 	// Always update observedGeneration
 	kcp.Status.ObservedGeneration = kcp.Generation
 
+	// The selector backs the scale subresource, so publish it before any
+	// machine exists
+	kcp.Status.Selector = controlPlaneSelector(cluster).String()
+
+	// Without admission webhooks invalid specs reach the controller; report
+	// them instead of creating machines from them
+	if r.ValidateOnReconcile {
+		if err := kcp.Validate(); err != nil {
+			log.Info("KairosControlPlane spec is invalid", "error", err.Error())
+			conditions.MarkFalse(kcp, controlplanev1beta2.ValidSpecCondition, controlplanev1beta2.InvalidSpecReason, clusterv1.ConditionSeverityError, "%s", err.Error())
+			conditions.MarkFalse(kcp, clusterv1.ReadyCondition, controlplanev1beta2.InvalidSpecReason, clusterv1.ConditionSeverityError, "%s", err.Error())
+			kcp.Status.FailureReason = controlplanev1beta2.InvalidSpecReason
+			kcp.Status.FailureMessage = err.Error()
+			metrics.RecordReconcileError(controllerName, controlplanev1beta2.InvalidSpecReason)
+			if updateErr := r.updateKCPStatus(ctx, kcp); updateErr != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to update KCP status: %w", updateErr)
+			}
+			return ctrl.Result{}, nil
+		}
+		conditions.MarkTrue(kcp, controlplanev1beta2.ValidSpecCondition)
+	}
+
+	// Replace unhealthy control plane machines; reconcileMachines creates the replacements
+	remediationResult, err := r.reconcileRemediation(ctx, log, kcp, cluster)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Hand the init path to another machine when its holder failed before the
+	// control plane was initialized
+	if err := r.reconcileInitLock(ctx, log, kcp, cluster); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// The cluster certificates must exist before the first machine bootstraps
+	if err := r.reconcileCertificates(ctx, kcp, cluster); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Drain the nodes of deleted control plane machines, then remove their
+	// etcd members
+	etcdResult := util.LowestNonZeroResult(r.reconcileDrain(ctx, log, kcp, cluster), r.reconcileEtcdMembers(ctx, log, kcp, cluster))
+
+	// Machines need an externally managed endpoint in their API server
+	// certificate, so none are created before it is known
+	if kcp.Spec.ExternalManagedEndpoint && !cluster.Spec.ControlPlaneEndpoint.IsValid() {
+		log.Info("Waiting for the externally managed control plane endpoint to be set on the Cluster")
+		conditions.MarkFalse(kcp, clusterv1.ReadyCondition, controlplanev1beta2.WaitingForControlPlaneEndpointReason, clusterv1.ConditionSeverityInfo,
+			"Waiting for spec.controlPlaneEndpoint to be set on Cluster %s", cluster.Name)
+		if err := r.updateKCPStatus(ctx, kcp); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update KCP status: %w", err)
+		}
+		return etcdResult, nil
+	}
+
 	// Reconcile control plane machines
 	if err := r.reconcileMachines(ctx, log, kcp, cluster); err != nil {
 		// Use "%s" as format string and pass error as argument to satisfy linter
@@ -149,11 +309,12 @@ func (r *KairosControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 		conditions.MarkFalse(kcp, controlplanev1beta2.AvailableCondition, controlplanev1beta2.ControlPlaneInitializationFailedReason, clusterv1.ConditionSeverityWarning, "%s", err.Error())
 		kcp.Status.FailureReason = controlplanev1beta2.ControlPlaneInitializationFailedReason
 		kcp.Status.FailureMessage = err.Error()
+		metrics.RecordReconcileError(controllerName, controlplanev1beta2.ControlPlaneInitializationFailedReason)
 		// Use Status().Update() to ensure all status fields are included
-		if updateErr := r.Status().Update(ctx, kcp); updateErr != nil {
+		if updateErr := r.updateKCPStatus(ctx, kcp); updateErr != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to update KCP status: %w", updateErr)
 		}
-		return ctrl.Result{}, nil
+		return etcdResult, nil
 	}
 
 	// Track previous initialized state to detect transitions
@@ -164,8 +325,9 @@ func (r *KairosControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, err
 	}
 
-	// Ensure the control-plane LoadBalancer Service exists for KubeVirt clusters.
-	if isKubevirtControlPlane(kcp) {
+	// Ensure the control-plane LoadBalancer Service exists for KubeVirt clusters
+	// whose endpoint is not managed externally.
+	if isKubevirtControlPlane(kcp) && !kcp.Spec.ExternalManagedEndpoint {
 		if err := r.reconcileControlPlaneLB(ctx, log, kcp, cluster); err != nil {
 			log.Error(err, "Failed to reconcile control plane load balancer service")
 		}
@@ -184,14 +346,14 @@ func (r *KairosControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 		// Fallback: Check if infrastructure is ready even without NodeRef
 		// This is useful when k0s is running but node hasn't registered yet
 		// But first check if kubeconfig already exists to avoid unnecessary work
-		secretName := fmt.Sprintf("%s-kubeconfig", cluster.Name)
+		secretName := kubeconfigsecret.Name(cluster.Name)
 		secretKey := types.NamespacedName{
 			Name:      secretName,
 			Namespace: cluster.Namespace,
 		}
 		existingSecret := &corev1.Secret{}
-		if err := r.Get(ctx, secretKey, existingSecret); err == nil {
-			if kubeconfig, ok := existingSecret.Data["value"]; ok && len(kubeconfig) > 0 {
+		if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, existingSecret); err == nil {
+			if kubeconfig, ok := existingSecret.Data[kubeconfigsecret.DataKey]; ok && len(kubeconfig) > 0 {
 				// Kubeconfig already exists, skip retrieval
 				log.V(4).Info("Kubeconfig already exists, skipping retrieval",
 					"readyReplicas", kcp.Status.ReadyReplicas,
@@ -276,7 +438,7 @@ func (r *KairosControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 					log.Error(err, "Failed to update status before requeue")
 				}
 				// Use Status().Update() to ensure all status fields are included
-				if updateErr := r.Status().Update(ctx, kcp); updateErr != nil {
+				if updateErr := r.updateKCPStatus(ctx, kcp); updateErr != nil {
 					if apierrors.IsConflict(updateErr) {
 						log.V(4).Info("Conflict updating KCP status before requeue, will retry", "error", updateErr)
 						return ctrl.Result{Requeue: true}, nil
@@ -300,6 +462,25 @@ func (r *KairosControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 		log.Error(err, "Failed to ensure providerID on workload nodes")
 	}
 
+	// Reflect control plane node join/Ready state from the workload cluster
+	result := util.LowestNonZeroResult(remediationResult, r.reconcileNodesReadyCondition(ctx, log, kcp, cluster))
+	result = util.LowestNonZeroResult(result, etcdResult)
+
+	// Roll spec.osImage out to the control plane nodes through the kairos operator
+	result = util.LowestNonZeroResult(result, r.reconcileOSUpgrade(ctx, log, kcp, cluster))
+
+	// Roll spec.osUpgrade out to the control plane nodes through a system-upgrade-controller Plan
+	result = util.LowestNonZeroResult(result, r.reconcileOSUpgradePlan(ctx, log, kcp, cluster))
+
+	// Upgrade machines to spec.version in place with upgradeStrategy InPlace
+	result = util.LowestNonZeroResult(result, r.reconcileInPlaceUpgrade(ctx, log, kcp, cluster))
+
+	// Keep a k0s controller join token for additional control plane machines
+	result = util.LowestNonZeroResult(result, r.reconcileControllerJoinToken(ctx, log, kcp, cluster))
+
+	// Come back when the kubeconfig client certificate is due for renewal
+	result = util.LowestNonZeroResult(result, r.kubeconfigRenewalResult(ctx, cluster))
+
 	// Update Cluster status
 	if err := r.updateClusterStatus(ctx, log, kcp, cluster); err != nil {
 		log.Error(err, "Failed to update cluster status")
@@ -330,7 +511,7 @@ func (r *KairosControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 	// This is important because Patch() with omitempty tags may omit zero values,
 	// causing fields like ReadyReplicas to appear as null instead of 0
 	// Status().Update() sends the complete status object, ensuring all fields are present
-	if err := r.Status().Update(ctx, kcp); err != nil {
+	if err := r.updateKCPStatus(ctx, kcp); err != nil {
 		if apierrors.IsConflict(err) {
 			// Conflict means the object was modified, requeue to retry
 			log.V(4).Info("Conflict updating KCP status, will requeue", "error", err)
@@ -358,225 +539,1205 @@ func (r *KairosControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 		}
 	}
 
-	return ctrl.Result{}, nil
+	return result, nil
 }
 
-// findClusterForControlPlane searches for a Cluster that references this KairosControlPlane
-func (r *KairosControlPlaneReconciler) findClusterForControlPlane(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane) (*clusterv1.Cluster, error) {
-	// List all Clusters in the same namespace
-	clusters := &clusterv1.ClusterList{}
-	if err := r.List(ctx, clusters, client.InNamespace(kcp.Namespace)); err != nil {
-		return nil, fmt.Errorf("failed to list clusters: %w", err)
+// reconcileNodesReadyCondition sets NodesReady from the workload cluster Nodes
+// backing the control plane machines and makes sure Node changes trigger a
+// reconcile. Workload cluster errors are reported in the condition and retried
+// later instead of failing the reconcile.
+func (r *KairosControlPlaneReconciler) reconcileNodesReadyCondition(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) ctrl.Result {
+	if r.Tracker == nil {
+		return ctrl.Result{}
 	}
 
-	// Find the Cluster that references this KairosControlPlane
-	for i := range clusters.Items {
-		cluster := &clusters.Items[i]
-		if cluster.Spec.ControlPlaneRef != nil &&
-			cluster.Spec.ControlPlaneRef.Kind == "KairosControlPlane" &&
-			cluster.Spec.ControlPlaneRef.Name == kcp.Name {
-			// Check namespace - it might be empty (defaults to cluster namespace)
-			refNamespace := cluster.Spec.ControlPlaneRef.Namespace
-			if refNamespace == "" || refNamespace == kcp.Namespace {
-				// Check API version/group matches
-				// In v1beta2, ControlPlaneRef uses apiGroup in YAML, but Go type uses APIVersion
-				// When apiGroup is set, APIVersion may be empty or contain the full version string
-				refAPIVersion := cluster.Spec.ControlPlaneRef.APIVersion
-				expectedGroup := controlplanev1beta2.GroupVersion.Group
-				expectedVersion := controlplanev1beta2.GroupVersion.String()
-
-				// Match if:
-				// 1. APIVersion is empty (v1beta2 using apiGroup - we trust the kind match)
-				// 2. APIVersion matches expected version (v1beta1 style or v1beta2 with full version)
-				// 3. APIVersion contains the expected group (handles partial matches)
-				if refAPIVersion == "" {
-					// Empty APIVersion means apiGroup is being used - trust the kind match
-					log.Info("Found Cluster with matching ControlPlaneRef (apiGroup)", "cluster", cluster.Name, "kind", cluster.Spec.ControlPlaneRef.Kind)
-					return cluster, nil
-				}
-				if refAPIVersion == expectedVersion {
-					return cluster, nil
-				}
-				if len(refAPIVersion) > 0 && len(expectedGroup) > 0 && len(refAPIVersion) >= len(expectedGroup) && refAPIVersion[:len(expectedGroup)] == expectedGroup {
-					return cluster, nil
-				}
-				log.Info("Cluster ControlPlaneRef APIVersion doesn't match", "cluster", cluster.Name, "refAPIVersion", refAPIVersion, "expectedVersion", expectedVersion, "expectedGroup", expectedGroup)
-			}
-		}
+	if !kcp.Status.Initialized {
+		conditions.MarkFalse(kcp, controlplanev1beta2.NodesReadyCondition, controlplanev1beta2.WaitingForMachinesReason, clusterv1.ConditionSeverityInfo, "Waiting for control plane initialization")
+		return ctrl.Result{}
 	}
 
-	return nil, nil
-}
+	clusterKey := util.ObjectKey(cluster)
+	if err := r.ConnectionBackoff.Watch(ctx, r.Tracker, remote.WatchInput{
+		Name:         "kairoscontrolplane-watchNodes",
+		Cluster:      clusterKey,
+		Watcher:      r.controller,
+		Kind:         &corev1.Node{},
+		EventHandler: handler.EnqueueRequestsFromMapFunc(r.nodeToKairosControlPlane(clusterKey)),
+		Predicates:   []predicate.Predicate{workload.NodeReadinessChanged()},
+	}); err != nil {
+		return r.markWorkloadClusterUnreachable(log, kcp, err)
+	}
 
-func (r *KairosControlPlaneReconciler) reconcileMachines(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) error {
-	// Get desired replica count
-	desiredReplicas := int32(1)
-	if kcp.Spec.Replicas != nil {
-		desiredReplicas = *kcp.Spec.Replicas
+	workloadClient, err := r.ConnectionBackoff.GetClient(ctx, r.Tracker, clusterKey)
+	if err != nil {
+		return r.markWorkloadClusterUnreachable(log, kcp, err)
 	}
 
-	// List existing control plane machines
 	machines, err := r.getControlPlaneMachines(ctx, kcp, cluster)
 	if err != nil {
-		return fmt.Errorf("failed to list control plane machines: %w", err)
+		return r.markWorkloadClusterUnreachable(log, kcp, err)
 	}
 
-	// Sort machines by creation timestamp (oldest first) for stable operations
-	sort.Slice(machines, func(i, j int) bool {
-		return machines[i].CreationTimestamp.Before(&machines[j].CreationTimestamp)
-	})
+	previous := map[string]*controlplanev1beta2.MachineNodeStatus{}
+	for i := range kcp.Status.MachineNodes {
+		previous[kcp.Status.MachineNodes[i].Machine] = &kcp.Status.MachineNodes[i]
+	}
+	machineNodes := make([]controlplanev1beta2.MachineNodeStatus, 0, len(machines))
+	var waiting, notReady, noProviderID []string
+	for _, machine := range machines {
+		node, err := workload.NodeForMachine(ctx, workloadClient, machine)
+		if err != nil {
+			return r.markWorkloadClusterUnreachable(log, kcp, err)
+		}
+		status := machineNodeStatus(machine, node, previous[machine.Name])
+		machineNodes = append(machineNodes, status)
+		switch {
+		case node == nil:
+			waiting = append(waiting, machine.Name)
+		case !workload.IsNodeReady(node):
+			notReady = append(notReady, machine.Name)
+		case !meta.IsStatusConditionTrue(status.Conditions, controlplanev1beta2.NodeProviderIDSetCondition):
+			noProviderID = append(noProviderID, machine.Name)
+		}
+	}
+	sort.Slice(machineNodes, func(i, j int) bool { return machineNodes[i].Machine < machineNodes[j].Machine })
+	kcp.Status.MachineNodes = machineNodes
+	sort.Strings(waiting)
+	sort.Strings(notReady)
+	sort.Strings(noProviderID)
+
+	ready := len(machines) - len(waiting) - len(notReady)
+	switch {
+	case len(machines) == 0:
+		conditions.MarkFalse(kcp, controlplanev1beta2.NodesReadyCondition, controlplanev1beta2.WaitingForMachinesReason, clusterv1.ConditionSeverityInfo, "Waiting for control plane machines")
+	case len(notReady) > 0:
+		conditions.MarkFalse(kcp, controlplanev1beta2.NodesReadyCondition, controlplanev1beta2.NodesNotReadyReason, clusterv1.ConditionSeverityWarning,
+			"%d of %d control plane nodes ready; not ready: %s", ready, len(machines), strings.Join(notReady, ", "))
+	case len(noProviderID) > 0:
+		conditions.MarkFalse(kcp, controlplanev1beta2.NodesReadyCondition, controlplanev1beta2.ProviderIDMissingReason, clusterv1.ConditionSeverityWarning,
+			"control plane nodes without the providerID of their machine: %s", strings.Join(noProviderID, ", "))
+	case len(waiting) > 0:
+		conditions.MarkFalse(kcp, controlplanev1beta2.NodesReadyCondition, controlplanev1beta2.WaitingForNodesReason, clusterv1.ConditionSeverityInfo,
+			"%d of %d control plane nodes ready; waiting to join: %s", ready, len(machines), strings.Join(waiting, ", "))
+	default:
+		conditions.MarkTrue(kcp, controlplanev1beta2.NodesReadyCondition)
+	}
+	return ctrl.Result{}
+}
 
-	currentReplicas := int32(len(machines))
+// machineNodeStatus reports whether node, the workload cluster Node found for
+// machine or nil, registered, carries the machine's providerID and is Ready.
+// The transition times of unchanged conditions are kept from previous.
+func machineNodeStatus(machine *clusterv1.Machine, node *corev1.Node, previous *controlplanev1beta2.MachineNodeStatus) controlplanev1beta2.MachineNodeStatus {
+	status := controlplanev1beta2.MachineNodeStatus{Machine: machine.Name}
+	if previous != nil {
+		status.Conditions = slices.Clone(previous.Conditions)
+	}
+	set := func(conditionType string, ok bool, reason, message string) {
+		cond := metav1.Condition{Type: conditionType, Status: metav1.ConditionFalse, Reason: reason, Message: message}
+		if ok {
+			cond.Status = metav1.ConditionTrue
+		}
+		meta.SetStatusCondition(&status.Conditions, cond)
+	}
 
-	log.Info("Reconciling control plane machines", "desired", desiredReplicas, "current", currentReplicas)
+	if node == nil {
+		set(controlplanev1beta2.NodeRegisteredCondition, false, controlplanev1beta2.NodeNotRegisteredReason, "Waiting for the node to join the workload cluster")
+		set(controlplanev1beta2.NodeProviderIDSetCondition, false, controlplanev1beta2.NodeNotRegisteredReason, "")
+		set(controlplanev1beta2.NodeReadyCondition, false, controlplanev1beta2.NodeNotRegisteredReason, "")
+		return status
+	}
+	status.NodeName = node.Name
+	status.ProviderID = node.Spec.ProviderID
+	set(controlplanev1beta2.NodeRegisteredCondition, true, controlplanev1beta2.NodeRegisteredReason, "")
 
-	maxSurge := int32(1)
-	if kcp.Spec.RolloutStrategy != nil && kcp.Spec.RolloutStrategy.RollingUpdate != nil && kcp.Spec.RolloutStrategy.RollingUpdate.MaxSurge != nil {
-		maxSurge = *kcp.Spec.RolloutStrategy.RollingUpdate.MaxSurge
+	switch {
+	case node.Spec.ProviderID == "":
+		set(controlplanev1beta2.NodeProviderIDSetCondition, false, controlplanev1beta2.ProviderIDMissingReason, "The node has no providerID")
+	case machine.Spec.ProviderID != nil && *machine.Spec.ProviderID != node.Spec.ProviderID:
+		set(controlplanev1beta2.NodeProviderIDSetCondition, false, controlplanev1beta2.ProviderIDMismatchReason,
+			fmt.Sprintf("The node has providerID %s, the machine %s", node.Spec.ProviderID, *machine.Spec.ProviderID))
+	default:
+		set(controlplanev1beta2.NodeProviderIDSetCondition, true, controlplanev1beta2.ProviderIDSetReason, "")
 	}
 
-	outdatedMachines := make([]*clusterv1.Machine, 0)
-	updatedReadyReplicas := int32(0)
-	for _, machine := range machines {
-		if r.machineMatchesVersion(machine, kcp.Spec.Version) {
-			if machine.Status.NodeRef != nil {
-				updatedReadyReplicas++
-			}
-			continue
-		}
-		outdatedMachines = append(outdatedMachines, machine)
+	if workload.IsNodeReady(node) {
+		set(controlplanev1beta2.NodeReadyCondition, true, controlplanev1beta2.NodeReadyReason, "")
+	} else {
+		set(controlplanev1beta2.NodeReadyCondition, false, controlplanev1beta2.NodeNotReadyReason, "The node is not Ready")
 	}
+	return status
+}
 
-	// Rolling update behavior when machines are outdated
-	if len(outdatedMachines) > 0 {
-		if currentReplicas < desiredReplicas+maxSurge {
-			nextIndex := r.nextMachineIndex(machines, kcp.Name)
-			if err := r.createControlPlaneMachine(ctx, log, kcp, cluster, nextIndex); err != nil {
-				return fmt.Errorf("failed to create control plane machine during rollout: %w", err)
-			}
-			return nil
+// markWorkloadClusterUnreachable records a workload cluster access error on the
+// NodesReady condition and schedules a retry.
+func (r *KairosControlPlaneReconciler) markWorkloadClusterUnreachable(log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, err error) ctrl.Result {
+	if errors.Is(err, remote.ErrClusterLocked) {
+		// Another worker is connecting to this cluster; try again shortly
+		return ctrl.Result{RequeueAfter: 5 * time.Second}
+	}
+	log.V(4).Info("Workload cluster not reachable, will retry NodesReady condition", "error", err.Error())
+	conditions.MarkUnknown(kcp, controlplanev1beta2.NodesReadyCondition, controlplanev1beta2.WorkloadClusterUnreachableReason, "%s", err.Error())
+	return ctrl.Result{RequeueAfter: 30 * time.Second}
+}
+
+// reconcileOSUpgrade upgrades the control plane nodes to spec.osImage in place
+// by creating a NodeOpUpgrade for the kairos operator in the workload cluster,
+// installing the operator first when a manifest is configured. Progress is
+// reported on the OSImageUpToDate condition; errors are retried later instead
+// of failing the reconcile.
+func (r *KairosControlPlaneReconciler) reconcileOSUpgrade(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) ctrl.Result {
+	if kcp.Spec.OSImage == "" {
+		conditions.Delete(kcp, controlplanev1beta2.OSImageUpToDateCondition)
+		return ctrl.Result{}
+	}
+
+	image := osupgrade.ImageRef(kcp.Spec.OSImage, kcp.Spec.OSVersion)
+	if kcp.Status.OSImage == image {
+		conditions.MarkTrue(kcp, controlplanev1beta2.OSImageUpToDateCondition)
+		return ctrl.Result{}
+	}
+	if r.Tracker == nil || !kcp.Status.Initialized {
+		conditions.MarkFalse(kcp, controlplanev1beta2.OSImageUpToDateCondition, controlplanev1beta2.WaitingForMachinesReason, clusterv1.ConditionSeverityInfo, "Waiting for control plane initialization")
+		return ctrl.Result{}
+	}
+
+	workloadClient, err := r.ConnectionBackoff.GetClient(ctx, r.Tracker, util.ObjectKey(cluster))
+	if err != nil {
+		return r.markOSUpgradeError(log, kcp, err)
+	}
+
+	installed, err := osupgrade.OperatorInstalled(workloadClient.RESTMapper())
+	if err != nil {
+		return r.markOSUpgradeError(log, kcp, err)
+	}
+	if !installed {
+		if len(r.KairosOperatorManifest) == 0 {
+			conditions.MarkFalse(kcp, controlplanev1beta2.OSImageUpToDateCondition, controlplanev1beta2.KairosOperatorNotInstalledReason, clusterv1.ConditionSeverityWarning,
+				"kairos operator is not installed in the workload cluster; install it or start the manager with --kairos-operator-manifest")
+			return ctrl.Result{RequeueAfter: time.Minute}
 		}
+		log.Info("Installing kairos operator in workload cluster", "cluster", cluster.Name)
+		if err := osupgrade.ApplyManifest(ctx, workloadClient, r.KairosOperatorManifest); err != nil {
+			return r.markOSUpgradeError(log, kcp, fmt.Errorf("failed to install kairos operator: %w", err))
+		}
+		conditions.MarkFalse(kcp, controlplanev1beta2.OSImageUpToDateCondition, controlplanev1beta2.InstallingKairosOperatorReason, clusterv1.ConditionSeverityInfo, "Installing kairos operator")
+		return ctrl.Result{RequeueAfter: 15 * time.Second}
+	}
 
-		// If we are above desired replicas and have enough updated/ready replicas, delete one outdated machine
-		if currentReplicas > desiredReplicas && updatedReadyReplicas >= desiredReplicas {
-			target := outdatedMachines[0]
-			log.Info("Deleting outdated control plane machine", "machine", target.Name)
-			if err := r.Delete(ctx, target); err != nil {
-				return fmt.Errorf("failed to delete outdated control plane machine: %w", err)
-			}
-			return nil
+	desired := osupgrade.NewNodeOpUpgrade(kcp.Name, image)
+	upgrade := &unstructured.Unstructured{}
+	upgrade.SetGroupVersionKind(osupgrade.NodeOpUpgradeGVK)
+	if err := workloadClient.Get(ctx, client.ObjectKeyFromObject(desired), upgrade); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return r.markOSUpgradeError(log, kcp, err)
+		}
+		log.Info("Starting OS upgrade of control plane nodes", "image", image, "nodeOpUpgrade", desired.GetName())
+		if err := workloadClient.Create(ctx, desired); err != nil {
+			return r.markOSUpgradeError(log, kcp, fmt.Errorf("failed to create NodeOpUpgrade: %w", err))
 		}
+		r.eventf(kcp, nil, corev1.EventTypeNormal, controlplanev1beta2.UpgradeStartedEvent, "Starting OS upgrade of control plane nodes to %s", image)
+		conditions.MarkFalse(kcp, controlplanev1beta2.OSImageUpToDateCondition, controlplanev1beta2.OSUpgradeInProgressReason, clusterv1.ConditionSeverityInfo, "Upgrading control plane nodes to %s", image)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}
+	}
+
+	phase, message := osupgrade.GetPhase(upgrade)
+	switch phase {
+	case osupgrade.PhaseCompleted:
+		log.Info("OS upgrade of control plane nodes completed", "image", image)
+		kcp.Status.OSImage = image
+		conditions.MarkTrue(kcp, controlplanev1beta2.OSImageUpToDateCondition)
+		r.deleteStaleUpgrades(ctx, log, workloadClient, osupgrade.NodeOpUpgradeGVK, osupgrade.Namespace, kcp, upgrade.GetName())
+		return ctrl.Result{}
+	case osupgrade.PhaseFailed:
+		// The operator stops on failure; a new osImage/osVersion starts a new run
+		conditions.MarkFalse(kcp, controlplanev1beta2.OSImageUpToDateCondition, controlplanev1beta2.OSUpgradeFailedReason, clusterv1.ConditionSeverityError,
+			"Upgrade to %s failed: %s", image, message)
+		return ctrl.Result{}
+	default:
+		conditions.MarkFalse(kcp, controlplanev1beta2.OSImageUpToDateCondition, controlplanev1beta2.OSUpgradeInProgressReason, clusterv1.ConditionSeverityInfo, "Upgrading control plane nodes to %s", image)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}
 	}
+}
 
-	// Create machines if needed
-	if currentReplicas < desiredReplicas {
-		toCreate := desiredReplicas - currentReplicas
-		if toCreate > 0 {
-			nextIndex := r.nextMachineIndex(machines, kcp.Name)
-			if err := r.createControlPlaneMachine(ctx, log, kcp, cluster, nextIndex); err != nil {
-				return fmt.Errorf("failed to create control plane machine: %w", err)
-			}
-			// Only create one per reconcile to avoid over-scaling
-			return nil
+// deleteStaleUpgrades removes the NodeOpUpgrades or Plans, depending on gvk,
+// of earlier images once the current one has completed.
+func (r *KairosControlPlaneReconciler) deleteStaleUpgrades(ctx context.Context, log logr.Logger, workloadClient client.Client, gvk schema.GroupVersionKind, namespace string, kcp *controlplanev1beta2.KairosControlPlane, current string) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err := workloadClient.List(ctx, list, client.InNamespace(namespace), client.MatchingLabels{osupgrade.ControlPlaneLabel: kcp.Name}); err != nil {
+		log.V(4).Info("Failed to list stale OS upgrades for cleanup", "kind", gvk.Kind, "error", err.Error())
+		return
+	}
+	for i := range list.Items {
+		if list.Items[i].GetName() == current {
+			continue
+		}
+		if err := workloadClient.Delete(ctx, &list.Items[i]); client.IgnoreNotFound(err) != nil {
+			log.V(4).Info("Failed to delete stale OS upgrade", "kind", gvk.Kind, "name", list.Items[i].GetName(), "error", err.Error())
 		}
 	}
+}
 
-	// Delete machines if needed (scale down)
-	if currentReplicas > desiredReplicas {
-		target := r.selectMachineForDeletion(machines, outdatedMachines)
-		if target != nil {
-			log.Info("Scaling down control plane machine", "machine", target.Name)
-			if err := r.Delete(ctx, target); err != nil {
-				return fmt.Errorf("failed to delete control plane machine: %w", err)
-			}
+// reconcileOSUpgradePlan upgrades the control plane nodes to spec.osUpgrade in
+// place with a system-upgrade-controller Plan in the workload cluster,
+// installing the controller first when a manifest is configured. Progress is
+// reported in status.osUpgrade and on the OSUpgradeCompleted condition;
+// errors are retried later instead of failing the reconcile.
+func (r *KairosControlPlaneReconciler) reconcileOSUpgradePlan(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) ctrl.Result {
+	upgrade := kcp.Spec.OSUpgrade
+	if upgrade == nil {
+		conditions.Delete(kcp, controlplanev1beta2.OSUpgradeCompletedCondition)
+		kcp.Status.OSUpgrade = nil
+		return ctrl.Result{}
+	}
+	if r.Tracker == nil || !kcp.Status.Initialized {
+		conditions.MarkFalse(kcp, controlplanev1beta2.OSUpgradeCompletedCondition, controlplanev1beta2.WaitingForMachinesReason, clusterv1.ConditionSeverityInfo, "Waiting for control plane initialization")
+		return ctrl.Result{}
+	}
+
+	workloadClient, err := r.ConnectionBackoff.GetClient(ctx, r.Tracker, util.ObjectKey(cluster))
+	if err != nil {
+		return r.markOSUpgradePlanError(log, kcp, err)
+	}
+
+	if result, err := r.ensureSystemUpgradeController(ctx, log, workloadClient, kcp, cluster, controlplanev1beta2.OSUpgradeCompletedCondition); err != nil {
+		return r.markOSUpgradePlanError(log, kcp, err)
+	} else if !result.IsZero() {
+		return result
+	}
+
+	image := osupgrade.ImageRef(upgrade.Image, upgrade.Version)
+	desired := osupgrade.NewControlPlanePlan(kcp.Name, upgrade.Image, upgrade.Version, upgrade.Concurrency)
+	plan := &unstructured.Unstructured{}
+	plan.SetGroupVersionKind(osupgrade.PlanGVK)
+	if err := workloadClient.Get(ctx, client.ObjectKeyFromObject(desired), plan); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return r.markOSUpgradePlanError(log, kcp, err)
+		}
+		log.Info("Starting OS upgrade of control plane nodes", "image", image, "plan", desired.GetName())
+		if err := workloadClient.Create(ctx, desired); err != nil {
+			return r.markOSUpgradePlanError(log, kcp, fmt.Errorf("failed to create Plan: %w", err))
 		}
+		r.eventf(kcp, nil, corev1.EventTypeNormal, controlplanev1beta2.UpgradeStartedEvent, "Starting OS upgrade of control plane nodes to %s", image)
+		kcp.Status.OSUpgrade = &controlplanev1beta2.OSUpgradeStatus{Image: image, Plan: desired.GetName()}
+		conditions.MarkFalse(kcp, controlplanev1beta2.OSUpgradeCompletedCondition, controlplanev1beta2.OSUpgradeInProgressReason, clusterv1.ConditionSeverityInfo, "Upgrading control plane nodes to %s", image)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}
 	}
 
-	return nil
+	nodes, updated, err := osupgrade.PlanProgress(ctx, workloadClient, plan)
+	if err != nil {
+		return r.markOSUpgradePlanError(log, kcp, err)
+	}
+	kcp.Status.OSUpgrade = &controlplanev1beta2.OSUpgradeStatus{
+		Image:        image,
+		Plan:         plan.GetName(),
+		Nodes:        nodes,
+		UpdatedNodes: updated,
+		Applying:     osupgrade.PlanApplying(plan),
+	}
+	if nodes == 0 || updated < nodes {
+		conditions.MarkFalse(kcp, controlplanev1beta2.OSUpgradeCompletedCondition, controlplanev1beta2.OSUpgradeInProgressReason, clusterv1.ConditionSeverityInfo,
+			"Upgraded %d of %d control plane nodes to %s", updated, nodes, image)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}
+	}
+
+	if !conditions.IsTrue(kcp, controlplanev1beta2.OSUpgradeCompletedCondition) {
+		log.Info("OS upgrade of control plane nodes completed", "image", image, "plan", plan.GetName())
+		r.deleteStaleUpgrades(ctx, log, workloadClient, osupgrade.PlanGVK, osupgrade.PlanNamespace, kcp, plan.GetName())
+	}
+	conditions.MarkTrue(kcp, controlplanev1beta2.OSUpgradeCompletedCondition)
+	return ctrl.Result{}
 }
 
-func (r *KairosControlPlaneReconciler) createControlPlaneMachine(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster, index int32) error {
-	machineName := fmt.Sprintf("%s-%d", kcp.Name, index)
+// ensureSystemUpgradeController checks that the system-upgrade-controller
+// runs in the workload cluster and installs it when a manifest is configured.
+// While it is missing, the reason is recorded on conditionType and a non-zero
+// result is returned.
+func (r *KairosControlPlaneReconciler) ensureSystemUpgradeController(ctx context.Context, log logr.Logger, workloadClient client.Client, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster, conditionType clusterv1.ConditionType) (ctrl.Result, error) {
+	installed, err := osupgrade.SystemUpgradeControllerInstalled(workloadClient.RESTMapper())
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if installed {
+		return ctrl.Result{}, nil
+	}
+	if len(r.SystemUpgradeControllerManifest) == 0 {
+		conditions.MarkFalse(kcp, conditionType, controlplanev1beta2.SystemUpgradeControllerNotInstalledReason, clusterv1.ConditionSeverityWarning,
+			"system-upgrade-controller is not installed in the workload cluster; install it or start the manager with --system-upgrade-controller-manifest")
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
+	}
+	log.Info("Installing system-upgrade-controller in workload cluster", "cluster", cluster.Name)
+	if err := osupgrade.ApplyManifest(ctx, workloadClient, r.SystemUpgradeControllerManifest); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to install system-upgrade-controller: %w", err)
+	}
+	conditions.MarkFalse(kcp, conditionType, controlplanev1beta2.InstallingSystemUpgradeControllerReason, clusterv1.ConditionSeverityInfo, "Installing system-upgrade-controller")
+	return ctrl.Result{RequeueAfter: 15 * time.Second}, nil
+}
 
-	// Create KairosConfig
-	distribution := kcp.Spec.Distribution
-	if distribution == "" {
-		distribution = "k0s"
+// reconcileInPlaceUpgrade upgrades the control plane machines whose only
+// outdated field is spec.version in place with upgradeStrategy InPlace. A
+// system-upgrade-controller Plan moves their nodes to the inPlaceUpgrade
+// image built for spec.version, and each Machine gets the new version and
+// spec hash once its node carries the Plan hash. Progress is reported on the
+// MachinesSpecUpToDate condition; errors are retried later.
+func (r *KairosControlPlaneReconciler) reconcileInPlaceUpgrade(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) ctrl.Result {
+	if kcp.Spec.UpgradeStrategy != controlplanev1beta2.UpgradeStrategyInPlace || kcp.Spec.InPlaceUpgrade == nil {
+		return ctrl.Result{}
 	}
-	kairosConfig := &bootstrapv1beta2.KairosConfig{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-%d", kcp.Name, index),
-			Namespace: kcp.Namespace,
-			Labels: map[string]string{
-				clusterv1.ClusterNameLabel:         cluster.Name,
-				clusterv1.MachineControlPlaneLabel: "",
-			},
-			OwnerReferences: []metav1.OwnerReference{
-				*metav1.NewControllerRef(kcp, controlplanev1beta2.GroupVersion.WithKind("KairosControlPlane")),
-			},
-		},
-		Spec: bootstrapv1beta2.KairosConfigSpec{
-			Role:              "control-plane",
-			Distribution:      distribution,
-			KubernetesVersion: kcp.Spec.Version,
-		},
+	machines, err := r.getControlPlaneMachines(ctx, kcp, cluster)
+	if err != nil {
+		log.Error(err, "Failed to list control plane machines")
+		return ctrl.Result{RequeueAfter: 30 * time.Second}
+	}
+	var pending []*clusterv1.Machine
+	for _, machine := range machines {
+		if machine.DeletionTimestamp.IsZero() && !r.machineUpToDate(machine, kcp) && machineUpgradableInPlace(machine, kcp) {
+			pending = append(pending, machine)
+		}
+	}
+	if len(pending) == 0 {
+		return ctrl.Result{}
+	}
+	if r.Tracker == nil || !kcp.Status.Initialized {
+		return ctrl.Result{}
 	}
 
-	// Determine single-node mode from replicas
-	replicas := int32(1)
-	if kcp.Spec.Replicas != nil {
-		replicas = *kcp.Spec.Replicas
+	upgrade := kcp.Spec.InPlaceUpgrade
+	tag, err := upgrade.Tag(kcp.Spec.Version)
+	if err != nil {
+		conditions.MarkFalse(kcp, controlplanev1beta2.MachinesSpecUpToDateCondition, controlplanev1beta2.RolloutBlockedReason, clusterv1.ConditionSeverityError, "%s", err.Error())
+		return ctrl.Result{}
+	}
+	workloadClient, err := r.ConnectionBackoff.GetClient(ctx, r.Tracker, util.ObjectKey(cluster))
+	if err != nil {
+		return r.markInPlaceUpgradeError(log, kcp, err)
+	}
+	if result, err := r.ensureSystemUpgradeController(ctx, log, workloadClient, kcp, cluster, controlplanev1beta2.MachinesSpecUpToDateCondition); err != nil {
+		return r.markInPlaceUpgradeError(log, kcp, err)
+	} else if !result.IsZero() {
+		return result
 	}
-	kairosConfig.Spec.SingleNode = (replicas == 1)
-	log.Info("Setting SingleNode flag", "singleNode", kairosConfig.Spec.SingleNode, "replicas", replicas)
 
-	// If there's a template, merge its spec
-	if kcp.Spec.KairosConfigTemplate.Name != "" {
-		template := &bootstrapv1beta2.KairosConfigTemplate{}
-		templateKey := types.NamespacedName{
-			Namespace: kcp.Namespace,
-			Name:      kcp.Spec.KairosConfigTemplate.Name,
+	image := osupgrade.ImageRef(upgrade.Image, tag)
+	desired := osupgrade.NewControlPlanePlan(kcp.Name, upgrade.Image, tag, upgrade.Concurrency)
+	plan := &unstructured.Unstructured{}
+	plan.SetGroupVersionKind(osupgrade.PlanGVK)
+	if err := workloadClient.Get(ctx, client.ObjectKeyFromObject(desired), plan); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return r.markInPlaceUpgradeError(log, kcp, err)
 		}
-		if err := r.Get(ctx, templateKey, template); err != nil {
-			return fmt.Errorf("failed to get KairosConfigTemplate: %w", err)
+		log.Info("Starting in-place upgrade of control plane machines", "version", kcp.Spec.Version, "image", image, "plan", desired.GetName())
+		if err := workloadClient.Create(ctx, desired); err != nil {
+			return r.markInPlaceUpgradeError(log, kcp, fmt.Errorf("failed to create Plan: %w", err))
 		}
-		// Merge template spec
-		kairosConfig.Spec = template.Spec.Template.Spec
-		kairosConfig.Spec.Role = "control-plane"
-		kairosConfig.Spec.Distribution = distribution
-		kairosConfig.Spec.KubernetesVersion = kcp.Spec.Version
-		// Override SingleNode based on replicas (replicas takes precedence)
-		kairosConfig.Spec.SingleNode = (replicas == 1)
+		r.eventf(kcp, nil, corev1.EventTypeNormal, controlplanev1beta2.UpgradeStartedEvent,
+			"Upgrading %d control plane machine(s) in place to %s with %s", len(pending), kcp.Spec.Version, image)
+		conditions.MarkFalse(kcp, controlplanev1beta2.MachinesSpecUpToDateCondition, controlplanev1beta2.InPlaceUpgradeInProgressReason, clusterv1.ConditionSeverityInfo,
+			"Upgrading %d control plane machine(s) in place to %s", len(pending), kcp.Spec.Version)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}
 	}
 
-	if err := r.Create(ctx, kairosConfig); err != nil {
-		if !apierrors.IsAlreadyExists(err) {
-			return err
+	upgraded := 0
+	for _, machine := range pending {
+		node, err := workload.NodeForMachine(ctx, workloadClient, machine)
+		if err != nil {
+			return r.markInPlaceUpgradeError(log, kcp, err)
+		}
+		if node == nil || !osupgrade.PlanApplied(plan, node) {
+			continue
 		}
+		base := machine.DeepCopy()
+		machine.Spec.Version = ptr.To(kcp.Spec.Version)
+		if machine.Annotations == nil {
+			machine.Annotations = map[string]string{}
+		}
+		machine.Annotations[controlplanev1beta2.MachineSpecHashAnnotation] = machineSpecHash(kcp)
+		if err := r.Patch(ctx, machine, client.MergeFrom(base)); err != nil {
+			return r.markInPlaceUpgradeError(log, kcp, fmt.Errorf("failed to update version of machine %s: %w", machine.Name, err))
+		}
+		log.Info("Control plane machine upgraded in place", "machine", machine.Name, "node", node.Name, "version", kcp.Spec.Version)
+		upgraded++
+	}
+	if upgraded < len(pending) {
+		conditions.MarkFalse(kcp, controlplanev1beta2.MachinesSpecUpToDateCondition, controlplanev1beta2.InPlaceUpgradeInProgressReason, clusterv1.ConditionSeverityInfo,
+			"Upgraded %d of %d control plane machine(s) in place to %s", upgraded, len(pending), kcp.Spec.Version)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}
 	}
 
-	// Create infrastructure machine (clone from template)
-	infraMachine, err := r.createInfrastructureMachine(ctx, log, kcp, cluster, machineName)
-	if err != nil {
-		return fmt.Errorf("failed to create infrastructure machine: %w", err)
+	conditions.MarkTrue(kcp, controlplanev1beta2.MachinesSpecUpToDateCondition)
+	r.deleteStaleUpgrades(ctx, log, workloadClient, osupgrade.PlanGVK, osupgrade.PlanNamespace, kcp, plan.GetName())
+	return ctrl.Result{}
+}
+
+// markInPlaceUpgradeError records a workload cluster error on the
+// MachinesSpecUpToDate condition and schedules a retry.
+func (r *KairosControlPlaneReconciler) markInPlaceUpgradeError(log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, err error) ctrl.Result {
+	if errors.Is(err, remote.ErrClusterLocked) {
+		return ctrl.Result{RequeueAfter: 5 * time.Second}
 	}
+	log.V(4).Info("In-place upgrade step failed, will retry", "error", err.Error())
+	conditions.MarkUnknown(kcp, controlplanev1beta2.MachinesSpecUpToDateCondition, controlplanev1beta2.WorkloadClusterUnreachableReason, "%s", err.Error())
+	return ctrl.Result{RequeueAfter: 30 * time.Second}
+}
 
-	// Create Machine
-	machine := &clusterv1.Machine{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      machineName,
-			Namespace: kcp.Namespace,
-			Labels: map[string]string{
-				clusterv1.ClusterNameLabel:         cluster.Name,
+// markOSUpgradePlanError records a workload cluster error on the
+// OSUpgradeCompleted condition and schedules a retry.
+func (r *KairosControlPlaneReconciler) markOSUpgradePlanError(log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, err error) ctrl.Result {
+	if errors.Is(err, remote.ErrClusterLocked) {
+		return ctrl.Result{RequeueAfter: 5 * time.Second}
+	}
+	log.V(4).Info("OS upgrade plan step failed, will retry", "error", err.Error())
+	conditions.MarkUnknown(kcp, controlplanev1beta2.OSUpgradeCompletedCondition, controlplanev1beta2.WorkloadClusterUnreachableReason, "%s", err.Error())
+	return ctrl.Result{RequeueAfter: 30 * time.Second}
+}
+
+// markOSUpgradeError records a workload cluster error on the OSImageUpToDate
+// condition and schedules a retry.
+func (r *KairosControlPlaneReconciler) markOSUpgradeError(log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, err error) ctrl.Result {
+	if errors.Is(err, remote.ErrClusterLocked) {
+		return ctrl.Result{RequeueAfter: 5 * time.Second}
+	}
+	log.V(4).Info("OS upgrade step failed, will retry", "error", err.Error())
+	conditions.MarkUnknown(kcp, controlplanev1beta2.OSImageUpToDateCondition, controlplanev1beta2.WorkloadClusterUnreachableReason, "%s", err.Error())
+	return ctrl.Result{RequeueAfter: 30 * time.Second}
+}
+
+// controllerJoinTokenSecretName returns the name of the Secret holding the k0s
+// controller join token of a cluster.
+func controllerJoinTokenSecretName(clusterName string) string {
+	return fmt.Sprintf("%s-k0s-controller-token", clusterName)
+}
+
+// newCredentialsSecret returns an empty join token Secret named by key.
+func newCredentialsSecret(key types.NamespacedName) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+		},
+		Type: bootstrapv1beta2.CredentialsSecretType,
+	}
+}
+
+// reconcileControllerJoinToken keeps a k0s controller join token in a Secret
+// that control plane machines after the first join with. The token is created
+// on an initialized control plane node and replaced before it expires.
+func (r *KairosControlPlaneReconciler) reconcileControllerJoinToken(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) ctrl.Result {
+	if kcp.Spec.Distribution != "" && kcp.Spec.Distribution != "k0s" {
+		return ctrl.Result{}
+	}
+	if kcp.Spec.Replicas == nil || *kcp.Spec.Replicas <= 1 || !kcp.Status.Initialized {
+		return ctrl.Result{}
+	}
+
+	secretKey := types.NamespacedName{Namespace: cluster.Namespace, Name: controllerJoinTokenSecretName(cluster.Name)}
+	secret := &corev1.Secret{}
+	if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Error(err, "Failed to get k0s controller token secret", "secret", secretKey.Name)
+			return ctrl.Result{RequeueAfter: 30 * time.Second}
+		}
+		secret = newCredentialsSecret(secretKey)
+	} else if expiry, err := time.Parse(time.RFC3339, secret.Annotations[controllerJoinTokenExpiryAnnotation]); err == nil {
+		if renewIn := time.Until(expiry) - controllerJoinTokenRenewBefore; renewIn > 0 {
+			return ctrl.Result{RequeueAfter: renewIn}
+		}
+	}
+
+	machines, err := r.getControlPlaneMachines(ctx, kcp, cluster)
+	if err != nil {
+		log.Error(err, "Failed to list control plane machines")
+		return ctrl.Result{RequeueAfter: 30 * time.Second}
+	}
+	var source *clusterv1.Machine
+	for _, machine := range machines {
+		if machine.DeletionTimestamp.IsZero() && machine.Status.NodeRef != nil {
+			source = machine
+			break
+		}
+	}
+	if source == nil {
+		log.V(4).Info("No joined control plane machine to create a k0s controller token on yet")
+		return ctrl.Result{RequeueAfter: 30 * time.Second}
+	}
+
+	expiry := time.Now().Add(controllerJoinTokenTTL)
+	token, err := r.createK0sControllerToken(ctx, log, source, cluster)
+	if err != nil {
+		log.Error(err, "Failed to create k0s controller token", "machine", source.Name)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}
+	}
+
+	if secret.ResourceVersion != "" && secret.Type != bootstrapv1beta2.CredentialsSecretType {
+		// Secrets written by earlier releases have another type, which cannot
+		// be changed; replace them
+		if err := r.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			log.Error(err, "Failed to replace k0s controller token secret", "secret", secretKey.Name)
+			return ctrl.Result{RequeueAfter: 30 * time.Second}
+		}
+		secret = newCredentialsSecret(secretKey)
+	}
+	if secret.Labels == nil {
+		secret.Labels = map[string]string{}
+	}
+	secret.Labels[clusterv1.ClusterNameLabel] = cluster.Name
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[controllerJoinTokenExpiryAnnotation] = expiry.UTC().Format(time.RFC3339)
+	secret.Data = map[string][]byte{"token": token}
+	if err := controllerutil.SetControllerReference(kcp, secret, r.Scheme); err != nil {
+		log.Error(err, "Failed to set owner on k0s controller token secret")
+		return ctrl.Result{RequeueAfter: 30 * time.Second}
+	}
+	if secret.ResourceVersion == "" {
+		err = r.Create(ctx, secret)
+	} else {
+		err = r.Update(ctx, secret)
+	}
+	if err != nil {
+		log.Error(err, "Failed to store k0s controller token", "secret", secretKey.Name)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}
+	}
+
+	log.Info("Stored k0s controller join token", "secret", secretKey.Name, "machine", source.Name, "expiry", expiry)
+	return ctrl.Result{RequeueAfter: controllerJoinTokenTTL - controllerJoinTokenRenewBefore}
+}
+
+// reconcileCertificates generates the cluster CA, etcd CA, front proxy CA and
+// service account key Secrets unless they exist, e.g. because they were
+// provided by the user. The bootstrap provider installs them on every control
+// plane node, so they outlive control plane machine replacement.
+func (r *KairosControlPlaneReconciler) reconcileCertificates(ctx context.Context, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) error {
+	certificates := bootstrap.ClusterCertificates()
+	owner := *metav1.NewControllerRef(kcp, controlplanev1beta2.GroupVersion.WithKind("KairosControlPlane"))
+	if err := certificates.LookupOrGenerate(ctx, r.Client, util.ObjectKey(cluster), owner); err != nil {
+		return fmt.Errorf("failed to look up or generate cluster certificates: %w", err)
+	}
+	return nil
+}
+
+// reconcileEtcdMembers removes the etcd member of every deleted k0s control
+// plane machine that waits for the pre-terminate hook, i.e. after its node was
+// drained, and then releases the hook so the Machine controller shuts the node
+// down. Members are removed through another joined control plane node.
+func (r *KairosControlPlaneReconciler) reconcileEtcdMembers(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) ctrl.Result {
+	machines, err := r.getControlPlaneMachines(ctx, kcp, cluster)
+	if err != nil {
+		log.Error(err, "Failed to list control plane machines")
+		return ctrl.Result{RequeueAfter: 30 * time.Second}
+	}
+
+	hooked := false
+	var failed []string
+	for _, machine := range machines {
+		if _, ok := machine.Annotations[etcd.PreTerminateHookAnnotation]; !ok {
+			continue
+		}
+		hooked = true
+		if machine.DeletionTimestamp.IsZero() {
+			continue
+		}
+		// The whole etcd cluster goes away with the Cluster, so nothing is removed
+		if cluster.DeletionTimestamp.IsZero() {
+			if conditions.GetReason(machine, clusterv1.PreTerminateDeleteHookSucceededCondition) != clusterv1.WaitingExternalHookReason {
+				log.V(4).Info("Waiting for control plane machine to be drained before removing its etcd member", "machine", machine.Name)
+				continue
+			}
+			if err := r.removeEtcdMember(ctx, log, cluster, machine, machines); err != nil {
+				log.Error(err, "Failed to remove etcd member of control plane machine", "machine", machine.Name)
+				failed = append(failed, machine.Name)
+				continue
+			}
+			log.Info("Removed etcd member of control plane machine", "machine", machine.Name)
+		}
+		if err := r.releaseEtcdHook(ctx, machine); err != nil {
+			log.Error(err, "Failed to remove pre-terminate hook from control plane machine", "machine", machine.Name)
+			failed = append(failed, machine.Name)
+		}
+	}
+
+	if len(failed) > 0 {
+		conditions.MarkFalse(kcp, controlplanev1beta2.EtcdClusterHealthyCondition, controlplanev1beta2.EtcdMemberRemovalFailedReason, clusterv1.ConditionSeverityWarning,
+			"Failed to remove the etcd member of machine(s) %s", strings.Join(failed, ", "))
+		return ctrl.Result{RequeueAfter: 30 * time.Second}
+	}
+	if hooked {
+		conditions.MarkTrue(kcp, controlplanev1beta2.EtcdClusterHealthyCondition)
+	}
+	return ctrl.Result{}
+}
+
+// reconcileDrain cordons and drains the nodes of deleted control plane
+// machines that carry the pre-drain hook, then releases the hook and tells the
+// Machine controller to skip its own drain. Draining gives up after
+// machineTemplate.nodeDrainTimeout, counted from the machine deletion.
+func (r *KairosControlPlaneReconciler) reconcileDrain(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) ctrl.Result {
+	machines, err := r.getControlPlaneMachines(ctx, kcp, cluster)
+	if err != nil {
+		log.Error(err, "Failed to list control plane machines")
+		return ctrl.Result{RequeueAfter: 30 * time.Second}
+	}
+
+	requeue := false
+	for _, machine := range machines {
+		if _, ok := machine.Annotations[drain.PreDrainHookAnnotation]; !ok || machine.DeletionTimestamp.IsZero() {
+			continue
+		}
+		skipMachineDrain := true
+		switch {
+		case !cluster.DeletionTimestamp.IsZero():
+			// The workloads go away with the Cluster
+		case machine.Status.NodeRef == nil:
+			// The node never joined, so nothing runs on it
+		case drainTimedOut(kcp, machine):
+			log.Info("Draining node of control plane machine timed out, deleting it anyway", "machine", machine.Name)
+		default:
+			workloadClient, err := r.getDrainClient(ctx, cluster)
+			if err != nil {
+				log.Error(err, "Failed to get workload cluster client to drain control plane machine", "machine", machine.Name)
+				requeue = true
+				continue
+			}
+			if workloadClient == nil {
+				// Leave draining to the Machine controller
+				skipMachineDrain = false
+				break
+			}
+			remaining, err := r.drainNode(ctx, workloadClient, machine)
+			if err != nil {
+				log.Error(err, "Failed to drain node of control plane machine", "machine", machine.Name)
+				requeue = true
+				continue
+			}
+			if remaining > 0 {
+				log.Info("Waiting for pods to be evicted from control plane machine", "machine", machine.Name, "pods", remaining)
+				requeue = true
+				continue
+			}
+			log.Info("Drained node of control plane machine", "machine", machine.Name)
+		}
+		if err := r.releaseDrainHook(ctx, machine, skipMachineDrain); err != nil {
+			log.Error(err, "Failed to remove pre-drain hook from control plane machine", "machine", machine.Name)
+			requeue = true
+		}
+	}
+
+	if requeue {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}
+	}
+	return ctrl.Result{}
+}
+
+// drainTimedOut reports whether machine has been draining for longer than
+// machineTemplate.nodeDrainTimeout. Without a timeout draining never stops.
+func drainTimedOut(kcp *controlplanev1beta2.KairosControlPlane, machine *clusterv1.Machine) bool {
+	timeout := kcp.Spec.MachineTemplate.NodeDrainTimeout
+	if timeout == nil || timeout.Duration <= 0 || machine.DeletionTimestamp.IsZero() {
+		return false
+	}
+	return time.Since(machine.DeletionTimestamp.Time) > timeout.Duration
+}
+
+// getDrainClient returns the client nodes are drained with. Pods are listed
+// across the node, so the client reads from the API server rather than from
+// a cache. It returns nil without a Tracker.
+func (r *KairosControlPlaneReconciler) getDrainClient(ctx context.Context, cluster *clusterv1.Cluster) (client.Client, error) {
+	if r.drainClient != nil {
+		return r.drainClient, nil
+	}
+	if r.Tracker == nil {
+		return nil, nil
+	}
+	restConfig, err := r.Tracker.GetRESTConfig(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workload cluster REST config: %w", err)
+	}
+	return client.New(restConfig, client.Options{Scheme: r.Scheme})
+}
+
+// drainNode cordons the node of machine and evicts its pods, returning the
+// number of pods still running on it.
+func (r *KairosControlPlaneReconciler) drainNode(ctx context.Context, workloadClient client.Client, machine *clusterv1.Machine) (int, error) {
+	node, err := workload.NodeForMachine(ctx, workloadClient, machine)
+	if err != nil || node == nil {
+		return 0, err
+	}
+	if err := drain.Cordon(ctx, workloadClient, node); err != nil {
+		return 0, err
+	}
+	return drain.Drain(ctx, workloadClient, node, workload.IsNodeReady(node))
+}
+
+// releaseDrainHook removes the pre-drain hook from machine. With
+// skipMachineDrain the Machine controller is told not to drain the node again.
+func (r *KairosControlPlaneReconciler) releaseDrainHook(ctx context.Context, machine *clusterv1.Machine, skipMachineDrain bool) error {
+	base := machine.DeepCopy()
+	delete(machine.Annotations, drain.PreDrainHookAnnotation)
+	if skipMachineDrain {
+		machine.Annotations[clusterv1.ExcludeNodeDrainingAnnotation] = ""
+	}
+	if err := r.Patch(ctx, machine, client.MergeFrom(base)); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// removeEtcdMember removes the etcd member of machine through the first of the
+// other joined control plane machines that can be reached. Without such a
+// machine there is no etcd cluster left to remove the member from.
+func (r *KairosControlPlaneReconciler) removeEtcdMember(ctx context.Context, log logr.Logger, cluster *clusterv1.Cluster, machine *clusterv1.Machine, machines []*clusterv1.Machine) error {
+	executor := r.etcdExecutor
+	if executor == nil {
+		executor = &sshEtcdExecutor{r: r, log: log, cluster: cluster}
+	}
+
+	var lastErr error
+	for _, peer := range machines {
+		if peer == machine || !peer.DeletionTimestamp.IsZero() || peer.Status.NodeRef == nil {
+			continue
+		}
+		if err := etcd.RemoveMember(ctx, executor, peer, machine); err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	if lastErr != nil {
+		return lastErr
+	}
+	log.Info("No other joined control plane machine, skipping etcd member removal", "machine", machine.Name)
+	return nil
+}
+
+// releaseEtcdHook removes the etcd pre-terminate hook from machine.
+func (r *KairosControlPlaneReconciler) releaseEtcdHook(ctx context.Context, machine *clusterv1.Machine) error {
+	base := machine.DeepCopy()
+	delete(machine.Annotations, etcd.PreTerminateHookAnnotation)
+	if err := r.Patch(ctx, machine, client.MergeFrom(base)); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// keepsEtcdQuorum reports whether deleting target leaves enough healthy joined
+// control plane machines for the etcd cluster of the remaining members.
+// Machines that never joined are no etcd members, and a single member control
+// plane is replaced as a whole.
+func keepsEtcdQuorum(machines []*clusterv1.Machine, target *clusterv1.Machine) (bool, int, int) {
+	if target.Status.NodeRef == nil {
+		return true, 0, 0
+	}
+	members, healthy := 0, 0
+	for _, machine := range machines {
+		if machine.Status.NodeRef == nil {
+			continue
+		}
+		members++
+		if machine != target && machine.DeletionTimestamp.IsZero() && !machineNeedsRemediation(machine) {
+			healthy++
+		}
+	}
+	if members <= 1 {
+		return true, healthy, 0
+	}
+	return etcd.CanRemoveMember(members, healthy), healthy, etcd.Quorum(members - 1)
+}
+
+// nodeToKairosControlPlane maps workload cluster Nodes of cluster to the
+// KairosControlPlane owning the Machine they back.
+func (r *KairosControlPlaneReconciler) nodeToKairosControlPlane(cluster client.ObjectKey) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		node, ok := o.(*corev1.Node)
+		if !ok {
+			return nil
+		}
+		machines, err := workload.MachinesForNode(ctx, r.Client, cluster, node)
+		if err != nil {
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for i := range machines {
+			requests = append(requests, r.machineToKairosControlPlane(ctx, &machines[i])...)
+		}
+		return requests
+	}
+}
+
+// findClusterForControlPlane searches for a Cluster that references this KairosControlPlane
+func (r *KairosControlPlaneReconciler) findClusterForControlPlane(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane) (*clusterv1.Cluster, error) {
+	// List all Clusters in the same namespace
+	clusters := &clusterv1.ClusterList{}
+	if err := r.List(ctx, clusters, client.InNamespace(kcp.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list clusters: %w", err)
+	}
+
+	// Find the Cluster that references this KairosControlPlane
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		if cluster.Spec.ControlPlaneRef != nil &&
+			cluster.Spec.ControlPlaneRef.Kind == "KairosControlPlane" &&
+			cluster.Spec.ControlPlaneRef.Name == kcp.Name {
+			// Check namespace - it might be empty (defaults to cluster namespace)
+			refNamespace := cluster.Spec.ControlPlaneRef.Namespace
+			if refNamespace == "" || refNamespace == kcp.Namespace {
+				// Check API version/group matches
+				// In v1beta2, ControlPlaneRef uses apiGroup in YAML, but Go type uses APIVersion
+				// When apiGroup is set, APIVersion may be empty or contain the full version string
+				refAPIVersion := cluster.Spec.ControlPlaneRef.APIVersion
+				expectedGroup := controlplanev1beta2.GroupVersion.Group
+				expectedVersion := controlplanev1beta2.GroupVersion.String()
+
+				// Match if:
+				// 1. APIVersion is empty (v1beta2 using apiGroup - we trust the kind match)
+				// 2. APIVersion matches expected version (v1beta1 style or v1beta2 with full version)
+				// 3. APIVersion contains the expected group (handles partial matches)
+				if refAPIVersion == "" {
+					// Empty APIVersion means apiGroup is being used - trust the kind match
+					log.Info("Found Cluster with matching ControlPlaneRef (apiGroup)", "cluster", cluster.Name, "kind", cluster.Spec.ControlPlaneRef.Kind)
+					return cluster, nil
+				}
+				if refAPIVersion == expectedVersion {
+					return cluster, nil
+				}
+				if len(refAPIVersion) > 0 && len(expectedGroup) > 0 && len(refAPIVersion) >= len(expectedGroup) && refAPIVersion[:len(expectedGroup)] == expectedGroup {
+					return cluster, nil
+				}
+				log.Info("Cluster ControlPlaneRef APIVersion doesn't match", "cluster", cluster.Name, "refAPIVersion", refAPIVersion, "expectedVersion", expectedVersion, "expectedGroup", expectedGroup)
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *KairosControlPlaneReconciler) reconcileMachines(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) error {
+	// Get desired replica count
+	desiredReplicas := int32(1)
+	if kcp.Spec.Replicas != nil {
+		desiredReplicas = *kcp.Spec.Replicas
+	}
+
+	// List existing control plane machines
+	machines, err := r.getControlPlaneMachines(ctx, kcp, cluster)
+	if err != nil {
+		return fmt.Errorf("failed to list control plane machines: %w", err)
+	}
+
+	// Sort machines by creation timestamp (oldest first) for stable operations
+	sort.Slice(machines, func(i, j int) bool {
+		return machines[i].CreationTimestamp.Before(&machines[j].CreationTimestamp)
+	})
+
+	currentReplicas := int32(len(machines))
+
+	log.Info("Reconciling control plane machines", "desired", desiredReplicas, "current", currentReplicas)
+
+	var outdatedMachines []*clusterv1.Machine
+	deleting := false
+	upgradingInPlace := false
+	for _, machine := range machines {
+		if !machine.DeletionTimestamp.IsZero() {
+			deleting = true
+			continue
+		}
+		if r.machineUpToDate(machine, kcp) {
+			continue
+		}
+		// Left to reconcileInPlaceUpgrade
+		if kcp.Spec.UpgradeStrategy == controlplanev1beta2.UpgradeStrategyInPlace && kcp.Spec.InPlaceUpgrade != nil && machineUpgradableInPlace(machine, kcp) {
+			upgradingInPlace = true
+			continue
+		}
+		outdatedMachines = append(outdatedMachines, machine)
+	}
+
+	// Replace outdated machines one at a time
+	if len(outdatedMachines) > 0 {
+		return r.rolloutMachines(ctx, log, kcp, cluster, machines, outdatedMachines, desiredReplicas)
+	}
+	if !upgradingInPlace {
+		conditions.MarkTrue(kcp, controlplanev1beta2.MachinesSpecUpToDateCondition)
+	}
+
+	// Never delete a machine while another one is still going away, so scale
+	// down removes members one by one
+	if deleting && currentReplicas > desiredReplicas {
+		log.Info("Waiting for control plane machine deletion to finish before scaling down")
+		return nil
+	}
+
+	// Create machines if needed
+	if currentReplicas < desiredReplicas {
+		toCreate := desiredReplicas - currentReplicas
+		if toCreate > 0 {
+			nextIndex := r.nextMachineIndex(machines, kcp.Name)
+			if err := r.createControlPlaneMachine(ctx, log, kcp, cluster, nextIndex); err != nil {
+				return fmt.Errorf("failed to create control plane machine: %w", err)
+			}
+			r.eventf(kcp, nil, corev1.EventTypeNormal, controlplanev1beta2.ControlPlaneScalingUpEvent,
+				"Scaling up control plane from %d to %d replicas", currentReplicas, desiredReplicas)
+			// Only create one per reconcile to avoid over-scaling
+			return nil
+		}
+	}
+
+	// Delete machines if needed (scale down)
+	if currentReplicas > desiredReplicas {
+		target := r.selectMachineForDeletion(machines, outdatedMachines)
+		if target != nil {
+			if ok, healthy, quorum := keepsEtcdQuorum(machines, target); !ok {
+				log.Info("Not scaling down control plane machine, etcd would lose quorum",
+					"machine", target.Name, "healthy", healthy, "quorum", quorum)
+				conditions.MarkFalse(kcp, controlplanev1beta2.EtcdClusterHealthyCondition, controlplanev1beta2.EtcdQuorumAtRiskReason, clusterv1.ConditionSeverityWarning,
+					"Deleting machine %s would lose etcd quorum: %d healthy control plane machine(s), %d required", target.Name, healthy, quorum)
+				return nil
+			}
+			log.Info("Scaling down control plane machine", "machine", target.Name)
+			if err := r.Delete(ctx, target); err != nil {
+				return fmt.Errorf("failed to delete control plane machine: %w", err)
+			}
+			r.eventf(kcp, target, corev1.EventTypeNormal, controlplanev1beta2.ControlPlaneScalingDownEvent,
+				"Scaling down control plane from %d to %d replicas, deleting machine %s", currentReplicas, desiredReplicas, target.Name)
+		}
+	}
+
+	return nil
+}
+
+// updateKCPStatus writes the KCP status even when the manager is shutting down,
+// so progress made by this reconcile (e.g. created machines) is not lost.
+func (r *KairosControlPlaneReconciler) updateKCPStatus(ctx context.Context, kcp *controlplanev1beta2.KairosControlPlane) error {
+	setV1Beta2Conditions(kcp)
+	redactStatus(kcp)
+	updateCtx, cancel := shutdown.DetachedContext(ctx)
+	defer cancel()
+	return r.Status().Update(updateCtx, kcp)
+}
+
+// redactStatus removes join tokens from the messages of kcp's status, which
+// often carry errors of commands run on the control plane nodes.
+func redactStatus(kcp *controlplanev1beta2.KairosControlPlane) {
+	redact.Conditions(kcp.Status.Conditions)
+	if kcp.Status.V1Beta2 != nil {
+		redact.V1Beta2Conditions(kcp.Status.V1Beta2.Conditions)
+	}
+	kcp.Status.FailureMessage = redact.String(kcp.Status.FailureMessage)
+}
+
+// setV1Beta2Conditions derives the v1beta2 conditions from the legacy
+// conditions and the replica counts in status.
+func setV1Beta2Conditions(kcp *controlplanev1beta2.KairosControlPlane) {
+	v1beta2conditions.Mirror(kcp, controlplanev1beta2.AvailableCondition,
+		controlplanev1beta2.AvailableV1Beta2Reason, controlplanev1beta2.NotAvailableV1Beta2Reason)
+	v1beta2conditions.Mirror(kcp, clusterv1.ReadyCondition,
+		controlplanev1beta2.ReadyV1Beta2Reason, controlplanev1beta2.NotReadyV1Beta2Reason)
+	if conditions.Has(kcp, controlplanev1beta2.PausedCondition) {
+		v1beta2conditions.Mirror(kcp, controlplanev1beta2.PausedCondition,
+			controlplanev1beta2.PausedV1Beta2Reason, controlplanev1beta2.NotPausedV1Beta2Reason)
+	}
+
+	desired := int32(1)
+	if kcp.Spec.Replicas != nil {
+		desired = *kcp.Spec.Replicas
+	}
+	current := kcp.Status.Replicas
+
+	scalingUp := metav1.Condition{
+		Type:   controlplanev1beta2.ScalingUpV1Beta2Condition,
+		Status: metav1.ConditionFalse,
+		Reason: controlplanev1beta2.NotScalingUpV1Beta2Reason,
+	}
+	if current < desired {
+		scalingUp.Status = metav1.ConditionTrue
+		scalingUp.Reason = controlplanev1beta2.ScalingUpV1Beta2Reason
+		scalingUp.Message = fmt.Sprintf("Scaling up from %d to %d replicas", current, desired)
+	}
+	v1beta2conditions.Set(kcp, scalingUp)
+
+	scalingDown := metav1.Condition{
+		Type:   controlplanev1beta2.ScalingDownV1Beta2Condition,
+		Status: metav1.ConditionFalse,
+		Reason: controlplanev1beta2.NotScalingDownV1Beta2Reason,
+	}
+	if current > desired {
+		scalingDown.Status = metav1.ConditionTrue
+		scalingDown.Reason = controlplanev1beta2.ScalingDownV1Beta2Reason
+		scalingDown.Message = fmt.Sprintf("Scaling down from %d to %d replicas", current, desired)
+	}
+	v1beta2conditions.Set(kcp, scalingDown)
+
+	machinesReady := metav1.Condition{
+		Type:   controlplanev1beta2.MachinesReadyV1Beta2Condition,
+		Status: metav1.ConditionTrue,
+		Reason: controlplanev1beta2.ReadyV1Beta2Reason,
+	}
+	switch {
+	case current == 0:
+		machinesReady.Reason = controlplanev1beta2.NoReplicasV1Beta2Reason
+	case kcp.Status.ReadyReplicas < current:
+		machinesReady.Status = metav1.ConditionFalse
+		machinesReady.Reason = controlplanev1beta2.NotReadyV1Beta2Reason
+		machinesReady.Message = fmt.Sprintf("%d of %d control plane machines are not ready", current-kcp.Status.ReadyReplicas, current)
+	}
+	v1beta2conditions.Set(kcp, machinesReady)
+}
+
+func (r *KairosControlPlaneReconciler) createControlPlaneMachine(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster, index int32) (retErr error) {
+	machineName, err := kcp.MachineName(cluster.Name, index, utilrand.String(5))
+	if err != nil {
+		return err
+	}
+
+	machines, err := r.getControlPlaneMachines(ctx, kcp, cluster)
+	if err != nil {
+		return fmt.Errorf("failed to list control plane machines: %w", err)
+	}
+	failureDomain := r.nextFailureDomain(kcp, cluster, machines)
+
+	// Create KairosConfig
+	distribution := kcp.Spec.Distribution
+	if distribution == "" {
+		distribution = "k0s"
+	}
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      machineName,
+			Namespace: kcp.Namespace,
+			Labels: map[string]string{
+				clusterv1.ClusterNameLabel:         cluster.Name,
+				clusterv1.MachineControlPlaneLabel: "",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(kcp, controlplanev1beta2.GroupVersion.WithKind("KairosControlPlane")),
+			},
+		},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role:              "control-plane",
+			Distribution:      distribution,
+			KubernetesVersion: kcp.Spec.Version,
+		},
+	}
+
+	// Determine single-node mode from replicas
+	replicas := int32(1)
+	if kcp.Spec.Replicas != nil {
+		replicas = *kcp.Spec.Replicas
+	}
+	kairosConfig.Spec.SingleNode = (replicas == 1)
+	log.Info("Setting SingleNode flag", "singleNode", kairosConfig.Spec.SingleNode, "replicas", replicas)
+
+	// Start from the inline configuration or the referenced template, if any
+	var configSpec *bootstrapv1beta2.KairosConfigSpec
+	if kcp.Spec.KairosConfigSpec != nil {
+		configSpec = kcp.Spec.KairosConfigSpec.DeepCopy()
+	} else if kcp.Spec.KairosConfigTemplate.Name != "" {
+		template := &bootstrapv1beta2.KairosConfigTemplate{}
+		templateKey := types.NamespacedName{
+			Namespace: kcp.Namespace,
+			Name:      kcp.Spec.KairosConfigTemplate.Name,
+		}
+		if err := r.Get(ctx, templateKey, template); err != nil {
+			return fmt.Errorf("failed to get KairosConfigTemplate: %w", err)
+		}
+		configSpec = &template.Spec.Template.Spec
+	}
+	if configSpec != nil {
+		kairosConfig.Spec = *configSpec
+		kairosConfig.Spec.Role = "control-plane"
+		kairosConfig.Spec.Distribution = distribution
+		kairosConfig.Spec.KubernetesVersion = kcp.Spec.Version
+		// Override SingleNode based on replicas (replicas takes precedence)
+		kairosConfig.Spec.SingleNode = (replicas == 1)
+	}
+	for _, san := range kcp.Spec.CertSANs {
+		if !slices.Contains(kairosConfig.Spec.CertSANs, san) {
+			kairosConfig.Spec.CertSANs = append(kairosConfig.Spec.CertSANs, san)
+		}
+	}
+	if err := applyControlPlaneEndpoint(&kairosConfig.Spec, kcp, cluster); err != nil {
+		return fmt.Errorf("failed to configure control plane endpoint: %w", err)
+	}
+	if kcp.Spec.ExternalManagedEndpoint {
+		applyExternalEndpoint(&kairosConfig.Spec, cluster)
+	}
+	applyCNI(&kairosConfig.Spec, kcp.Spec.CNI)
+	applyAddons(&kairosConfig.Spec, kcp.Spec.Addons)
+
+	// Control plane machines after the first join the existing k0s control plane
+	// with the token kept by reconcileControllerJoinToken
+	if distribution == "k0s" && !kairosConfig.Spec.SingleNode {
+		join, err := r.joinsK0sControlPlane(ctx, kcp, cluster)
+		if err != nil {
+			return err
+		}
+		if join {
+			kairosConfig.Spec.ControllerTokenSecretRef = &bootstrapv1beta2.WorkerTokenSecretReference{
+				Name: controllerJoinTokenSecretName(cluster.Name),
+				Key:  "token",
+			}
+		}
+	}
+
+	// KairosConfig, infrastructure machine and Machine are created as a unit: the
+	// sequence is not interrupted by a manager shutdown, and objects created here
+	// are removed again if a later step fails, so no orphans are left behind.
+	createCtx, cancel := shutdown.DetachedContext(ctx)
+	defer cancel()
+	var created []client.Object
+	defer func() {
+		if retErr == nil {
+			return
+		}
+		for _, obj := range created {
+			if err := r.Delete(createCtx, obj); err != nil && !apierrors.IsNotFound(err) {
+				log.Error(err, "Failed to clean up partially created control plane machine object",
+					"kind", obj.GetObjectKind().GroupVersionKind().Kind, "name", obj.GetName())
+			}
+		}
+	}()
+
+	if err := r.Create(createCtx, kairosConfig); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+	} else {
+		created = append(created, kairosConfig)
+	}
+
+	// Create infrastructure machine (clone from template)
+	infraMachine, infraCreated, err := r.createInfrastructureMachine(createCtx, log, kcp, cluster, machineName)
+	if err != nil {
+		return fmt.Errorf("failed to create infrastructure machine: %w", err)
+	}
+	if infraCreated {
+		created = append(created, infraMachine)
+	}
+
+	// Create Machine
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      machineName,
+			Namespace: kcp.Namespace,
+			Labels: map[string]string{
+				clusterv1.ClusterNameLabel:         cluster.Name,
 				clusterv1.MachineControlPlaneLabel: "",
 			},
+			Annotations: map[string]string{
+				controlplanev1beta2.MachineSpecHashAnnotation: machineSpecHash(kcp),
+				controlplanev1beta2.MachineIndexAnnotation:    strconv.Itoa(int(index)),
+			},
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(kcp, controlplanev1beta2.GroupVersion.WithKind("KairosControlPlane")),
 			},
 		},
 		Spec: clusterv1.MachineSpec{
-			ClusterName: cluster.Name,
-			Version:     &kcp.Spec.Version,
+			ClusterName:      cluster.Name,
+			Version:          &kcp.Spec.Version,
+			FailureDomain:    failureDomain,
+			NodeDrainTimeout: kcp.Spec.MachineTemplate.NodeDrainTimeout,
 			Bootstrap: clusterv1.Bootstrap{
 				ConfigRef: &corev1.ObjectReference{
 					APIVersion: bootstrapv1beta2.GroupVersion.String(),
@@ -594,102 +1755,662 @@ func (r *KairosControlPlaneReconciler) createControlPlaneMachine(ctx context.Con
 		},
 	}
 
-	return r.Create(ctx, machine)
+	// Control plane nodes are drained by this controller before they go away
+	machine.Annotations[drain.PreDrainHookAnnotation] = ""
+
+	// k0s controllers that joined etcd leave it before their node is shut down
+	if usesK0sEtcd(&kairosConfig.Spec) {
+		machine.Annotations[etcd.PreTerminateHookAnnotation] = ""
+	}
+
+	return r.Create(createCtx, machine)
+}
+
+// usesK0sEtcd reports whether a control plane machine with spec joins the
+// embedded etcd cluster of a multi-node k0s control plane.
+func usesK0sEtcd(spec *bootstrapv1beta2.KairosConfigSpec) bool {
+	if spec.Distribution != "k0s" || spec.SingleNode {
+		return false
+	}
+	return spec.K0sConfig == nil || spec.K0sConfig.Storage == nil || spec.K0sConfig.Storage.Type != "kine"
+}
+
+// joinsK0sControlPlane reports whether a new machine of a multi-node k0s
+// control plane joins with a controller token. Before the control plane is
+// initialized only the init lock holder runs the init path, so a new machine
+// takes the lock when nobody holds it.
+func (r *KairosControlPlaneReconciler) joinsK0sControlPlane(ctx context.Context, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) (bool, error) {
+	machines, err := r.getControlPlaneMachines(ctx, kcp, cluster)
+	if err != nil {
+		return false, fmt.Errorf("failed to list control plane machines: %w", err)
+	}
+	if kcp.Status.Initialized {
+		for _, machine := range machines {
+			if machine.DeletionTimestamp.IsZero() {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	holder, err := r.initLockHolder(ctx, machines)
+	if err != nil {
+		return false, err
+	}
+	return holder != nil, nil
+}
+
+// reconcileInitLock keeps a multi-node k0s control plane that is not
+// initialized yet from deadlocking: when the machine holding the init lock is
+// deleted or failed permanently, the oldest machine still waiting for its
+// controller join token takes the lock over. Dropping its token reference
+// makes the bootstrap controller render the init path for it instead.
+func (r *KairosControlPlaneReconciler) reconcileInitLock(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) error {
+	if kcp.Status.Initialized || kcp.Spec.Replicas == nil || *kcp.Spec.Replicas <= 1 {
+		return nil
+	}
+	if kcp.Spec.Distribution != "" && kcp.Spec.Distribution != "k0s" {
+		return nil
+	}
+
+	machines, err := r.getControlPlaneMachines(ctx, kcp, cluster)
+	if err != nil {
+		return fmt.Errorf("failed to list control plane machines: %w", err)
+	}
+	sort.Slice(machines, func(i, j int) bool {
+		return machines[i].CreationTimestamp.Before(&machines[j].CreationTimestamp)
+	})
+
+	holder, err := r.initLockHolder(ctx, machines)
+	if err != nil || holder != nil {
+		return err
+	}
+	for _, machine := range machines {
+		if !canHoldInitLock(machine) {
+			continue
+		}
+		kairosConfig, err := r.machineKairosConfig(ctx, machine)
+		if err != nil {
+			return err
+		}
+		// Machines that already booted from join bootstrap data cannot switch
+		// to the init path; they are replaced through remediation instead
+		if kairosConfig == nil || kairosConfig.Status.DataSecretName != nil {
+			continue
+		}
+		log.Info("Moving control plane init lock to machine", "machine", machine.Name)
+		patch := client.MergeFrom(kairosConfig.DeepCopy())
+		kairosConfig.Spec.ControllerTokenSecretRef = nil
+		if err := r.Patch(ctx, kairosConfig, patch); err != nil {
+			return fmt.Errorf("failed to move init lock to machine %s: %w", machine.Name, err)
+		}
+		return nil
+	}
+	return nil
+}
+
+// initLockHolder returns the machine of a multi-node k0s control plane that
+// runs the cluster init path, i.e. whose KairosConfig does not join with a
+// controller token, or nil when no live machine holds the init lock.
+func (r *KairosControlPlaneReconciler) initLockHolder(ctx context.Context, machines []*clusterv1.Machine) (*clusterv1.Machine, error) {
+	for _, machine := range machines {
+		if !canHoldInitLock(machine) {
+			continue
+		}
+		kairosConfig, err := r.machineKairosConfig(ctx, machine)
+		if err != nil {
+			return nil, err
+		}
+		if kairosConfig != nil && kairosConfig.Spec.ControllerTokenSecretRef == nil {
+			return machine, nil
+		}
+	}
+	return nil, nil
+}
+
+// canHoldInitLock reports whether machine may hold the init lock. Machines
+// being deleted or failed permanently never initialize the control plane.
+func canHoldInitLock(machine *clusterv1.Machine) bool {
+	return machine.DeletionTimestamp.IsZero() && machine.Status.FailureReason == nil && machine.Status.FailureMessage == nil
+}
+
+// machineKairosConfig returns the KairosConfig machine bootstraps from, or nil
+// when it does not reference one or it does not exist.
+func (r *KairosControlPlaneReconciler) machineKairosConfig(ctx context.Context, machine *clusterv1.Machine) (*bootstrapv1beta2.KairosConfig, error) {
+	ref := machine.Spec.Bootstrap.ConfigRef
+	if ref == nil || ref.Kind != "KairosConfig" {
+		return nil, nil
+	}
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = machine.Namespace
+	}
+	kairosConfig := &bootstrapv1beta2.KairosConfig{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, kairosConfig); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get KairosConfig for machine %s: %w", machine.Name, err)
+	}
+	return kairosConfig, nil
+}
+
+// createInfrastructureMachine clones the infrastructure machine template. The
+// returned bool reports whether the object was created by this call (as opposed
+// to already existing).
+func (r *KairosControlPlaneReconciler) createInfrastructureMachine(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster, machineName string) (client.Object, bool, error) {
+	infraRef := kcp.Spec.MachineTemplate.InfrastructureRef
+
+	// Prepare labels and annotations
+	labels := map[string]string{
+		clusterv1.ClusterNameLabel:         cluster.Name,
+		clusterv1.MachineControlPlaneLabel: "",
+	}
+	// Merge with template metadata labels
+	if kcp.Spec.MachineTemplate.Metadata.Labels != nil {
+		for k, v := range kcp.Spec.MachineTemplate.Metadata.Labels {
+			labels[k] = v
+		}
+	}
+
+	annotations := map[string]string{}
+	// Merge with template metadata annotations
+	if kcp.Spec.MachineTemplate.Metadata.Annotations != nil {
+		for k, v := range kcp.Spec.MachineTemplate.Metadata.Annotations {
+			annotations[k] = v
+		}
+	}
+
+	// Clone infrastructure machine using the helper
+	infraMachine, err := infrastructure.CloneInfrastructureMachine(
+		ctx,
+		r.Client,
+		r.Scheme,
+		infraRef,
+		machineName,
+		kcp.Namespace,
+		labels,
+		annotations,
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to clone infrastructure machine: %w", err)
+	}
+
+	// Set owner reference
+	if err := controllerutil.SetControllerReference(kcp, infraMachine, r.Scheme); err != nil {
+		return nil, false, fmt.Errorf("failed to set controller reference: %w", err)
+	}
+
+	// Create the infrastructure machine
+	created := true
+	if err := r.Create(ctx, infraMachine); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return nil, false, fmt.Errorf("failed to create infrastructure machine: %w", err)
+		}
+		// Machine already exists, get it
+		if err := r.Get(ctx, types.NamespacedName{Name: machineName, Namespace: kcp.Namespace}, infraMachine); err != nil {
+			return nil, false, fmt.Errorf("failed to get existing infrastructure machine: %w", err)
+		}
+		created = false
+	}
+
+	log.Info("Created infrastructure machine", "kind", infraRef.Kind, "name", machineName)
+	return infraMachine, created, nil
+}
+
+// controlPlaneSelector returns the label selector matching the control plane
+// machines of the cluster
+func controlPlaneSelector(cluster *clusterv1.Cluster) labels.Selector {
+	return labels.SelectorFromSet(map[string]string{
+		clusterv1.ClusterNameLabel:         cluster.Name,
+		clusterv1.MachineControlPlaneLabel: "",
+	})
+}
+
+func (r *KairosControlPlaneReconciler) getControlPlaneMachines(ctx context.Context, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) ([]*clusterv1.Machine, error) {
+	selector := controlPlaneSelector(cluster)
+
+	machineList := &clusterv1.MachineList{}
+	if err := r.List(ctx, machineList, client.InNamespace(kcp.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+
+	machines := make([]*clusterv1.Machine, 0, len(machineList.Items))
+	for i := range machineList.Items {
+		machine := &machineList.Items[i]
+		// Check if this machine is owned by this KCP
+		ownerRef := metav1.GetControllerOf(machine)
+		if ownerRef != nil && ownerRef.Kind == "KairosControlPlane" && ownerRef.Name == kcp.Name {
+			machines = append(machines, machine)
+		}
+	}
+
+	return machines, nil
+}
+
+// rolloutMachines performs one step of a rolling update: it creates an
+// up-to-date machine while within maxSurge, waits for new machines to join,
+// and deletes an outdated machine once that keeps at least
+// desired-maxUnavailable machines ready. The node of a deleted machine is
+// drained by reconcileDrain before the machine goes away.
+func (r *KairosControlPlaneReconciler) rolloutMachines(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster, machines, outdatedMachines []*clusterv1.Machine, desiredReplicas int32) error {
+	maxSurge, maxUnavailable := kcp.RolloutLimits()
+	currentReplicas := int32(len(machines))
+
+	if desiredReplicas == 1 && maxSurge > 0 {
+		// A surge machine of a single-node control plane bootstraps its own cluster
+		conditions.MarkFalse(kcp, controlplanev1beta2.MachinesSpecUpToDateCondition, controlplanev1beta2.RolloutBlockedReason, clusterv1.ConditionSeverityWarning,
+			"%d control plane machine(s) outdated; single-node control planes are only replaced with maxSurge 0 and maxUnavailable 1", len(outdatedMachines))
+		return nil
+	}
+	if conditions.GetReason(kcp, controlplanev1beta2.MachinesSpecUpToDateCondition) != controlplanev1beta2.RollingUpdateInProgressReason {
+		r.eventf(kcp, nil, corev1.EventTypeNormal, controlplanev1beta2.UpgradeStartedEvent,
+			"Starting rolling update of %d outdated control plane machine(s)", len(outdatedMachines))
+	}
+	conditions.MarkFalse(kcp, controlplanev1beta2.MachinesSpecUpToDateCondition, controlplanev1beta2.RollingUpdateInProgressReason, clusterv1.ConditionSeverityInfo,
+		"Rolling %d outdated control plane machine(s)", len(outdatedMachines))
+
+	var ready int32
+	for _, machine := range machines {
+		if !machine.DeletionTimestamp.IsZero() {
+			log.Info("Waiting for control plane machine deletion to finish", "machine", machine.Name)
+			return nil
+		}
+		if machine.Status.NodeRef != nil {
+			ready++
+		} else if r.machineUpToDate(machine, kcp) {
+			log.Info("Waiting for new control plane machine to join", "machine", machine.Name)
+			return nil
+		}
+	}
+
+	if currentReplicas < desiredReplicas+maxSurge {
+		nextIndex := r.nextMachineIndex(machines, kcp.Name)
+		if err := r.createControlPlaneMachine(ctx, log, kcp, cluster, nextIndex); err != nil {
+			return fmt.Errorf("failed to create control plane machine during rollout: %w", err)
+		}
+		return nil
+	}
+
+	target := outdatedMachines[0]
+	readyAfterDelete := ready
+	if target.Status.NodeRef != nil {
+		readyAfterDelete--
+	}
+	if readyAfterDelete < desiredReplicas-maxUnavailable {
+		log.Info("Not deleting outdated control plane machine yet, too few ready machines",
+			"machine", target.Name, "ready", ready, "desired", desiredReplicas, "maxUnavailable", maxUnavailable)
+		return nil
+	}
+
+	if ok, healthy, quorum := keepsEtcdQuorum(machines, target); !ok {
+		log.Info("Not deleting outdated control plane machine yet, etcd would lose quorum",
+			"machine", target.Name, "healthy", healthy, "quorum", quorum)
+		conditions.MarkFalse(kcp, controlplanev1beta2.EtcdClusterHealthyCondition, controlplanev1beta2.EtcdQuorumAtRiskReason, clusterv1.ConditionSeverityWarning,
+			"Deleting machine %s would lose etcd quorum: %d healthy control plane machine(s), %d required", target.Name, healthy, quorum)
+		return nil
+	}
+
+	log.Info("Deleting outdated control plane machine", "machine", target.Name)
+	if err := r.Delete(ctx, target); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete outdated control plane machine: %w", err)
+	}
+	return nil
+}
+
+// reconcileRemediation deletes one control plane machine marked for
+// remediation and records it in status.lastRemediation; reconcileMachines then
+// creates the replacement. Machines are remediated one at a time, and only
+// while the remaining healthy machines keep etcd quorum.
+func (r *KairosControlPlaneReconciler) reconcileRemediation(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) (ctrl.Result, error) {
+	machines, err := r.getControlPlaneMachines(ctx, kcp, cluster)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list control plane machines: %w", err)
+	}
+	sort.Slice(machines, func(i, j int) bool {
+		return machines[i].CreationTimestamp.Before(&machines[j].CreationTimestamp)
+	})
+
+	var target *clusterv1.Machine
+	for _, machine := range machines {
+		if machine.DeletionTimestamp.IsZero() && machineNeedsRemediation(machine) {
+			target = machine
+			break
+		}
+	}
+	if target == nil {
+		conditions.MarkTrue(kcp, controlplanev1beta2.MachinesHealthyCondition)
+		return ctrl.Result{}, nil
+	}
+
+	for _, machine := range machines {
+		if !machine.DeletionTimestamp.IsZero() {
+			conditions.MarkFalse(kcp, controlplanev1beta2.MachinesHealthyCondition, controlplanev1beta2.RemediationInProgressReason, clusterv1.ConditionSeverityInfo,
+				"Waiting for machine %s to be deleted before remediating machine %s", machine.Name, target.Name)
+			return ctrl.Result{}, nil
+		}
+	}
+
+	// Before the control plane is initialized there is no etcd quorum to protect,
+	// so a machine that failed to provision is always replaced
+	if kcp.Status.Initialized {
+		if len(machines) == 1 {
+			conditions.MarkFalse(kcp, controlplanev1beta2.MachinesHealthyCondition, controlplanev1beta2.RemediationBlockedReason, clusterv1.ConditionSeverityWarning,
+				"Machine %s is the only control plane machine and cannot be remediated", target.Name)
+			return ctrl.Result{}, nil
+		}
+		if ok, healthy, quorum := keepsEtcdQuorum(machines, target); !ok {
+			conditions.MarkFalse(kcp, controlplanev1beta2.MachinesHealthyCondition, controlplanev1beta2.RemediationBlockedReason, clusterv1.ConditionSeverityWarning,
+				"Remediating machine %s would lose quorum: %d healthy control plane machine(s), %d required", target.Name, healthy, quorum)
+			return ctrl.Result{}, nil
+		}
+	}
+
+	retryCount := int32(0)
+	strategy := kcp.Spec.RemediationStrategy
+	if last := kcp.Status.LastRemediation; last != nil {
+		since := time.Since(last.Timestamp.Time)
+		minHealthyPeriod := defaultMinHealthyPeriod
+		if strategy != nil && strategy.MinHealthyPeriod != nil {
+			minHealthyPeriod = strategy.MinHealthyPeriod.Duration
+		}
+		if since < minHealthyPeriod {
+			if strategy != nil && strategy.MaxRetry != nil && last.RetryCount >= *strategy.MaxRetry {
+				conditions.MarkFalse(kcp, controlplanev1beta2.MachinesHealthyCondition, controlplanev1beta2.RemediationBlockedReason, clusterv1.ConditionSeverityWarning,
+					"Machine %s cannot be remediated, %d retries after remediating machine %s reached", target.Name, *strategy.MaxRetry, last.Machine)
+				return ctrl.Result{RequeueAfter: minHealthyPeriod - since}, nil
+			}
+			retryCount = last.RetryCount + 1
+		}
+		if strategy != nil && since < strategy.RetryPeriod.Duration {
+			conditions.MarkFalse(kcp, controlplanev1beta2.MachinesHealthyCondition, controlplanev1beta2.RemediationInProgressReason, clusterv1.ConditionSeverityInfo,
+				"Waiting for the retry period to pass before remediating machine %s", target.Name)
+			return ctrl.Result{RequeueAfter: strategy.RetryPeriod.Duration - since}, nil
+		}
+	}
+
+	log.Info("Remediating unhealthy control plane machine", "machine", target.Name, "retryCount", retryCount)
+	if err := r.Delete(ctx, target); err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, fmt.Errorf("failed to delete control plane machine %s for remediation: %w", target.Name, err)
+	}
+	r.eventf(kcp, target, corev1.EventTypeWarning, controlplanev1beta2.RemediationTriggeredEvent,
+		"Deleting unhealthy control plane machine %s to replace it (retry %d)", target.Name, retryCount)
+	kcp.Status.LastRemediation = &controlplanev1beta2.LastRemediationStatus{
+		Machine:    target.Name,
+		Timestamp:  metav1.Now(),
+		RetryCount: retryCount,
+	}
+	conditions.MarkFalse(kcp, controlplanev1beta2.MachinesHealthyCondition, controlplanev1beta2.RemediationInProgressReason, clusterv1.ConditionSeverityInfo,
+		"Replacing unhealthy machine %s", target.Name)
+	return ctrl.Result{}, nil
+}
+
+// eventf emits an event on kcp and, when set, on the control plane machine
+// the event is about.
+func (r *KairosControlPlaneReconciler) eventf(kcp *controlplanev1beta2.KairosControlPlane, machine *clusterv1.Machine, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.recorder == nil {
+		return
+	}
+	message := redact.String(fmt.Sprintf(messageFmt, args...))
+	r.recorder.Event(kcp, eventType, reason, message)
+	if machine != nil {
+		r.recorder.Event(machine, eventType, reason, message)
+	}
+}
+
+// machineNeedsRemediation reports whether machine was marked for remediation,
+// either directly with the remediate-machine annotation or by a
+// MachineHealthCheck through the OwnerRemediated condition.
+func machineNeedsRemediation(machine *clusterv1.Machine) bool {
+	if _, ok := machine.Annotations[clusterv1.RemediateMachineAnnotation]; ok {
+		return true
+	}
+	return conditions.IsFalse(machine, clusterv1.MachineOwnerRemediatedCondition)
+}
+
+// machineUpToDate reports whether machine was created from the current spec.
+// Machines created before the spec hash annotation existed are only compared
+// by version. Machines that booted from bootstrap data which was re-rendered
+// since are never up to date.
+func (r *KairosControlPlaneReconciler) machineUpToDate(machine *clusterv1.Machine, kcp *controlplanev1beta2.KairosControlPlane) bool {
+	if machine.Spec.Version == nil || *machine.Spec.Version != kcp.Spec.Version {
+		return false
+	}
+	if _, ok := machine.Annotations[bootstrapv1beta2.BootstrapDataOutdatedAnnotation]; ok {
+		return false
+	}
+	hash, ok := machine.Annotations[controlplanev1beta2.MachineSpecHashAnnotation]
+	return !ok || hash == machineSpecHash(kcp)
+}
+
+// machineUpgradableInPlace reports whether spec.version is the only spec
+// field machine is outdated in, so an in-place upgrade brings it up to date.
+// Machines created before the spec hash annotation existed only have their
+// version to compare.
+func machineUpgradableInPlace(machine *clusterv1.Machine, kcp *controlplanev1beta2.KairosControlPlane) bool {
+	if machine.Spec.Version == nil {
+		return false
+	}
+	if _, ok := machine.Annotations[bootstrapv1beta2.BootstrapDataOutdatedAnnotation]; ok {
+		return false
+	}
+	hash, ok := machine.Annotations[controlplanev1beta2.MachineSpecHashAnnotation]
+	if !ok {
+		return true
+	}
+	created := kcp.DeepCopy()
+	created.Spec.Version = *machine.Spec.Version
+	return hash == machineSpecHash(created)
 }
 
-func (r *KairosControlPlaneReconciler) createInfrastructureMachine(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster, machineName string) (client.Object, error) {
+// machineSpecHash hashes the spec fields that require replacing a machine when
+// they change. Fields reconciled in place, like replicas or osImage, are left out.
+// An inline kairosConfigSpec, the controlPlaneEndpoint, certSANs, cni and
+// addons are included only when set, so the hashes of machines created before those
+// fields existed stay the same.
+func machineSpecHash(kcp *controlplanev1beta2.KairosControlPlane) string {
+	distribution := kcp.Spec.Distribution
+	if distribution == "" {
+		distribution = "k0s"
+	}
 	infraRef := kcp.Spec.MachineTemplate.InfrastructureRef
+	h := sha256.New()
+	for _, v := range []string{
+		kcp.Spec.Version,
+		distribution,
+		infraRef.APIVersion,
+		infraRef.Kind,
+		infraRef.Name,
+		kcp.Spec.KairosConfigTemplate.Name,
+	} {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	// Marshalling a struct is deterministic, and map keys are sorted
+	if kcp.Spec.KairosConfigSpec != nil {
+		config, _ := json.Marshal(kcp.Spec.KairosConfigSpec)
+		h.Write(config)
+	}
+	if kcp.Spec.ControlPlaneEndpoint != nil {
+		endpoint, _ := json.Marshal(kcp.Spec.ControlPlaneEndpoint)
+		h.Write(endpoint)
+	}
+	if len(kcp.Spec.CertSANs) > 0 {
+		sans, _ := json.Marshal(kcp.Spec.CertSANs)
+		h.Write(sans)
+	}
+	if kcp.Spec.CNI != "" {
+		h.Write([]byte("cni=" + kcp.Spec.CNI))
+	}
+	if len(kcp.Spec.Addons) > 0 {
+		addons, _ := json.Marshal(kcp.Spec.Addons)
+		h.Write(addons)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
 
-	// Prepare labels and annotations
-	labels := map[string]string{
-		clusterv1.ClusterNameLabel:         cluster.Name,
-		clusterv1.MachineControlPlaneLabel: "",
+// applyControlPlaneEndpoint adds the configuration announcing the virtual IP of
+// kcp.spec.controlPlaneEndpoint to the KairosConfig spec of a control plane
+// machine: keepalived control plane load balancing on k0s, a kube-vip static
+// pod on k3s.
+func applyControlPlaneEndpoint(spec *bootstrapv1beta2.KairosConfigSpec, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) error {
+	endpoint := kcp.Spec.ControlPlaneEndpoint
+	if endpoint == nil {
+		return nil
 	}
-	// Merge with template metadata labels
-	if kcp.Spec.MachineTemplate.Metadata.Labels != nil {
-		for k, v := range kcp.Spec.MachineTemplate.Metadata.Labels {
-			labels[k] = v
-		}
+	vip := bootstrap.VirtualIP{
+		Host:         endpoint.Host,
+		Port:         endpoint.Port,
+		Interface:    endpoint.Interface,
+		KubeVIPImage: endpoint.KubeVIPImage,
+	}
+	if vip.Port == 0 {
+		vip.Port = 6443
 	}
 
-	annotations := map[string]string{}
-	// Merge with template metadata annotations
-	if kcp.Spec.MachineTemplate.Metadata.Annotations != nil {
-		for k, v := range kcp.Spec.MachineTemplate.Metadata.Annotations {
-			annotations[k] = v
+	if spec.Distribution == "k3s" {
+		manifest, err := bootstrap.KubeVIPManifest(vip)
+		if err != nil {
+			return err
+		}
+		spec.Files = append(spec.Files, bootstrapv1beta2.File{
+			Path:        bootstrap.KubeVIPManifestPath,
+			Content:     manifest,
+			Permissions: "0600",
+		})
+		spec.ExtraInstallArgs = append(spec.ExtraInstallArgs, "--tls-san="+vip.Host)
+		if vip.Port != 6443 {
+			spec.ExtraInstallArgs = append(spec.ExtraInstallArgs, fmt.Sprintf("--https-listen-port=%d", vip.Port))
 		}
+		return nil
 	}
 
-	// Clone infrastructure machine using the helper
-	infraMachine, err := infrastructure.CloneInfrastructureMachine(
-		ctx,
-		r.Client,
-		r.Scheme,
-		infraRef,
-		machineName,
-		kcp.Namespace,
-		labels,
-		annotations,
-	)
+	// Every node of the cluster derives the same VRRP identity, while other
+	// clusters on the network most likely get another router ID
+	sum := sha256.Sum256([]byte(cluster.Namespace + "/" + cluster.Name))
+	vip.AuthPass = hex.EncodeToString(sum[:4])
+	vip.VirtualRouterID = int32(sum[4])%255 + 1
+	k0sConfig, err := bootstrap.K0sVirtualIPConfig(spec.K0sConfig, vip)
 	if err != nil {
-		return nil, fmt.Errorf("failed to clone infrastructure machine: %w", err)
+		return err
 	}
+	spec.K0sConfig = k0sConfig
+	return nil
+}
 
-	// Set owner reference
-	if err := controllerutil.SetControllerReference(kcp, infraMachine, r.Scheme); err != nil {
-		return nil, fmt.Errorf("failed to set controller reference: %w", err)
+// applyExternalEndpoint marks spec as using the externally managed Cluster
+// endpoint and adds its host to the API server certificate.
+func applyExternalEndpoint(spec *bootstrapv1beta2.KairosConfigSpec, cluster *clusterv1.Cluster) {
+	spec.ExternalControlPlaneEndpoint = true
+	host := cluster.Spec.ControlPlaneEndpoint.Host
+	if spec.Distribution == "k3s" {
+		spec.ExtraInstallArgs = append(spec.ExtraInstallArgs, "--tls-san="+host)
+		return
 	}
-
-	// Create the infrastructure machine
-	if err := r.Create(ctx, infraMachine); err != nil {
-		if !apierrors.IsAlreadyExists(err) {
-			return nil, fmt.Errorf("failed to create infrastructure machine: %w", err)
-		}
-		// Machine already exists, get it
-		if err := r.Get(ctx, types.NamespacedName{Name: machineName, Namespace: kcp.Namespace}, infraMachine); err != nil {
-			return nil, fmt.Errorf("failed to get existing infrastructure machine: %w", err)
-		}
+	k0sConfig := spec.K0sConfig.DeepCopy()
+	if k0sConfig == nil {
+		k0sConfig = &bootstrapv1beta2.K0sConfig{}
 	}
-
-	log.Info("Created infrastructure machine", "kind", infraRef.Kind, "name", machineName)
-	return infraMachine, nil
+	if !slices.Contains(k0sConfig.APISANs, host) {
+		k0sConfig.APISANs = append(k0sConfig.APISANs, host)
+	}
+	spec.K0sConfig = k0sConfig
 }
 
-func (r *KairosControlPlaneReconciler) getControlPlaneMachines(ctx context.Context, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) ([]*clusterv1.Machine, error) {
-	selector := labels.SelectorFromSet(map[string]string{
-		clusterv1.ClusterNameLabel:         cluster.Name,
-		clusterv1.MachineControlPlaneLabel: "",
-	})
+// applyCNI sets the k0s network provider selected by kcp.spec.cni on the
+// KairosConfig spec of a control plane machine. Cilium is not a k0s network
+// provider: k0s deploys none and installs the Cilium chart through its Helm
+// extension instead, unless the configuration already has a chart named
+// cilium.
+func applyCNI(spec *bootstrapv1beta2.KairosConfigSpec, cni string) {
+	if cni == "" {
+		return
+	}
+	k0sConfig := spec.K0sConfig.DeepCopy()
+	if k0sConfig == nil {
+		k0sConfig = &bootstrapv1beta2.K0sConfig{}
+	}
+	spec.K0sConfig = k0sConfig
+	if cni != "cilium" {
+		k0sConfig.NetworkProvider = cni
+		return
+	}
 
-	machineList := &clusterv1.MachineList{}
-	if err := r.List(ctx, machineList, client.InNamespace(kcp.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
-		return nil, err
+	k0sConfig.NetworkProvider = "custom"
+	if k0sConfig.Extensions == nil {
+		k0sConfig.Extensions = &bootstrapv1beta2.K0sExtensions{}
+	}
+	if k0sConfig.Extensions.Helm == nil {
+		k0sConfig.Extensions.Helm = &bootstrapv1beta2.K0sHelmExtension{}
 	}
+	helm := k0sConfig.Extensions.Helm
+	if slices.ContainsFunc(helm.Charts, func(c bootstrapv1beta2.K0sHelmChart) bool { return c.Name == ciliumChart.Name }) {
+		return
+	}
+	if !slices.ContainsFunc(helm.Repositories, func(r bootstrapv1beta2.K0sHelmRepository) bool { return r.Name == ciliumRepository.Name }) {
+		helm.Repositories = append(helm.Repositories, ciliumRepository)
+	}
+	helm.Charts = append(helm.Charts, ciliumChart)
+}
 
-	machines := make([]*clusterv1.Machine, 0, len(machineList.Items))
-	for i := range machineList.Items {
-		machine := &machineList.Items[i]
-		// Check if this machine is owned by this KCP
-		ownerRef := metav1.GetControllerOf(machine)
-		if ownerRef != nil && ownerRef.Kind == "KairosControlPlane" && ownerRef.Name == kcp.Name {
-			machines = append(machines, machine)
+// applyAddons adds kcp.spec.addons to the manifests of the KairosConfig spec
+// of a control plane machine, replacing manifests written to the same file.
+func applyAddons(spec *bootstrapv1beta2.KairosConfigSpec, addons []bootstrapv1beta2.Manifest) {
+	if len(addons) == 0 {
+		return
+	}
+	manifests := make([]bootstrapv1beta2.Manifest, 0, len(spec.Manifests)+len(addons))
+	for _, m := range spec.Manifests {
+		if !slices.ContainsFunc(addons, func(a bootstrapv1beta2.Manifest) bool { return a.Name == m.Name && a.File == m.File }) {
+			manifests = append(manifests, m)
 		}
 	}
-
-	return machines, nil
+	for _, a := range addons {
+		manifests = append(manifests, *a.DeepCopy())
+	}
+	spec.Manifests = manifests
 }
 
-func (r *KairosControlPlaneReconciler) machineMatchesVersion(machine *clusterv1.Machine, desiredVersion string) bool {
-	if machine.Spec.Version == nil {
-		return false
+// lowestMachineVersion returns the lowest Kubernetes version of the machines,
+// or nil when none of them has a parseable version. Build metadata such as
+// "+k0s.1" is compared too.
+func lowestMachineVersion(machines []*clusterv1.Machine) *string {
+	var lowest *string
+	for _, machine := range machines {
+		if machine.Spec.Version == nil {
+			continue
+		}
+		v, err := semver.ParseTolerant(*machine.Spec.Version)
+		if err != nil {
+			continue
+		}
+		if lowest != nil {
+			current, _ := semver.ParseTolerant(*lowest)
+			if version.Compare(v, current, version.WithBuildTags()) >= 0 {
+				continue
+			}
+		}
+		lowest = machine.Spec.Version
+	}
+	if lowest == nil {
+		return nil
 	}
-	return *machine.Spec.Version == desiredVersion
+	v := *lowest
+	return &v
 }
 
+// nextMachineIndex returns the index of the next control plane Machine, one
+// above the highest index in use. The index is read from the machine index
+// annotation, or from the name of Machines created before it existed.
 func (r *KairosControlPlaneReconciler) nextMachineIndex(machines []*clusterv1.Machine, kcpName string) int32 {
 	prefix := fmt.Sprintf("%s-", kcpName)
 	maxIndex := int32(-1)
 	for _, machine := range machines {
+		if value, ok := machine.Annotations[controlplanev1beta2.MachineIndexAnnotation]; ok {
+			if idx, err := strconv.ParseInt(value, 10, 32); err == nil && int32(idx) > maxIndex {
+				maxIndex = int32(idx)
+			}
+			continue
+		}
 		if !strings.HasPrefix(machine.Name, prefix) {
 			continue
 		}
@@ -706,6 +2427,9 @@ func (r *KairosControlPlaneReconciler) nextMachineIndex(machines []*clusterv1.Ma
 	return maxIndex + 1
 }
 
+// selectMachineForDeletion picks the machine to remove on scale down. Machines
+// that never joined or wait for remediation go first, as removing them does
+// not reduce the number of healthy etcd members.
 func (r *KairosControlPlaneReconciler) selectMachineForDeletion(machines []*clusterv1.Machine, outdatedMachines []*clusterv1.Machine) *clusterv1.Machine {
 	if len(outdatedMachines) > 0 {
 		return outdatedMachines[0]
@@ -713,8 +2437,78 @@ func (r *KairosControlPlaneReconciler) selectMachineForDeletion(machines []*clus
 	if len(machines) == 0 {
 		return nil
 	}
-	// Delete the newest machine for scale down to reduce churn on older nodes
-	return machines[len(machines)-1]
+	for i := len(machines) - 1; i >= 0; i-- {
+		if machines[i].Status.NodeRef == nil || machineNeedsRemediation(machines[i]) {
+			return machines[i]
+		}
+	}
+	// Delete the newest machine of the most crowded failure domain, which
+	// keeps the machines spread and reduces churn on older nodes
+	candidates := machinesInLargestFailureDomain(machines)
+	return candidates[len(candidates)-1]
+}
+
+// failureDomains returns the failure domains control plane machines are
+// spread across: spec.failureDomains, or else the control plane failure
+// domains of the Cluster in name order.
+func failureDomains(kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) []string {
+	if len(kcp.Spec.FailureDomains) > 0 {
+		return kcp.Spec.FailureDomains
+	}
+	var domains []string
+	for name, domain := range cluster.Status.FailureDomains {
+		if domain.ControlPlane {
+			domains = append(domains, name)
+		}
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// nextFailureDomain returns the failure domain for a new control plane
+// machine, or nil when there are none. It picks the domain with the fewest
+// up-to-date machines, so a rollout spreads the new machines, then the one
+// with the fewest machines overall, then the first one.
+func (r *KairosControlPlaneReconciler) nextFailureDomain(kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster, machines []*clusterv1.Machine) *string {
+	domains := failureDomains(kcp, cluster)
+	if len(domains) == 0 {
+		return nil
+	}
+	all, upToDate := map[string]int{}, map[string]int{}
+	for _, machine := range machines {
+		if !machine.DeletionTimestamp.IsZero() || machine.Spec.FailureDomain == nil {
+			continue
+		}
+		all[*machine.Spec.FailureDomain]++
+		if r.machineUpToDate(machine, kcp) {
+			upToDate[*machine.Spec.FailureDomain]++
+		}
+	}
+	next := domains[0]
+	for _, domain := range domains[1:] {
+		if upToDate[domain] < upToDate[next] || (upToDate[domain] == upToDate[next] && all[domain] < all[next]) {
+			next = domain
+		}
+	}
+	return &next
+}
+
+// machinesInLargestFailureDomain returns the machines, oldest first, of the
+// failure domain with the most machines. Machines without a failure domain
+// count as one domain of their own.
+func machinesInLargestFailureDomain(machines []*clusterv1.Machine) []*clusterv1.Machine {
+	byDomain := map[string][]*clusterv1.Machine{}
+	for _, machine := range machines {
+		domain := ptr.Deref(machine.Spec.FailureDomain, "")
+		byDomain[domain] = append(byDomain[domain], machine)
+	}
+	largest := ""
+	for domain, domainMachines := range byDomain {
+		if n := len(byDomain[largest]); len(domainMachines) > n || (len(domainMachines) == n && domain < largest) {
+			largest = domain
+		}
+	}
+	return byDomain[largest]
 }
 
 func (r *KairosControlPlaneReconciler) updateStatus(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) error {
@@ -735,8 +2529,8 @@ func (r *KairosControlPlaneReconciler) updateStatus(ctx context.Context, log log
 			readyReplicas++
 		}
 
-		// Check if machine is updated (matches desired version)
-		if machine.Spec.Version != nil && *machine.Spec.Version == kcp.Spec.Version {
+		// Check if machine is updated (matches the current spec)
+		if r.machineUpToDate(machine, kcp) {
 			updatedReplicas++
 		}
 
@@ -756,19 +2550,15 @@ func (r *KairosControlPlaneReconciler) updateStatus(ctx context.Context, log log
 	kcp.Status.ReadyReplicas = readyReplicas
 	kcp.Status.UpdatedReplicas = updatedReplicas
 	kcp.Status.UnavailableReplicas = unavailableReplicas
-
-	selector := labels.SelectorFromSet(map[string]string{
-		clusterv1.ClusterNameLabel:         cluster.Name,
-		clusterv1.MachineControlPlaneLabel: "",
-	})
-	kcp.Status.Selector = selector.String()
+	kcp.Status.Version = lowestMachineVersion(machines)
 
 	// Log status field updates for debugging
 	log.Info("Updated control plane status fields",
 		"readyReplicas", readyReplicas,
 		"updatedReplicas", updatedReplicas,
 		"unavailableReplicas", unavailableReplicas,
-		"replicas", kcp.Status.Replicas)
+		"replicas", kcp.Status.Replicas,
+		"version", kcp.Status.Version)
 
 	// Mark as initialized if we have at least one ready replica (NodeRef set)
 	// OR if kubeconfig exists (control plane is functional even without NodeRef)
@@ -781,14 +2571,14 @@ func (r *KairosControlPlaneReconciler) updateStatus(ctx context.Context, log log
 	} else if readyReplicas == 0 && !kcp.Status.Initialized {
 		// Check if kubeconfig exists - if so, mark as initialized even without NodeRef
 		// This allows the Machine controller to connect and set NodeRef
-		secretName := fmt.Sprintf("%s-kubeconfig", cluster.Name)
+		secretName := kubeconfigsecret.Name(cluster.Name)
 		secretKey := types.NamespacedName{
 			Name:      secretName,
 			Namespace: cluster.Namespace,
 		}
 		secret := &corev1.Secret{}
-		if err := r.Get(ctx, secretKey, secret); err == nil {
-			if kubeconfig, ok := secret.Data["value"]; ok && len(kubeconfig) > 0 {
+		if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, secret); err == nil {
+			if kubeconfig, ok := secret.Data[kubeconfigsecret.DataKey]; ok && len(kubeconfig) > 0 {
 				kcp.Status.Initialized = true
 				log.Info("Control plane initialized (kubeconfig exists, NodeRef pending)", "readyReplicas", readyReplicas)
 			}
@@ -815,23 +2605,35 @@ func (r *KairosControlPlaneReconciler) updateStatus(ctx context.Context, log log
 // reconcileKubeconfig retrieves the kubeconfig from the control plane node and stores it in a secret
 func (r *KairosControlPlaneReconciler) reconcileKubeconfig(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) error {
 	// Check if kubeconfig secret already exists
-	secretName := fmt.Sprintf("%s-kubeconfig", cluster.Name)
+	secretName := kubeconfigsecret.Name(cluster.Name)
 	secretKey := types.NamespacedName{
 		Name:      secretName,
 		Namespace: cluster.Namespace,
 	}
 
+	// renew is set when an existing kubeconfig is replaced because its client
+	// certificate is about to expire
+	var renew *corev1.Secret
 	existingSecret := &corev1.Secret{}
-	if err := r.Get(ctx, secretKey, existingSecret); err == nil {
+	if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, existingSecret); err == nil {
 		// Secret already exists, check if it's valid
-		if kubeconfig, ok := existingSecret.Data["value"]; ok && len(kubeconfig) > 0 {
+		if kubeconfig, ok := existingSecret.Data[kubeconfigsecret.DataKey]; ok && len(kubeconfig) > 0 {
 			if updated, err := r.ensureKubeconfigSecretMetadata(ctx, existingSecret, cluster); err != nil {
 				return err
 			} else if updated {
 				log.Info("Updated kubeconfig secret metadata", "secret", secretName)
 			}
-			log.V(4).Info("Kubeconfig secret already exists", "secret", secretName)
-			return nil
+			renewAt, err := kubeconfigsecret.RenewalTime(kubeconfig, kubeconfigRenewBefore)
+			if err != nil {
+				log.Error(err, "Failed to read kubeconfig client certificate expiry", "secret", secretName)
+			}
+			if renewAt.IsZero() || time.Now().Before(renewAt) {
+				log.V(4).Info("Kubeconfig secret already exists", "secret", secretName)
+				return nil
+			}
+			log.Info("Kubeconfig client certificate is due for renewal, refreshing from a control plane node",
+				"secret", secretName, "renewAt", renewAt)
+			renew = existingSecret
 		}
 	}
 
@@ -878,10 +2680,10 @@ func (r *KairosControlPlaneReconciler) reconcileKubeconfig(ctx context.Context,
 	}
 
 	// For KubeVirt, skip SSH once kubeconfig is present to avoid timeouts in bridged setups.
-	if isKubevirtMachine(readyMachine) {
+	if isKubevirtMachine(readyMachine) && renew == nil {
 		kubevirtSecret := &corev1.Secret{}
-		if err := r.Get(ctx, secretKey, kubevirtSecret); err == nil {
-			if kubeconfig, ok := kubevirtSecret.Data["value"]; ok && len(kubeconfig) > 0 {
+		if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, kubevirtSecret); err == nil {
+			if kubeconfig, ok := kubevirtSecret.Data[kubeconfigsecret.DataKey]; ok && len(kubeconfig) > 0 {
 				log.Info("Skipping SSH kubeconfig retrieval for KubeVirt; secret already exists", "secret", secretName)
 				return nil
 			}
@@ -901,7 +2703,11 @@ func (r *KairosControlPlaneReconciler) reconcileKubeconfig(ctx context.Context,
 	// For KubeVirt, prefer LB endpoint over node IP (canonical reachable address from management cluster)
 	if kcp.Spec.Distribution == "k3s" {
 		serverHost := ""
-		if isKubevirtControlPlane(kcp) {
+		serverPort := int32(6443)
+		if kcp.Spec.ExternalManagedEndpoint && cluster.Spec.ControlPlaneEndpoint.IsValid() {
+			serverHost = cluster.Spec.ControlPlaneEndpoint.Host
+			serverPort = cluster.Spec.ControlPlaneEndpoint.Port
+		} else if isKubevirtControlPlane(kcp) {
 			lbHost, lbPort, lbErr := r.getControlPlaneLBEndpoint(ctx, log, cluster)
 			if lbErr == nil && lbHost != "" && lbPort != 0 {
 				serverHost = lbHost
@@ -916,7 +2722,7 @@ func (r *KairosControlPlaneReconciler) reconcileKubeconfig(ctx context.Context,
 			}
 		}
 		if serverHost != "" {
-			updated, updateErr := updateKubeconfigServerToNodeIP(kubeconfig, serverHost, 6443)
+			updated, updateErr := updateKubeconfigServerToNodeIP(kubeconfig, serverHost, serverPort)
 			if updateErr != nil {
 				log.Error(updateErr, "Failed to update kubeconfig server for k3s, using as-is")
 			} else {
@@ -926,6 +2732,25 @@ func (r *KairosControlPlaneReconciler) reconcileKubeconfig(ctx context.Context,
 		}
 	}
 
+	if renew != nil {
+		renewAt, err := kubeconfigsecret.RenewalTime(kubeconfig, kubeconfigRenewBefore)
+		if err != nil {
+			return fmt.Errorf("failed to read client certificate expiry of retrieved kubeconfig: %w", err)
+		}
+		if !renewAt.IsZero() && !time.Now().Before(renewAt) {
+			// Keep the current secret; the node has not renewed its certificate yet
+			log.Info("Control plane node still serves a kubeconfig due for renewal, will retry",
+				"machine", readyMachine.Name, "retryAfter", kubeconfigRenewRetry)
+			return nil
+		}
+		renew.Data[kubeconfigsecret.DataKey] = kubeconfig
+		if err := r.Update(ctx, renew); err != nil {
+			return fmt.Errorf("failed to update kubeconfig secret: %w", err)
+		}
+		log.Info("Kubeconfig secret renewed", "secret", secretName)
+		return nil
+	}
+
 	// Create or update the kubeconfig secret
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -934,7 +2759,7 @@ func (r *KairosControlPlaneReconciler) reconcileKubeconfig(ctx context.Context,
 		},
 		Type: clusterv1.ClusterSecretType,
 		Data: map[string][]byte{
-			"value": kubeconfig,
+			kubeconfigsecret.DataKey: kubeconfig,
 		},
 	}
 	if _, err := r.ensureKubeconfigSecretMetadata(ctx, secret, cluster); err != nil {
@@ -956,35 +2781,57 @@ func (r *KairosControlPlaneReconciler) reconcileKubeconfig(ctx context.Context,
 	return nil
 }
 
+// kubeconfigRenewalResult requeues for the renewal of the kubeconfig secret's
+// client certificate, so it is refreshed even if nothing else changes.
+func (r *KairosControlPlaneReconciler) kubeconfigRenewalResult(ctx context.Context, cluster *clusterv1.Cluster) ctrl.Result {
+	secret := &corev1.Secret{}
+	secretKey := types.NamespacedName{Namespace: cluster.Namespace, Name: kubeconfigsecret.Name(cluster.Name)}
+	if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, secret); err != nil {
+		return ctrl.Result{}
+	}
+	renewAt, err := kubeconfigsecret.RenewalTime(secret.Data[kubeconfigsecret.DataKey], kubeconfigRenewBefore)
+	if err != nil || renewAt.IsZero() {
+		return ctrl.Result{}
+	}
+	if wait := time.Until(renewAt); wait > kubeconfigRenewRetry {
+		return ctrl.Result{RequeueAfter: wait}
+	}
+	return ctrl.Result{RequeueAfter: kubeconfigRenewRetry}
+}
+
 // ensureProviderIDOnNodes patches workload cluster Nodes with the Machine providerID.
 // This avoids relying on in-VM scripts and allows Machine-to-NodeRef matching.
 func (r *KairosControlPlaneReconciler) ensureProviderIDOnNodes(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) error {
-	secretName := fmt.Sprintf("%s-kubeconfig", cluster.Name)
+	secretName := kubeconfigsecret.Name(cluster.Name)
 	secretKey := types.NamespacedName{
 		Name:      secretName,
 		Namespace: cluster.Namespace,
 	}
 	secret := &corev1.Secret{}
-	if err := r.Get(ctx, secretKey, secret); err != nil {
+	if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, secret); err != nil {
 		if apierrors.IsNotFound(err) {
 			return nil
 		}
 		return err
 	}
 
-	kubeconfig, ok := secret.Data["value"]
+	kubeconfig, ok := secret.Data[kubeconfigsecret.DataKey]
 	if !ok || len(kubeconfig) == 0 {
 		return nil
 	}
 
-	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
-	if err != nil {
-		return fmt.Errorf("failed to build workload rest config: %w", err)
-	}
-
-	workloadClient, err := client.New(restConfig, client.Options{Scheme: r.Scheme})
+	workloadClient, err := r.getWorkloadClient(ctx, cluster, kubeconfig)
 	if err != nil {
-		return fmt.Errorf("failed to create workload client: %w", err)
+		if errors.Is(err, remote.ErrClusterLocked) {
+			log.V(4).Info("Workload cluster client is being created by another worker, skipping providerID patch")
+			return nil
+		}
+		var backoff *workload.BackoffError
+		if errors.As(err, &backoff) {
+			log.V(4).Info("Workload cluster unreachable, skipping providerID patch", "error", err.Error())
+			return nil
+		}
+		return err
 	}
 
 	machines, err := r.getControlPlaneMachines(ctx, kcp, cluster)
@@ -1086,6 +2933,29 @@ func (r *KairosControlPlaneReconciler) ensureProviderIDOnNodes(ctx context.Conte
 	return nil
 }
 
+// getWorkloadClient returns a client for the workload cluster, preferring the
+// shared Tracker over building a new client from the kubeconfig.
+func (r *KairosControlPlaneReconciler) getWorkloadClient(ctx context.Context, cluster *clusterv1.Cluster, kubeconfig []byte) (client.Client, error) {
+	if r.Tracker != nil {
+		workloadClient, err := r.ConnectionBackoff.GetClient(ctx, r.Tracker, util.ObjectKey(cluster))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get workload client: %w", err)
+		}
+		return workloadClient, nil
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build workload rest config: %w", err)
+	}
+
+	workloadClient, err := client.New(restConfig, client.Options{Scheme: r.Scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workload client: %w", err)
+	}
+	return workloadClient, nil
+}
+
 // getInfrastructureProviderID attempts to retrieve providerID from the infrastructure machine object.
 func (r *KairosControlPlaneReconciler) getInfrastructureProviderID(ctx context.Context, log logr.Logger, machine *clusterv1.Machine) string {
 	if machine == nil || machine.Spec.InfrastructureRef.Kind == "" {
@@ -1149,6 +3019,21 @@ func (r *KairosControlPlaneReconciler) getInfrastructureProviderID(ctx context.C
 		if providerID, found, err := unstructured.NestedString(dockerMachine.Object, "spec", "providerID"); err == nil && found && providerID != "" {
 			return providerID
 		}
+	case "Metal3Machine":
+		metal3Machine := &unstructured.Unstructured{}
+		metal3Machine.SetGroupVersionKind(machine.Spec.InfrastructureRef.GroupVersionKind())
+		metal3MachineKey := types.NamespacedName{
+			Name:      machine.Spec.InfrastructureRef.Name,
+			Namespace: machine.Spec.InfrastructureRef.Namespace,
+		}
+		if err := r.Get(ctx, metal3MachineKey, metal3Machine); err != nil {
+			log.V(4).Info("Failed to get Metal3Machine for providerID", "machine", machine.Name, "error", err)
+			return ""
+		}
+		// CAPM3 sets metal3://<namespace>/<host>/<metal3machine> once a host is associated
+		if providerID, found, err := unstructured.NestedString(metal3Machine.Object, "spec", "providerID"); err == nil && found && providerID != "" {
+			return providerID
+		}
 	}
 
 	return ""
@@ -1197,7 +3082,8 @@ func (r *KairosControlPlaneReconciler) retrieveKubeconfigFromNode(ctx context.Co
 }
 
 // getNodeIP retrieves the node IP from the infrastructure provider.
-// Supports CAPD (DockerMachine), CAPV (VSphereMachine/VSphereVM), and CAPK (KubevirtMachine).
+// Supports CAPD (DockerMachine), CAPV (VSphereMachine/VSphereVM), CAPK (KubevirtMachine)
+// and CAPM3 (Metal3Machine).
 func (r *KairosControlPlaneReconciler) getNodeIP(ctx context.Context, log logr.Logger, machine *clusterv1.Machine) (string, error) {
 	switch machine.Spec.InfrastructureRef.Kind {
 	case "VSphereMachine":
@@ -1275,6 +3161,21 @@ func (r *KairosControlPlaneReconciler) getNodeIP(ctx context.Context, log logr.L
 			return ip, nil
 		}
 		return "", fmt.Errorf("no IP address found in DockerMachine status")
+	case "Metal3Machine":
+		// CAPM3 copies the addresses BMO inspected on the BareMetalHost
+		metal3Machine := &unstructured.Unstructured{}
+		metal3Machine.SetGroupVersionKind(machine.Spec.InfrastructureRef.GroupVersionKind())
+		metal3MachineKey := types.NamespacedName{
+			Name:      machine.Spec.InfrastructureRef.Name,
+			Namespace: machine.Spec.InfrastructureRef.Namespace,
+		}
+		if err := r.Get(ctx, metal3MachineKey, metal3Machine); err != nil {
+			return "", fmt.Errorf("failed to get Metal3Machine: %w", err)
+		}
+		if ip := r.extractIPFromUnstructured(metal3Machine); ip != "" {
+			return ip, nil
+		}
+		return "", fmt.Errorf("no IP address found in Metal3Machine status")
 	default:
 		return "", fmt.Errorf("unsupported infrastructure provider: %s", machine.Spec.InfrastructureRef.Kind)
 	}
@@ -1444,8 +3345,29 @@ func (r *KairosControlPlaneReconciler) getSSHCredentials(ctx context.Context, lo
 		userName = "kairos" // Default
 	}
 
-	userPassword := kairosConfig.Spec.UserPassword
-	if userPassword == "" {
+	var userPassword string
+	switch {
+	case kairosConfig.Spec.UserPasswordSecretRef != nil:
+		ref := kairosConfig.Spec.UserPasswordSecretRef
+		secret := &corev1.Secret{}
+		secretKey := types.NamespacedName{Namespace: kairosConfig.Namespace, Name: ref.Name}
+		if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, secret); err != nil {
+			return "", "", fmt.Errorf("failed to get user password secret %s/%s: %w", secretKey.Namespace, secretKey.Name, err)
+		}
+		key := ref.Key
+		if key == "" {
+			key = "password"
+		}
+		userPassword = strings.TrimRight(string(secret.Data[key]), "\r\n")
+		if userPassword == "" {
+			return "", "", fmt.Errorf("user password secret %s/%s does not contain key '%s'", secretKey.Namespace, secretKey.Name, key)
+		}
+	case kairosConfig.Spec.PasswdHash != "":
+		// A hash cannot be used to log in; SSH needs the plain text password
+		return "", "", fmt.Errorf("KairosConfig %s only sets passwdHash, SSH access needs userPassword or userPasswordSecretRef", kairosConfig.Name)
+	case kairosConfig.Spec.UserPassword != "":
+		userPassword = kairosConfig.Spec.UserPassword
+	default:
 		userPassword = "kairos" // Default
 	}
 
@@ -1602,6 +3524,85 @@ func (r *KairosControlPlaneReconciler) executeK0sKubeconfigCommand(ctx context.C
 	return nil, fmt.Errorf("k0s kubeconfig command returned empty output")
 }
 
+// createK0sControllerToken creates a k0s controller join token on the node of
+// machine over SSH.
+func (r *KairosControlPlaneReconciler) createK0sControllerToken(ctx context.Context, log logr.Logger, machine *clusterv1.Machine, cluster *clusterv1.Cluster) ([]byte, error) {
+	out, err := r.runK0sCommand(ctx, log, machine, cluster, fmt.Sprintf("k0s token create --role=controller --expiry=%s", controllerJoinTokenTTL))
+	if err != nil {
+		return nil, err
+	}
+	token := bytes.TrimSpace(out)
+	if len(token) == 0 {
+		return nil, fmt.Errorf("k0s token command returned an empty token")
+	}
+	return token, nil
+}
+
+// runK0sCommand runs a k0s command on the node of machine over SSH once k0s is
+// ready there and returns its standard output. The command is tried with and
+// without sudo.
+func (r *KairosControlPlaneReconciler) runK0sCommand(ctx context.Context, log logr.Logger, machine *clusterv1.Machine, cluster *clusterv1.Cluster, command string) ([]byte, error) {
+	nodeIP, nodeErr := r.getNodeIP(ctx, log, machine)
+	sshHost, err := resolveSSHHost(machine, cluster, nodeIP, nodeErr, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve SSH host: %w", err)
+	}
+	userName, userPassword, err := r.getSSHCredentials(ctx, log, machine)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SSH credentials: %w", err)
+	}
+
+	config := &ssh.ClientConfig{
+		User: userName,
+		Auth: []ssh.AuthMethod{
+			ssh.Password(userPassword),
+		},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // In production, use proper host key verification
+		Timeout:         30 * time.Second,
+	}
+	client, err := ssh.Dial("tcp", net.JoinHostPort(sshHost, "22"), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial SSH: %w", err)
+	}
+	defer client.Close()
+
+	if err := r.checkK0sReady(ctx, log, client); err != nil {
+		return nil, fmt.Errorf("k0s is not ready yet: %w", err)
+	}
+
+	var lastErr error
+	for _, cmd := range []string{"sudo -n " + command, "sudo " + command, command} {
+		session, err := client.NewSession()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SSH session: %w", err)
+		}
+		var stdout, stderr bytes.Buffer
+		session.Stdout = &stdout
+		session.Stderr = &stderr
+		err = session.Run(cmd)
+		session.Close()
+		if err != nil {
+			lastErr = fmt.Errorf("command '%s' failed: %w, stderr: %s", cmd, err, redact.String(stderr.String()))
+			log.V(4).Info("k0s command failed, trying next", "command", cmd, "error", err)
+			continue
+		}
+		return stdout.Bytes(), nil
+	}
+	return nil, fmt.Errorf("all k0s commands failed, last error: %w", lastErr)
+}
+
+// sshEtcdExecutor runs the k0s etcd commands of the etcd package on control
+// plane nodes over SSH.
+type sshEtcdExecutor struct {
+	r       *KairosControlPlaneReconciler
+	log     logr.Logger
+	cluster *clusterv1.Cluster
+}
+
+func (e *sshEtcdExecutor) Run(ctx context.Context, machine *clusterv1.Machine, command string) ([]byte, error) {
+	return e.r.runK0sCommand(ctx, e.log, machine, e.cluster, command)
+}
+
 // checkK3sReady checks if k3s is ready by verifying the service is running and k3s.yaml exists
 func (r *KairosControlPlaneReconciler) checkK3sReady(ctx context.Context, log logr.Logger, client *ssh.Client) error {
 	// Check if k3s service is running (k3s server uses "k3s" service name)
@@ -1712,7 +3713,7 @@ func (r *KairosControlPlaneReconciler) executeK3sKubeconfigCommand(ctx context.C
 					return output, nil
 				}
 			} else {
-				lastErr = fmt.Errorf("command '%s' failed: %w, stderr: %s", cmd, err, stderr.String())
+				lastErr = fmt.Errorf("command '%s' failed: %w, stderr: %s", cmd, err, redact.String(stderr.String()))
 				log.V(4).Info("Command failed, trying next", "command", cmd, "error", err)
 			}
 		case <-commandCtx.Done():
@@ -1730,7 +3731,7 @@ func (r *KairosControlPlaneReconciler) executeK3sKubeconfigCommand(ctx context.C
 
 // updateClusterStatus updates the Cluster status based on control plane readiness
 func (r *KairosControlPlaneReconciler) updateClusterStatus(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane, cluster *clusterv1.Cluster) error {
-	secretName := fmt.Sprintf("%s-kubeconfig", cluster.Name)
+	secretName := kubeconfigsecret.Name(cluster.Name)
 	secretKey := types.NamespacedName{
 		Name:      secretName,
 		Namespace: cluster.Namespace,
@@ -1754,7 +3755,9 @@ func (r *KairosControlPlaneReconciler) updateClusterStatus(ctx context.Context,
 	currentPort := clusterToPatch.Spec.ControlPlaneEndpoint.Port
 	log.V(4).Info("Checking controlPlaneEndpoint", "cluster", clusterToPatch.Name, "currentHost", currentHost, "currentPort", currentPort)
 
-	if isKubevirtControlPlane(kcp) {
+	if kcp.Spec.ExternalManagedEndpoint {
+		log.V(4).Info("controlPlaneEndpoint is managed externally", "currentHost", currentHost, "currentPort", currentPort)
+	} else if isKubevirtControlPlane(kcp) {
 		lbHost, lbPort, err := r.getControlPlaneLBEndpoint(ctx, log, clusterToPatch)
 		if err != nil && !apierrors.IsNotFound(err) {
 			log.Error(err, "Failed to get control plane LoadBalancer endpoint", "cluster", clusterToPatch.Name)
@@ -1838,7 +3841,7 @@ func (r *KairosControlPlaneReconciler) updateClusterStatus(ctx context.Context,
 
 	// Check if kubeconfig secret exists - early exit if not, but controlPlaneEndpoint already updated above
 	secret := &corev1.Secret{}
-	if err := r.Get(ctx, secretKey, secret); err != nil {
+	if err := cachefilter.GetSecret(ctx, r.Client, r.APIReader, secretKey, secret); err != nil {
 		if apierrors.IsNotFound(err) {
 			log.V(4).Info("Kubeconfig secret not found, skipping cluster status update", "secret", secretName)
 			// Still update spec if controlPlaneEndpoint was set (e.g. from LB)
@@ -1860,8 +3863,17 @@ func (r *KairosControlPlaneReconciler) updateClusterStatus(ctx context.Context,
 
 	log.Info("updateClusterStatus called", "cluster", cluster.Name, "kubeconfigExists", true)
 
-	// For KubeVirt, ensure kubeconfig server URL matches LoadBalancer endpoint (only when secret exists)
-	if isKubevirtControlPlane(kcp) {
+	// Clients reach the control plane through the externally managed endpoint
+	// when one is configured
+	if kcp.Spec.ExternalManagedEndpoint {
+		updated, err := r.ensureKubeconfigServer(ctx, log, secret, currentHost, currentPort)
+		if err != nil {
+			log.Error(err, "Failed to ensure kubeconfig server", "cluster", clusterToPatch.Name)
+		} else if updated {
+			log.Info("Updated kubeconfig server to match the externally managed endpoint", "cluster", clusterToPatch.Name, "host", currentHost, "port", currentPort)
+		}
+	} else if isKubevirtControlPlane(kcp) {
+		// For KubeVirt, ensure kubeconfig server URL matches LoadBalancer endpoint (only when secret exists)
 		lbHost, lbPort, err := r.getControlPlaneLBEndpoint(ctx, log, clusterToPatch)
 		if err != nil && !apierrors.IsNotFound(err) {
 			log.Error(err, "Failed to get control plane LoadBalancer endpoint for kubeconfig", "cluster", clusterToPatch.Name)
@@ -2092,7 +4104,7 @@ func (r *KairosControlPlaneReconciler) ensureKubeconfigServer(ctx context.Contex
 		return false, nil
 	}
 
-	kubeconfig, ok := secret.Data["value"]
+	kubeconfig, ok := secret.Data[kubeconfigsecret.DataKey]
 	if !ok || len(kubeconfig) == 0 {
 		return false, nil
 	}
@@ -2141,7 +4153,7 @@ func (r *KairosControlPlaneReconciler) ensureKubeconfigServer(ctx context.Contex
 	}
 
 	secretCopy := secret.DeepCopy()
-	secretCopy.Data["value"] = out
+	secretCopy.Data[kubeconfigsecret.DataKey] = out
 	if err := r.Update(ctx, secretCopy); err != nil {
 		return false, fmt.Errorf("failed to update kubeconfig secret: %w", err)
 	}
@@ -2165,8 +4177,8 @@ func (r *KairosControlPlaneReconciler) ensureKubeconfigSecretMetadata(ctx contex
 
 	if cluster != nil {
 		ownerRef := metav1.OwnerReference{
-			APIVersion: cluster.APIVersion,
-			Kind:       cluster.Kind,
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Cluster",
 			Name:       cluster.Name,
 			UID:        cluster.UID,
 			Controller: func() *bool { b := true; return &b }(),
@@ -2247,15 +4259,45 @@ func (r *KairosControlPlaneReconciler) triggerClusterReconciliation(ctx context.
 }
 
 func (r *KairosControlPlaneReconciler) reconcileDelete(ctx context.Context, log logr.Logger, kcp *controlplanev1beta2.KairosControlPlane) (ctrl.Result, error) {
+	// Nobody drains nodes or removes etcd members once the control plane is
+	// gone, so the machines must not wait for it
+	machineList := &clusterv1.MachineList{}
+	if err := r.List(ctx, machineList, client.InNamespace(kcp.Namespace), client.HasLabels{clusterv1.MachineControlPlaneLabel}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list control plane machines: %w", err)
+	}
+	for i := range machineList.Items {
+		machine := &machineList.Items[i]
+		ownerRef := metav1.GetControllerOf(machine)
+		if ownerRef == nil || ownerRef.Kind != "KairosControlPlane" || ownerRef.Name != kcp.Name {
+			continue
+		}
+		if _, ok := machine.Annotations[drain.PreDrainHookAnnotation]; ok {
+			if err := r.releaseDrainHook(ctx, machine, false); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to remove pre-drain hook from machine %s: %w", machine.Name, err)
+			}
+			log.Info("Removed pre-drain hook from control plane machine", "machine", machine.Name)
+		}
+		if _, ok := machine.Annotations[etcd.PreTerminateHookAnnotation]; !ok {
+			continue
+		}
+		if err := r.releaseEtcdHook(ctx, machine); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to remove pre-terminate hook from machine %s: %w", machine.Name, err)
+		}
+		log.Info("Removed etcd pre-terminate hook from control plane machine", "machine", machine.Name)
+	}
+
 	// Remove finalizer
 	controllerutil.RemoveFinalizer(kcp, controlplanev1beta2.KairosControlPlaneFinalizer)
 	return ctrl.Result{}, r.Update(ctx, kcp)
 }
 
-// SetupWithManager sets up the controller with the Manager.
-func (r *KairosControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+// SetupWithManager sets up the controller with the Manager. options set the
+// number of concurrent reconciles and the work queue rate limiter.
+func (r *KairosControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	r.recorder = mgr.GetEventRecorderFor("kairoscontrolplane-controller")
+	c, err := ctrl.NewControllerManagedBy(mgr).
 		For(&controlplanev1beta2.KairosControlPlane{}).
+		WithOptions(options).
 		Watches(
 			&clusterv1.Machine{},
 			handler.EnqueueRequestsFromMapFunc(r.machineToKairosControlPlane),
@@ -2271,7 +4313,12 @@ func (r *KairosControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error
 				return strings.HasSuffix(obj.GetName(), "-kubeconfig")
 			})),
 		).
-		Complete(r)
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.controller = c
+	return nil
 }
 
 // machineToKairosControlPlane maps a Machine to its KairosControlPlane
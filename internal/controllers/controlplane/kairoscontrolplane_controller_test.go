@@ -18,22 +18,45 @@ package controlplane
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
+	"fmt"
+	"math/big"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
 	controlplanev1beta2 "github.com/kairos-io/kairos-capi/api/controlplane/v1beta2"
+	"github.com/kairos-io/kairos-capi/internal/bootstrap"
+	"github.com/kairos-io/kairos-capi/internal/drain"
+	"github.com/kairos-io/kairos-capi/internal/etcd"
+	"github.com/kairos-io/kairos-capi/internal/osupgrade"
 )
 
 func TestCreateControlPlaneMachine_SingleNode(t *testing.T) {
@@ -232,6 +255,239 @@ func TestCreateControlPlaneMachine_MultiNode(t *testing.T) {
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(kairosConfig.Spec.SingleNode).To(BeFalse())
 	g.Expect(kairosConfig.Spec.Role).To(Equal("control-plane"))
+	g.Expect(kairosConfig.Spec.ControllerTokenSecretRef).To(BeNil())
+
+	// Later machines join the first one with the controller token
+	g.Expect(reconciler.createControlPlaneMachine(context.Background(), log.Log, kcp, cluster, 1)).To(Succeed())
+	g.Expect(client.Get(context.Background(), types.NamespacedName{
+		Name:      "test-kcp-1",
+		Namespace: "default",
+	}, kairosConfig)).To(Succeed())
+	g.Expect(kairosConfig.Spec.ControllerTokenSecretRef).NotTo(BeNil())
+	g.Expect(kairosConfig.Spec.ControllerTokenSecretRef.Name).To(Equal("test-cluster-k0s-controller-token"))
+
+	// Multi-node k0s machines wait for their etcd member to be removed on deletion
+	machine := &clusterv1.Machine{}
+	g.Expect(client.Get(context.Background(), types.NamespacedName{
+		Name:      "test-kcp-1",
+		Namespace: "default",
+	}, machine)).To(Succeed())
+	g.Expect(machine.Annotations).To(HaveKey(etcd.PreTerminateHookAnnotation))
+}
+
+func TestCreateControlPlaneMachine_InlineKairosConfigSpec(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-kcp",
+			Namespace: "default",
+		},
+		Spec: controlplanev1beta2.KairosControlPlaneSpec{
+			Replicas:     ptr.To(int32(1)),
+			Version:      "v1.30.0+k3s.0",
+			Distribution: "k3s",
+			MachineTemplate: controlplanev1beta2.KairosControlPlaneMachineTemplate{
+				InfrastructureRef: corev1.ObjectReference{
+					APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+					Kind:       "DockerMachineTemplate",
+					Name:       "test-template",
+					Namespace:  "default",
+				},
+			},
+			// No KairosConfigTemplate exists: the inline spec is used instead
+			KairosConfigSpec: &bootstrapv1beta2.KairosConfigSpec{
+				Role:     "worker",
+				UserName: "admin",
+				CertSANs: []string{"192.0.2.10"},
+			},
+			CertSANs: []string{"api.example.com", "192.0.2.10"},
+		},
+	}
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+
+	infraTemplate := &unstructured.Unstructured{}
+	infraTemplate.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "DockerMachineTemplate",
+	})
+	infraTemplate.SetName("test-template")
+	infraTemplate.SetNamespace("default")
+	infraTemplate.Object["spec"] = map[string]interface{}{
+		"template": map[string]interface{}{
+			"spec": map[string]interface{}{},
+		},
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(infraTemplate).Build()
+	reconciler := &KairosControlPlaneReconciler{
+		Client: client,
+		Scheme: scheme,
+	}
+
+	g.Expect(reconciler.createControlPlaneMachine(context.Background(), log.Log, kcp, cluster, 0)).To(Succeed())
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{}
+	g.Expect(client.Get(context.Background(), types.NamespacedName{
+		Name:      "test-kcp-0",
+		Namespace: "default",
+	}, kairosConfig)).To(Succeed())
+	g.Expect(kairosConfig.Spec.UserName).To(Equal("admin"))
+	g.Expect(kairosConfig.Spec.Role).To(Equal("control-plane"))
+	g.Expect(kairosConfig.Spec.Distribution).To(Equal("k3s"))
+	g.Expect(kairosConfig.Spec.KubernetesVersion).To(Equal("v1.30.0+k3s.0"))
+	g.Expect(kairosConfig.Spec.SingleNode).To(BeTrue())
+	g.Expect(kairosConfig.Spec.CertSANs).To(Equal([]string{"192.0.2.10", "api.example.com"}))
+
+	// Changing the inline spec or the SANs rolls the machines out
+	hash := machineSpecHash(kcp)
+	kcp.Spec.KairosConfigSpec.UserName = "kairos"
+	g.Expect(machineSpecHash(kcp)).NotTo(Equal(hash))
+	hash = machineSpecHash(kcp)
+	kcp.Spec.CertSANs = append(kcp.Spec.CertSANs, "api2.example.com")
+	g.Expect(machineSpecHash(kcp)).NotTo(Equal(hash))
+}
+
+func TestCreateControlPlaneMachine_MachineNamingStrategy(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-kcp",
+			Namespace: "default",
+		},
+		Spec: controlplanev1beta2.KairosControlPlaneSpec{
+			Replicas: ptr.To(int32(3)),
+			Version:  "v1.30.0+k0s.0",
+			MachineTemplate: controlplanev1beta2.KairosControlPlaneMachineTemplate{
+				InfrastructureRef: corev1.ObjectReference{
+					APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+					Kind:       "DockerMachineTemplate",
+					Name:       "test-template",
+					Namespace:  "default",
+				},
+			},
+			KairosConfigSpec:      &bootstrapv1beta2.KairosConfigSpec{},
+			MachineNamingStrategy: &controlplanev1beta2.MachineNamingStrategy{Template: "{{ .cluster.name }}-cp{{ .index }}"},
+		},
+	}
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+
+	infraTemplate := &unstructured.Unstructured{}
+	infraTemplate.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "DockerMachineTemplate",
+	})
+	infraTemplate.SetName("test-template")
+	infraTemplate.SetNamespace("default")
+	infraTemplate.Object["spec"] = map[string]interface{}{
+		"template": map[string]interface{}{
+			"spec": map[string]interface{}{},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(infraTemplate).Build()
+	reconciler := &KairosControlPlaneReconciler{
+		Client: c,
+		Scheme: scheme,
+	}
+
+	g.Expect(reconciler.createControlPlaneMachine(context.Background(), log.Log, kcp, cluster, 4)).To(Succeed())
+
+	machine := &clusterv1.Machine{}
+	g.Expect(c.Get(context.Background(), types.NamespacedName{Name: "test-cluster-cp4", Namespace: "default"}, machine)).To(Succeed())
+	g.Expect(machine.Annotations).To(HaveKeyWithValue(controlplanev1beta2.MachineIndexAnnotation, "4"))
+	g.Expect(machine.Spec.Bootstrap.ConfigRef.Name).To(Equal("test-cluster-cp4"))
+	g.Expect(machine.Spec.InfrastructureRef.Name).To(Equal("test-cluster-cp4"))
+
+	// The index is read back from the annotation, the name no longer carries it
+	legacy := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "test-kcp-2"}}
+	g.Expect(reconciler.nextMachineIndex([]*clusterv1.Machine{legacy, machine}, kcp.Name)).To(Equal(int32(5)))
+}
+
+func TestCreateControlPlaneMachine_CleansUpOnFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	replicas := int32(1)
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-kcp",
+			Namespace: "default",
+		},
+		Spec: controlplanev1beta2.KairosControlPlaneSpec{
+			Replicas: &replicas,
+			Version:  "v1.30.0+k0s.0",
+			MachineTemplate: controlplanev1beta2.KairosControlPlaneMachineTemplate{
+				// The referenced template does not exist, so cloning fails.
+				InfrastructureRef: corev1.ObjectReference{
+					APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+					Kind:       "DockerMachineTemplate",
+					Name:       "missing-template",
+					Namespace:  "default",
+				},
+			},
+		},
+	}
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).Build()
+	reconciler := &KairosControlPlaneReconciler{
+		Client: client,
+		Scheme: scheme,
+	}
+
+	// A cancelled context simulates a manager shutdown; the creation sequence
+	// must still run (and roll back) on its detached context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := reconciler.createControlPlaneMachine(ctx, log.Log, kcp, cluster, 0)
+	g.Expect(err).To(HaveOccurred())
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{}
+	err = client.Get(context.Background(), types.NamespacedName{
+		Name:      "test-kcp-0",
+		Namespace: "default",
+	}, kairosConfig)
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
 }
 
 func TestResolveSSHHost_KubevirtFallback(t *testing.T) {
@@ -338,3 +594,2036 @@ func TestGetNodeIP_KubevirtVMIFallback(t *testing.T) {
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(ip).To(Equal("192.168.100.10"))
 }
+
+func TestEnsureProviderIDOnNodes_UsesTrackerClient(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-kcp",
+			Namespace: "default",
+			UID:       "kcp-uid",
+		},
+	}
+	controller := true
+	providerID := "kubevirt://test-kcp-0"
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-kcp-0",
+			Namespace: "default",
+			Labels: map[string]string{
+				clusterv1.ClusterNameLabel:         "test-cluster",
+				clusterv1.MachineControlPlaneLabel: "",
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: controlplanev1beta2.GroupVersion.String(),
+				Kind:       "KairosControlPlane",
+				Name:       "test-kcp",
+				UID:        "kcp-uid",
+				Controller: &controller,
+			}},
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: "test-cluster",
+			ProviderID:  &providerID,
+		},
+		Status: clusterv1.MachineStatus{
+			Addresses: clusterv1.MachineAddresses{{Type: clusterv1.MachineInternalIP, Address: "10.0.0.10"}},
+		},
+	}
+	// The kubeconfig content is never parsed when a tracker is configured.
+	kubeconfigSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster-kubeconfig",
+			Namespace: "default",
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+		},
+		Data: map[string][]byte{"value": []byte("not-a-kubeconfig")},
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-0"},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.10"}},
+		},
+	}
+
+	mgmtClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, kcp, machine, kubeconfigSecret).Build()
+	workloadClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(node).Build()
+
+	reconciler := &KairosControlPlaneReconciler{
+		Client:  mgmtClient,
+		Scheme:  scheme,
+		Tracker: remote.NewTestClusterCacheTracker(log.Log, mgmtClient, workloadClient, scheme, client.ObjectKeyFromObject(cluster)),
+	}
+
+	g.Expect(reconciler.ensureProviderIDOnNodes(context.Background(), log.Log, kcp, cluster)).To(Succeed())
+
+	updated := &corev1.Node{}
+	g.Expect(workloadClient.Get(context.Background(), types.NamespacedName{Name: "node-0"}, updated)).To(Succeed())
+	g.Expect(updated.Spec.ProviderID).To(Equal(providerID))
+}
+
+func TestReconcileNodesReadyCondition(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-kcp",
+			Namespace: "default",
+			UID:       "kcp-uid",
+		},
+		Status: controlplanev1beta2.KairosControlPlaneStatus{
+			Initialized: true,
+		},
+	}
+	controller := true
+	newMachine := func(name, providerID string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels: map[string]string{
+					clusterv1.ClusterNameLabel:         "test-cluster",
+					clusterv1.MachineControlPlaneLabel: "",
+				},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: controlplanev1beta2.GroupVersion.String(),
+					Kind:       "KairosControlPlane",
+					Name:       "test-kcp",
+					UID:        "kcp-uid",
+					Controller: &controller,
+				}},
+			},
+			Spec: clusterv1.MachineSpec{
+				ClusterName: "test-cluster",
+				ProviderID:  &providerID,
+			},
+		}
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-0"},
+		Spec:       corev1.NodeSpec{ProviderID: "kubevirt://test-kcp-0"},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+
+	mgmtClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		cluster, kcp,
+		newMachine("test-kcp-0", "kubevirt://test-kcp-0"),
+		newMachine("test-kcp-1", "kubevirt://test-kcp-1"),
+	).Build()
+	workloadClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(node).Build()
+
+	reconciler := &KairosControlPlaneReconciler{
+		Client: mgmtClient,
+		Scheme: scheme,
+		Tracker: remote.NewTestClusterCacheTracker(log.Log, mgmtClient, workloadClient, scheme,
+			client.ObjectKeyFromObject(cluster), "kairoscontrolplane-watchNodes"),
+	}
+
+	result := reconciler.reconcileNodesReadyCondition(context.Background(), log.Log, kcp, cluster)
+	g.Expect(result.IsZero()).To(BeTrue())
+	g.Expect(conditions.IsFalse(kcp, controlplanev1beta2.NodesReadyCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(kcp, controlplanev1beta2.NodesReadyCondition)).To(Equal(controlplanev1beta2.WaitingForNodesReason))
+	g.Expect(conditions.GetMessage(kcp, controlplanev1beta2.NodesReadyCondition)).To(Equal("1 of 2 control plane nodes ready; waiting to join: test-kcp-1"))
+	g.Expect(kcp.Status.MachineNodes).To(HaveLen(2))
+	g.Expect(kcp.Status.MachineNodes[0].Machine).To(Equal("test-kcp-0"))
+	g.Expect(kcp.Status.MachineNodes[0].NodeName).To(Equal("node-0"))
+	g.Expect(kcp.Status.MachineNodes[0].ProviderID).To(Equal("kubevirt://test-kcp-0"))
+	for _, conditionType := range []string{controlplanev1beta2.NodeRegisteredCondition, controlplanev1beta2.NodeProviderIDSetCondition, controlplanev1beta2.NodeReadyCondition} {
+		g.Expect(meta.IsStatusConditionTrue(kcp.Status.MachineNodes[0].Conditions, conditionType)).To(BeTrue(), conditionType)
+	}
+	g.Expect(kcp.Status.MachineNodes[1].Machine).To(Equal("test-kcp-1"))
+	g.Expect(kcp.Status.MachineNodes[1].NodeName).To(BeEmpty())
+	registered := meta.FindStatusCondition(kcp.Status.MachineNodes[1].Conditions, controlplanev1beta2.NodeRegisteredCondition)
+	g.Expect(registered.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(registered.Reason).To(Equal(controlplanev1beta2.NodeNotRegisteredReason))
+	transitioned := kcp.Status.MachineNodes[0].Conditions[0].LastTransitionTime
+
+	joined := node.DeepCopy()
+	joined.Name = "node-1"
+	joined.Spec.ProviderID = "kubevirt://test-kcp-1"
+	joined.ResourceVersion = ""
+	g.Expect(workloadClient.Create(context.Background(), joined)).To(Succeed())
+
+	reconciler.reconcileNodesReadyCondition(context.Background(), log.Log, kcp, cluster)
+	g.Expect(conditions.IsTrue(kcp, controlplanev1beta2.NodesReadyCondition)).To(BeTrue())
+	g.Expect(meta.IsStatusConditionTrue(kcp.Status.MachineNodes[1].Conditions, controlplanev1beta2.NodeRegisteredCondition)).To(BeTrue())
+	g.Expect(kcp.Status.MachineNodes[0].Conditions[0].LastTransitionTime).To(Equal(transitioned))
+
+	// A node found through the machine's nodeRef must carry its providerID
+	machine := &clusterv1.Machine{}
+	g.Expect(mgmtClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "test-kcp-1"}, machine)).To(Succeed())
+	machine.Status.NodeRef = &corev1.ObjectReference{Kind: "Node", Name: "node-1"}
+	g.Expect(mgmtClient.Update(context.Background(), machine)).To(Succeed())
+	g.Expect(workloadClient.Get(context.Background(), client.ObjectKey{Name: "node-1"}, joined)).To(Succeed())
+	joined.Spec.ProviderID = ""
+	g.Expect(workloadClient.Update(context.Background(), joined)).To(Succeed())
+
+	reconciler.reconcileNodesReadyCondition(context.Background(), log.Log, kcp, cluster)
+	g.Expect(conditions.GetReason(kcp, controlplanev1beta2.NodesReadyCondition)).To(Equal(controlplanev1beta2.ProviderIDMissingReason))
+	g.Expect(conditions.GetMessage(kcp, controlplanev1beta2.NodesReadyCondition)).To(Equal("control plane nodes without the providerID of their machine: test-kcp-1"))
+	providerIDSet := meta.FindStatusCondition(kcp.Status.MachineNodes[1].Conditions, controlplanev1beta2.NodeProviderIDSetCondition)
+	g.Expect(providerIDSet.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(providerIDSet.Reason).To(Equal(controlplanev1beta2.ProviderIDMissingReason))
+}
+
+func TestMachineNodeStatus(t *testing.T) {
+	g := NewWithT(t)
+
+	providerID := "kubevirt://cp-0"
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "cp-0"},
+		Spec:       clusterv1.MachineSpec{ProviderID: &providerID},
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-0"},
+		Spec:       corev1.NodeSpec{ProviderID: "kubevirt://other"},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionFalse}},
+		},
+	}
+
+	status := machineNodeStatus(machine, node, nil)
+	g.Expect(status.NodeName).To(Equal("node-0"))
+	g.Expect(meta.IsStatusConditionTrue(status.Conditions, controlplanev1beta2.NodeRegisteredCondition)).To(BeTrue())
+	providerIDSet := meta.FindStatusCondition(status.Conditions, controlplanev1beta2.NodeProviderIDSetCondition)
+	g.Expect(providerIDSet.Reason).To(Equal(controlplanev1beta2.ProviderIDMismatchReason))
+	g.Expect(providerIDSet.Message).To(Equal("The node has providerID kubevirt://other, the machine kubevirt://cp-0"))
+	nodeReady := meta.FindStatusCondition(status.Conditions, controlplanev1beta2.NodeReadyCondition)
+	g.Expect(nodeReady.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(nodeReady.Reason).To(Equal(controlplanev1beta2.NodeNotReadyReason))
+}
+
+func TestReconcileOSUpgrade(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-kcp",
+			Namespace: "default",
+		},
+		Spec: controlplanev1beta2.KairosControlPlaneSpec{
+			OSImage:   "quay.io/kairos/ubuntu",
+			OSVersion: "24.04-standard-amd64-generic-v3.5.0-k0sv1.33.1+k0s.0",
+		},
+		Status: controlplanev1beta2.KairosControlPlaneStatus{
+			Initialized: true,
+		},
+	}
+	mgmtClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, kcp).Build()
+
+	newReconciler := func(workloadClient client.Client) *KairosControlPlaneReconciler {
+		return &KairosControlPlaneReconciler{
+			Client: mgmtClient,
+			Scheme: scheme,
+			Tracker: remote.NewTestClusterCacheTracker(log.Log, mgmtClient, workloadClient, scheme,
+				client.ObjectKeyFromObject(cluster)),
+		}
+	}
+
+	// Without the operator and without a manifest to install it, report it
+	withoutOperator := fake.NewClientBuilder().WithScheme(scheme).Build()
+	newReconciler(withoutOperator).reconcileOSUpgrade(context.Background(), log.Log, kcp, cluster)
+	g.Expect(conditions.IsFalse(kcp, controlplanev1beta2.OSImageUpToDateCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(kcp, controlplanev1beta2.OSImageUpToDateCondition)).To(Equal(controlplanev1beta2.KairosOperatorNotInstalledReason))
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(osupgrade.NodeOpUpgradeGVK, meta.RESTScopeNamespace)
+	workloadClient := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).Build()
+	reconciler := newReconciler(workloadClient)
+
+	result := reconciler.reconcileOSUpgrade(context.Background(), log.Log, kcp, cluster)
+	g.Expect(result.RequeueAfter).NotTo(BeZero())
+	g.Expect(conditions.GetReason(kcp, controlplanev1beta2.OSImageUpToDateCondition)).To(Equal(controlplanev1beta2.OSUpgradeInProgressReason))
+
+	image := "quay.io/kairos/ubuntu:24.04-standard-amd64-generic-v3.5.0-k0sv1.33.1+k0s.0"
+	upgrade := &unstructured.Unstructured{}
+	upgrade.SetGroupVersionKind(osupgrade.NodeOpUpgradeGVK)
+	key := client.ObjectKey{Namespace: osupgrade.Namespace, Name: osupgrade.Name("test-kcp", image)}
+	g.Expect(workloadClient.Get(context.Background(), key, upgrade)).To(Succeed())
+	specImage, _, _ := unstructured.NestedString(upgrade.Object, "spec", "image")
+	g.Expect(specImage).To(Equal(image))
+
+	g.Expect(unstructured.SetNestedField(upgrade.Object, "Completed", "status", "phase")).To(Succeed())
+	g.Expect(workloadClient.Update(context.Background(), upgrade)).To(Succeed())
+
+	result = reconciler.reconcileOSUpgrade(context.Background(), log.Log, kcp, cluster)
+	g.Expect(result.IsZero()).To(BeTrue())
+	g.Expect(kcp.Status.OSImage).To(Equal(image))
+	g.Expect(conditions.IsTrue(kcp, controlplanev1beta2.OSImageUpToDateCondition)).To(BeTrue())
+}
+
+func TestReconcileOSUpgradePlan(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-kcp",
+			Namespace: "default",
+		},
+		Spec: controlplanev1beta2.KairosControlPlaneSpec{
+			OSUpgrade: &bootstrapv1beta2.OSUpgrade{
+				Image:   "quay.io/kairos/ubuntu",
+				Version: "v3.5.0",
+			},
+		},
+		Status: controlplanev1beta2.KairosControlPlaneStatus{
+			Initialized: true,
+		},
+	}
+	mgmtClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, kcp).Build()
+
+	newReconciler := func(workloadClient client.Client) *KairosControlPlaneReconciler {
+		return &KairosControlPlaneReconciler{
+			Client: mgmtClient,
+			Scheme: scheme,
+			Tracker: remote.NewTestClusterCacheTracker(log.Log, mgmtClient, workloadClient, scheme,
+				client.ObjectKeyFromObject(cluster)),
+		}
+	}
+
+	// Without the system-upgrade-controller and without a manifest to install it, report it
+	withoutSUC := fake.NewClientBuilder().WithScheme(scheme).Build()
+	newReconciler(withoutSUC).reconcileOSUpgradePlan(context.Background(), log.Log, kcp, cluster)
+	g.Expect(conditions.IsFalse(kcp, controlplanev1beta2.OSUpgradeCompletedCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(kcp, controlplanev1beta2.OSUpgradeCompletedCondition)).To(Equal(controlplanev1beta2.SystemUpgradeControllerNotInstalledReason))
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(osupgrade.PlanGVK, meta.RESTScopeNamespace)
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   "cp-0",
+		Labels: map[string]string{"node-role.kubernetes.io/control-plane": "true"},
+	}}
+	workloadClient := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(node).Build()
+	reconciler := newReconciler(workloadClient)
+
+	result := reconciler.reconcileOSUpgradePlan(context.Background(), log.Log, kcp, cluster)
+	g.Expect(result.RequeueAfter).NotTo(BeZero())
+	g.Expect(conditions.GetReason(kcp, controlplanev1beta2.OSUpgradeCompletedCondition)).To(Equal(controlplanev1beta2.OSUpgradeInProgressReason))
+
+	plan := &unstructured.Unstructured{}
+	plan.SetGroupVersionKind(osupgrade.PlanGVK)
+	key := client.ObjectKey{Namespace: osupgrade.PlanNamespace, Name: osupgrade.Name("test-kcp", "quay.io/kairos/ubuntu:v3.5.0")}
+	g.Expect(workloadClient.Get(context.Background(), key, plan)).To(Succeed())
+	g.Expect(kcp.Status.OSUpgrade.Plan).To(Equal(key.Name))
+
+	// The system-upgrade-controller labels the node with the Plan hash once done
+	g.Expect(unstructured.SetNestedField(plan.Object, "abc", "status", "latestHash")).To(Succeed())
+	g.Expect(workloadClient.Update(context.Background(), plan)).To(Succeed())
+	result = reconciler.reconcileOSUpgradePlan(context.Background(), log.Log, kcp, cluster)
+	g.Expect(result.RequeueAfter).NotTo(BeZero())
+	g.Expect(kcp.Status.OSUpgrade.Nodes).To(Equal(int32(1)))
+	g.Expect(kcp.Status.OSUpgrade.UpdatedNodes).To(BeZero())
+
+	node.Labels["plan.upgrade.cattle.io/"+key.Name] = "abc"
+	g.Expect(workloadClient.Update(context.Background(), node)).To(Succeed())
+	result = reconciler.reconcileOSUpgradePlan(context.Background(), log.Log, kcp, cluster)
+	g.Expect(result.IsZero()).To(BeTrue())
+	g.Expect(kcp.Status.OSUpgrade.UpdatedNodes).To(Equal(int32(1)))
+	g.Expect(conditions.IsTrue(kcp, controlplanev1beta2.OSUpgradeCompletedCondition)).To(BeTrue())
+
+	kcp.Spec.OSUpgrade = nil
+	reconciler.reconcileOSUpgradePlan(context.Background(), log.Log, kcp, cluster)
+	g.Expect(conditions.Get(kcp, controlplanev1beta2.OSUpgradeCompletedCondition)).To(BeNil())
+	g.Expect(kcp.Status.OSUpgrade).To(BeNil())
+}
+
+func TestReconcileMachines_RollingUpdate(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	replicas := int32(3)
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-kcp",
+			Namespace: "default",
+			UID:       "kcp-uid",
+		},
+		Spec: controlplanev1beta2.KairosControlPlaneSpec{
+			Replicas: &replicas,
+			Version:  "v1.30.0+k0s.0",
+			MachineTemplate: controlplanev1beta2.KairosControlPlaneMachineTemplate{
+				InfrastructureRef: corev1.ObjectReference{
+					APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+					Kind:       "DockerMachineTemplate",
+					Name:       "test-template-v2",
+					Namespace:  "default",
+				},
+			},
+			KairosConfigTemplate: controlplanev1beta2.KairosConfigTemplateReference{
+				Name: "test-config-template",
+			},
+		},
+	}
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+	template := &bootstrapv1beta2.KairosConfigTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-config-template",
+			Namespace: "default",
+		},
+	}
+	infraTemplate := &unstructured.Unstructured{}
+	infraTemplate.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "DockerMachineTemplate",
+	})
+	infraTemplate.SetName("test-template-v2")
+	infraTemplate.SetNamespace("default")
+	infraTemplate.Object["spec"] = map[string]interface{}{
+		"template": map[string]interface{}{
+			"spec": map[string]interface{}{},
+		},
+	}
+
+	// Three ready machines created from an older machine template
+	controller := true
+	objs := []client.Object{template, infraTemplate}
+	for i := 0; i < 3; i++ {
+		version := kcp.Spec.Version
+		objs = append(objs, &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              fmt.Sprintf("test-kcp-%d", i),
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(time.Duration(i-10) * time.Minute)),
+				Labels: map[string]string{
+					clusterv1.ClusterNameLabel:         "test-cluster",
+					clusterv1.MachineControlPlaneLabel: "",
+				},
+				Annotations: map[string]string{
+					controlplanev1beta2.MachineSpecHashAnnotation: "outdated",
+				},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: controlplanev1beta2.GroupVersion.String(),
+					Kind:       "KairosControlPlane",
+					Name:       "test-kcp",
+					UID:        "kcp-uid",
+					Controller: &controller,
+				}},
+			},
+			Spec: clusterv1.MachineSpec{ClusterName: "test-cluster", Version: &version},
+			Status: clusterv1.MachineStatus{
+				NodeRef: &corev1.ObjectReference{Name: fmt.Sprintf("node-%d", i)},
+			},
+		})
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithStatusSubresource(&clusterv1.Machine{}).Build()
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &KairosControlPlaneReconciler{Client: c, Scheme: scheme, recorder: recorder}
+
+	listMachines := func() []*clusterv1.Machine {
+		machines, err := reconciler.getControlPlaneMachines(context.Background(), kcp, cluster)
+		g.Expect(err).NotTo(HaveOccurred())
+		return machines
+	}
+
+	// Surge: one up-to-date machine is added
+	g.Expect(reconciler.reconcileMachines(context.Background(), log.Log, kcp, cluster)).To(Succeed())
+	g.Expect(listMachines()).To(HaveLen(4))
+	g.Expect(conditions.GetReason(kcp, controlplanev1beta2.MachinesSpecUpToDateCondition)).To(Equal(controlplanev1beta2.RollingUpdateInProgressReason))
+	g.Expect(recorder.Events).To(Receive(HavePrefix("Normal " + controlplanev1beta2.UpgradeStartedEvent)))
+
+	newMachine := &clusterv1.Machine{}
+	g.Expect(c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "test-kcp-3"}, newMachine)).To(Succeed())
+	g.Expect(reconciler.machineUpToDate(newMachine, kcp)).To(BeTrue())
+	outdated := newMachine.DeepCopy()
+	outdated.Annotations[bootstrapv1beta2.BootstrapDataOutdatedAnnotation] = "2"
+	g.Expect(reconciler.machineUpToDate(outdated, kcp)).To(BeFalse())
+
+	// The old machines stay until the new one has joined
+	g.Expect(reconciler.reconcileMachines(context.Background(), log.Log, kcp, cluster)).To(Succeed())
+	g.Expect(listMachines()).To(HaveLen(4))
+	g.Expect(recorder.Events).NotTo(Receive())
+
+	newMachine.Status.NodeRef = &corev1.ObjectReference{Name: "node-3"}
+	g.Expect(c.Status().Update(context.Background(), newMachine)).To(Succeed())
+
+	// Then the oldest outdated machine is deleted
+	g.Expect(reconciler.reconcileMachines(context.Background(), log.Log, kcp, cluster)).To(Succeed())
+	machines := listMachines()
+	g.Expect(machines).To(HaveLen(3))
+	for _, machine := range machines {
+		g.Expect(machine.Name).NotTo(Equal("test-kcp-0"))
+	}
+}
+
+func TestReconcileInPlaceUpgrade(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	replicas := int32(1)
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-kcp",
+			Namespace: "default",
+			UID:       "kcp-uid",
+		},
+		Spec: controlplanev1beta2.KairosControlPlaneSpec{
+			Replicas:             &replicas,
+			Version:              "v1.30.0+k0s.0",
+			KairosConfigTemplate: controlplanev1beta2.KairosConfigTemplateReference{Name: "test-config-template"},
+			UpgradeStrategy:      controlplanev1beta2.UpgradeStrategyInPlace,
+			InPlaceUpgrade: &controlplanev1beta2.InPlaceUpgrade{
+				Image:       "quay.io/kairos/ubuntu",
+				TagTemplate: "24.04-standard-amd64-generic-v3.5.0-k0s{{ .version }}",
+			},
+		},
+		Status: controlplanev1beta2.KairosControlPlaneStatus{
+			Initialized: true,
+		},
+	}
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+
+	// A machine created for the previous version
+	controller := true
+	oldVersion := kcp.Spec.Version
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-kcp-0",
+			Namespace: "default",
+			Labels: map[string]string{
+				clusterv1.ClusterNameLabel:         "test-cluster",
+				clusterv1.MachineControlPlaneLabel: "",
+			},
+			Annotations: map[string]string{
+				controlplanev1beta2.MachineSpecHashAnnotation: machineSpecHash(kcp),
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: controlplanev1beta2.GroupVersion.String(),
+				Kind:       "KairosControlPlane",
+				Name:       "test-kcp",
+				UID:        "kcp-uid",
+				Controller: &controller,
+			}},
+		},
+		Spec: clusterv1.MachineSpec{ClusterName: "test-cluster", Version: &oldVersion},
+		Status: clusterv1.MachineStatus{
+			NodeRef: &corev1.ObjectReference{Name: "node-0"},
+		},
+	}
+	kcp.Spec.Version = "v1.31.0+k0s.0"
+
+	mgmtClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, kcp, machine).Build()
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(osupgrade.PlanGVK, meta.RESTScopeNamespace)
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   "node-0",
+		Labels: map[string]string{"node-role.kubernetes.io/control-plane": "true"},
+	}}
+	workloadClient := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(node).Build()
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &KairosControlPlaneReconciler{
+		Client:   mgmtClient,
+		Scheme:   scheme,
+		recorder: recorder,
+		Tracker: remote.NewTestClusterCacheTracker(log.Log, mgmtClient, workloadClient, scheme,
+			client.ObjectKeyFromObject(cluster)),
+	}
+
+	// The outdated machine is not replaced
+	g.Expect(machineUpgradableInPlace(machine, kcp)).To(BeTrue())
+	g.Expect(reconciler.reconcileMachines(context.Background(), log.Log, kcp, cluster)).To(Succeed())
+	machines, err := reconciler.getControlPlaneMachines(context.Background(), kcp, cluster)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(machines).To(HaveLen(1))
+	g.Expect(conditions.IsTrue(kcp, controlplanev1beta2.MachinesSpecUpToDateCondition)).To(BeFalse())
+
+	// but its node is upgraded to the image carrying the new version
+	result := reconciler.reconcileInPlaceUpgrade(context.Background(), log.Log, kcp, cluster)
+	g.Expect(result.RequeueAfter).NotTo(BeZero())
+	g.Expect(conditions.GetReason(kcp, controlplanev1beta2.MachinesSpecUpToDateCondition)).To(Equal(controlplanev1beta2.InPlaceUpgradeInProgressReason))
+	g.Expect(recorder.Events).To(Receive(HavePrefix("Normal " + controlplanev1beta2.UpgradeStartedEvent)))
+
+	tag := "24.04-standard-amd64-generic-v3.5.0-k0sv1.31.0+k0s.0"
+	plan := &unstructured.Unstructured{}
+	plan.SetGroupVersionKind(osupgrade.PlanGVK)
+	key := client.ObjectKey{Namespace: osupgrade.PlanNamespace, Name: osupgrade.Name("test-kcp", "quay.io/kairos/ubuntu:"+tag)}
+	g.Expect(workloadClient.Get(context.Background(), key, plan)).To(Succeed())
+	version, _, _ := unstructured.NestedString(plan.Object, "spec", "version")
+	g.Expect(version).To(Equal(tag))
+
+	g.Expect(unstructured.SetNestedField(plan.Object, "abc", "status", "latestHash")).To(Succeed())
+	g.Expect(workloadClient.Update(context.Background(), plan)).To(Succeed())
+	node.Labels["plan.upgrade.cattle.io/"+key.Name] = "abc"
+	g.Expect(workloadClient.Update(context.Background(), node)).To(Succeed())
+
+	result = reconciler.reconcileInPlaceUpgrade(context.Background(), log.Log, kcp, cluster)
+	g.Expect(result.IsZero()).To(BeTrue())
+	g.Expect(conditions.IsTrue(kcp, controlplanev1beta2.MachinesSpecUpToDateCondition)).To(BeTrue())
+	g.Expect(mgmtClient.Get(context.Background(), client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+	g.Expect(*machine.Spec.Version).To(Equal("v1.31.0+k0s.0"))
+	g.Expect(reconciler.machineUpToDate(machine, kcp)).To(BeTrue())
+
+	// Other spec changes still replace the machine
+	kcp.Spec.KairosConfigTemplate.Name = "other-template"
+	kcp.Spec.Version = "v1.32.0+k0s.0"
+	g.Expect(machineUpgradableInPlace(machine, kcp)).To(BeFalse())
+}
+
+func TestReconcileMachines_SingleNodeRolloutBlocked(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-kcp", Namespace: "default"},
+		Spec:       controlplanev1beta2.KairosControlPlaneSpec{Version: "v1.31.0+k0s.0"},
+	}
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+	oldVersion := "v1.30.0+k0s.0"
+	controller := true
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-kcp-0",
+			Namespace: "default",
+			Labels: map[string]string{
+				clusterv1.ClusterNameLabel:         "test-cluster",
+				clusterv1.MachineControlPlaneLabel: "",
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: controlplanev1beta2.GroupVersion.String(),
+				Kind:       "KairosControlPlane",
+				Name:       "test-kcp",
+				Controller: &controller,
+			}},
+		},
+		Spec: clusterv1.MachineSpec{ClusterName: "test-cluster", Version: &oldVersion},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(machine).Build()
+	reconciler := &KairosControlPlaneReconciler{Client: c, Scheme: scheme}
+
+	g.Expect(reconciler.reconcileMachines(context.Background(), log.Log, kcp, cluster)).To(Succeed())
+	g.Expect(conditions.GetReason(kcp, controlplanev1beta2.MachinesSpecUpToDateCondition)).To(Equal(controlplanev1beta2.RolloutBlockedReason))
+
+	machines, err := reconciler.getControlPlaneMachines(context.Background(), kcp, cluster)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(machines).To(HaveLen(1))
+}
+
+func TestReconcileRemediation(t *testing.T) {
+	remediationMachine := func(name string, age time.Duration, unhealthy bool) *clusterv1.Machine {
+		controller := true
+		machine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+				Labels: map[string]string{
+					clusterv1.ClusterNameLabel:         "test-cluster",
+					clusterv1.MachineControlPlaneLabel: "",
+				},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: controlplanev1beta2.GroupVersion.String(),
+					Kind:       "KairosControlPlane",
+					Name:       "test-kcp",
+					Controller: &controller,
+				}},
+			},
+			Status: clusterv1.MachineStatus{
+				NodeRef: &corev1.ObjectReference{Name: name},
+			},
+		}
+		if unhealthy {
+			conditions.MarkFalse(machine, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "")
+		}
+		return machine
+	}
+	annotated := func(m *clusterv1.Machine) *clusterv1.Machine {
+		m.Annotations = map[string]string{clusterv1.RemediateMachineAnnotation: ""}
+		return m
+	}
+	maxRetry := int32(1)
+
+	tests := []struct {
+		name            string
+		machines        []*clusterv1.Machine
+		strategy        *controlplanev1beta2.RemediationStrategy
+		lastRemediation *controlplanev1beta2.LastRemediationStatus
+		expectedReason  string
+		expectedDeleted string
+		expectedRetries int32
+	}{
+		{
+			name: "healthy machines",
+			machines: []*clusterv1.Machine{
+				remediationMachine("m-0", 3*time.Hour, false),
+				remediationMachine("m-1", 2*time.Hour, false),
+				remediationMachine("m-2", time.Hour, false),
+			},
+		},
+		{
+			name: "annotated machine is deleted",
+			machines: []*clusterv1.Machine{
+				remediationMachine("m-0", 3*time.Hour, false),
+				annotated(remediationMachine("m-1", 2*time.Hour, false)),
+				remediationMachine("m-2", time.Hour, false),
+			},
+			expectedReason:  controlplanev1beta2.RemediationInProgressReason,
+			expectedDeleted: "m-1",
+		},
+		{
+			name: "remediation that would lose quorum is blocked",
+			machines: []*clusterv1.Machine{
+				remediationMachine("m-0", 3*time.Hour, true),
+				annotated(remediationMachine("m-1", 2*time.Hour, false)),
+				remediationMachine("m-2", time.Hour, false),
+			},
+			expectedReason: controlplanev1beta2.RemediationBlockedReason,
+		},
+		{
+			name: "single machine is not remediated",
+			machines: []*clusterv1.Machine{
+				remediationMachine("m-0", time.Hour, true),
+			},
+			expectedReason: controlplanev1beta2.RemediationBlockedReason,
+		},
+		{
+			name: "recent remediation counts as retry",
+			machines: []*clusterv1.Machine{
+				remediationMachine("m-0", 3*time.Hour, false),
+				remediationMachine("m-1", 2*time.Hour, false),
+				remediationMachine("m-3", 10*time.Minute, true),
+			},
+			strategy:        &controlplanev1beta2.RemediationStrategy{MaxRetry: &maxRetry},
+			lastRemediation: &controlplanev1beta2.LastRemediationStatus{Machine: "m-2", Timestamp: metav1.NewTime(time.Now().Add(-15 * time.Minute))},
+			expectedReason:  controlplanev1beta2.RemediationInProgressReason,
+			expectedDeleted: "m-3",
+			expectedRetries: 1,
+		},
+		{
+			name: "retries exhausted",
+			machines: []*clusterv1.Machine{
+				remediationMachine("m-0", 3*time.Hour, false),
+				remediationMachine("m-1", 2*time.Hour, false),
+				remediationMachine("m-4", 10*time.Minute, true),
+			},
+			strategy:        &controlplanev1beta2.RemediationStrategy{MaxRetry: &maxRetry},
+			lastRemediation: &controlplanev1beta2.LastRemediationStatus{Machine: "m-3", Timestamp: metav1.NewTime(time.Now().Add(-15 * time.Minute)), RetryCount: 1},
+			expectedReason:  controlplanev1beta2.RemediationBlockedReason,
+		},
+		{
+			name: "retry period not passed",
+			machines: []*clusterv1.Machine{
+				remediationMachine("m-0", 3*time.Hour, false),
+				remediationMachine("m-1", 2*time.Hour, false),
+				remediationMachine("m-3", 10*time.Minute, true),
+			},
+			strategy:        &controlplanev1beta2.RemediationStrategy{RetryPeriod: metav1.Duration{Duration: 30 * time.Minute}},
+			lastRemediation: &controlplanev1beta2.LastRemediationStatus{Machine: "m-2", Timestamp: metav1.NewTime(time.Now().Add(-15 * time.Minute))},
+			expectedReason:  controlplanev1beta2.RemediationInProgressReason,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+			g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+			objs := make([]client.Object, 0, len(tt.machines))
+			for _, m := range tt.machines {
+				objs = append(objs, m)
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			recorder := record.NewFakeRecorder(10)
+			reconciler := &KairosControlPlaneReconciler{Client: c, Scheme: scheme, recorder: recorder}
+
+			kcp := &controlplanev1beta2.KairosControlPlane{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-kcp", Namespace: "default"},
+				Spec:       controlplanev1beta2.KairosControlPlaneSpec{RemediationStrategy: tt.strategy},
+				Status: controlplanev1beta2.KairosControlPlaneStatus{
+					Initialized:     true,
+					LastRemediation: tt.lastRemediation,
+				},
+			}
+			cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+
+			_, err := reconciler.reconcileRemediation(context.Background(), log.Log, kcp, cluster)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			if tt.expectedReason == "" {
+				g.Expect(conditions.IsTrue(kcp, controlplanev1beta2.MachinesHealthyCondition)).To(BeTrue())
+			} else {
+				g.Expect(conditions.GetReason(kcp, controlplanev1beta2.MachinesHealthyCondition)).To(Equal(tt.expectedReason))
+			}
+
+			machines, err := reconciler.getControlPlaneMachines(context.Background(), kcp, cluster)
+			g.Expect(err).NotTo(HaveOccurred())
+			if tt.expectedDeleted == "" {
+				g.Expect(machines).To(HaveLen(len(tt.machines)))
+				g.Expect(kcp.Status.LastRemediation).To(Equal(tt.lastRemediation))
+				g.Expect(recorder.Events).NotTo(Receive())
+				return
+			}
+			g.Expect(machines).To(HaveLen(len(tt.machines) - 1))
+			// Emitted on the control plane and on the deleted machine
+			for i := 0; i < 2; i++ {
+				g.Expect(recorder.Events).To(Receive(ContainSubstring("Warning %s Deleting unhealthy control plane machine %s",
+					controlplanev1beta2.RemediationTriggeredEvent, tt.expectedDeleted)))
+			}
+			for _, m := range machines {
+				g.Expect(m.Name).NotTo(Equal(tt.expectedDeleted))
+			}
+			g.Expect(kcp.Status.LastRemediation).NotTo(BeNil())
+			g.Expect(kcp.Status.LastRemediation.Machine).To(Equal(tt.expectedDeleted))
+			g.Expect(kcp.Status.LastRemediation.RetryCount).To(Equal(tt.expectedRetries))
+		})
+	}
+}
+
+func TestReconcileInitLock(t *testing.T) {
+	initLockMachine := func(name string, age time.Duration) *clusterv1.Machine {
+		controller := true
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+				Labels: map[string]string{
+					clusterv1.ClusterNameLabel:         "test-cluster",
+					clusterv1.MachineControlPlaneLabel: "",
+				},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: controlplanev1beta2.GroupVersion.String(),
+					Kind:       "KairosControlPlane",
+					Name:       "test-kcp",
+					Controller: &controller,
+				}},
+			},
+			Spec: clusterv1.MachineSpec{
+				ClusterName: "test-cluster",
+				Bootstrap: clusterv1.Bootstrap{
+					ConfigRef: &corev1.ObjectReference{Kind: "KairosConfig", Name: name, Namespace: "default"},
+				},
+			},
+		}
+	}
+	deleting := func(m *clusterv1.Machine) *clusterv1.Machine {
+		m.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		m.Finalizers = []string{"test"}
+		return m
+	}
+	failed := func(m *clusterv1.Machine) *clusterv1.Machine {
+		m.Status.FailureMessage = ptr.To("instance terminated")
+		return m
+	}
+	kairosConfig := func(name string, joins, hasData bool) *bootstrapv1beta2.KairosConfig {
+		config := &bootstrapv1beta2.KairosConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       bootstrapv1beta2.KairosConfigSpec{Role: "control-plane", Distribution: "k0s"},
+		}
+		if joins {
+			config.Spec.ControllerTokenSecretRef = &bootstrapv1beta2.WorkerTokenSecretReference{Name: "test-cluster-k0s-controller-token", Key: "token"}
+		}
+		if hasData {
+			config.Status.DataSecretName = ptr.To(name)
+		}
+		return config
+	}
+
+	tests := []struct {
+		name        string
+		initialized bool
+		objects     []client.Object
+		// expectedInit lists the KairosConfigs that run the init path afterwards
+		expectedInit []string
+	}{
+		{
+			name: "live holder keeps the lock",
+			objects: []client.Object{
+				initLockMachine("m-0", 3*time.Hour), kairosConfig("m-0", false, true),
+				initLockMachine("m-1", 2*time.Hour), kairosConfig("m-1", true, false),
+			},
+			expectedInit: []string{"m-0"},
+		},
+		{
+			name: "deleted holder hands the lock to the oldest waiting machine",
+			objects: []client.Object{
+				deleting(initLockMachine("m-0", 3*time.Hour)), kairosConfig("m-0", false, true),
+				initLockMachine("m-1", 2*time.Hour), kairosConfig("m-1", true, false),
+				initLockMachine("m-2", time.Hour), kairosConfig("m-2", true, false),
+			},
+			expectedInit: []string{"m-0", "m-1"},
+		},
+		{
+			name: "failed holder hands the lock over",
+			objects: []client.Object{
+				failed(initLockMachine("m-0", 3*time.Hour)), kairosConfig("m-0", false, true),
+				initLockMachine("m-1", 2*time.Hour), kairosConfig("m-1", true, false),
+			},
+			expectedInit: []string{"m-0", "m-1"},
+		},
+		{
+			name: "machines with join bootstrap data are skipped",
+			objects: []client.Object{
+				deleting(initLockMachine("m-0", 3*time.Hour)), kairosConfig("m-0", false, true),
+				initLockMachine("m-1", 2*time.Hour), kairosConfig("m-1", true, true),
+				initLockMachine("m-2", time.Hour), kairosConfig("m-2", true, false),
+			},
+			expectedInit: []string{"m-0", "m-2"},
+		},
+		{
+			name:        "initialized control plane is left alone",
+			initialized: true,
+			objects: []client.Object{
+				deleting(initLockMachine("m-0", 3*time.Hour)), kairosConfig("m-0", false, true),
+				initLockMachine("m-1", 2*time.Hour), kairosConfig("m-1", true, false),
+			},
+			expectedInit: []string{"m-0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+			g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+			g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.objects...).Build()
+			reconciler := &KairosControlPlaneReconciler{Client: c, Scheme: scheme}
+
+			kcp := &controlplanev1beta2.KairosControlPlane{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-kcp", Namespace: "default"},
+				Spec:       controlplanev1beta2.KairosControlPlaneSpec{Replicas: ptr.To(int32(3))},
+				Status:     controlplanev1beta2.KairosControlPlaneStatus{Initialized: tt.initialized},
+			}
+			cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+
+			g.Expect(reconciler.reconcileInitLock(context.Background(), log.Log, kcp, cluster)).To(Succeed())
+
+			configs := &bootstrapv1beta2.KairosConfigList{}
+			g.Expect(c.List(context.Background(), configs)).To(Succeed())
+			var init []string
+			for _, config := range configs.Items {
+				if config.Spec.ControllerTokenSecretRef == nil {
+					init = append(init, config.Name)
+				}
+			}
+			g.Expect(init).To(ConsistOf(tt.expectedInit))
+		})
+	}
+}
+
+func TestCreateControlPlaneMachine_TakesFreeInitLock(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-kcp", Namespace: "default"},
+		Spec: controlplanev1beta2.KairosControlPlaneSpec{
+			Replicas: ptr.To(int32(3)),
+			Version:  "v1.30.0+k0s.0",
+			MachineTemplate: controlplanev1beta2.KairosControlPlaneMachineTemplate{
+				InfrastructureRef: corev1.ObjectReference{
+					APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+					Kind:       "DockerMachineTemplate",
+					Name:       "test-template",
+					Namespace:  "default",
+				},
+			},
+		},
+	}
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+
+	infraTemplate := &unstructured.Unstructured{}
+	infraTemplate.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "DockerMachineTemplate",
+	})
+	infraTemplate.SetName("test-template")
+	infraTemplate.SetNamespace("default")
+	infraTemplate.Object["spec"] = map[string]interface{}{
+		"template": map[string]interface{}{
+			"spec": map[string]interface{}{},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(infraTemplate).Build()
+	reconciler := &KairosControlPlaneReconciler{Client: c, Scheme: scheme}
+	ctx := context.Background()
+
+	g.Expect(reconciler.createControlPlaneMachine(ctx, log.Log, kcp, cluster, 0)).To(Succeed())
+
+	// The first machine fails before the control plane is initialized
+	machine := &clusterv1.Machine{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Name: "test-kcp-0", Namespace: "default"}, machine)).To(Succeed())
+	machine.Status.FailureMessage = ptr.To("instance terminated")
+	g.Expect(c.Update(ctx, machine)).To(Succeed())
+
+	// The next machine runs the init path instead of waiting for a token forever
+	g.Expect(reconciler.createControlPlaneMachine(ctx, log.Log, kcp, cluster, 1)).To(Succeed())
+	kairosConfig := &bootstrapv1beta2.KairosConfig{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Name: "test-kcp-1", Namespace: "default"}, kairosConfig)).To(Succeed())
+	g.Expect(kairosConfig.Spec.ControllerTokenSecretRef).To(BeNil())
+
+	// Only one machine holds the init lock
+	g.Expect(reconciler.createControlPlaneMachine(ctx, log.Log, kcp, cluster, 2)).To(Succeed())
+	g.Expect(c.Get(ctx, types.NamespacedName{Name: "test-kcp-2", Namespace: "default"}, kairosConfig)).To(Succeed())
+	g.Expect(kairosConfig.Spec.ControllerTokenSecretRef).NotTo(BeNil())
+}
+
+func TestReconcileControllerJoinToken(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	replicas := int32(3)
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-kcp", Namespace: "default"},
+		Spec:       controlplanev1beta2.KairosControlPlaneSpec{Replicas: &replicas},
+		Status:     controlplanev1beta2.KairosControlPlaneStatus{Initialized: true},
+	}
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+	expiry := time.Now().Add(20 * time.Hour)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-cluster-k0s-controller-token",
+			Namespace:   "default",
+			Annotations: map[string]string{controllerJoinTokenExpiryAnnotation: expiry.UTC().Format(time.RFC3339)},
+		},
+		Data: map[string][]byte{"token": []byte("test-controller-token")},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	reconciler := &KairosControlPlaneReconciler{Client: c, Scheme: scheme}
+
+	// A token that does not need renewing yet is kept until the renewal time
+	result := reconciler.reconcileControllerJoinToken(context.Background(), log.Log, kcp, cluster)
+	g.Expect(result.RequeueAfter).To(BeNumerically("~", time.Until(expiry)-controllerJoinTokenRenewBefore, time.Minute))
+
+	// Single-node and k3s control planes have no controller tokens
+	single := kcp.DeepCopy()
+	single.Spec.Replicas = nil
+	g.Expect(reconciler.reconcileControllerJoinToken(context.Background(), log.Log, single, cluster).RequeueAfter).To(BeZero())
+	k3s := kcp.DeepCopy()
+	k3s.Spec.Distribution = "k3s"
+	g.Expect(reconciler.reconcileControllerJoinToken(context.Background(), log.Log, k3s, cluster).RequeueAfter).To(BeZero())
+}
+
+// testCertKubeconfig returns a kubeconfig whose client certificate expires at notAfter
+func testCertKubeconfig(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "admin"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := clientcmdapi.NewConfig()
+	config.Clusters["test-cluster"] = &clientcmdapi.Cluster{Server: "https://10.0.0.10:6443"}
+	config.AuthInfos["admin"] = &clientcmdapi.AuthInfo{
+		ClientCertificateData: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+	config.Contexts["admin@test-cluster"] = &clientcmdapi.Context{Cluster: "test-cluster", AuthInfo: "admin"}
+	config.CurrentContext = "admin@test-cluster"
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+type fakeEtcdExecutor struct {
+	memberList string
+	err        error
+	commands   []string
+}
+
+func (f *fakeEtcdExecutor) Run(_ context.Context, machine *clusterv1.Machine, command string) ([]byte, error) {
+	f.commands = append(f.commands, machine.Name+": "+command)
+	if f.err != nil {
+		return nil, f.err
+	}
+	if command == "k0s etcd member-list" {
+		return []byte(f.memberList), nil
+	}
+	return nil, nil
+}
+
+func etcdTestMachine(name string, joined, deleting bool) *clusterv1.Machine {
+	controller := true
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels: map[string]string{
+				clusterv1.ClusterNameLabel:         "test-cluster",
+				clusterv1.MachineControlPlaneLabel: "",
+			},
+			Annotations: map[string]string{etcd.PreTerminateHookAnnotation: ""},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: controlplanev1beta2.GroupVersion.String(),
+				Kind:       "KairosControlPlane",
+				Name:       "test-kcp",
+				Controller: &controller,
+			}},
+		},
+	}
+	if joined {
+		machine.Status.NodeRef = &corev1.ObjectReference{Name: name}
+	}
+	if deleting {
+		now := metav1.Now()
+		machine.DeletionTimestamp = &now
+		machine.Finalizers = []string{clusterv1.MachineFinalizer}
+		conditions.MarkFalse(machine, clusterv1.PreTerminateDeleteHookSucceededCondition, clusterv1.WaitingExternalHookReason, clusterv1.ConditionSeverityInfo, "")
+	}
+	return machine
+}
+
+func TestReconcileDrain(t *testing.T) {
+	drainMachine := func(age time.Duration) *clusterv1.Machine {
+		controller := true
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test-kcp-2",
+				Namespace:         "default",
+				DeletionTimestamp: &metav1.Time{Time: time.Now().Add(-age)},
+				Finalizers:        []string{clusterv1.MachineFinalizer},
+				Labels: map[string]string{
+					clusterv1.ClusterNameLabel:         "test-cluster",
+					clusterv1.MachineControlPlaneLabel: "",
+				},
+				Annotations: map[string]string{drain.PreDrainHookAnnotation: ""},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: controlplanev1beta2.GroupVersion.String(),
+					Kind:       "KairosControlPlane",
+					Name:       "test-kcp",
+					Controller: &controller,
+				}},
+			},
+			Status: clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: "cp-2"}},
+		}
+	}
+	pod := func(name string, terminating bool) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       corev1.PodSpec{NodeName: "cp-2"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		if terminating {
+			p.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			p.Finalizers = []string{"test"}
+		}
+		return p
+	}
+
+	tests := []struct {
+		name             string
+		age              time.Duration
+		pods             []client.Object
+		reconciles       int
+		noWorkloadClient bool
+		expectHook       bool
+		expectSkipDrain  bool
+	}{
+		{
+			name:       "evicting pods keeps the hook",
+			pods:       []client.Object{pod("app", false)},
+			reconciles: 1,
+			expectHook: true,
+		},
+		{
+			name:            "drained node releases the hook",
+			pods:            []client.Object{pod("app", false)},
+			reconciles:      2,
+			expectSkipDrain: true,
+		},
+		{
+			name:       "pods still terminating keep the hook",
+			pods:       []client.Object{pod("app", true)},
+			expectHook: true,
+		},
+		{
+			name:            "timeout releases the hook",
+			age:             10 * time.Minute,
+			pods:            []client.Object{pod("app", true)},
+			expectSkipDrain: true,
+		},
+		{
+			name:             "without a workload client the Machine controller drains",
+			pods:             []client.Object{pod("app", false)},
+			noWorkloadClient: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+			g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+			g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+			machine := drainMachine(tt.age)
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(machine).Build()
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "cp-2"},
+				Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				}},
+			}
+			workloadClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(tt.pods, node)...).
+				WithIndex(&corev1.Pod{}, "spec.nodeName", func(obj client.Object) []string {
+					return []string{obj.(*corev1.Pod).Spec.NodeName}
+				}).
+				Build()
+			reconciler := &KairosControlPlaneReconciler{Client: c, Scheme: scheme}
+			if !tt.noWorkloadClient {
+				reconciler.drainClient = workloadClient
+			}
+
+			kcp := &controlplanev1beta2.KairosControlPlane{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-kcp", Namespace: "default"},
+				Spec: controlplanev1beta2.KairosControlPlaneSpec{
+					MachineTemplate: controlplanev1beta2.KairosControlPlaneMachineTemplate{
+						NodeDrainTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+					},
+				},
+			}
+			cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+
+			result := reconciler.reconcileDrain(context.Background(), log.Log, kcp, cluster)
+			for i := 1; i < tt.reconciles; i++ {
+				result = reconciler.reconcileDrain(context.Background(), log.Log, kcp, cluster)
+			}
+			g.Expect(result.RequeueAfter > 0).To(Equal(tt.expectHook))
+
+			got := &clusterv1.Machine{}
+			g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(machine), got)).To(Succeed())
+			if tt.expectHook {
+				g.Expect(got.Annotations).To(HaveKey(drain.PreDrainHookAnnotation))
+			} else {
+				g.Expect(got.Annotations).NotTo(HaveKey(drain.PreDrainHookAnnotation))
+			}
+			if tt.expectSkipDrain {
+				g.Expect(got.Annotations).To(HaveKey(clusterv1.ExcludeNodeDrainingAnnotation))
+			} else {
+				g.Expect(got.Annotations).NotTo(HaveKey(clusterv1.ExcludeNodeDrainingAnnotation))
+			}
+
+			if !tt.noWorkloadClient && tt.age == 0 {
+				g.Expect(workloadClient.Get(context.Background(), client.ObjectKeyFromObject(node), node)).To(Succeed())
+				g.Expect(node.Spec.Unschedulable).To(BeTrue())
+			}
+		})
+	}
+}
+
+func TestReconcileEtcdMembers(t *testing.T) {
+	const members = `{"members":{"test-kcp-0":"https://10.0.0.10:2380","test-kcp-1":"https://10.0.0.11:2380","test-kcp-2":"https://10.0.0.12:2380"}}`
+
+	tests := []struct {
+		name             string
+		deleting         *clusterv1.Machine
+		executorErr      error
+		expectedCommands []string
+		expectedReason   string
+		expectHook       bool
+	}{
+		{
+			name:     "member is removed through a peer",
+			deleting: etcdTestMachine("test-kcp-2", true, true),
+			expectedCommands: []string{
+				"test-kcp-0: k0s etcd member-list",
+				"test-kcp-0: k0s etcd leave --peer-address=10.0.0.12",
+			},
+		},
+		{
+			name: "machine is not drained yet",
+			deleting: func() *clusterv1.Machine {
+				m := etcdTestMachine("test-kcp-2", true, true)
+				m.Status.Conditions = nil
+				return m
+			}(),
+			expectHook: true,
+		},
+		{
+			name:        "removal failure keeps the hook",
+			deleting:    etcdTestMachine("test-kcp-2", true, true),
+			executorErr: errors.New("connection refused"),
+			expectedCommands: []string{
+				"test-kcp-0: k0s etcd member-list",
+				"test-kcp-1: k0s etcd member-list",
+			},
+			expectedReason: controlplanev1beta2.EtcdMemberRemovalFailedReason,
+			expectHook:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+			g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				etcdTestMachine("test-kcp-0", true, false),
+				etcdTestMachine("test-kcp-1", true, false),
+				tt.deleting,
+			).Build()
+			executor := &fakeEtcdExecutor{memberList: members, err: tt.executorErr}
+			reconciler := &KairosControlPlaneReconciler{Client: c, Scheme: scheme, etcdExecutor: executor}
+
+			kcp := &controlplanev1beta2.KairosControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "test-kcp", Namespace: "default"}}
+			cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+
+			reconciler.reconcileEtcdMembers(context.Background(), log.Log, kcp, cluster)
+			g.Expect(executor.commands).To(Equal(tt.expectedCommands))
+			if tt.expectedReason == "" {
+				g.Expect(conditions.IsTrue(kcp, controlplanev1beta2.EtcdClusterHealthyCondition)).To(BeTrue())
+			} else {
+				g.Expect(conditions.GetReason(kcp, controlplanev1beta2.EtcdClusterHealthyCondition)).To(Equal(tt.expectedReason))
+			}
+
+			machine := &clusterv1.Machine{}
+			g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(tt.deleting), machine)).To(Succeed())
+			if tt.expectHook {
+				g.Expect(machine.Annotations).To(HaveKey(etcd.PreTerminateHookAnnotation))
+			} else {
+				g.Expect(machine.Annotations).NotTo(HaveKey(etcd.PreTerminateHookAnnotation))
+			}
+		})
+	}
+}
+
+func TestReconcileMachines_ScaleDownKeepsEtcdQuorum(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	version := "v1.30.0+k0s.0"
+	replicas := int32(2)
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-kcp", Namespace: "default"},
+		Spec:       controlplanev1beta2.KairosControlPlaneSpec{Replicas: &replicas, Version: version},
+	}
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+
+	var objs []client.Object
+	for i := 0; i < 3; i++ {
+		machine := etcdTestMachine(fmt.Sprintf("test-kcp-%d", i), true, false)
+		machine.CreationTimestamp = metav1.NewTime(time.Now().Add(time.Duration(i-10) * time.Minute))
+		machine.Annotations[controlplanev1beta2.MachineSpecHashAnnotation] = machineSpecHash(kcp)
+		machine.Spec = clusterv1.MachineSpec{ClusterName: "test-cluster", Version: &version}
+		objs = append(objs, machine)
+	}
+	// The oldest machine is unhealthy, so only one healthy member would remain
+	conditions.MarkFalse(objs[0].(*clusterv1.Machine), clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "")
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	reconciler := &KairosControlPlaneReconciler{Client: c, Scheme: scheme}
+
+	// The unhealthy machine is removed first, which keeps quorum
+	g.Expect(reconciler.selectMachineForDeletion(func() []*clusterv1.Machine {
+		machines, err := reconciler.getControlPlaneMachines(context.Background(), kcp, cluster)
+		g.Expect(err).NotTo(HaveOccurred())
+		return machines
+	}(), nil).Name).To(Equal("test-kcp-0"))
+
+	// Deleting a healthy machine instead is refused
+	machines, err := reconciler.getControlPlaneMachines(context.Background(), kcp, cluster)
+	g.Expect(err).NotTo(HaveOccurred())
+	var healthy *clusterv1.Machine
+	for _, m := range machines {
+		if m.Name == "test-kcp-2" {
+			healthy = m
+		}
+	}
+	ok, remaining, quorum := keepsEtcdQuorum(machines, healthy)
+	g.Expect(ok).To(BeFalse())
+	g.Expect(remaining).To(Equal(1))
+	g.Expect(quorum).To(Equal(2))
+
+	g.Expect(reconciler.reconcileMachines(context.Background(), log.Log, kcp, cluster)).To(Succeed())
+	machines, err = reconciler.getControlPlaneMachines(context.Background(), kcp, cluster)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(machines).To(HaveLen(2))
+	for _, m := range machines {
+		g.Expect(m.Name).NotTo(Equal("test-kcp-0"))
+	}
+}
+
+func TestNextFailureDomain(t *testing.T) {
+	version := "v1.30.0+k0s.0"
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-kcp", Namespace: "default"},
+		Spec:       controlplanev1beta2.KairosControlPlaneSpec{Version: version},
+	}
+	fdMachine := func(domain string, upToDate, deleting bool) *clusterv1.Machine {
+		machine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{controlplanev1beta2.MachineSpecHashAnnotation: "outdated"}},
+			Spec:       clusterv1.MachineSpec{Version: &version, FailureDomain: &domain},
+		}
+		if upToDate {
+			machine.Annotations[controlplanev1beta2.MachineSpecHashAnnotation] = machineSpecHash(kcp)
+		}
+		if deleting {
+			machine.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		}
+		return machine
+	}
+	clusterDomains := clusterv1.FailureDomains{
+		"zone-c": clusterv1.FailureDomainSpec{ControlPlane: true},
+		"zone-a": clusterv1.FailureDomainSpec{ControlPlane: true},
+		"zone-b": clusterv1.FailureDomainSpec{ControlPlane: true},
+		"zone-w": clusterv1.FailureDomainSpec{},
+	}
+
+	tests := []struct {
+		name           string
+		specDomains    []string
+		clusterDomains clusterv1.FailureDomains
+		machines       []*clusterv1.Machine
+		expected       string
+	}{
+		{
+			name: "no failure domains",
+		},
+		{
+			name:           "first control plane domain of the Cluster",
+			clusterDomains: clusterDomains,
+			expected:       "zone-a",
+		},
+		{
+			name:           "domain with the fewest machines",
+			clusterDomains: clusterDomains,
+			machines:       []*clusterv1.Machine{fdMachine("zone-a", true, false), fdMachine("zone-b", true, false)},
+			expected:       "zone-c",
+		},
+		{
+			name:           "deleting machines do not count",
+			clusterDomains: clusterDomains,
+			machines:       []*clusterv1.Machine{fdMachine("zone-a", true, true), fdMachine("zone-b", true, false), fdMachine("zone-c", true, false)},
+			expected:       "zone-a",
+		},
+		{
+			name:           "rollout spreads the up-to-date machines",
+			clusterDomains: clusterDomains,
+			machines: []*clusterv1.Machine{
+				fdMachine("zone-a", true, false), fdMachine("zone-a", false, false),
+				fdMachine("zone-b", false, false), fdMachine("zone-c", false, false),
+			},
+			expected: "zone-b",
+		},
+		{
+			name:           "spec list overrides the Cluster",
+			specDomains:    []string{"rack-2", "rack-1"},
+			clusterDomains: clusterDomains,
+			machines:       []*clusterv1.Machine{fdMachine("rack-2", true, false)},
+			expected:       "rack-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			kcp := kcp.DeepCopy()
+			kcp.Spec.FailureDomains = tt.specDomains
+			cluster := &clusterv1.Cluster{Status: clusterv1.ClusterStatus{FailureDomains: tt.clusterDomains}}
+
+			reconciler := &KairosControlPlaneReconciler{}
+			domain := reconciler.nextFailureDomain(kcp, cluster, tt.machines)
+			if tt.expected == "" {
+				g.Expect(domain).To(BeNil())
+				return
+			}
+			g.Expect(domain).To(Equal(ptr.To(tt.expected)))
+		})
+	}
+}
+
+func TestSelectMachineForDeletion_FailureDomains(t *testing.T) {
+	g := NewWithT(t)
+
+	fdMachine := func(name, domain string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       clusterv1.MachineSpec{FailureDomain: &domain},
+			Status:     clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: name}},
+		}
+	}
+	// Oldest first
+	machines := []*clusterv1.Machine{
+		fdMachine("m-0", "zone-a"),
+		fdMachine("m-1", "zone-b"),
+		fdMachine("m-2", "zone-b"),
+		fdMachine("m-3", "zone-c"),
+	}
+
+	reconciler := &KairosControlPlaneReconciler{}
+	g.Expect(reconciler.selectMachineForDeletion(machines, nil).Name).To(Equal("m-2"))
+}
+
+func TestReconcileDelete_ReleasesEtcdHooks(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-kcp",
+			Namespace:  "default",
+			Finalizers: []string{controlplanev1beta2.KairosControlPlaneFinalizer},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(kcp, etcdTestMachine("test-kcp-0", true, true)).Build()
+	reconciler := &KairosControlPlaneReconciler{Client: c, Scheme: scheme}
+
+	_, err := reconciler.reconcileDelete(context.Background(), log.Log, kcp)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	machine := &clusterv1.Machine{}
+	g.Expect(c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "test-kcp-0"}, machine)).To(Succeed())
+	g.Expect(machine.Annotations).NotTo(HaveKey(etcd.PreTerminateHookAnnotation))
+}
+
+func TestReconcile_Paused(t *testing.T) {
+	tests := []struct {
+		name          string
+		clusterPaused bool
+		annotations   map[string]string
+	}{
+		{
+			name:          "cluster paused",
+			clusterPaused: true,
+		},
+		{
+			name:        "paused annotation",
+			annotations: map[string]string{clusterv1.PausedAnnotation: ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+			g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+			g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+				Spec:       clusterv1.ClusterSpec{Paused: tt.clusterPaused},
+			}
+			kcp := &controlplanev1beta2.KairosControlPlane{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-kcp",
+					Namespace:   "default",
+					Annotations: tt.annotations,
+					Labels:      map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+					Finalizers:  []string{controlplanev1beta2.KairosControlPlaneFinalizer},
+				},
+				Spec: controlplanev1beta2.KairosControlPlaneSpec{Replicas: ptr.To[int32](1)},
+			}
+			c := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(cluster, kcp).
+				WithStatusSubresource(kcp).
+				Build()
+			reconciler := &KairosControlPlaneReconciler{Client: c, Scheme: scheme}
+
+			_, err := reconciler.Reconcile(context.Background(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: "test-kcp", Namespace: "default"},
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			updated := &controlplanev1beta2.KairosControlPlane{}
+			g.Expect(c.Get(context.Background(), types.NamespacedName{Name: "test-kcp", Namespace: "default"}, updated)).To(Succeed())
+			g.Expect(conditions.IsTrue(updated, controlplanev1beta2.PausedCondition)).To(BeTrue())
+			paused := meta.FindStatusCondition(updated.GetV1Beta2Conditions(), controlplanev1beta2.PausedV1Beta2Condition)
+			g.Expect(paused).NotTo(BeNil())
+			g.Expect(paused.Status).To(Equal(metav1.ConditionTrue))
+
+			// Nothing is created while paused
+			secrets := &corev1.SecretList{}
+			g.Expect(c.List(context.Background(), secrets)).To(Succeed())
+			g.Expect(secrets.Items).To(BeEmpty())
+			machines := &clusterv1.MachineList{}
+			g.Expect(c.List(context.Background(), machines)).To(Succeed())
+			g.Expect(machines.Items).To(BeEmpty())
+		})
+	}
+}
+
+func TestEnsureKubeconfigSecretMetadata(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster-kubeconfig", Namespace: "default"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	reconciler := &KairosControlPlaneReconciler{Client: c, Scheme: scheme}
+	// Typed objects read through the client have no TypeMeta
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default", UID: "cluster-uid"}}
+
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+	updated, err := reconciler.ensureKubeconfigSecretMetadata(context.Background(), secret, cluster)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated).To(BeTrue())
+
+	// clusterctl move finds the secret through its label and owner
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+	g.Expect(secret.Labels).To(HaveKeyWithValue(clusterv1.ClusterNameLabel, "test-cluster"))
+	owner := metav1.GetControllerOf(secret)
+	g.Expect(owner).NotTo(BeNil())
+	g.Expect(owner.APIVersion).To(Equal(clusterv1.GroupVersion.String()))
+	g.Expect(owner.Kind).To(Equal("Cluster"))
+	g.Expect(owner.UID).To(Equal(cluster.UID))
+
+	updated, err = reconciler.ensureKubeconfigSecretMetadata(context.Background(), secret, cluster)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated).To(BeFalse())
+}
+
+func TestReconcileCertificates(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	reconciler := &KairosControlPlaneReconciler{Client: c, Scheme: scheme}
+	kcp := &controlplanev1beta2.KairosControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "test-kcp", Namespace: "default", UID: "kcp-uid"}}
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+
+	g.Expect(reconciler.reconcileCertificates(context.Background(), kcp, cluster)).To(Succeed())
+
+	ca := &corev1.Secret{}
+	for _, suffix := range []string{"ca", "etcd", "proxy", "sa"} {
+		s := &corev1.Secret{}
+		g.Expect(c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "test-cluster-" + suffix}, s)).To(Succeed())
+		g.Expect(s.Type).To(Equal(clusterv1.ClusterSecretType))
+		g.Expect(s.Labels).To(HaveKeyWithValue(clusterv1.ClusterNameLabel, "test-cluster"))
+		g.Expect(metav1.IsControlledBy(s, kcp)).To(BeTrue())
+		if suffix == "ca" {
+			ca = s
+		}
+	}
+
+	// Existing certificates are kept
+	g.Expect(reconciler.reconcileCertificates(context.Background(), kcp, cluster)).To(Succeed())
+	again := &corev1.Secret{}
+	g.Expect(c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "test-cluster-ca"}, again)).To(Succeed())
+	g.Expect(again.Data).To(Equal(ca.Data))
+}
+
+func TestKubeconfigRenewal(t *testing.T) {
+	tests := []struct {
+		name            string
+		expiresIn       time.Duration
+		expectRenew     bool
+		expectedRequeue time.Duration
+	}{
+		{
+			name:            "certificate outside the renewal window",
+			expiresIn:       90 * 24 * time.Hour,
+			expectedRequeue: 90*24*time.Hour - kubeconfigRenewBefore,
+		},
+		{
+			name:            "certificate inside the renewal window",
+			expiresIn:       10 * 24 * time.Hour,
+			expectRenew:     true,
+			expectedRequeue: kubeconfigRenewRetry,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+			g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+			g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+			kubeconfig := testCertKubeconfig(t, time.Now().Add(tt.expiresIn))
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-cluster-kubeconfig",
+					Namespace: "default",
+					Labels:    map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+				},
+				Type: clusterv1.ClusterSecretType,
+				Data: map[string][]byte{"value": kubeconfig},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+			reconciler := &KairosControlPlaneReconciler{Client: c, Scheme: scheme}
+
+			kcp := &controlplanev1beta2.KairosControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "test-kcp", Namespace: "default"}}
+			cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+
+			g.Expect(reconciler.kubeconfigRenewalResult(context.Background(), cluster).RequeueAfter).
+				To(BeNumerically("~", tt.expectedRequeue, time.Minute))
+
+			// A due certificate makes the controller go back to the nodes; there
+			// are none here, so the refresh fails and the secret is kept
+			err := reconciler.reconcileKubeconfig(context.Background(), log.Log, kcp, cluster)
+			if tt.expectRenew {
+				g.Expect(err).To(MatchError(ContainSubstring("no ready control plane machine found")))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			current := &corev1.Secret{}
+			g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(secret), current)).To(Succeed())
+			g.Expect(current.Data["value"]).To(Equal(kubeconfig))
+		})
+	}
+}
+
+func TestUpdateStatus(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	replicas := int32(3)
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-kcp", Namespace: "default", UID: "kcp-uid"},
+		Spec: controlplanev1beta2.KairosControlPlaneSpec{
+			Replicas: &replicas,
+			Version:  "v1.30.1+k0s.0",
+		},
+	}
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+
+	machine := func(name, version string, ready bool) *clusterv1.Machine {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels: map[string]string{
+					clusterv1.ClusterNameLabel:         "test-cluster",
+					clusterv1.MachineControlPlaneLabel: "",
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(kcp, controlplanev1beta2.GroupVersion.WithKind("KairosControlPlane")),
+				},
+			},
+			Spec: clusterv1.MachineSpec{ClusterName: "test-cluster", Version: &version},
+		}
+		if ready {
+			m.Status.NodeRef = &corev1.ObjectReference{Kind: "Node", Name: name}
+			m.Status.Phase = string(clusterv1.MachinePhaseRunning)
+		}
+		return m
+	}
+	// An upgrade from v1.30.0+k0s.1 is in progress
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		machine("test-kcp-0", "v1.30.0+k0s.1", true),
+		machine("test-kcp-1", "v1.30.1+k0s.0", true),
+		machine("test-kcp-2", "v1.30.1+k0s.0", false),
+	).Build()
+	reconciler := &KairosControlPlaneReconciler{Client: c, Scheme: scheme}
+
+	g.Expect(reconciler.updateStatus(context.Background(), log.Log, kcp, cluster)).To(Succeed())
+	g.Expect(kcp.Status.Replicas).To(Equal(int32(3)))
+	g.Expect(kcp.Status.ReadyReplicas).To(Equal(int32(2)))
+	g.Expect(kcp.Status.UpdatedReplicas).To(Equal(int32(2)))
+	g.Expect(kcp.Status.UnavailableReplicas).To(Equal(int32(1)))
+	g.Expect(kcp.Status.Version).To(Equal(ptr.To("v1.30.0+k0s.1")))
+	g.Expect(kcp.Status.Initialized).To(BeTrue())
+}
+
+func TestApplyControlPlaneEndpoint(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "edge", Namespace: "default"}}
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		Spec: controlplanev1beta2.KairosControlPlaneSpec{
+			ControlPlaneEndpoint: &controlplanev1beta2.ControlPlaneEndpoint{Host: "192.168.1.100", Port: 6443},
+		},
+	}
+
+	// k3s nodes run kube-vip as a static pod
+	k3s := &bootstrapv1beta2.KairosConfigSpec{Distribution: "k3s"}
+	g.Expect(applyControlPlaneEndpoint(k3s, kcp, cluster)).To(Succeed())
+	g.Expect(k3s.Files).To(HaveLen(1))
+	g.Expect(k3s.Files[0].Path).To(Equal(bootstrap.KubeVIPManifestPath))
+	g.Expect(k3s.Files[0].Content).To(ContainSubstring("192.168.1.100"))
+	g.Expect(k3s.ExtraInstallArgs).To(Equal([]string{"--tls-san=192.168.1.100"}))
+	g.Expect(k3s.K0sConfig).To(BeNil())
+
+	// k0s nodes use keepalived, with the same VRRP identity on every node
+	k0s := &bootstrapv1beta2.KairosConfigSpec{Distribution: "k0s"}
+	g.Expect(applyControlPlaneEndpoint(k0s, kcp, cluster)).To(Succeed())
+	g.Expect(k0s.Files).To(BeEmpty())
+	g.Expect(k0s.K0sConfig).NotTo(BeNil())
+	g.Expect(k0s.K0sConfig.APISANs).To(ConsistOf("192.168.1.100"))
+	g.Expect(string(k0s.K0sConfig.Config.Raw)).To(ContainSubstring(`"virtualIPs":["192.168.1.100/32"]`))
+
+	again := &bootstrapv1beta2.KairosConfigSpec{Distribution: "k0s"}
+	g.Expect(applyControlPlaneEndpoint(again, kcp, cluster)).To(Succeed())
+	g.Expect(again.K0sConfig.Config.Raw).To(Equal(k0s.K0sConfig.Config.Raw))
+
+	// Without an endpoint nothing is added
+	none := &bootstrapv1beta2.KairosConfigSpec{Distribution: "k0s"}
+	g.Expect(applyControlPlaneEndpoint(none, &controlplanev1beta2.KairosControlPlane{}, cluster)).To(Succeed())
+	g.Expect(none.K0sConfig).To(BeNil())
+}
+
+func TestApplyExternalEndpoint(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge", Namespace: "default"},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "api.example.com", Port: 443},
+		},
+	}
+
+	k3s := &bootstrapv1beta2.KairosConfigSpec{Distribution: "k3s"}
+	applyExternalEndpoint(k3s, cluster)
+	g.Expect(k3s.ExternalControlPlaneEndpoint).To(BeTrue())
+	g.Expect(k3s.ExtraInstallArgs).To(Equal([]string{"--tls-san=api.example.com"}))
+	g.Expect(k3s.K0sConfig).To(BeNil())
+
+	// The user's k0s configuration is kept and the host is only added once
+	k0s := &bootstrapv1beta2.KairosConfigSpec{
+		Distribution: "k0s",
+		K0sConfig:    &bootstrapv1beta2.K0sConfig{APISANs: []string{"api.example.com", "10.0.0.10"}},
+	}
+	applyExternalEndpoint(k0s, cluster)
+	g.Expect(k0s.ExternalControlPlaneEndpoint).To(BeTrue())
+	g.Expect(k0s.K0sConfig.APISANs).To(Equal([]string{"api.example.com", "10.0.0.10"}))
+
+	empty := &bootstrapv1beta2.KairosConfigSpec{Distribution: "k0s"}
+	applyExternalEndpoint(empty, cluster)
+	g.Expect(empty.K0sConfig.APISANs).To(ConsistOf("api.example.com"))
+}
+
+func TestApplyCNI(t *testing.T) {
+	g := NewWithT(t)
+
+	unset := &bootstrapv1beta2.KairosConfigSpec{Distribution: "k0s"}
+	applyCNI(unset, "")
+	g.Expect(unset.K0sConfig).To(BeNil())
+
+	calico := &bootstrapv1beta2.KairosConfigSpec{Distribution: "k0s"}
+	applyCNI(calico, "calico")
+	g.Expect(calico.K0sConfig.NetworkProvider).To(Equal("calico"))
+	g.Expect(calico.K0sConfig.Extensions).To(BeNil())
+
+	// Cilium is installed through the k0s Helm extension next to the user's charts
+	userChart := bootstrapv1beta2.K0sHelmChart{Name: "metrics-server", ChartName: "metrics-server/metrics-server", Namespace: "kube-system"}
+	cilium := &bootstrapv1beta2.KairosConfigSpec{
+		Distribution: "k0s",
+		K0sConfig: &bootstrapv1beta2.K0sConfig{Extensions: &bootstrapv1beta2.K0sExtensions{Helm: &bootstrapv1beta2.K0sHelmExtension{
+			Charts: []bootstrapv1beta2.K0sHelmChart{userChart},
+		}}},
+	}
+	original := cilium.K0sConfig
+	applyCNI(cilium, "cilium")
+	g.Expect(cilium.K0sConfig.NetworkProvider).To(Equal("custom"))
+	g.Expect(cilium.K0sConfig.Extensions.Helm.Repositories).To(Equal([]bootstrapv1beta2.K0sHelmRepository{ciliumRepository}))
+	g.Expect(cilium.K0sConfig.Extensions.Helm.Charts).To(Equal([]bootstrapv1beta2.K0sHelmChart{userChart, ciliumChart}))
+	g.Expect(original.Extensions.Helm.Charts).To(HaveLen(1))
+
+	// A cilium chart of the user's own is kept as is
+	ownCilium := bootstrapv1beta2.K0sHelmChart{Name: "cilium", ChartName: "oci://registry.example.com/cilium", Version: "1.16.0", Namespace: "kube-system"}
+	custom := &bootstrapv1beta2.KairosConfigSpec{
+		Distribution: "k0s",
+		K0sConfig: &bootstrapv1beta2.K0sConfig{Extensions: &bootstrapv1beta2.K0sExtensions{Helm: &bootstrapv1beta2.K0sHelmExtension{
+			Charts: []bootstrapv1beta2.K0sHelmChart{ownCilium},
+		}}},
+	}
+	applyCNI(custom, "cilium")
+	g.Expect(custom.K0sConfig.Extensions.Helm.Repositories).To(BeEmpty())
+	g.Expect(custom.K0sConfig.Extensions.Helm.Charts).To(Equal([]bootstrapv1beta2.K0sHelmChart{ownCilium}))
+}
+
+func TestApplyAddons(t *testing.T) {
+	g := NewWithT(t)
+
+	spec := &bootstrapv1beta2.KairosConfigSpec{
+		Manifests: []bootstrapv1beta2.Manifest{
+			{Name: "app", File: "app.yaml", Content: "kind: ConfigMap"},
+			{Name: "ccm", File: "ccm.yaml", Content: "old"},
+		},
+	}
+	addons := []bootstrapv1beta2.Manifest{
+		{Name: "ccm", File: "ccm.yaml", Content: "kind: DaemonSet"},
+		{Name: "csi", File: "csi.yaml", ContentFrom: &bootstrapv1beta2.ManifestSource{
+			ConfigMap: &bootstrapv1beta2.FileKeySelector{Name: "csi-driver", Key: "csi.yaml"},
+		}},
+	}
+	applyAddons(spec, addons)
+	g.Expect(spec.Manifests).To(Equal([]bootstrapv1beta2.Manifest{
+		{Name: "app", File: "app.yaml", Content: "kind: ConfigMap"},
+		addons[0],
+		addons[1],
+	}))
+
+	empty := &bootstrapv1beta2.KairosConfigSpec{}
+	applyAddons(empty, nil)
+	g.Expect(empty.Manifests).To(BeNil())
+}
+
+func TestLowestMachineVersion(t *testing.T) {
+	g := NewWithT(t)
+
+	machines := func(versions ...string) []*clusterv1.Machine {
+		var out []*clusterv1.Machine
+		for _, v := range versions {
+			m := &clusterv1.Machine{}
+			if v != "" {
+				m.Spec.Version = ptr.To(v)
+			}
+			out = append(out, m)
+		}
+		return out
+	}
+
+	g.Expect(lowestMachineVersion(nil)).To(BeNil())
+	g.Expect(lowestMachineVersion(machines("", "not-a-version"))).To(BeNil())
+	g.Expect(lowestMachineVersion(machines("v1.31.0+k3s1", "v1.30.4+k3s1", ""))).To(Equal(ptr.To("v1.30.4+k3s1")))
+	// Distribution releases of the same Kubernetes version are told apart
+	g.Expect(lowestMachineVersion(machines("v1.30.0+k0s.1", "v1.30.0+k0s.0"))).To(Equal(ptr.To("v1.30.0+k0s.0")))
+}
+
+func TestSetV1Beta2Conditions(t *testing.T) {
+	g := NewWithT(t)
+
+	replicas := int32(3)
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-kcp", Namespace: "default", Generation: 2},
+		Spec:       controlplanev1beta2.KairosControlPlaneSpec{Replicas: &replicas},
+		Status:     controlplanev1beta2.KairosControlPlaneStatus{Replicas: 1, ReadyReplicas: 1, Initialized: true},
+	}
+	conditions.MarkTrue(kcp, clusterv1.ReadyCondition)
+	conditions.MarkTrue(kcp, controlplanev1beta2.AvailableCondition)
+
+	condition := func(conditionType string) *metav1.Condition {
+		return meta.FindStatusCondition(kcp.GetV1Beta2Conditions(), conditionType)
+	}
+
+	setV1Beta2Conditions(kcp)
+	g.Expect(condition(controlplanev1beta2.AvailableV1Beta2Condition).Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(condition(controlplanev1beta2.ReadyV1Beta2Condition).Reason).To(Equal(controlplanev1beta2.ReadyV1Beta2Reason))
+	g.Expect(condition(controlplanev1beta2.ScalingUpV1Beta2Condition).Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(condition(controlplanev1beta2.ScalingUpV1Beta2Condition).Message).To(Equal("Scaling up from 1 to 3 replicas"))
+	g.Expect(condition(controlplanev1beta2.ScalingDownV1Beta2Condition).Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(condition(controlplanev1beta2.MachinesReadyV1Beta2Condition).Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(condition(controlplanev1beta2.ReadyV1Beta2Condition).ObservedGeneration).To(Equal(int64(2)))
+
+	// Scaling down with a machine that is not ready
+	replicas = 1
+	kcp.Status.Replicas = 2
+	conditions.MarkFalse(kcp, clusterv1.ReadyCondition, controlplanev1beta2.WaitingForMachinesReadyReason, clusterv1.ConditionSeverityInfo, "Waiting for control plane machines to be ready")
+	setV1Beta2Conditions(kcp)
+	g.Expect(condition(controlplanev1beta2.ReadyV1Beta2Condition).Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(condition(controlplanev1beta2.ReadyV1Beta2Condition).Reason).To(Equal(controlplanev1beta2.WaitingForMachinesReadyReason))
+	g.Expect(condition(controlplanev1beta2.ScalingUpV1Beta2Condition).Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(condition(controlplanev1beta2.ScalingDownV1Beta2Condition).Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(condition(controlplanev1beta2.MachinesReadyV1Beta2Condition).Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(condition(controlplanev1beta2.MachinesReadyV1Beta2Condition).Message).To(Equal("1 of 2 control plane machines are not ready"))
+
+	// No machines yet
+	kcp.Status.Replicas = 0
+	kcp.Status.ReadyReplicas = 0
+	setV1Beta2Conditions(kcp)
+	g.Expect(condition(controlplanev1beta2.MachinesReadyV1Beta2Condition).Reason).To(Equal(controlplanev1beta2.NoReplicasV1Beta2Reason))
+	g.Expect(kcp.GetV1Beta2Conditions()).To(HaveLen(5))
+}
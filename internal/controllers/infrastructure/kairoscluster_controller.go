@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	infrastructurev1beta2 "github.com/kairos-io/kairos-capi/api/infrastructure/v1beta2"
+	"github.com/kairos-io/kairos-capi/internal/shutdown"
+)
+
+// KairosClusterReconciler reconciles a KairosCluster object. Kairos hosts
+// bring their own infrastructure, so the KairosCluster only passes the control
+// plane endpoint through and reports ready once it belongs to a Cluster.
+type KairosClusterReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kairosclusters,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kairosclusters/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kairosclusters/finalizers,verbs=update
+//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop
+func (r *KairosClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	kairosCluster := &infrastructurev1beta2.KairosCluster{}
+	if err := r.Get(ctx, req.NamespacedName, kairosCluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Nothing is provisioned, so there is nothing to clean up
+	if !kairosCluster.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	cluster, err := util.GetOwnerCluster(ctx, r.Client, kairosCluster.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get owner cluster: %w", err)
+	}
+	if cluster == nil {
+		log.Info("Cluster Controller has not yet set OwnerRef")
+		return ctrl.Result{}, nil
+	}
+
+	helper, err := patch.NewHelper(kairosCluster, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if annotations.IsPaused(cluster, kairosCluster) {
+		log.Info("Reconciliation is paused for this object")
+		conditions.MarkTrue(kairosCluster, infrastructurev1beta2.PausedCondition)
+		return ctrl.Result{}, patchObject(ctx, helper, kairosCluster)
+	}
+	conditions.MarkFalse(kairosCluster, infrastructurev1beta2.PausedCondition, infrastructurev1beta2.NotPausedReason, clusterv1.ConditionSeverityInfo, "")
+
+	kairosCluster.Status.ObservedGeneration = kairosCluster.Generation
+	kairosCluster.Status.Ready = true
+	kairosCluster.Status.Initialization.Provisioned = ptr.To(true)
+	conditions.MarkTrue(kairosCluster, clusterv1.ReadyCondition)
+
+	return ctrl.Result{}, patchObject(ctx, helper, kairosCluster)
+}
+
+// patchObject writes obj back to the API server, even when the reconcile
+// context was cancelled by a manager shutdown.
+func patchObject(ctx context.Context, helper *patch.Helper, obj client.Object) error {
+	patchCtx, cancel := shutdown.DetachedContext(ctx)
+	defer cancel()
+	return helper.Patch(patchCtx, obj)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KairosClusterReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	log := ctrl.Log.WithName("KairosCluster")
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infrastructurev1beta2.KairosCluster{}).
+		WithOptions(options).
+		Watches(
+			&clusterv1.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(util.ClusterToInfrastructureMapFunc(ctx,
+				infrastructurev1beta2.GroupVersion.WithKind("KairosCluster"), mgr.GetClient(), &infrastructurev1beta2.KairosCluster{})),
+			builder.WithPredicates(predicates.ClusterUnpaused(log)),
+		).
+		Complete(r)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package infrastructure
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrastructurev1beta2 "github.com/kairos-io/kairos-capi/api/infrastructure/v1beta2"
+)
+
+func newScheme(g *WithT) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(infrastructurev1beta2.AddToScheme(scheme)).To(Succeed())
+	return scheme
+}
+
+func TestKairosClusterReconcile(t *testing.T) {
+	g := NewWithT(t)
+	scheme := newScheme(g)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+	}
+	kairosCluster := &infrastructurev1beta2.KairosCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "Cluster",
+				Name:       cluster.Name,
+			}},
+		},
+		Spec: infrastructurev1beta2.KairosClusterSpec{
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "192.0.2.10", Port: 6443},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(cluster, kairosCluster).
+		WithStatusSubresource(&infrastructurev1beta2.KairosCluster{}).
+		Build()
+	r := &KairosClusterReconciler{Client: c, Scheme: scheme}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kairosCluster)})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	got := &infrastructurev1beta2.KairosCluster{}
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(kairosCluster), got)).To(Succeed())
+	g.Expect(got.Status.Ready).To(BeTrue())
+	g.Expect(got.Status.Initialization.Provisioned).To(HaveValue(BeTrue()))
+	g.Expect(conditions.IsTrue(got, clusterv1.ReadyCondition)).To(BeTrue())
+	g.Expect(conditions.IsFalse(got, infrastructurev1beta2.PausedCondition)).To(BeTrue())
+	g.Expect(got.Spec.ControlPlaneEndpoint.Host).To(Equal("192.0.2.10"))
+}
+
+func TestKairosClusterReconcile_WaitsForOwner(t *testing.T) {
+	g := NewWithT(t)
+	scheme := newScheme(g)
+
+	kairosCluster := &infrastructurev1beta2.KairosCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(kairosCluster).
+		WithStatusSubresource(&infrastructurev1beta2.KairosCluster{}).
+		Build()
+	r := &KairosClusterReconciler{Client: c, Scheme: scheme}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kairosCluster)})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	got := &infrastructurev1beta2.KairosCluster{}
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(kairosCluster), got)).To(Succeed())
+	g.Expect(got.Status.Ready).To(BeFalse())
+}
+
+func TestKairosClusterReconcile_Paused(t *testing.T) {
+	g := NewWithT(t)
+	scheme := newScheme(g)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Spec:       clusterv1.ClusterSpec{Paused: true},
+	}
+	kairosCluster := &infrastructurev1beta2.KairosCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "Cluster",
+				Name:       cluster.Name,
+			}},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(cluster, kairosCluster).
+		WithStatusSubresource(&infrastructurev1beta2.KairosCluster{}).
+		Build()
+	r := &KairosClusterReconciler{Client: c, Scheme: scheme}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kairosCluster)})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	got := &infrastructurev1beta2.KairosCluster{}
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(kairosCluster), got)).To(Succeed())
+	g.Expect(got.Status.Ready).To(BeFalse())
+	g.Expect(conditions.IsTrue(got, infrastructurev1beta2.PausedCondition)).To(BeTrue())
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	infrastructurev1beta2 "github.com/kairos-io/kairos-capi/api/infrastructure/v1beta2"
+)
+
+// KairosHostReconciler reconciles a KairosHost object. It publishes the
+// bootstrap data of the Machine a host is bound to, so the host agent can
+// apply it, and releases the host once its KairosMachine is deleted.
+type KairosHostReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kairoshosts,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kairoshosts/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kairosmachines,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;clusters,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop
+func (r *KairosHostReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	host := &infrastructurev1beta2.KairosHost{}
+	if err := r.Get(ctx, req.NamespacedName, host); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !host.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	helper, err := patch.NewHelper(host, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if host.Spec.ConsumerRef == nil {
+		// Nothing to deliver until a KairosMachine binds the host
+		releaseHost(host)
+		return ctrl.Result{}, patchObject(ctx, helper, host)
+	}
+
+	kairosMachine := &infrastructurev1beta2.KairosMachine{}
+	err = r.Get(ctx, client.ObjectKey{Namespace: host.Namespace, Name: host.Spec.ConsumerRef.Name}, kairosMachine)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	if apierrors.IsNotFound(err) || kairosMachine.UID != host.Spec.ConsumerRef.UID || !kairosMachine.DeletionTimestamp.IsZero() {
+		log.Info("Releasing KairosHost of deleted KairosMachine", "kairosMachine", host.Spec.ConsumerRef.Name)
+		releaseHost(host)
+		return ctrl.Result{}, patchObject(ctx, helper, host)
+	}
+
+	machine, err := util.GetOwnerMachine(ctx, r.Client, kairosMachine.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get owner machine: %w", err)
+	}
+	if machine == nil {
+		return ctrl.Result{}, nil
+	}
+	cluster, err := util.GetClusterFromMetadata(ctx, r.Client, machine.ObjectMeta)
+	if err != nil {
+		log.Info("Machine is missing cluster label or cluster does not exist")
+		return ctrl.Result{}, nil
+	}
+
+	if annotations.IsPaused(cluster, host) {
+		log.Info("Reconciliation is paused for this object")
+		conditions.MarkTrue(host, infrastructurev1beta2.PausedCondition)
+		return ctrl.Result{}, patchObject(ctx, helper, host)
+	}
+	conditions.MarkFalse(host, infrastructurev1beta2.PausedCondition, infrastructurev1beta2.NotPausedReason, clusterv1.ConditionSeverityInfo, "")
+
+	host.Status.ObservedGeneration = host.Generation
+	r.reconcileBootstrapData(log, host, machine)
+
+	return ctrl.Result{}, patchObject(ctx, helper, host)
+}
+
+// reconcileBootstrapData publishes the bootstrap data Secret of machine on
+// host. It waits for the provider ID on the Machine, so the host applies
+// bootstrap data that sets it on the Node.
+func (r *KairosHostReconciler) reconcileBootstrapData(log logr.Logger, host *infrastructurev1beta2.KairosHost, machine *clusterv1.Machine) {
+	if machine.Spec.Bootstrap.DataSecretName == nil {
+		conditions.MarkFalse(host, infrastructurev1beta2.BootstrapDataDeliveredCondition, infrastructurev1beta2.WaitingForBootstrapDataReason, clusterv1.ConditionSeverityInfo, "")
+		return
+	}
+	if machine.Spec.ProviderID == nil || *machine.Spec.ProviderID == "" {
+		conditions.MarkFalse(host, infrastructurev1beta2.BootstrapDataDeliveredCondition, infrastructurev1beta2.WaitingForProviderIDReason, clusterv1.ConditionSeverityInfo, "")
+		return
+	}
+	if host.Status.BootstrapDataSecretName != *machine.Spec.Bootstrap.DataSecretName {
+		log.Info("Delivering bootstrap data to KairosHost", "secret", *machine.Spec.Bootstrap.DataSecretName)
+	}
+	host.Status.BootstrapDataSecretName = *machine.Spec.Bootstrap.DataSecretName
+	conditions.MarkTrue(host, infrastructurev1beta2.BootstrapDataDeliveredCondition)
+}
+
+// releaseHost unbinds host from its KairosMachine. The host agent resets the
+// host once it notices; until then BootstrapDataApplied keeps the host from
+// being bound again.
+func releaseHost(host *infrastructurev1beta2.KairosHost) {
+	host.Spec.ConsumerRef = nil
+	host.Status.BootstrapDataSecretName = ""
+	delete(host.Labels, clusterv1.ClusterNameLabel)
+	conditions.Delete(host, infrastructurev1beta2.BootstrapDataDeliveredCondition)
+	conditions.Delete(host, infrastructurev1beta2.PausedCondition)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KairosHostReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infrastructurev1beta2.KairosHost{}).
+		WithOptions(options).
+		Watches(
+			&infrastructurev1beta2.KairosMachine{},
+			handler.EnqueueRequestsFromMapFunc(kairosMachineToKairosHost),
+		).
+		Watches(
+			&clusterv1.Machine{},
+			handler.EnqueueRequestsFromMapFunc(r.machineToKairosHost),
+		).
+		Complete(r)
+}
+
+// kairosMachineToKairosHost maps a KairosMachine to the KairosHost bound to
+// it, including when the KairosMachine is deleted.
+func kairosMachineToKairosHost(_ context.Context, o client.Object) []reconcile.Request {
+	kairosMachine, ok := o.(*infrastructurev1beta2.KairosMachine)
+	if !ok || kairosMachine.Status.HostRef == nil {
+		return nil
+	}
+	return []reconcile.Request{{
+		NamespacedName: client.ObjectKey{Namespace: kairosMachine.Namespace, Name: kairosMachine.Status.HostRef.Name},
+	}}
+}
+
+// machineToKairosHost maps a Machine to the KairosHost bound to its
+// KairosMachine, so bootstrap data and provider ID changes are delivered.
+func (r *KairosHostReconciler) machineToKairosHost(ctx context.Context, o client.Object) []reconcile.Request {
+	machine, ok := o.(*clusterv1.Machine)
+	if !ok {
+		return nil
+	}
+	ref := machine.Spec.InfrastructureRef
+	if ref.GroupVersionKind().GroupKind() != infrastructurev1beta2.GroupVersion.WithKind("KairosMachine").GroupKind() {
+		return nil
+	}
+	kairosMachine := &infrastructurev1beta2.KairosMachine{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: machine.Namespace, Name: ref.Name}, kairosMachine); err != nil {
+		return nil
+	}
+	return kairosMachineToKairosHost(ctx, kairosMachine)
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package infrastructure
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrastructurev1beta2 "github.com/kairos-io/kairos-capi/api/infrastructure/v1beta2"
+)
+
+func TestKairosHostReconcile(t *testing.T) {
+	tests := []struct {
+		name           string
+		providerID     *string
+		dataSecretName *string
+		wantSecretName string
+		wantReason     string
+	}{
+		{
+			name:       "waits for bootstrap data",
+			wantReason: infrastructurev1beta2.WaitingForBootstrapDataReason,
+		},
+		{
+			name:           "waits for the provider ID",
+			dataSecretName: ptr.To("worker-0"),
+			wantReason:     infrastructurev1beta2.WaitingForProviderIDReason,
+		},
+		{
+			name:           "delivers bootstrap data",
+			dataSecretName: ptr.To("worker-0"),
+			providerID:     ptr.To("kairos://default/worker-0"),
+			wantSecretName: "worker-0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			scheme := newScheme(g)
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+			}
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "worker-0",
+					Namespace: "default",
+					Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster.Name},
+				},
+				Spec: clusterv1.MachineSpec{
+					ClusterName: cluster.Name,
+					Bootstrap:   clusterv1.Bootstrap{DataSecretName: tt.dataSecretName},
+					ProviderID:  tt.providerID,
+				},
+			}
+			kairosMachine := &infrastructurev1beta2.KairosMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "worker-0",
+					Namespace: "default",
+					UID:       types.UID("kairos-machine-uid"),
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: clusterv1.GroupVersion.String(),
+						Kind:       "Machine",
+						Name:       machine.Name,
+					}},
+				},
+			}
+			host := &infrastructurev1beta2.KairosHost{
+				ObjectMeta: metav1.ObjectMeta{Name: "box-1", Namespace: "default"},
+				Spec: infrastructurev1beta2.KairosHostSpec{
+					ConsumerRef: &corev1.ObjectReference{Kind: "KairosMachine", Namespace: "default", Name: "worker-0", UID: kairosMachine.UID},
+				},
+			}
+
+			c := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(cluster, machine, kairosMachine, host).
+				WithStatusSubresource(&infrastructurev1beta2.KairosHost{}).
+				Build()
+			r := &KairosHostReconciler{Client: c, Scheme: scheme}
+
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(host)})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got := &infrastructurev1beta2.KairosHost{}
+			g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(host), got)).To(Succeed())
+			g.Expect(got.Status.BootstrapDataSecretName).To(Equal(tt.wantSecretName))
+			if tt.wantReason == "" {
+				g.Expect(conditions.IsTrue(got, infrastructurev1beta2.BootstrapDataDeliveredCondition)).To(BeTrue())
+			} else {
+				g.Expect(conditions.GetReason(got, infrastructurev1beta2.BootstrapDataDeliveredCondition)).To(Equal(tt.wantReason))
+			}
+		})
+	}
+}
+
+func TestKairosHostReconcile_ReleasesHostOfDeletedMachine(t *testing.T) {
+	g := NewWithT(t)
+	scheme := newScheme(g)
+
+	host := &infrastructurev1beta2.KairosHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "box-1",
+			Namespace: "default",
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: "test-cluster", "site": "store-42"},
+		},
+		Spec: infrastructurev1beta2.KairosHostSpec{
+			ConsumerRef: &corev1.ObjectReference{Kind: "KairosMachine", Namespace: "default", Name: "worker-0", UID: "gone"},
+		},
+		Status: infrastructurev1beta2.KairosHostStatus{BootstrapDataSecretName: "worker-0"},
+	}
+	conditions.MarkTrue(host, infrastructurev1beta2.BootstrapDataDeliveredCondition)
+	conditions.MarkTrue(host, infrastructurev1beta2.BootstrapDataAppliedCondition)
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(host).
+		WithStatusSubresource(&infrastructurev1beta2.KairosHost{}).
+		Build()
+	r := &KairosHostReconciler{Client: c, Scheme: scheme}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(host)})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	got := &infrastructurev1beta2.KairosHost{}
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(host), got)).To(Succeed())
+	g.Expect(got.Spec.ConsumerRef).To(BeNil())
+	g.Expect(got.Status.BootstrapDataSecretName).To(BeEmpty())
+	g.Expect(got.Labels).NotTo(HaveKey(clusterv1.ClusterNameLabel))
+	g.Expect(got.Labels).To(HaveKey("site"))
+	g.Expect(conditions.Has(got, infrastructurev1beta2.BootstrapDataDeliveredCondition)).To(BeFalse())
+	// The agent resets the host before it can be bound again
+	g.Expect(conditions.IsTrue(got, infrastructurev1beta2.BootstrapDataAppliedCondition)).To(BeTrue())
+	g.Expect(hostAvailable(got)).To(BeFalse())
+}
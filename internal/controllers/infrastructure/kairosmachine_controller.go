@@ -0,0 +1,322 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	infrastructurev1beta2 "github.com/kairos-io/kairos-capi/api/infrastructure/v1beta2"
+)
+
+// KairosMachineReconciler reconciles a KairosMachine object. The host behind
+// a KairosMachine already runs Kairos, so the machine is ready as soon as its
+// cluster infrastructure and bootstrap data are and, with a hostSelector, a
+// KairosHost is bound to it.
+type KairosMachineReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kairosmachines,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kairosmachines/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kairosmachines/finalizers,verbs=update
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kairosmachinetemplates,verbs=get;list;watch
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kairoshosts,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;clusters,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop
+func (r *KairosMachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	kairosMachine := &infrastructurev1beta2.KairosMachine{}
+	if err := r.Get(ctx, req.NamespacedName, kairosMachine); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Hosts bound by a hostSelector are released by the KairosHost controller
+	// once the KairosMachine is gone; other hosts are left as is
+	if !kairosMachine.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	machine, err := util.GetOwnerMachine(ctx, r.Client, kairosMachine.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get owner machine: %w", err)
+	}
+	if machine == nil {
+		log.Info("Machine Controller has not yet set OwnerRef")
+		return ctrl.Result{}, nil
+	}
+
+	cluster, err := util.GetClusterFromMetadata(ctx, r.Client, machine.ObjectMeta)
+	if err != nil {
+		log.Info("Machine is missing cluster label or cluster does not exist")
+		return ctrl.Result{}, nil
+	}
+
+	helper, err := patch.NewHelper(kairosMachine, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if annotations.IsPaused(cluster, kairosMachine) {
+		log.Info("Reconciliation is paused for this object")
+		conditions.MarkTrue(kairosMachine, infrastructurev1beta2.PausedCondition)
+		return ctrl.Result{}, patchObject(ctx, helper, kairosMachine)
+	}
+	conditions.MarkFalse(kairosMachine, infrastructurev1beta2.PausedCondition, infrastructurev1beta2.NotPausedReason, clusterv1.ConditionSeverityInfo, "")
+
+	kairosMachine.Status.ObservedGeneration = kairosMachine.Generation
+
+	if !cluster.Status.InfrastructureReady {
+		log.Info("Waiting for the cluster infrastructure to be ready")
+		conditions.MarkFalse(kairosMachine, clusterv1.ReadyCondition, infrastructurev1beta2.WaitingForClusterInfrastructureReason, clusterv1.ConditionSeverityInfo, "")
+		return ctrl.Result{}, patchObject(ctx, helper, kairosMachine)
+	}
+
+	if machine.Spec.Bootstrap.DataSecretName == nil {
+		log.Info("Waiting for the bootstrap data secret of the Machine")
+		conditions.MarkFalse(kairosMachine, clusterv1.ReadyCondition, infrastructurev1beta2.WaitingForBootstrapDataReason, clusterv1.ConditionSeverityInfo, "")
+		return ctrl.Result{}, patchObject(ctx, helper, kairosMachine)
+	}
+
+	if kairosMachine.Spec.HostSelector != nil {
+		bound, err := r.reconcileHost(ctx, log, kairosMachine)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !bound {
+			return ctrl.Result{}, patchObject(ctx, helper, kairosMachine)
+		}
+	}
+
+	// The provider ID is carried into the bootstrap data and set on the Node
+	// once Cluster API copied it to the Machine
+	if kairosMachine.Spec.ProviderID == nil || *kairosMachine.Spec.ProviderID == "" {
+		kairosMachine.Spec.ProviderID = ptr.To(providerID(kairosMachine))
+	}
+
+	kairosMachine.Status.Ready = true
+	kairosMachine.Status.Initialization.Provisioned = ptr.To(true)
+	conditions.MarkTrue(kairosMachine, clusterv1.ReadyCondition)
+
+	return ctrl.Result{}, patchObject(ctx, helper, kairosMachine)
+}
+
+// reconcileHost binds kairosMachine to an available KairosHost matching its
+// hostSelector and reports whether a host is bound. The KairosHost controller
+// then delivers the bootstrap data to the host.
+func (r *KairosMachineReconciler) reconcileHost(ctx context.Context, log logr.Logger, kairosMachine *infrastructurev1beta2.KairosMachine) (bool, error) {
+	if ref := kairosMachine.Status.HostRef; ref != nil {
+		host := &infrastructurev1beta2.KairosHost{}
+		err := r.Get(ctx, client.ObjectKey{Namespace: kairosMachine.Namespace, Name: ref.Name}, host)
+		if err == nil && host.UID == ref.UID {
+			return true, nil
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			return false, err
+		}
+		// The host is gone; the machine cannot move to another one
+		kairosMachine.Status.Ready = false
+		conditions.MarkFalse(kairosMachine, clusterv1.ReadyCondition, infrastructurev1beta2.HostNotFoundReason, clusterv1.ConditionSeverityError,
+			"KairosHost %s was deleted", ref.Name)
+		return false, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(kairosMachine.Spec.HostSelector)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse host selector: %w", err)
+	}
+	hostList := &infrastructurev1beta2.KairosHostList{}
+	if err := r.List(ctx, hostList, client.InNamespace(kairosMachine.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return false, fmt.Errorf("failed to list KairosHosts: %w", err)
+	}
+	sort.Slice(hostList.Items, func(i, j int) bool {
+		return hostList.Items[i].Name < hostList.Items[j].Name
+	})
+
+	var host *infrastructurev1beta2.KairosHost
+	for i := range hostList.Items {
+		// A previous reconcile may have bound a host without recording it
+		if ref := hostList.Items[i].Spec.ConsumerRef; ref != nil && ref.UID == kairosMachine.UID {
+			host = &hostList.Items[i]
+			break
+		}
+	}
+	if host == nil {
+		for i := range hostList.Items {
+			if hostAvailable(&hostList.Items[i]) {
+				host = &hostList.Items[i]
+				break
+			}
+		}
+	}
+	if host == nil {
+		log.Info("Waiting for an available KairosHost")
+		conditions.MarkFalse(kairosMachine, clusterv1.ReadyCondition, infrastructurev1beta2.WaitingForHostReason, clusterv1.ConditionSeverityInfo,
+			"No available KairosHost matches the host selector")
+		return false, nil
+	}
+
+	ref := &corev1.ObjectReference{
+		APIVersion: infrastructurev1beta2.GroupVersion.String(),
+		Kind:       "KairosMachine",
+		Namespace:  kairosMachine.Namespace,
+		Name:       kairosMachine.Name,
+		UID:        kairosMachine.UID,
+	}
+	if host.Spec.ConsumerRef == nil {
+		// The optimistic lock keeps two machines from binding the same host
+		patchBase := client.MergeFromWithOptions(host.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		host.Spec.ConsumerRef = ref
+		if clusterName, ok := kairosMachine.Labels[clusterv1.ClusterNameLabel]; ok {
+			if host.Labels == nil {
+				host.Labels = map[string]string{}
+			}
+			host.Labels[clusterv1.ClusterNameLabel] = clusterName
+		}
+		if err := r.Patch(ctx, host, patchBase); err != nil {
+			return false, fmt.Errorf("failed to bind KairosHost %s: %w", host.Name, err)
+		}
+		log.Info("Bound KairosHost", "host", host.Name)
+	}
+
+	kairosMachine.Status.HostRef = &corev1.ObjectReference{
+		APIVersion: infrastructurev1beta2.GroupVersion.String(),
+		Kind:       "KairosHost",
+		Namespace:  host.Namespace,
+		Name:       host.Name,
+		UID:        host.UID,
+	}
+	return true, nil
+}
+
+// hostAvailable reports whether host can be bound to a KairosMachine. Hosts
+// still carrying the bootstrap data of a previous machine become available
+// once their agent reset them.
+func hostAvailable(host *infrastructurev1beta2.KairosHost) bool {
+	return host.Spec.ConsumerRef == nil &&
+		host.DeletionTimestamp.IsZero() &&
+		!conditions.Has(host, infrastructurev1beta2.BootstrapDataAppliedCondition)
+}
+
+// providerID is the default provider ID of kairosMachine,
+// kairos://<namespace>/<name>.
+func providerID(kairosMachine *infrastructurev1beta2.KairosMachine) string {
+	return infrastructurev1beta2.ProviderIDPrefix + kairosMachine.Namespace + "/" + kairosMachine.Name
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KairosMachineReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	log := ctrl.Log.WithName("KairosMachine")
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infrastructurev1beta2.KairosMachine{}).
+		WithOptions(options).
+		Watches(
+			&clusterv1.Machine{},
+			handler.EnqueueRequestsFromMapFunc(util.MachineToInfrastructureMapFunc(infrastructurev1beta2.GroupVersion.WithKind("KairosMachine"))),
+		).
+		Watches(
+			&clusterv1.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(r.clusterToKairosMachines),
+			builder.WithPredicates(predicates.ClusterUnpaused(log)),
+		).
+		Watches(
+			&infrastructurev1beta2.KairosHost{},
+			handler.EnqueueRequestsFromMapFunc(r.kairosHostToKairosMachines),
+		).
+		Complete(r)
+}
+
+// kairosHostToKairosMachines maps a KairosHost to the KairosMachine it is
+// bound to or, for unbound hosts, to the KairosMachines waiting for a host.
+func (r *KairosMachineReconciler) kairosHostToKairosMachines(ctx context.Context, o client.Object) []reconcile.Request {
+	host, ok := o.(*infrastructurev1beta2.KairosHost)
+	if !ok {
+		return nil
+	}
+	if ref := host.Spec.ConsumerRef; ref != nil {
+		return []reconcile.Request{{
+			NamespacedName: client.ObjectKey{Namespace: host.Namespace, Name: ref.Name},
+		}}
+	}
+
+	kairosMachineList := &infrastructurev1beta2.KairosMachineList{}
+	if err := r.List(ctx, kairosMachineList, client.InNamespace(host.Namespace)); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, kairosMachine := range kairosMachineList.Items {
+		if kairosMachine.Spec.HostSelector == nil || kairosMachine.Status.HostRef != nil {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(&kairosMachine),
+		})
+	}
+	return requests
+}
+
+// clusterToKairosMachines maps a Cluster to the KairosMachines of its Machines
+// so they notice the cluster infrastructure becoming ready.
+func (r *KairosMachineReconciler) clusterToKairosMachines(ctx context.Context, o client.Object) []reconcile.Request {
+	cluster, ok := o.(*clusterv1.Cluster)
+	if !ok {
+		return nil
+	}
+
+	machineList := &clusterv1.MachineList{}
+	if err := r.List(ctx, machineList, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: cluster.Name}); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, machine := range machineList.Items {
+		ref := machine.Spec.InfrastructureRef
+		if ref.GroupVersionKind().GroupKind() != infrastructurev1beta2.GroupVersion.WithKind("KairosMachine").GroupKind() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKey{Namespace: machine.Namespace, Name: ref.Name},
+		})
+	}
+	return requests
+}
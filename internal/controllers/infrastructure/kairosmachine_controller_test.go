@@ -0,0 +1,303 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package infrastructure
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrastructurev1beta2 "github.com/kairos-io/kairos-capi/api/infrastructure/v1beta2"
+)
+
+func TestKairosMachineReconcile(t *testing.T) {
+	tests := []struct {
+		name                string
+		infrastructureReady bool
+		dataSecretName      *string
+		providerID          *string
+		wantReady           bool
+		wantReason          string
+		wantProviderID      string
+	}{
+		{
+			name:       "waits for the cluster infrastructure",
+			wantReason: infrastructurev1beta2.WaitingForClusterInfrastructureReason,
+		},
+		{
+			name:                "waits for bootstrap data",
+			infrastructureReady: true,
+			wantReason:          infrastructurev1beta2.WaitingForBootstrapDataReason,
+		},
+		{
+			name:                "defaults the provider ID",
+			infrastructureReady: true,
+			dataSecretName:      ptr.To("test-machine-bootstrap"),
+			wantReady:           true,
+			wantProviderID:      "kairos://default/test-kairos-machine",
+		},
+		{
+			name:                "keeps a user provided provider ID",
+			infrastructureReady: true,
+			dataSecretName:      ptr.To("test-machine-bootstrap"),
+			providerID:          ptr.To("kairos://edge-site-1/box-7"),
+			wantReady:           true,
+			wantProviderID:      "kairos://edge-site-1/box-7",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			scheme := newScheme(g)
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+				Status:     clusterv1.ClusterStatus{InfrastructureReady: tt.infrastructureReady},
+			}
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-machine",
+					Namespace: "default",
+					Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster.Name},
+				},
+				Spec: clusterv1.MachineSpec{
+					ClusterName: cluster.Name,
+					Bootstrap:   clusterv1.Bootstrap{DataSecretName: tt.dataSecretName},
+					InfrastructureRef: corev1.ObjectReference{
+						APIVersion: infrastructurev1beta2.GroupVersion.String(),
+						Kind:       "KairosMachine",
+						Name:       "test-kairos-machine",
+					},
+				},
+			}
+			kairosMachine := &infrastructurev1beta2.KairosMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-kairos-machine",
+					Namespace: "default",
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: clusterv1.GroupVersion.String(),
+						Kind:       "Machine",
+						Name:       machine.Name,
+					}},
+				},
+				Spec: infrastructurev1beta2.KairosMachineSpec{ProviderID: tt.providerID},
+			}
+
+			c := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(cluster, machine, kairosMachine).
+				WithStatusSubresource(&infrastructurev1beta2.KairosMachine{}).
+				Build()
+			r := &KairosMachineReconciler{Client: c, Scheme: scheme}
+
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kairosMachine)})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got := &infrastructurev1beta2.KairosMachine{}
+			g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(kairosMachine), got)).To(Succeed())
+			g.Expect(got.Status.Ready).To(Equal(tt.wantReady))
+			if tt.wantReady {
+				g.Expect(conditions.IsTrue(got, clusterv1.ReadyCondition)).To(BeTrue())
+				g.Expect(got.Status.Initialization.Provisioned).To(HaveValue(BeTrue()))
+				g.Expect(got.Spec.ProviderID).To(HaveValue(Equal(tt.wantProviderID)))
+			} else {
+				g.Expect(conditions.GetReason(got, clusterv1.ReadyCondition)).To(Equal(tt.wantReason))
+				g.Expect(got.Spec.ProviderID).To(BeNil())
+			}
+		})
+	}
+}
+
+func TestKairosMachineReconcile_BindsHost(t *testing.T) {
+	g := NewWithT(t)
+	scheme := newScheme(g)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Status:     clusterv1.ClusterStatus{InfrastructureReady: true},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker-0",
+			Namespace: "default",
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster.Name},
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: cluster.Name,
+			Bootstrap:   clusterv1.Bootstrap{DataSecretName: ptr.To("worker-0")},
+		},
+	}
+	kairosMachine := &infrastructurev1beta2.KairosMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker-0",
+			Namespace: "default",
+			UID:       types.UID("kairos-machine-uid"),
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster.Name},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "Machine",
+				Name:       machine.Name,
+			}},
+		},
+		Spec: infrastructurev1beta2.KairosMachineSpec{
+			HostSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"site": "store-42"}},
+		},
+	}
+	otherSite := &infrastructurev1beta2.KairosHost{
+		ObjectMeta: metav1.ObjectMeta{Name: "box-0", Namespace: "default", Labels: map[string]string{"site": "store-43"}},
+	}
+	bound := &infrastructurev1beta2.KairosHost{
+		ObjectMeta: metav1.ObjectMeta{Name: "box-1", Namespace: "default", Labels: map[string]string{"site": "store-42"}},
+		Spec: infrastructurev1beta2.KairosHostSpec{
+			ConsumerRef: &corev1.ObjectReference{Kind: "KairosMachine", Namespace: "default", Name: "worker-1", UID: "other"},
+		},
+	}
+	notReset := &infrastructurev1beta2.KairosHost{
+		ObjectMeta: metav1.ObjectMeta{Name: "box-2", Namespace: "default", Labels: map[string]string{"site": "store-42"}},
+	}
+	conditions.MarkTrue(notReset, infrastructurev1beta2.BootstrapDataAppliedCondition)
+	available := &infrastructurev1beta2.KairosHost{
+		ObjectMeta: metav1.ObjectMeta{Name: "box-3", Namespace: "default", Labels: map[string]string{"site": "store-42"}},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(cluster, machine, kairosMachine, otherSite, bound, notReset).
+		WithStatusSubresource(&infrastructurev1beta2.KairosMachine{}, &infrastructurev1beta2.KairosHost{}).
+		Build()
+	r := &KairosMachineReconciler{Client: c, Scheme: scheme}
+
+	// No host is available yet
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kairosMachine)})
+	g.Expect(err).NotTo(HaveOccurred())
+	got := &infrastructurev1beta2.KairosMachine{}
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(kairosMachine), got)).To(Succeed())
+	g.Expect(got.Status.Ready).To(BeFalse())
+	g.Expect(conditions.GetReason(got, clusterv1.ReadyCondition)).To(Equal(infrastructurev1beta2.WaitingForHostReason))
+
+	g.Expect(c.Create(context.Background(), available)).To(Succeed())
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kairosMachine)})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(kairosMachine), got)).To(Succeed())
+	g.Expect(got.Status.Ready).To(BeTrue())
+	g.Expect(got.Status.HostRef).NotTo(BeNil())
+	g.Expect(got.Status.HostRef.Name).To(Equal("box-3"))
+
+	host := &infrastructurev1beta2.KairosHost{}
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(available), host)).To(Succeed())
+	g.Expect(host.Spec.ConsumerRef).NotTo(BeNil())
+	g.Expect(host.Spec.ConsumerRef.UID).To(Equal(kairosMachine.UID))
+	g.Expect(host.Labels).To(HaveKeyWithValue(clusterv1.ClusterNameLabel, cluster.Name))
+
+	// A deleted host is reported instead of binding another one
+	g.Expect(c.Delete(context.Background(), host)).To(Succeed())
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kairosMachine)})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(kairosMachine), got)).To(Succeed())
+	g.Expect(got.Status.Ready).To(BeFalse())
+	g.Expect(conditions.GetReason(got, clusterv1.ReadyCondition)).To(Equal(infrastructurev1beta2.HostNotFoundReason))
+}
+
+func TestKairosHostToKairosMachines(t *testing.T) {
+	g := NewWithT(t)
+	scheme := newScheme(g)
+
+	waiting := &infrastructurev1beta2.KairosMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "waiting", Namespace: "default"},
+		Spec:       infrastructurev1beta2.KairosMachineSpec{HostSelector: &metav1.LabelSelector{}},
+	}
+	withHost := &infrastructurev1beta2.KairosMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "with-host", Namespace: "default"},
+		Spec:       infrastructurev1beta2.KairosMachineSpec{HostSelector: &metav1.LabelSelector{}},
+		Status:     infrastructurev1beta2.KairosMachineStatus{HostRef: &corev1.ObjectReference{Name: "box-0"}},
+	}
+	withoutSelector := &infrastructurev1beta2.KairosMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "without-selector", Namespace: "default"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(waiting, withHost, withoutSelector).Build()
+	r := &KairosMachineReconciler{Client: c, Scheme: scheme}
+
+	requests := r.kairosHostToKairosMachines(context.Background(), &infrastructurev1beta2.KairosHost{
+		ObjectMeta: metav1.ObjectMeta{Name: "box-1", Namespace: "default"},
+	})
+	g.Expect(requests).To(HaveLen(1))
+	g.Expect(requests[0].Name).To(Equal("waiting"))
+
+	requests = r.kairosHostToKairosMachines(context.Background(), &infrastructurev1beta2.KairosHost{
+		ObjectMeta: metav1.ObjectMeta{Name: "box-0", Namespace: "default"},
+		Spec: infrastructurev1beta2.KairosHostSpec{
+			ConsumerRef: &corev1.ObjectReference{Name: "with-host"},
+		},
+	})
+	g.Expect(requests).To(HaveLen(1))
+	g.Expect(requests[0].Name).To(Equal("with-host"))
+}
+
+func TestClusterToKairosMachines(t *testing.T) {
+	g := NewWithT(t)
+	scheme := newScheme(g)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+	}
+	kairosMachine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kairos",
+			Namespace: "default",
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster.Name},
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: cluster.Name,
+			InfrastructureRef: corev1.ObjectReference{
+				APIVersion: infrastructurev1beta2.GroupVersion.String(),
+				Kind:       "KairosMachine",
+				Name:       "kairos-infra",
+			},
+		},
+	}
+	otherMachine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "docker",
+			Namespace: "default",
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster.Name},
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: cluster.Name,
+			InfrastructureRef: corev1.ObjectReference{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+				Kind:       "DockerMachine",
+				Name:       "docker-infra",
+			},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, kairosMachine, otherMachine).Build()
+	r := &KairosMachineReconciler{Client: c, Scheme: scheme}
+
+	requests := r.clusterToKairosMachines(context.Background(), cluster)
+	g.Expect(requests).To(HaveLen(1))
+	g.Expect(requests[0].Name).To(Equal("kairos-infra"))
+	g.Expect(requests[0].Namespace).To(Equal("default"))
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package dockerconfig reads registry credentials from the docker config JSON
+// stored in kubernetes.io/dockerconfigjson and kubernetes.io/dockercfg Secrets.
+package dockerconfig
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// dockerHubRegistry is the registry host serving docker.io images
+const dockerHubRegistry = "registry-1.docker.io"
+
+// Auth holds the credentials of a registry host
+type Auth struct {
+	// Registry is the registry host, e.g. "registry.example.com:5000"
+	Registry string
+	// Auth is the base64-encoded "username:password"
+	Auth string
+}
+
+type authEntry struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+}
+
+// Parse returns the credentials in a docker config JSON, sorted by registry.
+// Both the {"auths": {...}} format and the legacy .dockercfg format are
+// accepted. Registry keys are reduced to their host, and Docker Hub keys map
+// to the host serving docker.io images.
+func Parse(data []byte) ([]Auth, error) {
+	var config struct {
+		Auths map[string]authEntry `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse docker config: %w", err)
+	}
+	entries := config.Auths
+	if entries == nil {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse docker config: %w", err)
+		}
+	}
+
+	var auths []Auth
+	for key, entry := range entries {
+		registry := RegistryHost(key)
+		if registry == "" {
+			return nil, fmt.Errorf("invalid registry %q in docker config", key)
+		}
+		auth := entry.Auth
+		if auth == "" {
+			if entry.Username == "" && entry.Password == "" {
+				continue
+			}
+			auth = base64.StdEncoding.EncodeToString([]byte(entry.Username + ":" + entry.Password))
+		} else if _, err := base64.StdEncoding.DecodeString(auth); err != nil {
+			return nil, fmt.Errorf("invalid auth for registry %q in docker config: %w", key, err)
+		}
+		auths = append(auths, Auth{Registry: registry, Auth: auth})
+	}
+	sort.Slice(auths, func(i, j int) bool { return auths[i].Registry < auths[j].Registry })
+	return auths, nil
+}
+
+// RegistryHost returns the registry host of a docker config key, which may be
+// a host or a URL such as "https://index.docker.io/v1/".
+func RegistryHost(key string) string {
+	host := key
+	if strings.Contains(key, "://") {
+		u, err := url.Parse(key)
+		if err != nil {
+			return ""
+		}
+		host = u.Host
+	} else if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+
+	switch host {
+	case "docker.io", "index.docker.io":
+		return dockerHubRegistry
+	}
+	if strings.ContainsAny(host, " \t\n\"'") {
+		return ""
+	}
+	return host
+}
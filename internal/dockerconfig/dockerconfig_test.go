@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package dockerconfig
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParse(t *testing.T) {
+	g := NewWithT(t)
+
+	auths, err := Parse([]byte(`{"auths": {
+		"https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNz"},
+		"registry.example.com:5000": {"username": "user", "password": "pass"},
+		"ghcr.io": {}
+	}}`))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(auths).To(Equal([]Auth{
+		{Registry: "registry-1.docker.io", Auth: "dXNlcjpwYXNz"},
+		{Registry: "registry.example.com:5000", Auth: "dXNlcjpwYXNz"},
+	}))
+
+	// Legacy .dockercfg format
+	auths, err = Parse([]byte(`{"quay.io": {"auth": "dXNlcjpwYXNz"}}`))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(auths).To(Equal([]Auth{{Registry: "quay.io", Auth: "dXNlcjpwYXNz"}}))
+
+	_, err = Parse([]byte(`not json`))
+	g.Expect(err).To(HaveOccurred())
+	_, err = Parse([]byte(`{"auths": {"https://": {"auth": "eA=="}}}`))
+	g.Expect(err).To(HaveOccurred())
+	_, err = Parse([]byte(`{"auths": {"quay.io": {"auth": "not base64\""}}}`))
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestRegistryHost(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(RegistryHost("docker.io")).To(Equal("registry-1.docker.io"))
+	g.Expect(RegistryHost("https://index.docker.io/v1/")).To(Equal("registry-1.docker.io"))
+	g.Expect(RegistryHost("registry.example.com:5000/v2/")).To(Equal("registry.example.com:5000"))
+	g.Expect(RegistryHost("http://10.0.0.1:5000")).To(Equal("10.0.0.1:5000"))
+	g.Expect(RegistryHost(`bad"host`)).To(BeEmpty())
+}
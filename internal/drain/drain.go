@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package drain cordons the nodes of control plane machines and evicts their
+// pods through the workload cluster API before the Machine controller shuts
+// them down, so scale down and rolling updates do not disrupt workloads.
+package drain
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PreDrainHookAnnotation keeps the Machine controller from draining and
+// shutting down a control plane node until the control plane controller
+// drained it.
+const PreDrainHookAnnotation = clusterv1.PreDrainDeleteHookAnnotationPrefix + "/kairos-control-plane"
+
+// Cordon marks node unschedulable.
+func Cordon(ctx context.Context, c client.Client, node *corev1.Node) error {
+	if node.Spec.Unschedulable {
+		return nil
+	}
+	patch := client.MergeFrom(node.DeepCopy())
+	node.Spec.Unschedulable = true
+	if err := c.Patch(ctx, node, patch); err != nil {
+		return fmt.Errorf("failed to cordon node %s: %w", node.Name, err)
+	}
+	return nil
+}
+
+// Drain requests the eviction of every pod on node that a drain removes and
+// returns the number of them that are still running. Evictions refused by a
+// PodDisruptionBudget are retried on the next call. Pods on a node that is
+// not Ready are done once they are terminating, as their kubelet cannot
+// confirm the deletion.
+func Drain(ctx context.Context, c client.Client, node *corev1.Node, nodeReady bool) (int, error) {
+	podList := &corev1.PodList{}
+	if err := c.List(ctx, podList, client.MatchingFields{"spec.nodeName": node.Name}); err != nil {
+		return 0, fmt.Errorf("failed to list pods of node %s: %w", node.Name, err)
+	}
+
+	remaining := 0
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Spec.NodeName != node.Name || !evicted(pod) {
+			continue
+		}
+		if !pod.DeletionTimestamp.IsZero() {
+			if nodeReady {
+				remaining++
+			}
+			continue
+		}
+		eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace}}
+		if err := c.SubResource("eviction").Create(ctx, pod, eviction); err != nil {
+			switch {
+			case apierrors.IsNotFound(err):
+				continue
+			case apierrors.IsTooManyRequests(err):
+				// Blocked by a PodDisruptionBudget for now
+			default:
+				return 0, fmt.Errorf("failed to evict pod %s/%s: %w", pod.Namespace, pod.Name, err)
+			}
+		}
+		remaining++
+	}
+	return remaining, nil
+}
+
+// evicted reports whether a drain removes pod. DaemonSet pods are recreated
+// on the node right away, mirror pods belong to static pods, and finished pods
+// hold no workload.
+func evicted(pod *corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+		return false
+	}
+	if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "DaemonSet" {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func testPod(name, nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: nodeName},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func newClient(g *WithT, objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+		WithIndex(&corev1.Pod{}, "spec.nodeName", func(obj client.Object) []string {
+			return []string{obj.(*corev1.Pod).Spec.NodeName}
+		}).
+		Build()
+}
+
+func TestCordon(t *testing.T) {
+	g := NewWithT(t)
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "cp-0"}}
+	c := newClient(g, node)
+
+	g.Expect(Cordon(context.Background(), c, node)).To(Succeed())
+	g.Expect(Cordon(context.Background(), c, node)).To(Succeed())
+
+	got := &corev1.Node{}
+	g.Expect(c.Get(context.Background(), client.ObjectKey{Name: "cp-0"}, got)).To(Succeed())
+	g.Expect(got.Spec.Unschedulable).To(BeTrue())
+}
+
+func TestDrain(t *testing.T) {
+	g := NewWithT(t)
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "cp-0"}}
+
+	controller := true
+	daemonSetPod := testPod("daemon", "cp-0")
+	daemonSetPod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "ds", Controller: &controller}}
+	mirrorPod := testPod("static", "cp-0")
+	mirrorPod.Annotations = map[string]string{corev1.MirrorPodAnnotationKey: "hash"}
+	finishedPod := testPod("job", "cp-0")
+	finishedPod.Status.Phase = corev1.PodSucceeded
+	terminatingPod := testPod("terminating", "cp-0")
+	terminatingPod.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	terminatingPod.Finalizers = []string{"test"}
+
+	c := newClient(g, node, testPod("app", "cp-0"), testPod("other", "cp-1"), daemonSetPod, mirrorPod, finishedPod, terminatingPod)
+
+	// The terminating pod is still running on a Ready node
+	remaining, err := Drain(context.Background(), c, node, true)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(remaining).To(Equal(2))
+
+	pods := &corev1.PodList{}
+	g.Expect(c.List(context.Background(), pods)).To(Succeed())
+	var names []string
+	for _, pod := range pods.Items {
+		names = append(names, pod.Name)
+	}
+	g.Expect(names).To(ConsistOf("other", "daemon", "static", "job", "terminating"))
+
+	// Pods of an unreachable node are done once they are terminating
+	remaining, err = Drain(context.Background(), c, node, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(remaining).To(Equal(0))
+}
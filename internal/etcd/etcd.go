@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package etcd manages the etcd members of multi-node k0s control planes.
+// Members are listed and removed with the k0s CLI on a control plane node, so
+// the management cluster does not need etcd client certificates or network
+// access to the etcd peer port.
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// PreTerminateHookAnnotation keeps the Machine controller from shutting down
+// a control plane node after drain until its etcd member was removed.
+const PreTerminateHookAnnotation = clusterv1.PreTerminateDeleteHookAnnotationPrefix + "/kairos-control-plane"
+
+const (
+	memberListCommand = "k0s etcd member-list"
+	leaveCommand      = "k0s etcd leave --peer-address=%s"
+)
+
+// Executor runs a command on the node of a control plane machine and returns
+// its standard output.
+type Executor interface {
+	Run(ctx context.Context, machine *clusterv1.Machine, command string) ([]byte, error)
+}
+
+// Quorum returns the number of members an etcd cluster of the given size
+// needs to accept writes.
+func Quorum(members int) int {
+	return members/2 + 1
+}
+
+// CanRemoveMember reports whether one of members etcd members can be removed
+// while healthyRemaining members are left to keep quorum.
+func CanRemoveMember(members, healthyRemaining int) bool {
+	if members <= 1 {
+		return false
+	}
+	return healthyRemaining >= Quorum(members-1)
+}
+
+// ParseMemberList parses the output of k0s etcd member-list into a map of
+// member names to peer URLs.
+func ParseMemberList(out []byte) (map[string]string, error) {
+	var list struct {
+		Members map[string]string `json:"members"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse etcd member list: %w", err)
+	}
+	if list.Members == nil {
+		return nil, fmt.Errorf("etcd member list is empty")
+	}
+	return list.Members, nil
+}
+
+// PeerAddress returns the peer address of the etcd member of machine. Members
+// are named after the node hostname, so they are matched by node and machine
+// name first and by the machine addresses otherwise. It returns false when
+// machine has no member.
+func PeerAddress(members map[string]string, machine *clusterv1.Machine) (string, bool) {
+	names := []string{machine.Name}
+	if machine.Status.NodeRef != nil {
+		names = append([]string{machine.Status.NodeRef.Name}, names...)
+	}
+	for _, name := range names {
+		if peerURL, ok := members[name]; ok {
+			if host := peerHost(peerURL); host != "" {
+				return host, true
+			}
+		}
+	}
+	for _, peerURL := range members {
+		host := peerHost(peerURL)
+		for _, address := range machine.Status.Addresses {
+			if host != "" && address.Address == host {
+				return host, true
+			}
+		}
+	}
+	return "", false
+}
+
+// peerHost returns the host of an etcd peer URL such as https://10.0.0.2:2380.
+func peerHost(peerURL string) string {
+	u, err := url.Parse(peerURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// RemoveMember removes the etcd member of machine through the node of peer.
+// A machine without a member, e.g. because it never joined or already left,
+// is not an error.
+func RemoveMember(ctx context.Context, exec Executor, peer, machine *clusterv1.Machine) error {
+	out, err := exec.Run(ctx, peer, memberListCommand)
+	if err != nil {
+		return fmt.Errorf("failed to list etcd members on machine %s: %w", peer.Name, err)
+	}
+	members, err := ParseMemberList(out)
+	if err != nil {
+		return err
+	}
+	address, ok := PeerAddress(members, machine)
+	if !ok {
+		return nil
+	}
+	if ip := net.ParseIP(address); ip == nil {
+		return fmt.Errorf("etcd member of machine %s has invalid peer address %q", machine.Name, address)
+	}
+	if _, err := exec.Run(ctx, peer, fmt.Sprintf(leaveCommand, address)); err != nil {
+		return fmt.Errorf("failed to remove etcd member %s of machine %s: %w", address, machine.Name, err)
+	}
+	return nil
+}
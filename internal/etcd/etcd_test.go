@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const memberList = `{"members":{"cp-0":"https://10.0.0.10:2380","cp-1":"https://10.0.0.11:2380","node-c":"https://10.0.0.12:2380"}}`
+
+type fakeExecutor struct {
+	memberList []byte
+	err        error
+	commands   []string
+}
+
+func (f *fakeExecutor) Run(_ context.Context, machine *clusterv1.Machine, command string) ([]byte, error) {
+	f.commands = append(f.commands, machine.Name+": "+command)
+	if f.err != nil {
+		return nil, f.err
+	}
+	if command == memberListCommand {
+		return f.memberList, nil
+	}
+	return nil, nil
+}
+
+func testMachine(name, nodeName string, addresses ...string) *clusterv1.Machine {
+	machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if nodeName != "" {
+		machine.Status.NodeRef = &corev1.ObjectReference{Name: nodeName}
+	}
+	for _, address := range addresses {
+		machine.Status.Addresses = append(machine.Status.Addresses, clusterv1.MachineAddress{Type: clusterv1.MachineInternalIP, Address: address})
+	}
+	return machine
+}
+
+func TestCanRemoveMember(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(Quorum(1)).To(Equal(1))
+	g.Expect(Quorum(3)).To(Equal(2))
+	g.Expect(Quorum(4)).To(Equal(3))
+
+	g.Expect(CanRemoveMember(1, 0)).To(BeFalse())
+	g.Expect(CanRemoveMember(3, 2)).To(BeTrue())
+	g.Expect(CanRemoveMember(3, 1)).To(BeFalse())
+	g.Expect(CanRemoveMember(4, 2)).To(BeTrue())
+	g.Expect(CanRemoveMember(5, 2)).To(BeFalse())
+}
+
+func TestPeerAddress(t *testing.T) {
+	g := NewWithT(t)
+
+	members, err := ParseMemberList([]byte(memberList))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(members).To(HaveLen(3))
+
+	address, ok := PeerAddress(members, testMachine("m-0", "cp-0"))
+	g.Expect(ok).To(BeTrue())
+	g.Expect(address).To(Equal("10.0.0.10"))
+
+	address, ok = PeerAddress(members, testMachine("cp-1", ""))
+	g.Expect(ok).To(BeTrue())
+	g.Expect(address).To(Equal("10.0.0.11"))
+
+	address, ok = PeerAddress(members, testMachine("m-2", "renamed", "10.0.0.12"))
+	g.Expect(ok).To(BeTrue())
+	g.Expect(address).To(Equal("10.0.0.12"))
+
+	_, ok = PeerAddress(members, testMachine("m-3", "cp-3", "10.0.0.13"))
+	g.Expect(ok).To(BeFalse())
+
+	_, err = ParseMemberList([]byte("Error: not a controller"))
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestRemoveMember(t *testing.T) {
+	g := NewWithT(t)
+
+	peer := testMachine("cp-0", "cp-0")
+	exec := &fakeExecutor{memberList: []byte(memberList)}
+	g.Expect(RemoveMember(context.Background(), exec, peer, testMachine("cp-1", "cp-1"))).To(Succeed())
+	g.Expect(exec.commands).To(Equal([]string{
+		"cp-0: k0s etcd member-list",
+		"cp-0: k0s etcd leave --peer-address=10.0.0.11",
+	}))
+
+	// A machine that already left is not removed again
+	exec = &fakeExecutor{memberList: []byte(memberList)}
+	g.Expect(RemoveMember(context.Background(), exec, peer, testMachine("cp-5", "cp-5"))).To(Succeed())
+	g.Expect(exec.commands).To(HaveLen(1))
+
+	exec = &fakeExecutor{err: errors.New("connection refused")}
+	err := RemoveMember(context.Background(), exec, peer, testMachine("cp-1", "cp-1"))
+	g.Expect(err).To(MatchError(ContainSubstring("connection refused")))
+}
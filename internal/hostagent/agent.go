@@ -0,0 +1,222 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package hostagent implements the agent that runs on hosts already running
+// Kairos. It registers the host as a KairosHost, applies the bootstrap data
+// delivered once a KairosMachine binds the host and resets the host once it
+// is released.
+package hostagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrastructurev1beta2 "github.com/kairos-io/kairos-capi/api/infrastructure/v1beta2"
+)
+
+const (
+	// DefaultConfigPath is where the bootstrap data is written. Kairos applies
+	// cloud-configs in /oem on every boot.
+	DefaultConfigPath = "/oem/90_kairos_capi.yaml"
+
+	// bootstrapDataKey is the key of the bootstrap data in its Secret
+	bootstrapDataKey = "value"
+
+	// BootstrapDataAppliedReason is set on BootstrapDataApplied by the agent
+	BootstrapDataAppliedReason = "Applied"
+)
+
+// DefaultApplyCommand reboots the host so Kairos applies the bootstrap data.
+var DefaultApplyCommand = []string{"reboot"}
+
+// DefaultResetCommand resets the host to its factory state, wiping the
+// Kubernetes data of the cluster it was part of.
+var DefaultResetCommand = []string{"kairos-agent", "reset", "--unattended", "--reboot"}
+
+// Agent registers a host and applies the bootstrap data delivered to it.
+type Agent struct {
+	Client client.Client
+	Log    logr.Logger
+
+	// Name and Namespace of the KairosHost of this host
+	Name      string
+	Namespace string
+	// Labels are set on the KairosHost so KairosMachines can select it
+	Labels map[string]string
+	// Address is reported in the KairosHost spec
+	Address string
+
+	// ConfigPath is the file the bootstrap data is written to
+	ConfigPath string
+	// ApplyCommand runs after the bootstrap data was written
+	ApplyCommand []string
+	// ResetCommand runs once the host is released by its KairosMachine
+	ResetCommand []string
+
+	// run executes commands; replaced in tests
+	run func(ctx context.Context, command []string) error
+}
+
+// Run registers the host and syncs it every interval until ctx is done.
+// Sync errors are logged and retried.
+func (a *Agent) Run(ctx context.Context, interval time.Duration) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := a.Sync(ctx); err != nil {
+			a.Log.Error(err, "Failed to sync KairosHost")
+		}
+	}, interval)
+}
+
+// Sync registers the host and applies or removes its bootstrap data.
+func (a *Agent) Sync(ctx context.Context) error {
+	host, err := a.register(ctx)
+	if err != nil {
+		return err
+	}
+
+	applied := conditions.IsTrue(host, infrastructurev1beta2.BootstrapDataAppliedCondition)
+	switch {
+	case host.Spec.ConsumerRef != nil && host.Status.BootstrapDataSecretName != "" && !applied:
+		return a.apply(ctx, host)
+	case host.Spec.ConsumerRef == nil && conditions.Has(host, infrastructurev1beta2.BootstrapDataAppliedCondition):
+		return a.reset(ctx, host)
+	}
+	return nil
+}
+
+// register creates the KairosHost of this host or updates its labels and
+// address.
+func (a *Agent) register(ctx context.Context) (*infrastructurev1beta2.KairosHost, error) {
+	host := &infrastructurev1beta2.KairosHost{}
+	err := a.Client.Get(ctx, client.ObjectKey{Namespace: a.Namespace, Name: a.Name}, host)
+	if apierrors.IsNotFound(err) {
+		host = &infrastructurev1beta2.KairosHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      a.Name,
+				Namespace: a.Namespace,
+				Labels:    a.Labels,
+			},
+			Spec: infrastructurev1beta2.KairosHostSpec{Address: a.Address},
+		}
+		if err := a.Client.Create(ctx, host); err != nil {
+			return nil, fmt.Errorf("failed to register KairosHost: %w", err)
+		}
+		a.Log.Info("Registered KairosHost", "name", a.Name, "namespace", a.Namespace)
+		return host, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get KairosHost: %w", err)
+	}
+
+	patchBase := client.MergeFrom(host.DeepCopy())
+	changed := false
+	for k, v := range a.Labels {
+		if host.Labels[k] != v {
+			if host.Labels == nil {
+				host.Labels = map[string]string{}
+			}
+			host.Labels[k] = v
+			changed = true
+		}
+	}
+	if a.Address != "" && host.Spec.Address != a.Address {
+		host.Spec.Address = a.Address
+		changed = true
+	}
+	if changed {
+		if err := a.Client.Patch(ctx, host, patchBase); err != nil {
+			return nil, fmt.Errorf("failed to update KairosHost: %w", err)
+		}
+	}
+	return host, nil
+}
+
+// apply writes the bootstrap data delivered to host, reports it applied and
+// runs the apply command.
+func (a *Agent) apply(ctx context.Context, host *infrastructurev1beta2.KairosHost) error {
+	secret := &corev1.Secret{}
+	if err := a.Client.Get(ctx, client.ObjectKey{Namespace: host.Namespace, Name: host.Status.BootstrapDataSecretName}, secret); err != nil {
+		return fmt.Errorf("failed to get bootstrap data secret %s: %w", host.Status.BootstrapDataSecretName, err)
+	}
+	data, ok := secret.Data[bootstrapDataKey]
+	if !ok || len(data) == 0 {
+		return fmt.Errorf("bootstrap data secret %s has no %q key", secret.Name, bootstrapDataKey)
+	}
+	if err := os.MkdirAll(filepath.Dir(a.ConfigPath), 0o700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(a.ConfigPath), err)
+	}
+	if err := os.WriteFile(a.ConfigPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write bootstrap data: %w", err)
+	}
+
+	helper, err := patch.NewHelper(host, a.Client)
+	if err != nil {
+		return err
+	}
+	conditions.MarkTrue(host, infrastructurev1beta2.BootstrapDataAppliedCondition)
+	if err := helper.Patch(ctx, host); err != nil {
+		return fmt.Errorf("failed to report bootstrap data applied: %w", err)
+	}
+	a.Log.Info("Applied bootstrap data", "secret", secret.Name, "path", a.ConfigPath)
+	return a.runCommand(ctx, a.ApplyCommand)
+}
+
+// reset removes the bootstrap data of a released host, makes it available
+// again and runs the reset command.
+func (a *Agent) reset(ctx context.Context, host *infrastructurev1beta2.KairosHost) error {
+	if err := os.Remove(a.ConfigPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove bootstrap data: %w", err)
+	}
+
+	helper, err := patch.NewHelper(host, a.Client)
+	if err != nil {
+		return err
+	}
+	conditions.Delete(host, infrastructurev1beta2.BootstrapDataAppliedCondition)
+	if err := helper.Patch(ctx, host); err != nil {
+		return fmt.Errorf("failed to report host reset: %w", err)
+	}
+	a.Log.Info("KairosHost released, resetting host")
+	return a.runCommand(ctx, a.ResetCommand)
+}
+
+func (a *Agent) runCommand(ctx context.Context, command []string) error {
+	if len(command) == 0 {
+		return nil
+	}
+	if a.run != nil {
+		return a.run(ctx, command)
+	}
+	out, err := exec.CommandContext(ctx, command[0], command[1:]...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%q failed: %w: %s", command, err, out)
+	}
+	return nil
+}
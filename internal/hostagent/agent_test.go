@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package hostagent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrastructurev1beta2 "github.com/kairos-io/kairos-capi/api/infrastructure/v1beta2"
+)
+
+func newTestAgent(t *testing.T, objs ...client.Object) (*Agent, *[][]string) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(infrastructurev1beta2.AddToScheme(scheme)).To(Succeed())
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&infrastructurev1beta2.KairosHost{}).
+		Build()
+	var commands [][]string
+	return &Agent{
+		Client:       c,
+		Log:          logr.Discard(),
+		Name:         "box-1",
+		Namespace:    "edge",
+		Labels:       map[string]string{"site": "store-42"},
+		Address:      "192.0.2.21",
+		ConfigPath:   filepath.Join(t.TempDir(), "oem", "90_kairos_capi.yaml"),
+		ApplyCommand: DefaultApplyCommand,
+		ResetCommand: DefaultResetCommand,
+		run: func(_ context.Context, command []string) error {
+			commands = append(commands, command)
+			return nil
+		},
+	}, &commands
+}
+
+func TestSync_Registers(t *testing.T) {
+	g := NewWithT(t)
+	agent, commands := newTestAgent(t)
+
+	g.Expect(agent.Sync(context.Background())).To(Succeed())
+
+	host := &infrastructurev1beta2.KairosHost{}
+	g.Expect(agent.Client.Get(context.Background(), client.ObjectKey{Namespace: "edge", Name: "box-1"}, host)).To(Succeed())
+	g.Expect(host.Labels).To(HaveKeyWithValue("site", "store-42"))
+	g.Expect(host.Spec.Address).To(Equal("192.0.2.21"))
+	g.Expect(*commands).To(BeEmpty())
+
+	// Label and address changes are picked up on the next sync
+	agent.Labels = map[string]string{"site": "store-43"}
+	agent.Address = "192.0.2.22"
+	g.Expect(agent.Sync(context.Background())).To(Succeed())
+	g.Expect(agent.Client.Get(context.Background(), client.ObjectKey{Namespace: "edge", Name: "box-1"}, host)).To(Succeed())
+	g.Expect(host.Labels).To(HaveKeyWithValue("site", "store-43"))
+	g.Expect(host.Spec.Address).To(Equal("192.0.2.22"))
+}
+
+func TestSync_AppliesBootstrapData(t *testing.T) {
+	g := NewWithT(t)
+	host := &infrastructurev1beta2.KairosHost{
+		ObjectMeta: metav1.ObjectMeta{Name: "box-1", Namespace: "edge"},
+		Spec: infrastructurev1beta2.KairosHostSpec{
+			ConsumerRef: &corev1.ObjectReference{Kind: "KairosMachine", Namespace: "edge", Name: "worker-0"},
+		},
+		Status: infrastructurev1beta2.KairosHostStatus{BootstrapDataSecretName: "worker-0"},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Namespace: "edge"},
+		Data:       map[string][]byte{"value": []byte("#cloud-config\n")},
+	}
+	agent, commands := newTestAgent(t, host, secret)
+
+	g.Expect(agent.Sync(context.Background())).To(Succeed())
+
+	data, err := os.ReadFile(agent.ConfigPath)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(data)).To(Equal("#cloud-config\n"))
+	g.Expect(*commands).To(Equal([][]string{DefaultApplyCommand}))
+
+	got := &infrastructurev1beta2.KairosHost{}
+	g.Expect(agent.Client.Get(context.Background(), client.ObjectKeyFromObject(host), got)).To(Succeed())
+	g.Expect(conditions.IsTrue(got, infrastructurev1beta2.BootstrapDataAppliedCondition)).To(BeTrue())
+
+	// Applied bootstrap data is not applied again
+	g.Expect(agent.Sync(context.Background())).To(Succeed())
+	g.Expect(*commands).To(HaveLen(1))
+}
+
+func TestSync_ResetsReleasedHost(t *testing.T) {
+	g := NewWithT(t)
+	host := &infrastructurev1beta2.KairosHost{
+		ObjectMeta: metav1.ObjectMeta{Name: "box-1", Namespace: "edge"},
+	}
+	conditions.MarkTrue(host, infrastructurev1beta2.BootstrapDataAppliedCondition)
+	agent, commands := newTestAgent(t, host)
+	g.Expect(os.MkdirAll(filepath.Dir(agent.ConfigPath), 0o700)).To(Succeed())
+	g.Expect(os.WriteFile(agent.ConfigPath, []byte("#cloud-config\n"), 0o600)).To(Succeed())
+
+	g.Expect(agent.Sync(context.Background())).To(Succeed())
+
+	g.Expect(agent.ConfigPath).NotTo(BeAnExistingFile())
+	g.Expect(*commands).To(Equal([][]string{DefaultResetCommand}))
+	got := &infrastructurev1beta2.KairosHost{}
+	g.Expect(agent.Client.Get(context.Background(), client.ObjectKeyFromObject(host), got)).To(Succeed())
+	g.Expect(conditions.Has(got, infrastructurev1beta2.BootstrapDataAppliedCondition)).To(BeFalse())
+}
+
+func TestSync_WaitsForBootstrapData(t *testing.T) {
+	g := NewWithT(t)
+	host := &infrastructurev1beta2.KairosHost{
+		ObjectMeta: metav1.ObjectMeta{Name: "box-1", Namespace: "edge"},
+		Spec: infrastructurev1beta2.KairosHostSpec{
+			ConsumerRef: &corev1.ObjectReference{Kind: "KairosMachine", Namespace: "edge", Name: "worker-0"},
+		},
+	}
+	agent, commands := newTestAgent(t, host)
+
+	g.Expect(agent.Sync(context.Background())).To(Succeed())
+	g.Expect(agent.ConfigPath).NotTo(BeAnExistingFile())
+	g.Expect(*commands).To(BeEmpty())
+}
@@ -59,6 +59,8 @@ func CloneInfrastructureMachine(ctx context.Context, c client.Client, scheme *ru
 	case "KubevirtMachineTemplate", "KubeVirtMachineTemplate":
 		logger.Info("Cloning KubevirtMachineTemplate", "machineName", machineName)
 		return cloneKubevirtMachineTemplate(ctx, c, scheme, templateObj, machineName, namespace, labels, annotations)
+	case "Metal3MachineTemplate":
+		return cloneMetal3MachineTemplate(ctx, c, scheme, templateObj, machineName, namespace, labels, annotations)
 	default:
 		return nil, fmt.Errorf("unsupported infrastructure provider: %s (Group: %s, Version: %s, FullGVK: %s)",
 			kind,
@@ -222,3 +224,49 @@ func cloneKubevirtMachineTemplate(ctx context.Context, c client.Client, scheme *
 
 	return kubevirtMachine, nil
 }
+
+func cloneMetal3MachineTemplate(ctx context.Context, c client.Client, scheme *runtime.Scheme, template *unstructured.Unstructured, machineName, namespace string, labels, annotations map[string]string) (client.Object, error) {
+	// For CAPM3, we create a Metal3Machine from Metal3MachineTemplate.
+	// BMO picks a BareMetalHost matching spec.hostSelector and provisions
+	// spec.image (or spec.customDeploy) on it.
+
+	metal3Machine := &unstructured.Unstructured{}
+	version := template.GroupVersionKind().Version
+	if version == "" {
+		version = "v1beta1"
+	}
+	metal3Machine.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Version: version,
+		Kind:    "Metal3Machine",
+	})
+
+	metal3Machine.SetName(machineName)
+	metal3Machine.SetNamespace(namespace)
+	metal3Machine.SetLabels(labels)
+	metal3Machine.SetAnnotations(annotations)
+
+	// Copy spec from template, including hostSelector, image, dataTemplate and
+	// automatedCleaningMode
+	spec, ok, _ := unstructured.NestedMap(template.UnstructuredContent(), "spec", "template", "spec")
+	if !ok {
+		spec = map[string]interface{}{}
+	}
+
+	// Without an image nothing would be written to the host's disk
+	_, hasImage := spec["image"]
+	_, hasCustomDeploy := spec["customDeploy"]
+	if !hasImage && !hasCustomDeploy {
+		return nil, fmt.Errorf("Metal3MachineTemplate %s/%s must set spec.template.spec.image or spec.template.spec.customDeploy",
+			template.GetNamespace(), template.GetName())
+	}
+
+	// providerID is set by CAPM3 once a host is associated
+	delete(spec, "providerID")
+
+	if err := unstructured.SetNestedMap(metal3Machine.UnstructuredContent(), spec, "spec"); err != nil {
+		return nil, fmt.Errorf("failed to set spec: %w", err)
+	}
+
+	return metal3Machine, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package infrastructure
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCloneInfrastructureMachine_Metal3(t *testing.T) {
+	g := NewWithT(t)
+
+	template := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "infrastructure.cluster.x-k8s.io/v1beta1",
+		"kind":       "Metal3MachineTemplate",
+		"metadata": map[string]interface{}{
+			"name":      "edge-control-plane",
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{
+			"nodeReuse": false,
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"hostSelector": map[string]interface{}{
+						"matchLabels": map[string]interface{}{"site": "edge-1"},
+					},
+					"image": map[string]interface{}{
+						"url":      "http://images.local/kairos.raw",
+						"checksum": "http://images.local/kairos.raw.sha256",
+						"format":   "raw",
+					},
+					"dataTemplate": map[string]interface{}{"name": "edge-metadata"},
+				},
+			},
+		},
+	}}
+
+	scheme := runtime.NewScheme()
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(template).Build()
+	ref := corev1.ObjectReference{
+		APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+		Kind:       "Metal3MachineTemplate",
+		Name:       "edge-control-plane",
+		Namespace:  "default",
+	}
+
+	obj, err := CloneInfrastructureMachine(context.Background(), c, scheme, ref, "kcp-0", "default",
+		map[string]string{"cluster.x-k8s.io/cluster-name": "edge"}, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	machine := obj.(*unstructured.Unstructured)
+	g.Expect(machine.GetKind()).To(Equal("Metal3Machine"))
+	g.Expect(machine.GetAPIVersion()).To(Equal("infrastructure.cluster.x-k8s.io/v1beta1"))
+	g.Expect(machine.GetName()).To(Equal("kcp-0"))
+	g.Expect(machine.GetLabels()).To(HaveKeyWithValue("cluster.x-k8s.io/cluster-name", "edge"))
+
+	site, _, _ := unstructured.NestedString(machine.Object, "spec", "hostSelector", "matchLabels", "site")
+	g.Expect(site).To(Equal("edge-1"))
+	url, _, _ := unstructured.NestedString(machine.Object, "spec", "image", "url")
+	g.Expect(url).To(Equal("http://images.local/kairos.raw"))
+	dataTemplate, _, _ := unstructured.NestedString(machine.Object, "spec", "dataTemplate", "name")
+	g.Expect(dataTemplate).To(Equal("edge-metadata"))
+	_, found, _ := unstructured.NestedFieldNoCopy(machine.Object, "spec", "nodeReuse")
+	g.Expect(found).To(BeFalse())
+
+	// A template without an image cannot provision a host
+	unstructured.RemoveNestedField(template.Object, "spec", "template", "spec", "image")
+	g.Expect(c.Update(context.Background(), template)).To(Succeed())
+	_, err = CloneInfrastructureMachine(context.Background(), c, scheme, ref, "kcp-1", "default", nil, nil)
+	g.Expect(err).To(MatchError(ContainSubstring("spec.template.spec.image")))
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package k0stoken creates k0s worker join tokens through the Kubernetes API
+// of a workload cluster, the same way `k0s token create --role=worker` does:
+// a bootstrap token Secret in kube-system plus a gzipped, base64-encoded
+// kubeconfig that carries the token.
+package k0stoken
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// Namespace holds bootstrap token Secrets in the workload cluster.
+	Namespace = "kube-system"
+
+	// bootstrapTokenSecretType is the Secret type the bootstrap token authenticator reads.
+	bootstrapTokenSecretType corev1.SecretType = "bootstrap.kubernetes.io/token"
+
+	tokenChars = "abcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+// BootstrapToken is a Kubernetes bootstrap token, "<id>.<secret>".
+type BootstrapToken struct {
+	ID     string
+	Secret string
+}
+
+// String returns the token in its "<id>.<secret>" form.
+func (t BootstrapToken) String() string {
+	return t.ID + "." + t.Secret
+}
+
+// NewBootstrapToken generates a random bootstrap token.
+func NewBootstrapToken() (BootstrapToken, error) {
+	id, err := randomString(6)
+	if err != nil {
+		return BootstrapToken{}, err
+	}
+	secret, err := randomString(16)
+	if err != nil {
+		return BootstrapToken{}, err
+	}
+	return BootstrapToken{ID: id, Secret: secret}, nil
+}
+
+// WorkerSecret returns the kube-system Secret that lets token authenticate as
+// a kubelet bootstrapping into the workload cluster until expiry.
+func WorkerSecret(token BootstrapToken, expiry time.Time) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bootstrap-token-" + token.ID,
+			Namespace: Namespace,
+		},
+		Type: bootstrapTokenSecretType,
+		StringData: map[string]string{
+			"token-id":                       token.ID,
+			"token-secret":                   token.Secret,
+			"expiration":                     expiry.UTC().Format(time.RFC3339),
+			"description":                    "Worker bootstrap token generated by kairos-capi",
+			"usage-bootstrap-authentication": "true",
+			"usage-bootstrap-signing":        "true",
+		},
+	}
+}
+
+// JoinToken encodes token as a k0s join token for the API server at server,
+// trusting caData.
+func JoinToken(server string, caData []byte, token BootstrapToken) (string, error) {
+	config := clientcmdapi.NewConfig()
+	config.Clusters["k0s"] = &clientcmdapi.Cluster{
+		Server:                   server,
+		CertificateAuthorityData: caData,
+	}
+	config.AuthInfos["kubelet-bootstrap"] = &clientcmdapi.AuthInfo{Token: token.String()}
+	config.Contexts["k0s"] = &clientcmdapi.Context{Cluster: "k0s", AuthInfo: "kubelet-bootstrap"}
+	config.CurrentContext = "k0s"
+
+	kubeconfig, err := clientcmd.Write(*config)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize join kubeconfig: %w", err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(kubeconfig); err != nil {
+		return "", fmt.Errorf("failed to compress join kubeconfig: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress join kubeconfig: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// ParseJoinToken returns the bootstrap token carried by a k0s join token.
+func ParseJoinToken(joinToken string) (BootstrapToken, error) {
+	compressed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(joinToken))
+	if err != nil {
+		return BootstrapToken{}, fmt.Errorf("failed to decode join token: %w", err)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return BootstrapToken{}, fmt.Errorf("failed to decompress join token: %w", err)
+	}
+	kubeconfig, err := io.ReadAll(gz)
+	if err != nil {
+		return BootstrapToken{}, fmt.Errorf("failed to decompress join token: %w", err)
+	}
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return BootstrapToken{}, fmt.Errorf("failed to parse join kubeconfig: %w", err)
+	}
+	kubeContext, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return BootstrapToken{}, fmt.Errorf("join kubeconfig has no current context")
+	}
+	authInfo, ok := config.AuthInfos[kubeContext.AuthInfo]
+	if !ok {
+		return BootstrapToken{}, fmt.Errorf("join kubeconfig has no user %q", kubeContext.AuthInfo)
+	}
+	id, secret, ok := strings.Cut(authInfo.Token, ".")
+	if !ok || id == "" || secret == "" {
+		return BootstrapToken{}, fmt.Errorf("join kubeconfig does not carry a bootstrap token")
+	}
+	return BootstrapToken{ID: id, Secret: secret}, nil
+}
+
+func randomString(length int) (string, error) {
+	b := make([]byte, length)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(tokenChars))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate bootstrap token: %w", err)
+		}
+		b[i] = tokenChars[n.Int64()]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package k0stoken
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"regexp"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestNewBootstrapToken(t *testing.T) {
+	g := NewWithT(t)
+
+	token, err := NewBootstrapToken()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(token.String()).To(MatchRegexp(`^[a-z0-9]{6}\.[a-z0-9]{16}$`))
+
+	other, err := NewBootstrapToken()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(other).NotTo(Equal(token))
+}
+
+func TestWorkerSecret(t *testing.T) {
+	g := NewWithT(t)
+
+	token := BootstrapToken{ID: "abcdef", Secret: "0123456789abcdef"}
+	expiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	secret := WorkerSecret(token, expiry)
+
+	g.Expect(secret.Name).To(Equal("bootstrap-token-abcdef"))
+	g.Expect(secret.Namespace).To(Equal("kube-system"))
+	g.Expect(string(secret.Type)).To(Equal("bootstrap.kubernetes.io/token"))
+	g.Expect(secret.StringData).To(HaveKeyWithValue("token-secret", "0123456789abcdef"))
+	g.Expect(secret.StringData).To(HaveKeyWithValue("expiration", "2030-01-02T03:04:05Z"))
+	g.Expect(secret.StringData).To(HaveKeyWithValue("usage-bootstrap-authentication", "true"))
+}
+
+func TestJoinToken(t *testing.T) {
+	g := NewWithT(t)
+
+	token := BootstrapToken{ID: "abcdef", Secret: "0123456789abcdef"}
+	joinToken, err := JoinToken("https://10.0.0.10:6443", []byte("test-ca"), token)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(regexp.MustCompile(`\s`).MatchString(joinToken)).To(BeFalse())
+
+	compressed, err := base64.StdEncoding.DecodeString(joinToken)
+	g.Expect(err).NotTo(HaveOccurred())
+	gz, err := gzip.NewReader(bytes.NewReader(compressed))
+	g.Expect(err).NotTo(HaveOccurred())
+	kubeconfig, err := io.ReadAll(gz)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	config, err := clientcmd.Load(kubeconfig)
+	g.Expect(err).NotTo(HaveOccurred())
+	cluster := config.Clusters[config.Contexts[config.CurrentContext].Cluster]
+	g.Expect(cluster.Server).To(Equal("https://10.0.0.10:6443"))
+	g.Expect(cluster.CertificateAuthorityData).To(Equal([]byte("test-ca")))
+	g.Expect(config.AuthInfos[config.Contexts[config.CurrentContext].AuthInfo].Token).To(Equal("abcdef.0123456789abcdef"))
+}
+
+func TestParseJoinToken(t *testing.T) {
+	g := NewWithT(t)
+
+	token := BootstrapToken{ID: "abcdef", Secret: "0123456789abcdef"}
+	joinToken, err := JoinToken("https://10.0.0.10:6443", []byte("test-ca"), token)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	parsed, err := ParseJoinToken(joinToken)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(parsed).To(Equal(token))
+
+	_, err = ParseJoinToken("not-a-join-token")
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package kubeconfigsecret describes the kubeconfig Secret that Cluster API
+// expects a control plane provider to publish for each cluster, and the client
+// certificate checks used to refresh it before it expires.
+package kubeconfigsecret
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// DataKey is the Secret data key holding the kubeconfig.
+const DataKey = "value"
+
+// Name returns the name of the kubeconfig Secret for a cluster.
+func Name(clusterName string) string {
+	return fmt.Sprintf("%s-kubeconfig", clusterName)
+}
+
+// ClientCertificateExpiry returns the earliest expiry of the embedded client
+// certificates in a kubeconfig. It returns the zero time when the kubeconfig
+// authenticates without client certificates.
+func ClientCertificateExpiry(data []byte) (time.Time, error) {
+	config, err := clientcmd.Load(data)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	var expiry time.Time
+	for name, authInfo := range config.AuthInfos {
+		if authInfo == nil || len(authInfo.ClientCertificateData) == 0 {
+			continue
+		}
+		block, _ := pem.Decode(authInfo.ClientCertificateData)
+		if block == nil {
+			return time.Time{}, fmt.Errorf("user %q has no PEM client certificate", name)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse client certificate of user %q: %w", name, err)
+		}
+		if expiry.IsZero() || cert.NotAfter.Before(expiry) {
+			expiry = cert.NotAfter
+		}
+	}
+	return expiry, nil
+}
+
+// RenewalTime returns when a kubeconfig should be refreshed, renewBefore
+// ahead of its client certificate expiry. It returns the zero time when there
+// is no client certificate to renew.
+func RenewalTime(data []byte, renewBefore time.Duration) (time.Time, error) {
+	expiry, err := ClientCertificateExpiry(data)
+	if err != nil || expiry.IsZero() {
+		return time.Time{}, err
+	}
+	return expiry.Add(-renewBefore), nil
+}
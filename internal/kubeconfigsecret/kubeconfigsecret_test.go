@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package kubeconfigsecret
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func testKubeconfig(t *testing.T, notAfter ...time.Time) []byte {
+	t.Helper()
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters["test"] = &clientcmdapi.Cluster{Server: "https://10.0.0.10:6443"}
+	config.AuthInfos["token"] = &clientcmdapi.AuthInfo{Token: "test-token"}
+	for i, expiry := range notAfter {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(int64(i + 1)),
+			Subject:      pkix.Name{CommonName: "admin"},
+			NotBefore:    expiry.Add(-365 * 24 * time.Hour),
+			NotAfter:     expiry,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config.AuthInfos[fmt.Sprintf("admin-%d", i)] = &clientcmdapi.AuthInfo{
+			ClientCertificateData: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		}
+	}
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestName(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(Name("test-cluster")).To(Equal("test-cluster-kubeconfig"))
+}
+
+func TestClientCertificateExpiry(t *testing.T) {
+	g := NewWithT(t)
+
+	sooner := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	later := sooner.Add(30 * 24 * time.Hour)
+
+	expiry, err := ClientCertificateExpiry(testKubeconfig(t, later, sooner))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(expiry).To(BeTemporally("==", sooner))
+
+	// Token-only kubeconfigs have nothing to renew
+	expiry, err = ClientCertificateExpiry(testKubeconfig(t))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(expiry.IsZero()).To(BeTrue())
+
+	_, err = ClientCertificateExpiry([]byte("not a kubeconfig"))
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestRenewalTime(t *testing.T) {
+	g := NewWithT(t)
+
+	expiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	renewAt, err := RenewalTime(testKubeconfig(t, expiry), 24*time.Hour)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(renewAt).To(BeTemporally("==", expiry.Add(-24*time.Hour)))
+
+	renewAt, err = RenewalTime(testKubeconfig(t), 24*time.Hour)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(renewAt.IsZero()).To(BeTrue())
+}
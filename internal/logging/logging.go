@@ -0,0 +1,302 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package logging builds the manager's logger: a zap logger with a
+// selectable encoding, a verbosity that can be raised per controller, and
+// redaction of credentials passed as log values.
+package logging
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	uberzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/kairos-io/kairos-capi/internal/redact"
+)
+
+// Log formats accepted by --log-format
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// Redacted replaces the values of log keys that hold credentials.
+const Redacted = redact.Placeholder
+
+// sensitiveKeys are the substrings of lower-cased log keys whose values are
+// redacted.
+var sensitiveKeys = []string{"token", "password", "passwd", "privatekey", "kubeconfig", "userdata", "bootstrapdata"}
+
+// Options configure the manager's logger.
+type Options struct {
+	// Format is the log encoding, json or console. When empty, the zap
+	// options decide.
+	Format string
+	// Verbosity is the highest logr V-level logged.
+	Verbosity int
+	// ControllerVerbosity overrides Verbosity for the reconcile loggers of
+	// the named controllers, e.g. kairosconfig or kairoscontrolplane.
+	ControllerVerbosity map[string]int
+
+	// flags is the flag set of AddFlags. With it, the verbosities only
+	// replace the zap level (--zap-log-level) when their flags are set.
+	flags *flag.FlagSet
+	// verbositySet is set by SetVerbosity
+	verbositySet bool
+}
+
+// AddFlags registers the logging flags on fs.
+func (o *Options) AddFlags(fs *flag.FlagSet) {
+	o.flags = fs
+	fs.StringVar(&o.Format, "log-format", o.Format,
+		"Log encoding, json or console. Takes precedence over --zap-encoder.")
+	fs.IntVar(&o.Verbosity, "log-verbosity", o.Verbosity,
+		"Highest log verbosity (V-level) logged, e.g. 4 for debug logs. Takes precedence over --zap-log-level.")
+	fs.Var(verbosityMap{m: &o.ControllerVerbosity}, "controller-log-verbosity",
+		"Log verbosity of individual controllers as a comma separated list of name=level, "+
+			"e.g. kairoscontrolplane=4,kairosconfig=2. Controllers not listed use --log-verbosity.")
+}
+
+// SetVerbosity sets Verbosity, which then replaces the zap level even when
+// --log-verbosity is not set.
+func (o *Options) SetVerbosity(v int) {
+	o.Verbosity = v
+	o.verbositySet = true
+}
+
+// setsLevel reports whether the verbosities replace the level of the zap
+// options: always when set programmatically, otherwise only when set with
+// their flags, so --zap-log-level works on its own.
+func (o Options) setsLevel() bool {
+	if o.flags == nil || o.verbositySet {
+		return true
+	}
+	set := false
+	o.flags.Visit(func(f *flag.Flag) {
+		if f.Name == "log-verbosity" || f.Name == "controller-log-verbosity" {
+			set = true
+		}
+	})
+	return set
+}
+
+// New returns a logger configured by o on top of zapOpts.
+func New(o Options, zapOpts *zap.Options) (logr.Logger, error) {
+	switch o.Format {
+	case "":
+	case FormatJSON:
+		zapOpts.NewEncoder = func(opts ...zap.EncoderConfigOption) zapcore.Encoder {
+			cfg := uberzap.NewProductionEncoderConfig()
+			for _, opt := range opts {
+				opt(&cfg)
+			}
+			return zapcore.NewJSONEncoder(cfg)
+		}
+	case FormatConsole:
+		zapOpts.NewEncoder = func(opts ...zap.EncoderConfigOption) zapcore.Encoder {
+			cfg := uberzap.NewDevelopmentEncoderConfig()
+			for _, opt := range opts {
+				opt(&cfg)
+			}
+			return zapcore.NewConsoleEncoder(cfg)
+		}
+	default:
+		return logr.Logger{}, fmt.Errorf("unsupported log format %q: must be %s or %s", o.Format, FormatJSON, FormatConsole)
+	}
+	if o.Verbosity < 0 {
+		return logr.Logger{}, fmt.Errorf("log verbosity must not be negative")
+	}
+
+	if !o.setsLevel() {
+		base := zap.New(zap.UseFlagOptions(zapOpts))
+		return logr.New(&sink{sink: base.GetSink(), verbosity: math.MaxInt}), nil
+	}
+
+	// zap filters on the highest verbosity of any controller; the sink
+	// lowers it again for everything else.
+	maxVerbosity := o.Verbosity
+	for _, v := range o.ControllerVerbosity {
+		maxVerbosity = max(maxVerbosity, v)
+	}
+	level := uberzap.NewAtomicLevelAt(zapcore.Level(-maxVerbosity))
+	zapOpts.Level = &level
+
+	base := zap.New(zap.UseFlagOptions(zapOpts))
+	return logr.New(&sink{
+		sink:        base.GetSink(),
+		verbosity:   o.Verbosity,
+		controllers: o.ControllerVerbosity,
+	}), nil
+}
+
+// sink limits the verbosity of the wrapped sink, per controller, and redacts
+// credentials from the logged key/value pairs.
+type sink struct {
+	sink        logr.LogSink
+	verbosity   int
+	controllers map[string]int
+}
+
+var _ logr.CallDepthLogSink = &sink{}
+
+func (s *sink) Init(info logr.RuntimeInfo) {
+	// Account for the frame of this sink when reporting the caller
+	info.CallDepth++
+	s.sink.Init(info)
+}
+
+func (s *sink) Enabled(level int) bool {
+	return level <= s.verbosity && s.sink.Enabled(level)
+}
+
+func (s *sink) Info(level int, msg string, keysAndValues ...any) {
+	s.sink.Info(level, redact.String(msg), Redact(keysAndValues)...)
+}
+
+func (s *sink) Error(err error, msg string, keysAndValues ...any) {
+	if err != nil {
+		err = redactedError{err}
+	}
+	s.sink.Error(err, redact.String(msg), Redact(keysAndValues)...)
+}
+
+// redactedError hides join tokens in the message of a logged error.
+type redactedError struct {
+	error
+}
+
+func (e redactedError) Error() string {
+	return redact.String(e.error.Error())
+}
+
+func (s *sink) WithValues(keysAndValues ...any) logr.LogSink {
+	out := *s
+	out.sink = s.sink.WithValues(Redact(keysAndValues)...)
+	// controller-runtime adds the controller name to the logger of every
+	// reconcile
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		if key, ok := keysAndValues[i].(string); ok && key == "controller" {
+			if v, ok := s.controllers[fmt.Sprint(keysAndValues[i+1])]; ok {
+				out.verbosity = v
+			}
+		}
+	}
+	return &out
+}
+
+func (s *sink) WithName(name string) logr.LogSink {
+	out := *s
+	out.sink = s.sink.WithName(name)
+	return &out
+}
+
+func (s *sink) WithCallDepth(depth int) logr.LogSink {
+	out := *s
+	if withDepth, ok := s.sink.(logr.CallDepthLogSink); ok {
+		out.sink = withDepth.WithCallDepth(depth)
+	}
+	return &out
+}
+
+// Redact returns keysAndValues with the values of keys that name credentials,
+// such as tokens, passwords and kubeconfigs, replaced by Redacted, and join
+// tokens removed from string and error values. The input is not modified.
+func Redact(keysAndValues []any) []any {
+	var out []any
+	set := func(i int, value any) {
+		if out == nil {
+			out = append([]any(nil), keysAndValues...)
+		}
+		out[i] = value
+	}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		if key, ok := keysAndValues[i].(string); ok && isSensitive(key) {
+			set(i+1, Redacted)
+			continue
+		}
+		switch value := keysAndValues[i+1].(type) {
+		case string:
+			if redacted := redact.String(value); redacted != value {
+				set(i+1, redacted)
+			}
+		case error:
+			set(i+1, redactedError{value})
+		}
+	}
+	if out == nil {
+		return keysAndValues
+	}
+	return out
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// verbosityMap is a flag.Value for a comma separated list of name=level.
+type verbosityMap struct {
+	m *map[string]int
+}
+
+func (v verbosityMap) String() string {
+	if v.m == nil || len(*v.m) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(*v.m))
+	for name := range *v.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+"="+strconv.Itoa((*v.m)[name]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (v verbosityMap) Set(value string) error {
+	m := map[string]int{}
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, level, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("invalid controller log verbosity %q: must be name=level", pair)
+		}
+		n, err := strconv.Atoi(level)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid log verbosity %q for controller %s: must be a non-negative integer", level, name)
+		}
+		m[strings.ToLower(name)] = n
+	}
+	*v.m = m
+	return nil
+}
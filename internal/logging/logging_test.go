@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+func logLines(g Gomega, buf *bytes.Buffer) []map[string]any {
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := map[string]any{}
+		g.Expect(json.Unmarshal([]byte(line), &entry)).To(Succeed())
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func TestNew(t *testing.T) {
+	g := NewWithT(t)
+
+	buf := &bytes.Buffer{}
+	logger, err := New(Options{
+		Format:              FormatJSON,
+		Verbosity:           1,
+		ControllerVerbosity: map[string]int{"kairoscontrolplane": 4},
+	}, &zap.Options{DestWriter: buf})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	logger.Info("provisioned", "secret", "c1-token", "joinToken", "abc", "password", "hunter2")
+	logger.V(1).Info("verbose")
+	logger.V(4).Info("debug")
+	logger.WithValues("controller", "kairoscontrolplane").V(4).Info("controller debug")
+	logger.WithValues("controller", "kairosconfig").V(4).Info("other controller debug")
+	logger.WithValues("kubeconfig", "apiVersion: v1").Info("with values")
+	logger.Error(errors.New("token abcdef.0123456789abcdef rejected"), "join failed")
+
+	lines := logLines(g, buf)
+	g.Expect(lines).To(HaveLen(5))
+	g.Expect(lines[0]).To(HaveKeyWithValue("msg", "provisioned"))
+	g.Expect(lines[0]).To(HaveKeyWithValue("secret", "c1-token"))
+	g.Expect(lines[0]).To(HaveKeyWithValue("joinToken", Redacted))
+	g.Expect(lines[0]).To(HaveKeyWithValue("password", Redacted))
+	g.Expect(lines[1]).To(HaveKeyWithValue("msg", "verbose"))
+	g.Expect(lines[2]).To(HaveKeyWithValue("msg", "controller debug"))
+	g.Expect(lines[3]).To(HaveKeyWithValue("kubeconfig", Redacted))
+	g.Expect(lines[4]).To(HaveKeyWithValue("error", "token <redacted> rejected"))
+
+	_, err = New(Options{Format: "xml"}, &zap.Options{DestWriter: buf})
+	g.Expect(err).To(MatchError(ContainSubstring("unsupported log format")))
+}
+
+func TestRedact(t *testing.T) {
+	g := NewWithT(t)
+
+	in := []any{"machine", "m1", "userData", []byte("#cloud-config"), "K3sToken", "t", "odd"}
+	out := Redact(in)
+	g.Expect(out).To(Equal([]any{"machine", "m1", "userData", Redacted, "K3sToken", Redacted, "odd"}))
+	g.Expect(in[3]).To(Equal([]byte("#cloud-config")))
+
+	clean := []any{"machine", "m1"}
+	g.Expect(Redact(clean)).To(Equal(clean))
+
+	// Join tokens are removed from values of any key
+	out = Redact([]any{"error", errors.New("join with abcdef.0123456789abcdef failed"), "output", "K10ff::server:s3cret"})
+	g.Expect(out[1].(error).Error()).To(Equal("join with <redacted> failed"))
+	g.Expect(out[3]).To(Equal(Redacted))
+}
+
+func TestControllerVerbosityFlag(t *testing.T) {
+	g := NewWithT(t)
+
+	o := Options{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	o.AddFlags(fs)
+	g.Expect(fs.Parse([]string{"--controller-log-verbosity=KairosConfig=2, kairoscontrolplane=4", "--log-format=json"})).To(Succeed())
+	g.Expect(o.ControllerVerbosity).To(Equal(map[string]int{"kairosconfig": 2, "kairoscontrolplane": 4}))
+	g.Expect(o.Format).To(Equal(FormatJSON))
+	g.Expect(fs.Lookup("controller-log-verbosity").Value.String()).To(Equal("kairosconfig=2,kairoscontrolplane=4"))
+
+	g.Expect(fs.Parse([]string{"--controller-log-verbosity=kairosconfig"})).NotTo(Succeed())
+	g.Expect(fs.Parse([]string{"--controller-log-verbosity=kairosconfig=-1"})).NotTo(Succeed())
+}
+
+func TestZapLogLevelWithoutVerbosityFlags(t *testing.T) {
+	g := NewWithT(t)
+
+	parse := func(args ...string) *bytes.Buffer {
+		buf := &bytes.Buffer{}
+		zapOpts := zap.Options{DestWriter: buf}
+		o := Options{Format: FormatJSON, Verbosity: 1}
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		zapOpts.BindFlags(fs)
+		o.AddFlags(fs)
+		g.Expect(fs.Parse(args)).To(Succeed())
+
+		logger, err := New(o, &zapOpts)
+		g.Expect(err).NotTo(HaveOccurred())
+		logger.Info("info")
+		logger.V(1).Info("verbose")
+		logger.V(2).Info("debug")
+		logger.Error(errors.New("boom"), "failed")
+		return buf
+	}
+	messages := func(buf *bytes.Buffer) []string {
+		var msgs []string
+		for _, line := range logLines(g, buf) {
+			msgs = append(msgs, line["msg"].(string))
+		}
+		return msgs
+	}
+
+	// --zap-log-level alone is not overridden by the default verbosity
+	g.Expect(messages(parse("--zap-log-level=error"))).To(Equal([]string{"failed"}))
+	g.Expect(messages(parse("--zap-log-level=2"))).To(Equal([]string{"info", "verbose", "debug", "failed"}))
+
+	// --log-verbosity takes precedence
+	g.Expect(messages(parse("--zap-log-level=error", "--log-verbosity=1"))).To(Equal([]string{"info", "verbose", "failed"}))
+	g.Expect(messages(parse("--zap-log-level=error", "--controller-log-verbosity=kairosconfig=2"))).To(Equal([]string{"info", "verbose", "failed"}))
+}
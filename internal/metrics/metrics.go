@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package metrics defines the Prometheus metrics of the Kairos CAPI
+// controllers. They are served on the manager metrics endpoint next to the
+// controller-runtime metrics once Register is called.
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+	controlplanev1beta2 "github.com/kairos-io/kairos-capi/api/controlplane/v1beta2"
+)
+
+const namespace = "kairos_capi"
+
+// Phases a KairosConfig is counted in by kairos_capi_kairosconfigs.
+const (
+	phasePending = "Pending"
+	phaseReady   = "Ready"
+	phaseFailed  = "Failed"
+)
+
+var (
+	// BootstrapDataGenerationDuration observes how long rendering the
+	// cloud-config of a KairosConfig took, by distribution.
+	BootstrapDataGenerationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "bootstrap_data_generation_duration_seconds",
+		Help:      "Time spent generating the bootstrap data of a KairosConfig.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+	}, []string{"distribution"})
+
+	// TokenFetchFailures counts join tokens that could not be read or
+	// provisioned while generating bootstrap data, by distribution.
+	TokenFetchFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_fetch_failures_total",
+		Help:      "Number of failures to read or provision a join token for bootstrap data.",
+	}, []string{"distribution"})
+
+	// ReconcileErrors counts reconciles that failed with a reason reported on
+	// the object status, by controller and reason.
+	ReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconcile_errors_total",
+		Help:      "Number of reconcile failures by controller and failure reason.",
+	}, []string{"controller", "reason"})
+)
+
+// Register adds the Kairos CAPI metrics to registry. Counts of objects are
+// read from c whenever the metrics are scraped, so c should be cached.
+func Register(registry prometheus.Registerer, c client.Reader) {
+	registry.MustRegister(
+		BootstrapDataGenerationDuration,
+		TokenFetchFailures,
+		ReconcileErrors,
+		NewStateCollector(c),
+	)
+}
+
+// RecordReconcileError counts a failed reconcile of controller.
+func RecordReconcileError(controller, reason string) {
+	ReconcileErrors.WithLabelValues(controller, reason).Inc()
+}
+
+var (
+	kairosConfigsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "kairosconfigs"),
+		"Number of KairosConfigs by phase.",
+		[]string{"phase"}, nil,
+	)
+	replicaDriftDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "controlplane_replica_drift"),
+		"Desired replicas of a KairosControlPlane minus its ready replicas.",
+		[]string{"namespace", "name", "cluster"}, nil,
+	)
+)
+
+// stateCollector reports metrics derived from the current KairosConfigs and
+// KairosControlPlanes, so deleted objects never leave stale series behind.
+type stateCollector struct {
+	client client.Reader
+}
+
+// NewStateCollector returns a collector that lists KairosConfigs and
+// KairosControlPlanes through c on every scrape.
+func NewStateCollector(c client.Reader) prometheus.Collector {
+	return &stateCollector{client: c}
+}
+
+func (s *stateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- kairosConfigsDesc
+	ch <- replicaDriftDesc
+}
+
+func (s *stateCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Reads fail until the cache is synced; report nothing rather than zeros
+	configs := &bootstrapv1beta2.KairosConfigList{}
+	if err := s.client.List(ctx, configs); err == nil {
+		phases := map[string]int{phasePending: 0, phaseReady: 0, phaseFailed: 0}
+		for i := range configs.Items {
+			phases[configPhase(&configs.Items[i])]++
+		}
+		for phase, count := range phases {
+			ch <- prometheus.MustNewConstMetric(kairosConfigsDesc, prometheus.GaugeValue, float64(count), phase)
+		}
+	}
+
+	kcps := &controlplanev1beta2.KairosControlPlaneList{}
+	if err := s.client.List(ctx, kcps); err == nil {
+		for i := range kcps.Items {
+			kcp := &kcps.Items[i]
+			desired := int32(1)
+			if kcp.Spec.Replicas != nil {
+				desired = *kcp.Spec.Replicas
+			}
+			ch <- prometheus.MustNewConstMetric(replicaDriftDesc, prometheus.GaugeValue, float64(desired-kcp.Status.ReadyReplicas),
+				kcp.Namespace, kcp.Name, kcp.Labels[clusterv1.ClusterNameLabel])
+		}
+	}
+}
+
+// configPhase returns the phase kairosConfig is counted in: Failed while a
+// failure reason is reported, Ready once its bootstrap data is available and
+// Pending otherwise.
+func configPhase(kairosConfig *bootstrapv1beta2.KairosConfig) string {
+	switch {
+	case kairosConfig.Status.FailureReason != "":
+		return phaseFailed
+	case kairosConfig.Status.Ready:
+		return phaseReady
+	default:
+		return phasePending
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+	controlplanev1beta2 "github.com/kairos-io/kairos-capi/api/controlplane/v1beta2"
+)
+
+func TestStateCollector(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+
+	replicas := int32(3)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&bootstrapv1beta2.KairosConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "ready", Namespace: "default"},
+			Status:     bootstrapv1beta2.KairosConfigStatus{Ready: true},
+		},
+		&bootstrapv1beta2.KairosConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "failed", Namespace: "default"},
+			Status:     bootstrapv1beta2.KairosConfigStatus{Ready: true, FailureReason: "BootstrapDataSecretGenerationFailed"},
+		},
+		&bootstrapv1beta2.KairosConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default"},
+		},
+		&controlplanev1beta2.KairosControlPlane{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-kcp",
+				Namespace: "default",
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+			},
+			Spec:   controlplanev1beta2.KairosControlPlaneSpec{Replicas: &replicas},
+			Status: controlplanev1beta2.KairosControlPlaneStatus{ReadyReplicas: 1},
+		},
+	).Build()
+
+	reg := prometheus.NewRegistry()
+	g.Expect(reg.Register(NewStateCollector(c))).To(Succeed())
+
+	expected := `
+# HELP kairos_capi_controlplane_replica_drift Desired replicas of a KairosControlPlane minus its ready replicas.
+# TYPE kairos_capi_controlplane_replica_drift gauge
+kairos_capi_controlplane_replica_drift{cluster="test-cluster",name="test-kcp",namespace="default"} 2
+# HELP kairos_capi_kairosconfigs Number of KairosConfigs by phase.
+# TYPE kairos_capi_kairosconfigs gauge
+kairos_capi_kairosconfigs{phase="Failed"} 1
+kairos_capi_kairosconfigs{phase="Pending"} 1
+kairos_capi_kairosconfigs{phase="Ready"} 1
+`
+	g.Expect(testutil.GatherAndCompare(reg, strings.NewReader(expected))).To(Succeed())
+}
+
+func TestRecordReconcileError(t *testing.T) {
+	g := NewWithT(t)
+
+	before := testutil.ToFloat64(ReconcileErrors.WithLabelValues("KairosConfig", "InvalidSpec"))
+	RecordReconcileError("KairosConfig", "InvalidSpec")
+	g.Expect(testutil.ToFloat64(ReconcileErrors.WithLabelValues("KairosConfig", "InvalidSpec"))).To(Equal(before + 1))
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package osupgrade drives in-place Kairos OS upgrades of workload cluster
+// nodes, either through the kairos operator or through system-upgrade-controller
+// Plans. The controllers create one NodeOpUpgrade or Plan per target image and
+// read its status to report progress.
+package osupgrade
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NodeOpUpgradeGVK is the kairos operator resource that upgrades node OS images.
+var NodeOpUpgradeGVK = schema.GroupVersionKind{
+	Group:   "operator.kairos.io",
+	Version: "v1alpha1",
+	Kind:    "NodeOpUpgrade",
+}
+
+const (
+	// Namespace holds the NodeOpUpgrade objects created in the workload cluster.
+	Namespace = "kube-system"
+
+	// ControlPlaneLabel marks NodeOpUpgrades with the name of the
+	// KairosControlPlane they were created for.
+	ControlPlaneLabel = "controlplane.cluster.x-k8s.io/kairos-control-plane"
+
+	// controlPlaneNodeLabel selects control plane nodes on both k0s and k3s.
+	controlPlaneNodeLabel = "node-role.kubernetes.io/control-plane"
+
+	fieldOwner = "kairos-capi"
+)
+
+// Phase is the progress of a NodeOpUpgrade.
+type Phase string
+
+const (
+	// PhaseInProgress means the upgrade has not finished on every node yet.
+	PhaseInProgress Phase = "InProgress"
+	// PhaseCompleted means every selected node runs the new image.
+	PhaseCompleted Phase = "Completed"
+	// PhaseFailed means the operator stopped the upgrade after a failure.
+	PhaseFailed Phase = "Failed"
+)
+
+// ImageRef joins osImage and osVersion into an image reference. osVersion is
+// used as the tag; when it is empty osImage is returned unchanged.
+func ImageRef(osImage, osVersion string) string {
+	if osVersion == "" {
+		return osImage
+	}
+	return osImage + ":" + osVersion
+}
+
+// Name returns the NodeOpUpgrade or Plan name for upgrading the nodes of
+// owner, e.g. a control plane, to image. A new image yields a new name, so
+// every upgrade is a fresh run.
+func Name(owner, image string) string {
+	sum := sha256.Sum256([]byte(image))
+	suffix := "-os-" + hex.EncodeToString(sum[:])[:10]
+	if max := 63 - len(suffix); len(owner) > max {
+		owner = owner[:max]
+	}
+	return owner + suffix
+}
+
+// NewNodeOpUpgrade builds a NodeOpUpgrade that upgrades the control plane
+// nodes to image one at a time and stops at the first failure.
+func NewNodeOpUpgrade(kcpName, image string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(NodeOpUpgradeGVK)
+	u.SetName(Name(kcpName, image))
+	u.SetNamespace(Namespace)
+	u.SetLabels(map[string]string{ControlPlaneLabel: kcpName})
+	u.Object["spec"] = map[string]interface{}{
+		"image": image,
+		"nodeSelector": map[string]interface{}{
+			"matchExpressions": []interface{}{
+				map[string]interface{}{
+					"key":      controlPlaneNodeLabel,
+					"operator": "Exists",
+				},
+			},
+		},
+		"concurrency":     int64(1),
+		"stopOnFailure":   true,
+		"upgradeActive":   true,
+		"upgradeRecovery": false,
+	}
+	return u
+}
+
+// GetPhase reads the phase reported by the operator. Unknown or missing
+// phases are treated as in progress.
+func GetPhase(u *unstructured.Unstructured) (Phase, string) {
+	phase, _, _ := unstructured.NestedString(u.Object, "status", "phase")
+	message, _, _ := unstructured.NestedString(u.Object, "status", "message")
+	switch {
+	case strings.EqualFold(phase, "Completed"), strings.EqualFold(phase, "Succeeded"):
+		return PhaseCompleted, message
+	case strings.EqualFold(phase, "Failed"):
+		return PhaseFailed, message
+	default:
+		return PhaseInProgress, message
+	}
+}
+
+// OperatorInstalled reports whether the NodeOpUpgrade CRD is served by the
+// cluster behind mapper.
+func OperatorInstalled(mapper meta.RESTMapper) (bool, error) {
+	return served(mapper, NodeOpUpgradeGVK)
+}
+
+// served reports whether gvk is served by the cluster behind mapper.
+func served(mapper meta.RESTMapper, gvk schema.GroupVersionKind) (bool, error) {
+	_, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err == nil {
+		return true, nil
+	}
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to look up %s: %w", gvk.Kind, err)
+}
+
+// ApplyManifest server-side applies every object in a multi-document YAML
+// manifest, such as the kairos operator or system-upgrade-controller install
+// bundle.
+func ApplyManifest(ctx context.Context, c client.Client, manifest []byte) error {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifest), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to decode manifest: %w", err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		if err := c.Patch(ctx, obj, client.Apply, client.FieldOwner(fieldOwner), client.ForceOwnership); err != nil {
+			return fmt.Errorf("failed to apply %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+}
+
+// LoadManifest reads a manifest from a local path or an http(s) URL.
+func LoadManifest(source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest %s: %w", source, err)
+		}
+		return data, nil
+	}
+
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	resp, err := httpClient.Get(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download manifest %s: %w", source, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download manifest %s: HTTP %d", source, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", source, err)
+	}
+	return data, nil
+}
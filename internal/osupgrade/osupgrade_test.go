@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package osupgrade
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestImageRef(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(ImageRef("quay.io/kairos/ubuntu", "")).To(Equal("quay.io/kairos/ubuntu"))
+	g.Expect(ImageRef("quay.io/kairos/ubuntu", "v3.5.0")).To(Equal("quay.io/kairos/ubuntu:v3.5.0"))
+}
+
+func TestName(t *testing.T) {
+	g := NewWithT(t)
+
+	a := Name("kcp", "quay.io/kairos/ubuntu:v1")
+	b := Name("kcp", "quay.io/kairos/ubuntu:v2")
+	g.Expect(a).To(HavePrefix("kcp-os-"))
+	g.Expect(a).NotTo(Equal(b))
+	g.Expect(Name("kcp", "quay.io/kairos/ubuntu:v1")).To(Equal(a))
+	g.Expect(len(Name(strings.Repeat("a", 100), "img"))).To(BeNumerically("<=", 63))
+}
+
+func TestNewNodeOpUpgrade(t *testing.T) {
+	g := NewWithT(t)
+
+	u := NewNodeOpUpgrade("kcp", "quay.io/kairos/ubuntu:v1")
+	g.Expect(u.GroupVersionKind()).To(Equal(NodeOpUpgradeGVK))
+	g.Expect(u.GetNamespace()).To(Equal(Namespace))
+	g.Expect(u.GetLabels()).To(HaveKeyWithValue(ControlPlaneLabel, "kcp"))
+
+	image, _, _ := unstructured.NestedString(u.Object, "spec", "image")
+	g.Expect(image).To(Equal("quay.io/kairos/ubuntu:v1"))
+	concurrency, _, _ := unstructured.NestedInt64(u.Object, "spec", "concurrency")
+	g.Expect(concurrency).To(Equal(int64(1)))
+}
+
+func TestGetPhase(t *testing.T) {
+	g := NewWithT(t)
+
+	u := NewNodeOpUpgrade("kcp", "img")
+	phase, _ := GetPhase(u)
+	g.Expect(phase).To(Equal(PhaseInProgress))
+
+	g.Expect(unstructured.SetNestedField(u.Object, "completed", "status", "phase")).To(Succeed())
+	phase, _ = GetPhase(u)
+	g.Expect(phase).To(Equal(PhaseCompleted))
+
+	g.Expect(unstructured.SetNestedField(u.Object, "Failed", "status", "phase")).To(Succeed())
+	g.Expect(unstructured.SetNestedField(u.Object, "job failed on node-0", "status", "message")).To(Succeed())
+	phase, message := GetPhase(u)
+	g.Expect(phase).To(Equal(PhaseFailed))
+	g.Expect(message).To(Equal("job failed on node-0"))
+}
+
+func TestOperatorInstalled(t *testing.T) {
+	g := NewWithT(t)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	installed, err := OperatorInstalled(mapper)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(installed).To(BeFalse())
+
+	mapper.Add(NodeOpUpgradeGVK, meta.RESTScopeNamespace)
+	installed, err = OperatorInstalled(mapper)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(installed).To(BeTrue())
+}
+
+func TestApplyManifest(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	// The fake client does not implement server-side apply, so record the
+	// apply patches instead.
+	var applied []string
+	c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(_ context.Context, _ client.WithWatch, obj client.Object, patch client.Patch, _ ...client.PatchOption) error {
+			g.Expect(patch.Type()).To(Equal(types.ApplyPatchType))
+			applied = append(applied, obj.GetObjectKind().GroupVersionKind().Kind+"/"+obj.GetName())
+			return nil
+		},
+	}).Build()
+
+	manifest := []byte(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: operator-system
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: operator
+  namespace: operator-system
+---
+`)
+	g.Expect(ApplyManifest(context.Background(), c, manifest)).To(Succeed())
+	g.Expect(applied).To(Equal([]string{"Namespace/operator-system", "ServiceAccount/operator"}))
+
+	g.Expect(ApplyManifest(context.Background(), c, []byte("kind: [broken"))).NotTo(Succeed())
+}
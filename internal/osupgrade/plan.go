@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package osupgrade
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PlanGVK is the system-upgrade-controller resource that runs an upgrade job
+// on every node it selects.
+var PlanGVK = schema.GroupVersionKind{
+	Group:   "upgrade.cattle.io",
+	Version: "v1",
+	Kind:    "Plan",
+}
+
+const (
+	// PlanNamespace holds the Plans created in the workload cluster. The
+	// system-upgrade-controller runs its jobs with the service account of the
+	// same name in this namespace.
+	PlanNamespace = "system-upgrade"
+
+	// PlanNodeLabel is set on worker Nodes to the name of the Plan that
+	// upgrades them. Worker Plans select their nodes by it.
+	PlanNodeLabel = "bootstrap.cluster.x-k8s.io/os-upgrade-plan"
+
+	planServiceAccount = "system-upgrade"
+
+	// planAppliedLabelPrefix prefixes the Node label the
+	// system-upgrade-controller sets to the Plan hash once the Plan completed
+	// on the Node.
+	planAppliedLabelPrefix = "plan.upgrade.cattle.io/"
+
+	// sucUpgradeCommand is the upgrade entrypoint of Kairos images.
+	sucUpgradeCommand = "/usr/sbin/suc-upgrade"
+)
+
+// NewControlPlanePlan builds a Plan that upgrades the control plane nodes of
+// kcpName to image:version, concurrency nodes at a time.
+func NewControlPlanePlan(kcpName, image, version string, concurrency int64) *unstructured.Unstructured {
+	u := newPlan(Name(kcpName, ImageRef(image, version)), image, version, concurrency)
+	u.SetLabels(map[string]string{ControlPlaneLabel: kcpName})
+	_ = unstructured.SetNestedField(u.Object, map[string]interface{}{
+		"matchExpressions": []interface{}{
+			map[string]interface{}{
+				"key":      controlPlaneNodeLabel,
+				"operator": "Exists",
+			},
+		},
+	}, "spec", "nodeSelector")
+	// Upgrade jobs have to run on tainted control plane nodes
+	_ = unstructured.SetNestedSlice(u.Object, []interface{}{
+		map[string]interface{}{
+			"key":      controlPlaneNodeLabel,
+			"operator": "Exists",
+			"effect":   string(corev1.TaintEffectNoSchedule),
+		},
+	}, "spec", "tolerations")
+	return u
+}
+
+// NewWorkerPlan builds a Plan that upgrades the worker nodes of clusterName
+// labeled with PlanNodeLabel set to its name to image:version, concurrency
+// nodes at a time.
+func NewWorkerPlan(clusterName, image, version string, concurrency int64) *unstructured.Unstructured {
+	name := Name(clusterName+"-workers", ImageRef(image, version))
+	u := newPlan(name, image, version, concurrency)
+	u.SetLabels(map[string]string{clusterv1.ClusterNameLabel: clusterName})
+	_ = unstructured.SetNestedField(u.Object, map[string]interface{}{
+		"matchLabels": map[string]interface{}{
+			PlanNodeLabel: name,
+		},
+	}, "spec", "nodeSelector")
+	return u
+}
+
+func newPlan(name, image, version string, concurrency int64) *unstructured.Unstructured {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(PlanGVK)
+	u.SetName(name)
+	u.SetNamespace(PlanNamespace)
+	u.Object["spec"] = map[string]interface{}{
+		"concurrency":        concurrency,
+		"version":            version,
+		"serviceAccountName": planServiceAccount,
+		"cordon":             true,
+		"upgrade": map[string]interface{}{
+			"image":   image,
+			"command": []interface{}{sucUpgradeCommand},
+		},
+	}
+	return u
+}
+
+// PlanApplied reports whether the system-upgrade-controller finished plan on
+// node. Nodes count as upgraded once they carry the current Plan hash.
+func PlanApplied(plan *unstructured.Unstructured, node *corev1.Node) bool {
+	hash, _, _ := unstructured.NestedString(plan.Object, "status", "latestHash")
+	return hash != "" && node.Labels[planAppliedLabelPrefix+plan.GetName()] == hash
+}
+
+// PlanProgress counts the Nodes selected by plan and those it completed on.
+func PlanProgress(ctx context.Context, c client.Reader, plan *unstructured.Unstructured) (nodes, updated int32, err error) {
+	raw, _, _ := unstructured.NestedMap(plan.Object, "spec", "nodeSelector")
+	labelSelector := &metav1.LabelSelector{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, labelSelector); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse node selector of Plan %s: %w", plan.GetName(), err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse node selector of Plan %s: %w", plan.GetName(), err)
+	}
+	list := &corev1.NodeList{}
+	if err := c.List(ctx, list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return 0, 0, fmt.Errorf("failed to list nodes of Plan %s: %w", plan.GetName(), err)
+	}
+	for i := range list.Items {
+		nodes++
+		if PlanApplied(plan, &list.Items[i]) {
+			updated++
+		}
+	}
+	return nodes, updated, nil
+}
+
+// PlanApplying returns the nodes the system-upgrade-controller is currently
+// upgrading with plan.
+func PlanApplying(plan *unstructured.Unstructured) []string {
+	nodes, _, _ := unstructured.NestedStringSlice(plan.Object, "status", "applying")
+	return nodes
+}
+
+// SystemUpgradeControllerInstalled reports whether the Plan CRD is served by
+// the cluster behind mapper.
+func SystemUpgradeControllerInstalled(mapper meta.RESTMapper) (bool, error) {
+	return served(mapper, PlanGVK)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package osupgrade
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewControlPlanePlan(t *testing.T) {
+	g := NewWithT(t)
+
+	u := NewControlPlanePlan("kcp", "quay.io/kairos/ubuntu", "v3.5.0", 0)
+	g.Expect(u.GroupVersionKind()).To(Equal(PlanGVK))
+	g.Expect(u.GetNamespace()).To(Equal(PlanNamespace))
+	g.Expect(u.GetName()).To(Equal(Name("kcp", "quay.io/kairos/ubuntu:v3.5.0")))
+	g.Expect(u.GetLabels()).To(HaveKeyWithValue(ControlPlaneLabel, "kcp"))
+
+	image, _, _ := unstructured.NestedString(u.Object, "spec", "upgrade", "image")
+	g.Expect(image).To(Equal("quay.io/kairos/ubuntu"))
+	version, _, _ := unstructured.NestedString(u.Object, "spec", "version")
+	g.Expect(version).To(Equal("v3.5.0"))
+	command, _, _ := unstructured.NestedStringSlice(u.Object, "spec", "upgrade", "command")
+	g.Expect(command).To(Equal([]string{"/usr/sbin/suc-upgrade"}))
+	concurrency, _, _ := unstructured.NestedInt64(u.Object, "spec", "concurrency")
+	g.Expect(concurrency).To(Equal(int64(1)))
+	tolerations, _, _ := unstructured.NestedSlice(u.Object, "spec", "tolerations")
+	g.Expect(tolerations).To(HaveLen(1))
+}
+
+func TestNewWorkerPlan(t *testing.T) {
+	g := NewWithT(t)
+
+	u := NewWorkerPlan("cluster", "quay.io/kairos/ubuntu", "v3.5.0", 2)
+	g.Expect(u.GetName()).To(HavePrefix("cluster-workers-os-"))
+	g.Expect(u.GetLabels()).To(HaveKeyWithValue("cluster.x-k8s.io/cluster-name", "cluster"))
+
+	selector, _, _ := unstructured.NestedStringMap(u.Object, "spec", "nodeSelector", "matchLabels")
+	g.Expect(selector).To(Equal(map[string]string{PlanNodeLabel: u.GetName()}))
+	concurrency, _, _ := unstructured.NestedInt64(u.Object, "spec", "concurrency")
+	g.Expect(concurrency).To(Equal(int64(2)))
+
+	g.Expect(NewWorkerPlan("cluster", "quay.io/kairos/ubuntu", "v3.6.0", 2).GetName()).NotTo(Equal(u.GetName()))
+}
+
+func TestPlanApplied(t *testing.T) {
+	g := NewWithT(t)
+
+	plan := NewControlPlanePlan("kcp", "img", "v1", 1)
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-0"}}
+	g.Expect(PlanApplied(plan, node)).To(BeFalse())
+
+	g.Expect(unstructured.SetNestedField(plan.Object, "abc", "status", "latestHash")).To(Succeed())
+	g.Expect(unstructured.SetNestedStringSlice(plan.Object, []string{"node-0"}, "status", "applying")).To(Succeed())
+	g.Expect(PlanApplied(plan, node)).To(BeFalse())
+	g.Expect(PlanApplying(plan)).To(Equal([]string{"node-0"}))
+
+	node.Labels = map[string]string{"plan.upgrade.cattle.io/" + plan.GetName(): "old"}
+	g.Expect(PlanApplied(plan, node)).To(BeFalse())
+	node.Labels["plan.upgrade.cattle.io/"+plan.GetName()] = "abc"
+	g.Expect(PlanApplied(plan, node)).To(BeTrue())
+}
+
+func TestPlanProgress(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	plan := NewControlPlanePlan("kcp", "img", "v1", 1)
+	g.Expect(unstructured.SetNestedField(plan.Object, "abc", "status", "latestHash")).To(Succeed())
+	appliedLabel := "plan.upgrade.cattle.io/" + plan.GetName()
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "cp-0", Labels: map[string]string{
+			"node-role.kubernetes.io/control-plane": "true",
+			appliedLabel:                            "abc",
+		}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "cp-1", Labels: map[string]string{
+			"node-role.kubernetes.io/control-plane": "true",
+		}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Labels: map[string]string{
+			appliedLabel: "abc",
+		}}},
+	).Build()
+
+	nodes, updated, err := PlanProgress(context.Background(), c, plan)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(nodes).To(Equal(int32(2)))
+	g.Expect(updated).To(Equal(int32(1)))
+}
+
+func TestSystemUpgradeControllerInstalled(t *testing.T) {
+	g := NewWithT(t)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	installed, err := SystemUpgradeControllerInstalled(mapper)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(installed).To(BeFalse())
+
+	mapper.Add(PlanGVK, meta.RESTScopeNamespace)
+	installed, err = SystemUpgradeControllerInstalled(mapper)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(installed).To(BeTrue())
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package redact removes join tokens and other credentials from text that
+// ends up in logs, events and status conditions.
+package redact
+
+import (
+	"regexp"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// Placeholder replaces redacted credentials.
+const Placeholder = "<redacted>"
+
+// tokenPatterns match the join tokens the controllers handle.
+var tokenPatterns = []*regexp.Regexp{
+	// Kubernetes bootstrap tokens, <id>.<secret>
+	regexp.MustCompile(`\b[a-z0-9]{6}\.[a-z0-9]{16}\b`),
+	// k3s secure tokens, K10<CA hash>::<user>:<password>
+	regexp.MustCompile(`K10[0-9a-f]+::[^\s:]+:\S+`),
+	// k0s join tokens, a base64-encoded gzipped kubeconfig
+	regexp.MustCompile(`H4sI[A-Za-z0-9+/]+=*`),
+}
+
+// String returns s with join tokens and the given secrets replaced by
+// Placeholder. Empty secrets are ignored.
+func String(s string, secrets ...string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, Placeholder)
+		}
+	}
+	for _, pattern := range tokenPatterns {
+		s = pattern.ReplaceAllLiteralString(s, Placeholder)
+	}
+	return s
+}
+
+// Conditions redacts the messages of conds in place.
+func Conditions(conds clusterv1.Conditions) {
+	for i := range conds {
+		conds[i].Message = String(conds[i].Message)
+	}
+}
+
+// V1Beta2Conditions redacts the messages of conds in place.
+func V1Beta2Conditions(conds []metav1.Condition) {
+	for i := range conds {
+		conds[i].Message = String(conds[i].Message)
+	}
+}
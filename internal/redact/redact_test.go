@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package redact
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		secrets []string
+		want    string
+	}{
+		{
+			name: "bootstrap token",
+			in:   "failed to join with abcdef.0123456789abcdef: unauthorized",
+			want: "failed to join with <redacted>: unauthorized",
+		},
+		{
+			name: "k3s secure token",
+			in:   "token K10a1b2c3::server:s3cr3t rejected",
+			want: "token <redacted> rejected",
+		},
+		{
+			name: "k0s join token",
+			in:   "command 'k0s token create' failed, stderr: H4sIAAAAAAAC/0yRT4+bPhA=\n",
+			want: "command 'k0s token create' failed, stderr: <redacted>\n",
+		},
+		{
+			name:    "known secret",
+			in:      "user password hunter2 must not contain line breaks",
+			secrets: []string{"", "hunter2"},
+			want:    "user password <redacted> must not contain line breaks",
+		},
+		{
+			name: "no credentials",
+			in:   "worker token secret default/c1-k0s-worker-token does not contain key 'token'",
+			want: "worker token secret default/c1-k0s-worker-token does not contain key 'token'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(String(tt.in, tt.secrets...)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestConditions(t *testing.T) {
+	g := NewWithT(t)
+
+	conds := clusterv1.Conditions{{Type: clusterv1.ReadyCondition, Message: "join token abcdef.0123456789abcdef expired"}}
+	Conditions(conds)
+	g.Expect(conds[0].Message).To(Equal("join token <redacted> expired"))
+
+	v1beta2 := []metav1.Condition{{Type: "Ready", Message: "token K10ff::node:secret"}}
+	V1Beta2Conditions(v1beta2)
+	g.Expect(v1beta2[0].Message).To(Equal("token <redacted>"))
+}
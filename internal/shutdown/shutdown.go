@@ -0,0 +1,41 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package shutdown helps reconcilers finish short critical sections when the
+// manager is stopping.
+//
+// On SIGTERM the manager cancels the context passed to Reconcile. A reconcile
+// that has already created a bootstrap Secret (or the first objects of a new
+// control plane Machine) but not yet recorded it would leave half-created
+// artifacts behind. Such sections run on a context that ignores the parent's
+// cancellation but is bounded by CriticalSectionTimeout, which must stay below
+// the manager's graceful shutdown timeout.
+package shutdown
+
+import (
+	"context"
+	"time"
+)
+
+// CriticalSectionTimeout bounds how long a detached critical section may run.
+var CriticalSectionTimeout = 15 * time.Second
+
+// DetachedContext returns a context that keeps the values of parent (logger,
+// tracing) but is not cancelled when parent is, and expires after
+// CriticalSectionTimeout.
+func DetachedContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(parent), CriticalSectionTimeout)
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package shutdown
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type ctxKey struct{}
+
+func TestDetachedContext(t *testing.T) {
+	g := NewWithT(t)
+
+	parent, cancelParent := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "value"))
+	ctx, cancel := DetachedContext(parent)
+	defer cancel()
+
+	cancelParent()
+	g.Expect(ctx.Err()).NotTo(HaveOccurred())
+	g.Expect(ctx.Value(ctxKey{})).To(Equal("value"))
+
+	deadline, ok := ctx.Deadline()
+	g.Expect(ok).To(BeTrue())
+	g.Expect(time.Until(deadline)).To(BeNumerically("<=", CriticalSectionTimeout))
+}
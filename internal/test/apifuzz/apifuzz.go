@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package apifuzz runs fuzz-based round-trip tests against the Kairos API
+// types so that a new field which is not serialized, or not deep-copied,
+// fails a test instead of silently losing data.
+//
+// The same fuzzer functions are passed to
+// sigs.k8s.io/cluster-api/util/conversion.FuzzTestFunc by the conversion
+// tests of the v1beta1 APIs.
+package apifuzz
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	"k8s.io/apimachinery/pkg/api/apitesting/roundtrip"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
+)
+
+// Funcs returns custom fuzzer functions for the Kairos API types. Add entries
+// here when a field needs values that survive conversion (e.g. enums that an
+// older version cannot represent).
+func Funcs(_ serializer.CodecFactory) []interface{} {
+	return []interface{}{}
+}
+
+// itersEnv overrides the number of fuzzing iterations per kind.
+const itersEnv = "API_FUZZ_ITERS"
+
+// RoundTripTest fuzzes every kind registered by addToScheme and checks that
+// it survives deep copy and a JSON encode/decode round trip unchanged.
+// Kinds listed in skip are not tested.
+func RoundTripTest(t *testing.T, addToScheme func(*runtime.Scheme) error, skip ...schema.GroupVersionKind) {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := addToScheme(scheme); err != nil {
+		t.Fatalf("failed to add types to scheme: %v", err)
+	}
+
+	nonRoundTrippable := map[schema.GroupVersionKind]bool{}
+	for _, gvk := range skip {
+		nonRoundTrippable[gvk] = true
+	}
+
+	if v := os.Getenv(itersEnv); v != "" {
+		iters, err := strconv.Atoi(v)
+		if err != nil || iters < 1 {
+			t.Fatalf("invalid %s %q", itersEnv, v)
+		}
+		*roundtrip.FuzzIters = iters
+	}
+
+	codecs := serializer.NewCodecFactory(scheme)
+	f := utilconversion.GetFuzzer(scheme, fuzzer.FuzzerFuncs(Funcs))
+	roundtrip.RoundTripExternalTypesWithoutProtobuf(t, scheme, codecs, f, nonRoundTrippable)
+}
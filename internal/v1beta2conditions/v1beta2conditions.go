@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package v1beta2conditions maintains the metav1.Condition list of the Cluster
+// API v1beta2 status contract next to the legacy clusterv1.Conditions.
+//
+// Controllers keep setting the legacy conditions with the conditions package;
+// Mirror copies one of them into the v1beta2 list so consumers of either
+// contract see the same state.
+package v1beta2conditions
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// Setter is an object exposing both legacy and v1beta2 conditions.
+type Setter interface {
+	conditions.Getter
+	GetV1Beta2Conditions() []metav1.Condition
+	SetV1Beta2Conditions([]metav1.Condition)
+}
+
+// Set adds or updates condition on obj. ObservedGeneration is set to the
+// generation of obj, and LastTransitionTime only changes with the status.
+func Set(obj Setter, condition metav1.Condition) {
+	condition.ObservedGeneration = obj.GetGeneration()
+	list := obj.GetV1Beta2Conditions()
+	meta.SetStatusCondition(&list, condition)
+	obj.SetV1Beta2Conditions(list)
+}
+
+// Mirror sets the v1beta2 condition of conditionType from the legacy condition
+// of the same type. A true condition gets trueReason; otherwise the legacy
+// reason is kept, falling back to falseReason when there is none.
+func Mirror(obj Setter, conditionType clusterv1.ConditionType, trueReason, falseReason string) {
+	condition := metav1.Condition{
+		Type:    string(conditionType),
+		Status:  metav1.ConditionUnknown,
+		Reason:  falseReason,
+		Message: fmt.Sprintf("%s condition not reported yet", conditionType),
+	}
+	if legacy := conditions.Get(obj, conditionType); legacy != nil {
+		condition.Status = metav1.ConditionStatus(legacy.Status)
+		condition.Message = legacy.Message
+		if legacy.Reason != "" {
+			condition.Reason = legacy.Reason
+		}
+	}
+	if condition.Status == metav1.ConditionTrue {
+		condition.Reason = trueReason
+	}
+	Set(obj, condition)
+}
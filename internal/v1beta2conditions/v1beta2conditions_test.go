@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package v1beta2conditions
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+)
+
+func TestMirror(t *testing.T) {
+	g := NewWithT(t)
+
+	config := &bootstrapv1beta2.KairosConfig{ObjectMeta: metav1.ObjectMeta{Generation: 3}}
+	ready := func() *metav1.Condition {
+		return meta.FindStatusCondition(config.GetV1Beta2Conditions(), string(clusterv1.ReadyCondition))
+	}
+
+	// A legacy condition that was never set is reported as unknown
+	Mirror(config, clusterv1.ReadyCondition, "Ready", "NotReady")
+	g.Expect(ready().Status).To(Equal(metav1.ConditionUnknown))
+	g.Expect(ready().Reason).To(Equal("NotReady"))
+	g.Expect(ready().ObservedGeneration).To(Equal(int64(3)))
+
+	conditions.MarkFalse(config, clusterv1.ReadyCondition, "BootstrapFailed", clusterv1.ConditionSeverityWarning, "boom")
+	Mirror(config, clusterv1.ReadyCondition, "Ready", "NotReady")
+	g.Expect(ready().Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(ready().Reason).To(Equal("BootstrapFailed"))
+	g.Expect(ready().Message).To(Equal("boom"))
+	transition := ready().LastTransitionTime
+
+	conditions.MarkTrue(config, clusterv1.ReadyCondition)
+	Mirror(config, clusterv1.ReadyCondition, "Ready", "NotReady")
+	g.Expect(ready().Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(ready().Reason).To(Equal("Ready"))
+	g.Expect(ready().Message).To(BeEmpty())
+	g.Expect(ready().LastTransitionTime.Before(&transition)).To(BeFalse())
+	g.Expect(config.GetV1Beta2Conditions()).To(HaveLen(1))
+}
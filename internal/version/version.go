@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package version exposes build information for the manager and kubevirt-env.
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CAPIContract is the Cluster API contract version implemented by the provider.
+// It matches the cluster.x-k8s.io/v1beta1 contract label of the CRDs; the
+// provider is built against the v1beta1 Cluster API types.
+const CAPIContract = "v1beta1"
+
+// These are set at build time via -ldflags "-X ...". When unset, GitCommit
+// falls back to the VCS revision embedded by the Go toolchain.
+var (
+	Version   = "dev"
+	GitCommit = ""
+	BuildDate = ""
+)
+
+// Info describes the running binary.
+type Info struct {
+	Version      string `json:"version"`
+	GitCommit    string `json:"gitCommit"`
+	BuildDate    string `json:"buildDate"`
+	GoVersion    string `json:"goVersion"`
+	Platform     string `json:"platform"`
+	CAPIContract string `json:"capiContract"`
+}
+
+// Get returns the build information of the running binary.
+func Get() Info {
+	info := Info{
+		Version:      Version,
+		GitCommit:    GitCommit,
+		BuildDate:    BuildDate,
+		GoVersion:    runtime.Version(),
+		Platform:     fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		CAPIContract: CAPIContract,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.GitCommit == "" {
+					info.GitCommit = s.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = s.Value
+				}
+			}
+		}
+	}
+	if info.GitCommit == "" {
+		info.GitCommit = "unknown"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+	return info
+}
+
+// String returns a single-line, human readable version string.
+func (i Info) String() string {
+	return fmt.Sprintf("%s (commit %s, built %s, %s, %s, CAPI contract %s)",
+		i.Version, i.GitCommit, i.BuildDate, i.GoVersion, i.Platform, i.CAPIContract)
+}
+
+// NewCollector returns a kairos_capi_build_info gauge, always set to 1, whose
+// labels carry the build information.
+func NewCollector() prometheus.Collector {
+	info := Get()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "kairos_capi_build_info",
+		Help: "Build information of the running Kairos CAPI binary. Always 1.",
+		ConstLabels: prometheus.Labels{
+			"version":       info.Version,
+			"git_commit":    info.GitCommit,
+			"go_version":    info.GoVersion,
+			"capi_contract": info.CAPIContract,
+		},
+	})
+	gauge.Set(1)
+	return gauge
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package version
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestGet(t *testing.T) {
+	g := NewWithT(t)
+
+	info := Get()
+	g.Expect(info.Version).NotTo(BeEmpty())
+	g.Expect(info.GitCommit).NotTo(BeEmpty())
+	g.Expect(info.GoVersion).To(HavePrefix("go"))
+	g.Expect(info.CAPIContract).To(Equal(CAPIContract))
+	g.Expect(info.String()).To(ContainSubstring(info.Version))
+}
+
+func TestNewCollector(t *testing.T) {
+	g := NewWithT(t)
+
+	reg := prometheus.NewRegistry()
+	g.Expect(reg.Register(NewCollector())).To(Succeed())
+
+	expected := `
+# HELP kairos_capi_build_info Build information of the running Kairos CAPI binary. Always 1.
+# TYPE kairos_capi_build_info gauge
+`
+	g.Expect(testutil.GatherAndCount(reg, "kairos_capi_build_info")).To(Equal(1))
+	g.Expect(testutil.GatherAndCompare(reg, strings.NewReader(expected+buildInfoLine()), "kairos_capi_build_info")).To(Succeed())
+}
+
+func buildInfoLine() string {
+	info := Get()
+	return `kairos_capi_build_info{capi_contract="` + info.CAPIContract + `",git_commit="` + info.GitCommit +
+		`",go_version="` + info.GoVersion + `",version="` + info.Version + `"} 1
+`
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package workload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Default delays between connection attempts to an unreachable workload
+// cluster.
+const (
+	DefaultConnectionBackoff    = 10 * time.Second
+	DefaultMaxConnectionBackoff = 5 * time.Minute
+)
+
+// BackoffError is returned instead of connecting to a workload cluster whose
+// last connection attempt failed less than the backoff delay ago.
+type BackoffError struct {
+	Cluster client.ObjectKey
+	// Remaining is the time until the next connection attempt.
+	Remaining time.Duration
+	// Err is the error of the last connection attempt.
+	Err error
+}
+
+func (e *BackoffError) Error() string {
+	return fmt.Sprintf("not connecting to workload cluster %s for another %s after failed attempt: %v",
+		e.Cluster, e.Remaining.Round(time.Second), e.Err)
+}
+
+func (e *BackoffError) Unwrap() error {
+	return e.Err
+}
+
+// ConnectionBackoff spaces out connection attempts to workload clusters that
+// cannot be reached, so that reconciles do not build a new client for an
+// unreachable API server every time. The delay doubles after every failed
+// attempt, up to a maximum, and is reset by a successful one. A nil
+// ConnectionBackoff never backs off.
+type ConnectionBackoff struct {
+	initial time.Duration
+	max     time.Duration
+	now     func() time.Time
+
+	mu       sync.Mutex
+	failures map[client.ObjectKey]*connectionFailure
+}
+
+type connectionFailure struct {
+	delay time.Duration
+	retry time.Time
+	err   error
+}
+
+// NewConnectionBackoff returns a ConnectionBackoff waiting initial after the
+// first failed attempt and at most maxDelay.
+func NewConnectionBackoff(initial, maxDelay time.Duration) *ConnectionBackoff {
+	if initial <= 0 {
+		initial = DefaultConnectionBackoff
+	}
+	if maxDelay < initial {
+		maxDelay = initial
+	}
+	return &ConnectionBackoff{
+		initial:  initial,
+		max:      maxDelay,
+		now:      time.Now,
+		failures: map[client.ObjectKey]*connectionFailure{},
+	}
+}
+
+// Connect runs connect unless cluster is backed off, in which case it returns
+// a *BackoffError. An error from connect extends the backoff, except
+// remote.ErrClusterLocked, which only means another worker is connecting.
+func (b *ConnectionBackoff) Connect(cluster client.ObjectKey, connect func() error) error {
+	if b == nil {
+		return connect()
+	}
+
+	b.mu.Lock()
+	if failure, ok := b.failures[cluster]; ok {
+		if remaining := failure.retry.Sub(b.now()); remaining > 0 {
+			b.mu.Unlock()
+			return &BackoffError{Cluster: cluster, Remaining: remaining, Err: failure.err}
+		}
+	}
+	b.mu.Unlock()
+
+	err := connect()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case err == nil:
+		delete(b.failures, cluster)
+	case errors.Is(err, remote.ErrClusterLocked):
+	default:
+		delay := b.initial
+		if failure, ok := b.failures[cluster]; ok {
+			delay = min(failure.delay*2, b.max)
+		}
+		b.failures[cluster] = &connectionFailure{delay: delay, retry: b.now().Add(delay), err: err}
+	}
+	return err
+}
+
+// GetClient returns the tracker's client for cluster through Connect.
+func (b *ConnectionBackoff) GetClient(ctx context.Context, tracker *remote.ClusterCacheTracker, cluster client.ObjectKey) (client.Client, error) {
+	var c client.Client
+	err := b.Connect(cluster, func() error {
+		var err error
+		c, err = tracker.GetClient(ctx, cluster)
+		return err
+	})
+	return c, err
+}
+
+// Watch starts a watch on the workload cluster through Connect.
+func (b *ConnectionBackoff) Watch(ctx context.Context, tracker *remote.ClusterCacheTracker, input remote.WatchInput) error {
+	return b.Connect(input.Cluster, func() error {
+		return tracker.Watch(ctx, input)
+	})
+}
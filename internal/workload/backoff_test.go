@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package workload
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestConnectionBackoff(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	b := NewConnectionBackoff(10*time.Second, 30*time.Second)
+	b.now = func() time.Time { return now }
+
+	cluster := client.ObjectKey{Namespace: "default", Name: "c1"}
+	other := client.ObjectKey{Namespace: "default", Name: "c2"}
+	unreachable := errors.New("connection refused")
+	attempts := 0
+	fail := func() error { attempts++; return unreachable }
+	succeed := func() error { attempts++; return nil }
+
+	g.Expect(b.Connect(cluster, fail)).To(MatchError(unreachable))
+	g.Expect(attempts).To(Equal(1))
+
+	// Backed off: connect is not called and the last error is kept
+	err := b.Connect(cluster, succeed)
+	var backoff *BackoffError
+	g.Expect(errors.As(err, &backoff)).To(BeTrue())
+	g.Expect(backoff.Remaining).To(Equal(10 * time.Second))
+	g.Expect(err).To(MatchError(unreachable))
+	g.Expect(attempts).To(Equal(1))
+
+	// Other clusters are not affected
+	g.Expect(b.Connect(other, succeed)).To(Succeed())
+	g.Expect(attempts).To(Equal(2))
+
+	// The delay doubles up to the maximum
+	now = now.Add(10 * time.Second)
+	g.Expect(b.Connect(cluster, fail)).To(MatchError(unreachable))
+	g.Expect(errors.As(b.Connect(cluster, succeed), &backoff)).To(BeTrue())
+	g.Expect(backoff.Remaining).To(Equal(20 * time.Second))
+	now = now.Add(20 * time.Second)
+	g.Expect(b.Connect(cluster, fail)).To(MatchError(unreachable))
+	g.Expect(errors.As(b.Connect(cluster, succeed), &backoff)).To(BeTrue())
+	g.Expect(backoff.Remaining).To(Equal(30 * time.Second))
+
+	// A successful attempt resets the backoff
+	now = now.Add(30 * time.Second)
+	g.Expect(b.Connect(cluster, succeed)).To(Succeed())
+	g.Expect(b.Connect(cluster, fail)).To(MatchError(unreachable))
+	g.Expect(errors.As(b.Connect(cluster, succeed), &backoff)).To(BeTrue())
+	g.Expect(backoff.Remaining).To(Equal(10 * time.Second))
+
+	// A locked cluster is being connected to by another worker and is not
+	// backed off
+	g.Expect(b.Connect(other, func() error { return remote.ErrClusterLocked })).To(MatchError(remote.ErrClusterLocked))
+	g.Expect(b.Connect(other, succeed)).To(Succeed())
+
+	// A nil backoff always connects
+	var none *ConnectionBackoff
+	g.Expect(none.Connect(cluster, fail)).To(MatchError(unreachable))
+	g.Expect(none.Connect(cluster, succeed)).To(Succeed())
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package workload contains helpers for reading workload cluster state
+// through the shared ClusterCacheTracker client.
+package workload
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// NodeForMachine returns the workload cluster Node backing machine, or nil if
+// the node has not joined yet. It uses the Machine's NodeRef when set and
+// falls back to matching the providerID.
+func NodeForMachine(ctx context.Context, c client.Reader, machine *clusterv1.Machine) (*corev1.Node, error) {
+	if machine.Status.NodeRef != nil {
+		node := &corev1.Node{}
+		err := c.Get(ctx, client.ObjectKey{Name: machine.Status.NodeRef.Name}, node)
+		if err == nil {
+			return node, nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get node %s: %w", machine.Status.NodeRef.Name, err)
+		}
+	}
+
+	if machine.Spec.ProviderID == nil || *machine.Spec.ProviderID == "" {
+		return nil, nil
+	}
+
+	nodeList := &corev1.NodeList{}
+	if err := c.List(ctx, nodeList); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	for i := range nodeList.Items {
+		if nodeList.Items[i].Spec.ProviderID == *machine.Spec.ProviderID {
+			return &nodeList.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// MachinesForNode returns the Machines of cluster that are backed by node,
+// matched by NodeRef name or providerID.
+func MachinesForNode(ctx context.Context, c client.Reader, cluster client.ObjectKey, node *corev1.Node) ([]clusterv1.Machine, error) {
+	machineList := &clusterv1.MachineList{}
+	if err := c.List(ctx, machineList,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: cluster.Name},
+	); err != nil {
+		return nil, fmt.Errorf("failed to list machines: %w", err)
+	}
+
+	var machines []clusterv1.Machine
+	for _, machine := range machineList.Items {
+		if machine.Status.NodeRef != nil && machine.Status.NodeRef.Name == node.Name {
+			machines = append(machines, machine)
+			continue
+		}
+		if node.Spec.ProviderID != "" && machine.Spec.ProviderID != nil && *machine.Spec.ProviderID == node.Spec.ProviderID {
+			machines = append(machines, machine)
+		}
+	}
+	return machines, nil
+}
+
+// IsNodeReady reports whether the node's Ready condition is True.
+func IsNodeReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// NodeReadinessChanged filters Node events down to the ones that can change
+// machine join/Ready state, dropping the periodic heartbeat updates.
+func NodeReadinessChanged() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, ok := e.ObjectOld.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			newNode, ok := e.ObjectNew.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			return IsNodeReady(oldNode) != IsNodeReady(newNode) ||
+				oldNode.Spec.ProviderID != newNode.Spec.ProviderID
+		},
+	}
+}
+
+// NodeAnnotationChanged filters Node update events down to the ones that
+// change the annotation key.
+func NodeAnnotationChanged(key string) predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetAnnotations()[key] != e.ObjectNew.GetAnnotations()[key]
+		},
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package workload
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func readyNode(name, providerID string, ready corev1.ConditionStatus) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{ProviderID: providerID},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+		},
+	}
+}
+
+func TestNodeForMachine(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	workloadClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		readyNode("node-a", "kubevirt://a", corev1.ConditionTrue),
+		readyNode("node-b", "kubevirt://b", corev1.ConditionFalse),
+	).Build()
+
+	providerID := "kubevirt://b"
+	byProviderID := &clusterv1.Machine{Spec: clusterv1.MachineSpec{ProviderID: &providerID}}
+	node, err := NodeForMachine(context.Background(), workloadClient, byProviderID)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(node).NotTo(BeNil())
+	g.Expect(node.Name).To(Equal("node-b"))
+	g.Expect(IsNodeReady(node)).To(BeFalse())
+
+	byNodeRef := &clusterv1.Machine{Status: clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: "node-a"}}}
+	node, err = NodeForMachine(context.Background(), workloadClient, byNodeRef)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(node.Name).To(Equal("node-a"))
+	g.Expect(IsNodeReady(node)).To(BeTrue())
+
+	missing := "kubevirt://c"
+	node, err = NodeForMachine(context.Background(), workloadClient, &clusterv1.Machine{Spec: clusterv1.MachineSpec{ProviderID: &missing}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(node).To(BeNil())
+}
+
+func TestMachinesForNode(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	providerID := "kubevirt://a"
+	machine := func(name, cluster string, providerID *string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster},
+			},
+			Spec: clusterv1.MachineSpec{ClusterName: cluster, ProviderID: providerID},
+		}
+	}
+	mgmtClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		machine("m-a", "c1", &providerID),
+		machine("m-other", "c2", &providerID),
+		machine("m-none", "c1", nil),
+	).Build()
+
+	machines, err := MachinesForNode(context.Background(), mgmtClient, client.ObjectKey{Namespace: "default", Name: "c1"},
+		readyNode("node-a", providerID, corev1.ConditionTrue))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(machines).To(HaveLen(1))
+	g.Expect(machines[0].Name).To(Equal("m-a"))
+}
+
+func TestNodeReadinessChanged(t *testing.T) {
+	g := NewWithT(t)
+
+	p := NodeReadinessChanged()
+	oldNode := readyNode("n", "", corev1.ConditionFalse)
+
+	heartbeat := oldNode.DeepCopy()
+	heartbeat.Status.Conditions[0].LastHeartbeatTime = metav1.Now()
+	g.Expect(p.Update(event.UpdateEvent{ObjectOld: oldNode, ObjectNew: heartbeat})).To(BeFalse())
+
+	g.Expect(p.Update(event.UpdateEvent{ObjectOld: oldNode, ObjectNew: readyNode("n", "", corev1.ConditionTrue)})).To(BeTrue())
+	g.Expect(p.Update(event.UpdateEvent{ObjectOld: oldNode, ObjectNew: readyNode("n", "kubevirt://n", corev1.ConditionFalse)})).To(BeTrue())
+	g.Expect(p.Create(event.CreateEvent{Object: oldNode})).To(BeTrue())
+}
+
+func TestNodeAnnotationChanged(t *testing.T) {
+	g := NewWithT(t)
+
+	p := NodeAnnotationChanged("example.com/done")
+	oldNode := readyNode("n", "", corev1.ConditionTrue)
+
+	unrelated := oldNode.DeepCopy()
+	unrelated.Annotations = map[string]string{"example.com/other": "x"}
+	g.Expect(p.Update(event.UpdateEvent{ObjectOld: oldNode, ObjectNew: unrelated})).To(BeFalse())
+
+	annotated := oldNode.DeepCopy()
+	annotated.Annotations = map[string]string{"example.com/done": "2024-01-01T00:00:00Z"}
+	g.Expect(p.Update(event.UpdateEvent{ObjectOld: oldNode, ObjectNew: annotated})).To(BeTrue())
+}
@@ -18,25 +18,42 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"time"
 
+	"golang.org/x/time/rate"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/remote"
 
+	bootstrapv1beta1 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta1"
 	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+	controlplanev1beta1 "github.com/kairos-io/kairos-capi/api/controlplane/v1beta1"
 	controlplanev1beta2 "github.com/kairos-io/kairos-capi/api/controlplane/v1beta2"
+	infrastructurev1beta2 "github.com/kairos-io/kairos-capi/api/infrastructure/v1beta2"
+	"github.com/kairos-io/kairos-capi/internal/cachefilter"
 	"github.com/kairos-io/kairos-capi/internal/config"
 	"github.com/kairos-io/kairos-capi/internal/controllers/bootstrap"
 	"github.com/kairos-io/kairos-capi/internal/controllers/controlplane"
+	"github.com/kairos-io/kairos-capi/internal/controllers/infrastructure"
+	"github.com/kairos-io/kairos-capi/internal/logging"
+	"github.com/kairos-io/kairos-capi/internal/metrics"
+	"github.com/kairos-io/kairos-capi/internal/osupgrade"
+	"github.com/kairos-io/kairos-capi/internal/version"
+	"github.com/kairos-io/kairos-capi/internal/workload"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -48,35 +65,129 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(clusterv1.AddToScheme(scheme))
+	utilruntime.Must(bootstrapv1beta1.AddToScheme(scheme))
 	utilruntime.Must(bootstrapv1beta2.AddToScheme(scheme))
+	utilruntime.Must(controlplanev1beta1.AddToScheme(scheme))
 	utilruntime.Must(controlplanev1beta2.AddToScheme(scheme))
+	utilruntime.Must(infrastructurev1beta2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
+	var leaderElectionNamespace string
+	var leaderElectionLeaseDuration time.Duration
+	var leaderElectionRenewDeadline time.Duration
+	var leaderElectionRetryPeriod time.Duration
 	var probeAddr string
+	var showVersion bool
+	var gracefulShutdownTimeout time.Duration
+	var enableWebhooks bool
+	var kairosOperatorManifest string
+	var systemUpgradeControllerManifest string
+	var workloadClusterClientQPS float64
+	var workloadClusterClientBurst int
+	var workloadClusterConnectionBackoff time.Duration
+	var workloadClusterMaxConnectionBackoff time.Duration
+	var kairosConfigConcurrency int
+	var kairosControlPlaneConcurrency int
+	var kairosClusterConcurrency int
+	var kairosMachineConcurrency int
+	var kairosHostConcurrency int
+	var syncPeriod time.Duration
+	var rateLimiterBaseDelay time.Duration
+	var rateLimiterMaxDelay time.Duration
+	var rateLimiterQPS float64
+	var rateLimiterBurst int
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "",
+		"Namespace of the leader election lease. Defaults to the namespace the manager runs in.")
+	flag.DurationVar(&leaderElectionLeaseDuration, "leader-elect-lease-duration", 15*time.Second,
+		"How long replicas that are not the leader wait before taking over a lease that was not renewed.")
+	flag.DurationVar(&leaderElectionRenewDeadline, "leader-elect-renew-deadline", 10*time.Second,
+		"How long the leader keeps trying to renew its lease before giving up leadership. "+
+			"Must be lower than --leader-elect-lease-duration.")
+	flag.DurationVar(&leaderElectionRetryPeriod, "leader-elect-retry-period", 2*time.Second,
+		"How often replicas try to acquire or renew the lease.")
+	flag.BoolVar(&showVersion, "version", false, "Print version information and exit.")
+	flag.DurationVar(&gracefulShutdownTimeout, "graceful-shutdown-timeout", 30*time.Second,
+		"How long to wait on shutdown for in-flight reconciles to finish before exiting. "+
+			"Must be lower than the pod's terminationGracePeriodSeconds.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", true,
+		"Serve the defaulting and validating admission webhooks. When disabled, specs are "+
+			"validated during reconciliation and reported through the ValidSpec condition.")
+	flag.StringVar(&kairosOperatorManifest, "kairos-operator-manifest", "",
+		"Path or http(s) URL of the kairos operator manifest to install in workload clusters that "+
+			"set spec.osImage on their KairosControlPlane. When empty, the operator must already be installed.")
+	flag.StringVar(&systemUpgradeControllerManifest, "system-upgrade-controller-manifest", "",
+		"Path or http(s) URL of the system-upgrade-controller manifest to install in workload clusters that "+
+			"set spec.osUpgrade on their KairosControlPlane or KairosConfigs. When empty, it must already be installed.")
+	flag.Float64Var(&workloadClusterClientQPS, "workload-cluster-client-qps", 20,
+		"Maximum queries per second from the cached client of each workload cluster.")
+	flag.IntVar(&workloadClusterClientBurst, "workload-cluster-client-burst", 30,
+		"Maximum burst of queries from the cached client of each workload cluster.")
+	flag.DurationVar(&workloadClusterConnectionBackoff, "workload-cluster-connection-backoff", workload.DefaultConnectionBackoff,
+		"How long to wait before connecting again to a workload cluster that could not be reached. "+
+			"The delay doubles with every failed attempt.")
+	flag.DurationVar(&workloadClusterMaxConnectionBackoff, "workload-cluster-max-connection-backoff", workload.DefaultMaxConnectionBackoff,
+		"Maximum delay between connection attempts to an unreachable workload cluster.")
+	flag.IntVar(&kairosConfigConcurrency, "kairosconfig-concurrency", 10,
+		"Number of KairosConfigs to reconcile concurrently.")
+	flag.IntVar(&kairosControlPlaneConcurrency, "kairoscontrolplane-concurrency", 10,
+		"Number of KairosControlPlanes to reconcile concurrently.")
+	flag.IntVar(&kairosClusterConcurrency, "kairoscluster-concurrency", 10,
+		"Number of KairosClusters to reconcile concurrently.")
+	flag.IntVar(&kairosMachineConcurrency, "kairosmachine-concurrency", 10,
+		"Number of KairosMachines to reconcile concurrently.")
+	flag.IntVar(&kairosHostConcurrency, "kairoshost-concurrency", 10,
+		"Number of KairosHosts to reconcile concurrently.")
+	flag.DurationVar(&syncPeriod, "sync-period", 10*time.Minute,
+		"How often all watched objects are reconciled again, even without changes.")
+	flag.DurationVar(&rateLimiterBaseDelay, "rate-limiter-base-delay", 5*time.Millisecond,
+		"Requeue delay after the first failed reconcile of an object; it doubles with every further failure.")
+	flag.DurationVar(&rateLimiterMaxDelay, "rate-limiter-max-delay", 1000*time.Second,
+		"Maximum requeue delay after failed reconciles of an object.")
+	flag.Float64Var(&rateLimiterQPS, "rate-limiter-qps", 10,
+		"Maximum rate at which each controller requeues objects, across all objects.")
+	flag.IntVar(&rateLimiterBurst, "rate-limiter-burst", 100,
+		"Maximum burst of requeues of each controller, across all objects.")
 	opts := zap.Options{
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
+	logOpts := logging.Options{Verbosity: 1}
+	logOpts.AddFlags(flag.CommandLine)
 	flag.Parse()
 
+	if showVersion {
+		fmt.Println(version.Get())
+		os.Exit(0)
+	}
+
 	// Load configuration
 	cfg := config.LoadConfig()
 
-	// Set log level if specified
-	if cfg.LogLevel == "debug" {
-		opts.Development = true
+	// LOG_LEVEL=debug enables the debug logs unless --log-verbosity is set
+	if cfg.LogLevel == "debug" && !flagSet("log-verbosity") {
+		logOpts.SetVerbosity(4)
 	}
 
-	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	logger, err := logging.New(logOpts, &opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	ctrl.SetLogger(logger)
+
+	buildInfo := version.Get()
+	setupLog.Info("Kairos CAPI provider", "version", buildInfo.Version, "gitCommit", buildInfo.GitCommit,
+		"goVersion", buildInfo.GoVersion, "capiContract", buildInfo.CAPIContract)
+	ctrlmetrics.Registry.MustRegister(version.NewCollector())
 
 	// Configure manager options
 	mgrOptions := ctrl.Options{
@@ -87,17 +198,32 @@ func main() {
 		WebhookServer: webhook.NewServer(webhook.Options{
 			Port: 9443,
 		}),
-		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "kairos-capi-leader-election",
+		HealthProbeBindAddress:  probeAddr,
+		LeaderElection:          enableLeaderElection,
+		LeaderElectionID:        "kairos-capi-leader-election",
+		LeaderElectionNamespace: leaderElectionNamespace,
+		LeaseDuration:           &leaderElectionLeaseDuration,
+		RenewDeadline:           &leaderElectionRenewDeadline,
+		RetryPeriod:             &leaderElectionRetryPeriod,
+		// Stop accepting new work on SIGTERM and give in-flight reconciles time to
+		// finish their critical sections (see internal/shutdown) before exiting.
+		GracefulShutdownTimeout: &gracefulShutdownTimeout,
+		// Release the lease on shutdown so a new replica takes over immediately
+		// during rolling upgrades.
+		LeaderElectionReleaseOnCancel: true,
+	}
+
+	// Only cache Secrets and ConfigMaps that belong to a Cluster API cluster;
+	// anything else is read directly from the API server when referenced.
+	mgrOptions.Cache = cache.Options{
+		ByObject:   cachefilter.ByObject(),
+		SyncPeriod: &syncPeriod,
 	}
 
 	// Set cache namespace if WATCH_NAMESPACE is configured
 	if !cfg.ShouldWatchAllNamespaces() {
-		mgrOptions.Cache = cache.Options{
-			DefaultNamespaces: map[string]cache.Config{
-				cfg.GetWatchNamespace(): {},
-			},
+		mgrOptions.Cache.DefaultNamespaces = map[string]cache.Config{
+			cfg.GetWatchNamespace(): {},
 		}
 		setupLog.Info("Watching single namespace", "namespace", cfg.GetWatchNamespace())
 	} else {
@@ -110,31 +236,138 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Object counts are read from the manager cache on every scrape
+	metrics.Register(ctrlmetrics.Registry, mgr.GetClient())
+
+	ctx := ctrl.SetupSignalHandler()
+
+	var operatorManifest []byte
+	if kairosOperatorManifest != "" {
+		operatorManifest, err = osupgrade.LoadManifest(kairosOperatorManifest)
+		if err != nil {
+			setupLog.Error(err, "unable to load kairos operator manifest")
+			os.Exit(1)
+		}
+	}
+	var sucManifest []byte
+	if systemUpgradeControllerManifest != "" {
+		sucManifest, err = osupgrade.LoadManifest(systemUpgradeControllerManifest)
+		if err != nil {
+			setupLog.Error(err, "unable to load system-upgrade-controller manifest")
+			os.Exit(1)
+		}
+	}
+
+	// One tracker is shared by all controllers so each workload cluster gets a
+	// single cached client and connection, health-checked in the background.
+	// The tracker drops the client of a cluster that fails its health checks;
+	// the connection backoff then keeps reconciles from reconnecting to it on
+	// every run.
+	trackerLog := ctrl.Log.WithName("remote").WithName("ClusterCacheTracker")
+	tracker, err := remote.NewClusterCacheTracker(mgr, remote.ClusterCacheTrackerOptions{
+		ControllerName: "kairos-capi",
+		Log:            &trackerLog,
+		ClientQPS:      float32(workloadClusterClientQPS),
+		ClientBurst:    workloadClusterClientBurst,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to create cluster cache tracker")
+		os.Exit(1)
+	}
+	if err = (&remote.ClusterCacheReconciler{
+		Client:  mgr.GetClient(),
+		Tracker: tracker,
+	}).SetupWithManager(ctx, mgr, controller.Options{}); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterCacheReconciler")
+		os.Exit(1)
+	}
+	// Large fleets need several workers per controller so that one slow
+	// workload cluster does not hold up every other machine.
+	controllerOptions := func(concurrency int) controller.Options {
+		return controller.Options{
+			MaxConcurrentReconciles: concurrency,
+			RateLimiter:             rateLimiter(rateLimiterBaseDelay, rateLimiterMaxDelay, rateLimiterQPS, rateLimiterBurst),
+		}
+	}
+	connectionBackoff := workload.NewConnectionBackoff(workloadClusterConnectionBackoff, workloadClusterMaxConnectionBackoff)
+
 	if err = (&bootstrap.KairosConfigReconciler{
 		Client:     mgr.GetClient(),
 		Scheme:     mgr.GetScheme(),
 		RESTConfig: mgr.GetConfig(),
-	}).SetupWithManager(mgr); err != nil {
+		APIReader:  mgr.GetAPIReader(),
+		Tracker:    tracker,
+
+		ConnectionBackoff:               connectionBackoff,
+		SystemUpgradeControllerManifest: sucManifest,
+		ValidateOnReconcile:             !enableWebhooks,
+	}).SetupWithManager(mgr, controllerOptions(kairosConfigConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KairosConfig")
 		os.Exit(1)
 	}
 
 	if err = (&controlplane.KairosControlPlaneReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		APIReader: mgr.GetAPIReader(),
+		Tracker:   tracker,
+
+		ConnectionBackoff:               connectionBackoff,
+		KairosOperatorManifest:          operatorManifest,
+		SystemUpgradeControllerManifest: sucManifest,
+		ValidateOnReconcile:             !enableWebhooks,
+	}).SetupWithManager(mgr, controllerOptions(kairosControlPlaneConcurrency)); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KairosControlPlane")
+		os.Exit(1)
+	}
+
+	if err = (&infrastructure.KairosClusterReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "KairosControlPlane")
+	}).SetupWithManager(ctx, mgr, controllerOptions(kairosClusterConcurrency)); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KairosCluster")
 		os.Exit(1)
 	}
 
-	if err = (&bootstrapv1beta2.KairosConfig{}).SetupWebhookWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create webhook", "webhook", "KairosConfig")
+	if err = (&infrastructure.KairosMachineReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr, controllerOptions(kairosMachineConcurrency)); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KairosMachine")
 		os.Exit(1)
 	}
-	if err = (&controlplanev1beta2.KairosControlPlane{}).SetupWebhookWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create webhook", "webhook", "KairosControlPlane")
+
+	if err = (&infrastructure.KairosHostReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr, controllerOptions(kairosHostConcurrency)); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KairosHost")
 		os.Exit(1)
 	}
+
+	// The webhook server is only started once a webhook is registered, so
+	// skipping registration leaves port 9443 closed. Registering the v1beta2
+	// hubs also serves the /convert endpoint for the v1beta1 APIs.
+	if enableWebhooks {
+		if err = (&bootstrapv1beta2.KairosConfig{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "KairosConfig")
+			os.Exit(1)
+		}
+		if err = (&bootstrapv1beta2.KairosConfigTemplate{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "KairosConfigTemplate")
+			os.Exit(1)
+		}
+		if err = (&controlplanev1beta2.KairosControlPlane{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "KairosControlPlane")
+			os.Exit(1)
+		}
+		if err = (&controlplanev1beta2.KairosControlPlaneTemplate{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "KairosControlPlaneTemplate")
+			os.Exit(1)
+		}
+	} else {
+		setupLog.Info("Admission webhooks disabled, validating specs during reconciliation")
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -145,10 +378,43 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	// Every replica serves webhooks, leader or not, so a replica only becomes
+	// ready once its webhook server listens with the serving certificate.
+	if enableWebhooks {
+		if err := mgr.AddHealthzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
+			setupLog.Error(err, "unable to set up webhook health check")
+			os.Exit(1)
+		}
+		if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
+			setupLog.Error(err, "unable to set up webhook ready check")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
+
+// rateLimiter returns the work queue rate limiter of a controller: failed
+// objects are requeued with a per-object exponential delay between baseDelay
+// and maxDelay, and all requeues together are limited to qps with burst.
+func rateLimiter(baseDelay, maxDelay time.Duration, qps float64, burst int) workqueue.RateLimiter {
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(qps), burst)},
+	)
+}
+
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
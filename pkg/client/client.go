@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package client is the Go client library for the Kairos provider APIs.
+//
+// The generated subpackages provide a typed clientset (clientset/versioned),
+// listers (listers) and shared informers (informers/externalversions) for
+// KairosConfig, KairosConfigTemplate, KairosControlPlane and
+// KairosControlPlaneTemplate. They are regenerated with `make generate-client`.
+//
+// This package adds helpers on top of the clientset for common integration
+// tasks such as waiting for a control plane or reading bootstrap data.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+	controlplanev1beta2 "github.com/kairos-io/kairos-capi/api/controlplane/v1beta2"
+	"github.com/kairos-io/kairos-capi/pkg/client/clientset/versioned"
+)
+
+// BootstrapDataKey is the key holding the cloud-config in a bootstrap data Secret.
+const BootstrapDataKey = "value"
+
+// DefaultPollInterval is how often the Wait helpers check the object.
+const DefaultPollInterval = 5 * time.Second
+
+// ErrBootstrapDataNotReady is returned when a KairosConfig has no bootstrap
+// data Secret yet.
+var ErrBootstrapDataNotReady = errors.New("bootstrap data is not ready")
+
+// IsControlPlaneReady reports whether the control plane is initialized, the
+// controller has observed its latest spec and all desired replicas are ready.
+func IsControlPlaneReady(kcp *controlplanev1beta2.KairosControlPlane) bool {
+	desired := int32(1)
+	if kcp.Spec.Replicas != nil {
+		desired = *kcp.Spec.Replicas
+	}
+	return kcp.Status.Initialized &&
+		kcp.Status.ObservedGeneration >= kcp.Generation &&
+		kcp.Status.ReadyReplicas >= desired
+}
+
+// WaitForControlPlaneReady polls the KairosControlPlane until
+// IsControlPlaneReady is true or ctx is done, and returns the last object read.
+// Use a context with a deadline to bound the wait.
+func WaitForControlPlaneReady(ctx context.Context, cs versioned.Interface, namespace, name string) (*controlplanev1beta2.KairosControlPlane, error) {
+	var kcp *controlplanev1beta2.KairosControlPlane
+	err := wait.PollUntilContextCancel(ctx, DefaultPollInterval, true, func(ctx context.Context) (bool, error) {
+		current, err := cs.ControlPlaneV1beta2().KairosControlPlanes(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("failed to get KairosControlPlane %s/%s: %w", namespace, name, err)
+		}
+		kcp = current
+		return IsControlPlaneReady(kcp), nil
+	})
+	if err != nil {
+		return kcp, fmt.Errorf("KairosControlPlane %s/%s not ready: %w", namespace, name, err)
+	}
+	return kcp, nil
+}
+
+// WaitForBootstrapReady polls the KairosConfig until its bootstrap data is
+// ready or ctx is done, and returns the last object read.
+func WaitForBootstrapReady(ctx context.Context, cs versioned.Interface, namespace, name string) (*bootstrapv1beta2.KairosConfig, error) {
+	var kc *bootstrapv1beta2.KairosConfig
+	err := wait.PollUntilContextCancel(ctx, DefaultPollInterval, true, func(ctx context.Context) (bool, error) {
+		current, err := cs.BootstrapV1beta2().KairosConfigs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("failed to get KairosConfig %s/%s: %w", namespace, name, err)
+		}
+		kc = current
+		return kc.Status.Ready && kc.Status.DataSecretName != nil, nil
+	})
+	if err != nil {
+		return kc, fmt.Errorf("KairosConfig %s/%s not ready: %w", namespace, name, err)
+	}
+	return kc, nil
+}
+
+// GetBootstrapSecret returns the Secret holding the bootstrap data of the
+// KairosConfig namespace/name. It returns ErrBootstrapDataNotReady when the
+// controller has not generated the data yet.
+func GetBootstrapSecret(ctx context.Context, kube kubernetes.Interface, cs versioned.Interface, namespace, name string) (*corev1.Secret, error) {
+	kc, err := cs.BootstrapV1beta2().KairosConfigs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get KairosConfig %s/%s: %w", namespace, name, err)
+	}
+	if kc.Status.DataSecretName == nil || *kc.Status.DataSecretName == "" {
+		return nil, fmt.Errorf("KairosConfig %s/%s: %w", namespace, name, ErrBootstrapDataNotReady)
+	}
+	secret, err := kube.CoreV1().Secrets(namespace).Get(ctx, *kc.Status.DataSecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bootstrap data secret %s/%s: %w", namespace, *kc.Status.DataSecretName, err)
+	}
+	return secret, nil
+}
+
+// GetBootstrapData returns the cloud-config generated for the KairosConfig
+// namespace/name.
+func GetBootstrapData(ctx context.Context, kube kubernetes.Interface, cs versioned.Interface, namespace, name string) ([]byte, error) {
+	secret, err := GetBootstrapSecret(ctx, kube, cs, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := secret.Data[BootstrapDataKey]
+	if !ok {
+		return nil, fmt.Errorf("bootstrap data secret %s/%s has no %q key", namespace, secret.Name, BootstrapDataKey)
+	}
+	return data, nil
+}
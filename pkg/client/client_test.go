@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+	controlplanev1beta2 "github.com/kairos-io/kairos-capi/api/controlplane/v1beta2"
+	"github.com/kairos-io/kairos-capi/pkg/client/clientset/versioned/fake"
+)
+
+func TestIsControlPlaneReady(t *testing.T) {
+	g := NewWithT(t)
+
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec:       controlplanev1beta2.KairosControlPlaneSpec{Replicas: ptr.To[int32](3)},
+		Status: controlplanev1beta2.KairosControlPlaneStatus{
+			Initialized:        true,
+			ObservedGeneration: 2,
+			ReadyReplicas:      2,
+		},
+	}
+	g.Expect(IsControlPlaneReady(kcp)).To(BeFalse())
+
+	kcp.Status.ReadyReplicas = 3
+	g.Expect(IsControlPlaneReady(kcp)).To(BeTrue())
+
+	kcp.Generation = 3
+	g.Expect(IsControlPlaneReady(kcp)).To(BeFalse())
+}
+
+func TestWaitForControlPlaneReady(t *testing.T) {
+	g := NewWithT(t)
+
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "kcp", Namespace: "default"},
+		Status:     controlplanev1beta2.KairosControlPlaneStatus{Initialized: true, ReadyReplicas: 1},
+	}
+	cs := fake.NewSimpleClientset(kcp)
+
+	got, err := WaitForControlPlaneReady(context.Background(), cs, "default", "kcp")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got.Name).To(Equal("kcp"))
+
+	kcp.Status.ReadyReplicas = 0
+	_, err = cs.ControlPlaneV1beta2().KairosControlPlanes("default").Update(context.Background(), kcp, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = WaitForControlPlaneReady(ctx, cs, "default", "kcp")
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestGetBootstrapData(t *testing.T) {
+	g := NewWithT(t)
+
+	pending := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default"},
+	}
+	ready := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "ready", Namespace: "default"},
+		Status: bootstrapv1beta2.KairosConfigStatus{
+			Ready:          true,
+			DataSecretName: ptr.To("ready-bootstrap"),
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ready-bootstrap", Namespace: "default"},
+		Data:       map[string][]byte{BootstrapDataKey: []byte("#cloud-config\n")},
+	}
+	cs := fake.NewSimpleClientset(pending, ready)
+	kube := kubefake.NewSimpleClientset(secret)
+
+	data, err := GetBootstrapData(context.Background(), kube, cs, "default", "ready")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(data)).To(Equal("#cloud-config\n"))
+
+	_, err = GetBootstrapSecret(context.Background(), kube, cs, "default", "pending")
+	g.Expect(errors.Is(err, ErrBootstrapDataNotReady)).To(BeTrue())
+
+	kc, err := WaitForBootstrapReady(context.Background(), cs, "default", "ready")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(*kc.Status.DataSecretName).To(Equal("ready-bootstrap"))
+}
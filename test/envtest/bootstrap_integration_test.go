@@ -28,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"os"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -80,7 +81,7 @@ func TestBootstrapIntegration(t *testing.T) {
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}
-	g.Expect(bootstrapReconciler.SetupWithManager(mgr)).To(Succeed())
+	g.Expect(bootstrapReconciler.SetupWithManager(mgr, controller.Options{})).To(Succeed())
 
 	// Start manager
 	ctx, cancel := context.WithCancel(context.Background())
@@ -22,12 +22,15 @@ import (
 	"time"
 
 	. "github.com/onsi/gomega"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"os"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -87,13 +90,13 @@ func TestControlPlaneIntegration(t *testing.T) {
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}
-	g.Expect(bootstrapReconciler.SetupWithManager(mgr)).To(Succeed())
+	g.Expect(bootstrapReconciler.SetupWithManager(mgr, controller.Options{})).To(Succeed())
 
 	controlPlaneReconciler := &controlplane.KairosControlPlaneReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}
-	g.Expect(controlPlaneReconciler.SetupWithManager(mgr)).To(Succeed())
+	g.Expect(controlPlaneReconciler.SetupWithManager(mgr, controller.Options{})).To(Succeed())
 
 	// Start manager
 	ctx, cancel := context.WithCancel(context.Background())
@@ -210,3 +213,75 @@ func TestControlPlaneIntegration(t *testing.T) {
 	// The unit tests (TestCreateControlPlaneMachine_SingleNode) verify the SingleNode logic
 	// with mocked infrastructure. For full integration testing, use a real infrastructure provider.
 }
+
+func TestControlPlaneScaleSubresource(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+	g := NewWithT(t)
+
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths:     []string{"../../config/crd/bases"},
+		ErrorIfCRDPathMissing: true,
+	}
+
+	cfg, err := testEnv.Start()
+	g.Expect(err).NotTo(HaveOccurred())
+	defer func() {
+		g.Expect(testEnv.Stop()).To(Succeed())
+	}()
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(autoscalingv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(controlplanev1beta2.AddToScheme(scheme)).To(Succeed())
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	ctx := context.Background()
+	g.Expect(c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "scale-test"}})).To(Succeed())
+
+	replicas := int32(1)
+	kcp := &controlplanev1beta2.KairosControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-kcp",
+			Namespace: "scale-test",
+		},
+		Spec: controlplanev1beta2.KairosControlPlaneSpec{
+			Replicas: &replicas,
+			Version:  "v1.30.0+k0s.0",
+			MachineTemplate: controlplanev1beta2.KairosControlPlaneMachineTemplate{
+				InfrastructureRef: corev1.ObjectReference{
+					APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+					Kind:       "DockerMachineTemplate",
+					Name:       "test-infra-template",
+				},
+			},
+			KairosConfigTemplate: controlplanev1beta2.KairosConfigTemplateReference{
+				Name: "test-config-template",
+			},
+		},
+	}
+	g.Expect(c.Create(ctx, kcp)).To(Succeed())
+
+	kcp.Status.Replicas = 1
+	kcp.Status.Selector = "cluster.x-k8s.io/cluster-name=test-cluster,cluster.x-k8s.io/control-plane="
+	g.Expect(c.Status().Update(ctx, kcp)).To(Succeed())
+
+	// The scale subresource reports the spec/status replicas and the selector
+	scale := &autoscalingv1.Scale{}
+	g.Expect(c.SubResource("scale").Get(ctx, kcp, scale)).To(Succeed())
+	g.Expect(scale.Spec.Replicas).To(Equal(int32(1)))
+	g.Expect(scale.Status.Replicas).To(Equal(int32(1)))
+	g.Expect(scale.Status.Selector).To(Equal(kcp.Status.Selector))
+
+	// Scaling through the subresource updates spec.replicas
+	scale.Spec.Replicas = 3
+	g.Expect(c.SubResource("scale").Update(ctx, kcp, client.WithSubResourceBody(scale))).To(Succeed())
+
+	updated := &controlplanev1beta2.KairosControlPlane{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(kcp), updated)).To(Succeed())
+	g.Expect(updated.Spec.Replicas).NotTo(BeNil())
+	g.Expect(*updated.Spec.Replicas).To(Equal(int32(3)))
+}
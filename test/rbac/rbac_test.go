@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package rbac checks that the namespace-scoped Role of the minimal RBAC
+// profile keeps up with the ClusterRole generated from the kubebuilder:rbac
+// markers.
+package rbac
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// caInjectionResources are only needed by the webhook CA injection job,
+// which the minimal profile drops, and are cluster-scoped or live outside
+// the manager's namespace.
+var caInjectionResources = map[string]bool{
+	"admissionregistration.k8s.io/mutatingwebhookconfigurations":   true,
+	"admissionregistration.k8s.io/validatingwebhookconfigurations": true,
+	"apiextensions.k8s.io/customresourcedefinitions":               true,
+	"apps/deployments":             true,
+	"batch/jobs":                   true,
+	"cert-manager.io/certificates": true,
+}
+
+type permission struct {
+	group    string
+	resource string
+	verb     string
+}
+
+func TestMinimalRoleCoversGeneratedRules(t *testing.T) {
+	g := NewWithT(t)
+
+	clusterRole := &rbacv1.ClusterRole{}
+	g.Expect(readManifest("../../config/rbac/role.yaml", clusterRole)).To(Succeed())
+	role := &rbacv1.Role{}
+	g.Expect(readManifest("../../config/rbac-minimal/role.yaml", role)).To(Succeed())
+
+	var missing []permission
+	for _, p := range permissions(clusterRole.Rules) {
+		if caInjectionResources[p.group+"/"+p.resource] {
+			continue
+		}
+		if !allows(role.Rules, p) {
+			missing = append(missing, p)
+		}
+	}
+	g.Expect(missing).To(BeEmpty(), "config/rbac-minimal/role.yaml is missing rules of config/rbac/role.yaml")
+}
+
+func readManifest(path string, obj interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(data, obj)
+}
+
+func permissions(rules []rbacv1.PolicyRule) []permission {
+	var perms []permission
+	for _, rule := range rules {
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				for _, verb := range rule.Verbs {
+					perms = append(perms, permission{group: group, resource: resource, verb: verb})
+				}
+			}
+		}
+	}
+	return perms
+}
+
+// allows reports whether rules grant p, honouring wildcards.
+func allows(rules []rbacv1.PolicyRule, p permission) bool {
+	for _, rule := range rules {
+		if matches(rule.APIGroups, p.group) && matches(rule.Resources, p.resource) && matches(rule.Verbs, p.verb) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(values []string, value string) bool {
+	for _, v := range values {
+		if v == rbacv1.ResourceAll || v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,349 @@
+/*
+Copyright 2024 The Kairos CAPI Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+implied. See the License for the specific language governing
+permissions and limitations under the License.
+*/
+
+// Package scale contains a load test that creates many synthetic
+// Clusters/Machines/KairosConfigs and reports how the bootstrap controller
+// keeps up. It only runs when KAIROS_SCALE_TEST is set (see `make test-scale`).
+package scale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	bootstrapv1beta2 "github.com/kairos-io/kairos-capi/api/bootstrap/v1beta2"
+	"github.com/kairos-io/kairos-capi/internal/controllers/bootstrap"
+)
+
+const (
+	scaleNamespace   = "kairos-scale"
+	defaultClusters  = 100
+	defaultTimeout   = 10 * time.Minute
+	sampleInterval   = 250 * time.Millisecond
+	bootstrapCtrlKey = "kairosconfig"
+)
+
+// report is the summary printed (and optionally written to SCALE_REPORT) at
+// the end of a run.
+type report struct {
+	Clusters        int           `json:"clusters"`
+	Total           time.Duration `json:"total"`
+	LatencyP50      time.Duration `json:"latencyP50"`
+	LatencyP95      time.Duration `json:"latencyP95"`
+	LatencyMax      time.Duration `json:"latencyMax"`
+	MaxQueueDepth   float64       `json:"maxQueueDepth"`
+	PeakHeapAllocMB uint64        `json:"peakHeapAllocMB"`
+}
+
+func TestBootstrapScale(t *testing.T) {
+	if os.Getenv("KAIROS_SCALE_TEST") == "" {
+		t.Skip("Set KAIROS_SCALE_TEST=1 to run the scalability test")
+	}
+	g := NewWithT(t)
+
+	clusters := envInt(t, "SCALE_CLUSTERS", defaultClusters)
+	timeout := envDuration(t, "SCALE_TIMEOUT", defaultTimeout)
+
+	// USE_EXISTING_CLUSTER=true makes envtest target the current kubeconfig
+	// (e.g. a kind cluster) instead of starting a local control plane.
+	crdPaths := []string{"../../config/crd/bases"}
+	if _, err := os.Stat("../../test/crd/capi/cluster-api-components.yaml"); err == nil {
+		crdPaths = append(crdPaths, "../../test/crd/capi")
+	}
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths:     crdPaths,
+		ErrorIfCRDPathMissing: false,
+	}
+	cfg, err := testEnv.Start()
+	g.Expect(err).NotTo(HaveOccurred())
+	defer func() {
+		g.Expect(testEnv.Stop()).To(Succeed())
+	}()
+	// The default client-side rate limiter would dominate the measurement.
+	cfg.QPS = 500
+	cfg.Burst = 1000
+
+	scheme := clientgoscheme.Scheme
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(bootstrapv1beta2.AddToScheme(scheme)).To(Succeed())
+
+	mgr, err := manager.New(cfg, manager.Options{
+		Scheme:  scheme,
+		Logger:  log.Log,
+		Metrics: metricsserver.Options{BindAddress: "0"},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect((&bootstrap.KairosConfigReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		APIReader: mgr.GetAPIReader(),
+	}).SetupWithManager(mgr, controller.Options{})).To(Succeed())
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	go func() {
+		_ = mgr.Start(ctx)
+	}()
+	g.Expect(mgr.GetCache().WaitForCacheSync(ctx)).To(BeTrue())
+
+	c := mgr.GetClient()
+	g.Expect(client.IgnoreAlreadyExists(c.Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: scaleNamespace},
+	}))).To(Succeed())
+
+	var (
+		mu            sync.Mutex
+		maxQueueDepth float64
+		peakHeap      uint64
+	)
+	samplerCtx, stopSampler := context.WithCancel(ctx)
+	defer stopSampler()
+	go func() {
+		ticker := time.NewTicker(sampleInterval)
+		defer ticker.Stop()
+		var ms runtime.MemStats
+		for {
+			select {
+			case <-samplerCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			depth := queueDepth(bootstrapCtrlKey)
+			runtime.ReadMemStats(&ms)
+			mu.Lock()
+			if depth > maxQueueDepth {
+				maxQueueDepth = depth
+			}
+			if ms.HeapAlloc > peakHeap {
+				peakHeap = ms.HeapAlloc
+			}
+			mu.Unlock()
+		}
+	}()
+
+	start := time.Now()
+	created := make(map[string]time.Time, clusters)
+	for i := 0; i < clusters; i++ {
+		name := fmt.Sprintf("scale-%04d", i)
+		g.Expect(createSyntheticCluster(ctx, c, name)).To(Succeed())
+		created[name] = time.Now()
+	}
+	t.Logf("created %d clusters in %s", clusters, time.Since(start))
+
+	ready := make(map[string]time.Duration, clusters)
+	g.Eventually(func() int {
+		list := &bootstrapv1beta2.KairosConfigList{}
+		if err := c.List(ctx, list, client.InNamespace(scaleNamespace)); err != nil {
+			return len(ready)
+		}
+		now := time.Now()
+		for i := range list.Items {
+			kc := &list.Items[i]
+			if _, done := ready[kc.Name]; done || kc.Status.DataSecretName == nil {
+				continue
+			}
+			ready[kc.Name] = now.Sub(created[kc.Name])
+		}
+		return len(ready)
+	}, timeout, time.Second).Should(Equal(clusters))
+	total := time.Since(start)
+	stopSampler()
+
+	latencies := make([]time.Duration, 0, len(ready))
+	for _, d := range ready {
+		latencies = append(latencies, d)
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	mu.Lock()
+	r := report{
+		Clusters:        clusters,
+		Total:           total,
+		LatencyP50:      percentile(latencies, 50),
+		LatencyP95:      percentile(latencies, 95),
+		LatencyMax:      latencies[len(latencies)-1],
+		MaxQueueDepth:   maxQueueDepth,
+		PeakHeapAllocMB: peakHeap / (1024 * 1024),
+	}
+	mu.Unlock()
+
+	t.Logf("scale report: clusters=%d total=%s p50=%s p95=%s max=%s maxQueueDepth=%.0f peakHeap=%dMiB",
+		r.Clusters, r.Total, r.LatencyP50, r.LatencyP95, r.LatencyMax, r.MaxQueueDepth, r.PeakHeapAllocMB)
+
+	if path := os.Getenv("SCALE_REPORT"); path != "" {
+		data, err := json.MarshalIndent(r, "", "  ")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(os.WriteFile(path, data, 0644)).To(Succeed())
+	}
+
+	// Optional regression guards.
+	if maxP95 := envDuration(t, "SCALE_MAX_P95", 0); maxP95 > 0 {
+		g.Expect(r.LatencyP95).To(BeNumerically("<=", maxP95), "p95 reconcile latency regressed")
+	}
+	if maxHeap := envInt(t, "SCALE_MAX_HEAP_MB", 0); maxHeap > 0 {
+		g.Expect(r.PeakHeapAllocMB).To(BeNumerically("<=", maxHeap), "peak heap usage regressed")
+	}
+}
+
+// createSyntheticCluster creates a Cluster, a control-plane Machine and its
+// single-node KairosConfig, which is enough for the bootstrap controller to
+// render and store bootstrap data.
+func createSyntheticCluster(ctx context.Context, c client.Client, name string) error {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: scaleNamespace,
+		},
+		Spec: clusterv1.ClusterSpec{
+			InfrastructureRef: &corev1.ObjectReference{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+				Kind:       "DockerCluster",
+				Name:       name,
+			},
+		},
+	}
+	if err := c.Create(ctx, cluster); err != nil {
+		return fmt.Errorf("failed to create cluster %s: %w", name, err)
+	}
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: scaleNamespace,
+			Labels: map[string]string{
+				clusterv1.ClusterNameLabel:         name,
+				clusterv1.MachineControlPlaneLabel: "",
+			},
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: name,
+			Bootstrap: clusterv1.Bootstrap{
+				ConfigRef: &corev1.ObjectReference{
+					APIVersion: bootstrapv1beta2.GroupVersion.String(),
+					Kind:       "KairosConfig",
+					Name:       name,
+					Namespace:  scaleNamespace,
+				},
+			},
+		},
+	}
+	if err := c.Create(ctx, machine); err != nil {
+		return fmt.Errorf("failed to create machine %s: %w", name, err)
+	}
+
+	kairosConfig := &bootstrapv1beta2.KairosConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: scaleNamespace,
+			Labels: map[string]string{
+				clusterv1.ClusterNameLabel: name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(machine, clusterv1.GroupVersion.WithKind("Machine")),
+			},
+		},
+		Spec: bootstrapv1beta2.KairosConfigSpec{
+			Role:              "control-plane",
+			Distribution:      "k0s",
+			KubernetesVersion: "v1.30.0+k0s.0",
+			SingleNode:        true,
+			UserName:          "kairos",
+			UserPassword:      "kairos",
+			UserGroups:        []string{"admin"},
+		},
+	}
+	if err := c.Create(ctx, kairosConfig); err != nil {
+		return fmt.Errorf("failed to create kairosconfig %s: %w", name, err)
+	}
+	return nil
+}
+
+// queueDepth reads the workqueue_depth gauge for the named controller from the
+// controller-runtime metrics registry.
+func queueDepth(name string) float64 {
+	families, err := ctrlmetrics.Registry.Gather()
+	if err != nil {
+		return 0
+	}
+	for _, mf := range families {
+		if mf.GetName() != "workqueue_depth" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "name" && l.GetValue() == name {
+					return m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := (len(sorted)*p + 99) / 100
+	if idx > 0 {
+		idx--
+	}
+	return sorted[idx]
+}
+
+func envInt(t *testing.T, key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		t.Fatalf("invalid %s=%q: %v", key, v, err)
+	}
+	return n
+}
+
+func envDuration(t *testing.T, key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		t.Fatalf("invalid %s=%q: %v", key, v, err)
+	}
+	return d
+}